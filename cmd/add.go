@@ -7,7 +7,9 @@ import (
 
 	"github.com/datagendev/datagen-cli/internal/codegen"
 	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/output"
 	"github.com/datagendev/datagen-cli/internal/prompts"
+	"github.com/datagendev/datagen-cli/internal/telemetry"
 	"github.com/spf13/cobra"
 )
 
@@ -21,7 +23,18 @@ var addCmd = &cobra.Command{
 	Short: "Add a new service to an existing project",
 	Long: `Interactively add a new service (endpoint) to an existing DataGen project.
 This command will update the configuration and inject new code into existing files
-without overwriting user customizations.`,
+without overwriting user customizations.
+
+Every question can be answered with a flag instead; --name skips the questions
+and takes the rest from flags and their defaults, for Makefiles and CI.
+Fields are name:type[:required|optional[:default]].
+
+Examples:
+  datagen add
+  datagen add --name signup --type webhook --description "Enrich new signups" \
+    --field "email:str:required" --retries 3
+  datagen add --name chat --type api --path /api/chat --description "Answer questions" \
+    --field "question:str:required" --output-field "answer:str" --auth bearer_token --tools none`,
 	Run: runAdd,
 }
 
@@ -30,72 +43,103 @@ func init() {
 	addCmd.Flags().StringVarP(&addConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml configuration file")
 	addCmd.MarkFlagDirname("output")
 	addCmd.MarkFlagFilename("config", "toml")
+	addServiceFlags(addCmd)
 }
 
 func runAdd(cmd *cobra.Command, args []string) {
+	used, named := serviceFlagsUsed(cmd)
+	if used && !named {
+		fmt.Fprintln(os.Stderr, "Error: --name is required to create a service from flags")
+		os.Exit(1)
+	}
+
 	fmt.Println("➕ Adding a new service to your project...")
 
+	// Hold the project lock from reading datagen.toml until main.py is
+	// updated, releasing it before every exit
+	lock, err := lockProject(addOutputDir, "add")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer lock.Release()
+	exit := func() {
+		lock.Release()
+		os.Exit(1)
+	}
+
 	// Load existing configuration
 	cfg, err := config.LoadConfig(addConfigPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 		fmt.Println("\nMake sure you run this command from your project directory,")
 		fmt.Println("or use --config to specify the path to datagen.toml")
-		os.Exit(1)
+		exit()
 	}
 
-	fmt.Printf("✓ Loaded configuration with %d existing service(s)\n", len(cfg.Services))
+	output.Printf("✓ Loaded configuration with %d existing service(s)\n", len(cfg.Services))
 
-	// Collect new service configuration
-	fmt.Println("\n📦 Configure new service:")
-	newService, err := prompts.CollectServiceConfig()
+	// Collect new service configuration, from flags when --name is given
+	var newService *config.Service
+	if named {
+		newService, err = serviceFromFlags(cmd, cfg.Defaults)
+	} else {
+		fmt.Println("\n📦 Configure new service:")
+		newService, err = prompts.CollectServiceConfig(cfg.Defaults)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		exit()
 	}
 
 	// Check for duplicate service names
 	for _, svc := range cfg.Services {
 		if svc.Name == newService.Name {
 			fmt.Fprintf(os.Stderr, "Error: Service '%s' already exists\n", newService.Name)
-			os.Exit(1)
+			exit()
 		}
 	}
 
-	// Add service to configuration
+	// Add service to configuration, filling in what it inherits from [defaults]
 	cfg.Services = append(cfg.Services, *newService)
-
-	// Save updated configuration
-	if err := config.SaveConfig(cfg, addConfigPath); err != nil {
-		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
-		os.Exit(1)
-	}
-
-	fmt.Println("\n✓ Configuration updated")
+	cfg.ApplyDefaults()
+	newService = &cfg.Services[len(cfg.Services)-1]
 
 	// Create agent prompt file
-	fmt.Println("\n📝 Creating agent prompt file...")
-	if err := createAgentPromptFile(addOutputDir, newService); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: Could not create prompt file: %v\n", err)
+	output.Println("\n📝 Creating agent prompt file...")
+	if _, err := os.Stat(filepath.Join(addOutputDir, newService.Prompt)); err == nil {
+		output.Printf("  ✓ Using existing %s\n", newService.Prompt)
+	} else if err := createAgentPromptFile(addOutputDir, newService); err != nil {
+		output.Warnf("Could not create prompt file: %v\n", err)
 		fmt.Println("You may need to create it manually.")
 	} else {
-		fmt.Printf("  ✓ Created %s\n", newService.Prompt)
+		output.Printf("  ✓ Created %s\n", newService.Prompt)
 	}
 
 	// Update existing code files incrementally
-	fmt.Println("\n🔄 Updating project files...")
+	output.Println("\n🔄 Updating project files...")
 	if err := codegen.IncrementalAddService(cfg, newService, addOutputDir); err != nil {
 		fmt.Fprintf(os.Stderr, "Error updating project files: %v\n", err)
-		fmt.Println("\nNote: If marker comments are missing, you may need to run 'datagen build'")
-		fmt.Println("to fully regenerate the project (this will overwrite customizations).")
-		os.Exit(1)
+		fmt.Println("\nNote: If marker comments are missing, run 'datagen repair' to restore them,")
+		fmt.Println("then run 'datagen add' again. datagen.toml is unchanged; 'datagen undo' reverts")
+		fmt.Println("any files this run already wrote.")
+		exit()
+	}
+
+	// Save the configuration only once the code has the service
+	telemetry.TagConfig(cfg)
+	if err := config.SaveConfig(cfg, addConfigPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+		exit()
 	}
 
+	output.Println("\n✓ Configuration updated")
+
 	absPath, _ := filepath.Abs(addOutputDir)
-	fmt.Printf("\n✅ Service '%s' added successfully to %s\n", newService.Name, absPath)
+	output.Printf("\n✅ Service '%s' added successfully to %s\n", newService.Name, absPath)
 	fmt.Println("\n📝 Next steps:")
 	fmt.Printf("  1. Customize the agent prompt file: %s\n", newService.Prompt)
 	fmt.Println("  2. Test the new endpoint locally")
-	fmt.Println("  3. Deploy your updated project: datagen deploy railway")
+	fmt.Println("  3. Deploy your updated project: datagen env push, then railway up")
 	fmt.Println("\n💡 Tip: Your custom code in other parts of the files has been preserved!")
 }
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/datagendev/datagen-cli/internal/adopt"
+	"github.com/datagendev/datagen-cli/internal/codegen"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/prompts"
+	"github.com/spf13/cobra"
+)
+
+var (
+	adoptOutputDir  string
+	adoptConfigPath string
+	adoptDryRun     bool
+	adoptYes        bool
+	adoptForce      bool
+)
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt",
+	Short: "Bring an existing FastAPI project under datagen management",
+	Long: `Scan an existing FastAPI app and write a datagen.toml describing it, so
+'datagen add' and the deploy commands work without regenerating the app.
+
+adopt looks at the FastAPI app in app/main.py, the routes and pydantic models
+in app/*.py, and the agent prompts in .claude/agents. A POST route becomes a
+service when a prompt is named after its handler or last path segment (for
+example .claude/agents/enrich.md for POST /api/enrich).
+
+It then appends a marked section to app/main.py (and app/models.py) where
+'datagen add' injects new services, and writes the support modules datagen
+handlers import (app/agent.py, app/config.py, ...) when they are missing.
+Existing code is not rewritten.
+
+Do not run 'datagen build' on an adopted project: it regenerates app/main.py.
+Use 'datagen build --file' or 'datagen generate' for individual files.
+
+Examples:
+  datagen adopt --dry-run
+  datagen adopt -o ./legacy-service
+  datagen adopt --yes`,
+	Args: cobra.NoArgs,
+	RunE: runAdopt,
+}
+
+func init() {
+	adoptCmd.Flags().StringVarP(&adoptOutputDir, "output", "o", ".", "Project directory")
+	adoptCmd.Flags().StringVarP(&adoptConfigPath, "config", "c", "", "Path for the new datagen.toml (default <output>/datagen.toml)")
+	adoptCmd.Flags().BoolVar(&adoptDryRun, "dry-run", false, "Print the proposed datagen.toml without writing anything")
+	adoptCmd.Flags().BoolVarP(&adoptYes, "yes", "y", false, "Skip the confirmation prompt")
+	adoptCmd.Flags().BoolVar(&adoptForce, "force", false, "Overwrite an existing datagen.toml")
+	adoptCmd.MarkFlagDirname("output")
+}
+
+func runAdopt(cmd *cobra.Command, args []string) error {
+	configPath := adoptConfigPath
+	if configPath == "" {
+		configPath = filepath.Join(adoptOutputDir, "datagen.toml")
+	}
+	if _, err := os.Stat(configPath); err == nil && !adoptForce && !adoptDryRun {
+		return fmt.Errorf("%s already exists; the project is already managed by datagen (use --force to replace it)", configPath)
+	}
+
+	project, err := adopt.Scan(adoptOutputDir)
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	output.Printf("🔍 Found FastAPI app %q with %d route(s) and %d agent prompt(s)\n\n", project.AppVar, len(project.Routes), len(project.Agents))
+
+	services, skipped := project.Propose()
+	for _, s := range services {
+		output.Printf("  ✓ %-6s %-28s → %s service %q (%s)\n", "POST", s.Route.Path, s.Service.Type, s.Service.Name, s.Service.Prompt)
+	}
+	for _, r := range skipped {
+		reason := "no matching agent prompt"
+		if r.Method != "post" {
+			reason = "not a POST route"
+		}
+		fmt.Printf("  - %-6s %-28s skipped (%s)\n", strings.ToUpper(r.Method), r.Path, reason)
+	}
+	if len(services) == 0 {
+		return fmt.Errorf("no route is backed by an agent prompt; name a prompt in .claude/agents after a POST route's handler (e.g. enrich.md for def enrich) and run adopt again")
+	}
+
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+	}
+	for _, s := range services {
+		cfg.Services = append(cfg.Services, s.Service)
+	}
+	if err := config.ValidateConfig(cfg, adoptOutputDir); err != nil {
+		return fmt.Errorf("proposed config is invalid: %w", err)
+	}
+
+	fmt.Printf("\n📄 Proposed %s:\n\n", configPath)
+	if err := config.EncodeConfig(cfg, os.Stdout); err != nil {
+		return err
+	}
+	if adoptDryRun {
+		return nil
+	}
+
+	if !adoptYes {
+		confirm := true
+		if err := prompts.AskOne(&survey.Confirm{
+			Message: fmt.Sprintf("Write %s and add datagen markers to app/main.py?", configPath),
+			Default: true,
+		}, &confirm); err != nil {
+			return err
+		}
+		if !confirm {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	changed, err := codegen.AdoptProject(cfg, adoptOutputDir, project.AppVar)
+	if err != nil {
+		return err
+	}
+	if err := config.SaveConfig(cfg, configPath); err != nil {
+		return fmt.Errorf("error saving config: %w", err)
+	}
+
+	output.Printf("\n✓ Wrote %s\n", configPath)
+	for _, p := range changed {
+		output.Printf("  ✓ %s\n", p)
+	}
+	fmt.Println("\n📝 Next steps:")
+	fmt.Println("  1. Review the changes (git diff)")
+	fmt.Println("  2. Add services with: datagen add")
+	fmt.Println("  3. Deploy to Railway: datagen env push, then railway up")
+	return nil
+}
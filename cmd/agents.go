@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/datagendev/datagen-cli/internal/api"
+	"github.com/datagendev/datagen-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -49,7 +50,8 @@ var (
 )
 
 var agentsCmd = &cobra.Command{
-	Use:   "agents",
+	Use:     "agents",
+	Aliases: []string{"agent"},
 	Short: "Manage discovered agents, skills, and commands",
 	Long: `Manage agents, skills, and commands discovered from your connected GitHub repositories.
 
@@ -103,7 +105,18 @@ var agentsRunCmd = &cobra.Command{
 	Short: "Trigger agent execution",
 	Long: `Trigger an agent to run with an optional payload.
 
-The agent must be deployed before it can be run.`,
+The agent must be deployed before it can be run.
+
+With --local, the argument is the name or file of an agent, skill or command
+under .claude (or ~/.claude) and it runs on this machine with the Claude Agent SDK, configured as the
+generated app would run it: same system prompt, tools, model and DataGen MCP
+connection. Nothing is built or deployed. The reply streams to stdout and
+tool calls to stderr. Needs Python with claude-agent-sdk installed and
+ANTHROPIC_API_KEY in the environment or .env.
+
+Examples:
+  datagen agents run agt_123 --payload '{"email": "a@example.com"}'
+  datagen agent run lead-enricher --local --data-file samples/enrich.json`,
 	Args: cobra.ExactArgs(1),
 	Run:  runAgentsRun,
 }
@@ -326,7 +339,7 @@ func runAgentsShow(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	fmt.Printf("🔍 Fetching details: %s\n", agentID)
+	output.Printf("🔍 Fetching details: %s\n", agentID)
 
 	agent, err := client.GetAgent(agentID)
 	if err != nil {
@@ -417,7 +430,7 @@ func runAgentsDeploy(cmd *cobra.Command, args []string) {
 
 	label := resolveAgentTypeLabel(client, agentID)
 
-	fmt.Printf("🚀 Deploying %s: %s\n", label, agentID)
+	output.Printf("🚀 Deploying %s: %s\n", label, agentID)
 
 	resp, err := client.DeployAgent(agentID, "", nil)
 	if err != nil {
@@ -426,7 +439,7 @@ func runAgentsDeploy(cmd *cobra.Command, args []string) {
 	}
 
 	fmt.Println()
-	fmt.Printf("✅ %s deployed successfully!\n", capitalize(label))
+	output.Printf("✅ %s deployed successfully!\n", capitalize(label))
 	fmt.Println()
 	fmt.Println("🔗 Webhook URL:")
 	fmt.Printf("   %s\n", resp.WebhookUrl)
@@ -450,7 +463,7 @@ func runAgentsUndeploy(cmd *cobra.Command, args []string) {
 
 	label := resolveAgentTypeLabel(client, agentID)
 
-	fmt.Printf("🛑 Undeploying %s: %s\n", label, agentID)
+	output.Printf("🛑 Undeploying %s: %s\n", label, agentID)
 
 	_, err = client.UndeployAgent(agentID)
 	if err != nil {
@@ -458,13 +471,21 @@ func runAgentsUndeploy(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	fmt.Printf("✅ %s undeployed successfully!\n", capitalize(label))
+	output.Printf("✅ %s undeployed successfully!\n", capitalize(label))
 	fmt.Println("   The webhook URL is no longer active.")
 }
 
 func runAgentsRun(cmd *cobra.Command, args []string) {
 	agentID := args[0]
 
+	if agentsRunLocal {
+		if err := runAgentsRunLocal(agentID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	client, err := getAPIClient()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -480,7 +501,7 @@ func runAgentsRun(cmd *cobra.Command, args []string) {
 
 	label := resolveAgentTypeLabel(client, agentID)
 
-	fmt.Printf("▶️  Running %s: %s\n", label, agentID)
+	output.Printf("▶️  Running %s: %s\n", label, agentID)
 
 	resp, err := client.RunAgent(agentID, payload)
 	if err != nil {
@@ -489,7 +510,7 @@ func runAgentsRun(cmd *cobra.Command, args []string) {
 	}
 
 	fmt.Println()
-	fmt.Printf("✅ %s execution started!\n", capitalize(label))
+	output.Printf("✅ %s execution started!\n", capitalize(label))
 	fmt.Printf("   Execution ID: %s\n", resp.ExecutionID)
 	fmt.Printf("   Status: %s\n", resp.Status)
 	fmt.Println()
@@ -526,7 +547,7 @@ func runAgentsLogs(cmd *cobra.Command, args []string) {
 	}
 
 	// Default: list executions summary
-	fmt.Printf("📜 Fetching execution logs for: %s\n", agentID)
+	output.Printf("📜 Fetching execution logs for: %s\n", agentID)
 
 	resp, err := client.ListAgentExecutions(agentID, agentsExecLimit)
 	if err != nil {
@@ -587,7 +608,7 @@ func runAgentsLogs(cmd *cobra.Command, args []string) {
 
 // resolveExecutionFromSession looks up the execution ID for a given session ID
 func resolveExecutionFromSession(client *api.Client, agentID, sessionID string) (string, error) {
-	fmt.Printf("🔍 Looking up execution by session: %s\n", sessionID)
+	output.Printf("🔍 Looking up execution by session: %s\n", sessionID)
 
 	output, err := client.GetAgentExecutionOutputBySession(agentID, sessionID)
 	if err != nil {
@@ -696,7 +717,7 @@ func runDetailedLogs(client *api.Client, executionID string) {
 		limit = 1000 // default to more logs when viewing details
 	}
 
-	fmt.Printf("📜 Fetching detailed logs for execution: %s\n", executionID)
+	output.Printf("📜 Fetching detailed logs for execution: %s\n", executionID)
 
 	resp, err := client.GetExecutionLogs(executionID, logsLevel, limit)
 	if err != nil {
@@ -1078,7 +1099,7 @@ func runTranscript(client *api.Client, agentID, executionID string) {
 		limit = 200
 	}
 
-	fmt.Printf("📜 Fetching transcript for execution: %s\n", executionID)
+	output.Printf("📜 Fetching transcript for execution: %s\n", executionID)
 
 	resp, err := client.GetExecutionTranscript(agentID, executionID, limit)
 	if err != nil {
@@ -1241,22 +1262,22 @@ func runAgentsOutput(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	var output *api.ExecutionOutputResponse
+	var execOutput *api.ExecutionOutputResponse
 
 	switch {
 	case outputSessionID != "":
 		// Look up by session ID
-		fmt.Printf("🔍 Looking up output by session: %s\n", outputSessionID)
-		output, err = client.GetAgentExecutionOutputBySession(agentID, outputSessionID)
+		output.Printf("🔍 Looking up output by session: %s\n", outputSessionID)
+		execOutput, err = client.GetAgentExecutionOutputBySession(agentID, outputSessionID)
 
 	case outputExecID != "":
 		// Look up by execution ID
-		fmt.Printf("🔍 Fetching output for execution: %s\n", outputExecID)
-		output, err = client.GetAgentExecutionOutput(agentID, outputExecID)
+		output.Printf("🔍 Fetching output for execution: %s\n", outputExecID)
+		execOutput, err = client.GetAgentExecutionOutput(agentID, outputExecID)
 
 	default:
 		// Get latest execution, then fetch its output
-		fmt.Println("🔍 Fetching latest execution output...")
+		output.Println("🔍 Fetching latest execution output...")
 		execResp, execErr := client.ListAgentExecutions(agentID, 1)
 		if execErr != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", execErr)
@@ -1268,7 +1289,7 @@ func runAgentsOutput(cmd *cobra.Command, args []string) {
 			return
 		}
 		latestExec := execResp.Executions[0]
-		output, err = client.GetAgentExecutionOutput(agentID, latestExec.ID)
+		execOutput, err = client.GetAgentExecutionOutput(agentID, latestExec.ID)
 	}
 
 	if err != nil {
@@ -1278,50 +1299,50 @@ func runAgentsOutput(cmd *cobra.Command, args []string) {
 
 	// JSON mode: dump the raw result
 	if outputJSON {
-		data, _ := json.MarshalIndent(output.Result, "", "  ")
+		data, _ := json.MarshalIndent(execOutput.Result, "", "  ")
 		fmt.Println(string(data))
 		return
 	}
 
 	// Display formatted output
-	label := typeLabel(strings.ToUpper(output.Type))
+	label := typeLabel(strings.ToUpper(execOutput.Type))
 	fmt.Println()
-	fmt.Printf("%s %s: %s\n", typeIcon(strings.ToUpper(output.Type)), capitalize(label), output.AgentName)
+	fmt.Printf("%s %s: %s\n", typeIcon(strings.ToUpper(execOutput.Type)), capitalize(label), execOutput.AgentName)
 	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-	fmt.Printf("Execution: %s\n", output.ExecutionID)
-	fmt.Printf("Status:    %s %s\n", getExecutionStatusIcon(output.Status), output.Status)
+	fmt.Printf("Execution: %s\n", execOutput.ExecutionID)
+	fmt.Printf("Status:    %s %s\n", getExecutionStatusIcon(execOutput.Status), execOutput.Status)
 
-	if output.SdkSessionID != nil && *output.SdkSessionID != "" {
-		fmt.Printf("Session:   %s\n", *output.SdkSessionID)
+	if execOutput.SdkSessionID != nil && *execOutput.SdkSessionID != "" {
+		fmt.Printf("Session:   %s\n", *execOutput.SdkSessionID)
 	}
 
-	if output.StartedAt != nil {
-		fmt.Printf("Started:   %s\n", output.StartedAt.Format("2006-01-02 15:04:05"))
+	if execOutput.StartedAt != nil {
+		fmt.Printf("Started:   %s\n", execOutput.StartedAt.Format("2006-01-02 15:04:05"))
 	}
-	if output.CompletedAt != nil {
-		fmt.Printf("Completed: %s\n", output.CompletedAt.Format("2006-01-02 15:04:05"))
+	if execOutput.CompletedAt != nil {
+		fmt.Printf("Completed: %s\n", execOutput.CompletedAt.Format("2006-01-02 15:04:05"))
 	}
-	if output.DurationMs != nil {
-		fmt.Printf("Duration:  %dms\n", *output.DurationMs)
+	if execOutput.DurationMs != nil {
+		fmt.Printf("Duration:  %dms\n", *execOutput.DurationMs)
 	}
 
-	if output.AgentBranch != "" {
-		fmt.Printf("Branch:    %s\n", output.AgentBranch)
+	if execOutput.AgentBranch != "" {
+		fmt.Printf("Branch:    %s\n", execOutput.AgentBranch)
 	}
-	if output.PrUrl != "" {
-		fmt.Printf("PR:        %s\n", output.PrUrl)
+	if execOutput.PrUrl != "" {
+		fmt.Printf("PR:        %s\n", execOutput.PrUrl)
 	}
 
-	if output.ErrorMessage != "" {
+	if execOutput.ErrorMessage != "" {
 		fmt.Println()
 		fmt.Println("Error:")
-		fmt.Printf("  %s\n", output.ErrorMessage)
+		fmt.Printf("  %s\n", execOutput.ErrorMessage)
 	}
 
-	if output.Result != nil && len(output.Result) > 0 {
+	if execOutput.Result != nil && len(execOutput.Result) > 0 {
 		fmt.Println()
 		fmt.Println("Result:")
-		data, _ := json.MarshalIndent(output.Result, "  ", "  ")
+		data, _ := json.MarshalIndent(execOutput.Result, "  ", "  ")
 		fmt.Printf("  %s\n", string(data))
 	}
 
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/agents"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	agentsLintConfig string
+	agentsLintFailOn string
+	agentsLintFix    bool
+)
+
+var agentsLintCmd = &cobra.Command{
+	Use:   "lint [path...]",
+	Short: "Check local agent prompts for secrets and risky tool grants",
+	Long: `Lint agent markdown files before they are deployed.
+
+Checks:
+  embedded-secret             API keys, tokens or private keys in the prompt (error)
+  invalid-frontmatter         frontmatter that is not valid YAML (error)
+  missing-frontmatter         no frontmatter at all (warning, fixable)
+  invalid-name                missing or non-kebab-case name (warning, fixable)
+  missing-description         no description (warning)
+  unknown-model               model not in the known list (error, fixable for typos)
+  invalid-tools               malformed tool names, empty or duplicate entries (error/warning)
+  unsupported-mcp             MCP servers other than DataGen that datagen.toml does not connect (warning)
+  broad-tools                 no tools listed, so every tool is inherited (warning)
+  unconstrained-execute-code  executeCode granted but never scoped in the prompt (warning)
+  injection-prone             prompts that let request content steer the agent (warning)
+  long-prompt                 prompts over 3000 words (warning)
+  missing-guardrails          no Guardrails/Constraints section (info)
+
+Paths may be files or directories of .md files. Without arguments, lints
+.claude/agents and the prompts referenced by datagen.toml, taking executeCode
+grants and extra MCP servers from each service.
+
+--fix rewrites the frontmatter to correct fixable findings and reports what
+is left. The command exits non-zero when a finding is at or above --fail-on,
+so it can gate CI.
+
+Examples:
+  datagen agents lint
+  datagen agent lint prompts/support.md
+  datagen agents lint --fix
+  datagen agents lint --fail-on warning`,
+	RunE: runAgentsLint,
+}
+
+func init() {
+	agentsLintCmd.Flags().StringVarP(&agentsLintConfig, "config", "c", "datagen.toml", "Path to datagen.toml configuration file")
+	agentsLintCmd.Flags().StringVar(&agentsLintFailOn, "fail-on", "error", "Lowest severity that fails the run: info, warning, error, or none")
+	agentsLintCmd.Flags().BoolVar(&agentsLintFix, "fix", false, "Correct mechanical issues (frontmatter name, model typos, duplicate tools) in place")
+
+	agentsCmd.AddCommand(agentsLintCmd)
+}
+
+func runAgentsLint(cmd *cobra.Command, args []string) error {
+	var failOn agents.Severity
+	if agentsLintFailOn != "none" {
+		sev, err := agents.ParseSeverity(agentsLintFailOn)
+		if err != nil {
+			return fmt.Errorf("invalid --fail-on: %w", err)
+		}
+		failOn = sev
+	}
+
+	targets, err := lintTargets(args)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		fmt.Println("No agent prompts found.")
+		return nil
+	}
+	cmd.SilenceUsage = true
+
+	paths := make([]string, 0, len(targets))
+	for p := range targets {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var all []agents.Finding
+	for _, p := range paths {
+		if agentsLintFix {
+			fixed, err := agents.Fix(p, targets[p])
+			if err != nil {
+				return fmt.Errorf("fix %s: %w", p, err)
+			}
+			for _, f := range fixed {
+				output.Printf("fixed %s\n", f)
+			}
+		}
+		findings, err := agents.Lint(p, targets[p])
+		if err != nil {
+			return fmt.Errorf("lint %s: %w", p, err)
+		}
+		for _, f := range findings {
+			fmt.Println(f)
+		}
+		all = append(all, findings...)
+	}
+
+	counts := map[agents.Severity]int{}
+	failing, fixable := 0, 0
+	for _, f := range all {
+		counts[f.Severity]++
+		if f.Fixable {
+			fixable++
+		}
+		if failOn != 0 && f.Severity >= failOn {
+			failing++
+		}
+	}
+	fmt.Printf("\n%d file(s): %d error(s), %d warning(s), %d info\n",
+		len(paths), counts[agents.SeverityError], counts[agents.SeverityWarning], counts[agents.SeverityInfo])
+	if fixable > 0 {
+		fmt.Printf("%d finding(s) can be fixed with --fix\n", fixable)
+	}
+
+	if failing > 0 {
+		return fmt.Errorf("%d finding(s) at or above %s", failing, failOn)
+	}
+	return nil
+}
+
+// lintTargets maps each prompt file to lint to the grants configured for it
+func lintTargets(args []string) (map[string]agents.LintOptions, error) {
+	targets := map[string]agents.LintOptions{}
+	addPath := func(path string, explicit bool) error {
+		info, err := os.Stat(path)
+		if err != nil {
+			if !explicit && os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() {
+			targets[filepath.Clean(path)] = targets[filepath.Clean(path)]
+			return nil
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(strings.ToLower(e.Name()), ".md") {
+				p := filepath.Join(path, e.Name())
+				targets[p] = targets[p]
+			}
+		}
+		return nil
+	}
+
+	if len(args) > 0 {
+		for _, a := range args {
+			if err := addPath(a, true); err != nil {
+				return nil, err
+			}
+		}
+		return targets, nil
+	}
+
+	if err := addPath(filepath.Join(".claude", "agents"), false); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(agentsLintConfig); err != nil {
+		return targets, nil
+	}
+	cfg, err := config.LoadConfig(agentsLintConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error loading config: %w", err)
+	}
+	configDir := filepath.Dir(agentsLintConfig)
+	for _, svc := range cfg.Services {
+		for _, prompt := range svc.PromptFiles() {
+			if !filepath.IsAbs(prompt) {
+				prompt = filepath.Join(configDir, prompt)
+			}
+			prompt = filepath.Clean(prompt)
+			opts := targets[prompt]
+			opts.ExecuteCode = opts.ExecuteCode || svc.Tools().ExecuteCode
+			for _, m := range svc.MCPServers {
+				opts.MCPServers = append(opts.MCPServers, m.Name)
+			}
+			targets[prompt] = opts
+		}
+	}
+	return targets, nil
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/datagendev/datagen-cli/internal/agents"
+	"github.com/datagendev/datagen-cli/internal/debuglog"
+	"github.com/datagendev/datagen-cli/internal/diagnose"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/prompts"
+	"github.com/spf13/cobra"
+)
+
+var (
+	agentsNewName  string
+	agentsNewModel string
+	agentsNewForce bool
+	agentsNewPrint bool
+	agentsNewStart bool
+)
+
+var agentsNewCmd = &cobra.Command{
+	Use:   "new <description>",
+	Short: "Draft an agent file from a plain-language description",
+	Long: `Ask Claude to draft a .claude/agents/<name>.md from a description of what the
+agent should do. The draft has frontmatter (name, description, tools, model)
+and Role, Input, Steps, Output and Constraints sections, and is linted
+before it is saved.
+
+The Claude key is read from the environment or .env, using claude_api_key_env
+from datagen.toml (default ANTHROPIC_API_KEY).
+
+After saving, datagen offers to run 'datagen start' on the new agent; pass
+--start to do so without asking.
+
+Examples:
+  datagen agent new "summarize inbound support emails and tag priority"
+  datagen agents new "enrich a lead from its email" --name lead-enricher --start
+  datagen agents new "classify refund requests" --print > refund.md`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAgentsNew,
+}
+
+func init() {
+	agentsNewCmd.Flags().StringVar(&agentsNewName, "name", "", "Agent name (default: chosen by Claude)")
+	agentsNewCmd.Flags().StringVar(&agentsNewModel, "model", diagnose.DefaultModel, "Claude model that drafts the agent")
+	agentsNewCmd.Flags().BoolVar(&agentsNewForce, "force", false, "Overwrite an existing agent file")
+	agentsNewCmd.Flags().BoolVar(&agentsNewPrint, "print", false, "Print the draft instead of saving it")
+	agentsNewCmd.Flags().BoolVar(&agentsNewStart, "start", false, "Run 'datagen start' on the new agent without asking")
+
+	agentsCmd.AddCommand(agentsNewCmd)
+}
+
+func runAgentsNew(cmd *cobra.Command, args []string) error {
+	if agentsNewPrint && agentsNewStart {
+		return fmt.Errorf("--print cannot be combined with --start")
+	}
+	cmd.SilenceUsage = true
+
+	apiKey, err := findClaudeKey(".", "datagen.toml")
+	if err != nil {
+		return err
+	}
+
+	output.Println("✍️  Drafting agent with Claude...")
+	reply, err := diagnose.New(apiKey, agentsNewModel).Ask(context.Background(), agents.DraftSystemPrompt, agents.DraftRequest(args[0], agentsNewName), 4096)
+	if err != nil {
+		return err
+	}
+	content, name, err := agents.ParseDraft(reply, agentsNewName)
+	if err != nil {
+		return fmt.Errorf("%w\n\nClaude replied:\n%s", err, reply)
+	}
+
+	if agentsNewPrint {
+		fmt.Print(string(content))
+		return nil
+	}
+
+	path := filepath.Join(".claude", "agents", name+".md")
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) && !agentsNewForce {
+		return fmt.Errorf("%s already exists (use --force to overwrite, or --name to pick another name)", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create agents directory: %w", err)
+	}
+	debuglog.FileWrite(path, content)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	output.Printf("✅ Saved %s\n", path)
+
+	findings, err := agents.Lint(path, agents.LintOptions{})
+	if err != nil {
+		return err
+	}
+	for _, f := range findings {
+		fmt.Printf("  %s\n", f)
+	}
+	if len(findings) > 0 {
+		fmt.Println("  Review the draft before deploying it.")
+	}
+
+	start := agentsNewStart
+	if !start && stdinIsTerminal() {
+		if err := prompts.AskOne(&survey.Confirm{
+			Message: "Set up a project for this agent now (datagen start)?",
+			Default: true,
+		}, &start); err != nil {
+			return err
+		}
+	}
+	if !start {
+		fmt.Println("\n📝 Next steps:")
+		fmt.Printf("  1. Review and edit %s\n", path)
+		fmt.Printf("  2. Run 'datagen start --agent %s' to create datagen.toml\n", name)
+		return nil
+	}
+
+	fmt.Println()
+	startAgents, startOutputDir = []string{name}, "."
+	return runStartFromExistingAgents()
+}
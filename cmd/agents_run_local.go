@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/datagendev/datagen-cli/internal/agents"
+	"github.com/datagendev/datagen-cli/internal/auth"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/localrun"
+	"github.com/datagendev/datagen-cli/internal/output"
+)
+
+var (
+	agentsRunLocal    bool
+	agentsRunDataFile string
+	agentsRunModel    string
+	agentsRunMaxTurns int
+	agentsRunConfig   string
+	agentsRunDirs     []string
+)
+
+func init() {
+	agentsRunCmd.Flags().BoolVar(&agentsRunLocal, "local", false, "Run a .claude/agents file on this machine with the Claude Agent SDK instead of the deployed agent")
+	agentsRunCmd.Flags().StringVar(&agentsRunDataFile, "data-file", "", "Read the JSON payload from a file (with --local)")
+	agentsRunCmd.Flags().StringVar(&agentsRunModel, "model", "", "Override the agent's model (with --local)")
+	agentsRunCmd.Flags().IntVar(&agentsRunMaxTurns, "max-turns", 0, "Stop after this many agent turns (with --local; 0 = no limit)")
+	agentsRunCmd.Flags().StringVarP(&agentsRunConfig, "config", "c", "datagen.toml", "datagen.toml with the service's extra MCP servers (with --local)")
+	agentsRunCmd.Flags().StringSliceVar(&agentsRunDirs, "agents-dir", nil, "Also discover agents in this directory (with --local; repeatable)")
+}
+
+// runAgentsRunLocal runs the agent file named by arg (a path, or the name or
+// filename of an agent, skill or command under .claude) against the payload,
+// streaming the reply to stdout and tool calls to stderr
+func runAgentsRunLocal(arg string) error {
+	path, err := resolveLocalAgent(arg)
+	if err != nil {
+		return err
+	}
+	prompt, err := agents.LoadPrompt(path)
+	if err != nil {
+		return err
+	}
+
+	payload, err := localRunPayload()
+	if err != nil {
+		return err
+	}
+
+	claudeKey, err := findClaudeKey(".", agentsRunConfig)
+	if err != nil {
+		return err
+	}
+	opts := localrun.Options{Model: agentsRunModel, MaxTurns: agentsRunMaxTurns}
+	if key, _, ok := auth.FindEnvVarOrProfile("DATAGEN_API_KEY"); ok {
+		opts.DatagenAPIKey = key
+	} else {
+		output.Warnf("DATAGEN_API_KEY not found; running without DataGen MCP tools (run 'datagen login')")
+	}
+	opts.MCPServers = serviceMCPServers(path)
+
+	spec, err := localrun.BuildSpec(prompt, payload, opts)
+	if err != nil {
+		return err
+	}
+	python, err := localrun.FindPython()
+	if err != nil {
+		return err
+	}
+
+	output.Printf("▶️  Running %s locally (%s)\n", prompt.Name, spec.Model)
+	if len(spec.AllowedTools) > 0 {
+		output.Printf("   Tools: %s\n", strings.Join(spec.AllowedTools, ", "))
+	}
+	output.Println()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	names := map[string]string{}
+	endsWithNewline := true
+	err = localrun.Run(ctx, python, spec, []string{"ANTHROPIC_API_KEY=" + claudeKey}, os.Stderr, func(ev localrun.Event) {
+		switch ev.Type {
+		case "text":
+			fmt.Print(ev.Text)
+			endsWithNewline = strings.HasSuffix(ev.Text, "\n")
+		case "tool_use":
+			if !endsWithNewline {
+				fmt.Println()
+				endsWithNewline = true
+			}
+			names[ev.ID] = ev.Name
+			input, _ := json.Marshal(ev.Input)
+			fmt.Fprintf(os.Stderr, "🔧 %s %s\n", ev.Name, truncate(string(input), 200))
+		case "tool_result":
+			status := "ok"
+			if ev.IsError {
+				status = "error"
+			}
+			fmt.Fprintf(os.Stderr, "   ↳ %s %s: %s\n", names[ev.ID], status, truncate(ev.Text, 200))
+		case "result":
+			if !endsWithNewline {
+				fmt.Println()
+			}
+			output.Printf("\n✅ Finished in %.1fs, %d turn(s), $%.4f\n", float64(ev.DurationMs)/1000, ev.NumTurns, ev.CostUSD)
+		}
+	})
+	return err
+}
+
+// resolveLocalAgent finds the agent file for a path or an agent name in the
+// discovery roots
+func resolveLocalAgent(arg string) (string, error) {
+	if info, err := os.Stat(arg); err == nil && !info.IsDir() {
+		return arg, nil
+	}
+	found, err := agents.DiscoverRoots(agents.Roots(".", agentsRunDirs...))
+	if err != nil {
+		return "", err
+	}
+	if len(found) == 0 {
+		return "", fmt.Errorf("%s is not a file and no agents, skills or commands were found in .claude or ~/.claude", arg)
+	}
+	a, err := chooseAgent(found, arg)
+	if err != nil {
+		return "", err
+	}
+	return a.Path, nil
+}
+
+func localRunPayload() (any, error) {
+	data := []byte(agentsRunPayload)
+	source := "--payload"
+	if agentsRunDataFile != "" {
+		b, err := os.ReadFile(agentsRunDataFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --data-file: %w", err)
+		}
+		data, source = b, agentsRunDataFile
+	}
+	var payload any
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("invalid JSON in %s: %w", source, err)
+	}
+	return payload, nil
+}
+
+// serviceMCPServers returns the extra MCP servers of the datagen.toml service
+// whose prompt is path, if any
+func serviceMCPServers(path string) []config.MCPServer {
+	if _, err := os.Stat(agentsRunConfig); err != nil {
+		return nil
+	}
+	cfg, err := config.LoadConfig(agentsRunConfig)
+	if err != nil {
+		output.Warnf("ignoring %s: %v", agentsRunConfig, err)
+		return nil
+	}
+	configDir := filepath.Dir(agentsRunConfig)
+	for _, svc := range cfg.Services {
+		for _, prompt := range svc.PromptFiles() {
+			if samePath(filepath.Join(configDir, prompt), path) {
+				return svc.MCPServers
+			}
+		}
+	}
+	return nil
+}
+
+// truncate shortens s to one line of at most n characters
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "…"
+}
@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/datagendev/datagen-cli/internal/agents"
+	"github.com/datagendev/datagen-cli/internal/codegen"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/debuglog"
+	"github.com/datagendev/datagen-cli/internal/gitrepo"
+	"github.com/datagendev/datagen-cli/internal/models"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/summary"
+	"github.com/datagendev/datagen-cli/internal/telemetry"
+	"github.com/datagendev/datagen-cli/internal/undo"
+	"github.com/spf13/cobra"
+)
+
+var (
+	buildOutputDir  string
+	buildConfigPath string
+	buildSummary    string
+	buildFiles      []string
+	buildGit        bool
+)
+
+var buildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Generate a FastAPI project from datagen.toml",
+	Long: `Parse and validate datagen.toml, generate the FastAPI project into the output
+directory, and copy each service's agent prompt next to it.
+
+--file regenerates only the named generated files (plus the files derived from
+the same settings, e.g. app/config.py with .env.example) and leaves the rest of
+the project, including customized app code, untouched.
+
+Files are generated concurrently and only rewritten when their content
+changed, so unchanged files keep their modification times (and Docker layer
+caches stay warm); the created, updated and unchanged counts are reported.
+
+A .gitignore (secrets, virtualenvs, datagen and Railway state) and a
+.dockerignore are written when the project has none; existing ones are kept.
+--git also runs git init and commits the project, unless it already belongs to
+a repository. Nothing is committed while an env file other than .env.example is
+not ignored.
+
+A summary of each step, its duration, and the next steps is printed at the end;
+--summary json prints it as JSON instead (and nothing else on stdout).
+
+Examples:
+  datagen build
+  datagen build --config ./my-project/datagen.toml --output ./out
+  datagen build --summary json
+  datagen build --output ./out --git
+  datagen build --file Dockerfile
+  datagen build --file .env.example --file railway.json
+  datagen build --file .do/app.yaml   # DigitalOcean App Platform spec (on demand only)`,
+	Args: cobra.NoArgs,
+	RunE: runBuild,
+}
+
+func init() {
+	buildCmd.Flags().StringVarP(&buildOutputDir, "output", "o", ".", "Directory for generated files")
+	buildCmd.Flags().StringVarP(&buildConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml configuration file")
+	buildCmd.Flags().StringVar(&buildSummary, "summary", "table", "Final summary format: table, json, or none")
+	buildCmd.Flags().StringSliceVar(&buildFiles, "file", nil, "Regenerate only these generated files (e.g. Dockerfile, requirements.txt); repeatable")
+	buildCmd.Flags().BoolVar(&buildGit, "git", false, "Run git init and commit the generated project, unless it is already in a repository")
+	buildCmd.MarkFlagDirname("output")
+	buildCmd.MarkFlagFilename("config", "toml")
+}
+
+func runBuild(cmd *cobra.Command, args []string) error {
+	if err := summary.ValidateFormat(buildSummary); err != nil {
+		return err
+	}
+	// Keep stdout machine-readable in JSON mode
+	progress := output.Progress()
+	if buildSummary == "json" {
+		progress = io.Discard
+	}
+
+	sum := summary.New("build")
+	err := buildProject(sum, progress)
+	if writeErr := sum.Write(os.Stdout, buildSummary); writeErr != nil && err == nil {
+		err = writeErr
+	}
+	if err != nil {
+		cmd.SilenceUsage = true
+	}
+	return err
+}
+
+func buildProject(sum *summary.Summary, progress io.Writer) error {
+	fmt.Fprintf(progress, "🔨 Building project from %s\n", buildConfigPath)
+
+	var cfg *config.DatagenConfig
+	if err := sum.Run("Load config", func() error {
+		loaded, err := config.LoadConfig(buildConfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading config: %w", err)
+		}
+		cfg = loaded
+		telemetry.TagConfig(cfg)
+		return nil
+	}); err != nil {
+		sum.Skip("Generate project", "config invalid")
+		sum.Skip("Copy prompts", "config invalid")
+		return err
+	}
+
+	if warning := cfg.StateStoreWarning(); warning != "" {
+		output.Warnf("%s\n", warning)
+	}
+
+	if err := sum.Run("Check models", func() error {
+		return checkServiceModels(cfg, filepath.Dir(buildConfigPath), models.Builtin())
+	}); err != nil {
+		sum.Skip("Generate project", "retired model")
+		sum.Skip("Copy prompts", "retired model")
+		return err
+	}
+
+	lock, err := lockProject(buildOutputDir, "build")
+	if err != nil {
+		sum.Skip("Generate project", "project locked")
+		sum.Skip("Copy prompts", "project locked")
+		return err
+	}
+	defer lock.Release()
+
+	if len(buildFiles) > 0 {
+		return buildSelectedFiles(sum, progress, cfg)
+	}
+
+	var generated *codegen.GenerateResult
+	if err := sum.Run("Generate project", func() error {
+		var err error
+		generated, err = codegen.GenerateProject(cfg, buildOutputDir)
+		return err
+	}); err != nil {
+		sum.Skip("Copy prompts", "generation failed")
+		return err
+	}
+	if cfg.PythonFormatter != "" && generated.Formatter == "" {
+		output.Warnf("%s is not installed; the generated Python was not formatted with it\n", cfg.PythonFormatter)
+	}
+
+	if sameDir(filepath.Dir(buildConfigPath), buildOutputDir) {
+		sum.Skip("Copy prompts", "output is the config directory")
+	} else if err := sum.Run("Copy prompts", func() error {
+		return copyPromptFiles(cfg, filepath.Dir(buildConfigPath), buildOutputDir)
+	}); err != nil {
+		return err
+	}
+
+	if lockFile := codegen.LockFile(cfg); lockFile != "" {
+		tool := cfg.Packaging()
+		if _, err := exec.LookPath(tool); err != nil {
+			sum.Skip("Lock dependencies", tool+" not installed")
+			output.Warnf("%s is not installed; run '%s lock' in %s, since until %s exists images resolve dependency versions at build time\n", tool, tool, buildOutputDir, lockFile)
+		} else if err := sum.Run("Lock dependencies", func() error {
+			return codegen.LockDependencies(cfg, buildOutputDir)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if buildGit {
+		if err := initGitRepo(sum, buildOutputDir); err != nil {
+			return err
+		}
+	}
+
+	absOut, _ := filepath.Abs(buildOutputDir)
+	debuglog.Logf("generated %d service(s) from %s into %s", len(cfg.Services), buildConfigPath, absOut)
+	fmt.Fprintf(progress, "✅ Generated %d service(s) into %s (%s)\n", len(cfg.Services), absOut, generated)
+
+	sum.Next(
+		fmt.Sprintf("cd %s && %s", absOut, codegen.InstallCommand(cfg)),
+		"cp .env.example .env   # then fill in your keys",
+		"uvicorn app.main:app --reload",
+	)
+	if len(cfg.Services) > 0 {
+		sum.Next(fmt.Sprintf("datagen invoke %s --config %s", cfg.Services[0].Name, buildConfigPath))
+	}
+	return nil
+}
+
+// buildSelectedFiles regenerates only --file targets and their companions
+func buildSelectedFiles(sum *summary.Summary, progress io.Writer, cfg *config.DatagenConfig) error {
+	paths, err := codegen.ExpandGeneratedFiles(buildFiles)
+	if err != nil {
+		sum.Skip("Generate files", "unknown file")
+		return err
+	}
+	for _, p := range paths {
+		if p == "app/main.py" || p == "app/agent.py" {
+			output.Warnf("regenerating %s overwrites any customizations in it\n", p)
+		}
+	}
+
+	var written []string
+	if err := sum.Run(fmt.Sprintf("Generate %d file(s)", len(paths)), func() error {
+		written, err = codegen.GenerateFiles(cfg, buildOutputDir, buildFiles)
+		return err
+	}); err != nil {
+		return err
+	}
+	sum.Skip("Copy prompts", "--file")
+
+	for _, p := range written {
+		fmt.Fprintf(progress, "  ✓ %s\n", p)
+	}
+	return nil
+}
+
+// initGitRepo makes dir a git repository with everything in it committed,
+// unless it already belongs to one
+func initGitRepo(sum *summary.Summary, dir string) error {
+	if gitrepo.Inside(dir) {
+		sum.Skip("Initialize git", "already a git repository")
+		return nil
+	}
+	return sum.Run("Initialize git", func() error {
+		return gitrepo.Init(dir, "Initial datagen project")
+	})
+}
+
+func sameDir(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// copyPromptFiles copies each service's prompts (including per-locale prompts
+// and pipeline steps) from the config directory into the output directory at
+// the same relative path, where the app loads them from
+func copyPromptFiles(cfg *config.DatagenConfig, configDir, outputDir string) error {
+	for _, svc := range cfg.Services {
+		for _, prompt := range svc.PromptFiles() {
+			if err := copyPromptFile(svc.Name, prompt, configDir, outputDir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func copyPromptFile(service, prompt, configDir, outputDir string) error {
+	if filepath.IsAbs(prompt) {
+		return fmt.Errorf("service %s: prompt path must be relative to datagen.toml to be copied", service)
+	}
+	if filepath.Base(prompt) == agents.SkillFile {
+		// A skill ships with the scripts and documents next to its SKILL.md
+		if err := copyDir(filepath.Join(configDir, filepath.Dir(prompt)), filepath.Join(outputDir, filepath.Dir(prompt))); err != nil {
+			return fmt.Errorf("service %s: %w", service, err)
+		}
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(configDir, prompt))
+	if err != nil {
+		return fmt.Errorf("service %s: %w", service, err)
+	}
+	dest := filepath.Join(outputDir, prompt)
+	if err := undo.Save(dest); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(dest, data, 0644)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/codegen"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/drift"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	compareConfigPath string
+	compareTimeout    time.Duration
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <url>",
+	Short: "Check a deployed project for drift from local config",
+	Long: `Fetch /version and /openapi.json from a deployed project and compare them with
+the local datagen.toml and generated templates.
+
+Reports missing or extra services, input schema differences, and CLI/template
+version mismatches. Exits non-zero when drift is found so it can gate CI.
+
+Example:
+  datagen compare https://my-agents.up.railway.app`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCompare,
+}
+
+func init() {
+	compareCmd.Flags().StringVarP(&compareConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml configuration file")
+	compareCmd.Flags().DurationVar(&compareTimeout, "timeout", 10*time.Second, "HTTP timeout for each request")
+	compareCmd.MarkFlagFilename("config", "toml")
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	baseURL := strings.TrimRight(args[0], "/")
+
+	cfg, err := config.LoadConfig(compareConfigPath)
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	local, err := codegen.BuildProjectMetadata(cfg)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: compareTimeout}
+
+	var remote drift.RemoteVersion
+	if err := fetchJSON(client, baseURL+"/version", &remote); err != nil {
+		return fmt.Errorf("failed to fetch /version (was the project generated with a CLI that serves it?): %w", err)
+	}
+
+	var openapi *drift.OpenAPI
+	var doc drift.OpenAPI
+	if err := fetchJSON(client, baseURL+"/openapi.json", &doc); err != nil {
+		fmt.Printf("⚠️  Skipping schema comparison: %v\n", err)
+	} else {
+		openapi = &doc
+	}
+
+	output.Printf("🔍 Comparing %s with %s\n", baseURL, compareConfigPath)
+	if remote.GitCommit != "" {
+		fmt.Printf("   Deployed commit: %s\n", remote.GitCommit)
+	}
+
+	findings := drift.Compare(cfg, local, &remote, openapi)
+	if len(findings) == 0 {
+		output.Println("✅ No drift detected")
+		return nil
+	}
+
+	fmt.Printf("\n❌ Drift detected (%d):\n", len(findings))
+	for _, f := range findings {
+		fmt.Printf("   - %s\n", f)
+	}
+	fmt.Println("\nRegenerate and redeploy to bring the deployment in line with local config.")
+	return fmt.Errorf("deployment has drifted from local config")
+}
+
+func fetchJSON(client *http.Client, url string, out any) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned HTTP %d", url, resp.StatusCode)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/datagendev/datagen-cli/internal/codegen"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/deps"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	depsConfigPath string
+	depsOutputDir  string
+	depsDryRun     bool
+)
+
+var depsCmd = &cobra.Command{
+	Use:   "deps",
+	Short: "Manage the generated project's Python dependencies",
+	Long: `Generated projects depend on FastAPI, the Claude Agent SDK and a few other
+packages. By default requirements.txt (or pyproject.toml) allows patch releases
+of a known-good version (~=0.115.0), so two builds a month apart can install
+different code. Pinning exact versions in datagen.toml makes builds repeatable.
+
+Examples:
+  datagen deps refresh
+  datagen deps refresh --dry-run`,
+}
+
+var depsRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Pin the runtime's packages to their latest releases on PyPI",
+	Long: `Look up the latest release of every package the generated project needs on
+PyPI and pin each to that exact version: the pins go in the [dependencies] table
+of datagen.toml, so later builds keep them, and requirements.txt (or
+pyproject.toml and its lockfile) is rewritten in the output directory.
+
+Upgrades that may break the generated code are listed with a link to the
+release notes: a new major version of fastapi or claude-agent-sdk, or a new
+minor version while it is still 0.x. Rebuild and run your evals before
+deploying them.
+
+Examples:
+  datagen deps refresh
+  datagen deps refresh --dry-run
+  datagen deps refresh -o ./service`,
+	Args: cobra.NoArgs,
+	RunE: runDepsRefresh,
+}
+
+func init() {
+	depsRefreshCmd.Flags().StringVarP(&depsConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml configuration file")
+	depsRefreshCmd.Flags().StringVarP(&depsOutputDir, "output", "o", ".", "Directory of the generated project")
+	depsRefreshCmd.Flags().BoolVar(&depsDryRun, "dry-run", false, "Report the latest versions without writing anything")
+	depsRefreshCmd.MarkFlagFilename("config", "toml")
+	depsCmd.AddCommand(depsRefreshCmd)
+}
+
+func runDepsRefresh(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	cfg, err := config.LoadConfig(depsConfigPath)
+	if err != nil {
+		return err
+	}
+	reqs := codegen.Requirements(cfg)
+	output.Printf("Resolving %d package(s) on PyPI...\n", len(reqs))
+	latest, err := resolveLatest(context.Background(), deps.New(), reqs)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PACKAGE\tCURRENT\tLATEST")
+	changed := 0
+	for i, r := range reqs {
+		mark := ""
+		if r.Pin != latest[i] {
+			changed++
+			mark = " ↑"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s%s\n", r.Name, r.String()[len(r.Name+r.Extras):], latest[i], mark)
+	}
+	w.Flush()
+
+	var breaking []string
+	for i, r := range reqs {
+		notes, watched := deps.ReleaseNotes[r.Name]
+		if watched && deps.Breaking(r.Version(), latest[i]) {
+			breaking = append(breaking, fmt.Sprintf("  ! %s %s → %s: review %s", r.Name, r.Version(), latest[i], notes))
+		}
+	}
+	if len(breaking) > 0 {
+		fmt.Println("\nBreaking-change candidates (rebuild and test before deploying):")
+		for _, line := range breaking {
+			fmt.Println(line)
+		}
+	}
+
+	if changed == 0 {
+		output.Printf("\n✓ All %d package(s) are pinned to their latest releases\n", len(reqs))
+		return nil
+	}
+	if depsDryRun {
+		output.Printf("\nDry run: %d pin(s) would change; nothing was written\n", changed)
+		return nil
+	}
+
+	if cfg.Dependencies == nil {
+		cfg.Dependencies = map[string]string{}
+	}
+	for i, r := range reqs {
+		cfg.Dependencies[r.Name] = latest[i]
+	}
+	if err := config.SaveConfig(cfg, depsConfigPath); err != nil {
+		return err
+	}
+	file, err := codegen.GenerateFile(cfg, depsOutputDir, codegen.DependencyFile(cfg))
+	if err != nil {
+		return err
+	}
+	output.Printf("\n✓ Pinned %d package(s) in %s and wrote %s\n", len(reqs), depsConfigPath, filepath.Join(depsOutputDir, file))
+
+	if lockFile := codegen.LockFile(cfg); lockFile != "" {
+		tool := cfg.Packaging()
+		if _, err := exec.LookPath(tool); err != nil {
+			output.Warnf("%s is not installed; run '%s lock' in %s to update %s\n", tool, tool, depsOutputDir, lockFile)
+			return nil
+		}
+		if err := codegen.LockDependencies(cfg, depsOutputDir); err != nil {
+			return err
+		}
+		output.Printf("✓ Updated %s\n", filepath.Join(depsOutputDir, lockFile))
+	}
+	return nil
+}
+
+// resolveLatest looks up every requirement concurrently and returns the
+// latest versions in the same order
+func resolveLatest(ctx context.Context, client *deps.Client, reqs []codegen.Requirement) ([]string, error) {
+	latest := make([]string, len(reqs))
+	errs := make([]error, len(reqs))
+	var wg sync.WaitGroup
+	for i, r := range reqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			latest[i], errs[i] = client.Latest(ctx, r.Name)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/datagendev/datagen-cli/internal/deploy"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/prompts"
+	"github.com/datagendev/datagen-cli/internal/railway"
+	"github.com/datagendev/datagen-cli/internal/state"
+	"github.com/datagendev/datagen-cli/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	destroyDir         string
+	destroyYes         bool
+	destroyKeepProject bool
+)
+
+var destroyCmd = &cobra.Command{
+	Use:   "destroy",
+	Short: "Tear down the platform project for a generated project",
+	Long: `Delete the Railway project (or only its service) that the project directory is
+linked to. The project is taken from the deployment record in .datagen/state.json
+when there is one, otherwise from the Railway CLI link (~/.railway/config.json).
+RAILWAY_API_TOKEN or your 'railway login' session is used to authenticate.
+
+Examples:
+  datagen destroy                  # Delete the whole Railway project
+  datagen destroy --keep-project   # Delete only the linked service
+  datagen destroy --yes            # Skip confirmation (CI/automation)`,
+	RunE: runDestroy,
+}
+
+func init() {
+	destroyCmd.Flags().StringVarP(&destroyDir, "output", "o", ".", "Project directory linked to the platform")
+	destroyCmd.Flags().BoolVarP(&destroyYes, "yes", "y", false, "Skip confirmation prompt")
+	destroyCmd.Flags().BoolVar(&destroyKeepProject, "keep-project", false, "Keep the Railway project and delete only the linked service")
+}
+
+func runDestroy(cmd *cobra.Command, args []string) error {
+	project, err := destroyTarget(destroyDir)
+	if err != nil {
+		return err
+	}
+	if destroyKeepProject && project.Service == "" {
+		return fmt.Errorf("no service is linked for %s (run 'railway service' to pick one)", project.Dir)
+	}
+
+	target := fmt.Sprintf("Railway project %q (%s)", project.Name, project.ID)
+	if destroyKeepProject {
+		target = fmt.Sprintf("service %s in Railway project %q", project.Service, project.Name)
+	}
+
+	if !destroyYes {
+		confirm := false
+		if err := prompts.AskOne(&survey.Confirm{
+			Message: fmt.Sprintf("Permanently delete %s? This cannot be undone.", target),
+			Default: false,
+		}, &confirm); err != nil {
+			return err
+		}
+		if !confirm {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+
+	telemetry.Tag("platform", "railway")
+	output.Printf("🗑️  Deleting %s...\n", target)
+	if err := deploy.NewRailway().Destroy(project, destroyKeepProject); err != nil {
+		return err
+	}
+
+	output.Println("✅ Teardown complete")
+	if err := state.RemoveDeployment(destroyDir); err != nil {
+		output.Warnf("could not remove %s: %v\n", state.DeploymentFile, err)
+	}
+	if !destroyKeepProject {
+		forgetProjectLink(project.Dir)
+		fmt.Println("   Run 'railway unlink' to clear the local link.")
+	}
+	return nil
+}
+
+// destroyTarget prefers the deployment record in .datagen/state.json, which names
+// exactly what datagen manages, and falls back to the Railway CLI link
+func destroyTarget(dir string) (*deploy.Project, error) {
+	dep, err := state.LoadDeployment(dir)
+	if err != nil {
+		return nil, err
+	}
+	if dep == nil || dep.Platform != "railway" || dep.ProjectID == "" {
+		link, err := railway.FindLinkedProject(dir)
+		if err != nil {
+			return nil, err
+		}
+		return deploy.ProjectFromLink(link), nil
+	}
+
+	fmt.Printf("Using deployment record %s\n", state.DeploymentFile)
+	return &deploy.Project{
+		Dir:         dir,
+		Name:        dep.ProjectName,
+		ID:          dep.ProjectID,
+		Environment: dep.EnvironmentID,
+		Service:     dep.ServiceID,
+	}, nil
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/diagnose"
+	"github.com/datagendev/datagen-cli/internal/dotenv"
+	"github.com/datagendev/datagen-cli/internal/prompts"
+	"github.com/datagendev/datagen-cli/internal/transcript"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diagnoseDir    string
+	diagnoseConfig string
+	diagnoseModel  string
+)
+
+var diagnoseCmd = &cobra.Command{
+	Use:   "diagnose [log-file]",
+	Short: "Ask Claude why a deploy command failed",
+	Long: `Send the redacted transcript of a failed platform command to Claude and print
+the likely cause and fixes.
+
+Without an argument the newest log in .datagen/logs is used. These logs are
+written whenever a railway invocation fails. The Claude key is read from the
+environment or the project's .env, using claude_api_key_env from datagen.toml
+(default ANTHROPIC_API_KEY).
+
+When a command fails interactively, datagen offers to run this for you.
+
+Examples:
+  datagen diagnose
+  datagen diagnose .datagen/logs/20260102-150405-railway-up.log
+  datagen diagnose --model claude-opus-4-1`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDiagnose,
+}
+
+func init() {
+	diagnoseCmd.Flags().StringVarP(&diagnoseDir, "output", "o", ".", "Project directory containing .datagen/logs")
+	diagnoseCmd.Flags().StringVarP(&diagnoseConfig, "config", "c", "datagen.toml", "Path to datagen.toml configuration file")
+	diagnoseCmd.Flags().StringVar(&diagnoseModel, "model", diagnose.DefaultModel, "Claude model to ask")
+}
+
+func runDiagnose(cmd *cobra.Command, args []string) error {
+	path := ""
+	if len(args) == 1 {
+		path = args[0]
+	} else {
+		latest, err := transcript.Latest(diagnoseDir)
+		if err != nil {
+			return err
+		}
+		path = latest
+	}
+	cmd.SilenceUsage = true
+	return diagnoseLog(path, diagnoseDir, diagnoseConfig)
+}
+
+// diagnoseLog sends the transcript at path to Claude and prints the reply
+func diagnoseLog(path, projectDir, configPath string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read log: %w", err)
+	}
+
+	apiKey, err := findClaudeKey(projectDir, configPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Asking Claude about %s...\n\n", path)
+	answer, err := diagnose.New(apiKey, diagnoseModel).Diagnose(context.Background(), string(data))
+	if err != nil {
+		return err
+	}
+	fmt.Println(answer)
+	return nil
+}
+
+// findClaudeKey reads the Claude API key from the environment, then the
+// project's .env
+func findClaudeKey(projectDir, configPath string) (string, error) {
+	envVar := "ANTHROPIC_API_KEY"
+	if _, err := os.Stat(config.FindConfig(configPath)); err == nil {
+		if cfg, err := config.LoadConfig(configPath); err == nil {
+			envVar = cfg.ClaudeAPIKeyEnv
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv(envVar)); v != "" {
+		return v, nil
+	}
+	if values, err := dotenv.Load(filepath.Join(projectDir, ".env")); err == nil {
+		if v := strings.TrimSpace(values[envVar]); v != "" {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("%s not found in the environment or %s", envVar, filepath.Join(projectDir, ".env"))
+}
+
+// offerDiagnosis asks whether to diagnose a failed platform command when its
+// transcript was saved and the CLI is attached to a terminal
+func offerDiagnosis(err error) {
+	var failure *transcript.Failure
+	if !errors.As(err, &failure) || !stdinIsTerminal() {
+		return
+	}
+
+	ask := false
+	if promptErr := prompts.AskOne(&survey.Confirm{
+		Message: "Ask Claude to diagnose this failure?",
+		Default: false,
+	}, &ask); promptErr != nil || !ask {
+		return
+	}
+
+	// Transcripts live in <project>/.datagen/logs
+	projectDir := filepath.Dir(filepath.Dir(filepath.Dir(failure.Path)))
+	configPath := filepath.Join(projectDir, "datagen.toml")
+	fmt.Println()
+	if diagErr := diagnoseLog(failure.Path, projectDir, configPath); diagErr != nil {
+		fmt.Fprintf(os.Stderr, "Diagnosis failed: %v\n", diagErr)
+	}
+}
+
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/datagendev/datagen-cli/internal/doctor"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorConfigPath string
+	doctorOffline    bool
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check your environment for common setup problems",
+	Long: `Check what datagen and generated projects need and suggest a fix for each
+problem:
+
+- Python 3.10+ for running generated apps locally
+- Railway, Fly and Google Cloud CLIs for deploys
+- DATAGEN_API_KEY resolution and validity
+- agent prompts in .claude/agents
+- datagen.toml validity
+- the markers 'datagen add' injects between in app/main.py and app/models.py
+- the app, packaging and platform files a deploy needs, per the build manifest
+- network access to mcp.datagen.dev
+
+Exits non-zero when a check fails; warnings do not fail.
+
+Examples:
+  datagen doctor
+  datagen doctor --offline
+  datagen doctor -c services/datagen.toml`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().StringVarP(&doctorConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml configuration file")
+	doctorCmd.Flags().BoolVar(&doctorOffline, "offline", false, "Skip checks that need the network (key validation, reachability)")
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	output.Println("🩺 Checking your datagen setup...")
+	fmt.Println()
+
+	failed := 0
+	for _, r := range doctor.Run(doctor.Options{Dir: ".", ConfigPath: doctorConfigPath, Offline: doctorOffline}) {
+		icon := map[doctor.Status]string{doctor.OK: "✓", doctor.Warn: "!", doctor.Fail: "✗", doctor.Skip: "-"}[r.Status]
+		fmt.Printf("  %s %-18s %s\n", icon, r.Name, r.Detail)
+		if r.Fix != "" {
+			fmt.Printf("    %-18s → %s\n", "", r.Fix)
+		}
+		if r.Status == doctor.Fail {
+			failed++
+		}
+	}
+	fmt.Println()
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	output.Println("✓ No problems found")
+	return nil
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/codegen"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/prompts"
+	"github.com/datagendev/datagen-cli/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	editConfigPath string
+	editOutputDir  string
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit <service>",
+	Short: "Change an existing service's settings interactively",
+	Long: `Ask the 'datagen add' questions again for a service in datagen.toml, with
+its current settings as the default answers, so pressing Enter keeps them.
+The type, path, input and output fields, tools, type-specific settings and
+auth can all be changed; the name cannot.
+
+The updated service is saved to datagen.toml (or the included file it came
+from). When the project has been built, only that service's code in
+app/main.py and app/models.py is regenerated, through the marker comments
+'datagen add' uses, so customizations elsewhere in those files are kept.
+
+Examples:
+  datagen edit chat
+  datagen edit enrich -c ./my-project/datagen.toml -o ./my-project`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEdit,
+}
+
+func init() {
+	editCmd.Flags().StringVarP(&editConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml configuration file")
+	editCmd.Flags().StringVarP(&editOutputDir, "output", "o", ".", "Project directory with the generated app")
+	editCmd.MarkFlagFilename("config", "toml", "yaml", "yml", "json")
+	editCmd.MarkFlagDirname("output")
+}
+
+func runEdit(cmd *cobra.Command, args []string) error {
+	lock, err := lockProject(editOutputDir, "edit")
+	if err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	defer lock.Release()
+
+	configPath := config.FindConfig(editConfigPath)
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	index := -1
+	names := make([]string, len(cfg.Services))
+	for i, svc := range cfg.Services {
+		names[i] = svc.Name
+		if svc.Name == args[0] {
+			index = i
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("service %q not found in %s (services: %s)", args[0], configPath, strings.Join(names, ", "))
+	}
+	if !stdinIsTerminal() && !prompts.SimpleMode {
+		return fmt.Errorf("not a terminal; 'datagen edit' asks its questions interactively")
+	}
+
+	current := cfg.Services[index]
+	fmt.Printf("✏️  Editing service %s (press Enter to keep each current answer)\n\n", current.Name)
+	edited, err := prompts.EditServiceConfig(&current, cfg.Defaults)
+	if err != nil {
+		return err
+	}
+
+	cfg.Services[index] = *edited
+	cfg.ApplyDefaults()
+	edited = &cfg.Services[index]
+	if reflect.DeepEqual(*edited, current) {
+		output.Printf("\n✓ No changes to %s\n", current.Name)
+		return nil
+	}
+
+	configDir := filepath.Dir(configPath)
+	if edited.Prompt != current.Prompt {
+		if _, err := os.Stat(filepath.Join(configDir, edited.Prompt)); os.IsNotExist(err) {
+			if err := createAgentPromptFile(configDir, edited); err != nil {
+				return err
+			}
+			output.Printf("  ✓ Created %s\n", edited.Prompt)
+		}
+	}
+	if err := config.ValidateConfig(cfg, configDir); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+	telemetry.TagConfig(cfg)
+	if err := config.SaveConfig(cfg, configPath); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+	output.Printf("\n✓ Updated %s in %s\n", edited.Name, configPath)
+
+	if _, err := os.Stat(filepath.Join(editOutputDir, "app", "main.py")); err != nil {
+		fmt.Println("\nNo generated project found; run 'datagen build' to generate it")
+		return nil
+	}
+	output.Println("🔄 Regenerating the service's code...")
+	if err := codegen.IncrementalUpdateService(cfg, edited, editOutputDir); err != nil {
+		fmt.Println("\nNote: If marker comments are missing, run 'datagen repair' to restore them,")
+		fmt.Println("then run 'datagen edit' again.")
+		return fmt.Errorf("updating project files for %s: %w", edited.Name, err)
+	}
+	if edited.Prompt != current.Prompt {
+		if err := copyPromptFile(edited.Name, edited.Prompt, configDir, editOutputDir); err != nil {
+			return err
+		}
+	}
+
+	absPath, _ := filepath.Abs(editOutputDir)
+	output.Printf("\n✅ Service '%s' updated in %s\n", edited.Name, absPath)
+	fmt.Println("\n💡 Tip: Your custom code in other parts of the files has been preserved!")
+	return nil
+}
@@ -0,0 +1,622 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/debuglog"
+	"github.com/datagendev/datagen-cli/internal/dotenv"
+	"github.com/datagendev/datagen-cli/internal/keycheck"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/prompts"
+	"github.com/datagendev/datagen-cli/internal/railway"
+	"github.com/datagendev/datagen-cli/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	envFile       string
+	envName       string
+	envConfigPath string
+	envDir        string
+	envService    string
+	envKeys       string
+	envYes        bool
+	envVars       []string
+	envFrom       []string
+	envDoppler    string
+	envDopplerCfg string
+	envCheckKeys  bool
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Sync variables between a local .env file and the platform",
+	Long: `Compare and sync a local .env file with the variables set on the platform
+(Railway). Values are masked in all output.
+
+Before pushing, the Claude and DataGen API keys are checked with their providers
+(Anthropic's models endpoint and a DataGen MCP handshake) so a typo fails here
+rather than after the service boots; --check-keys=false skips this.
+
+Examples:
+  datagen env init                    # create .env from .env.example
+  datagen env diff
+  datagen env push --keys ANTHROPIC_API_KEY,DATAGEN_API_KEY
+  datagen env pull --env staging      # reads/writes .env.staging
+
+Values may be secret references instead of plaintext; they are resolved with the
+matching CLI before comparing or pushing, and never written back by pull:
+  op://vault/item/field         1Password (op)
+  vault://secret/path#field     HashiCorp Vault (vault kv get)
+  aws-sm://secret-id[#key]      AWS Secrets Manager (aws)
+
+  datagen env push --var ANTHROPIC_API_KEY=op://Engineering/Anthropic/credential
+
+Local values can also come from Doppler or a direnv .envrc; --from is repeatable
+and later sources win. The preview labels each key with its source:
+  datagen env diff --from doppler
+  datagen env push --from dotenv --from envrc
+
+The preview also labels each key secret or plain. Keys named like credentials
+(*_KEY, *_TOKEN, *_SECRET, *_PASSWORD, ...), URLs with a password, private keys
+and resolved secret references are secrets; MODEL_NAME, LOG_LEVEL and other
+config are plain. A "# secret" or "# plain" comment after a value in
+.env.example overrides the guess:
+  WEBHOOK_SIGNING=your-secret-here  # secret`,
+}
+
+var envDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show masked differences between local and platform variables",
+	Args:  cobra.NoArgs,
+	RunE:  runEnvDiff,
+}
+
+var envPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Set selected local variables on the platform",
+	Long: `Set the local variables that differ from the platform's.
+
+Without --keys or --yes the push is shown as a checklist first: choose which
+variables to push, edit a value, or add a variable that isn't in any local
+source. Values are entered with hidden input and stay masked in the list.`,
+	Args: cobra.NoArgs,
+	RunE: runEnvPush,
+}
+
+var envPullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Write selected platform variables into the local .env file",
+	Args:  cobra.NoArgs,
+	RunE:  runEnvPull,
+}
+
+func init() {
+	envCmd.PersistentFlags().StringVar(&envFile, "file", "", "Local variables file (default .env, or the environment's env_file, in --output)")
+	envCmd.PersistentFlags().StringVar(&envName, "env", "", "Environment from datagen.toml [environments] (e.g. staging)")
+	envCmd.PersistentFlags().StringVarP(&envConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml configuration file")
+	envCmd.PersistentFlags().StringVarP(&envDir, "output", "o", ".", "Project directory linked to the platform")
+	envCmd.PersistentFlags().StringVar(&envService, "service", "", "Platform service name (defaults to the linked service)")
+	envCmd.PersistentFlags().StringArrayVar(&envFrom, "from", []string{"dotenv"}, "Local variable source: dotenv, envrc, or doppler; repeatable")
+	envCmd.PersistentFlags().StringVar(&envDoppler, "doppler-project", "", "Doppler project for --from doppler (default: doppler setup)")
+	envCmd.PersistentFlags().StringVar(&envDopplerCfg, "doppler-config", "", "Doppler config for --from doppler (default: doppler setup)")
+
+	for _, c := range []*cobra.Command{envPushCmd, envPullCmd} {
+		c.Flags().StringVar(&envKeys, "keys", "", "Comma-separated keys to sync (default: choose interactively)")
+		c.Flags().BoolVarP(&envYes, "yes", "y", false, "Sync every differing key without prompting")
+	}
+
+	envPushCmd.Flags().StringArrayVar(&envVars, "var", nil, "Extra KEY=VALUE to push (VALUE may be a secret reference); repeatable")
+	envPushCmd.Flags().BoolVar(&envCheckKeys, "check-keys", true, "Verify Anthropic and DataGen API keys with their providers before pushing")
+
+	envCmd.AddCommand(envDiffCmd)
+	envCmd.AddCommand(envPushCmd)
+	envCmd.AddCommand(envPullCmd)
+}
+
+// envSyncContext is the resolved local file, extra config variables, and platform target
+type envSyncContext struct {
+	File       string
+	Variables  map[string]string
+	Target     railway.VariablesTarget
+	Sources    map[string]string
+	Refs       map[string]bool
+	Classifier *secrets.Classifier
+}
+
+func resolveEnvSyncContext() (*envSyncContext, error) {
+	ctx := &envSyncContext{
+		File:   filepath.Join(envDir, ".env"),
+		Target: railway.VariablesTarget{Dir: envDir, Service: envService},
+	}
+	if envName != "" {
+		env, err := resolveCommandEnvironment(envConfigPath, envName)
+		if err != nil {
+			return nil, err
+		}
+		// env_file, like the default .env, lives in the project directory
+		ctx.File = env.EnvFile
+		if !filepath.IsAbs(ctx.File) {
+			ctx.File = filepath.Join(envDir, ctx.File)
+		}
+		ctx.Variables = env.Variables
+		ctx.Target.Environment = env.RailwayEnvironment
+	}
+	if envFile != "" {
+		ctx.File = envFile
+	}
+	return ctx, nil
+}
+
+// localValues merges the --from sources in order, then [environments] variables
+// and --var flags. It also returns the source label of each key.
+func (c *envSyncContext) localValues() (map[string]string, map[string]string, error) {
+	values := map[string]string{}
+	sources := map[string]string{}
+	set := func(vars map[string]string, source string) {
+		for k, v := range vars {
+			values[k] = v
+			sources[k] = source
+		}
+	}
+
+	for _, from := range envFrom {
+		switch from {
+		case "dotenv":
+			vars, err := dotenv.Load(c.File)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, nil, err
+			}
+			set(vars, c.File)
+		case "envrc":
+			vars, err := loadEnvrcFile(filepath.Join(envDir, ".envrc"))
+			if err != nil {
+				return nil, nil, err
+			}
+			set(vars, filepath.Join(envDir, ".envrc"))
+		case "doppler":
+			vars, err := secrets.NewDoppler(envDoppler, envDopplerCfg).Load(context.Background())
+			if err != nil {
+				return nil, nil, err
+			}
+			set(vars, "doppler")
+		default:
+			return nil, nil, fmt.Errorf("unknown --from source %q (supported: dotenv, envrc, doppler)", from)
+		}
+	}
+
+	set(c.Variables, envConfigPath)
+	for _, kv := range envVars {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, nil, fmt.Errorf("invalid --var %q (expected KEY=VALUE)", kv)
+		}
+		set(map[string]string{strings.TrimSpace(key): value}, "--var")
+	}
+	return values, sources, nil
+}
+
+// describe names the local side for messages, e.g. ".env" or ".env + doppler"
+func (c *envSyncContext) describe() string {
+	var names []string
+	for _, from := range envFrom {
+		switch from {
+		case "dotenv":
+			names = append(names, c.File)
+		case "envrc":
+			names = append(names, filepath.Join(envDir, ".envrc"))
+		default:
+			names = append(names, from)
+		}
+	}
+	return strings.Join(names, " + ")
+}
+
+// loadEnvSides returns local values (with secret references resolved), platform
+// values, and the set of local keys that came from a secret manager. The
+// context records each key's local source and the .env.example secret marks.
+func loadEnvSides() (*envSyncContext, map[string]string, map[string]string, map[string]bool, error) {
+	if err := railway.EnsureCLI(); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	ctx, err := resolveEnvSyncContext()
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	local, sources, err := ctx.localValues()
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	ctx.Sources = sources
+	refs, err := secrets.DefaultRegistry().ResolveAll(context.Background(), local)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	ctx.Refs = refs
+	ctx.Classifier, err = secrets.LoadClassifier(filepath.Join(filepath.Dir(ctx.File), ".env.example"))
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	remote, err := railway.Variables(ctx.Target)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return ctx, local, remote, refs, nil
+}
+
+func runEnvDiff(cmd *cobra.Command, args []string) error {
+	ctx, local, remote, _, err := loadEnvSides()
+	if err != nil {
+		return err
+	}
+
+	changes := labelEnvChanges(diffEnvValues(local, remote), ctx)
+	if len(changes) == 0 {
+		output.Printf("✅ %s matches the platform\n", ctx.describe())
+		return nil
+	}
+
+	fmt.Printf("Differences between %s and the platform:\n", ctx.describe())
+	printEnvChanges(changes)
+	return nil
+}
+
+func runEnvPush(cmd *cobra.Command, args []string) error {
+	warnIfConfigChanged(envDir, envConfigPath)
+	ctx, local, remote, _, err := loadEnvSides()
+	if err != nil {
+		return err
+	}
+
+	var candidates []envChange
+	for _, c := range labelEnvChanges(diffEnvValues(local, remote), ctx) {
+		if c.Kind != envRemoteOnly {
+			candidates = append(candidates, c)
+		}
+	}
+	var selected []envChange
+	if envKeys == "" && !envYes && len(candidates) > 0 {
+		selected, err = reviewEnvChanges(ctx, candidates, remote)
+	} else {
+		selected, err = selectEnvChanges(candidates, "Push which variables to the platform?")
+	}
+	if err != nil || len(selected) == 0 {
+		return err
+	}
+
+	vars := map[string]string{}
+	secretCount := 0
+	for _, c := range selected {
+		vars[c.Key] = c.Local
+		if c.Secret {
+			secretCount++
+		}
+	}
+	if envCheckKeys {
+		if err := checkAPIKeys(vars); err != nil {
+			return err
+		}
+	}
+	if err := railway.SetVariables(ctx.Target, vars); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	if envName == "" {
+		// The deployment record tracks the linked environment only
+		recordPush(envDir, envConfigPath, keys)
+	}
+	output.Printf("✅ Pushed %d variable(s) from %s (%d secret, %d plain)\n", len(vars), ctx.describe(), secretCount, len(vars)-secretCount)
+	if secretCount > 0 {
+		// The Railway CLI and API can't seal a variable; only the dashboard can
+		output.Printf("   Railway stores secrets as regular variables; seal them in the dashboard to hide their values\n")
+	}
+	return nil
+}
+
+// checkAPIKeys verifies the Claude and DataGen keys among vars with their
+// providers. Rejected keys abort the push; unreachable providers only warn.
+func checkAPIKeys(vars map[string]string) error {
+	claudeEnv, datagenEnv := apiKeyEnvNames()
+	kinds := map[string]keycheck.Kind{claudeEnv: keycheck.Anthropic, datagenEnv: keycheck.Datagen}
+
+	names := make([]string, 0, len(vars))
+	for k := range vars {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	results := keycheck.New().Check(context.Background(), vars, names, kinds)
+	var rejected []string
+	for _, r := range results {
+		switch {
+		case r.Err == nil:
+			output.Printf("  ✓ %s accepted by %s\n", r.Key, r.Kind)
+		case r.Rejected:
+			fmt.Fprintf(os.Stderr, "  ✗ %s (%s): %v\n", r.Key, maskValue(vars[r.Key]), r.Err)
+			rejected = append(rejected, r.Key)
+		default:
+			fmt.Fprintf(os.Stderr, "  ! %s not verified: %v\n", r.Key, r.Err)
+		}
+	}
+	if len(rejected) > 0 {
+		return fmt.Errorf("invalid API key(s): %s (fix the value, or push anyway with --check-keys=false)", strings.Join(rejected, ", "))
+	}
+	return nil
+}
+
+// apiKeyEnvNames returns the variables datagen.toml names for the Claude and
+// DataGen API keys, or the defaults without a loadable config
+func apiKeyEnvNames() (claudeEnv, datagenEnv string) {
+	claudeEnv, datagenEnv = "ANTHROPIC_API_KEY", "DATAGEN_API_KEY"
+	if _, err := os.Stat(config.FindConfig(envConfigPath)); err == nil {
+		if cfg, err := config.LoadConfig(envConfigPath); err == nil {
+			claudeEnv, datagenEnv = cfg.ClaudeAPIKeyEnv, cfg.DatagenAPIKeyEnv
+		}
+	}
+	return claudeEnv, datagenEnv
+}
+
+func runEnvPull(cmd *cobra.Command, args []string) error {
+	ctx, local, remote, refs, err := loadEnvSides()
+	if err != nil {
+		return err
+	}
+
+	var candidates []envChange
+	for _, c := range labelEnvChanges(diffEnvValues(local, remote), ctx) {
+		if c.Kind == envLocalOnly {
+			continue
+		}
+		if c.Source != "" && c.Source != ctx.File {
+			fmt.Printf("  Skipping %s: local value comes from %s\n", c.Key, c.Source)
+			continue
+		}
+		if refs[c.Key] {
+			// Never replace a secret reference with the plaintext value
+			fmt.Printf("  Skipping %s: local value is a secret reference\n", c.Key)
+			continue
+		}
+		candidates = append(candidates, c)
+	}
+	selected, err := selectEnvChanges(candidates, fmt.Sprintf("Write which variables to %s?", ctx.File))
+	if err != nil || len(selected) == 0 {
+		return err
+	}
+
+	vars := map[string]string{}
+	for _, c := range selected {
+		vars[c.Key] = c.Remote
+	}
+	if err := writeDotEnvValues(ctx.File, vars); err != nil {
+		return err
+	}
+	output.Printf("✅ Pulled %d variable(s) into %s\n", len(vars), ctx.File)
+	return nil
+}
+
+const (
+	envLocalOnly  = "local only"
+	envRemoteOnly = "platform only"
+	envChanged    = "changed"
+)
+
+// envChange is one key that differs between the local sources and the platform
+type envChange struct {
+	Key    string
+	Kind   string
+	Local  string
+	Remote string
+	Source string
+	Secret bool
+}
+
+func diffEnvValues(local, remote map[string]string) []envChange {
+	var changes []envChange
+	for k, lv := range local {
+		rv, ok := remote[k]
+		switch {
+		case !ok:
+			changes = append(changes, envChange{Key: k, Kind: envLocalOnly, Local: lv})
+		case rv != lv:
+			changes = append(changes, envChange{Key: k, Kind: envChanged, Local: lv, Remote: rv})
+		}
+	}
+	for k, rv := range remote {
+		if _, ok := local[k]; !ok {
+			changes = append(changes, envChange{Key: k, Kind: envRemoteOnly, Remote: rv})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// labelEnvChanges sets the local source of each change and whether it is a
+// secret: a .env.example mark decides, then a value resolved from a secret
+// reference is one, then the key's name and value
+func labelEnvChanges(changes []envChange, ctx *envSyncContext) []envChange {
+	for i := range changes {
+		c := &changes[i]
+		value := c.Local
+		if c.Kind == envRemoteOnly {
+			value = c.Remote
+		} else {
+			c.Source = ctx.Sources[c.Key]
+		}
+		c.Secret = ctx.isSecret(c.Key, value)
+	}
+	return changes
+}
+
+// isSecret classifies key for labelEnvChanges
+func (c *envSyncContext) isSecret(key, value string) bool {
+	_, marked := c.Classifier.Marks[key]
+	return (!marked && c.Refs[key]) || c.Classifier.Kind(key, value) == secrets.KindSecret
+}
+
+func printEnvChanges(changes []envChange) {
+	for _, c := range changes {
+		fmt.Println("  " + formatEnvChange(c))
+	}
+}
+
+// secretKind is "secret" or "plain"
+func (c envChange) secretKind() string {
+	if c.Secret {
+		return secrets.KindSecret
+	}
+	return secrets.KindPlain
+}
+
+// formatEnvChange describes c with masked values, e.g.
+// "+ ANTHROPIC_API_KEY = sk******yz (local only, secret) [.env]"
+func formatEnvChange(c envChange) string {
+	from := ""
+	if c.Source != "" {
+		from = " [" + c.Source + "]"
+	}
+	kind := c.secretKind()
+	switch c.Kind {
+	case envLocalOnly:
+		return fmt.Sprintf("+ %s = %s (%s, %s)%s", c.Key, maskValue(c.Local), c.Kind, kind, from)
+	case envRemoteOnly:
+		return fmt.Sprintf("- %s = %s (%s, %s)", c.Key, maskValue(c.Remote), c.Kind, kind)
+	default:
+		return fmt.Sprintf("~ %s (%s): local %s, platform %s%s", c.Key, kind, maskValue(c.Local), maskValue(c.Remote), from)
+	}
+}
+
+// selectEnvChanges narrows changes by --keys, --yes, or an interactive multi-select
+func selectEnvChanges(changes []envChange, message string) ([]envChange, error) {
+	if len(changes) == 0 {
+		output.Println("✅ Nothing to sync")
+		return nil, nil
+	}
+	printEnvChanges(changes)
+
+	if envKeys != "" {
+		wanted := parseCSVSet(envKeys)
+		var selected []envChange
+		for _, c := range changes {
+			if wanted[c.Key] {
+				selected = append(selected, c)
+				delete(wanted, c.Key)
+			}
+		}
+		for k := range wanted {
+			output.Warnf("%s has no difference to sync\n", k)
+		}
+		return selected, nil
+	}
+	if envYes {
+		return changes, nil
+	}
+
+	keys := make([]string, len(changes))
+	for i, c := range changes {
+		keys[i] = c.Key
+	}
+	var chosen []string
+	if err := prompts.AskOne(&survey.MultiSelect{
+		Message: message,
+		Options: keys,
+		Default: keys,
+	}, &chosen); err != nil {
+		return nil, err
+	}
+
+	chosenSet := map[string]bool{}
+	for _, k := range chosen {
+		chosenSet[k] = true
+	}
+	var selected []envChange
+	for _, c := range changes {
+		if chosenSet[c.Key] {
+			selected = append(selected, c)
+		}
+	}
+	return selected, nil
+}
+
+// maskValue hides all but the edges of a value
+func maskValue(v string) string {
+	if v == "" {
+		return "(empty)"
+	}
+	if len(v) <= 8 {
+		return strings.Repeat("*", len(v))
+	}
+	return v[:2] + strings.Repeat("*", 6) + v[len(v)-2:]
+}
+
+// loadEnvrcFile reads `export KEY=VALUE` assignments from a direnv .envrc.
+// Other shell statements (dotenv, source_env, layout, ...) are ignored, since
+// evaluating them would need direnv itself. A missing file yields no values.
+func loadEnvrcFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		rest, ok := strings.CutPrefix(line, "export ")
+		if !ok {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimSpace(rest), "=")
+		if !ok || strings.ContainsAny(key, " \t$") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			values[key] = value[1 : len(value)-1]
+			continue
+		}
+		if strings.ContainsAny(value, "$`") {
+			// Skip values that need shell expansion
+			continue
+		}
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
+
+// writeDotEnvValues updates keys in place in a .env file and appends new ones,
+// leaving comments and unrelated lines untouched
+func writeDotEnvValues(path string, values map[string]string) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	data, err := dotenv.Update(existing, values)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return writeDotEnvFile(path, data)
+}
+
+// writeDotEnvFile writes a .env file readable only by its owner
+func writeDotEnvFile(path string, data []byte) error {
+	debuglog.FileWrite(path, data)
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/datagendev/datagen-cli/internal/auth"
+	"github.com/datagendev/datagen-cli/internal/dotenv"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/prompts"
+	"github.com/spf13/cobra"
+)
+
+var envInitYes bool
+
+var envInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create .env from .env.example, filling in the values datagen can find",
+	Long: `Create the local .env file from the project's .env.example.
+
+Settings with a default (MODEL_NAME, PORT, ...) keep it. Placeholders such as
+your-anthropic-api-key-here are filled in where datagen can find the value:
+the DataGen API key from 'datagen login' (active profile, environment,
+keychain or shell profile) and any other key from the current environment,
+such as ANTHROPIC_API_KEY. The rest are asked for with hidden input. Values
+are masked in the output, and the file is readable only by you.
+
+When .env already exists, only keys it lacks or still holds a placeholder
+for are filled in; everything else in it is left as is.
+
+Examples:
+  datagen env init
+  datagen env init --env staging      # writes .env.staging
+  datagen env init --yes              # don't prompt; leave unknown values empty`,
+	Args: cobra.NoArgs,
+	RunE: runEnvInit,
+}
+
+func init() {
+	envInitCmd.Flags().BoolVarP(&envInitYes, "yes", "y", false, "Don't prompt; leave values datagen can't find empty")
+	envCmd.AddCommand(envInitCmd)
+}
+
+func runEnvInit(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	ctx, err := resolveEnvSyncContext()
+	if err != nil {
+		return err
+	}
+	example := filepath.Join(filepath.Dir(ctx.File), ".env.example")
+	entries, err := dotenv.Read(example)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s not found; run 'datagen build' to generate it", example)
+	}
+	if err != nil {
+		return err
+	}
+
+	base, err := os.ReadFile(ctx.File)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	existing := map[string]string{}
+	if exists {
+		if existing, err = dotenv.Load(ctx.File); err != nil {
+			return err
+		}
+	} else if base, err = os.ReadFile(example); err != nil {
+		return err
+	}
+
+	_, datagenEnv := apiKeyEnvNames()
+	interactive := !envInitYes && (stdinIsTerminal() || prompts.SimpleMode)
+	values := map[string]string{}
+	set := func(key, value string) {
+		if v, ok := existing[key]; !exists || !ok || v != value {
+			values[key] = value
+		}
+	}
+	var filled, empty []string
+	for _, e := range entries {
+		if v, ok := existing[e.Key]; ok && !isEnvPlaceholder(v) {
+			continue
+		}
+		if !isEnvPlaceholder(e.Value) {
+			set(e.Key, e.Value)
+			continue
+		}
+
+		value, source := findEnvValue(e.Key, datagenEnv)
+		if value == "" && interactive {
+			message := e.Key + ":"
+			if e.Comment != "" {
+				message = fmt.Sprintf("%s (%s):", e.Key, e.Comment)
+			}
+			if err := prompts.AskOne(&survey.Password{
+				Message: message,
+				Help:    "Leave empty to fill it in later",
+			}, &value); err != nil {
+				return err
+			}
+			value, source = strings.TrimSpace(value), "entered"
+		}
+		set(e.Key, value)
+		if value == "" {
+			empty = append(empty, e.Key)
+			continue
+		}
+		filled = append(filled, e.Key)
+		output.Printf("  ✓ %s = %s (%s)\n", e.Key, maskValue(value), source)
+	}
+
+	if len(values) == 0 {
+		output.Printf("✅ %s already has every variable in %s\n", ctx.File, filepath.Base(example))
+	} else {
+		data, err := dotenv.Update(base, values)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", ctx.File, err)
+		}
+		if err := writeDotEnvFile(ctx.File, data); err != nil {
+			return err
+		}
+		verb := "Created"
+		if exists {
+			verb = "Updated"
+		}
+		output.Printf("✅ %s %s (%d value(s) found or entered, %d left empty)\n", verb, ctx.File, len(filled), len(empty))
+	}
+	if len(empty) > 0 {
+		output.Printf("   Still to set: %s\n", strings.Join(empty, ", "))
+	}
+	return nil
+}
+
+// findEnvValue looks up key without asking: the DataGen API key wherever
+// 'datagen login' keeps it, any other key in the current environment
+func findEnvValue(key, datagenEnv string) (value, source string) {
+	if key == datagenEnv {
+		if v, source, ok := auth.FindEnvVarOrProfile(key); ok {
+			return strings.TrimSpace(v), source
+		}
+		return "", ""
+	}
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v, "environment"
+	}
+	return "", ""
+}
+
+// isEnvPlaceholder reports whether v is a .env.example value that stands in
+// for one the user has to supply: empty, or like your-secret-here
+func isEnvPlaceholder(v string) bool {
+	return v == "" || (strings.HasPrefix(v, "your-") && strings.HasSuffix(v, "-here"))
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/prompts"
+)
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// envReview is what an interactive push will set: the differing keys, with
+// values edited or added during the review, and which of them are selected
+type envReview struct {
+	ctx      *envSyncContext
+	remote   map[string]string
+	changes  []envChange
+	selected map[string]bool
+}
+
+func newEnvReview(ctx *envSyncContext, candidates []envChange, remote map[string]string) *envReview {
+	r := &envReview{ctx: ctx, remote: remote, selected: map[string]bool{}}
+	for _, c := range candidates {
+		r.changes = append(r.changes, c)
+		r.selected[c.Key] = true
+	}
+	return r
+}
+
+// chosen returns the selected changes in key order
+func (r *envReview) chosen() []envChange {
+	var chosen []envChange
+	for _, c := range r.changes {
+		if r.selected[c.Key] {
+			chosen = append(chosen, c)
+		}
+	}
+	return chosen
+}
+
+// set gives key a new local value and selects it, adding the key when it is
+// not under review yet. It returns false, dropping the key, when the platform
+// already has that value.
+func (r *envReview) set(key, value, source string) bool {
+	i := sort.Search(len(r.changes), func(i int) bool { return r.changes[i].Key >= key })
+	exists := i < len(r.changes) && r.changes[i].Key == key
+
+	remote, onPlatform := r.remote[key]
+	if onPlatform && remote == value {
+		if exists {
+			r.changes = append(r.changes[:i], r.changes[i+1:]...)
+		}
+		delete(r.selected, key)
+		return false
+	}
+
+	c := envChange{Key: key, Kind: envLocalOnly, Local: value, Source: source, Secret: r.ctx.isSecret(key, value)}
+	if onPlatform {
+		c.Kind, c.Remote = envChanged, remote
+	}
+	if exists {
+		r.changes[i] = c
+	} else {
+		r.changes = append(r.changes[:i], append([]envChange{c}, r.changes[i:]...)...)
+	}
+	r.selected[key] = true
+	return true
+}
+
+// reviewEnvChanges shows the push as a checklist and lets the user toggle
+// keys, edit values (hidden input) and add variables before anything is set
+func reviewEnvChanges(ctx *envSyncContext, candidates []envChange, remote map[string]string) ([]envChange, error) {
+	const (
+		choose = "Choose variables"
+		edit   = "Edit a value"
+		add    = "Add a variable"
+		cancel = "Cancel"
+	)
+	r := newEnvReview(ctx, candidates, remote)
+	for {
+		fmt.Println("Variables to push:")
+		for _, c := range r.changes {
+			box := "[ ]"
+			if r.selected[c.Key] {
+				box = "[x]"
+			}
+			fmt.Printf("  %s %s\n", box, formatEnvChange(c))
+		}
+
+		push := fmt.Sprintf("Push %d variable(s)", len(r.chosen()))
+		var action string
+		if err := prompts.AskOne(&survey.Select{
+			Message: "Review the push:",
+			Options: []string{push, choose, edit, add, cancel},
+			Default: push,
+		}, &action); err != nil {
+			return nil, err
+		}
+
+		var err error
+		switch action {
+		case push:
+			return r.chosen(), nil
+		case choose:
+			err = r.choose()
+		case edit:
+			err = r.edit()
+		case add:
+			err = r.add()
+		case cancel:
+			output.Println("Nothing pushed")
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// describe is the option description of key in the review's prompts
+func (r *envReview) describe(key string, _ int) string {
+	for _, c := range r.changes {
+		if c.Key == key {
+			return fmt.Sprintf("%s (%s, %s)", maskValue(c.Local), c.Kind, c.secretKind())
+		}
+	}
+	return ""
+}
+
+func (r *envReview) keys() []string {
+	keys := make([]string, len(r.changes))
+	for i, c := range r.changes {
+		keys[i] = c.Key
+	}
+	return keys
+}
+
+func (r *envReview) choose() error {
+	var defaults, chosen []string
+	for _, c := range r.chosen() {
+		defaults = append(defaults, c.Key)
+	}
+	if err := prompts.AskOne(&survey.MultiSelect{
+		Message:     "Push which variables to the platform?",
+		Options:     r.keys(),
+		Default:     defaults,
+		Description: r.describe,
+	}, &chosen); err != nil {
+		return err
+	}
+	r.selected = map[string]bool{}
+	for _, k := range chosen {
+		r.selected[k] = true
+	}
+	return nil
+}
+
+func (r *envReview) edit() error {
+	if len(r.changes) == 0 {
+		output.Warnf("No variables to edit\n")
+		return nil
+	}
+	var key string
+	if err := prompts.AskOne(&survey.Select{
+		Message:     "Edit which variable?",
+		Options:     r.keys(),
+		Description: r.describe,
+	}, &key); err != nil {
+		return err
+	}
+	return r.askValue(key, "edited")
+}
+
+func (r *envReview) add() error {
+	var key string
+	if err := prompts.AskOne(&survey.Input{
+		Message: "Variable name:",
+	}, &key, survey.WithValidator(func(ans interface{}) error {
+		if name, _ := ans.(string); !envKeyPattern.MatchString(strings.TrimSpace(name)) {
+			return errors.New("use letters, digits and underscores, not starting with a digit")
+		}
+		return nil
+	})); err != nil {
+		return err
+	}
+	key = strings.TrimSpace(key)
+	for _, c := range r.changes {
+		if c.Key == key {
+			return r.askValue(key, "edited")
+		}
+	}
+
+	var value string
+	if err := prompts.AskOne(&survey.Password{
+		Message: fmt.Sprintf("Value for %s:", key),
+		Help:    "Input is hidden",
+	}, &value, survey.WithValidator(survey.Required)); err != nil {
+		return err
+	}
+	if !r.set(key, value, "entered") {
+		output.Printf("  %s already has that value on the platform; it won't be pushed\n", key)
+	}
+	return nil
+}
+
+// askValue asks for a new value of key, which is under review, with hidden
+// input; an empty answer keeps the current one
+func (r *envReview) askValue(key, source string) error {
+	var value string
+	if err := prompts.AskOne(&survey.Password{
+		Message: fmt.Sprintf("Value for %s:", key),
+		Help:    "Input is hidden; leave empty to keep the current value",
+	}, &value); err != nil {
+		return err
+	}
+	if value == "" {
+		return nil
+	}
+	if !r.set(key, value, source) {
+		output.Printf("  %s already has that value on the platform; it won't be pushed\n", key)
+	}
+	return nil
+}
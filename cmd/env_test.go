@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/dotenv"
+	"github.com/datagendev/datagen-cli/internal/secrets"
+)
+
+func TestDiffEnvValues(t *testing.T) {
+	local := map[string]string{"A": "1", "B": "2", "C": "3"}
+	remote := map[string]string{"B": "2", "C": "30", "D": "4"}
+
+	got := diffEnvValues(local, remote)
+	want := []envChange{
+		{Key: "A", Kind: envLocalOnly, Local: "1"},
+		{Key: "C", Kind: envChanged, Local: "3", Remote: "30"},
+		{Key: "D", Kind: envRemoteOnly, Remote: "4"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("diffEnvValues() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLabelEnvChanges(t *testing.T) {
+	ctx := &envSyncContext{
+		Sources:    map[string]string{"ANTHROPIC_API_KEY": ".env", "MODEL_NAME": ".env", "SLACK_WEBHOOK": "--var", "SESSION_KEY_TTL": ".env"},
+		Refs:       map[string]bool{"SLACK_WEBHOOK": true},
+		Classifier: &secrets.Classifier{Marks: map[string]string{"SESSION_KEY_TTL": secrets.KindPlain}},
+	}
+	changes := labelEnvChanges(diffEnvValues(
+		map[string]string{"ANTHROPIC_API_KEY": "sk-ant-x", "MODEL_NAME": "claude-sonnet-4-5", "SLACK_WEBHOOK": "https://hooks.slack.com/x", "SESSION_KEY_TTL": "3600"},
+		map[string]string{"GITHUB_TOKEN": "ghp_x"},
+	), ctx)
+
+	want := map[string]bool{"ANTHROPIC_API_KEY": true, "GITHUB_TOKEN": true, "MODEL_NAME": false, "SLACK_WEBHOOK": true, "SESSION_KEY_TTL": false}
+	for _, c := range changes {
+		if c.Secret != want[c.Key] {
+			t.Errorf("%s: Secret = %v, want %v", c.Key, c.Secret, want[c.Key])
+		}
+		if c.Source != ctx.Sources[c.Key] {
+			t.Errorf("%s: Source = %q, want %q", c.Key, c.Source, ctx.Sources[c.Key])
+		}
+	}
+}
+
+func TestEnvReviewSet(t *testing.T) {
+	ctx := &envSyncContext{Classifier: &secrets.Classifier{}}
+	remote := map[string]string{"LOG_LEVEL": "INFO", "MODEL_NAME": "old"}
+	r := newEnvReview(ctx, diffEnvValues(map[string]string{"LOG_LEVEL": "DEBUG", "MODEL_NAME": "new"}, remote), remote)
+	r.selected["MODEL_NAME"] = false
+
+	if !r.set("STRIPE_KEY", "sk_live_x", "entered") {
+		t.Fatal("set() of a new key should add it")
+	}
+	if r.set("LOG_LEVEL", "INFO", "edited") {
+		t.Error("set() to the platform's value should drop the key")
+	}
+	if !r.set("MODEL_NAME", "newer", "edited") {
+		t.Fatal("set() of a changed key should keep it")
+	}
+
+	want := []envChange{
+		{Key: "MODEL_NAME", Kind: envChanged, Local: "newer", Remote: "old", Source: "edited"},
+		{Key: "STRIPE_KEY", Kind: envLocalOnly, Local: "sk_live_x", Source: "entered", Secret: true},
+	}
+	if got := r.chosen(); !reflect.DeepEqual(got, want) {
+		t.Errorf("chosen() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteDotEnvValuesPreservesComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	initial := "# Required\nANTHROPIC_API_KEY=old\n\nLOG_LEVEL=INFO\n"
+	if err := os.WriteFile(path, []byte(initial), 0o600); err != nil {
+		t.Fatalf("WriteFile error = %v", err)
+	}
+
+	if err := writeDotEnvValues(path, map[string]string{"ANTHROPIC_API_KEY": "new", "GREETING": "hello world"}); err != nil {
+		t.Fatalf("writeDotEnvValues() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile error = %v", err)
+	}
+	want := "# Required\nANTHROPIC_API_KEY=new\n\nLOG_LEVEL=INFO\nGREETING=\"hello world\"\n"
+	if string(data) != want {
+		t.Fatalf("file = %q, want %q", string(data), want)
+	}
+
+	values, err := dotenv.Load(path)
+	if err != nil {
+		t.Fatalf("dotenv.Load() error = %v", err)
+	}
+	if values["GREETING"] != "hello world" || values["LOG_LEVEL"] != "INFO" {
+		t.Fatalf("dotenv.Load() = %v", values)
+	}
+}
+
+func TestMaskValue(t *testing.T) {
+	tests := map[string]string{
+		"":                    "(empty)",
+		"short":               "*****",
+		"sk-ant-api03-abcxyz": "sk******yz",
+	}
+	for in, want := range tests {
+		if got := maskValue(in); got != want {
+			t.Fatalf("maskValue(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLoadEnvrcFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".envrc")
+	content := `dotenv
+export ANTHROPIC_API_KEY=sk-1
+export GREETING="hello world"
+export RAW='$literal'
+export PATH_ADD="$PWD/bin"
+layout python
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile error = %v", err)
+	}
+
+	got, err := loadEnvrcFile(path)
+	if err != nil {
+		t.Fatalf("loadEnvrcFile() error = %v", err)
+	}
+	want := map[string]string{"ANTHROPIC_API_KEY": "sk-1", "GREETING": "hello world", "RAW": "$literal"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("loadEnvrcFile() = %v, want %v", got, want)
+	}
+}
+
+func TestIsEnvPlaceholder(t *testing.T) {
+	tests := map[string]bool{
+		"":                            true,
+		"your-anthropic-api-key-here": true,
+		"your-secret-here":            true,
+		"claude-sonnet-4-5":           false,
+		"your-own":                    false,
+	}
+	for in, want := range tests {
+		if got := isEnvPlaceholder(in); got != want {
+			t.Errorf("isEnvPlaceholder(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestResolveEnvSyncContextUsesOutputDir(t *testing.T) {
+	dir := t.TempDir()
+	oldDir, oldName, oldConfig, oldFile := envDir, envName, envConfigPath, envFile
+	t.Cleanup(func() { envDir, envName, envConfigPath, envFile = oldDir, oldName, oldConfig, oldFile })
+	envDir, envConfigPath, envFile = dir, filepath.Join(dir, "datagen.toml"), ""
+
+	for name, want := range map[string]string{"": ".env", "staging": ".env.staging"} {
+		envName = name
+		ctx, err := resolveEnvSyncContext()
+		if err != nil {
+			t.Fatalf("resolveEnvSyncContext(%q) error = %v", name, err)
+		}
+		if ctx.File != filepath.Join(dir, want) {
+			t.Errorf("resolveEnvSyncContext(%q).File = %q, want %q in --output", name, ctx.File, want)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/diagnose"
+	"github.com/datagendev/datagen-cli/internal/eval"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	evalConfigPath  string
+	evalTarget      string
+	evalURL         string
+	evalDir         string
+	evalTimeout     time.Duration
+	evalRuns        int
+	evalMinScore    float64
+	evalGraderModel string
+	evalJSON        bool
+)
+
+var evalCmd = &cobra.Command{
+	Use:   "eval [suite or dir]...",
+	Short: "Run evaluation suites against a project's services",
+	Long: `Run the evaluation suites in evals/ (or the given files and directories)
+against a locally running or deployed project and print a score matrix: one row
+per case, one column per assertion kind.
+
+A suite is a YAML file naming a service and listing cases, each an input
+payload and assertions on the reply:
+
+  service: support-triage
+  cases:
+    - name: refund request
+      input: {message: "I want my money back"}
+      assert:
+        - contains: refund               # case-insensitive
+        - not_contains: "as an AI"
+        - json_path: result.priority     # for JSON replies
+          equals: high
+        - rubric: Apologizes and explains the refund policy
+          min_score: 4                   # Claude grades 1-5; default 4
+
+Streaming replies are joined into their text before checking. Rubrics are
+graded by Claude with the project's Claude API key (from the environment or
+.env). The command exits non-zero when the share of passing assertions is below
+--min-score, so it can gate prompt changes in CI.
+
+Examples:
+  datagen eval
+  datagen eval evals/triage.yaml --runs 3 --min-score 0.9
+  datagen eval --target deployed --json > eval-results.json`,
+	RunE: runEval,
+}
+
+func init() {
+	evalCmd.Flags().StringVarP(&evalConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml configuration file")
+	evalCmd.Flags().StringVar(&evalTarget, "target", "local", "Where to send the cases: local or deployed")
+	evalCmd.Flags().StringVar(&evalURL, "url", "", "Base URL to send to (overrides --target)")
+	evalCmd.Flags().StringVarP(&evalDir, "output", "o", ".", "Project directory linked to the platform (for --target deployed)")
+	evalCmd.Flags().DurationVar(&evalTimeout, "timeout", 5*time.Minute, "Timeout per request")
+	evalCmd.Flags().IntVar(&evalRuns, "runs", 1, "Times to run each case, to smooth out varying replies")
+	evalCmd.Flags().Float64Var(&evalMinScore, "min-score", 1, "Share of assertions (0-1) that must pass")
+	evalCmd.Flags().StringVar(&evalGraderModel, "grader-model", diagnose.DefaultModel, "Claude model that grades rubric assertions")
+	evalCmd.Flags().BoolVar(&evalJSON, "json", false, "Print the results as JSON instead of a table")
+	evalCmd.MarkFlagFilename("config", "toml")
+}
+
+func runEval(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	if evalRuns < 1 {
+		return fmt.Errorf("--runs must be at least 1")
+	}
+	if evalMinScore < 0 || evalMinScore > 1 {
+		return fmt.Errorf("--min-score must be between 0 and 1")
+	}
+	if len(args) == 0 {
+		args = []string{eval.DefaultDir}
+	}
+	files, err := eval.Find(args)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no eval suites (*.yaml) found in %s", strings.Join(args, ", "))
+	}
+	var suites []*eval.Suite
+	usesRubric := false
+	for _, file := range files {
+		suite, err := eval.Load(file)
+		if err != nil {
+			return err
+		}
+		suites = append(suites, suite)
+		for _, c := range suite.Cases {
+			for _, a := range c.Assert {
+				usesRubric = usesRubric || a.Rubric != ""
+			}
+		}
+	}
+
+	cfg, err := config.LoadConfig(evalConfigPath)
+	if err != nil {
+		return err
+	}
+	baseURL, err := targetBaseURL(evalTarget, evalURL, evalDir)
+	if err != nil {
+		return err
+	}
+	var grader eval.Asker
+	if usesRubric {
+		key, err := findClaudeKey(".", evalConfigPath)
+		if err != nil {
+			return fmt.Errorf("rubric assertions need a Claude API key: %w", err)
+		}
+		grader = diagnose.New(key, evalGraderModel)
+	}
+
+	ctx := context.Background()
+	client := &http.Client{Timeout: evalTimeout}
+	var results []eval.Result
+	for _, suite := range suites {
+		svc, err := evalService(cfg, suite)
+		if err != nil {
+			return fmt.Errorf("%s: %w", suite.File, err)
+		}
+		if !evalJSON {
+			output.Printf("Running %s (%d case(s)) against %s%s\n", suite.Name(), len(suite.Cases), baseURL, svc.GetPath())
+		}
+		results = append(results, suite.Run(ctx, evalSender(client, baseURL, svc), grader, evalRuns)...)
+	}
+
+	passed, total := 0, 0
+	for _, r := range results {
+		passed += r.Passed()
+		total += len(r.Checks)
+	}
+	score := float64(passed) / float64(total)
+
+	if evalJSON {
+		if err := printEvalJSON(results, score); err != nil {
+			return err
+		}
+	} else {
+		printEvalMatrix(results)
+		fmt.Printf("\nScore: %d/%d assertions passed (%.0f%%)\n", passed, total, score*100)
+	}
+	if score < evalMinScore {
+		return fmt.Errorf("eval score %.2f is below --min-score %.2f", score, evalMinScore)
+	}
+	return nil
+}
+
+// evalService returns the service a suite runs against
+func evalService(cfg *config.DatagenConfig, suite *eval.Suite) (*config.Service, error) {
+	var svc *config.Service
+	if suite.Service != "" {
+		svc = findService(cfg, suite.Service)
+		if svc == nil {
+			return nil, fmt.Errorf("no service named %q in %s", suite.Service, evalConfigPath)
+		}
+	} else if svc = serviceForPath(cfg, suite.Path); svc == nil {
+		return nil, fmt.Errorf("no service serves %s in %s", suite.Path, evalConfigPath)
+	}
+	if svc.Type == "webhook" {
+		return nil, fmt.Errorf("service %s is a webhook, which replies before its agent runs; evaluate api, streaming or pipeline services", svc.Name)
+	}
+	return svc, nil
+}
+
+// evalSender posts case inputs to svc with its auth header
+func evalSender(client *http.Client, baseURL string, svc *config.Service) eval.Sender {
+	return func(ctx context.Context, input map[string]any) (eval.Reply, error) {
+		if input == nil {
+			input = map[string]any{}
+		}
+		payload, err := json.Marshal(input)
+		if err != nil {
+			return eval.Reply{}, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+svc.GetPath(), bytes.NewReader(payload))
+		if err != nil {
+			return eval.Reply{}, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if name, value, ok := serviceAuthHeader(svc); ok {
+			req.Header.Set(name, value)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return eval.Reply{}, err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return eval.Reply{}, err
+		}
+		return eval.ParseReply(resp.StatusCode, resp.Header.Get("Content-Type"), string(body)), nil
+	}
+}
+
+// printEvalMatrix prints one row per case with passed/total per assertion
+// kind, then each failure
+func printEvalMatrix(results []eval.Result) {
+	type row struct {
+		suite, name string
+		byKind      map[string][2]int
+		passed      int
+		total       int
+	}
+	var rows []*row
+	index := map[string]*row{}
+	used := map[string]bool{}
+	for _, r := range results {
+		key := r.Suite + "\x00" + r.Case
+		rw := index[key]
+		if rw == nil {
+			rw = &row{suite: r.Suite, name: r.Case, byKind: map[string][2]int{}}
+			index[key] = rw
+			rows = append(rows, rw)
+		}
+		for _, c := range r.Checks {
+			counts := rw.byKind[c.Kind]
+			counts[1]++
+			if c.Passed {
+				counts[0]++
+				rw.passed++
+			}
+			rw.byKind[c.Kind] = counts
+			rw.total++
+			used[c.Kind] = true
+		}
+	}
+	var kinds []string
+	for _, kind := range eval.Kinds {
+		if used[kind] {
+			kinds = append(kinds, kind)
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "SUITE\tCASE\t%s\tSCORE\n", strings.ToUpper(strings.Join(kinds, "\t")))
+	for _, rw := range rows {
+		cells := []string{rw.suite, rw.name}
+		for _, kind := range kinds {
+			if counts, ok := rw.byKind[kind]; ok {
+				cells = append(cells, fmt.Sprintf("%d/%d", counts[0], counts[1]))
+			} else {
+				cells = append(cells, "-")
+			}
+		}
+		cells = append(cells, fmt.Sprintf("%.0f%%", float64(rw.passed)*100/float64(rw.total)))
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+
+	failures := false
+	for _, r := range results {
+		if r.Err != nil {
+			if !failures {
+				fmt.Println("\nFailures:")
+				failures = true
+			}
+			fmt.Printf("  ✗ %s / %s: %v\n", r.Suite, r.Case, r.Err)
+			continue
+		}
+		for _, c := range r.Checks {
+			if !c.Passed {
+				if !failures {
+					fmt.Println("\nFailures:")
+					failures = true
+				}
+				fmt.Printf("  ✗ %s / %s (%s): %s\n", r.Suite, r.Case, c.Kind, c.Detail)
+			}
+		}
+	}
+}
+
+func printEvalJSON(results []eval.Result, score float64) error {
+	type check struct {
+		Kind   string `json:"kind"`
+		Passed bool   `json:"passed"`
+		Detail string `json:"detail,omitempty"`
+	}
+	type result struct {
+		Suite  string  `json:"suite"`
+		Case   string  `json:"case"`
+		Error  string  `json:"error,omitempty"`
+		Checks []check `json:"checks"`
+	}
+	out := struct {
+		Score   float64  `json:"score"`
+		Results []result `json:"results"`
+	}{Score: score}
+	for _, r := range results {
+		res := result{Suite: r.Suite, Case: r.Case, Checks: []check{}}
+		if r.Err != nil {
+			res.Error = r.Err.Error()
+		}
+		for _, c := range r.Checks {
+			res.Checks = append(res.Checks, check{Kind: c.Kind, Passed: c.Passed, Detail: c.Detail})
+		}
+		out.Results = append(out.Results, res)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
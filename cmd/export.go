@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/archive"
+	"github.com/datagendev/datagen-cli/internal/codegen"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportDir        string
+	exportConfigPath string
+	exportFromConfig bool
+	exportOutput     string
+	exportFormat     string
+	exportName       string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a generated project for external pipelines",
+}
+
+var exportArchiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Pack the generated project into a tarball or zip with a manifest",
+	Long: `Pack a generated project into a .tar.gz or .zip for teams that build and deploy
+through their own pipelines. The archive has a MANIFEST.json at its root that
+lists every file with its size and SHA-256, plus the CLI version and source.
+
+By default the project directory (--dir) is archived as it is on disk. With
+--from-config the project is rendered from datagen.toml into a temporary
+directory instead, so nothing is written next to your config; the archive then
+also contains datagen.toml and the agent prompts.
+
+Local .env files, virtualenvs, .git, .datagen and Python caches are never included.
+
+Examples:
+  datagen export archive
+  datagen export archive --dir ./out -o agents.zip
+  datagen export archive --from-config -c ./my-project/datagen.toml
+  datagen export archive --from-config -o - > agents.tar.gz`,
+	Args: cobra.NoArgs,
+	RunE: runExportArchive,
+}
+
+func init() {
+	exportArchiveCmd.Flags().StringVarP(&exportDir, "dir", "d", ".", "Generated project directory to archive")
+	exportArchiveCmd.Flags().StringVarP(&exportConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml (used with --from-config)")
+	exportArchiveCmd.Flags().BoolVar(&exportFromConfig, "from-config", false, "Render the project from datagen.toml instead of archiving --dir")
+	exportArchiveCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Archive path, or - for stdout (default <name>.tar.gz)")
+	exportArchiveCmd.Flags().StringVar(&exportFormat, "format", "", "Archive format: tar.gz or zip (default from --output extension)")
+	exportArchiveCmd.Flags().StringVar(&exportName, "name", "", "Top-level directory inside the archive (default project directory name)")
+	exportArchiveCmd.MarkFlagDirname("dir")
+	exportArchiveCmd.MarkFlagFilename("config", "toml")
+
+	exportCmd.AddCommand(exportArchiveCmd)
+}
+
+func runExportArchive(cmd *cobra.Command, args []string) error {
+	srcDir := exportDir
+	source := "directory:" + exportDir
+	name := exportName
+
+	if exportFromConfig {
+		cfg, err := config.LoadConfig(exportConfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading config: %w", err)
+		}
+		tmp, err := os.MkdirTemp("", "datagen-export-*")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+
+		if err := renderProject(cfg, exportConfigPath, tmp); err != nil {
+			return err
+		}
+		srcDir = tmp
+		source = "config:" + exportConfigPath
+		if name == "" {
+			name = projectName(filepath.Dir(exportConfigPath))
+		}
+	} else if _, err := os.Stat(filepath.Join(exportDir, codegen.MetadataFile)); err != nil {
+		return fmt.Errorf("%s does not look like a generated project (no %s); run 'datagen build' or use --from-config", exportDir, codegen.MetadataFile)
+	}
+	if name == "" {
+		name = projectName(exportDir)
+	}
+
+	format := exportFormat
+	if format == "" {
+		format = archive.FormatForPath(exportOutput)
+	}
+	if err := archive.ValidateFormat(format); err != nil {
+		return err
+	}
+	dest := exportOutput
+	if dest == "" {
+		dest = name + "." + format
+	}
+
+	opts := archive.Options{
+		Format:     format,
+		Name:       name,
+		CLIVersion: version.Version,
+		Source:     source,
+	}
+
+	var w io.Writer = os.Stdout
+	if dest != "-" {
+		if rel, ok := relativeInside(srcDir, dest); ok {
+			opts.Exclude = append(opts.Exclude, rel)
+		}
+		f, err := os.Create(dest)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	manifest, err := archive.Write(w, srcDir, opts)
+	if err != nil {
+		if dest != "-" {
+			os.Remove(dest)
+		}
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if dest != "-" {
+		output.Printf("📦 Wrote %s (%d files)\n", dest, len(manifest.Files))
+	}
+	return nil
+}
+
+// renderProject generates cfg into dir together with datagen.toml and the agent prompts
+func renderProject(cfg *config.DatagenConfig, configPath, dir string) error {
+	if _, err := codegen.GenerateProject(cfg, dir); err != nil {
+		return err
+	}
+	configDir := filepath.Dir(configPath)
+	if err := copyPromptFiles(cfg, configDir, dir); err != nil {
+		return err
+	}
+	configPath = config.FindConfig(configPath)
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, filepath.Base(configPath)), data, 0644)
+}
+
+// projectName is the base name of dir, resolved to an absolute path
+func projectName(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "project"
+	}
+	return filepath.Base(abs)
+}
+
+// relativeInside returns target relative to dir (slash-separated) when it lies inside dir
+func relativeInside(dir, target string) (string, bool) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	absTarget, err := filepath.Abs(target)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(absDir, absTarget)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
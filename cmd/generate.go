@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/datagendev/datagen-cli/internal/codegen"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	generateOutputDir  string
+	generateConfigPath string
+	generateForce      bool
+)
+
+// generateArtifacts maps each generate subcommand to the file it writes
+var generateArtifacts = []struct {
+	name  string
+	path  string
+	short string
+}{
+	{"dockerfile", "Dockerfile", "Dockerfile running app.main:app"},
+	{"dockerignore", ".dockerignore", ".dockerignore keeping secrets and caches out of images"},
+	{"gitignore", ".gitignore", ".gitignore for secrets, virtualenvs and datagen logs"},
+	{"requirements", "requirements.txt", "requirements.txt for the generated runtime"},
+	{"pyproject", "pyproject.toml", "pyproject.toml for python_packaging = \"uv\" or \"poetry\""},
+	{"procfile", "Procfile", "Procfile for buildpack platforms"},
+	{"env-example", ".env.example", ".env.example listing the variables the app reads"},
+	{"railway", "railway.json", "railway.json (Railway config-as-code)"},
+	{"do", ".do/app.yaml", "DigitalOcean App Platform spec"},
+	{"hpa", "k8s/hpa.yaml", "Kubernetes HorizontalPodAutoscaler"},
+	{"mcp", ".mcp.json", ".mcp.json wiring the DataGen MCP server into Claude Code"},
+}
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Write a single scaffolding file into an existing project",
+	Long: `Write one piece of datagen's scaffolding into a project, without running
+start or build. Useful for hand-rolled projects that want to adopt, say, the
+Dockerfile or railway.json.
+
+Settings come from datagen.toml when it exists; otherwise defaults are used
+(ANTHROPIC_API_KEY, DATAGEN_API_KEY, app.main:app on port 8000). Existing files
+are left alone unless --force is given.
+
+Examples:
+  datagen generate dockerfile
+  datagen generate railway --output ./service
+  datagen generate mcp --force`,
+}
+
+func init() {
+	generateCmd.PersistentFlags().StringVarP(&generateOutputDir, "output", "o", ".", "Directory to write the file into")
+	generateCmd.PersistentFlags().StringVarP(&generateConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml configuration file (optional)")
+	generateCmd.PersistentFlags().BoolVarP(&generateForce, "force", "f", false, "Overwrite the file if it already exists")
+
+	for _, a := range generateArtifacts {
+		path := a.path
+		generateCmd.AddCommand(&cobra.Command{
+			Use:   a.name,
+			Short: "Write " + a.short,
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runGenerate(cmd, path)
+			},
+		})
+	}
+}
+
+func runGenerate(cmd *cobra.Command, path string) error {
+	cfg, err := generateConfig()
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	dest := filepath.Join(generateOutputDir, path)
+	if _, err := os.Stat(dest); err == nil && !generateForce {
+		return fmt.Errorf("%s already exists (use --force to overwrite)", dest)
+	}
+
+	if _, err := codegen.GenerateFile(cfg, generateOutputDir, path); err != nil {
+		return err
+	}
+	// Some generators write nothing without their config section ([scaling] for the HPA)
+	if _, err := os.Stat(dest); os.IsNotExist(err) {
+		return fmt.Errorf("nothing to write for %s with this config", path)
+	}
+	output.Printf("✓ Wrote %s\n", dest)
+	return nil
+}
+
+// generateConfig loads datagen.toml when present, falling back to defaults
+// for projects that were not created by datagen
+func generateConfig() (*config.DatagenConfig, error) {
+	if _, err := os.Stat(config.FindConfig(generateConfigPath)); err != nil {
+		if os.IsNotExist(err) && !generateCmd.PersistentFlags().Changed("config") {
+			return &config.DatagenConfig{
+				DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+				ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+			}, nil
+		}
+		return nil, fmt.Errorf("error loading config: %w", err)
+	}
+	cfg, err := config.LoadConfig(generateConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading config: %w", err)
+	}
+	return cfg, nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/datagendev/datagen-cli/internal/api"
 	"github.com/datagendev/datagen-cli/internal/auth"
+	"github.com/datagendev/datagen-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -98,7 +99,7 @@ func runGitHubConnect(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	fmt.Println("🔗 Getting GitHub App installation URL...")
+	output.Println("🔗 Getting GitHub App installation URL...")
 
 	resp, err := client.GetGitHubInstallUrl()
 	if err != nil {
@@ -238,7 +239,7 @@ func runGitHubRepos(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	fmt.Printf("\n📦 Available repositories (%d):\n\n", totalRepos)
+	output.Printf("\n📦 Available repositories (%d):\n\n", totalRepos)
 
 	for _, inst := range reposResp.Installations {
 		if len(inst.Repos) > 0 {
@@ -282,7 +283,7 @@ func runGitHubConnected(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	fmt.Printf("\n🔗 Connected repositories (%d):\n\n", len(repos.Repos))
+	output.Printf("\n🔗 Connected repositories (%d):\n\n", len(repos.Repos))
 
 	for _, repo := range repos.Repos {
 		statusIcon := "✅"
@@ -312,7 +313,7 @@ func runGitHubConnectRepo(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	fmt.Printf("🔗 Connecting repository: %s\n", fullName)
+	output.Printf("🔗 Connecting repository: %s\n", fullName)
 
 	resp, err := client.ConnectRepo(fullName)
 	if err != nil {
@@ -320,7 +321,7 @@ func runGitHubConnectRepo(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	fmt.Printf("✅ Connected: %s\n", resp.Repo.FullName)
+	output.Printf("✅ Connected: %s\n", resp.Repo.FullName)
 	fmt.Printf("   ID: %s\n", resp.Repo.ID)
 	fmt.Printf("   Agents discovered: %d\n", resp.AgentsDiscovered)
 
@@ -345,7 +346,7 @@ func runGitHubSync(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	fmt.Printf("🔄 Syncing repository: %s\n", repoID)
+	output.Printf("🔄 Syncing repository: %s\n", repoID)
 
 	resp, err := client.SyncRepo(repoID)
 	if err != nil {
@@ -353,7 +354,7 @@ func runGitHubSync(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	fmt.Printf("✅ Sync complete!\n")
+	output.Printf("✅ Sync complete!\n")
 	fmt.Printf("   Agents found: %d\n", resp.AgentsFound)
 	fmt.Printf("   New agents: %d\n", resp.NewAgents)
 	fmt.Printf("   Updated agents: %d\n", resp.UpdatedAgents)
@@ -366,7 +367,7 @@ func runGitHubStatus(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	fmt.Println("🔍 Checking GitHub connection status...")
+	output.Println("🔍 Checking GitHub connection status...")
 
 	installations, err := client.ListGitHubInstallations()
 	if err != nil {
@@ -380,7 +381,7 @@ func runGitHubStatus(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	fmt.Printf("\n✅ GitHub App installations (%d):\n\n", len(installations.Installations))
+	output.Printf("\n✅ GitHub App installations (%d):\n\n", len(installations.Installations))
 
 	for _, install := range installations.Installations {
 		statusIcon := "✅"
@@ -426,5 +427,5 @@ func openBrowser(url string) error {
 		return fmt.Errorf("unsupported platform")
 	}
 
-	return cmd.Start()
+	return output.Start(cmd)
 }
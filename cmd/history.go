@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/history"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyService    string
+	historySearch     string
+	historyLimit      int
+	historyConfigPath string
+	historyTimeout    time.Duration
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Browse and re-run local agent invocations",
+	Long: `Browse the local run history recorded by 'datagen invoke' in ~/.datagen/history.db.
+
+Examples:
+  datagen history list --service chat --search refund
+  datagen history show 12
+  datagen history rerun 12`,
+}
+
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded runs, newest first",
+	Args:  cobra.NoArgs,
+	RunE:  runHistoryList,
+}
+
+var historyShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a recorded run's payload and output",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runHistoryShow,
+}
+
+var historyRerunCmd = &cobra.Command{
+	Use:   "rerun <id>",
+	Short: "Send a recorded payload again and record the new result",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runHistoryRerun,
+}
+
+func init() {
+	historyListCmd.Flags().StringVar(&historyService, "service", "", "Only show runs for this service")
+	historyListCmd.Flags().StringVar(&historySearch, "search", "", "Only show runs whose payload or output contains this text")
+	historyListCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Maximum number of runs to show")
+
+	historyRerunCmd.Flags().StringVarP(&historyConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml (used for auth headers)")
+	historyRerunCmd.Flags().DurationVar(&historyTimeout, "timeout", 5*time.Minute, "Request timeout")
+
+	historyCmd.AddCommand(historyListCmd)
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.AddCommand(historyRerunCmd)
+}
+
+func runHistoryList(cmd *cobra.Command, args []string) error {
+	store, err := openHistory()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	runs, err := store.List(history.ListOptions{
+		Service: historyService,
+		Search:  historySearch,
+		Limit:   historyLimit,
+	})
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		fmt.Println("No runs recorded yet. Use 'datagen invoke <service>' to create one.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tWHEN\tSERVICE\tSTATUS\tDURATION\tTOKENS")
+	for _, run := range runs {
+		status := strconv.Itoa(run.StatusCode)
+		if run.StatusCode == 0 {
+			status = "error"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			run.ID,
+			run.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+			run.Service,
+			status,
+			run.Duration.Round(time.Millisecond),
+			formatTokens(run.InputTokens, run.OutputTokens),
+		)
+	}
+	return w.Flush()
+}
+
+func runHistoryShow(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid run id %q", args[0])
+	}
+
+	store, err := openHistory()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	run, err := store.Get(id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Run #%d\n", run.ID)
+	fmt.Printf("   Service:  %s\n", run.Service)
+	fmt.Printf("   URL:      %s\n", run.URL)
+	fmt.Printf("   When:     %s\n", run.CreatedAt.Local().Format(time.RFC1123))
+	fmt.Printf("   Status:   %d\n", run.StatusCode)
+	fmt.Printf("   Duration: %s\n", run.Duration.Round(time.Millisecond))
+	fmt.Printf("   Tokens:   %s\n", formatTokens(run.InputTokens, run.OutputTokens))
+	if run.Error != "" {
+		fmt.Printf("   Error:    %s\n", run.Error)
+	}
+	fmt.Println("\nPayload:")
+	fmt.Println(indentJSON(run.Payload))
+	fmt.Println("\nOutput:")
+	fmt.Println(indentJSON(run.Output))
+	return nil
+}
+
+func runHistoryRerun(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid run id %q", args[0])
+	}
+
+	store, err := openHistory()
+	if err != nil {
+		return err
+	}
+	previous, err := store.Get(id)
+	store.Close()
+	if err != nil {
+		return err
+	}
+
+	// Prefer the current service config so auth headers use today's env vars
+	svc := &config.Service{Name: previous.Service}
+	if cfg, err := config.LoadConfig(historyConfigPath); err == nil {
+		if found := findService(cfg, previous.Service); found != nil {
+			svc = found
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Re-running #%d (%s) against %s\n", previous.ID, previous.Service, previous.URL)
+	run := invokeService(svc, previous.URL, previous.Payload, historyTimeout)
+	return finishInvocation(run, false)
+}
+
+func formatTokens(input, output *int64) string {
+	if input == nil && output == nil {
+		return "-"
+	}
+	format := func(v *int64) string {
+		if v == nil {
+			return "?"
+		}
+		return strconv.FormatInt(*v, 10)
+	}
+	return format(input) + " in / " + format(output) + " out"
+}
+
+func indentJSON(s string) string {
+	var pretty bytes.Buffer
+	if json.Indent(&pretty, []byte(s), "", "  ") == nil {
+		return pretty.String()
+	}
+	return s
+}
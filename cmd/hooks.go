@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/hooks"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	hooksConfigPath string
+	hooksURL        string
+	hooksProvider   string
+	hooksRepo       string
+	hooksAppID      string
+	hooksEvents     string
+)
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage provider webhook subscriptions for deployed services",
+}
+
+var hooksRegisterCmd = &cobra.Command{
+	Use:   "register <service>",
+	Short: "Subscribe a provider's webhooks to a deployed webhook service",
+	Long: `Create the webhook subscription on the provider so events are delivered to a
+deployed webhook service. The provider comes from [service.webhook] provider in
+datagen.toml or --provider; tokens are read from the environment:
+
+  github   GITHUB_TOKEN, repository from --repo or GITHUB_REPOSITORY
+  stripe   STRIPE_API_KEY
+  slack    SLACK_CONFIG_TOKEN (app configuration token), app from --app-id or SLACK_APP_ID
+
+When the service has a webhook secret_env set locally, GitHub hooks are signed with
+it. Stripe generates its own signing secret, which is printed so it can be pushed
+with 'datagen env push --var'.
+
+Examples:
+  datagen hooks register github-events --url https://my-app.up.railway.app --repo acme/app
+  datagen hooks register billing --url https://my-app.up.railway.app --events invoice.paid`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHooksRegister,
+}
+
+func init() {
+	hooksRegisterCmd.Flags().StringVarP(&hooksConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml configuration file")
+	hooksRegisterCmd.Flags().StringVar(&hooksURL, "url", "", "Base URL of the deployed project (required)")
+	hooksRegisterCmd.Flags().StringVar(&hooksProvider, "provider", "", "Provider: github, stripe, or slack (default: the service's webhook provider)")
+	hooksRegisterCmd.Flags().StringVar(&hooksRepo, "repo", "", "GitHub repository as owner/name")
+	hooksRegisterCmd.Flags().StringVar(&hooksAppID, "app-id", "", "Slack app ID")
+	hooksRegisterCmd.Flags().StringVar(&hooksEvents, "events", "", "Comma-separated events to subscribe to (provider default when empty)")
+	hooksRegisterCmd.MarkFlagRequired("url")
+	hooksRegisterCmd.MarkFlagFilename("config", "toml")
+
+	hooksCmd.AddCommand(hooksRegisterCmd)
+}
+
+func runHooksRegister(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig(hooksConfigPath)
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	svc := findService(cfg, args[0])
+	if svc == nil {
+		return fmt.Errorf("service %q not found in %s", args[0], hooksConfigPath)
+	}
+	if svc.Type != "webhook" {
+		return fmt.Errorf("service %q is a %s service; only webhook services can be registered", svc.Name, svc.Type)
+	}
+
+	providerName := hooksProvider
+	if providerName == "" && svc.Webhook != nil {
+		providerName = svc.Webhook.Provider
+	}
+	if providerName == "" {
+		return fmt.Errorf("no provider for %q: set [service.webhook] provider or pass --provider", svc.Name)
+	}
+	provider, err := hooks.New(providerName, hooks.Options{Repo: hooksRepo, AppID: hooksAppID})
+	if err != nil {
+		return err
+	}
+
+	target := hooks.Target{URL: strings.TrimRight(hooksURL, "/") + svc.GetPath()}
+	if hooksEvents != "" {
+		for _, e := range strings.Split(hooksEvents, ",") {
+			if e = strings.TrimSpace(e); e != "" {
+				target.Events = append(target.Events, e)
+			}
+		}
+	}
+	if svc.Webhook != nil && svc.Webhook.SecretEnv != "" {
+		target.Secret = os.Getenv(svc.Webhook.SecretEnv)
+	}
+
+	output.Printf("🔗 Registering %s webhook for %s → %s\n", provider.Name(), svc.Name, target.URL)
+	result, err := provider.Register(context.Background(), target)
+	if err != nil {
+		return err
+	}
+
+	output.Printf("✅ Created %s subscription %s\n", provider.Name(), result.ID)
+	if result.Secret != "" {
+		envVar := "WEBHOOK_SECRET"
+		if svc.Webhook != nil && svc.Webhook.SecretEnv != "" {
+			envVar = svc.Webhook.SecretEnv
+		}
+		fmt.Printf("\nSigning secret: %s\n", result.Secret)
+		fmt.Printf("Set it on the platform with:\n  datagen env push --var %s=<secret> --keys %s\n", envVar, envVar)
+	}
+	if result.Note != "" {
+		fmt.Printf("\nNote: %s\n", result.Note)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/openapi"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importOutputDir  string
+	importConfigPath string
+	importDryRun     bool
+	importForce      bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Create a datagen project from an existing API description",
+}
+
+var importOpenAPICmd = &cobra.Command{
+	Use:   "openapi <spec>",
+	Short: "Create datagen.toml and agent prompts from an OpenAPI 3 spec",
+	Long: `Read an OpenAPI 3 document (YAML or JSON) and write a datagen.toml with one
+service per operation, plus a stub agent prompt for each in .claude/agents,
+so an existing API contract can be served by agents.
+
+Each operation becomes a service named after its operationId (or its method
+and path) and served at its path:
+  - responses of type text/event-stream or NDJSON make a streaming service
+  - operations that only answer 202 Accepted, or declare callbacks, make a
+    webhook service
+  - everything else makes an api service
+Path and query parameters and the properties of the JSON request body become
+input fields; the properties of a JSON 200/201 response become output fields.
+apiKey (header), bearer and OAuth security schemes set the service's auth.
+
+Generated services accept POST, so only one operation per path is imported
+(the first of POST, PUT, PATCH, DELETE, GET); the others are listed as
+skipped. Existing prompt files are kept.
+
+Examples:
+  datagen import openapi openapi.yaml --dry-run
+  datagen import openapi api.json -o ./agents-api
+  datagen build`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImportOpenAPI,
+}
+
+func init() {
+	importOpenAPICmd.Flags().StringVarP(&importOutputDir, "output", "o", ".", "Project directory")
+	importOpenAPICmd.Flags().StringVarP(&importConfigPath, "config", "c", "", "Path for the new datagen.toml (default <output>/datagen.toml)")
+	importOpenAPICmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Print the proposed datagen.toml without writing anything")
+	importOpenAPICmd.Flags().BoolVar(&importForce, "force", false, "Overwrite an existing datagen.toml")
+	importOpenAPICmd.MarkFlagFilename("config", "toml")
+	importOpenAPICmd.MarkFlagDirname("output")
+
+	importCmd.AddCommand(importOpenAPICmd)
+}
+
+func runImportOpenAPI(cmd *cobra.Command, args []string) error {
+	configPath := importConfigPath
+	if configPath == "" {
+		configPath = filepath.Join(importOutputDir, "datagen.toml")
+	}
+	cmd.SilenceUsage = true
+	if existing := config.FindConfig(configPath); !importForce && !importDryRun {
+		if _, err := os.Stat(existing); err == nil {
+			return fmt.Errorf("%s already exists (use --force to replace it, or 'datagen add' to add services)", existing)
+		}
+	}
+
+	doc, err := openapi.Load(args[0])
+	if err != nil {
+		return err
+	}
+	services, skipped, err := doc.Services()
+	if err != nil {
+		return err
+	}
+
+	title := doc.Info.Title
+	if title == "" {
+		title = filepath.Base(args[0])
+	}
+	output.Printf("🔍 Found %d operation(s) in %s\n\n", len(services)+len(skipped), title)
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+	}
+	for _, s := range services {
+		output.Printf("  ✓ %-6s %-28s → %s service %q\n", s.Method, s.Path, s.Service.Type, s.Service.Name)
+		for _, note := range s.Notes {
+			fmt.Printf("      note: %s\n", note)
+		}
+		cfg.Services = append(cfg.Services, s.Service)
+	}
+	for _, s := range skipped {
+		fmt.Printf("  - %-6s %-28s skipped (%s)\n", s.Method, s.Path, s.Reason)
+	}
+	if len(services) == 0 {
+		return fmt.Errorf("no operation could be imported")
+	}
+
+	if importDryRun {
+		fmt.Printf("\n📄 Proposed %s:\n\n", configPath)
+		return config.EncodeConfig(cfg, os.Stdout)
+	}
+
+	output.Println("\n📝 Creating agent prompt files...")
+	for i := range cfg.Services {
+		svc := &cfg.Services[i]
+		if _, err := os.Stat(filepath.Join(importOutputDir, svc.Prompt)); err == nil {
+			output.Printf("  ✓ Using existing %s\n", svc.Prompt)
+			continue
+		}
+		if err := createAgentPromptFile(importOutputDir, svc); err != nil {
+			return err
+		}
+		output.Printf("  ✓ Created %s\n", svc.Prompt)
+	}
+
+	if err := config.ValidateConfig(cfg, importOutputDir); err != nil {
+		return fmt.Errorf("imported config is invalid: %w", err)
+	}
+	telemetry.TagConfig(cfg)
+	if err := config.SaveConfig(cfg, configPath); err != nil {
+		return fmt.Errorf("error saving config: %w", err)
+	}
+
+	absPath, _ := filepath.Abs(configPath)
+	output.Printf("\n✅ Imported %d service(s) into %s\n", len(cfg.Services), absPath)
+	fmt.Println("\n📝 Next steps:")
+	fmt.Println("  1. Write each agent's instructions in .claude/agents/")
+	fmt.Printf("  2. Review %s (paths, auth, fields)\n", filepath.Base(configPath))
+	fmt.Println("  3. Run 'datagen build' to generate the FastAPI project")
+	if len(skipped) > 0 {
+		fmt.Println("\nSkipped operations can be added with 'datagen add' at a path of their own.")
+	}
+	return nil
+}
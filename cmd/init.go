@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/summary"
+	"github.com/datagendev/datagen-cli/internal/telemetry"
+	"github.com/datagendev/datagen-cli/internal/templates"
+	"github.com/spf13/cobra"
+)
+
+var (
+	initTemplate string
+	initList     bool
+	initForce    bool
+	initNoBuild  bool
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init [dir]",
+	Short: "Create a project from a starter template",
+	Long: `Create a project from a curated starter template in the datagen-templates
+GitHub repository. The agent prompt, datagen.toml and sample payloads are
+written to dir (default: the current directory) and the project is built.
+
+Set DATAGEN_TEMPLATES_URL to a fork's raw URL or a local checkout to use other
+templates.
+
+Examples:
+  datagen init --list
+  datagen init --template lead-enrichment-api
+  datagen init fraud-hooks --template stripe-fraud-webhook`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runInit,
+}
+
+func init() {
+	initCmd.Flags().StringVarP(&initTemplate, "template", "t", "", "Starter template to create (see --list)")
+	initCmd.Flags().BoolVar(&initList, "list", false, "List available templates")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite files that already exist")
+	initCmd.Flags().BoolVar(&initNoBuild, "no-build", false, "Only write the template files; skip 'datagen build'")
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	if !initList && initTemplate == "" {
+		return fmt.Errorf("choose a template with --template (see 'datagen init --list')")
+	}
+	cmd.SilenceUsage = true
+
+	source := templates.DefaultSource()
+	if initList {
+		list, err := source.List()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TEMPLATE\tDESCRIPTION")
+		for _, t := range list {
+			fmt.Fprintf(w, "%s\t%s\n", t.Name, t.Description)
+		}
+		w.Flush()
+		fmt.Println("\nCreate one with: datagen init --template <name>")
+		return nil
+	}
+
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+
+	output.Printf("📦 Fetching template %s...\n", initTemplate)
+	tmpl, err := source.Get(initTemplate)
+	if err != nil {
+		return err
+	}
+	files, err := source.Fetch(tmpl)
+	if err != nil {
+		return err
+	}
+	telemetry.Tag("template", tmpl.Name)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	written, err := templates.Write(dir, files, initForce)
+	if err != nil {
+		return err
+	}
+	for _, p := range written {
+		output.Printf("  ✓ %s\n", p)
+	}
+
+	configPath := filepath.Join(dir, "datagen.toml")
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("template %s has an invalid datagen.toml: %w", tmpl.Name, err)
+	}
+	telemetry.TagConfig(cfg)
+
+	var samples []string
+	for _, svc := range cfg.Services {
+		sample := "samples/" + svc.Name + ".json"
+		if _, ok := files[sample]; ok {
+			samples = append(samples, fmt.Sprintf("datagen invoke %s --config %s --data-file %s", svc.Name, configPath, filepath.Join(dir, filepath.FromSlash(sample))))
+		}
+	}
+
+	if initNoBuild {
+		fmt.Println("\n📝 Next steps:")
+		fmt.Printf("  datagen build --config %s --output %s\n", configPath, dir)
+		for _, s := range samples {
+			fmt.Printf("  %s\n", s)
+		}
+		return nil
+	}
+
+	fmt.Println()
+	buildConfigPath, buildOutputDir, buildFiles = configPath, dir, nil
+	sum := summary.New("init")
+	err = buildProject(sum, output.Progress())
+	if err == nil {
+		sum.Next(samples...)
+	}
+	if writeErr := sum.Write(os.Stdout, "table"); writeErr != nil && err == nil {
+		err = writeErr
+	}
+	return err
+}
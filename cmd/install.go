@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/railway"
+	"github.com/spf13/cobra"
+)
+
+var (
+	installDir   string
+	installForce bool
+)
+
+var installCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the platform CLIs datagen runs",
+	Long: `Install the command-line tools datagen runs for deploys, without npm, bash or
+curl, so they work the same from PowerShell, cmd and Unix shells.
+
+Examples:
+  datagen install railway`,
+}
+
+var installRailwayCmd = &cobra.Command{
+	Use:   "railway",
+	Short: "Download the Railway CLI from its GitHub releases",
+	Long: `Download the latest Railway CLI release for this OS and architecture from
+GitHub, verify its checksum when the release publishes one, and put the binary
+in ~/.datagen/bin (or --dir).
+
+datagen finds the CLI there even when the directory is not on PATH. Add it to
+PATH to run 'railway login' and other railway commands yourself.
+
+Examples:
+  datagen install railway
+  datagen install railway --force
+  datagen install railway --dir C:\Tools`,
+	Args: cobra.NoArgs,
+	RunE: runInstallRailway,
+}
+
+func init() {
+	installRailwayCmd.Flags().StringVar(&installDir, "dir", "", "Directory to install into (default ~/.datagen/bin)")
+	installRailwayCmd.Flags().BoolVarP(&installForce, "force", "f", false, "Install even when a railway CLI is already on PATH")
+	installRailwayCmd.MarkFlagDirname("dir")
+	installCmd.AddCommand(installRailwayCmd)
+}
+
+func runInstallRailway(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	if path, err := exec.LookPath(railway.CLI); err == nil && !installForce {
+		output.Printf("✓ The Railway CLI is already installed at %s (use --force to install datagen's copy)\n", path)
+		return nil
+	}
+	dir := installDir
+	if dir == "" {
+		var err error
+		if dir, err = railway.BinDir(); err != nil {
+			return err
+		}
+	}
+
+	output.Printf("Downloading the Railway CLI for %s/%s...\n", runtime.GOOS, runtime.GOARCH)
+	path, tag, err := railway.NewInstaller().Install(context.Background(), dir)
+	if err != nil {
+		return err
+	}
+	output.Printf("✓ Installed Railway CLI %s at %s\n", tag, path)
+
+	if !onPath(dir) {
+		fmt.Println("\ndatagen will use it from there. To run railway commands yourself, add the")
+		fmt.Println("directory to PATH:")
+		if runtime.GOOS == "windows" {
+			fmt.Printf("  PowerShell: [Environment]::SetEnvironmentVariable(\"Path\", $env:Path + \";%s\", \"User\")\n", dir)
+			fmt.Printf("  cmd:        setx PATH \"%%PATH%%;%s\"\n", dir)
+		} else {
+			fmt.Printf("  export PATH=\"%s:$PATH\"\n", dir)
+		}
+	}
+	fmt.Println("\nNext: railway login")
+	return nil
+}
+
+// onPath reports whether dir is one of the PATH entries
+func onPath(dir string) bool {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range filepath.SplitList(os.Getenv("PATH")) {
+		if entryAbs, err := filepath.Abs(entry); err == nil {
+			if entryAbs == abs || (runtime.GOOS == "windows" && strings.EqualFold(entryAbs, abs)) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/customtools"
+	"github.com/datagendev/datagen-cli/internal/history"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	invokeConfigPath string
+	invokeURL        string
+	invokeData       string
+	invokeDataFile   string
+	invokeTimeout    time.Duration
+	invokeNoHistory  bool
+)
+
+var invokeCmd = &cobra.Command{
+	Use:   "invoke <service>",
+	Short: "Call a service on a locally running project",
+	Long: `POST a JSON payload to a service of a locally running generated project and
+print the response. Each call is recorded in ~/.datagen/history.db so you can
+browse and re-run it with 'datagen history'.
+
+Auth headers are filled in from the service's configured environment variable
+when it is set.
+
+Examples:
+  datagen invoke chat --data '{"message": "hello"}'
+  datagen invoke enrich --data-file payload.json --url http://localhost:8080`,
+	Args: cobra.ExactArgs(1),
+	RunE: runInvoke,
+}
+
+func init() {
+	invokeCmd.Flags().StringVarP(&invokeConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml configuration file")
+	invokeCmd.Flags().StringVar(&invokeURL, "url", "http://localhost:8000", "Base URL of the running project")
+	invokeCmd.Flags().StringVar(&invokeData, "data", "", "JSON payload")
+	invokeCmd.Flags().StringVar(&invokeDataFile, "data-file", "", "Path to a JSON payload file")
+	invokeCmd.Flags().DurationVar(&invokeTimeout, "timeout", 5*time.Minute, "Request timeout")
+	invokeCmd.Flags().BoolVar(&invokeNoHistory, "no-history", false, "Do not record this call in local history")
+	invokeCmd.MarkFlagFilename("config", "toml")
+}
+
+func runInvoke(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig(invokeConfigPath)
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	svc := findService(cfg, args[0])
+	if svc == nil {
+		return fmt.Errorf("service %q not found in %s", args[0], invokeConfigPath)
+	}
+
+	payload, _, err := customtools.ParseJSONObject(invokeData, invokeDataFile)
+	if err != nil {
+		return fmt.Errorf("payload: %w", err)
+	}
+	if payload == nil {
+		payload = map[string]interface{}{}
+	}
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	run := invokeService(svc, strings.TrimRight(invokeURL, "/")+svc.GetPath(), string(payloadJSON), invokeTimeout)
+	return finishInvocation(run, invokeNoHistory)
+}
+
+// findService returns the named service from cfg, or nil
+func findService(cfg *config.DatagenConfig, name string) *config.Service {
+	for i := range cfg.Services {
+		if cfg.Services[i].Name == name {
+			return &cfg.Services[i]
+		}
+	}
+	return nil
+}
+
+// invokeService POSTs payload to url and captures the outcome as a history run.
+// Transport failures are recorded on the run rather than returned.
+func invokeService(svc *config.Service, url, payload string, timeout time.Duration) *history.Run {
+	run := &history.Run{Service: svc.Name, URL: url, Payload: payload, CreatedAt: time.Now()}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader([]byte(payload)))
+	if err != nil {
+		run.Error = err.Error()
+		return run
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if name, value, ok := serviceAuthHeader(svc); ok {
+		req.Header.Set(name, value)
+	}
+
+	start := time.Now()
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		run.Duration = time.Since(start)
+		run.Error = err.Error()
+		return run
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	run.Duration = time.Since(start)
+	run.StatusCode = resp.StatusCode
+	run.Output = string(body)
+	if err != nil {
+		run.Error = err.Error()
+	} else if resp.StatusCode >= 400 {
+		run.Error = fmt.Sprintf("HTTP %d", resp.StatusCode)
+	}
+	run.InputTokens, run.OutputTokens = responseTokenUsage(body)
+	return run
+}
+
+// serviceAuthHeader builds the auth header for svc from its configured env var
+func serviceAuthHeader(svc *config.Service) (string, string, bool) {
+	if svc.Auth == nil || svc.Auth.EnvVar == "" {
+		return "", "", false
+	}
+	value := os.Getenv(svc.Auth.EnvVar)
+	if value == "" {
+		return "", "", false
+	}
+	switch svc.Auth.Type {
+	case "bearer_token":
+		return "Authorization", "Bearer " + value, true
+	case "api_key":
+		header := svc.Auth.Header
+		if header == "" {
+			header = "X-API-Key"
+		}
+		return header, value, true
+	default:
+		return "", "", false
+	}
+}
+
+// responseTokenUsage reads an optional {"usage": {"input_tokens", "output_tokens"}} block
+func responseTokenUsage(body []byte) (*int64, *int64) {
+	var resp struct {
+		Usage *struct {
+			InputTokens  *int64 `json:"input_tokens"`
+			OutputTokens *int64 `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	if json.Unmarshal(body, &resp) != nil || resp.Usage == nil {
+		return nil, nil
+	}
+	return resp.Usage.InputTokens, resp.Usage.OutputTokens
+}
+
+// finishInvocation prints run, records it in history, and returns its error
+func finishInvocation(run *history.Run, skipHistory bool) error {
+	if run.StatusCode != 0 {
+		fmt.Fprintf(os.Stderr, "HTTP %d in %s\n", run.StatusCode, run.Duration.Round(time.Millisecond))
+	}
+	if run.Output != "" {
+		fmt.Println(indentJSON(run.Output))
+	}
+
+	if !skipHistory {
+		if err := recordRun(run); err != nil {
+			output.Warnf("could not record run history: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Recorded as run #%d\n", run.ID)
+		}
+	}
+
+	if run.Error != "" {
+		return fmt.Errorf("invoke %s failed: %s", run.Service, run.Error)
+	}
+	return nil
+}
+
+func recordRun(run *history.Run) error {
+	store, err := openHistory()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	_, err = store.Record(run)
+	return err
+}
+
+func openHistory() (*history.Store, error) {
+	path, err := history.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return history.Open(path)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+func TestInvokeService(t *testing.T) {
+	t.Setenv("CHAT_API_KEY", "secret")
+
+	var gotKey, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("X-API-Key")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		_, _ = w.Write([]byte(`{"status": "completed", "usage": {"input_tokens": 10, "output_tokens": 5}}`))
+	}))
+	defer server.Close()
+
+	svc := &config.Service{
+		Name:    "chat",
+		APIPath: "/api/chat",
+		Auth:    &config.Auth{Type: "api_key", Header: "X-API-Key", EnvVar: "CHAT_API_KEY"},
+	}
+	run := invokeService(svc, server.URL+"/api/chat", `{"message":"hi"}`, time.Second)
+
+	if run.Error != "" {
+		t.Fatalf("invokeService() error = %q", run.Error)
+	}
+	if gotKey != "secret" {
+		t.Fatalf("X-API-Key = %q, want secret", gotKey)
+	}
+	if gotBody != `{"message":"hi"}` {
+		t.Fatalf("body = %q, want payload", gotBody)
+	}
+	if run.StatusCode != http.StatusOK {
+		t.Fatalf("StatusCode = %d, want 200", run.StatusCode)
+	}
+	if run.InputTokens == nil || *run.InputTokens != 10 || run.OutputTokens == nil || *run.OutputTokens != 5 {
+		t.Fatalf("tokens = %v/%v, want 10/5", run.InputTokens, run.OutputTokens)
+	}
+}
+
+func TestInvokeServiceRecordsHTTPErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"detail": "Agent execution failed"}`, http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	run := invokeService(&config.Service{Name: "chat"}, server.URL, `{}`, time.Second)
+	if run.StatusCode != http.StatusInternalServerError || run.Error != "HTTP 500" {
+		t.Fatalf("run = %+v, want HTTP 500 error", run)
+	}
+}
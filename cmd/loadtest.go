@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/datagendev/datagen-cli/internal/agents"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/customtools"
+	"github.com/datagendev/datagen-cli/internal/hooks"
+	"github.com/datagendev/datagen-cli/internal/loadtest"
+	"github.com/datagendev/datagen-cli/internal/models"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/prompts"
+	"github.com/spf13/cobra"
+)
+
+var (
+	loadtestConfigPath  string
+	loadtestService     string
+	loadtestRPS         float64
+	loadtestDuration    time.Duration
+	loadtestTarget      string
+	loadtestURL         string
+	loadtestDir         string
+	loadtestTimeout     time.Duration
+	loadtestMaxInFlight int
+	loadtestData        string
+	loadtestDataFile    string
+	loadtestYes         bool
+)
+
+var loadtestCmd = &cobra.Command{
+	Use:   "loadtest",
+	Short: "Drive synthetic load at a deployed service and report latency and cost",
+	Long: `Send requests to one service at a fixed rate and report latency percentiles,
+error rates and an estimate of the model cost, to size Railway instances before
+launch.
+
+Requests start every 1/rps seconds whether or not earlier ones have replied, so
+a service that can't keep up shows rising latency and errors instead of a
+quietly lower rate. Payloads are generated from the service's input schema
+(field defaults are used when set); --data or --data-file sends a fixed payload
+instead.
+
+The cost estimate prices one model call per request from the size of the system
+prompt, payload and reply at about 4 bytes per token. Agents that call tools
+make several calls per request, so treat it as a lower bound.
+
+Every request runs the agent and is billed by Anthropic; the command asks before
+starting unless --yes is given.
+
+Examples:
+  datagen loadtest --service chat --rps 5 --duration 60s
+  datagen loadtest --service chat --rps 2 --duration 5m --max-inflight 20
+  datagen loadtest --service chat --target local --data '{"message": "hi"}'`,
+	RunE: runLoadtest,
+}
+
+func init() {
+	loadtestCmd.Flags().StringVarP(&loadtestConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml configuration file")
+	loadtestCmd.Flags().StringVar(&loadtestService, "service", "", "Service to load (required)")
+	loadtestCmd.Flags().Float64Var(&loadtestRPS, "rps", 1, "Requests started per second")
+	loadtestCmd.Flags().DurationVar(&loadtestDuration, "duration", 30*time.Second, "How long to keep starting requests")
+	loadtestCmd.Flags().StringVar(&loadtestTarget, "target", "deployed", "Where to send the requests: deployed or local")
+	loadtestCmd.Flags().StringVar(&loadtestURL, "url", "", "Base URL to send to (overrides --target)")
+	loadtestCmd.Flags().StringVarP(&loadtestDir, "output", "o", ".", "Project directory linked to the platform (for --target deployed)")
+	loadtestCmd.Flags().DurationVar(&loadtestTimeout, "timeout", 2*time.Minute, "Timeout per request")
+	loadtestCmd.Flags().IntVar(&loadtestMaxInFlight, "max-inflight", 100, "Requests allowed to wait for a reply at once; more are counted as errors")
+	loadtestCmd.Flags().StringVar(&loadtestData, "data", "", "Fixed JSON payload instead of generated ones")
+	loadtestCmd.Flags().StringVar(&loadtestDataFile, "data-file", "", "Path to a fixed JSON payload")
+	loadtestCmd.Flags().BoolVarP(&loadtestYes, "yes", "y", false, "Skip the confirmation prompt")
+	loadtestCmd.MarkFlagRequired("service")
+	loadtestCmd.MarkFlagFilename("config", "toml")
+}
+
+func runLoadtest(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	if loadtestRPS <= 0 || loadtestRPS > 1000 {
+		return fmt.Errorf("--rps must be between 0 and 1000")
+	}
+	if loadtestDuration <= 0 {
+		return fmt.Errorf("--duration must be positive")
+	}
+	cfg, err := config.LoadConfig(loadtestConfigPath)
+	if err != nil {
+		return err
+	}
+	svc := findService(cfg, loadtestService)
+	if svc == nil {
+		return fmt.Errorf("no service named %q in %s", loadtestService, loadtestConfigPath)
+	}
+	fixed, _, err := customtools.ParseJSONObject(loadtestData, loadtestDataFile)
+	if err != nil {
+		return err
+	}
+	baseURL, err := targetBaseURL(loadtestTarget, loadtestURL, loadtestDir)
+	if err != nil {
+		return err
+	}
+	if svc.Type == "webhook" {
+		output.Warnf("%s is a webhook service, which replies before its agent runs; latencies measure only the intake\n", svc.Name)
+	}
+
+	total := int(loadtestDuration.Seconds() * loadtestRPS)
+	if !loadtestYes {
+		if !stdinIsTerminal() {
+			return fmt.Errorf("loadtest sends about %d billed requests; pass --yes to run it non-interactively", total)
+		}
+		confirm := false
+		if err := prompts.AskOne(&survey.Confirm{
+			Message: fmt.Sprintf("Send about %d requests to %s%s over %s?", total, baseURL, svc.GetPath(), loadtestDuration),
+			Default: false,
+		}, &confirm); err != nil {
+			return err
+		}
+		if !confirm {
+			return fmt.Errorf("cancelled")
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = loadtestMaxInFlight
+	client := &http.Client{Timeout: loadtestTimeout, Transport: transport}
+	output.Printf("Loading %s%s at %.2f rps for %s (Ctrl+C stops early)\n", baseURL, svc.GetPath(), loadtestRPS, loadtestDuration)
+	samples, elapsed := loadtest.Run(ctx, loadtest.Options{
+		RPS:           loadtestRPS,
+		Duration:      loadtestDuration,
+		MaxInFlight:   loadtestMaxInFlight,
+		ProgressEvery: 10 * time.Second,
+		Progress: func(p loadtest.Progress) {
+			output.Printf("  %s: %d sent, %d done, %d in flight, %d failed\n", p.Elapsed.Round(time.Second), p.Sent, p.Done, p.InFlight, p.Failed)
+		},
+	}, loadtestSender(client, baseURL, svc, fixed))
+
+	report := loadtest.Summarize(samples, elapsed)
+	model, promptBytes := serviceModelAndPromptSize(svc, filepath.Dir(loadtestConfigPath))
+	printLoadtestReport(svc, report, loadtest.EstimateCost(report, model, promptBytes))
+	return nil
+}
+
+// loadtestSender posts generated or fixed payloads to svc, with its auth
+// header and, for webhooks with a secret, a fresh signature
+func loadtestSender(client *http.Client, baseURL string, svc *config.Service, fixed map[string]any) loadtest.Sender {
+	var secret string
+	if svc.Webhook != nil && svc.Webhook.SecretEnv != "" {
+		secret = lookupLocalEnv(loadtestDir, svc.Webhook.SecretEnv)
+	}
+	return func(ctx context.Context, i int) loadtest.Sample {
+		payload := fixed
+		if payload == nil {
+			payload = loadtest.Payload(svc.InputSchema.Fields, i)
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return loadtest.Sample{Err: err}
+		}
+		sample := loadtest.Sample{RequestBytes: len(body)}
+		start := time.Now()
+		trace := &httptrace.ClientTrace{GotFirstResponseByte: func() { sample.FirstByte = time.Since(start) }}
+		req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodPost, baseURL+svc.GetPath(), bytes.NewReader(body))
+		if err != nil {
+			sample.Err = err
+			return sample
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if name, value, ok := serviceAuthHeader(svc); ok {
+			req.Header.Set(name, value)
+		}
+		if secret != "" {
+			for name, values := range hooks.SignatureHeaders(svc.Webhook.Provider, "", svc.Webhook.SignatureHeader, secret, body, time.Now()) {
+				req.Header[name] = values
+			}
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			sample.Err = err
+			sample.Latency = time.Since(start)
+			return sample
+		}
+		defer resp.Body.Close()
+		n, err := io.Copy(io.Discard, resp.Body)
+		sample.Latency = time.Since(start)
+		sample.Status = resp.StatusCode
+		sample.ResponseBytes = int(n)
+		sample.Err = err
+		return sample
+	}
+}
+
+// serviceModelAndPromptSize returns the model a service runs (datagen.toml,
+// else its prompt's frontmatter, else the default) and the size of the
+// system prompts a request goes through
+func serviceModelAndPromptSize(svc *config.Service, configDir string) (string, int) {
+	model := svc.Model
+	size := 0
+	// Locale prompts stand in for the main prompt, so only it is counted
+	var files []string
+	if svc.Prompt != "" {
+		files = append(files, svc.Prompt)
+	}
+	for _, step := range svc.Steps {
+		files = append(files, step.Prompt)
+	}
+	for _, file := range files {
+		p, err := agents.LoadPrompt(filepath.Join(configDir, file))
+		if err != nil {
+			continue
+		}
+		size += len(p.SystemPrompt)
+		if model == "" {
+			model = p.Model
+		}
+	}
+	if model == "" {
+		model = models.Default
+	}
+	return model, size
+}
+
+func printLoadtestReport(svc *config.Service, r loadtest.Report, cost loadtest.CostEstimate) {
+	fmt.Printf("\nRequests:    %d in %s (%d succeeded, %d failed, %.1f%% errors)\n",
+		r.Requests, r.Elapsed.Round(time.Second), r.Succeeded, r.Failed, r.ErrorRate()*100)
+	fmt.Printf("Throughput:  %.2f successful replies/s\n", r.Throughput)
+	if r.Succeeded > 0 {
+		l := r.Latency
+		fmt.Printf("Latency:     p50 %s  p90 %s  p95 %s  p99 %s  max %s\n", ms(l.P50), ms(l.P90), ms(l.P95), ms(l.P99), ms(l.Max))
+		if svc.Type == "streaming" {
+			f := r.FirstByte
+			fmt.Printf("First byte:  p50 %s  p90 %s  p95 %s  p99 %s  max %s\n", ms(f.P50), ms(f.P90), ms(f.P95), ms(f.P99), ms(f.Max))
+		}
+	}
+	if len(r.Errors) > 0 {
+		kinds := make([]string, 0, len(r.Errors))
+		for kind := range r.Errors {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		fmt.Println("Errors:")
+		for _, kind := range kinds {
+			fmt.Printf("  %-26s %d\n", kind, r.Errors[kind])
+		}
+	}
+	if r.Succeeded == 0 {
+		return
+	}
+	fmt.Printf("Tokens:      ~%.0f in, ~%.0f out per request (%s, estimated from sizes)\n", cost.InputTokens, cost.OutputTokens, cost.Model)
+	if !cost.Priced {
+		fmt.Printf("Cost:        no list price known for %s\n", cost.Model)
+		return
+	}
+	fmt.Printf("Cost:        ~$%.4f per request, ~$%.2f per 1,000, ~$%.2f per hour at this rate (lower bound)\n",
+		cost.PerRequest, cost.PerRequest*1000, cost.PerRequest*r.Throughput*3600)
+}
+
+func ms(d time.Duration) string {
+	return d.Round(time.Millisecond).String()
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/codegen"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/projectlock"
+	"github.com/datagendev/datagen-cli/internal/undo"
+)
+
+// projectLock is held while a command modifies a project
+type projectLock struct {
+	lock *projectlock.Lock
+}
+
+// lockProject takes the lock on the project in dir for command, so commands
+// that modify it (and datagen.toml) run one at a time, and snapshots each
+// file the command rewrites so 'datagen undo' can revert it. When the command
+// before died holding the lock, it warns that files may be half-written and
+// points to 'datagen repair' if the injection markers were lost.
+func lockProject(dir, command string) (*projectLock, error) {
+	lock, err := acquireProject(dir, command)
+	if err != nil {
+		return nil, err
+	}
+	if err := undo.Begin(dir, command); err != nil {
+		lock.Release()
+		return nil, err
+	}
+	return &projectLock{lock: lock}, nil
+}
+
+// Release ends the undo snapshot and releases the lock
+func (l *projectLock) Release() {
+	if _, err := undo.End(); err != nil {
+		output.Warnf("could not prune old undo snapshots: %v\n", err)
+	}
+	l.lock.Release()
+}
+
+// acquireProject takes the project lock without starting an undo snapshot
+func acquireProject(dir, command string) (*projectlock.Lock, error) {
+	lock, err := projectlock.Acquire(dir, command, func(h *projectlock.Holder) {
+		fmt.Fprintf(output.Stderr, "⏳ Waiting for %s to finish...\n", h)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if h := lock.Abandoned; h != nil {
+		output.Warnf("%s stopped before finishing and may have left files in %s half-written; check them with 'git diff'\n", h, dir)
+		if _, err := os.Stat(filepath.Join(dir, "app", "main.py")); err != nil {
+			return lock, nil
+		}
+		if missing, err := codegen.MissingMarkers(dir); err == nil && len(missing) > 0 {
+			output.Warnf("missing datagen markers (%s); run 'datagen repair' to restore them\n", strings.Join(missing, "; "))
+		}
+	}
+	return lock, nil
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,16 +11,22 @@ import (
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/datagendev/datagen-cli/internal/auth"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/prompts"
 	"github.com/spf13/cobra"
 )
 
 var (
-	loginAPIKey    string
-	loginShell     string
-	loginProfile   string
-	loginEnvVar    string
-	loginYes       bool
-	loginPrintOnly bool
+	loginAPIKey      string
+	loginShell       string
+	loginProfile     string
+	loginProfileFile string
+	loginEnvVar      string
+	loginYes         bool
+	loginPrintOnly   bool
+	loginStorage     string
+	loginBrowser     bool
+	loginBrowserless bool
 )
 
 var loginCmd = &cobra.Command{
@@ -27,31 +34,87 @@ var loginCmd = &cobra.Command{
 	Short: "Log in to DataGen",
 	Long: `Log in to DataGen using your browser (OAuth PKCE flow).
 
-Opens your browser to authenticate and saves your credentials locally.
-New terminals will have DATAGEN_API_KEY set automatically.
+Opens your browser to authenticate and saves your API key locally. By default
+the key goes into the OS keychain (macOS Keychain, Windows Credential Manager,
+libsecret on Linux), where datagen commands read it from. When no keychain is
+available it is written to your shell profile so new terminals have
+DATAGEN_API_KEY set. Use --storage to choose explicitly.
+
+Over SSH, where your browser cannot reach the CLI's local callback server, login
+uses a device code instead: open the printed URL on any device, enter the code,
+and the CLI picks up the key once you approve. Force either flow with --browser
+or --browserless.
+
+  datagen login --browserless
 
 For non-interactive or CI environments, use --api-key to provide a key directly:
 
-  datagen login --api-key <your-key>`,
+  datagen login --api-key <your-key>
+
+To keep keys for several DataGen accounts, log in to a named profile and switch
+with 'datagen profile use' or DATAGEN_PROFILE:
+
+  datagen login --profile work
+  datagen profile use work`,
 	Run: runLogin,
 }
 
 func init() {
 	loginCmd.Flags().StringVar(&loginAPIKey, "api-key", "", "DataGen API key (skips browser login)")
 	loginCmd.Flags().StringVar(&loginShell, "shell", "", "Shell type (bash, zsh, fish, powershell)")
-	loginCmd.Flags().StringVar(&loginProfile, "profile", "", "Shell profile file to update (defaults based on shell)")
+	loginCmd.Flags().StringVar(&loginProfile, "profile", "", "Named profile to store the key under (defaults to the active profile)")
+	loginCmd.Flags().StringVar(&loginProfileFile, "profile-file", "", "Shell profile file to update (defaults based on shell)")
 	loginCmd.Flags().StringVar(&loginEnvVar, "env", "DATAGEN_API_KEY", "Environment variable name to set")
 	loginCmd.Flags().BoolVarP(&loginYes, "yes", "y", false, "Skip confirmation prompts")
 	loginCmd.Flags().BoolVar(&loginPrintOnly, "print", false, "Print the export command (does not write files)")
+	loginCmd.Flags().BoolVar(&loginBrowser, "browser", false, "Log in through a browser on this machine (local callback)")
+	loginCmd.Flags().BoolVar(&loginBrowserless, "browserless", false, "Log in with a device code you approve on any device")
+	loginCmd.Flags().StringVar(&loginStorage, "storage", "auto", "Where to store the key: auto (keychain, falling back to profile), keychain, or profile")
 }
 
 func runLogin(cmd *cobra.Command, args []string) {
+	switch loginStorage {
+	case "auto", "keychain", "profile":
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unsupported --storage %q (use auto, keychain, profile)\n", loginStorage)
+		os.Exit(1)
+	}
+
+	// --profile used to name the shell profile file; keep paths working
+	if looksLikeProfileFile(loginProfile) && loginProfileFile == "" {
+		output.Warnf("--profile now selects a named DataGen profile; use --profile-file for shell profile paths")
+		loginProfileFile, loginProfile = loginProfile, ""
+	}
+	if loginProfile == "" {
+		loginProfile = auth.ActiveProfile()
+	}
+	if !auth.ValidProfileName(loginProfile) {
+		fmt.Fprintf(os.Stderr, "Error: invalid profile name %q (use letters, digits, - and _)\n", loginProfile)
+		os.Exit(1)
+	}
+
+	if loginBrowser && loginBrowserless {
+		fmt.Fprintln(os.Stderr, "Error: --browser and --browserless cannot be used together")
+		os.Exit(1)
+	}
+
 	// If --api-key was explicitly provided, use the direct key flow.
 	if cmd.Flags().Changed("api-key") {
+		if loginBrowser || loginBrowserless {
+			fmt.Fprintln(os.Stderr, "Error: --api-key cannot be combined with --browser or --browserless")
+			os.Exit(1)
+		}
 		runLoginWithKey(loginAPIKey)
 		return
 	}
 
+	// A browser on the user's machine can't reach a callback server on a
+	// remote host, so default to the device flow over SSH.
+	if loginBrowserless || (!loginBrowser && auth.IsRemoteSession()) {
+		runDeviceLogin()
+		return
+	}
+
 	// Default: browser-based OAuth PKCE flow.
 	runOAuthLogin()
 }
@@ -108,19 +171,53 @@ func runOAuthLogin() {
 		os.Exit(1)
 	}
 
+	finishOAuthLogin(serverBase, tokens)
+}
+
+func runDeviceLogin() {
+	serverBase := auth.ServerBaseURL()
+
+	code, err := auth.RequestDeviceCode(serverBase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	verifyURL := code.VerificationURI
+	if code.VerificationURIComplete != "" {
+		verifyURL = code.VerificationURIComplete
+	}
+	fmt.Println("To log in to DataGen, open this URL on any device:")
+	fmt.Printf("\n  %s\n\n", verifyURL)
+	fmt.Printf("and enter the code: %s\n\n", code.UserCode)
+	fmt.Println("Waiting for approval... (Ctrl+C to cancel)")
+
+	tokens, err := auth.PollDeviceToken(context.Background(), serverBase, code)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\nError: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("\nLogin approved.")
+	finishOAuthLogin(serverBase, tokens)
+}
+
+// finishOAuthLogin persists OAuth tokens, trades them for the user's API key,
+// and stores the key the same way --api-key would.
+func finishOAuthLogin(serverBase string, tokens *auth.OAuthTokens) {
 	// Persist OAuth tokens to ~/.config/datagen/credentials.json.
 	if err := auth.SaveTokens(auth.TokenStore{
 		AccessToken:  tokens.AccessToken,
 		RefreshToken: tokens.RefreshToken,
 	}); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: could not save credentials file: %v\n", err)
+		output.Warnf("could not save credentials file: %v\n", err)
 	}
 
 	// Use the OAuth token to fetch the user's real API key from the server.
 	fmt.Println("Fetching API key...")
 	apiKey, err := auth.FetchApiKey(serverBase, tokens.AccessToken)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: could not fetch API key, using access token instead: %v\n", err)
+		output.Warnf("could not fetch API key, using access token instead: %v\n", err)
 		apiKey = tokens.AccessToken
 	}
 
@@ -138,7 +235,7 @@ func runLoginWithKey(apiKey string) {
 
 	apiKey = strings.TrimSpace(apiKey)
 	if apiKey == "" {
-		if err := survey.AskOne(&survey.Password{
+		if err := prompts.AskOne(&survey.Password{
 			Message: "Enter your DataGen API key:",
 		}, &apiKey); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -154,7 +251,7 @@ func runLoginWithKey(apiKey string) {
 
 	if existing, ok := os.LookupEnv(envVar); ok && existing != "" && existing != apiKey && !loginYes {
 		overwrite := false
-		if err := survey.AskOne(&survey.Confirm{
+		if err := prompts.AskOne(&survey.Confirm{
 			Message: fmt.Sprintf("%s is already set in your current environment. Overwrite?", envVar),
 			Default: false,
 		}, &overwrite); err != nil {
@@ -172,6 +269,33 @@ func runLoginWithKey(apiKey string) {
 		return
 	}
 
+	if loginProfile != auth.DefaultProfile {
+		where, err := auth.SaveProfileKey(loginProfile, envVar, apiKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		output.Printf("✅ Saved %s for profile %q in %s\n", envVar, loginProfile, where)
+		if auth.ActiveProfile() != loginProfile {
+			fmt.Printf("Switch to it with: datagen profile use %s (or set %s=%s)\n", loginProfile, auth.ProfileEnvVar, loginProfile)
+		}
+		return
+	}
+
+	if loginStorage != "profile" {
+		err := auth.SaveToKeyring(envVar, apiKey)
+		if err == nil {
+			output.Printf("✅ Saved %s in the OS keychain\n", envVar)
+			fmt.Println("datagen commands will use it automatically.")
+			return
+		}
+		if loginStorage == "keychain" {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Note: OS keychain unavailable (%v); saving to your shell profile instead\n", err)
+	}
+
 	if runtime.GOOS == "windows" {
 		persistWindowsEnvVar(envVar, apiKey)
 		return
@@ -187,7 +311,7 @@ func runLoginWithKey(apiKey string) {
 		shell = parsed
 	}
 
-	profilePath := strings.TrimSpace(loginProfile)
+	profilePath := strings.TrimSpace(loginProfileFile)
 	if profilePath == "" {
 		var err error
 		profilePath, err = auth.DefaultProfilePath(shell)
@@ -205,7 +329,7 @@ func runLoginWithKey(apiKey string) {
 
 	if !loginYes {
 		confirm := true
-		if err := survey.AskOne(&survey.Confirm{
+		if err := prompts.AskOne(&survey.Confirm{
 			Message: fmt.Sprintf("Write %s to %s?", envVar, profilePath),
 			Default: true,
 		}, &confirm); err != nil {
@@ -223,7 +347,7 @@ func runLoginWithKey(apiKey string) {
 		os.Exit(1)
 	}
 
-	fmt.Printf("✅ Saved %s in %s\n", envVar, profilePath)
+	output.Printf("✅ Saved %s in %s\n", envVar, profilePath)
 	if shell == auth.ShellPowerShell {
 		fmt.Printf("Restart your shell or run: . %s\n", profilePath)
 	} else {
@@ -231,6 +355,12 @@ func runLoginWithKey(apiKey string) {
 	}
 }
 
+// looksLikeProfileFile reports whether a --profile value is a shell profile path
+// rather than a profile name
+func looksLikeProfileFile(value string) bool {
+	return strings.ContainsAny(value, `/\~`) || strings.HasPrefix(value, ".")
+}
+
 func printLoginCommand(envVar string, apiKey string) {
 	shell := auth.DetectShell(runtime.GOOS, os.Getenv("SHELL"))
 	if loginShell != "" {
@@ -261,7 +391,7 @@ func persistWindowsEnvVar(envVar string, apiKey string) {
 	_ = os.Setenv(envVar, apiKey)
 
 	// setx persists for future shells (not the current one).
-	out, err := exec.Command("setx", envVar, apiKey).CombinedOutput()
+	out, err := output.CombinedOutput(exec.Command("setx", envVar, apiKey), apiKey)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: failed to persist %s with setx: %v\n", envVar, err)
 		_ = out // avoid printing; output may include sensitive values
@@ -270,6 +400,6 @@ func persistWindowsEnvVar(envVar string, apiKey string) {
 		os.Exit(1)
 	}
 
-	fmt.Printf("✅ Saved %s for future terminals (Windows user env)\n", envVar)
+	output.Printf("✅ Saved %s for future terminals (Windows user env)\n", envVar)
 	fmt.Println("Restart your terminal for it to take effect.")
 }
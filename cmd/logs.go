@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/railway"
+	"github.com/datagendev/datagen-cli/internal/telemetry"
+	"github.com/datagendev/datagen-cli/internal/transcript"
+	"github.com/spf13/cobra"
+)
+
+var (
+	platformLogsPlatform  string
+	platformLogsDir       string
+	platformLogsService   string
+	platformLogsEnv       string
+	platformLogsConfig    string
+	platformLogsSince     string
+	platformLogsFollow    bool
+	platformLogsBuild     bool
+	platformLogsLines     int
+	platformLogsEvent     string
+	platformLogsRequestID string
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Stream logs from a deployed project",
+	Long: `Stream deployment or build logs for a generated project from its hosting platform.
+
+Railway is currently the only supported platform; the project directory must be
+linked with 'railway link'. The generated app emits one JSON object per log line,
+so --event and --request-id filter on those structured fields client-side.
+
+Examples:
+  datagen logs
+  datagen logs --service api --since 1h
+  datagen logs --env staging
+  datagen logs --follow --event agent_error
+  datagen logs --request-id 3f2c9a1e-...`,
+	RunE: runLogs,
+}
+
+func init() {
+	logsCmd.Flags().StringVar(&platformLogsPlatform, "platform", "railway", "Hosting platform (railway)")
+	logsCmd.Flags().StringVarP(&platformLogsDir, "output", "o", ".", "Project directory linked to the platform")
+	logsCmd.Flags().StringVar(&platformLogsService, "service", "", "Platform service name (defaults to the linked service)")
+	logsCmd.Flags().StringVar(&platformLogsEnv, "env", "", "Environment from datagen.toml [environments] (e.g. staging)")
+	logsCmd.Flags().StringVarP(&platformLogsConfig, "config", "c", "datagen.toml", "Path to datagen.toml configuration file")
+	logsCmd.Flags().StringVar(&platformLogsSince, "since", "", "Only show logs newer than this (e.g. 30m, 2h, 1d)")
+	logsCmd.Flags().BoolVarP(&platformLogsFollow, "follow", "f", false, "Keep streaming new log lines")
+	logsCmd.Flags().BoolVar(&platformLogsBuild, "build", false, "Show build logs instead of deployment logs")
+	logsCmd.Flags().IntVarP(&platformLogsLines, "lines", "n", 200, "Number of lines to fetch when not following")
+	logsCmd.Flags().StringVar(&platformLogsEvent, "event", "", "Only show structured events with this name (e.g. agent_error)")
+	logsCmd.Flags().StringVar(&platformLogsRequestID, "request-id", "", "Only show structured events for this request ID")
+}
+
+func runLogs(cmd *cobra.Command, args []string) error {
+	if platformLogsPlatform != "railway" {
+		return fmt.Errorf("unsupported platform %q (supported: railway)", platformLogsPlatform)
+	}
+	if platformLogsFollow && platformLogsSince != "" {
+		return fmt.Errorf("--since cannot be combined with --follow")
+	}
+	telemetry.Tag("platform", platformLogsPlatform)
+	if err := railway.EnsureCLI(); err != nil {
+		return err
+	}
+
+	railwayEnv := ""
+	if platformLogsEnv != "" {
+		env, err := resolveCommandEnvironment(platformLogsConfig, platformLogsEnv)
+		if err != nil {
+			return err
+		}
+		railwayEnv = env.RailwayEnvironment
+	}
+
+	filter := logFilter{Event: platformLogsEvent, RequestID: platformLogsRequestID}
+
+	lines := platformLogsLines
+	if platformLogsSince != "" {
+		lines = 0
+	}
+	railwayCmd := railway.Command(platformLogsDir, railway.LogsArgs(railway.LogsOptions{
+		Service:     platformLogsService,
+		Environment: railwayEnv,
+		Since:       platformLogsSince,
+		Follow:      platformLogsFollow,
+		Build:       platformLogsBuild,
+		Lines:       lines,
+	})...)
+
+	rec := transcript.Attach(railwayCmd)
+	stdout, err := railwayCmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	done := output.Trace(railwayCmd)
+	if err := railwayCmd.Start(); err != nil {
+		done(err)
+		return fmt.Errorf("failed to run railway logs: %w", err)
+	}
+
+	copyErr := filter.Copy(os.Stdout, stdout)
+	err = railwayCmd.Wait()
+	done(err)
+	if err != nil {
+		return rec.Fail(platformLogsDir, fmt.Errorf("railway logs failed: %w", err))
+	}
+	return copyErr
+}
+
+// resolveCommandEnvironment resolves --env against datagen.toml when it exists,
+// falling back to name-based defaults for projects without a config file.
+func resolveCommandEnvironment(configPath, name string) (*config.EnvironmentConfig, error) {
+	var cfg *config.DatagenConfig
+	if _, err := os.Stat(config.FindConfig(configPath)); err == nil {
+		loaded, err := config.LoadConfig(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("error loading config: %w", err)
+		}
+		cfg = loaded
+	}
+	return config.ResolveEnvironment(cfg, name)
+}
+
+// logFilter selects log lines by the structured fields written by the
+// generated app's log_event helper. An empty filter passes every line.
+type logFilter struct {
+	Event     string
+	RequestID string
+}
+
+func (f logFilter) active() bool {
+	return f.Event != "" || f.RequestID != ""
+}
+
+// Match reports whether line should be shown. Lines that are not JSON
+// objects only pass when no filter is set.
+func (f logFilter) Match(line string) bool {
+	if !f.active() {
+		return true
+	}
+
+	trimmed := strings.TrimSpace(line)
+	start := strings.Index(trimmed, "{")
+	if start == -1 {
+		return false
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(trimmed[start:]), &payload); err != nil {
+		return false
+	}
+
+	if f.Event != "" {
+		if event, _ := payload["event"].(string); event != f.Event {
+			return false
+		}
+	}
+	if f.RequestID != "" {
+		if requestID, _ := payload["request_id"].(string); requestID != f.RequestID {
+			return false
+		}
+	}
+	return true
+}
+
+// Copy streams matching lines from r to w
+func (f logFilter) Copy(w io.Writer, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !f.Match(line) {
+			continue
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLogFilterMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter logFilter
+		line   string
+		want   bool
+	}{
+		{"no filter passes plain text", logFilter{}, "Starting Container", true},
+		{"event filter skips plain text", logFilter{Event: "agent_error"}, "Starting Container", false},
+		{"event match", logFilter{Event: "agent_error"}, `{"event": "agent_error", "request_id": "abc"}`, true},
+		{"event mismatch", logFilter{Event: "agent_error"}, `{"event": "agent_start", "request_id": "abc"}`, false},
+		{"request id with log prefix", logFilter{RequestID: "abc"}, `INFO:app.agent:{"event": "agent_start", "request_id": "abc"}`, true},
+		{"event and request id must both match", logFilter{Event: "agent_start", RequestID: "xyz"}, `{"event": "agent_start", "request_id": "abc"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(tt.line); got != tt.want {
+				t.Fatalf("Match(%q) = %v, want %v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogFilterCopy(t *testing.T) {
+	input := strings.Join([]string{
+		`{"event": "agent_start", "request_id": "a"}`,
+		`{"event": "agent_error", "request_id": "a"}`,
+		`{"event": "agent_error", "request_id": "b"}`,
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := (logFilter{Event: "agent_error"}).Copy(&out, strings.NewReader(input)); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+
+	got := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(got) != 2 {
+		t.Fatalf("Copy() wrote %d lines, want 2: %q", len(got), out.String())
+	}
+}
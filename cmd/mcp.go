@@ -3,11 +3,15 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/datagendev/datagen-cli/internal/auth"
+	"github.com/datagendev/datagen-cli/internal/config"
 	"github.com/datagendev/datagen-cli/internal/mcpconfig"
+	"github.com/datagendev/datagen-cli/internal/prompts"
 	"github.com/spf13/cobra"
 )
 
@@ -18,41 +22,89 @@ var (
 	mcpYes         bool
 	mcpDryRun      bool
 	mcpCodexStatic bool
+	mcpProject     bool
+	mcpEnvRef      bool
+	mcpFromConfig  string
 )
 
+// projectMCPClients have a project-level config file
+var projectMCPClients = map[string]bool{"cursor": true, "vscode": true}
+
 var mcpCmd = &cobra.Command{
 	Use:   "mcp",
 	Short: "Configure DataGen MCP in local tools",
 	Long: `Configure the DataGen MCP server in supported local tools if their config files exist:
 - Codex (~/.codex/config.toml)
 - Claude (~/.claude.json)
-- Gemini (~/.gemini/settings.json)`,
+- Gemini (~/.gemini/settings.json)
+- Cursor (~/.cursor/mcp.json, created when Cursor is installed)
+- VS Code (user mcp.json, created when VS Code is installed)
+- Windsurf (~/.codeium/windsurf/mcp_config.json, created when Windsurf is installed)
+- Zed (context_servers in ~/.config/zed/settings.json)
+- Cline (cline_mcp_settings.json in VS Code's global storage, when Cline is installed)
+
+With --project, Cursor is configured for the current project instead
+(.cursor/mcp.json). The project file references $DATAGEN_API_KEY through
+Cursor's ${env:...} interpolation, so it is safe to commit.
+
+VS Code (GitHub Copilot agent mode) never gets the key written to disk: its
+config declares a password input that VS Code asks for on first use and keeps
+in its secret storage. Use --clients vscode --project for .vscode/mcp.json.
+
+With --env-ref, Claude, Gemini, Cursor and Windsurf reference $DATAGEN_API_KEY
+(see --env) instead of storing the key; the variable must then be set in the
+environment the tool is started from. Zed and Cline cannot expand variables
+and are skipped. Codex uses env_http_headers unless --codex-static is set.
+
+With --from-config, the extra MCP servers the services of a datagen.toml
+declare ([[service.mcp_servers]]) are added next to DataGen in Claude, Gemini,
+Cursor, Windsurf and Cline, so agents can be tried locally with the same
+servers. ${VAR} references stay environment references where the client
+supports them.`,
 	Run: runMCP,
 }
 
 func init() {
-	mcpCmd.Flags().StringVar(&mcpClients, "clients", "codex,claude,gemini", "Comma-separated clients to configure (codex, claude, gemini)")
+	mcpCmd.Flags().StringVar(&mcpClients, "clients", "codex,claude,gemini,cursor,vscode,windsurf,zed,cline", "Comma-separated clients to configure (codex, claude, gemini, cursor, vscode, windsurf, zed, cline)")
 	mcpCmd.Flags().StringVar(&mcpAPIKey, "api-key", "", "DataGen API key (if empty, uses env/profile lookup or prompts when needed)")
 	mcpCmd.Flags().StringVar(&mcpEnvVar, "env", "DATAGEN_API_KEY", "Environment variable name to look up for the API key")
 	mcpCmd.Flags().BoolVarP(&mcpYes, "yes", "y", false, "Skip confirmation prompts")
 	mcpCmd.Flags().BoolVar(&mcpDryRun, "dry-run", false, "Show what would change without writing files")
+	mcpCmd.Flags().BoolVar(&mcpProject, "project", false, "Write project-level config in the current directory (cursor, vscode)")
+	mcpCmd.Flags().BoolVar(&mcpEnvRef, "env-ref", false, "Reference the --env variable in JSON configs instead of writing the key (claude, gemini, cursor, windsurf)")
+	mcpCmd.Flags().StringVar(&mcpFromConfig, "from-config", "", "Also install the extra MCP servers declared in this datagen.toml (claude, gemini, cursor, windsurf, cline)")
 	mcpCmd.Flags().BoolVar(&mcpCodexStatic, "codex-static", false, "Write a static x-api-key header in Codex config (default uses env_http_headers)")
 }
 
 func runMCP(cmd *cobra.Command, args []string) {
+	if mcpProject && !cmd.Flags().Changed("clients") {
+		mcpClients = "cursor"
+	}
 	selected := parseCSVSet(mcpClients)
 	if len(selected) == 0 {
 		fmt.Fprintln(os.Stderr, "Error: --clients cannot be empty")
 		os.Exit(1)
 	}
+	if mcpProject {
+		for client := range selected {
+			if !projectMCPClients[client] {
+				fmt.Fprintf(os.Stderr, "Error: --project is not supported for %s (supported: cursor, vscode)\n", client)
+				os.Exit(1)
+			}
+		}
+	}
+
+	if mcpEnvRef && mcpCodexStatic {
+		fmt.Fprintln(os.Stderr, "Error: --env-ref and --codex-static cannot be used together")
+		os.Exit(1)
+	}
 
 	var didAnything bool
 
-	if selected["codex"] {
-		// Defer until after we resolve API key (if codex-static is enabled).
+	apiKeyNeeded := (selected["codex"] && mcpCodexStatic) || (selected["cursor"] && !mcpProject && !mcpEnvRef)
+	for _, client := range []string{"claude", "gemini", "windsurf", "zed", "cline"} {
+		apiKeyNeeded = apiKeyNeeded || (selected[client] && !mcpEnvRef)
 	}
-
-	apiKeyNeeded := selected["claude"] || selected["gemini"] || (selected["codex"] && mcpCodexStatic)
 	apiKey := ""
 	if apiKeyNeeded {
 		apiKey = mustResolveAPIKey()
@@ -70,7 +122,7 @@ func runMCP(cmd *cobra.Command, args []string) {
 	}
 
 	if selected["claude"] {
-		changed, ok, err := configureClaude(apiKey)
+		changed, ok, err := configureClaude(mcpKeyValue("claude", apiKey))
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Claude: %v\n", err)
 			os.Exit(1)
@@ -81,7 +133,7 @@ func runMCP(cmd *cobra.Command, args []string) {
 	}
 
 	if selected["gemini"] {
-		changed, ok, err := configureGemini(apiKey)
+		changed, ok, err := configureGemini(mcpKeyValue("gemini", apiKey))
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Gemini: %v\n", err)
 			os.Exit(1)
@@ -91,6 +143,57 @@ func runMCP(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	if selected["cursor"] {
+		changed, ok, err := configureCursor(apiKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cursor: %v\n", err)
+			os.Exit(1)
+		}
+		if ok {
+			didAnything = didAnything || changed
+		}
+	}
+
+	if selected["vscode"] {
+		changed, ok, err := configureVSCode()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "VS Code: %v\n", err)
+			os.Exit(1)
+		}
+		if ok {
+			didAnything = didAnything || changed
+		}
+	}
+
+	for _, client := range editorMCPClients {
+		if !selected[client.name] {
+			continue
+		}
+		if mcpEnvRef {
+			if _, ok := mcpconfig.EnvReference(client.name, ""); !ok {
+				fmt.Printf("%s: skipped (--env-ref: its config cannot reference environment variables)\n", client.label)
+				continue
+			}
+		}
+		changed, ok, err := configureEditor(client, mcpKeyValue(client.name, apiKey))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", client.label, err)
+			os.Exit(1)
+		}
+		if ok {
+			didAnything = didAnything || changed
+		}
+	}
+
+	if mcpFromConfig != "" {
+		changed, err := configureExtraServers(selected)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		didAnything = didAnything || changed
+	}
+
 	if !didAnything {
 		fmt.Println("No changes needed.")
 	}
@@ -130,7 +233,7 @@ func configureCodex(apiKey string) (changed bool, fileExists bool, err error) {
 
 	if !mcpYes {
 		confirm := true
-		if err := survey.AskOne(&survey.Confirm{
+		if err := prompts.AskOne(&survey.Confirm{
 			Message: fmt.Sprintf("Update Codex config at %s?", path),
 			Default: true,
 		}, &confirm); err != nil {
@@ -199,8 +302,8 @@ func configureClaude(apiKey string) (changed bool, fileExists bool, err error) {
 
 	if !mcpYes {
 		confirm := true
-		if err := survey.AskOne(&survey.Confirm{
-			Message: fmt.Sprintf("Update Claude config at %s? (stores API key in the file)", path),
+		if err := prompts.AskOne(&survey.Confirm{
+			Message: fmt.Sprintf("Update Claude config at %s? (%s)", path, mcpKeyNote()),
 			Default: true,
 		}, &confirm); err != nil {
 			return false, true, err
@@ -260,8 +363,8 @@ func configureGemini(apiKey string) (changed bool, fileExists bool, err error) {
 
 	if !mcpYes {
 		confirm := true
-		if err := survey.AskOne(&survey.Confirm{
-			Message: fmt.Sprintf("Update Gemini config at %s? (stores API key in the file)", path),
+		if err := prompts.AskOne(&survey.Confirm{
+			Message: fmt.Sprintf("Update Gemini config at %s? (%s)", path, mcpKeyNote()),
 			Default: true,
 		}, &confirm); err != nil {
 			return false, true, err
@@ -284,6 +387,387 @@ func configureGemini(apiKey string) (changed bool, fileExists bool, err error) {
 	return changed, true, nil
 }
 
+func configureCursor(apiKey string) (changed bool, fileExists bool, err error) {
+	var path, headerValue, note string
+	if mcpProject {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return false, false, err
+		}
+		path = mcpconfig.CursorProjectConfigPath(cwd)
+		if !mcpDryRun {
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return false, false, err
+			}
+		}
+		headerValue = mcpconfig.CursorEnvHeader(strings.TrimSpace(mcpEnvVar))
+		note = fmt.Sprintf("reads %s from the environment", strings.TrimSpace(mcpEnvVar))
+	} else {
+		path, err = mcpconfig.CursorConfigPath()
+		if err != nil {
+			return false, false, err
+		}
+		// Only create mcp.json when Cursor itself is installed
+		if _, statErr := os.Stat(filepath.Dir(path)); statErr != nil {
+			if os.IsNotExist(statErr) {
+				fmt.Printf("Cursor: skipped (missing %s)\n", filepath.Dir(path))
+				return false, false, nil
+			}
+			return false, false, statErr
+		}
+		headerValue = mcpKeyValue("cursor", apiKey)
+		note = mcpKeyNote()
+	}
+
+	if mcpDryRun {
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return false, true, err
+		}
+		_, changed, err := mcpconfig.UpdateCursorConfig(string(data), headerValue)
+		if err != nil {
+			return false, true, err
+		}
+		if changed {
+			fmt.Printf("Cursor: would update %s\n", path)
+		} else {
+			fmt.Printf("Cursor: already configured (%s)\n", path)
+		}
+		return changed, true, nil
+	}
+
+	if !mcpYes {
+		confirm := true
+		if err := prompts.AskOne(&survey.Confirm{
+			Message: fmt.Sprintf("Update Cursor config at %s? (%s)", path, note),
+			Default: true,
+		}, &confirm); err != nil {
+			return false, true, err
+		}
+		if !confirm {
+			fmt.Printf("Cursor: skipped (%s)\n", path)
+			return false, true, nil
+		}
+	}
+
+	changed, err = mcpconfig.UpdateCursorConfigFile(path, headerValue)
+	if err != nil {
+		return false, true, err
+	}
+	if changed {
+		fmt.Printf("Cursor: updated %s\n", path)
+	} else {
+		fmt.Printf("Cursor: already configured (%s)\n", path)
+	}
+	return changed, true, nil
+}
+
+func configureVSCode() (changed bool, fileExists bool, err error) {
+	var path string
+	if mcpProject {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return false, false, err
+		}
+		path = mcpconfig.VSCodeProjectConfigPath(cwd)
+		if !mcpDryRun {
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return false, false, err
+			}
+		}
+	} else {
+		path, err = mcpconfig.VSCodeConfigPath()
+		if err != nil {
+			return false, false, err
+		}
+		// Only create mcp.json when VS Code itself is installed
+		if _, statErr := os.Stat(filepath.Dir(path)); statErr != nil {
+			if os.IsNotExist(statErr) {
+				fmt.Printf("VS Code: skipped (missing %s)\n", filepath.Dir(path))
+				return false, false, nil
+			}
+			return false, false, statErr
+		}
+	}
+
+	if mcpDryRun {
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return false, true, err
+		}
+		_, changed, err := mcpconfig.UpdateVSCodeConfig(string(data))
+		if err != nil {
+			return false, true, err
+		}
+		if changed {
+			fmt.Printf("VS Code: would update %s\n", path)
+		} else {
+			fmt.Printf("VS Code: already configured (%s)\n", path)
+		}
+		return changed, true, nil
+	}
+
+	if !mcpYes {
+		confirm := true
+		if err := prompts.AskOne(&survey.Confirm{
+			Message: fmt.Sprintf("Update VS Code config at %s? (VS Code prompts for the API key on first use)", path),
+			Default: true,
+		}, &confirm); err != nil {
+			return false, true, err
+		}
+		if !confirm {
+			fmt.Printf("VS Code: skipped (%s)\n", path)
+			return false, true, nil
+		}
+	}
+
+	changed, err = mcpconfig.UpdateVSCodeConfigFile(path)
+	if err != nil {
+		return false, true, err
+	}
+	if changed {
+		fmt.Printf("VS Code: updated %s\n", path)
+	} else {
+		fmt.Printf("VS Code: already configured (%s)\n", path)
+	}
+	return changed, true, nil
+}
+
+// editorMCPClient is a client whose JSON config only needs the Datagen
+// server entry written into it
+type editorMCPClient struct {
+	name       string
+	label      string
+	path       func() (string, error)
+	update     func(contents, apiKey string) (string, bool, error)
+	updateFile func(path, apiKey string) (bool, error)
+}
+
+var editorMCPClients = []editorMCPClient{
+	{"windsurf", "Windsurf", mcpconfig.WindsurfConfigPath, mcpconfig.UpdateWindsurfConfig, mcpconfig.UpdateWindsurfConfigFile},
+	{"zed", "Zed", mcpconfig.ZedConfigPath, mcpconfig.UpdateZedConfig, mcpconfig.UpdateZedConfigFile},
+	{"cline", "Cline", mcpconfig.ClineConfigPath, mcpconfig.UpdateClineConfig, mcpconfig.UpdateClineConfigFile},
+}
+
+func configureEditor(client editorMCPClient, apiKey string) (changed bool, fileExists bool, err error) {
+	path, err := client.path()
+	if err != nil {
+		return false, false, err
+	}
+	// Only create the config when the editor (or extension) itself is installed
+	if _, statErr := os.Stat(filepath.Dir(path)); statErr != nil {
+		if os.IsNotExist(statErr) {
+			fmt.Printf("%s: skipped (missing %s)\n", client.label, filepath.Dir(path))
+			return false, false, nil
+		}
+		return false, false, statErr
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, true, err
+	}
+	_, changed, err = client.update(string(data), apiKey)
+	if err != nil {
+		return false, true, err
+	}
+	note := mcpKeyNote()
+	if changed && mcpconfig.HasJSONComments(string(data)) {
+		note += "; comments in it will be removed"
+	}
+
+	if mcpDryRun {
+		if changed {
+			fmt.Printf("%s: would update %s (%s)\n", client.label, path, note)
+		} else {
+			fmt.Printf("%s: already configured (%s)\n", client.label, path)
+		}
+		return changed, true, nil
+	}
+
+	if changed && !mcpYes {
+		confirm := true
+		if err := prompts.AskOne(&survey.Confirm{
+			Message: fmt.Sprintf("Update %s config at %s? (%s)", client.label, path, note),
+			Default: true,
+		}, &confirm); err != nil {
+			return false, true, err
+		}
+		if !confirm {
+			fmt.Printf("%s: skipped (%s)\n", client.label, path)
+			return false, true, nil
+		}
+	}
+
+	changed, err = client.updateFile(path, apiKey)
+	if err != nil {
+		return false, true, err
+	}
+	if changed {
+		fmt.Printf("%s: updated %s\n", client.label, path)
+	} else {
+		fmt.Printf("%s: already configured (%s)\n", client.label, path)
+	}
+	return changed, true, nil
+}
+
+var mcpClientLabels = map[string]string{
+	"codex": "Codex", "claude": "Claude", "gemini": "Gemini", "cursor": "Cursor",
+	"vscode": "VS Code", "windsurf": "Windsurf", "zed": "Zed", "cline": "Cline",
+}
+
+// configureExtraServers installs the [[service.mcp_servers]] of the
+// --from-config datagen.toml into the selected clients whose config exists
+func configureExtraServers(selected map[string]bool) (bool, error) {
+	cfg, err := config.LoadConfig(mcpFromConfig)
+	if err != nil {
+		return false, fmt.Errorf("error loading config: %w", err)
+	}
+
+	var servers []config.MCPServer
+	index := map[string]int{}
+	owner := map[string]string{}
+	for _, svc := range cfg.Services {
+		for _, s := range svc.MCPServers {
+			i, ok := index[s.Name]
+			if !ok {
+				index[s.Name], owner[s.Name] = len(servers), svc.Name
+				servers = append(servers, s)
+				continue
+			}
+			if !reflect.DeepEqual(servers[i], s) {
+				return false, fmt.Errorf("MCP server %q is declared differently by services %s and %s", s.Name, owner[s.Name], svc.Name)
+			}
+		}
+	}
+	if len(servers) == 0 {
+		fmt.Printf("No extra MCP servers declared in %s\n", mcpFromConfig)
+		return false, nil
+	}
+	names := make([]string, len(servers))
+	for i, s := range servers {
+		names[i] = s.Name
+	}
+
+	var didAnything bool
+	for _, client := range mcpconfig.ServerClients {
+		if !selected[client] {
+			continue
+		}
+		label := mcpClientLabels[client]
+		if _, ok := mcpconfig.EnvReference(client, ""); mcpEnvRef && !ok {
+			fmt.Printf("%s: extra servers skipped (--env-ref: its config cannot reference environment variables)\n", label)
+			continue
+		}
+		path, err := extraServersPath(client)
+		if err != nil {
+			return didAnything, err
+		}
+		if _, err := os.Stat(path); err != nil {
+			if os.IsNotExist(err) {
+				fmt.Printf("%s: extra servers skipped (missing %s)\n", label, path)
+				continue
+			}
+			return didAnything, err
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return didAnything, err
+		}
+		_, changed, err := mcpconfig.UpdateServersConfig(client, string(data), servers)
+		if err != nil {
+			return didAnything, fmt.Errorf("%s: %w", label, err)
+		}
+		if !changed {
+			fmt.Printf("%s: %s already configured (%s)\n", label, strings.Join(names, ", "), path)
+			continue
+		}
+		if mcpDryRun {
+			fmt.Printf("%s: would add %s to %s\n", label, strings.Join(names, ", "), path)
+			didAnything = true
+			continue
+		}
+		if !mcpYes {
+			confirm := true
+			if err := prompts.AskOne(&survey.Confirm{
+				Message: fmt.Sprintf("Add MCP servers %s to %s config at %s?", strings.Join(names, ", "), label, path),
+				Default: true,
+			}, &confirm); err != nil {
+				return didAnything, err
+			}
+			if !confirm {
+				fmt.Printf("%s: extra servers skipped (%s)\n", label, path)
+				continue
+			}
+		}
+		if _, err := mcpconfig.UpdateServersConfigFile(client, path, servers); err != nil {
+			return didAnything, fmt.Errorf("%s: %w", label, err)
+		}
+		fmt.Printf("%s: added %s to %s\n", label, strings.Join(names, ", "), path)
+		didAnything = true
+	}
+	for _, client := range []string{"codex", "vscode", "zed"} {
+		if selected[client] {
+			fmt.Printf("%s: extra servers not supported; add %s by hand\n", mcpClientLabels[client], strings.Join(names, ", "))
+		}
+	}
+	return didAnything, nil
+}
+
+// extraServersPath returns the config file configureExtraServers edits for client
+func extraServersPath(client string) (string, error) {
+	switch client {
+	case "claude":
+		path, err := mcpconfig.ClaudeConfigPath()
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if legacy, err := mcpconfig.ClaudeConfigPathLegacy(); err == nil {
+				if _, err := os.Stat(legacy); err == nil {
+					return legacy, nil
+				}
+			}
+		}
+		return path, nil
+	case "gemini":
+		return mcpconfig.GeminiConfigPath()
+	case "cursor":
+		if mcpProject {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return "", err
+			}
+			return mcpconfig.CursorProjectConfigPath(cwd), nil
+		}
+		return mcpconfig.CursorConfigPath()
+	case "windsurf":
+		return mcpconfig.WindsurfConfigPath()
+	case "cline":
+		return mcpconfig.ClineConfigPath()
+	}
+	return "", fmt.Errorf("extra MCP servers are not supported for %s", client)
+}
+
+// mcpKeyValue returns the API key header value to write for client: a
+// reference to the --env variable with --env-ref, otherwise the key itself
+func mcpKeyValue(client, apiKey string) string {
+	if mcpEnvRef {
+		if ref, ok := mcpconfig.EnvReference(client, strings.TrimSpace(mcpEnvVar)); ok {
+			return ref
+		}
+	}
+	return apiKey
+}
+
+func mcpKeyNote() string {
+	if mcpEnvRef {
+		return fmt.Sprintf("reads %s from the environment", strings.TrimSpace(mcpEnvVar))
+	}
+	return "stores API key in the file"
+}
+
 func mustResolveAPIKey() string {
 	if strings.TrimSpace(mcpAPIKey) != "" {
 		return strings.TrimSpace(mcpAPIKey)
@@ -299,7 +783,7 @@ func mustResolveAPIKey() string {
 	}
 
 	var apiKey string
-	if err := survey.AskOne(&survey.Password{
+	if err := prompts.AskOne(&survey.Password{
 		Message: "Enter your DataGen API key:",
 	}, &apiKey); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/datagendev/datagen-cli/internal/backup"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/debuglog"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateConfigPath string
+	migrateDryRun     bool
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade datagen.toml to the current config format",
+	Long: `Upgrade datagen.toml to the config format version this datagen writes.
+
+The format version is the "version" key at the top of datagen.toml. Files in
+an older format still load (they are upgraded in memory each time), but
+'datagen migrate' rewrites the file once so it matches what datagen reads and
+new settings can be added to it. Files it includes are upgraded too. Only the
+migrated keys change: comments, layout and every other setting are left as
+written. The changes are printed as a diff; the previous file can be brought
+back with 'datagen restore'.
+
+A file in a newer format than this datagen supports is refused; upgrade
+datagen instead.
+
+Examples:
+  datagen migrate
+  datagen migrate --dry-run
+  datagen migrate -c ./my-project/datagen.toml`,
+	Args: cobra.NoArgs,
+	RunE: runMigrate,
+}
+
+func init() {
+	migrateCmd.Flags().StringVarP(&migrateConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml configuration file (or datagen.yaml/datagen.json)")
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Print the changes without writing datagen.toml")
+	migrateCmd.MarkFlagFilename("config", "toml", "yaml", "yml", "json")
+}
+
+func runMigrate(cmd *cobra.Command, args []string) error {
+	path := config.FindConfig(migrateConfigPath)
+	migrations, err := config.MigrateFiles(path)
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+	if len(migrations) == 0 {
+		output.Printf("✓ %s is already at config format version %d\n", path, config.CurrentVersion)
+		return nil
+	}
+
+	for _, m := range migrations {
+		fmt.Print(debuglog.Diff(m.Before, m.After))
+		fmt.Println()
+	}
+	if migrateDryRun {
+		for _, m := range migrations {
+			output.Printf("%s would be upgraded to config format version %d:\n", m.Path, config.CurrentVersion)
+			for _, c := range m.Changes {
+				output.Printf("  • %s\n", c)
+			}
+		}
+		return nil
+	}
+
+	for _, m := range migrations {
+		if err := backup.Save(m.Path); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", m.Path, err)
+		}
+	}
+	for _, m := range migrations {
+		debuglog.FileWrite(m.Path, []byte(m.After))
+		if err := os.WriteFile(m.Path, []byte(m.After), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", m.Path, err)
+		}
+		output.Printf("✓ Upgraded %s to config format version %d\n", m.Path, config.CurrentVersion)
+		for _, c := range m.Changes {
+			output.Printf("  ✓ %s\n", c)
+		}
+	}
+	fmt.Println("\nThe previous version can be restored with:")
+	for _, m := range migrations {
+		fmt.Println("  datagen restore " + m.Path)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/datagendev/datagen-cli/internal/agents"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/models"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	modelsLive   bool
+	modelsConfig string
+)
+
+var modelsCmd = &cobra.Command{
+	Use:   "models [model...]",
+	Short: "List Claude models or check model names",
+	Long: `Without arguments, list the Claude models agents can name in their frontmatter
+or in datagen.toml, with retired and deprecated models and their replacements.
+With arguments, check each model name and suggest the nearest current model;
+exits non-zero when a name is retired or unknown.
+
+The catalog ships with the CLI. --live adds the models your Anthropic API key
+can use today (the key is read from the environment or .env, like 'datagen
+diagnose').
+
+datagen build runs the same check on every service's model and fails on
+retired models.
+
+Examples:
+  datagen models
+  datagen models claude-3-5-sonnet-20241022
+  datagen models claude-sonnet-4-6 --live`,
+	RunE: runModels,
+}
+
+func init() {
+	modelsCmd.Flags().BoolVar(&modelsLive, "live", false, "Also fetch the models your Anthropic API key can use")
+	modelsCmd.Flags().StringVarP(&modelsConfig, "config", "c", "datagen.toml", "datagen.toml naming the Claude API key variable (with --live)")
+}
+
+func runModels(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	catalog := models.Builtin()
+	if modelsLive {
+		key, err := findClaudeKey(".", modelsConfig)
+		if err != nil {
+			return err
+		}
+		ids, err := models.Fetch(context.Background(), nil, models.DefaultBaseURL, key)
+		if err != nil {
+			return err
+		}
+		catalog = catalog.WithLive(ids)
+	}
+
+	if len(args) == 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "MODEL\tSTATUS\tREPLACEMENT")
+		for _, m := range catalog {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", m.ID, m.Status, m.Replacement)
+		}
+		return w.Flush()
+	}
+
+	bad := 0
+	for _, model := range args {
+		verdict := catalog.Check(model)
+		if msg := verdict.String(); msg != "" {
+			fmt.Printf("✗ %s\n", msg)
+			if verdict.Status != models.StatusDeprecated {
+				bad++
+			}
+			continue
+		}
+		fmt.Printf("✓ %s\n", model)
+	}
+	if bad > 0 {
+		return fmt.Errorf("%d model name(s) need attention", bad)
+	}
+	return nil
+}
+
+// checkServiceModels checks the model each service runs: its model in
+// datagen.toml, else the frontmatter model of each of its prompts. Retired
+// models are errors; deprecated and unknown ones are printed as warnings.
+// Services on Bedrock or Vertex name models in the provider's own format and
+// are skipped.
+func checkServiceModels(cfg *config.DatagenConfig, configDir string, catalog models.Catalog) error {
+	for _, svc := range cfg.Services {
+		if svc.ProviderName() != config.ProviderAnthropic {
+			continue
+		}
+		type use struct{ model, where string }
+		var uses []use
+		if svc.Model != "" {
+			uses = append(uses, use{svc.Model, "datagen.toml"})
+		} else {
+			for _, prompt := range svc.PromptFiles() {
+				p, err := agents.LoadPrompt(filepath.Join(configDir, prompt))
+				if err != nil || p.Model == "" {
+					continue
+				}
+				uses = append(uses, use{p.Model, prompt})
+			}
+		}
+		if svc.FallbackModel != "" {
+			uses = append(uses, use{svc.FallbackModel, "datagen.toml fallback_model"})
+		}
+		for _, u := range uses {
+			verdict := catalog.Check(u.model)
+			switch verdict.Status {
+			case models.StatusCurrent:
+			case models.StatusRetired:
+				return fmt.Errorf("service %s (%s): %s", svc.Name, u.where, verdict)
+			default:
+				output.Warnf("service %s (%s): %s\n", svc.Name, u.where, verdict)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/datagendev/datagen-cli/internal/auth"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Switch between DataGen accounts",
+	Long: `Manage named profiles for multiple DataGen accounts.
+
+Create a profile with 'datagen login --profile <name>'. The active profile is
+the one chosen with 'datagen profile use', unless DATAGEN_PROFILE is set, and
+every command that needs DATAGEN_API_KEY (mcp, secrets, state, ...) resolves it
+from there. The "default" profile is the key saved by a plain 'datagen login'.
+
+Examples:
+  datagen login --profile work
+  datagen profile list
+  datagen profile use work
+  DATAGEN_PROFILE=personal datagen secrets list`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles and show which one is active",
+	Args:  cobra.NoArgs,
+	RunE:  runProfileList,
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Make a profile the active one",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfileUse,
+}
+
+func init() {
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileUseCmd)
+}
+
+func runProfileList(cmd *cobra.Command, args []string) error {
+	profiles, err := auth.ListProfiles()
+	if err != nil {
+		return err
+	}
+	active := auth.ActiveProfile()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tPROFILE\tSTORAGE")
+	for _, p := range profiles {
+		marker := ""
+		if p.Name == active {
+			marker = "*"
+		}
+		storage := "profiles file"
+		switch {
+		case p.Name == auth.DefaultProfile:
+			storage = "environment / keychain / shell profile"
+		case p.Keychain:
+			storage = "keychain"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", marker, p.Name, storage)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if strings.TrimSpace(os.Getenv(auth.ProfileEnvVar)) != "" {
+		fmt.Printf("\nActive profile set by %s\n", auth.ProfileEnvVar)
+	}
+	return nil
+}
+
+func runProfileUse(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := auth.UseProfile(name); err != nil {
+		return err
+	}
+	output.Printf("✅ Active profile: %s\n", name)
+	if env := strings.TrimSpace(os.Getenv(auth.ProfileEnvVar)); env != "" && env != name {
+		fmt.Fprintf(os.Stderr, "Note: %s=%s still overrides it in this shell\n", auth.ProfileEnvVar, env)
+	}
+	return nil
+}
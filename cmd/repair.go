@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/datagendev/datagen-cli/internal/backup"
+	"github.com/datagendev/datagen-cli/internal/codegen"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	repairOutputDir  string
+	repairConfigPath string
+	repairRegenerate bool
+)
+
+var repairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Restore the marker comments 'datagen add' injects between",
+	Long: `Restore the marker comments in app/main.py and app/models.py that 'datagen
+add' injects new services between, after they were deleted or mangled.
+
+Each missing START/END marker is put back where generated projects have it:
+around the load_agent calls in lifespan, around the endpoint handlers (which
+end at the /health route) and around the service models. The rest of the code
+is left alone. Nothing is written unless every missing marker can be placed.
+
+When a marker has nowhere to go (for example, main.py no longer loads agents
+in lifespan), --regenerate rebuilds app/main.py and app/models.py from
+datagen.toml instead. Customizations in those two files are lost; the old
+versions are kept and can be brought back with 'datagen restore'.
+
+Examples:
+  datagen repair
+  datagen repair -o ./my-project
+  datagen repair --regenerate`,
+	Args: cobra.NoArgs,
+	RunE: runRepair,
+}
+
+func init() {
+	repairCmd.Flags().StringVarP(&repairOutputDir, "output", "o", ".", "Project directory")
+	repairCmd.Flags().StringVarP(&repairConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml configuration file (for --regenerate)")
+	repairCmd.Flags().BoolVar(&repairRegenerate, "regenerate", false, "Rebuild app/main.py and app/models.py from datagen.toml, discarding their customizations")
+	repairCmd.MarkFlagDirname("output")
+	repairCmd.MarkFlagFilename("config", "toml")
+}
+
+func runRepair(cmd *cobra.Command, args []string) error {
+	lock, err := lockProject(repairOutputDir, "repair")
+	if err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	defer lock.Release()
+
+	if repairRegenerate {
+		return regenerateMarkedFiles(cmd)
+	}
+
+	missing, err := codegen.MissingMarkers(repairOutputDir)
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+	if len(missing) == 0 {
+		output.Println("✓ datagen markers intact in app/main.py and app/models.py")
+		return nil
+	}
+
+	restored, err := codegen.RepairMarkers(repairOutputDir)
+	if errors.Is(err, codegen.ErrMarkersUnrepairable) {
+		return fmt.Errorf("%w\nRun 'datagen repair --regenerate' to rebuild app/main.py and app/models.py from %s (their customizations are lost)", err, repairConfigPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	output.Printf("✓ Restored %d marker(s)\n", len(restored))
+	for _, r := range restored {
+		output.Printf("  ✓ %s\n", r)
+	}
+	fmt.Println("\nReview the changes (git diff), then add or change services with: datagen add, datagen edit or datagen sync")
+	return nil
+}
+
+// regenerateMarkedFiles rewrites main.py and models.py from datagen.toml,
+// backing up the current versions first
+func regenerateMarkedFiles(cmd *cobra.Command) error {
+	cfg, err := config.LoadConfig(repairConfigPath)
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	cmd.SilenceUsage = true
+
+	files := []string{"app/main.py", "app/models.py"}
+	for _, f := range files {
+		if err := backup.Save(filepath.Join(repairOutputDir, f)); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", f, err)
+		}
+	}
+	written, err := codegen.GenerateFiles(cfg, repairOutputDir, files)
+	if err != nil {
+		return err
+	}
+
+	output.Printf("✓ Regenerated from %s\n", repairConfigPath)
+	for _, p := range written {
+		output.Printf("  ✓ %s\n", p)
+	}
+	fmt.Println("\nThe previous versions can be restored with: datagen restore <file>")
+	return nil
+}
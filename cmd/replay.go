@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/debuglog"
+	"github.com/datagendev/datagen-cli/internal/hooks"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/replay"
+	"github.com/spf13/cobra"
+)
+
+var (
+	replayConfigPath string
+	replayTarget     string
+	replayURL        string
+	replayDir        string
+	replayTimeout    time.Duration
+	replayDiff       bool
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <file or dir>...",
+	Short: "Re-send captured requests and compare the responses",
+	Long: `Re-send requests recorded by a generated app with [capture] enabled and
+compare each response with the recorded one, to regression-test prompt
+changes.
+
+Directories are searched for recordings, which replay in the order they were
+captured. Each request goes to the service with the recorded path. Auth
+headers and webhook signatures are rebuilt from the service's env vars (in the
+environment or .env), since recordings never contain them. Masked payload
+fields are sent as "[REDACTED]".
+
+A changed status code is a failure; a changed body is reported, since agent
+replies vary from run to run. --diff prints what changed.
+
+--target local posts to http://localhost:8000 (override with --url); --target
+deployed posts to the public domain of the Railway service linked to the project.
+
+Examples:
+  datagen replay recordings/
+  datagen replay recordings/2026-10-16 --diff
+  datagen replay recordings/ --target deployed`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runReplay,
+}
+
+func init() {
+	replayCmd.Flags().StringVarP(&replayConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml (for auth headers and webhook secrets)")
+	replayCmd.Flags().StringVar(&replayTarget, "target", "local", "Where to send the requests: local or deployed")
+	replayCmd.Flags().StringVar(&replayURL, "url", "", "Base URL to send to (overrides --target)")
+	replayCmd.Flags().StringVarP(&replayDir, "output", "o", ".", "Project directory linked to the platform (for --target deployed)")
+	replayCmd.Flags().DurationVar(&replayTimeout, "timeout", 5*time.Minute, "Timeout per request")
+	replayCmd.Flags().BoolVar(&replayDiff, "diff", false, "Print how each changed response body differs from the recording")
+	replayCmd.MarkFlagFilename("config", "toml")
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	files, err := replay.Find(args)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no recordings found in %s", strings.Join(args, ", "))
+	}
+	baseURL, err := targetBaseURL(replayTarget, replayURL, replayDir)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadConfig(replayConfigPath)
+	if err != nil {
+		output.Warnf("could not load %s (%v); replaying without auth headers\n", replayConfigPath, err)
+		cfg = &config.DatagenConfig{}
+	}
+
+	client := &http.Client{Timeout: replayTimeout}
+	failed, changed := 0, 0
+	for _, file := range files {
+		rec, err := replay.Load(file)
+		if err != nil {
+			output.Warnf("skipping %v\n", err)
+			continue
+		}
+		status, body, duration, err := replayRecording(client, cfg, baseURL, rec)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Printf("✗ %s  %s %s  %v\n", file, rec.Request.Method, rec.Request.Path, err)
+		case status != rec.Response.Status:
+			failed++
+			fmt.Printf("✗ %s  %s %s  HTTP %d, recorded %d\n", file, rec.Request.Method, rec.Request.Path, status, rec.Response.Status)
+		default:
+			note := "same body"
+			before, after := replay.Normalize(rec.ResponseBody()), replay.Normalize(body)
+			if before != after {
+				changed++
+				note = "body changed"
+			}
+			fmt.Printf("✓ %s  %s %s  HTTP %d in %s (recorded %s), %s\n", file, rec.Request.Method, rec.Request.Path, status,
+				duration.Round(time.Millisecond), (time.Duration(rec.Response.DurationMS) * time.Millisecond).Round(time.Millisecond), note)
+			if replayDiff && before != after {
+				fmt.Print(debuglog.Diff(before, after))
+			}
+		}
+	}
+
+	fmt.Printf("\n%d replayed, %d failed, %d with a changed body\n", len(files), failed, changed)
+	if failed > 0 {
+		return fmt.Errorf("%d replayed request(s) failed or changed status", failed)
+	}
+	return nil
+}
+
+// replayRecording sends rec to baseURL with fresh auth and signature headers
+// for the service serving its path
+func replayRecording(client *http.Client, cfg *config.DatagenConfig, baseURL string, rec *replay.Recording) (int, string, time.Duration, error) {
+	url := baseURL + rec.Request.Path
+	if rec.Request.Query != "" {
+		url += "?" + rec.Request.Query
+	}
+	body := rec.RequestBody()
+	req, err := http.NewRequest(rec.Request.Method, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", 0, err
+	}
+	for name, value := range rec.Request.Headers {
+		if !strings.EqualFold(name, "x-request-id") {
+			req.Header.Set(name, value)
+		}
+	}
+	if svc := serviceForPath(cfg, rec.Request.Path); svc != nil {
+		if name, value, ok := serviceAuthHeader(svc); ok {
+			req.Header.Set(name, value)
+		}
+		if svc.Webhook != nil && svc.Webhook.SecretEnv != "" {
+			if secret := lookupLocalEnv(replayDir, svc.Webhook.SecretEnv); secret != "" {
+				event := rec.Request.Headers["x-github-event"]
+				for name, values := range hooks.SignatureHeaders(svc.Webhook.Provider, event, svc.Webhook.SignatureHeader, secret, body, time.Now()) {
+					req.Header[name] = values
+				}
+			}
+		}
+	}
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", time.Since(start), err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data), time.Since(start), err
+}
+
+// serviceForPath returns the service serving path, or nil
+func serviceForPath(cfg *config.DatagenConfig, path string) *config.Service {
+	for i := range cfg.Services {
+		if cfg.Services[i].GetPath() == path {
+			return &cfg.Services[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/datagendev/datagen-cli/internal/backup"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var restoreAll bool
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore [file...]",
+	Short: "Revert changes datagen made to MCP configs and shell profiles",
+	Long: `Revert the last change 'datagen mcp' or 'datagen login' made to a file.
+
+Before rewriting ~/.claude.json, ~/.codex/config.toml, an editor's MCP config
+or a shell profile, datagen keeps a timestamped copy under ~/.datagen/backups
+(the last 10 per file). Without arguments, restore lists the files that can
+be reverted. Restoring a file again reverts the change before that.
+
+Examples:
+  datagen restore
+  datagen restore ~/.codex/config.toml
+  datagen restore --all`,
+	RunE: runRestore,
+}
+
+func init() {
+	restoreCmd.Flags().BoolVar(&restoreAll, "all", false, "Revert the last change to every backed-up file")
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	if restoreAll && len(args) > 0 {
+		return fmt.Errorf("--all cannot be combined with file arguments")
+	}
+	cmd.SilenceUsage = true
+
+	latest, err := backup.Latest()
+	if err != nil {
+		return err
+	}
+
+	paths := args
+	if restoreAll {
+		for _, b := range latest {
+			paths = append(paths, b.Path)
+		}
+	}
+
+	if len(paths) == 0 {
+		if len(latest) == 0 {
+			fmt.Println("No backups found.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FILE\tLAST CHANGED")
+		for _, b := range latest {
+			fmt.Fprintf(w, "%s\t%s\n", b.Path, b.Time.Local().Format("2006-01-02 15:04:05"))
+		}
+		w.Flush()
+		fmt.Println("\nRevert a file with: datagen restore <file>")
+		return nil
+	}
+
+	for _, path := range paths {
+		b, err := backup.Restore(expandHome(path))
+		if err != nil {
+			return err
+		}
+		if b.Absent {
+			output.Printf("✓ Removed %s (it did not exist before %s)\n", b.Path, b.Time.Local().Format("2006-01-02 15:04:05"))
+		} else {
+			output.Printf("✓ Restored %s as of %s\n", b.Path, b.Time.Local().Format("2006-01-02 15:04:05"))
+		}
+	}
+	return nil
+}
+
+// expandHome resolves a leading ~ so paths can be copied from help text
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~")
+	if !ok || (rest != "" && rest[0] != '/' && rest[0] != filepath.Separator) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
@@ -5,12 +5,19 @@ import (
 	"os"
 	"time"
 
+	"github.com/datagendev/datagen-cli/internal/debuglog"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/prompts"
+	"github.com/datagendev/datagen-cli/internal/telemetry"
 	"github.com/datagendev/datagen-cli/internal/version"
 	"github.com/spf13/cobra"
 )
 
 var updateMsg <-chan string
 
+// debugLog is set by --debug
+var debugLog bool
+
 var rootCmd = &cobra.Command{
 	Use:   "datagen",
 	Short: "DataGen CLI - Deploy and manage AI agents",
@@ -19,24 +26,68 @@ discovers Claude Code agents, skills, and commands, and deploys them as live end
 
 Workflow:
   datagen login              Save your DataGen API key
+  datagen profile use        Switch between DataGen accounts
+  datagen whoami             Check which account your API key belongs to
+  datagen doctor             Check your environment for setup problems
+  datagen upgrade            Update datagen to the latest release
+  datagen install railway    Download the Railway CLI (no npm, bash or curl needed)
+  datagen telemetry on/off   Opt in to (or out of) anonymous usage metrics
   datagen mcp                Configure DataGen MCP locally
+  datagen restore            Revert a config or profile change made by mcp/login
   datagen tools list         List deployed custom tools
   datagen tools deploy       Deploy a Python custom tool
   datagen github connect     Install the GitHub App and connect repos
+  datagen agent new "..."    Draft an agent file from a description
   datagen agents list        List discovered agents, skills, and commands
   datagen agents deploy      Deploy an agent/skill/command as a webhook endpoint
   datagen skills list        List discovered skills (shortcut)
   datagen commands list      List discovered commands (shortcut)
-  datagen agents run         Trigger an execution
+  datagen agents run         Trigger an execution (--local to try a prompt locally)
   datagen agents schedule    Set up cron schedules
   datagen agents config      Configure prompts, secrets, and recipients
-  datagen secrets set        Store API keys for agent use`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		// Skip background check for the explicit version command
-		if cmd.Name() == "version" {
-			return
+  datagen secrets set        Store API keys for agent use
+  datagen init -t <name>     Create a project from a starter template
+  datagen build              Generate a FastAPI project from datagen.toml
+  datagen adopt              Bring an existing FastAPI project under datagen
+  datagen import openapi     Create datagen.toml from an OpenAPI spec
+  datagen migrate            Upgrade datagen.toml to the current config format
+  datagen sync               Add/remove services to match .claude/agents
+  datagen edit <service>     Change a service's settings and regenerate its code
+  datagen models             List Claude models or check model names
+  datagen generate <file>    Add one scaffolding file (Dockerfile, railway.json...) to a project
+  datagen deps refresh       Pin the runtime's Python packages to their latest releases
+  datagen logs               Stream logs from a deployed project
+  datagen diagnose           Ask Claude why a deploy command failed
+  datagen compare <url>      Check a deployment for drift from local config
+  datagen destroy            Tear down a project's platform resources
+  datagen invoke <service>   Call a service on a locally running project
+  datagen history list       Browse recorded local invocations
+  datagen env diff           Compare local .env with platform variables
+  datagen hooks register     Subscribe provider webhooks to a deployed service
+  datagen simulate <event>   Send a signed sample provider event to a webhook
+  datagen replay <dir>       Re-send captured requests and compare responses
+  datagen eval               Run evals/*.yaml against services and score replies
+  datagen loadtest           Load a deployed service and report latency and cost
+  datagen state push/pull    Sync encrypted CLI state between machines
+  datagen export archive     Pack a generated project into a tarball/zip`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := output.Configure(); err != nil {
+			return err
+		}
+		if debugLog {
+			path, err := debuglog.Start(os.Args, version.Version)
+			if err != nil {
+				output.Warnf("could not start debug log: %v", err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Debug log: %s\n", path)
+			}
+		}
+		// Skip background check for commands that check explicitly
+		if cmd.Name() == "version" || cmd.Name() == "upgrade" || output.Quiet {
+			return nil
 		}
 		updateMsg = version.CheckForUpdate()
+		return nil
 	},
 	PersistentPostRun: func(cmd *cobra.Command, args []string) {
 		if updateMsg == nil {
@@ -54,16 +105,34 @@ Workflow:
 
 // Execute runs the root command
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	start := time.Now()
+	cmd, err := rootCmd.ExecuteC()
+	debuglog.Close(err)
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		offerDiagnosis(err)
+	}
+	telemetry.Send(telemetry.NewEvent(telemetryCommand(cmd), time.Since(start), version.Version, err))
+	if err != nil {
 		os.Exit(1)
 	}
 }
 
 func init() {
 	rootCmd.Version = version.Version
+	rootCmd.PersistentFlags().BoolVar(&prompts.SimpleMode, "simple-prompts", prompts.SimpleMode,
+		"Ask numbered plain-text questions instead of interactive menus (screen-reader friendly; or set DATAGEN_SIMPLE_PROMPTS=1)")
+	rootCmd.PersistentFlags().BoolVarP(&output.Quiet, "quiet", "q", false, "Only print results, warnings and errors (for CI)")
+	rootCmd.PersistentFlags().BoolVar(&output.Verbose, "verbose", false, "Show every external command datagen runs (railway, op, vault...) with timing")
+	rootCmd.PersistentFlags().BoolVar(&debugLog, "debug", os.Getenv("DATAGEN_DEBUG") != "",
+		"Write a session log of commands run, API calls and file changes to ~/.datagen/logs (or set DATAGEN_DEBUG=1)")
+	rootCmd.PersistentFlags().BoolVar(&output.NoColor, "no-color", output.NoColor, "Disable colored output (or set NO_COLOR)")
 
 	rootCmd.AddCommand(loginCmd)
+	rootCmd.AddCommand(profileCmd)
+	rootCmd.AddCommand(whoamiCmd)
+	rootCmd.AddCommand(diagnoseCmd)
+	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(mcpCmd)
 	rootCmd.AddCommand(toolsCmd)
 	rootCmd.AddCommand(githubCmd)
@@ -71,5 +140,36 @@ func init() {
 	rootCmd.AddCommand(skillsCmd)
 	rootCmd.AddCommand(commandsCmd)
 	rootCmd.AddCommand(secretsCmd)
+	rootCmd.AddCommand(startCmd)
+	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(addCmd)
+	rootCmd.AddCommand(buildCmd)
+	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(compareCmd)
+	rootCmd.AddCommand(destroyCmd)
+	rootCmd.AddCommand(invokeCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(envCmd)
+	rootCmd.AddCommand(hooksCmd)
+	rootCmd.AddCommand(simulateCmd)
+	rootCmd.AddCommand(replayCmd)
+	rootCmd.AddCommand(evalCmd)
+	rootCmd.AddCommand(loadtestCmd)
+	rootCmd.AddCommand(stateCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(depsCmd)
+	rootCmd.AddCommand(adoptCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(repairCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(editCmd)
+	rootCmd.AddCommand(modelsCmd)
+	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(upgradeCmd)
+	rootCmd.AddCommand(installCmd)
+	rootCmd.AddCommand(telemetryCmd)
 	rootCmd.AddCommand(versionCmd)
 }
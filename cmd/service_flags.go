@@ -0,0 +1,357 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Service flags answer the add/start survey questions, so a service can be
+// created from a script; --name switches the survey off
+var (
+	serviceName            string
+	serviceType            string
+	servicePath            string
+	serviceDescription     string
+	servicePrompt          string
+	serviceFields          []string
+	serviceOutputFields    []string
+	serviceTools           []string
+	serviceAuth            string
+	serviceHeader          string
+	serviceAuthEnv         string
+	serviceResponseFormat  string
+	serviceTimeout         int
+	serviceRateLimit       int
+	serviceSignature       string
+	serviceSignatureHeader string
+	serviceSecretEnv       string
+	serviceRetries         int
+	serviceBackoff         string
+	serviceSSEFormat       string
+	serviceBufferSize      int
+	serviceFraming         string
+)
+
+var (
+	serviceTypes      = []string{"webhook", "api", "streaming"}
+	fieldTypes        = []string{"str", "int", "float", "bool", "list", "dict", "any"}
+	toolNames         = []string{"searchTools", "executeTools", "executeCode", "getToolDetails"}
+	authTypes         = []string{"api_key", "bearer_token", "oauth", "none"}
+	responseFormats   = []string{"json", "text", "custom"}
+	signatureMethods  = []string{"hmac_sha256", "custom", "none"}
+	backoffStrategies = []string{"exponential", "linear"}
+	sseFormats        = []string{"default", "json", "custom"}
+	framings          = []string{config.FramingSSE, config.FramingNDJSON}
+)
+
+// typeFlags are the flags that only apply to one service type
+var typeFlags = map[string][]string{
+	"api":       {"output-field", "response-format", "timeout", "rate-limit"},
+	"webhook":   {"signature", "signature-header", "secret-env", "retries", "backoff"},
+	"streaming": {"sse-format", "buffer-size", "framing"},
+}
+
+func addServiceFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&serviceName, "name", "", "Service name; answers every question from flags instead of asking")
+	cmd.Flags().StringVar(&serviceType, "type", "api", "Service type: webhook, api or streaming")
+	cmd.Flags().StringVar(&servicePath, "path", "", "Endpoint path (default /api/<name> or /webhook/<name>)")
+	cmd.Flags().StringVar(&serviceDescription, "description", "", "What the service does (required with --name)")
+	cmd.Flags().StringVar(&servicePrompt, "prompt", "", "Agent prompt file (default .claude/agents/<name>.md)")
+	cmd.Flags().StringArrayVar(&serviceFields, "field", nil, "Input field as name:type[:required|optional[:default]] (repeatable)")
+	cmd.Flags().StringArrayVar(&serviceOutputFields, "output-field", nil, "Output field of an api service, in the --field format (repeatable)")
+	cmd.Flags().StringSliceVar(&serviceTools, "tools", nil, "DataGen tools: searchTools, executeTools, executeCode, getToolDetails, or none (default executeTools,getToolDetails, or [defaults])")
+	cmd.Flags().StringVar(&serviceAuth, "auth", "", "Authentication: api_key, bearer_token, oauth or none (default api_key, or [defaults])")
+	cmd.Flags().StringVar(&serviceHeader, "auth-header", "", "Header carrying the credential (default X-API-Key, or Authorization for bearer_token)")
+	cmd.Flags().StringVar(&serviceAuthEnv, "auth-env", "", "Environment variable holding the credential (default <NAME>_API_KEY)")
+	cmd.Flags().StringVar(&serviceResponseFormat, "response-format", "json", "api: response format: json, text or custom")
+	cmd.Flags().IntVar(&serviceTimeout, "timeout", 0, "api: timeout in seconds (default 30, or [defaults])")
+	cmd.Flags().IntVar(&serviceRateLimit, "rate-limit", 0, "api: requests per minute; 0 turns rate limiting off")
+	cmd.Flags().StringVar(&serviceSignature, "signature", "none", "webhook: signature verification: hmac_sha256, custom or none")
+	cmd.Flags().StringVar(&serviceSignatureHeader, "signature-header", "X-Signature", "webhook: header carrying the hmac_sha256 signature")
+	cmd.Flags().StringVar(&serviceSecretEnv, "secret-env", "HMAC_SECRET", "webhook: environment variable holding the hmac_sha256 secret")
+	cmd.Flags().IntVar(&serviceRetries, "retries", 0, "webhook: max retries; 0 turns the retry policy off")
+	cmd.Flags().StringVar(&serviceBackoff, "backoff", "exponential", "webhook: retry backoff: exponential or linear")
+	cmd.Flags().StringVar(&serviceSSEFormat, "sse-format", "default", "streaming: event format: default, json or custom")
+	cmd.Flags().IntVar(&serviceBufferSize, "buffer-size", 8192, "streaming: buffer size in bytes")
+	cmd.Flags().StringVar(&serviceFraming, "framing", config.FramingSSE, "streaming: framing: sse or ndjson")
+	cmd.MarkFlagFilename("prompt", "md")
+}
+
+// serviceFlagsUsed reports whether any service flag was given, and whether
+// that includes --name
+func serviceFlagsUsed(cmd *cobra.Command) (used, named bool) {
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if isServiceFlag(f.Name) {
+			used = true
+		}
+	})
+	return used, cmd.Flags().Changed("name")
+}
+
+func isServiceFlag(name string) bool {
+	if slices.Contains([]string{"name", "type", "path", "description", "prompt", "field", "tools", "auth", "auth-header", "auth-env"}, name) {
+		return true
+	}
+	for _, names := range typeFlags {
+		if slices.Contains(names, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceFromFlags builds the service the survey would collect from the
+// service flags. Like the survey, settings [defaults] covers are left unset
+// unless a flag sets them; defaults may be nil.
+func serviceFromFlags(cmd *cobra.Command, defaults *config.ServiceDefaults) (*config.Service, error) {
+	if defaults == nil {
+		defaults = &config.ServiceDefaults{}
+	}
+	if serviceName == "" {
+		return nil, fmt.Errorf("--name must not be empty")
+	}
+	if serviceDescription == "" {
+		return nil, fmt.Errorf("--description is required with --name")
+	}
+	if err := oneOf("type", serviceType, serviceTypes); err != nil {
+		return nil, err
+	}
+	for otherType, names := range typeFlags {
+		if otherType == serviceType {
+			continue
+		}
+		for _, name := range names {
+			if cmd.Flags().Changed(name) {
+				return nil, fmt.Errorf("--%s only applies to %s services", name, otherType)
+			}
+		}
+	}
+
+	svc := &config.Service{
+		Name:        serviceName,
+		Type:        serviceType,
+		Description: serviceDescription,
+		Prompt:      servicePrompt,
+		InputSchema: config.Schema{Fields: []config.Field{}},
+	}
+	if svc.Prompt == "" {
+		svc.Prompt = fmt.Sprintf(".claude/agents/%s.md", svc.Name)
+	}
+	path := servicePath
+	if serviceType == "webhook" {
+		if path == "" {
+			path = fmt.Sprintf("/webhook/%s", svc.Name)
+		}
+		svc.WebhookPath = path
+	} else {
+		if path == "" {
+			path = fmt.Sprintf("/api/%s", svc.Name)
+		}
+		svc.APIPath = path
+	}
+
+	var err error
+	if svc.InputSchema.Fields, err = parseFieldFlags("field", serviceFields); err != nil {
+		return nil, err
+	}
+	if len(serviceOutputFields) > 0 {
+		svc.OutputSchema = &config.Schema{}
+		if svc.OutputSchema.Fields, err = parseFieldFlags("output-field", serviceOutputFields); err != nil {
+			return nil, err
+		}
+	}
+
+	if cmd.Flags().Changed("tools") {
+		if svc.AllowedTools, err = parseToolsFlag(serviceTools); err != nil {
+			return nil, err
+		}
+		// No tools needs no table, unless it would inherit the default tools
+		if *svc.AllowedTools == (config.AllowedTools{}) && defaults.AllowedTools == nil {
+			svc.AllowedTools = nil
+		}
+	} else if defaults.AllowedTools == nil {
+		svc.AllowedTools = &config.AllowedTools{ExecuteTools: true, GetToolDetails: true}
+	}
+
+	switch serviceType {
+	case "webhook":
+		if svc.Webhook, err = webhookFromFlags(); err != nil {
+			return nil, err
+		}
+	case "api":
+		if svc.API, err = apiFromFlags(defaults.Timeout); err != nil {
+			return nil, err
+		}
+	case "streaming":
+		if svc.Streaming, err = streamingFromFlags(); err != nil {
+			return nil, err
+		}
+	}
+
+	if svc.Auth, err = authFromFlags(cmd, svc.Name, defaults.Auth != nil); err != nil {
+		return nil, err
+	}
+	return svc, nil
+}
+
+// parseFieldFlags parses name:type[:required|optional[:default]] specs; the
+// type defaults to str and a field is optional unless marked required
+func parseFieldFlags(flag string, specs []string) ([]config.Field, error) {
+	fields := []config.Field{}
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, ":", 4)
+		field := config.Field{Name: strings.TrimSpace(parts[0]), Type: "str"}
+		if field.Name == "" {
+			return nil, fmt.Errorf("--%s %q: missing field name", flag, spec)
+		}
+		if len(parts) > 1 && parts[1] != "" {
+			field.Type = parts[1]
+		}
+		if !slices.Contains(fieldTypes, field.Type) {
+			return nil, fmt.Errorf("--%s %q: type must be one of %s", flag, spec, strings.Join(fieldTypes, ", "))
+		}
+		if len(parts) > 2 {
+			switch parts[2] {
+			case "required":
+				field.Required = true
+			case "optional", "":
+			default:
+				return nil, fmt.Errorf("--%s %q: expected required or optional, got %q", flag, spec, parts[2])
+			}
+		}
+		if len(parts) > 3 {
+			field.Default = parts[3]
+			if _, err := field.DefaultValue(); err != nil {
+				return nil, fmt.Errorf("--%s %q: %w", flag, spec, err)
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// parseToolsFlag turns --tools names into allowed tools; "none" allows none
+func parseToolsFlag(names []string) (*config.AllowedTools, error) {
+	tools := &config.AllowedTools{}
+	for _, name := range names {
+		switch name {
+		case "searchTools":
+			tools.SearchTools = true
+		case "executeTools":
+			tools.ExecuteTools = true
+		case "executeCode":
+			tools.ExecuteCode = true
+		case "getToolDetails":
+			tools.GetToolDetails = true
+		case "none":
+		default:
+			return nil, fmt.Errorf("--tools: unknown tool %q (tools: %s, or none)", name, strings.Join(toolNames, ", "))
+		}
+	}
+	return tools, nil
+}
+
+func webhookFromFlags() (*config.WebhookConfig, error) {
+	if err := oneOf("signature", serviceSignature, signatureMethods); err != nil {
+		return nil, err
+	}
+	if err := oneOf("backoff", serviceBackoff, backoffStrategies); err != nil {
+		return nil, err
+	}
+	if serviceRetries < 0 {
+		return nil, fmt.Errorf("--retries must not be negative")
+	}
+	webhook := &config.WebhookConfig{SignatureVerification: serviceSignature}
+	if serviceSignature == "hmac_sha256" {
+		webhook.SignatureHeader = serviceSignatureHeader
+		webhook.SecretEnv = serviceSecretEnv
+	}
+	if serviceRetries > 0 {
+		webhook.RetryEnabled = true
+		webhook.MaxRetries = serviceRetries
+		webhook.BackoffStrategy = serviceBackoff
+	}
+	return webhook, nil
+}
+
+func apiFromFlags(defaultTimeout int) (*config.APIConfig, error) {
+	if err := oneOf("response-format", serviceResponseFormat, responseFormats); err != nil {
+		return nil, err
+	}
+	if serviceTimeout < 0 || serviceRateLimit < 0 {
+		return nil, fmt.Errorf("--timeout and --rate-limit must not be negative")
+	}
+	api := &config.APIConfig{ResponseFormat: serviceResponseFormat, Timeout: serviceTimeout}
+	if api.Timeout == 0 && defaultTimeout == 0 {
+		api.Timeout = 30
+	}
+	if api.Timeout == defaultTimeout {
+		// Inherited from [defaults]
+		api.Timeout = 0
+	}
+	if serviceRateLimit > 0 {
+		api.RateLimitEnabled = true
+		api.RateLimitRPM = serviceRateLimit
+	}
+	return api, nil
+}
+
+func streamingFromFlags() (*config.StreamingConfig, error) {
+	if err := oneOf("sse-format", serviceSSEFormat, sseFormats); err != nil {
+		return nil, err
+	}
+	if err := oneOf("framing", serviceFraming, framings); err != nil {
+		return nil, err
+	}
+	if serviceBufferSize <= 0 {
+		return nil, fmt.Errorf("--buffer-size must be positive")
+	}
+	return &config.StreamingConfig{Format: serviceSSEFormat, BufferSize: serviceBufferSize, Framing: serviceFraming}, nil
+}
+
+// authFromFlags returns the service's auth; without --auth it inherits the
+// default auth when there is one and uses an api key otherwise
+func authFromFlags(cmd *cobra.Command, name string, hasDefault bool) (*config.Auth, error) {
+	authType := serviceAuth
+	if authType == "" {
+		if hasDefault && !cmd.Flags().Changed("auth-header") && !cmd.Flags().Changed("auth-env") {
+			return nil, nil
+		}
+		authType = "api_key"
+	}
+	if err := oneOf("auth", authType, authTypes); err != nil {
+		return nil, err
+	}
+	if authType == "none" {
+		if hasDefault {
+			// Without an auth table the service would inherit the default
+			return &config.Auth{Type: "none"}, nil
+		}
+		return nil, nil
+	}
+
+	auth := &config.Auth{Type: authType, Header: serviceHeader, EnvVar: serviceAuthEnv}
+	if auth.Header == "" {
+		auth.Header = "X-API-Key"
+		if authType == "bearer_token" {
+			auth.Header = "Authorization"
+		}
+	}
+	if auth.EnvVar == "" {
+		auth.EnvVar = strings.ToUpper(name) + "_API_KEY"
+	}
+	return auth, nil
+}
+
+// oneOf checks a flag's value against its allowed values
+func oneOf(flag, value string, allowed []string) error {
+	if !slices.Contains(allowed, value) {
+		return fmt.Errorf("--%s must be one of %s", flag, strings.Join(allowed, ", "))
+	}
+	return nil
+}
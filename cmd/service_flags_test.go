@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// parseServiceFlags registers the service flags on a fresh command, which
+// resets them to their defaults, and parses args
+func parseServiceFlags(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	cmd := &cobra.Command{Use: "add"}
+	addServiceFlags(cmd)
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("ParseFlags(%v): %v", args, err)
+	}
+	return cmd
+}
+
+func TestServiceFromFlags(t *testing.T) {
+	cmd := parseServiceFlags(t, "--name", "chat", "--description", "Answer questions",
+		"--field", "question:str:required", "--field", "tone::optional:friendly: and brief", "--output-field", "answer",
+		"--tools", "none", "--auth", "bearer_token", "--rate-limit", "20")
+	got, err := serviceFromFlags(cmd, nil)
+	if err != nil {
+		t.Fatalf("serviceFromFlags: %v", err)
+	}
+
+	want := &config.Service{
+		Name:        "chat",
+		Type:        "api",
+		Description: "Answer questions",
+		Prompt:      ".claude/agents/chat.md",
+		APIPath:     "/api/chat",
+		InputSchema: config.Schema{Fields: []config.Field{
+			{Name: "question", Type: "str", Required: true},
+			{Name: "tone", Type: "str", Default: "friendly: and brief"},
+		}},
+		OutputSchema: &config.Schema{Fields: []config.Field{{Name: "answer", Type: "str"}}},
+		Auth:         &config.Auth{Type: "bearer_token", Header: "Authorization", EnvVar: "CHAT_API_KEY"},
+		API:          &config.APIConfig{ResponseFormat: "json", Timeout: 30, RateLimitEnabled: true, RateLimitRPM: 20},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("serviceFromFlags() =\n %+v\nwant\n %+v", got, want)
+	}
+}
+
+func TestServiceFromFlags_Webhook(t *testing.T) {
+	cmd := parseServiceFlags(t, "--name", "signup", "--type", "webhook", "--description", "Enrich signups",
+		"--signature", "hmac_sha256", "--retries", "5", "--auth", "none")
+	got, err := serviceFromFlags(cmd, nil)
+	if err != nil {
+		t.Fatalf("serviceFromFlags: %v", err)
+	}
+	wantHook := &config.WebhookConfig{SignatureVerification: "hmac_sha256", SignatureHeader: "X-Signature", SecretEnv: "HMAC_SECRET", RetryEnabled: true, MaxRetries: 5, BackoffStrategy: "exponential"}
+	if got.WebhookPath != "/webhook/signup" || got.API != nil || got.Auth != nil || !reflect.DeepEqual(got.Webhook, wantHook) {
+		t.Fatalf("webhook service = %+v, webhook %+v", got, got.Webhook)
+	}
+	if !reflect.DeepEqual(got.AllowedTools, &config.AllowedTools{ExecuteTools: true, GetToolDetails: true}) {
+		t.Fatalf("tools = %+v, want the survey's default tools", got.AllowedTools)
+	}
+}
+
+func TestServiceFromFlags_Defaults(t *testing.T) {
+	defaults := &config.ServiceDefaults{
+		Timeout:      60,
+		AllowedTools: &config.AllowedTools{SearchTools: true},
+		Auth:         &config.Auth{Type: "api_key", Header: "X-API-Key", EnvVar: "SERVICE_API_KEY"},
+	}
+
+	got, err := serviceFromFlags(parseServiceFlags(t, "--name", "chat", "--description", "Answers"), defaults)
+	if err != nil {
+		t.Fatalf("serviceFromFlags: %v", err)
+	}
+	if got.AllowedTools != nil || got.Auth != nil || got.API == nil || got.API.Timeout != 0 {
+		t.Fatalf("service should inherit its defaults: %+v, api %+v", got, got.API)
+	}
+
+	got, err = serviceFromFlags(parseServiceFlags(t, "--name", "chat", "--description", "Answers", "--auth", "none", "--tools", "none", "--timeout", "10"), defaults)
+	if err != nil {
+		t.Fatalf("serviceFromFlags: %v", err)
+	}
+	if !reflect.DeepEqual(got.Auth, &config.Auth{Type: "none"}) || !reflect.DeepEqual(got.AllowedTools, &config.AllowedTools{}) || got.API.Timeout != 10 {
+		t.Fatalf("service should override its defaults: %+v, api %+v", got, got.API)
+	}
+}
+
+func TestServiceFromFlags_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "no description", args: []string{"--name", "chat"}, wantErr: "--description is required"},
+		{name: "unknown type", args: []string{"--type", "grpc"}, wantErr: "--type must be one of webhook, api, streaming"},
+		{name: "flag for another type", args: []string{"--type", "webhook", "--timeout", "10"}, wantErr: "--timeout only applies to api services"},
+		{name: "bad field type", args: []string{"--field", "age:integer"}, wantErr: `--field "age:integer": type must be one of`},
+		{name: "bad required marker", args: []string{"--field", "age:int:yes"}, wantErr: `expected required or optional, got "yes"`},
+		{name: "default of another type", args: []string{"--field", "n:int:optional:three"}, wantErr: `--field "n:int:optional:three": default "three" is not an int`},
+		{name: "unknown tool", args: []string{"--tools", "executeTools,browse"}, wantErr: `unknown tool "browse"`},
+		{name: "unknown auth", args: []string{"--auth", "basic"}, wantErr: "--auth must be one of"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.name != "no description" {
+				args = append([]string{"--name", "chat", "--description", "Answers"}, args...)
+			}
+			if _, err := serviceFromFlags(parseServiceFlags(t, args...), nil); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("serviceFromFlags(%v) error = %v, want %q", args, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceFlagsUsed(t *testing.T) {
+	if used, named := serviceFlagsUsed(parseServiceFlags(t)); used || named {
+		t.Fatalf("no flags: used=%v named=%v", used, named)
+	}
+	if used, named := serviceFlagsUsed(parseServiceFlags(t, "--rate-limit", "5")); !used || named {
+		t.Fatalf("--rate-limit: used=%v named=%v", used, named)
+	}
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/deploy"
+	"github.com/datagendev/datagen-cli/internal/dotenv"
+	"github.com/datagendev/datagen-cli/internal/hooks"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/railway"
+	"github.com/spf13/cobra"
+)
+
+var (
+	simulateConfigPath string
+	simulateTarget     string
+	simulateURL        string
+	simulateService    string
+	simulateDir        string
+	simulateList       bool
+)
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate <provider:event>",
+	Short: "Send a signed sample provider event to a webhook service",
+	Long: `Send a realistic provider payload from the bundled fixtures to the matching
+webhook service, signed the way the provider signs it, so end-to-end flows can be
+tested without real provider events.
+
+The service is the webhook service whose [service.webhook] provider matches the
+event's provider (or --service). The signature uses the service's secret_env value
+from the environment or the local .env file.
+
+--target local posts to http://localhost:8000 (override with --url); --target
+deployed posts to the public domain of the Railway service linked to the project.
+
+Examples:
+  datagen simulate --list
+  datagen simulate stripe:invoice.paid
+  datagen simulate github:push --target deployed
+  datagen simulate slack:app_mention --service slack-bot --url http://localhost:8080`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if simulateList {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: runSimulate,
+}
+
+func init() {
+	simulateCmd.Flags().StringVarP(&simulateConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml configuration file")
+	simulateCmd.Flags().StringVar(&simulateTarget, "target", "local", "Where to send the event: local or deployed")
+	simulateCmd.Flags().StringVar(&simulateURL, "url", "", "Base URL to send to (overrides --target)")
+	simulateCmd.Flags().StringVar(&simulateService, "service", "", "Webhook service to send to (default: the service for the provider)")
+	simulateCmd.Flags().StringVarP(&simulateDir, "output", "o", ".", "Project directory linked to the platform (for --target deployed)")
+	simulateCmd.Flags().BoolVar(&simulateList, "list", false, "List the bundled events")
+	simulateCmd.MarkFlagFilename("config", "toml")
+}
+
+func runSimulate(cmd *cobra.Command, args []string) error {
+	if simulateList {
+		for _, name := range hooks.Fixtures() {
+			fmt.Println(name)
+		}
+		return nil
+	}
+
+	provider, body, err := hooks.Fixture(args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(simulateConfigPath)
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	svc, err := simulateTargetService(cfg, provider)
+	if err != nil {
+		return err
+	}
+
+	baseURL, err := simulateBaseURL()
+	if err != nil {
+		return err
+	}
+	url := baseURL + svc.GetPath()
+
+	header, secret := "", ""
+	if svc.Webhook != nil {
+		header = svc.Webhook.SignatureHeader
+		if svc.Webhook.SecretEnv != "" {
+			secret = lookupLocalEnv(simulateDir, svc.Webhook.SecretEnv)
+			if secret == "" {
+				output.Warnf("%s is not set; sending the event unsigned\n", svc.Webhook.SecretEnv)
+			}
+		}
+	}
+	_, event, _ := strings.Cut(args[0], ":")
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header = hooks.SignatureHeaders(provider, event, header, secret, body, time.Now())
+	req.Header.Set("Content-Type", "application/json")
+	if name, value, ok := serviceAuthHeader(svc); ok {
+		req.Header.Set(name, value)
+	}
+
+	output.Printf("📨 Sending %s to %s (%s)\n", args[0], svc.Name, url)
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	fmt.Printf("HTTP %d\n%s\n", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned HTTP %d", svc.Name, resp.StatusCode)
+	}
+	return nil
+}
+
+// simulateTargetService picks --service, or the single webhook service for provider
+func simulateTargetService(cfg *config.DatagenConfig, provider string) (*config.Service, error) {
+	if simulateService != "" {
+		svc := findService(cfg, simulateService)
+		if svc == nil {
+			return nil, fmt.Errorf("service %q not found in %s", simulateService, simulateConfigPath)
+		}
+		if svc.Type != "webhook" {
+			return nil, fmt.Errorf("service %q is a %s service, not a webhook", svc.Name, svc.Type)
+		}
+		return svc, nil
+	}
+
+	var matches []*config.Service
+	for i := range cfg.Services {
+		svc := &cfg.Services[i]
+		if svc.Type == "webhook" && svc.Webhook != nil && svc.Webhook.Provider == provider {
+			matches = append(matches, svc)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no webhook service has provider %q; set [service.webhook] provider or pass --service", provider)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("several webhook services use provider %q; pass --service", provider)
+	}
+}
+
+func simulateBaseURL() (string, error) {
+	return targetBaseURL(simulateTarget, simulateURL, simulateDir)
+}
+
+// targetBaseURL returns url when set, else http://localhost:8000 for the
+// local target or the public domain of the Railway service linked to dir
+// for the deployed one
+func targetBaseURL(target, url, dir string) (string, error) {
+	if url != "" {
+		return strings.TrimRight(url, "/"), nil
+	}
+	switch target {
+	case "local":
+		return "http://localhost:8000", nil
+	case "deployed":
+		link, err := railway.FindLinkedProject(dir)
+		if err != nil {
+			return "", err
+		}
+		domain, err := deploy.NewRailway().URL(deploy.ProjectFromLink(link))
+		if err != nil {
+			return "", err
+		}
+		rememberRailwayLink(link.ProjectPath, link, domain)
+		return domain, nil
+	default:
+		return "", fmt.Errorf("invalid --target %q (expected local or deployed)", target)
+	}
+}
+
+// lookupLocalEnv reads key from the environment, falling back to the .env in
+// the project directory dir
+func lookupLocalEnv(dir, key string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	values, err := dotenv.Load(filepath.Join(dir, ".env"))
+	if err != nil {
+		return ""
+	}
+	return values[key]
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLookupLocalEnvReadsProjectDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("HOOK_SECRET=from-project\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOOK_SECRET", "")
+
+	if got := lookupLocalEnv(dir, "HOOK_SECRET"); got != "from-project" {
+		t.Errorf("lookupLocalEnv = %q, want the value from %s/.env", got, dir)
+	}
+	t.Setenv("HOOK_SECRET", "from-env")
+	if got := lookupLocalEnv(dir, "HOOK_SECRET"); got != "from-env" {
+		t.Errorf("lookupLocalEnv = %q, want the environment to win", got)
+	}
+}
@@ -2,51 +2,112 @@ package cmd
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/datagendev/datagen-cli/internal/agents"
+	"github.com/datagendev/datagen-cli/internal/agentsync"
+	"github.com/datagendev/datagen-cli/internal/codegen"
 	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/gitrepo"
+	"github.com/datagendev/datagen-cli/internal/models"
+	"github.com/datagendev/datagen-cli/internal/output"
 	"github.com/datagendev/datagen-cli/internal/prompts"
+	"github.com/datagendev/datagen-cli/internal/telemetry"
+	"github.com/datagendev/datagen-cli/internal/undo"
 	"github.com/spf13/cobra"
 )
 
 var startOutputDir string
 var startAdvanced bool
-var startAgent string
+var startAgents []string
 var startMode string
+var startAgentsDirs []string
+var startGit bool
+var startFormat string
 
 var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Project setup",
-	Long:  `Start a new DataGen project (defaults-first) from an existing .claude/agents/*.md agent, or use --advanced for the full interactive flow.`,
-	Run:   runStart,
+	Long: `Start a new DataGen project (defaults-first) from an existing agent, or use --advanced for the full interactive flow.
+
+Agents are discovered in any --agents-dir directories, the project's
+.claude/agents and the user-level ~/.claude/agents, including
+subdirectories. When two locations define an agent with the same name, the
+earlier one wins. An agent from outside the project is copied into
+.claude/agents.
+
+Claude Code skills (.claude/skills/<name>/SKILL.md) and slash commands
+(.claude/commands/*.md) in the project and ~/.claude can be deployed the same
+way: their instructions become the service's system prompt, and allowed-tools
+is read like an agent's tools. A skill is copied with its whole directory.
+
+Several agents can be picked at once (or --agent repeated); each gets its own
+mode and becomes a service in the same datagen.toml.
+
+The configuration is written as datagen.toml, or with --format yaml or json as
+datagen.yaml or datagen.json; every command finds it either way.
+
+A .gitignore covering .env, virtualenvs and datagen state is written when the
+directory has none. --git also runs git init and makes the first commit.
+
+--name creates the project with one new service described by the same flags
+as 'datagen add' (--type, --path, --field, --auth...), without asking
+anything; add more services afterwards with 'datagen add --name'.
+
+Examples:
+  datagen start
+  datagen start --agent triage --mode webhook
+  datagen start -o ./bot --name chat --description "Answer questions" \
+    --field "question:str:required" --auth none`,
+	Run: runStart,
 }
 
 func init() {
 	startCmd.Flags().StringVarP(&startOutputDir, "output", "o", ".", "Output directory for project configuration")
 	startCmd.MarkFlagDirname("output")
 	startCmd.Flags().BoolVar(&startAdvanced, "advanced", false, "Use the full interactive flow to create services and agent files")
-	startCmd.Flags().StringVar(&startAgent, "agent", "", "Agent to deploy (agent name or filename under .claude/agents)")
-	startCmd.Flags().StringVar(&startMode, "mode", "", "Deployment mode: webhook or api")
+	startCmd.Flags().StringSliceVar(&startAgents, "agent", nil, "Agent to deploy (agent name or filename under .claude/agents; repeatable)")
+	startCmd.Flags().StringVar(&startMode, "mode", "", "Deployment mode for every agent: webhook or api")
+	startCmd.Flags().StringSliceVar(&startAgentsDirs, "agents-dir", nil, "Also discover agents in this directory (repeatable); searched before .claude/agents and ~/.claude/agents")
+	startCmd.Flags().BoolVar(&startGit, "git", false, "Run git init and commit the new project, unless it is already in a repository")
+	startCmd.Flags().StringVar(&startFormat, "format", config.FormatTOML, "Config file format: toml, yaml or json")
+	addServiceFlags(startCmd)
 }
 
 func runStart(cmd *cobra.Command, args []string) {
-	fmt.Println("🚀 Welcome to DataGen CLI!")
+	used, named := serviceFlagsUsed(cmd)
+	if used && !named {
+		fmt.Fprintln(os.Stderr, "Error: --name is required to create a service from flags")
+		os.Exit(1)
+	}
+	if named && (len(startAgents) > 0 || startMode != "") {
+		fmt.Fprintln(os.Stderr, "Error: --name creates a new service; it cannot be combined with --agent or --mode")
+		os.Exit(1)
+	}
+
+	output.Println("🚀 Welcome to DataGen CLI!")
 	fmt.Println("Let's set up your agent project.")
 	fmt.Println()
 
+	if !slices.Contains(config.Formats, startFormat) {
+		fmt.Fprintf(os.Stderr, "Error: --format must be one of %s\n", strings.Join(config.Formats, ", "))
+		os.Exit(1)
+	}
+
 	// Create output directory if it doesn't exist
 	if err := os.MkdirAll(startOutputDir, 0755); err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
 		os.Exit(1)
 	}
 
-	if startAdvanced {
-		runStartAdvanced()
+	if startAdvanced || named {
+		runStartAdvanced(cmd, named)
 		return
 	}
 
@@ -57,7 +118,9 @@ func runStart(cmd *cobra.Command, args []string) {
 	}
 }
 
-func runStartAdvanced() {
+// runStartAdvanced asks for each service, or takes the one service from the
+// service flags when fromFlags is set
+func runStartAdvanced(cmd *cobra.Command, fromFlags bool) {
 	// Collect root configuration
 	datagenKey, claudeKey, err := prompts.CollectRootConfig()
 	if err != nil {
@@ -72,29 +135,15 @@ func runStartAdvanced() {
 	}
 
 	// Collect services
-	for {
-		fmt.Println("\n📦 Configure a service:")
-		svc, err := prompts.CollectServiceConfig()
+	if fromFlags {
+		svc, err := serviceFromFlags(cmd, nil)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-
 		cfg.Services = append(cfg.Services, *svc)
-
-		// Ask if user wants to add another service
-		addAnother := false
-		if err := survey.AskOne(&survey.Confirm{
-			Message: "Add another service?",
-			Default: false,
-		}, &addAnother); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
-
-		if !addAnother {
-			break
-		}
+	} else {
+		askStartServices(cfg)
 	}
 
 	// Create .claude/agents directory
@@ -105,52 +154,113 @@ func runStartAdvanced() {
 	}
 
 	// Create agent prompt files for each service
-	fmt.Println("\n📝 Creating agent prompt files...")
+	output.Println("\n📝 Creating agent prompt files...")
 	for _, svc := range cfg.Services {
-		if err := createAgentPromptFile(startOutputDir, &svc); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: Could not create prompt file for %s: %v\n", svc.Name, err)
+		if _, err := os.Stat(filepath.Join(startOutputDir, svc.Prompt)); err == nil {
+			output.Printf("  ✓ Using existing %s\n", svc.Prompt)
+		} else if err := createAgentPromptFile(startOutputDir, &svc); err != nil {
+			output.Warnf("Could not create prompt file for %s: %v\n", svc.Name, err)
 		} else {
-			fmt.Printf("  ✓ Created %s\n", svc.Prompt)
+			output.Printf("  ✓ Created %s\n", svc.Prompt)
 		}
 	}
 
 	// Save configuration to output directory
-	configPath := filepath.Join(startOutputDir, "datagen.toml")
+	configPath := filepath.Join(startOutputDir, config.FileName(startFormat))
+	telemetry.TagConfig(cfg)
 	if err := config.SaveConfig(cfg, configPath); err != nil {
 		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
 		os.Exit(1)
 	}
 
 	absPath, _ := filepath.Abs(configPath)
-	fmt.Printf("\n✅ Configuration saved to %s\n", absPath)
+	output.Printf("\n✅ Configuration saved to %s\n", absPath)
+	if err := scaffoldStartProject(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 	fmt.Println("\n📝 Next steps:")
 	if startOutputDir != "." {
 		fmt.Printf("  1. cd %s\n", startOutputDir)
-		fmt.Println("  2. Review and edit datagen.toml if needed")
+		fmt.Printf("  2. Review and edit %s if needed\n", filepath.Base(configPath))
 		fmt.Println("  3. Customize your agent prompt files in .claude/agents/")
 		fmt.Println("  4. Run 'datagen build' to generate the boilerplate code")
-		fmt.Println("  5. Test locally, then run 'datagen deploy railway' to deploy")
+		fmt.Println("  5. Test locally, then deploy with 'datagen env push' and 'railway up'")
 	} else {
-		fmt.Println("  1. Review and edit datagen.toml if needed")
+		fmt.Printf("  1. Review and edit %s if needed\n", filepath.Base(configPath))
 		fmt.Println("  2. Customize your agent prompt files in .claude/agents/")
 		fmt.Println("  3. Run 'datagen build' to generate the boilerplate code")
-		fmt.Println("  4. Test locally, then run 'datagen deploy railway' to deploy")
+		fmt.Println("  4. Test locally, then deploy with 'datagen env push' and 'railway up'")
 	}
 }
 
-func runStartFromExistingAgents() error {
-	sourceAgentsDir := filepath.Join(".claude", "agents")
-	if _, err := os.Stat(sourceAgentsDir); err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("no .claude agents directory found in current directory: %s", sourceAgentsDir)
+// askStartServices asks for services until the user is done, offering the
+// first one's answers as [defaults] for the rest
+func askStartServices(cfg *config.DatagenConfig) {
+	for {
+		fmt.Println("\n📦 Configure a service:")
+		svc, err := prompts.CollectServiceConfig(cfg.Defaults)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		cfg.Services = append(cfg.Services, *svc)
+
+		// Ask if user wants to add another service
+		addAnother := false
+		if err := prompts.AskOne(&survey.Confirm{
+			Message: "Add another service?",
+			Default: false,
+		}, &addAnother); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !addAnother {
+			break
+		}
+
+		// Offer the first service's answers as [defaults] for the rest
+		if cfg.Defaults == nil && (svc.Auth != nil || svc.AllowedTools != nil) {
+			useAsDefaults := false
+			if err := prompts.AskOne(&survey.Confirm{
+				Message: fmt.Sprintf("Use %s's authentication, tools and timeout as defaults for the next services?", svc.Name),
+				Default: true,
+				Help:    "Saved as [defaults] in datagen.toml; each service can still override them",
+			}, &useAsDefaults); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if useAsDefaults {
+				cfg.Defaults = serviceDefaults(svc)
+			}
 		}
-		return err
 	}
+}
 
-	found, err := agents.Discover(sourceAgentsDir)
+// serviceDefaults returns svc's auth, tools and api timeout as [defaults]
+func serviceDefaults(svc *config.Service) *config.ServiceDefaults {
+	d := &config.ServiceDefaults{Auth: svc.Auth, AllowedTools: svc.AllowedTools}
+	if svc.API != nil {
+		d.Timeout = svc.API.Timeout
+	}
+	return d
+}
+
+func runStartFromExistingAgents() error {
+	roots := agents.Roots(".", startAgentsDirs...)
+	found, err := agents.DiscoverRoots(roots)
 	if err != nil {
 		return err
 	}
+	searched := make([]string, len(roots))
+	for i, r := range roots {
+		searched[i] = r.Dir
+	}
+	if len(found) == 0 {
+		return fmt.Errorf("no agents found in %s", strings.Join(searched, ", "))
+	}
 
 	selectable := make([]agents.Agent, 0, len(found))
 	for _, a := range found {
@@ -159,21 +269,35 @@ func runStartFromExistingAgents() error {
 		}
 	}
 	if len(selectable) == 0 {
-		return fmt.Errorf("no selectable agents found in %s (only 'tools: [datagen]' or no tools are supported)", sourceAgentsDir)
+		return fmt.Errorf("no selectable agents found in %s (only 'tools: [datagen]' or no tools are supported)", strings.Join(searched, ", "))
 	}
 
 	sort.Slice(selectable, func(i, j int) bool {
-		return strings.ToLower(filepath.Base(selectable[i].Path)) < strings.ToLower(filepath.Base(selectable[j].Path))
+		return strings.ToLower(selectable[i].RelPath) < strings.ToLower(selectable[j].RelPath)
 	})
 
-	selected, err := chooseAgent(selectable, startAgent)
+	selected, err := chooseAgents(selectable, startAgents)
 	if err != nil {
 		return err
 	}
+	for _, a := range selected {
+		if a.Model == "" {
+			continue
+		}
+		if msg := models.Builtin().Check(a.Model).String(); msg != "" {
+			output.Warnf("%s: %s\n", a.Name, msg)
+		}
+	}
 
-	mode, err := chooseMode(startMode)
-	if err != nil {
-		return err
+	modes := make([]string, len(selected))
+	for i, a := range selected {
+		message := "Deploy this agent as:"
+		if len(selected) > 1 {
+			message = fmt.Sprintf("Deploy %s as:", a.Name)
+		}
+		if modes[i], err = chooseMode(startMode, message); err != nil {
+			return err
+		}
 	}
 
 	// Root configuration defaults to env var names.
@@ -182,101 +306,114 @@ func runStartFromExistingAgents() error {
 		return err
 	}
 
-	// Service config derived from the agent file.
-	rawName := selected.Name
-	if rawName == "" {
-		rawName = strings.TrimSuffix(filepath.Base(selected.Path), filepath.Ext(selected.Path))
-	}
-	serviceName := config.NormalizeServiceName(rawName)
-
-	description := strings.TrimSpace(selected.Description)
-	if description == "" {
-		description = fmt.Sprintf("Deploy agent %s", rawName)
-	}
-
-	promptRel := filepath.ToSlash(filepath.Join(".claude", "agents", filepath.Base(selected.Path)))
-
-	// Ensure the selected agent exists in the output directory (copy when --output != ".").
-	destAgentsDir := filepath.Join(startOutputDir, ".claude", "agents")
-	if err := os.MkdirAll(destAgentsDir, 0755); err != nil {
-		return fmt.Errorf("create agents dir: %w", err)
-	}
-	destAgentPath := filepath.Join(destAgentsDir, filepath.Base(selected.Path))
-	if !samePath(selected.Path, destAgentPath) {
-		if _, err := os.Stat(destAgentPath); err == nil {
-			return fmt.Errorf("agent file already exists in output dir: %s", destAgentPath)
-		}
-		if err := copyFile(selected.Path, destAgentPath); err != nil {
-			return fmt.Errorf("copy agent to output dir: %w", err)
-		}
-	}
-
-	svc := config.Service{
-		Name:        serviceName,
-		Type:        mode,
-		Description: description,
-		Prompt:      promptRel,
-		InputSchema: config.Schema{Fields: []config.Field{}},
-		Auth: &config.Auth{
-			Type:   "api_key",
-			Header: "X-API-Key",
-			EnvVar: config.NormalizeEnvVarName(serviceName) + "_API_KEY",
-		},
-	}
-
-	if selected.Kind == agents.KindDatagenOnly {
-		svc.AllowedTools = config.AllowedTools{
-			ExecuteTools:   true,
-			GetToolDetails: true,
+	taken := map[string]string{}
+	for _, a := range selected {
+		name := config.NormalizeServiceName(agentsync.AgentName(a))
+		if other, ok := taken[name]; ok {
+			return fmt.Errorf("%s and %s would both deploy as service %q; rename one of them in its frontmatter", other, a.RelPath, name)
 		}
+		taken[name] = a.RelPath
 	}
 
-	switch mode {
-	case "webhook":
-		svc.WebhookPath = fmt.Sprintf("/webhook/%s", serviceName)
-		svc.Webhook = &config.WebhookConfig{
-			SignatureVerification: "none",
-			RetryEnabled:          false,
-		}
-	case "api":
-		svc.APIPath = fmt.Sprintf("/api/%s", serviceName)
-		svc.API = &config.APIConfig{
-			ResponseFormat:   "json",
-			Timeout:          30,
-			RateLimitEnabled: false,
+	services := make([]config.Service, 0, len(selected))
+	for i, a := range selected {
+		svc, err := startService(a, modes[i])
+		if err != nil {
+			return err
 		}
-	default:
-		return fmt.Errorf("unsupported mode %q", mode)
+		services = append(services, svc)
 	}
 
 	cfg := &config.DatagenConfig{
 		DatagenAPIKeyEnv: datagenKey,
 		ClaudeAPIKeyEnv:  claudeKey,
-		Services:         []config.Service{svc},
+		Services:         services,
 	}
 
-	configPath := filepath.Join(startOutputDir, "datagen.toml")
+	configPath := filepath.Join(startOutputDir, config.FileName(startFormat))
+	telemetry.TagConfig(cfg)
 	if err := config.SaveConfig(cfg, configPath); err != nil {
 		return fmt.Errorf("saving config: %w", err)
 	}
 
 	absPath, _ := filepath.Abs(configPath)
-	fmt.Printf("\n✅ Configuration saved to %s\n", absPath)
+	output.Printf("\n✅ Configuration saved to %s\n", absPath)
+	if err := scaffoldStartProject(); err != nil {
+		return err
+	}
 	fmt.Println("\n📝 Next steps:")
 	if startOutputDir != "." {
 		fmt.Printf("  1. cd %s\n", startOutputDir)
-		fmt.Println("  2. Review and edit datagen.toml if needed")
+		fmt.Printf("  2. Review and edit %s if needed\n", filepath.Base(configPath))
 		fmt.Println("  3. Run 'datagen build' to generate the boilerplate code")
-		fmt.Println("  4. Test locally, then run 'datagen deploy railway' to deploy")
+		fmt.Println("  4. Test locally, then deploy with 'datagen env push' and 'railway up'")
 	} else {
-		fmt.Println("  1. Review and edit datagen.toml if needed")
+		fmt.Printf("  1. Review and edit %s if needed\n", filepath.Base(configPath))
 		fmt.Println("  2. Run 'datagen build' to generate the boilerplate code")
-		fmt.Println("  3. Test locally, then run 'datagen deploy railway' to deploy")
+		fmt.Println("  3. Test locally, then deploy with 'datagen env push' and 'railway up'")
 	}
 
 	return nil
 }
 
+// scaffoldStartProject writes a .gitignore into the new project when it has
+// none and, with --git, makes it a repository with a first commit
+func scaffoldStartProject() error {
+	wrote, err := codegen.EnsureGitignore(startOutputDir)
+	if err != nil {
+		return fmt.Errorf("writing .gitignore: %w", err)
+	}
+	if wrote {
+		output.Printf("  ✓ Created %s\n", filepath.Join(startOutputDir, ".gitignore"))
+	}
+	if !startGit {
+		return nil
+	}
+	if gitrepo.Inside(startOutputDir) {
+		output.Printf("  %s is already in a git repository; not running git init\n", startOutputDir)
+		return nil
+	}
+	if err := gitrepo.Init(startOutputDir, "Initial datagen project"); err != nil {
+		return err
+	}
+	output.Printf("  ✓ Initialized a git repository with the first commit\n")
+	return nil
+}
+
+// startService copies a into the output directory when it lives elsewhere and
+// returns the service that deploys it in mode
+func startService(a agents.Agent, mode string) (config.Service, error) {
+	if mode != "api" && mode != "webhook" {
+		return config.Service{}, fmt.Errorf("unsupported mode %q", mode)
+	}
+	subdir := agentType(a).Dir()
+	promptRel := ".claude/" + subdir + "/" + a.RelPath
+
+	// Ensure the selected agent exists in the output directory (copy when --output != ".").
+	destAgentsDir := filepath.Join(startOutputDir, ".claude", subdir)
+	if err := os.MkdirAll(destAgentsDir, 0755); err != nil {
+		return config.Service{}, fmt.Errorf("create %s dir: %w", subdir, err)
+	}
+	destAgentPath := filepath.Join(destAgentsDir, filepath.FromSlash(a.RelPath))
+	if !samePath(a.Path, destAgentPath) {
+		var err error
+		if _, err = os.Stat(destAgentPath); err == nil {
+			return config.Service{}, fmt.Errorf("%s file already exists in output dir: %s", agentType(a), destAgentPath)
+		}
+		if a.Type == agents.TypeSkill {
+			// Skills may reference scripts and documents next to SKILL.md
+			err = copyDir(filepath.Dir(a.Path), filepath.Dir(destAgentPath))
+		} else if err = os.MkdirAll(filepath.Dir(destAgentPath), 0755); err == nil {
+			err = copyFile(a.Path, destAgentPath)
+		}
+		if err != nil {
+			return config.Service{}, fmt.Errorf("copy %s to output dir: %w", agentType(a), err)
+		}
+	}
+
+	return agentsync.ServiceForAgent(a, promptRel, mode), nil
+}
+
 func samePath(a, b string) bool {
 	aa, errA := filepath.Abs(a)
 	bb, errB := filepath.Abs(b)
@@ -291,10 +428,39 @@ func copyFile(src, dst string) error {
 	if err != nil {
 		return err
 	}
+	if err := undo.Save(dst); err != nil {
+		return err
+	}
 	return os.WriteFile(dst, data, 0644)
 }
 
-func chooseMode(flagValue string) (string, error) {
+// copyDir copies the files below src into dst
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, ent fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if ent.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return copyFile(path, target)
+	})
+}
+
+// agentType returns a's type; an agent without one is a .claude/agents file
+func agentType(a agents.Agent) agents.Type {
+	if a.Type == "" {
+		return agents.TypeAgent
+	}
+	return a.Type
+}
+
+func chooseMode(flagValue, message string) (string, error) {
 	if flagValue != "" {
 		switch flagValue {
 		case "api", "webhook":
@@ -305,8 +471,8 @@ func chooseMode(flagValue string) (string, error) {
 	}
 
 	var mode string
-	if err := survey.AskOne(&survey.Select{
-		Message: "Deploy this agent as:",
+	if err := prompts.AskOne(&survey.Select{
+		Message: message,
 		Options: []string{"api", "webhook"},
 		Default: "api",
 		Description: func(value string, index int) string {
@@ -325,36 +491,43 @@ func chooseMode(flagValue string) (string, error) {
 	return mode, nil
 }
 
-func chooseAgent(selectable []agents.Agent, flagValue string) (agents.Agent, error) {
-	if flagValue != "" {
-		matches := make([]agents.Agent, 0, 2)
-		for _, a := range selectable {
-			base := filepath.Base(a.Path)
-			stem := strings.TrimSuffix(base, filepath.Ext(base))
-			if strings.EqualFold(a.Name, flagValue) || strings.EqualFold(base, flagValue) || strings.EqualFold(stem, flagValue) {
-				matches = append(matches, a)
+// chooseAgents returns the agents named by flagValues, or asks which of
+// selectable to deploy when there are none
+func chooseAgents(selectable []agents.Agent, flagValues []string) ([]agents.Agent, error) {
+	if len(flagValues) > 0 {
+		chosen := make([]agents.Agent, 0, len(flagValues))
+		seen := map[string]bool{}
+		for _, v := range flagValues {
+			a, err := chooseAgent(selectable, v)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[a.Path] {
+				seen[a.Path] = true
+				chosen = append(chosen, a)
 			}
 		}
-		if len(matches) == 1 {
-			return matches[0], nil
-		}
-		if len(matches) == 0 {
-			return agents.Agent{}, fmt.Errorf("no agent matches --agent %q", flagValue)
-		}
-		return agents.Agent{}, fmt.Errorf("multiple agents match --agent %q; use the full filename", flagValue)
+		return chosen, nil
 	}
 
 	options := make([]string, 0, len(selectable))
 	byOption := map[string]agents.Agent{}
 	for _, a := range selectable {
-		opt := fmt.Sprintf("%s (%s)", a.Name, filepath.Base(a.Path))
+		details := []string{a.RelPath}
+		if t := agentType(a); t != agents.TypeAgent {
+			details = append([]string{string(t)}, details...)
+		}
+		if a.Source != "" {
+			details = append(details, a.Source)
+		}
+		opt := fmt.Sprintf("%s (%s)", a.Name, strings.Join(details, ", "))
 		options = append(options, opt)
 		byOption[opt] = a
 	}
 
-	var picked string
-	if err := survey.AskOne(&survey.Select{
-		Message: "Select an agent to deploy:",
+	var picked []string
+	if err := prompts.AskOne(&survey.MultiSelect{
+		Message: "Select the agents to deploy:",
 		Options: options,
 		Description: func(value string, index int) string {
 			a := byOption[value]
@@ -372,14 +545,38 @@ func chooseAgent(selectable []agents.Agent, flagValue string) (agents.Agent, err
 			}
 		},
 	}, &picked, survey.WithValidator(survey.Required)); err != nil {
-		return agents.Agent{}, err
+		return nil, err
 	}
 
-	a, ok := byOption[picked]
-	if !ok {
-		return agents.Agent{}, fmt.Errorf("internal error: selected option not found")
+	chosen := make([]agents.Agent, 0, len(picked))
+	for _, opt := range picked {
+		a, ok := byOption[opt]
+		if !ok {
+			return nil, fmt.Errorf("internal error: selected option not found")
+		}
+		chosen = append(chosen, a)
 	}
-	return a, nil
+	return chosen, nil
+}
+
+// chooseAgent returns the one agent in selectable matching name, file name or
+// path below its .claude directory
+func chooseAgent(selectable []agents.Agent, flagValue string) (agents.Agent, error) {
+	matches := make([]agents.Agent, 0, 2)
+	for _, a := range selectable {
+		base := filepath.Base(a.Path)
+		stem := strings.TrimSuffix(base, filepath.Ext(base))
+		if strings.EqualFold(a.Name, flagValue) || strings.EqualFold(base, flagValue) || strings.EqualFold(stem, flagValue) || strings.EqualFold(a.RelPath, flagValue) {
+			matches = append(matches, a)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	if len(matches) == 0 {
+		return agents.Agent{}, fmt.Errorf("no agent matches %q", flagValue)
+	}
+	return agents.Agent{}, fmt.Errorf("multiple agents match %q; use the file path below .claude/agents, .claude/skills or .claude/commands", flagValue)
 }
 
 func createAgentPromptFile(outputDir string, svc *config.Service) error {
@@ -403,6 +600,9 @@ func createAgentPromptFile(outputDir string, svc *config.Service) error {
 	}
 
 	// Write the template to the file
+	if err := undo.Save(promptPath); err != nil {
+		return err
+	}
 	if err := os.WriteFile(promptPath, []byte(template), 0644); err != nil {
 		return fmt.Errorf("failed to write prompt file: %w", err)
 	}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/agents"
+)
+
+func TestChooseAgents(t *testing.T) {
+	selectable := []agents.Agent{
+		{Path: "/p/.claude/agents/triage.md", Name: "triage", RelPath: "triage.md", Type: agents.TypeAgent},
+		{Path: "/p/.claude/agents/support/digest.md", Name: "daily-digest", RelPath: "support/digest.md", Type: agents.TypeAgent},
+		{Path: "/p/.claude/skills/triage/SKILL.md", Name: "triage", RelPath: "triage/SKILL.md", Type: agents.TypeSkill},
+	}
+
+	got, err := chooseAgents(selectable, []string{"daily-digest", "triage.md", "support/digest.md"})
+	if err != nil {
+		t.Fatalf("chooseAgents() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "daily-digest" || got[1].RelPath != "triage.md" {
+		t.Errorf("chooseAgents() = %+v, want daily-digest then the triage agent once each", got)
+	}
+
+	if _, err := chooseAgents(selectable, []string{"triage"}); err == nil || !strings.Contains(err.Error(), "multiple agents match") {
+		t.Errorf("chooseAgents(triage) error = %v, want an ambiguity error", err)
+	}
+	if _, err := chooseAgents(selectable, []string{"digest", "missing"}); err == nil || !strings.Contains(err.Error(), `no agent matches "missing"`) {
+		t.Errorf("chooseAgents(missing) error = %v, want a no-match error", err)
+	}
+}
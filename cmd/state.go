@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/datagendev/datagen-cli/internal/api"
+	"github.com/datagendev/datagen-cli/internal/auth"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/prompts"
+	"github.com/datagendev/datagen-cli/internal/railway"
+	"github.com/datagendev/datagen-cli/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Inspect and sync local CLI state",
+	Long: `Local CLI state (links between project directories and deployed services,
+deploy records) lives in ~/.datagen/state. 'datagen state push' uploads it to the
+DataGen platform encrypted with a passphrase only you know; 'datagen state pull'
+restores it on another machine.
+
+The passphrase is read from DATAGEN_STATE_PASSPHRASE or prompted for.
+
+Examples:
+  datagen state list
+  datagen state push
+  datagen state pull`,
+}
+
+var stateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List linked project directories",
+	Args:  cobra.NoArgs,
+	RunE:  runStateList,
+}
+
+var statePushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Upload encrypted local state to the DataGen platform",
+	Args:  cobra.NoArgs,
+	RunE:  runStatePush,
+}
+
+var statePullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Restore state from the DataGen platform into local state",
+	Args:  cobra.NoArgs,
+	RunE:  runStatePull,
+}
+
+func init() {
+	stateCmd.AddCommand(stateListCmd)
+	stateCmd.AddCommand(statePushCmd)
+	stateCmd.AddCommand(statePullCmd)
+}
+
+func runStateList(cmd *cobra.Command, args []string) error {
+	store, err := state.Open()
+	if err != nil {
+		return err
+	}
+	links, err := state.ProjectLinks(store)
+	if err != nil {
+		return err
+	}
+	if len(links) == 0 {
+		fmt.Println("No linked projects recorded.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DIRECTORY\tPLATFORM\tNAME\tURL\tUPDATED")
+	for _, l := range links {
+		url := l.URL
+		if url == "" {
+			url = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", l.Dir, l.Platform, l.Name, url, l.UpdatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	return w.Flush()
+}
+
+func runStatePush(cmd *cobra.Command, args []string) error {
+	store, err := state.Open()
+	if err != nil {
+		return err
+	}
+	bundle, err := state.Export(store)
+	if err != nil {
+		return err
+	}
+	passphrase, err := statePassphrase()
+	if err != nil {
+		return err
+	}
+	sealed, err := state.Seal(bundle, passphrase)
+	if err != nil {
+		return err
+	}
+
+	client, err := newStateAPIClient()
+	if err != nil {
+		return err
+	}
+	if err := client.PutCLIState(sealed); err != nil {
+		return err
+	}
+	output.Printf("✅ Pushed %d state entries\n", len(bundle))
+	return nil
+}
+
+func runStatePull(cmd *cobra.Command, args []string) error {
+	client, err := newStateAPIClient()
+	if err != nil {
+		return err
+	}
+	remote, err := client.GetCLIState()
+	if err != nil {
+		return err
+	}
+	if len(remote.State) == 0 {
+		fmt.Println("No synced state found. Run 'datagen state push' on the other machine first.")
+		return nil
+	}
+
+	passphrase, err := statePassphrase()
+	if err != nil {
+		return err
+	}
+	bundle, err := state.Unseal(remote.State, passphrase)
+	if err != nil {
+		return err
+	}
+
+	store, err := state.Open()
+	if err != nil {
+		return err
+	}
+	if err := state.Import(store, bundle); err != nil {
+		return err
+	}
+	output.Printf("✅ Pulled %d state entries\n", len(bundle))
+	return nil
+}
+
+func newStateAPIClient() (*api.Client, error) {
+	apiKey, _, ok := auth.FindEnvVarOrProfile("DATAGEN_API_KEY")
+	if !ok {
+		return nil, fmt.Errorf("DATAGEN_API_KEY not found. Run 'datagen login' first")
+	}
+	return api.NewClient(apiKey), nil
+}
+
+func statePassphrase() (string, error) {
+	if p := os.Getenv("DATAGEN_STATE_PASSPHRASE"); p != "" {
+		return p, nil
+	}
+	var passphrase string
+	if err := prompts.AskOne(&survey.Password{
+		Message: "State sync passphrase:",
+	}, &passphrase, survey.WithValidator(survey.Required)); err != nil {
+		return "", err
+	}
+	return passphrase, nil
+}
+
+// rememberRailwayLink records dir's Railway link in local state. Failures are
+// not fatal: state is a convenience for moving between machines.
+func rememberRailwayLink(dir string, link *railway.LinkedProject, url string) {
+	store, err := state.Open()
+	if err == nil {
+		err = state.SaveProjectLink(store, state.ProjectLink{
+			Dir:           dir,
+			Name:          link.Name,
+			Platform:      "railway",
+			ProjectID:     link.Project,
+			EnvironmentID: link.Environment,
+			ServiceID:     link.Service,
+			URL:           url,
+		})
+	}
+	if err != nil {
+		output.Warnf("could not record project link: %v\n", err)
+	}
+}
+
+// forgetProjectLink removes dir from local state after teardown
+func forgetProjectLink(dir string) {
+	store, err := state.Open()
+	if err == nil {
+		err = state.RemoveProjectLink(store, dir)
+	}
+	if err != nil {
+		output.Warnf("could not remove project link: %v\n", err)
+	}
+}
+
+// warnIfConfigChanged warns when datagen.toml differs from the last deployed config
+func warnIfConfigChanged(dir, configPath string) {
+	dep, err := state.LoadDeployment(dir)
+	if err != nil || dep == nil {
+		return
+	}
+	if changed, err := dep.ConfigChanged(config.FindConfig(configPath)); err == nil && changed {
+		since := "the last deploy"
+		if dep.DeployedAt != nil {
+			since += " (" + dep.DeployedAt.Local().Format("2006-01-02 15:04") + ")"
+		}
+		output.Warnf("%s has changed since %s\n", configPath, since)
+	}
+}
+
+// recordPush adds keys to dir's deployment record, creating it from the
+// Railway link when there is none yet. Setting variables redeploys the
+// service, so it also records configPath as the deployed config.
+func recordPush(dir, configPath string, keys []string) {
+	dep, err := state.LoadDeployment(dir)
+	if err == nil && dep == nil {
+		dep = &state.Deployment{Platform: "railway"}
+		if link, linkErr := railway.FindLinkedProject(dir); linkErr == nil {
+			dep.ProjectID = link.Project
+			dep.ProjectName = link.Name
+			dep.EnvironmentID = link.Environment
+			dep.ServiceID = link.Service
+		}
+	}
+	if err == nil {
+		dep.AddVariableKeys(keys...)
+		if markErr := dep.MarkDeployed(config.FindConfig(configPath)); markErr != nil && !os.IsNotExist(markErr) {
+			output.Warnf("could not hash %s: %v\n", configPath, markErr)
+		}
+		err = state.SaveDeployment(dir, dep)
+	}
+	if err != nil {
+		output.Warnf("could not update %s: %v\n", state.DeploymentFile, err)
+	}
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/datagendev/datagen-cli/internal/agents"
+	"github.com/datagendev/datagen-cli/internal/agentsync"
+	"github.com/datagendev/datagen-cli/internal/codegen"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/prompts"
+	"github.com/datagendev/datagen-cli/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncConfigPath string
+	syncOutputDir  string
+	syncMode       string
+	syncDryRun     bool
+	syncYes        bool
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Match datagen.toml services to the agent files in .claude/agents",
+	Long: `Compare the agent files under .claude/agents with the services in
+datagen.toml and bring them back in step:
+
+  - a new agent file becomes a service (in --mode, default api)
+  - a service whose agent file is gone is removed
+  - a service whose agent file moved is pointed at the new path, when the
+    file at the new path has the same agent name
+
+The changes are listed and confirmed before anything is written. When the
+project has been built, app/main.py and app/models.py are updated in place
+through their marker comments, like 'datagen add', so customizations
+elsewhere in those files are kept.
+
+Examples:
+  datagen sync --dry-run
+  datagen sync --mode webhook --yes`,
+	Args: cobra.NoArgs,
+	RunE: runSync,
+}
+
+func init() {
+	syncCmd.Flags().StringVarP(&syncConfigPath, "config", "c", "datagen.toml", "Path to datagen.toml configuration file")
+	syncCmd.Flags().StringVarP(&syncOutputDir, "output", "o", ".", "Project directory with the generated app")
+	syncCmd.Flags().StringVar(&syncMode, "mode", "api", "Deployment mode for new agents: api or webhook")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Show the changes without applying them")
+	syncCmd.Flags().BoolVarP(&syncYes, "yes", "y", false, "Apply the changes without prompting")
+	syncCmd.MarkFlagFilename("config", "toml")
+	syncCmd.MarkFlagDirname("output")
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	if syncMode != "api" && syncMode != "webhook" {
+		return fmt.Errorf("invalid --mode %q (expected 'api' or 'webhook')", syncMode)
+	}
+	cmd.SilenceUsage = true
+	if !syncDryRun {
+		lock, err := lockProject(syncOutputDir, "sync")
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+	}
+
+	// Not LoadConfig: validation fails on exactly the missing prompt files
+	// sync is here to fix
+	cfg, err := config.ReadConfig(syncConfigPath)
+	if err != nil {
+		return err
+	}
+	configDir := filepath.Dir(syncConfigPath)
+	found, err := agents.Discover(filepath.Join(configDir, filepath.FromSlash(agentsync.AgentsDir)))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	changes := agentsync.Plan(cfg, found, syncMode)
+	pending := 0
+	for _, c := range changes {
+		if c.Action == agentsync.ActionSkip {
+			output.Warnf("%s", c)
+			continue
+		}
+		pending++
+		fmt.Printf("  %s %s\n", syncSymbol(c.Action), c)
+	}
+	if pending == 0 {
+		output.Printf("✓ %s matches %s\n", syncConfigPath, agentsync.AgentsDir)
+		return nil
+	}
+	if syncDryRun {
+		fmt.Printf("\n%d change(s); run without --dry-run to apply\n", pending)
+		return nil
+	}
+
+	if !syncYes {
+		if !stdinIsTerminal() {
+			return fmt.Errorf("not a terminal; pass --yes to apply %d change(s)", pending)
+		}
+		apply := false
+		if err := prompts.AskOne(&survey.Confirm{
+			Message: fmt.Sprintf("Apply %d change(s)?", pending),
+			Default: true,
+		}, &apply); err != nil {
+			return err
+		}
+		if !apply {
+			return nil
+		}
+	}
+
+	agentsync.Apply(cfg, changes)
+	if err := config.ValidateConfig(cfg, configDir); err != nil {
+		return fmt.Errorf("config validation failed after sync: %w", err)
+	}
+	telemetry.TagConfig(cfg)
+	if err := config.SaveConfig(cfg, syncConfigPath); err != nil {
+		return fmt.Errorf("saving config: %w", err)
+	}
+	output.Printf("\n✓ Updated %s\n", syncConfigPath)
+
+	if _, err := os.Stat(filepath.Join(syncOutputDir, "app", "main.py")); err != nil {
+		fmt.Println("\nNo generated project found; run 'datagen build' to generate it")
+		return nil
+	}
+	output.Println("🔄 Updating project files...")
+	for _, c := range changes {
+		svc := c.Service
+		switch c.Action {
+		case agentsync.ActionAdd:
+			err = codegen.IncrementalAddService(cfg, &svc, syncOutputDir)
+			if err == nil {
+				err = copyPromptFile(svc.Name, svc.Prompt, configDir, syncOutputDir)
+			}
+		case agentsync.ActionRemove:
+			err = codegen.IncrementalRemoveService(cfg, svc.Name, syncOutputDir)
+		case agentsync.ActionRename:
+			err = codegen.IncrementalMovePrompt(cfg, &svc, c.OldPrompt, syncOutputDir)
+			if err == nil {
+				err = copyPromptFile(svc.Name, svc.Prompt, configDir, syncOutputDir)
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			fmt.Println("\nNote: If marker comments are missing, you may need to run 'datagen build'")
+			fmt.Println("to fully regenerate the project (this will overwrite customizations).")
+			return fmt.Errorf("updating project files for %s: %w", svc.Name, err)
+		}
+	}
+
+	absPath, _ := filepath.Abs(syncOutputDir)
+	output.Printf("\n✅ Synced %d change(s) into %s\n", pending, absPath)
+	return nil
+}
+
+func syncSymbol(a agentsync.Action) string {
+	switch a {
+	case agentsync.ActionAdd:
+		return "+"
+	case agentsync.ActionRemove:
+		return "-"
+	default:
+		return "~"
+	}
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/telemetry"
+	"github.com/datagendev/datagen-cli/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var telemetryCmd = &cobra.Command{
+	Use:   "telemetry",
+	Short: "Turn anonymous usage metrics on or off",
+	Long: `Telemetry is off unless you turn it on. When on, each command sends one
+anonymous event so maintainers can see which commands, deploy platforms and
+service types are used:
+
+- the command name (e.g. "env pull"), never its arguments
+- how long it took
+- OS, architecture and datagen version
+- the kind of error it ended with (e.g. "network", "api_401"), never the message
+- fixed-choice properties such as the deploy platform or service types
+- a random install ID, created when you turn telemetry on
+
+No API keys, file contents, paths, names or prompts are ever sent.
+DATAGEN_TELEMETRY=0 or DO_NOT_TRACK=1 turn telemetry off for a single shell.`,
+}
+
+var telemetryOnCmd = &cobra.Command{
+	Use:   "on",
+	Short: "Send anonymous usage metrics",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		if _, err := telemetry.SetEnabled(true); err != nil {
+			return fmt.Errorf("failed to save telemetry setting: %w", err)
+		}
+		fmt.Println("✓ Telemetry is on. Thanks! Run 'datagen telemetry status' to see what is sent.")
+		return nil
+	},
+}
+
+var telemetryOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Stop sending usage metrics",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		if _, err := telemetry.SetEnabled(false); err != nil {
+			return fmt.Errorf("failed to save telemetry setting: %w", err)
+		}
+		fmt.Println("✓ Telemetry is off")
+		return nil
+	},
+}
+
+var telemetryStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether telemetry is on and what an event contains",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		s, err := telemetry.Load()
+		if err != nil {
+			return err
+		}
+		switch env := telemetry.DisabledByEnv(); {
+		case env != "":
+			fmt.Printf("Telemetry: off (disabled by %s)\n", env)
+		case s.Enabled:
+			fmt.Println("Telemetry: on")
+		default:
+			fmt.Println("Telemetry: off (turn on with 'datagen telemetry on')")
+		}
+		if s.InstallID != "" {
+			fmt.Printf("Install ID: %s\n", s.InstallID)
+		}
+		fmt.Printf("Endpoint:   %s\n", telemetry.Endpoint())
+
+		example := telemetry.NewEvent("build", 0, version.Version, nil)
+		example.InstallID = s.InstallID
+		example.DurationMs = 412
+		example.Tags = map[string]string{"service_types": "api,webhook"}
+		data, _ := json.MarshalIndent(example, "", "  ")
+		fmt.Printf("\nExample event:\n%s\n", data)
+		return nil
+	},
+}
+
+func init() {
+	telemetryCmd.AddCommand(telemetryOnCmd, telemetryOffCmd, telemetryStatusCmd)
+}
+
+// telemetryCommand is the event name for cmd, its path without "datagen"
+func telemetryCommand(cmd *cobra.Command) string {
+	if cmd == nil || cmd == rootCmd {
+		return "root"
+	}
+	return strings.TrimSpace(strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()))
+}
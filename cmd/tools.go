@@ -7,6 +7,7 @@ import (
 
 	"github.com/datagendev/datagen-cli/internal/api"
 	"github.com/datagendev/datagen-cli/internal/customtools"
+	"github.com/datagendev/datagen-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -200,7 +201,7 @@ func runToolsShow(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	fmt.Printf("🔍 Fetching custom tool: %s\n", toolUUID)
+	output.Printf("🔍 Fetching custom tool: %s\n", toolUUID)
 
 	resp, err := client.GetCustomTool(toolUUID)
 	if err != nil {
@@ -284,7 +285,7 @@ func runToolsDeploy(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	fmt.Printf("🚀 Deploying custom tool: %s\n", name)
+	output.Printf("🚀 Deploying custom tool: %s\n", name)
 
 	resp, err := client.DeployCustomTool(req)
 	if err != nil {
@@ -292,7 +293,7 @@ func runToolsDeploy(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Println()
-	fmt.Println("✅ Custom tool deployed successfully!")
+	output.Println("✅ Custom tool deployed successfully!")
 	fmt.Printf("   UUID: %s\n", resp.Data.DeploymentUUID)
 	if resp.Data.Status != "" {
 		fmt.Printf("   Status: %s\n", resp.Data.Status)
@@ -340,7 +341,7 @@ func runToolsUpdate(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	fmt.Printf("✏️  Updating custom tool: %s\n", toolUUID)
+	output.Printf("✏️  Updating custom tool: %s\n", toolUUID)
 
 	resp, err := client.UpdateCustomTool(toolUUID, req)
 	if err != nil {
@@ -348,7 +349,7 @@ func runToolsUpdate(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Println()
-	fmt.Println("✅ Custom tool updated successfully!")
+	output.Println("✅ Custom tool updated successfully!")
 	fmt.Printf("   UUID: %s\n", resp.Data.DeploymentUUID)
 	fmt.Println()
 	fmt.Printf("Show details: datagen tools show %s\n", toolUUID)
@@ -369,7 +370,7 @@ func runToolsRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	fmt.Printf("🔐 Validating custom tool requirements: %s\n", toolUUID)
+	output.Printf("🔐 Validating custom tool requirements: %s\n", toolUUID)
 
 	validateResp, err := client.ValidateCustomTool(toolUUID)
 	if err != nil {
@@ -402,7 +403,7 @@ func runToolsRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("custom tool is not ready to run")
 	}
 
-	fmt.Printf("▶️  Running custom tool: %s\n", toolUUID)
+	output.Printf("▶️  Running custom tool: %s\n", toolUUID)
 
 	runResp, err := client.RunCustomTool(toolUUID, inputVars)
 	if err != nil {
@@ -410,7 +411,7 @@ func runToolsRun(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Println()
-	fmt.Println("✅ Custom tool run started!")
+	output.Println("✅ Custom tool run started!")
 	if runResp.Data.RunUUID != "" {
 		fmt.Printf("   Run UUID: %s\n", runResp.Data.RunUUID)
 	}
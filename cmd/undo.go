@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/undo"
+	"github.com/spf13/cobra"
+)
+
+var (
+	undoOutputDir string
+	undoList      bool
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Revert the project files the last build, add, edit, sync or repair changed",
+	Long: `Put back the files the last project-modifying command changed.
+
+Before 'datagen build', 'add', 'edit', 'sync' or 'repair' rewrites a file in
+the project (or datagen.toml and agent prompts), datagen copies it into
+.datagen/undo/<timestamp>/. undo restores the files of the latest snapshot
+and removes the ones that command created. Running it again goes one more
+command back; the last 10 snapshots are kept.
+
+Examples:
+  datagen undo
+  datagen undo --list
+  datagen undo -o ./my-project`,
+	Args: cobra.NoArgs,
+	RunE: runUndo,
+}
+
+func init() {
+	undoCmd.Flags().StringVarP(&undoOutputDir, "output", "o", ".", "Project directory")
+	undoCmd.Flags().BoolVar(&undoList, "list", false, "List the snapshots that can be undone, newest first")
+	undoCmd.MarkFlagDirname("output")
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	if undoList {
+		snapshots, err := undo.List(undoOutputDir)
+		if err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			fmt.Println("Nothing to undo.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tCOMMAND\tFILES")
+		for _, s := range snapshots {
+			fmt.Fprintf(w, "%s\tdatagen %s\t%d\n", s.Time.Local().Format("2006-01-02 15:04:05"), s.Command, len(s.Files))
+		}
+		return w.Flush()
+	}
+
+	lock, err := acquireProject(undoOutputDir, "undo")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	s, err := undo.Restore(undoOutputDir)
+	if errors.Is(err, undo.ErrNothingToUndo) {
+		fmt.Println("Nothing to undo.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	output.Printf("✓ Undid 'datagen %s' from %s\n", s.Command, s.Time.Local().Format("2006-01-02 15:04:05"))
+	for _, f := range s.Files {
+		if f.Absent {
+			output.Printf("  removed  %s\n", f.Path)
+		} else {
+			output.Printf("  restored %s\n", f.Path)
+		}
+	}
+	return nil
+}
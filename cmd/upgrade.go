@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradeVersion string
+	upgradeCheck   bool
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Update datagen to the latest release",
+	Long: `Download the latest datagen release for this OS and architecture from GitHub,
+verify it against the release's checksums.txt, and replace the running binary.
+
+The old binary stays in place if any step fails. On Windows it is kept as
+datagen.exe.old until the next upgrade.
+
+datagen also prints a notice when a newer release is out (checked at most once
+a day). Set DATAGEN_NO_UPDATE_CHECK=1 to turn the notice off.
+
+Examples:
+  datagen upgrade
+  datagen upgrade --check
+  datagen upgrade --version v0.3.1`,
+	Args: cobra.NoArgs,
+	RunE: runUpgrade,
+}
+
+func init() {
+	upgradeCmd.Flags().StringVar(&upgradeVersion, "version", "", "Install this release tag instead of the latest (also allows downgrades)")
+	upgradeCmd.Flags().BoolVar(&upgradeCheck, "check", false, "Only report whether a newer release is available")
+}
+
+func runUpgrade(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	target := upgradeVersion
+	if target == "" {
+		output.Println("Checking for updates...")
+		latest, err := version.FetchLatestVersion()
+		if err != nil {
+			return fmt.Errorf("could not check for updates: %w", err)
+		}
+		target = latest
+
+		if version.Version == "dev" {
+			if upgradeCheck {
+				fmt.Printf("Latest release: %s (this is a development build)\n", latest)
+				return nil
+			}
+			return fmt.Errorf("this is a development build; install a release explicitly with: datagen upgrade --version %s", latest)
+		}
+		if !version.IsNewer(version.Version, latest) {
+			output.Printf("✓ datagen %s is up to date\n", version.Version)
+			return nil
+		}
+	}
+
+	if upgradeCheck {
+		fmt.Printf("A newer version is available: %s (current: %s)\nRun 'datagen upgrade' to install it.\n", target, version.Version)
+		return nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("could not locate the datagen binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	output.Printf("Downloading %s (%s)...\n", version.AssetName(runtime.GOOS, runtime.GOARCH), target)
+	data, err := version.DownloadRelease(target)
+	if err != nil {
+		return err
+	}
+	output.Println("✓ Checksum verified")
+
+	if err := version.ReplaceExecutable(exe, data); err != nil {
+		if errors.Is(err, fs.ErrPermission) {
+			return fmt.Errorf("no permission to replace %s; re-run with sudo or reinstall with install.sh: %w", exe, err)
+		}
+		return fmt.Errorf("failed to replace %s: %w", exe, err)
+	}
+	output.Printf("✓ Upgraded datagen %s → %s (%s)\n", version.Version, target, exe)
+	return nil
+}
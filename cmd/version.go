@@ -28,7 +28,7 @@ var versionCmd = &cobra.Command{
 
 		if version.IsNewer(version.Version, latest) {
 			fmt.Printf("\nA newer version is available: %s (current: %s)\n", latest, version.Version)
-			fmt.Println("Update with: curl -fsSL https://raw.githubusercontent.com/datagendev/datagen-cli/main/install.sh | sh")
+			fmt.Println("Update with: datagen upgrade")
 		} else {
 			fmt.Printf("You are up to date. (%s)\n", latest)
 		}
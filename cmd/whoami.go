@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/api"
+	"github.com/datagendev/datagen-cli/internal/auth"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var whoamiJSON bool
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show which DataGen account your API key belongs to",
+	Long: `Resolve DATAGEN_API_KEY the same way other commands do (active profile,
+environment, OS keychain, then shell profile), validate it against the DataGen
+API, and print the account, organization, key scopes and expiry.
+
+Use this to debug "my key isn't working" before deploying anything.
+
+Examples:
+  datagen whoami
+  DATAGEN_PROFILE=work datagen whoami
+  datagen whoami --json`,
+	Args: cobra.NoArgs,
+	RunE: runWhoami,
+}
+
+func init() {
+	whoamiCmd.Flags().BoolVar(&whoamiJSON, "json", false, "Print the account details as JSON")
+}
+
+func runWhoami(cmd *cobra.Command, args []string) error {
+	apiKey, source, ok := auth.FindEnvVarOrProfile("DATAGEN_API_KEY")
+	if !ok {
+		return fmt.Errorf("DATAGEN_API_KEY not found. Run 'datagen login' first")
+	}
+
+	client := api.NewClient(apiKey)
+	me, err := client.WhoAmI()
+	if err != nil {
+		cmd.SilenceUsage = true
+		if strings.Contains(err.Error(), "API error (401)") || strings.Contains(err.Error(), "API error (403)") {
+			return fmt.Errorf("key %s (from %s) was rejected: %w\nRun 'datagen login' to store a new key", maskValue(apiKey), source, err)
+		}
+		return fmt.Errorf("failed to validate key %s (from %s): %w", maskValue(apiKey), source, err)
+	}
+
+	if whoamiJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(me)
+	}
+
+	account := me.Email
+	if me.Name != "" {
+		account = fmt.Sprintf("%s <%s>", me.Name, me.Email)
+	}
+	output.Printf("✅ Key is valid\n\n")
+	fmt.Printf("  Account:       %s\n", account)
+	if me.Organization != "" {
+		fmt.Printf("  Organization:  %s\n", me.Organization)
+	}
+	fmt.Printf("  Profile:       %s\n", auth.ActiveProfile())
+	fmt.Printf("  Key:           %s (from %s)\n", maskValue(apiKey), source)
+	if me.KeyName != "" {
+		fmt.Printf("  Key name:      %s\n", me.KeyName)
+	}
+	if len(me.Scopes) > 0 {
+		fmt.Printf("  Scopes:        %s\n", strings.Join(me.Scopes, ", "))
+	}
+	fmt.Printf("  Expires:       %s\n", describeExpiry(me.ExpiresAt, time.Now()))
+	fmt.Printf("  API:           %s\n", client.BaseURL)
+	return nil
+}
+
+// describeExpiry renders a key expiry relative to now
+func describeExpiry(expiresAt *time.Time, now time.Time) string {
+	if expiresAt == nil {
+		return "never"
+	}
+	date := expiresAt.Local().Format("2006-01-02")
+	remaining := expiresAt.Sub(now)
+	if remaining <= 0 {
+		return date + " (expired)"
+	}
+	days := int(remaining.Hours() / 24)
+	if days == 0 {
+		return date + " (today)"
+	}
+	return fmt.Sprintf("%s (in %d days)", date, days)
+}
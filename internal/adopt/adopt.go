@@ -0,0 +1,337 @@
+// Package adopt scans an existing FastAPI project so datagen can manage it
+// without regenerating the application code.
+package adopt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+// EntryFile is where datagen expects the FastAPI app, relative to the project
+const EntryFile = "app/main.py"
+
+// Route is a path operation found in the project
+type Route struct {
+	File     string
+	Line     int
+	Method   string
+	Path     string
+	Function string
+	// Model is the pydantic model of the request body, if one was detected
+	Model string
+	Doc   string
+	// Streaming is set when the handler returns a StreamingResponse
+	Streaming bool
+	// Background is set when the handler takes BackgroundTasks
+	Background bool
+}
+
+// Project is the result of scanning an existing FastAPI project
+type Project struct {
+	Dir string
+	// AppVar is the name of the FastAPI instance in EntryFile
+	AppVar string
+	Routes []Route
+	// Models maps pydantic model names to their fields
+	Models map[string][]config.Field
+	// Agents maps normalized agent names to prompt paths relative to Dir
+	Agents map[string]string
+}
+
+// Service pairs a proposed datagen service with the route it came from
+type Service struct {
+	Route   Route
+	Service config.Service
+}
+
+var (
+	appVarPattern    = regexp.MustCompile(`(?m)^(\w+)\s*(?::\s*\w+\s*)?=\s*(?:fastapi\.)?FastAPI\(`)
+	decoratorPattern = regexp.MustCompile(`(?m)^@(\w+)\.(get|post|put|patch|delete)\(\s*["']([^"']*)["']`)
+	defPattern       = regexp.MustCompile(`(?m)^(?:async\s+)?def\s+(\w+)\s*\(`)
+	classPattern     = regexp.MustCompile(`(?m)^class\s+(\w+)\s*\(([^)]*)\)\s*:`)
+	fieldPattern     = regexp.MustCompile(`^    (\w+)\s*:\s*([^=#]+?)\s*(=.*)?$`)
+	paramPattern     = regexp.MustCompile(`^\s*(\w+)\s*:\s*([\w.]+)\s*$`)
+)
+
+// Scan reads the project in dir: the FastAPI app in app/main.py, route
+// handlers and pydantic models in app/, and agent prompts in .claude/agents
+func Scan(dir string) (*Project, error) {
+	entry, err := os.ReadFile(filepath.Join(dir, EntryFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s not found; datagen manages the FastAPI app in %s, so move the app there first", filepath.Join(dir, EntryFile), EntryFile)
+		}
+		return nil, err
+	}
+	m := appVarPattern.FindStringSubmatch(string(entry))
+	if m == nil {
+		return nil, fmt.Errorf("no FastAPI() instance found in %s", EntryFile)
+	}
+
+	p := &Project{
+		Dir:    dir,
+		AppVar: m[1],
+		Models: map[string][]config.Field{},
+		Agents: map[string]string{},
+	}
+
+	sources, err := filepath.Glob(filepath.Join(dir, "app", "*.py"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(sources)
+	for _, path := range sources {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		rel = filepath.ToSlash(rel)
+		scanModels(string(data), p.Models)
+		p.Routes = append(p.Routes, scanRoutes(rel, string(data))...)
+	}
+	for i := range p.Routes {
+		if _, ok := p.Models[p.Routes[i].Model]; !ok {
+			p.Routes[i].Model = ""
+		}
+	}
+
+	agents, err := filepath.Glob(filepath.Join(dir, ".claude", "agents", "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range agents {
+		rel, _ := filepath.Rel(dir, path)
+		name := config.NormalizeServiceName(strings.TrimSuffix(filepath.Base(path), ".md"))
+		p.Agents[name] = filepath.ToSlash(rel)
+	}
+	return p, nil
+}
+
+// Propose turns POST routes backed by an agent prompt into datagen services.
+// A route is backed by an agent when a prompt in .claude/agents is named after
+// its handler or its last path segment. Other routes are returned as skipped.
+func (p *Project) Propose() (services []Service, skipped []Route) {
+	used := map[string]bool{}
+	for _, r := range p.Routes {
+		if r.Method != "post" {
+			skipped = append(skipped, r)
+			continue
+		}
+		name, prompt := p.agentFor(r)
+		if prompt == "" || used[name] {
+			skipped = append(skipped, r)
+			continue
+		}
+		used[name] = true
+
+		svc := config.Service{
+			Name:        name,
+			Type:        "api",
+			Description: r.Doc,
+			Prompt:      prompt,
+			InputSchema: config.Schema{Fields: p.Models[r.Model]},
+		}
+		if svc.Description == "" {
+			svc.Description = fmt.Sprintf("Adopted from POST %s", r.Path)
+		}
+		switch {
+		case r.Streaming:
+			svc.Type = "streaming"
+			svc.APIPath = r.Path
+		case r.Background || strings.Contains(r.Path, "webhook"):
+			svc.Type = "webhook"
+			svc.WebhookPath = r.Path
+		default:
+			svc.APIPath = r.Path
+		}
+		services = append(services, Service{Route: r, Service: svc})
+	}
+	return services, skipped
+}
+
+func (p *Project) agentFor(r Route) (string, string) {
+	candidates := []string{config.NormalizeServiceName(r.Function)}
+	if seg := lastSegment(r.Path); seg != "" {
+		candidates = append(candidates, config.NormalizeServiceName(seg))
+	}
+	for _, name := range candidates {
+		if prompt, ok := p.Agents[name]; ok {
+			return name, prompt
+		}
+	}
+	return "", ""
+}
+
+func lastSegment(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if parts[i] != "" && !strings.HasPrefix(parts[i], "{") {
+			return parts[i]
+		}
+	}
+	return ""
+}
+
+func scanRoutes(file, src string) []Route {
+	var routes []Route
+	for _, loc := range decoratorPattern.FindAllStringSubmatchIndex(src, -1) {
+		rest := src[loc[1]:]
+		def := defPattern.FindStringSubmatchIndex(rest)
+		if def == nil {
+			continue
+		}
+		r := Route{
+			File:     file,
+			Line:     strings.Count(src[:loc[0]], "\n") + 1,
+			Method:   src[loc[4]:loc[5]],
+			Path:     src[loc[6]:loc[7]],
+			Function: rest[def[2]:def[3]],
+		}
+		params, body := splitDef(rest[def[1]:])
+		for _, param := range splitParams(params) {
+			pm := paramPattern.FindStringSubmatch(param)
+			if pm == nil {
+				continue
+			}
+			typ := pm[2][strings.LastIndex(pm[2], ".")+1:]
+			switch {
+			case typ == "BackgroundTasks":
+				r.Background = true
+			case typ != "Request" && r.Model == "":
+				r.Model = typ
+			}
+		}
+		r.Doc = docstring(body)
+		r.Streaming = strings.Contains(body, "StreamingResponse(") || strings.Contains(body, "EventSourceResponse(")
+		routes = append(routes, r)
+	}
+	return routes
+}
+
+// splitDef splits the text after "def name(" into the parameter list and the
+// function body (up to the next top-level statement)
+func splitDef(s string) (string, string) {
+	depth := 1
+	end := len(s)
+	for i, c := range s {
+		if c == '(' {
+			depth++
+		} else if c == ')' {
+			depth--
+			if depth == 0 {
+				end = i
+				break
+			}
+		}
+	}
+	params := s[:end]
+	rest := s[min(end+1, len(s)):]
+	if nl := strings.Index(rest, "\n"); nl >= 0 {
+		rest = rest[nl+1:]
+	}
+	var body strings.Builder
+	for _, line := range strings.SplitAfter(rest, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") && line[0] != ' ' && line[0] != '\t' {
+			break
+		}
+		body.WriteString(line)
+	}
+	return params, body.String()
+}
+
+// splitParams splits a parameter list on top-level commas
+func splitParams(params string) []string {
+	var out []string
+	depth, start := 0, 0
+	for i, c := range params {
+		switch c {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				out = append(out, params[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(out, params[start:])
+}
+
+func docstring(body string) string {
+	trimmed := strings.TrimSpace(body)
+	for _, quote := range []string{`"""`, `'''`} {
+		if rest, ok := strings.CutPrefix(trimmed, quote); ok {
+			if end := strings.Index(rest, quote); end >= 0 {
+				for _, line := range strings.Split(rest[:end], "\n") {
+					if line = strings.TrimSpace(line); line != "" {
+						return line
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func scanModels(src string, models map[string][]config.Field) {
+	for _, loc := range classPattern.FindAllStringSubmatchIndex(src, -1) {
+		name := src[loc[2]:loc[3]]
+		bases := src[loc[4]:loc[5]]
+		if !strings.Contains(bases, "BaseModel") {
+			continue
+		}
+		fields := []config.Field{}
+		for _, line := range strings.Split(src[loc[1]:], "\n")[1:] {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if line[0] != ' ' && line[0] != '\t' {
+				break
+			}
+			fm := fieldPattern.FindStringSubmatch(line)
+			if fm == nil || fm[1] == "model_config" {
+				continue
+			}
+			typ, optional := fieldType(fm[2])
+			fields = append(fields, config.Field{Name: fm[1], Type: typ, Required: !optional && fm[3] == ""})
+		}
+		models[name] = fields
+	}
+}
+
+// fieldType maps a Python annotation to a datagen field type and reports
+// whether it is Optional
+func fieldType(annotation string) (string, bool) {
+	a := strings.ReplaceAll(annotation, " ", "")
+	optional := false
+	if inner, ok := strings.CutPrefix(a, "Optional["); ok {
+		a, optional = strings.TrimSuffix(inner, "]"), true
+	}
+	if strings.HasSuffix(a, "|None") || strings.HasPrefix(a, "None|") {
+		a, optional = strings.Trim(strings.ReplaceAll(a, "None", ""), "|"), true
+	}
+	base := strings.ToLower(a)
+	if i := strings.Index(base, "["); i >= 0 {
+		base = base[:i]
+	}
+	switch base {
+	case "str", "int", "float", "bool", "list", "dict":
+		return base, optional
+	case "typing.list", "sequence", "set", "tuple":
+		return "list", optional
+	case "typing.dict", "mapping":
+		return "dict", optional
+	default:
+		return "any", optional
+	}
+}
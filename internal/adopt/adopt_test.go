@@ -0,0 +1,119 @@
+package adopt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, body := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+}
+
+func TestScanAndPropose(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app/main.py": `from fastapi import BackgroundTasks, Depends, FastAPI
+
+from app.schemas import EnrichRequest
+
+api = FastAPI(title="Legacy")
+
+
+@api.get("/health")
+async def health():
+    return {"ok": True}
+
+
+@api.post("/api/enrich")
+async def enrich(payload: EnrichRequest, db=Depends(get_db)):
+    """Enrich a contact with company data."""
+    return {"ok": True}
+
+
+@api.post("/webhooks/github")
+async def github_hook(payload: dict, tasks: BackgroundTasks):
+    return {"ok": True}
+
+
+@api.post("/chat/{session_id}/stream")
+async def stream(session_id: str, request: Request):
+    return StreamingResponse(gen())
+
+
+@api.post("/admin/reset")
+def reset():
+    return {}
+`,
+		"app/schemas.py": `from typing import Optional
+from pydantic import BaseModel
+
+
+class EnrichRequest(BaseModel):
+    """Request."""
+    model_config = {"extra": "ignore"}
+    email: str
+    company: Optional[str] = None
+    tags: list[str] = []
+    score: int | None
+
+    def normalized(self):
+        return self.email.lower()
+`,
+		".claude/agents/enrich.md": "hi\n",
+		".claude/agents/github.md": "hi\n",
+		".claude/agents/stream.md": "hi\n",
+	})
+
+	p, err := Scan(dir)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if p.AppVar != "api" {
+		t.Fatalf("AppVar = %q, want api", p.AppVar)
+	}
+	if len(p.Routes) != 5 {
+		t.Fatalf("found %d routes, want 5: %+v", len(p.Routes), p.Routes)
+	}
+	fields := p.Models["EnrichRequest"]
+	if len(fields) != 4 || fields[0].Name != "email" || !fields[0].Required || fields[1].Required || fields[2].Type != "list" || fields[3].Type != "int" || fields[3].Required {
+		t.Fatalf("EnrichRequest fields = %+v", fields)
+	}
+
+	services, skipped := p.Propose()
+	want := map[string]string{"enrich": "api", "github": "webhook", "stream": "streaming"}
+	if len(services) != len(want) {
+		t.Fatalf("Propose() = %d services, want %d: %+v", len(services), len(want), services)
+	}
+	for _, s := range services {
+		if want[s.Service.Name] != s.Service.Type {
+			t.Fatalf("service %s has type %s, want %s", s.Service.Name, s.Service.Type, want[s.Service.Name])
+		}
+	}
+	if services[0].Service.Description != "Enrich a contact with company data." || len(services[0].Service.InputSchema.Fields) != 4 {
+		t.Fatalf("enrich service = %+v", services[0].Service)
+	}
+	if len(skipped) != 2 {
+		t.Fatalf("skipped = %+v, want health and reset", skipped)
+	}
+}
+
+func TestScanRequiresFastAPIApp(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Scan(dir); err == nil {
+		t.Fatal("Scan() error = nil, want missing app/main.py error")
+	}
+	writeFiles(t, dir, map[string]string{"app/main.py": "print('hi')\n"})
+	if _, err := Scan(dir); err == nil {
+		t.Fatal("Scan() error = nil, want missing FastAPI() error")
+	}
+}
@@ -3,8 +3,10 @@ package agents
 import (
 	"bytes"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	yaml "go.yaml.in/yaml/v3"
@@ -18,44 +20,211 @@ const (
 	KindOtherMCP    Kind = "other-mcp"
 )
 
+// Type is the kind of Claude Code file an agent was discovered as. Skills and
+// slash commands deploy like agents: their instructions become the system
+// prompt.
+type Type string
+
+const (
+	// TypeAgent is a subagent file under .claude/agents
+	TypeAgent Type = "agent"
+	// TypeSkill is a skill directory under .claude/skills with a SKILL.md
+	TypeSkill Type = "skill"
+	// TypeCommand is a slash command file under .claude/commands
+	TypeCommand Type = "command"
+)
+
+// Dir returns the directory below .claude that holds files of type t
+func (t Type) Dir() string {
+	switch t {
+	case TypeSkill:
+		return "skills"
+	case TypeCommand:
+		return "commands"
+	default:
+		return "agents"
+	}
+}
+
+// SkillFile is the instructions file of a skill directory
+const SkillFile = "SKILL.md"
+
 type Agent struct {
 	Path        string
 	Name        string
 	Description string
 	Tools       []string
+	Model       string
 	Kind        Kind
+	Type        Type
+
+	// RelPath is the slash-separated path below the discovery root, e.g.
+	// support/triage.md for an agent in a nested directory
+	RelPath string
+	// Source labels the root the agent was found in (see Root)
+	Source string
 }
 
 type frontmatterMeta struct {
 	Name        string `yaml:"name"`
 	Description string `yaml:"description"`
 	Tools       any    `yaml:"tools"`
+	Model       string `yaml:"model"`
+	// AllowedTools is the skill and command spelling of tools
+	AllowedTools any `yaml:"allowed-tools"`
+}
+
+// Discovery sources, from highest to lowest priority after --agents-dir
+const (
+	SourceProject = "project"
+	SourceUser    = "user"
+)
+
+// Root is a directory agents are discovered in
+type Root struct {
+	Dir string
+	// Source is shown next to agents from this root: project, user, or the
+	// directory itself for extra roots
+	Source string
+	// Type is what the directory holds; empty means agents
+	Type Type
 }
 
+// Roots returns the discovery roots in priority order: extra agent
+// directories (e.g. from --agents-dir), then the agents, skills and commands
+// of projectDir/.claude, then those of ~/.claude
+func Roots(projectDir string, extra ...string) []Root {
+	var roots []Root
+	for _, dir := range extra {
+		roots = append(roots, Root{Dir: dir, Source: dir, Type: TypeAgent})
+	}
+	claudeRoots := func(claudeDir, source string) {
+		for _, t := range []Type{TypeAgent, TypeSkill, TypeCommand} {
+			roots = append(roots, Root{Dir: filepath.Join(claudeDir, t.Dir()), Source: source, Type: t})
+		}
+	}
+	claudeRoots(filepath.Join(projectDir, ".claude"), SourceProject)
+	if home, err := os.UserHomeDir(); err == nil {
+		claudeRoots(filepath.Join(home, ".claude"), SourceUser)
+	}
+	return roots
+}
+
+// DiscoverRoots discovers the agents, skills and commands in every root,
+// including nested directories. Roots that do not exist are skipped. When
+// roots define two of the same type and name, the one from the earlier root
+// is kept.
+func DiscoverRoots(roots []Root) ([]Agent, error) {
+	var agents []Agent
+	seenDirs := map[string]bool{}
+	seenNames := map[string]bool{}
+	for _, root := range roots {
+		abs, err := filepath.Abs(root.Dir)
+		if err != nil {
+			return nil, err
+		}
+		if seenDirs[abs] {
+			continue
+		}
+		seenDirs[abs] = true
+
+		var found []Agent
+		switch root.Type {
+		case TypeSkill:
+			found, err = DiscoverSkills(root.Dir)
+		case TypeCommand:
+			found, err = DiscoverCommands(root.Dir)
+		default:
+			found, err = Discover(root.Dir)
+		}
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range found {
+			key := string(a.Type) + "/" + strings.ToLower(a.Name)
+			if seenNames[key] {
+				continue
+			}
+			seenNames[key] = true
+			a.Source = root.Source
+			agents = append(agents, a)
+		}
+	}
+	return agents, nil
+}
+
+// Discover reads the agent files in agentsDir and its subdirectories
 func Discover(agentsDir string) ([]Agent, error) {
-	entries, err := os.ReadDir(agentsDir)
+	return discoverFiles(agentsDir, TypeAgent)
+}
+
+// DiscoverCommands reads the slash command files in commandsDir and its
+// subdirectories. Commands are named after their file unless the frontmatter
+// sets a name.
+func DiscoverCommands(commandsDir string) ([]Agent, error) {
+	return discoverFiles(commandsDir, TypeCommand)
+}
+
+// DiscoverSkills reads the skills in skillsDir: every subdirectory with a
+// SKILL.md. Skills are named after their directory unless the frontmatter
+// sets a name.
+func DiscoverSkills(skillsDir string) ([]Agent, error) {
+	entries, err := os.ReadDir(skillsDir)
 	if err != nil {
 		return nil, err
 	}
-
-	var agents []Agent
+	var skills []Agent
 	for _, ent := range entries {
-		if ent.IsDir() {
+		if !ent.IsDir() {
 			continue
 		}
-		name := ent.Name()
-		if !strings.HasSuffix(strings.ToLower(name), ".md") {
+		path := filepath.Join(skillsDir, ent.Name(), SkillFile)
+		if _, err := os.Stat(path); err != nil {
 			continue
 		}
+		skill, err := parseAgentFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("parse skill %s: %w", ent.Name(), err)
+		}
+		if skill.Name == strings.TrimSuffix(SkillFile, ".md") {
+			skill.Name = ent.Name()
+		}
+		skill.Type = TypeSkill
+		skill.RelPath = ent.Name() + "/" + SkillFile
+		skills = append(skills, skill)
+	}
+	return skills, nil
+}
+
+func discoverFiles(dir string, typ Type) ([]Agent, error) {
+	var agents []Agent
+	err := filepath.WalkDir(dir, func(path string, ent fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ent.IsDir() || !strings.HasSuffix(strings.ToLower(ent.Name()), ".md") {
+			return nil
+		}
 
-		fullPath := filepath.Join(agentsDir, name)
-		agent, err := parseAgentFile(fullPath)
+		rel, err := filepath.Rel(dir, path)
 		if err != nil {
-			return nil, fmt.Errorf("parse agent %s: %w", name, err)
+			return err
 		}
+		agent, err := parseAgentFile(path)
+		if err != nil {
+			return fmt.Errorf("parse %s %s: %w", typ, filepath.ToSlash(rel), err)
+		}
+		agent.Type = typ
+		agent.RelPath = filepath.ToSlash(rel)
 		agents = append(agents, agent)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
 	return agents, nil
 }
 
@@ -80,7 +249,12 @@ func parseAgentFile(path string) (Agent, error) {
 		agent.Name = meta.Name
 	}
 	agent.Description = processDescription(meta.Description)
-	agent.Tools = normalizeTools(meta.Tools)
+	tools := meta.Tools
+	if tools == nil {
+		tools = meta.AllowedTools
+	}
+	agent.Tools = normalizeTools(tools)
+	agent.Model = strings.TrimSpace(meta.Model)
 	agent.Kind = classifyTools(agent.Tools)
 	return agent, nil
 }
@@ -218,3 +392,71 @@ func classifyTools(tools []string) Kind {
 	}
 	return KindDatagenOnly
 }
+
+// ParseFile reads a single agent prompt file and its frontmatter
+func ParseFile(path string) (Agent, error) {
+	return parseAgentFile(path)
+}
+
+// Prompt is an agent file as the generated app loads it
+type Prompt struct {
+	Agent
+	// SystemPrompt is the markdown after the frontmatter, trimmed
+	SystemPrompt string
+	// AllowedTools are the frontmatter tools as written; names are case
+	// sensitive for the Agent SDK
+	AllowedTools []string
+}
+
+// DefaultTools are granted to agent files without frontmatter
+var DefaultTools = []string{"mcp__datagen__getToolDetails", "mcp__datagen__executeTool"}
+
+// LoadPrompt reads an agent file the way the generated app's AgentConfig does
+func LoadPrompt(path string) (Prompt, error) {
+	agent, err := parseAgentFile(path)
+	if err != nil {
+		return Prompt{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Prompt{}, err
+	}
+	p := Prompt{Agent: agent}
+	fm := readFrontmatter(string(data))
+	if !fm.present || fm.err != nil {
+		p.SystemPrompt = strings.TrimSpace(string(data))
+		p.AllowedTools = DefaultTools
+		return p, nil
+	}
+	body, _ := splitBody(string(data))
+	p.SystemPrompt = strings.TrimSpace(body)
+	raw, ok := fm.fields["tools"]
+	if !ok {
+		raw = fm.fields["allowed-tools"]
+	}
+	if entries, _, err := toolEntries(raw); err == nil {
+		p.AllowedTools, _ = cleanTools(entries)
+	}
+	return p, nil
+}
+
+// ExternalMCPServers returns the MCP servers other than datagen that the
+// mcp__<server>__<tool> entries in tools refer to, sorted and de-duplicated
+func ExternalMCPServers(tools []string) []string {
+	seen := map[string]bool{}
+	var servers []string
+	for _, t := range tools {
+		rest, ok := strings.CutPrefix(t, "mcp__")
+		if !ok {
+			continue
+		}
+		server, _, _ := strings.Cut(rest, "__")
+		if server == "" || server == "datagen" || seen[server] {
+			continue
+		}
+		seen[server] = true
+		servers = append(servers, server)
+	}
+	sort.Strings(servers)
+	return servers
+}
@@ -86,3 +86,151 @@ hi
 		t.Fatalf("datagen_tool_names.md kind = %q; want %q", got, KindDatagenOnly)
 	}
 }
+
+func TestExternalMCPServers(t *testing.T) {
+	t.Parallel()
+
+	tools := []string{"read", "mcp__datagen__executetool", "mcp__github__create_issue", "mcp__slack__post", "mcp__github__list_prs"}
+	got := ExternalMCPServers(tools)
+	if len(got) != 2 || got[0] != "github" || got[1] != "slack" {
+		t.Fatalf("ExternalMCPServers() = %v, want [github slack]", got)
+	}
+}
+
+func TestDiscoverRoots(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	extra := filepath.Join(dir, "shared")
+	project := filepath.Join(dir, "project", ".claude", "agents")
+	user := filepath.Join(dir, "home", ".claude", "agents")
+	write := func(path, name string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("---\nname: "+name+"\n---\nhi\n"), 0644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	write(filepath.Join(extra, "triage.md"), "triage")
+	write(filepath.Join(project, "Triage.md"), "Triage")
+	write(filepath.Join(project, "support", "refunds.md"), "refunds")
+	write(filepath.Join(project, "notes.txt"), "ignored")
+	write(filepath.Join(user, "refunds.md"), "refunds")
+	write(filepath.Join(user, "digest.md"), "digest")
+
+	roots := []Root{
+		{Dir: extra, Source: extra},
+		{Dir: project, Source: SourceProject},
+		{Dir: project, Source: "duplicate root"},
+		{Dir: user, Source: SourceUser},
+		{Dir: filepath.Join(dir, "missing"), Source: "missing"},
+	}
+	found, err := DiscoverRoots(roots)
+	if err != nil {
+		t.Fatalf("DiscoverRoots() error = %v", err)
+	}
+
+	got := map[string]Agent{}
+	for _, a := range found {
+		got[a.Name] = a
+	}
+	if len(found) != 3 {
+		t.Fatalf("DiscoverRoots() = %+v, want triage, refunds and digest once each", found)
+	}
+	if a := got["triage"]; a.Source != extra || a.RelPath != "triage.md" {
+		t.Errorf("triage = %+v, want it from the first root", a)
+	}
+	if a := got["refunds"]; a.Source != SourceProject || a.RelPath != "support/refunds.md" {
+		t.Errorf("refunds = %+v, want the nested project agent", a)
+	}
+	if a := got["digest"]; a.Source != SourceUser {
+		t.Errorf("digest = %+v, want it from the user root", a)
+	}
+}
+
+func TestRoots(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	roots := Roots("proj", "extra")
+	want := []Root{
+		{Dir: "extra", Source: "extra", Type: TypeAgent},
+		{Dir: filepath.Join("proj", ".claude", "agents"), Source: SourceProject, Type: TypeAgent},
+		{Dir: filepath.Join("proj", ".claude", "skills"), Source: SourceProject, Type: TypeSkill},
+		{Dir: filepath.Join("proj", ".claude", "commands"), Source: SourceProject, Type: TypeCommand},
+		{Dir: filepath.Join(home, ".claude", "agents"), Source: SourceUser, Type: TypeAgent},
+		{Dir: filepath.Join(home, ".claude", "skills"), Source: SourceUser, Type: TypeSkill},
+		{Dir: filepath.Join(home, ".claude", "commands"), Source: SourceUser, Type: TypeCommand},
+	}
+	if len(roots) != len(want) {
+		t.Fatalf("Roots() = %v, want %v", roots, want)
+	}
+	for i := range want {
+		if roots[i] != want[i] {
+			t.Errorf("Roots()[%d] = %v, want %v", i, roots[i], want[i])
+		}
+	}
+}
+
+func TestDiscoverRoots_SkillsAndCommands(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	claude := filepath.Join(dir, ".claude")
+	write := func(rel, body string) {
+		t.Helper()
+		path := filepath.Join(claude, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+	}
+	write("agents/triage.md", "---\nname: triage\n---\nhi\n")
+	write("skills/triage/SKILL.md", "---\ndescription: Triage skill\nallowed-tools: mcp__datagen__executeTool\n---\nhi\n")
+	write("skills/triage/reference.md", "not a skill")
+	write("skills/pdf/SKILL.md", "---\nname: pdf-tools\ndescription: PDFs\n---\nhi\n")
+	write("skills/empty/notes.md", "no SKILL.md")
+	write("commands/git/review.md", "---\ndescription: Review a diff\n---\nReview $ARGUMENTS\n")
+
+	found, err := DiscoverRoots([]Root{
+		{Dir: filepath.Join(claude, "agents"), Source: SourceProject, Type: TypeAgent},
+		{Dir: filepath.Join(claude, "skills"), Source: SourceProject, Type: TypeSkill},
+		{Dir: filepath.Join(claude, "commands"), Source: SourceProject, Type: TypeCommand},
+	})
+	if err != nil {
+		t.Fatalf("DiscoverRoots() error = %v", err)
+	}
+
+	got := map[string]Agent{}
+	for _, a := range found {
+		got[string(a.Type)+"/"+a.Name] = a
+	}
+	if len(found) != 4 {
+		t.Fatalf("DiscoverRoots() = %+v, want 4 entries", found)
+	}
+	if a := got["agent/triage"]; a.RelPath != "triage.md" {
+		t.Errorf("agent triage = %+v", a)
+	}
+	if a := got["skill/triage"]; a.RelPath != "triage/SKILL.md" || a.Kind != KindDatagenOnly || a.Description != "Triage skill" {
+		t.Errorf("skill triage = %+v, want it named after its directory with allowed-tools read", a)
+	}
+	if a := got["skill/pdf-tools"]; a.RelPath != "pdf/SKILL.md" {
+		t.Errorf("skill pdf-tools = %+v, want the frontmatter name", a)
+	}
+	if a := got["command/review"]; a.RelPath != "git/review.md" || a.Kind != KindNoMCP {
+		t.Errorf("command review = %+v", a)
+	}
+
+	prompt, err := LoadPrompt(got["skill/triage"].Path)
+	if err != nil {
+		t.Fatalf("LoadPrompt() error = %v", err)
+	}
+	if len(prompt.AllowedTools) != 1 || prompt.AllowedTools[0] != "mcp__datagen__executeTool" {
+		t.Errorf("AllowedTools = %v, want allowed-tools", prompt.AllowedTools)
+	}
+}
@@ -0,0 +1,122 @@
+package agents
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DraftSystemPrompt instructs Claude to write an agent markdown file that
+// `datagen start` can deploy and `datagen agents lint` accepts
+const DraftSystemPrompt = `You write Claude agent definition files (.claude/agents/<name>.md) for DataGen, which deploys them as webhook or API services.
+
+Reply with the file contents only: no code fences and no commentary before or after.
+
+The file starts with YAML frontmatter:
+---
+name: <kebab-case name, at most 4 words>
+description: <one sentence saying when to use this agent>
+tools: datagen
+model: sonnet
+---
+
+Use "tools: datagen" when the agent needs external data or actions (DataGen MCP connects CRMs, email, Slack, databases and executeCode). Omit the tools line only when the agent works purely from its input. Use model haiku for simple classification or extraction, sonnet otherwise.
+
+After the frontmatter write the system prompt in second person with these sections:
+## Role
+## Input
+What the request payload contains, with example field names.
+## Steps
+A numbered procedure.
+## Output
+The exact JSON shape to return.
+## Constraints
+What the agent must never do, when executeCode may be used (if tools is datagen), and how to handle missing or ambiguous input.
+
+Never include API keys, tokens or other secrets; refer to environment variables instead.`
+
+// DraftRequest builds the user message asking for an agent that does what
+// description says. A non-empty name is requested as the frontmatter name.
+func DraftRequest(description, name string) string {
+	msg := "Write an agent that does the following:\n\n" + strings.TrimSpace(description)
+	if name != "" {
+		msg += "\n\nName the agent " + name + "."
+	}
+	return msg
+}
+
+var codeFence = regexp.MustCompile("(?s)^```[a-zA-Z]*\n(.*?)\n?```$")
+
+// ParseDraft extracts the agent file from Claude's reply. It strips a
+// surrounding code fence, checks the frontmatter, and returns the contents and
+// the agent's file name (kebab-case, without .md). A non-empty name overrides
+// the one Claude chose.
+func ParseDraft(reply, name string) ([]byte, string, error) {
+	text := strings.TrimSpace(reply)
+	if m := codeFence.FindStringSubmatch(text); m != nil {
+		text = strings.TrimSpace(m[1])
+	}
+	if i := strings.Index(text, "---"); i > 0 {
+		// Drop any preamble before the frontmatter
+		text = text[i:]
+	}
+	content := []byte(text + "\n")
+
+	meta, ok := parseFrontmatter(content)
+	if !ok {
+		return nil, "", fmt.Errorf("draft has no valid YAML frontmatter")
+	}
+	if strings.TrimSpace(meta.Description) == "" {
+		return nil, "", fmt.Errorf("draft frontmatter has no description")
+	}
+	if name == "" {
+		name = meta.Name
+	}
+	slug := Slug(name)
+	if slug == "" {
+		return nil, "", fmt.Errorf("draft frontmatter has no name; pass --name")
+	}
+	if meta.Name != slug {
+		content = setFrontmatterName(content, slug)
+	}
+	return content, slug, nil
+}
+
+var frontmatterNameLine = regexp.MustCompile(`(?m)^name:.*$`)
+
+// setFrontmatterName replaces the name line of the frontmatter, adding one
+// after the opening --- when there is none
+func setFrontmatterName(content []byte, name string) []byte {
+	text := string(content)
+	rest := strings.TrimPrefix(text, "---")
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return content
+	}
+	front := rest[:end]
+	if frontmatterNameLine.MatchString(front) {
+		front = frontmatterNameLine.ReplaceAllLiteralString(front, "name: "+name)
+	} else {
+		nl := strings.Index(front, "\n")
+		front = front[:nl+1] + "name: " + name + "\n" + front[nl+1:]
+	}
+	return []byte("---" + front + rest[end:])
+}
+
+// Slug converts s into a kebab-case agent file name
+func Slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
@@ -0,0 +1,108 @@
+package agents
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const draftReply = `---
+name: Support Triage
+description: Summarize inbound support emails and tag their priority
+tools: datagen
+model: sonnet
+---
+
+## Role
+You triage support email.
+
+## Constraints
+Never reply to the customer.
+`
+
+func TestParseDraft(t *testing.T) {
+	tests := []struct {
+		name     string
+		reply    string
+		override string
+		wantName string
+	}{
+		{"plain", draftReply, "", "support-triage"},
+		{"fenced", "```markdown\n" + draftReply + "```", "", "support-triage"},
+		{"preamble", "Here is the agent:\n\n" + draftReply, "", "support-triage"},
+		{"name override", draftReply, "email-triage", "email-triage"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, name, err := ParseDraft(tt.reply, tt.override)
+			if err != nil {
+				t.Fatalf("ParseDraft() error = %v", err)
+			}
+			if name != tt.wantName {
+				t.Errorf("name = %q, want %q", name, tt.wantName)
+			}
+			if !strings.HasPrefix(string(content), "---\nname: "+tt.wantName+"\n") {
+				t.Errorf("content does not start with the frontmatter name:\n%s", content)
+			}
+			if !strings.HasSuffix(string(content), "Never reply to the customer.\n") {
+				t.Errorf("content lost the body:\n%s", content)
+			}
+
+			path := filepath.Join(t.TempDir(), name+".md")
+			if err := os.WriteFile(path, content, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			agent, err := ParseFile(path)
+			if err != nil {
+				t.Fatalf("ParseFile() error = %v", err)
+			}
+			if agent.Name != tt.wantName || agent.Kind != KindDatagenOnly {
+				t.Errorf("ParseFile() = %+v, want a datagen-only agent named %s", agent, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestParseDraft_AddsMissingName(t *testing.T) {
+	reply := "---\ndescription: Tag refunds\n---\n\nBody\n"
+	content, name, err := ParseDraft(reply, "refunds")
+	if err != nil {
+		t.Fatalf("ParseDraft() error = %v", err)
+	}
+	if name != "refunds" || !strings.HasPrefix(string(content), "---\nname: refunds\ndescription: Tag refunds\n---\n") {
+		t.Errorf("ParseDraft() = %q, %q", name, content)
+	}
+}
+
+func TestParseDraft_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+	}{
+		{"no frontmatter", "I can't help with that."},
+		{"no description", "---\nname: x\n---\nBody\n"},
+		{"no name", "---\ndescription: Does things\n---\nBody\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ParseDraft(tt.reply, ""); err == nil {
+				t.Fatalf("expected an error")
+			}
+		})
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"Support Triage":      "support-triage",
+		"  lead_enricher v2 ": "lead-enricher-v2",
+		"slack-bot":           "slack-bot",
+		"--":                  "",
+	}
+	for in, want := range tests {
+		if got := Slug(in); got != want {
+			t.Errorf("Slug(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package agents
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/debuglog"
+)
+
+// Fix rewrites path to correct its fixable lint findings (missing frontmatter
+// or name, non-kebab-case names, misspelled models, empty or duplicate tools)
+// and returns the findings it fixed. Other content is left untouched.
+func Fix(path string, opts LintOptions) ([]Finding, error) {
+	findings, err := Lint(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	var fixed []Finding
+	rules := map[string]bool{}
+	for _, f := range findings {
+		if f.Fixable {
+			fixed = append(fixed, f)
+			rules[f.Rule] = true
+		}
+	}
+	if len(fixed) == 0 {
+		return nil, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	content := string(data)
+	cr := ""
+	if strings.Contains(content, "\r\n") {
+		cr = "\r"
+	}
+	stem := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	fm := readFrontmatter(content)
+	if !fm.present {
+		name := Slug(stem)
+		if name == "" {
+			name = "agent"
+		}
+		eol := cr + "\n"
+		content = "---" + eol + "name: " + name + eol + "---" + eol + eol + strings.TrimLeft(content, "\r\n")
+	} else {
+		content = fixFrontmatter(content, fm, rules, cr)
+		if rules[RuleName] {
+			name, _ := fm.fields["name"].(string)
+			slug := Slug(name)
+			if slug == "" {
+				slug = Slug(stem)
+			}
+			content = string(setFrontmatterName([]byte(content), slug+cr))
+		}
+	}
+
+	debuglog.FileWrite(path, []byte(content))
+	if err := os.WriteFile(path, []byte(content), info.Mode().Perm()); err != nil {
+		return nil, err
+	}
+	return fixed, nil
+}
+
+// fixFrontmatter rewrites the model and tools lines of the frontmatter
+func fixFrontmatter(content string, fm frontmatter, rules map[string]bool, cr string) string {
+	lines := strings.Split(content, "\n")
+	type edit struct {
+		start, end int
+		repl       []string
+	}
+	var edits []edit
+
+	if model, _ := fm.fields["model"].(string); rules[RuleModel] && canonicalModel(model) != "" {
+		i := fm.keys["model"] - 1
+		edits = append(edits, edit{i, i + 1, []string{"model: " + canonicalModel(model) + cr}})
+	}
+	if rules[RuleToolsSyntax] {
+		if entries, isList, err := toolEntries(fm.fields["tools"]); err == nil {
+			tools, _ := cleanTools(entries)
+			start := fm.keys["tools"] - 1
+			// A list or folded value continues on indented or "- " lines
+			end := start + 1
+			for end < fm.end-1 && (strings.HasPrefix(lines[end], " ") || strings.HasPrefix(lines[end], "\t") || strings.HasPrefix(lines[end], "-")) {
+				end++
+			}
+			repl := []string{"tools: " + strings.Join(tools, ", ") + cr}
+			if isList {
+				repl = []string{"tools:" + cr}
+				for _, t := range tools {
+					repl = append(repl, "  - "+t+cr)
+				}
+			}
+			edits = append(edits, edit{start, end, repl})
+		}
+	}
+
+	// Apply bottom-up so earlier line numbers stay valid
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, e := range edits {
+		lines = append(lines[:e.start], append(e.repl, lines[e.end:]...)...)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,434 @@
+package agents
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/models"
+	yaml "go.yaml.in/yaml/v3"
+)
+
+// Severity ranks lint findings
+type Severity int
+
+const (
+	SeverityInfo Severity = iota + 1
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSeverity parses info, warning or error
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "info":
+		return SeverityInfo, nil
+	case "warning", "warn":
+		return SeverityWarning, nil
+	case "error":
+		return SeverityError, nil
+	default:
+		return 0, fmt.Errorf("unknown severity %q (use info, warning, error)", s)
+	}
+}
+
+// Finding is a single lint result. Line is 1-based, or 0 for file-level findings.
+type Finding struct {
+	Path     string
+	Line     int
+	Rule     string
+	Severity Severity
+	Message  string
+	// Fixable is set when Fix can correct the finding mechanically
+	Fixable bool
+}
+
+func (f Finding) String() string {
+	loc := f.Path
+	if f.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", f.Path, f.Line)
+	}
+	return fmt.Sprintf("%s: %s [%s] %s", loc, f.Severity, f.Rule, f.Message)
+}
+
+// LintOptions carries grants made outside the prompt file itself
+type LintOptions struct {
+	// ExecuteCode is set when datagen.toml allows executeCode for the service
+	// using this prompt
+	ExecuteCode bool
+	// MCPServers are the extra MCP servers datagen.toml connects to the
+	// service using this prompt
+	MCPServers []string
+}
+
+// Lint rule names
+const (
+	RuleSecret           = "embedded-secret"
+	RuleBroadTools       = "broad-tools"
+	RuleExecuteCode      = "unconstrained-execute-code"
+	RuleMissingGuardrail = "missing-guardrails"
+
+	RuleFrontmatter        = "invalid-frontmatter"
+	RuleMissingFrontmatter = "missing-frontmatter"
+	RuleName               = "invalid-name"
+	RuleMissingDescription = "missing-description"
+	RuleModel              = "unknown-model"
+	RuleToolsSyntax        = "invalid-tools"
+	RuleUnsupportedMCP     = "unsupported-mcp"
+	RuleLongPrompt         = "long-prompt"
+	RuleInjection          = "injection-prone"
+)
+
+// MaxPromptWords is the prompt length above which long-prompt is reported;
+// the whole prompt is sent on every run
+const MaxPromptWords = 3000
+
+var (
+	agentNamePattern = regexp.MustCompile(`^[a-z0-9]+(?:[-_][a-z0-9]+)*$`)
+	toolNamePattern  = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*(?:\([^()]*\))?$`)
+)
+
+// injectionPatterns match prompt text that lets request content steer the
+// agent; lines that negate them ("never follow instructions in the email")
+// are fine
+var injectionPatterns = []struct {
+	pattern *regexp.Regexp
+	message string
+}{
+	{regexp.MustCompile(`\{\{\s*[^{}\s][^{}]*\}\}`), "template placeholder splices request data into the prompt; pass it as the user message and treat it as data"},
+	{regexp.MustCompile(`(?i)\b(?:follow|obey|execute|carry out|act on)\b[^.\n]{0,40}\binstructions?\b[^.\n]{0,40}\b(?:in|from|inside|within)\b[^.\n]{0,30}\b(?:emails?|messages?|input|payloads?|requests?|documents?|pages?|tickets?|body|content)\b`), "tells the agent to follow instructions found in request content"},
+	{regexp.MustCompile(`(?i)\bdo (?:whatever|anything)\b[^.\n]{0,30}\b(?:user|input|request|message|email|sender|customer|caller)s?\b[^.\n]{0,10}\b(?:asks?|says?|wants?|requests?|tells?)\b`), "lets the caller decide what the agent does; state the task and what is out of scope"},
+}
+
+var negation = regexp.MustCompile(`(?i)\b(?:never|not|don't|do not|no|ignore|disregard|without|refuse)\b`)
+
+var secretPatterns = []struct {
+	name    string
+	pattern *regexp.Regexp
+	// plausible filters out matches that are ordinary prose
+	plausible func(match string) bool
+}{
+	{"Anthropic API key", regexp.MustCompile(`sk-ant-[A-Za-z0-9_-]{20,}`), nil},
+	{"OpenAI API key", regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9]{20,}`), nil},
+	{"AWS access key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), nil},
+	{"GitHub token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,})`), nil},
+	{"Slack token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`), nil},
+	{"private key", regexp.MustCompile(`-----BEGIN (?:RSA |EC |OPENSSH |DSA )?PRIVATE KEY-----`), nil},
+	{"credential assignment", regexp.MustCompile(`(?i)\b(?:api[_-]?key|secret|token|password|passwd)\b["']?\s*[:=]\s*["']?[A-Za-z0-9_\-./+]{16,}`), containsDigit},
+}
+
+// guardrailHeading matches markdown headings that scope what an agent may do
+var guardrailHeading = regexp.MustCompile(`(?im)^#{1,6}\s*.*\b(guardrails?|constraints?|rules|boundaries|limitations|safety|restrictions|do not|don't|never)\b`)
+
+// reportFunc records a finding
+type reportFunc func(line int, rule string, sev Severity, format string, args ...any)
+
+// Lint checks an agent prompt file for embedded secrets, overly broad tool
+// grants and missing guardrails
+func Lint(path string, opts LintOptions) ([]Finding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	agent, err := parseAgentFile(path)
+	if err != nil {
+		return nil, err
+	}
+	body, bodyLine := splitBody(string(data))
+
+	var findings []Finding
+	report := func(fixable bool) reportFunc {
+		return func(line int, rule string, sev Severity, format string, args ...any) {
+			findings = append(findings, Finding{Path: path, Line: line, Rule: rule, Severity: sev, Message: fmt.Sprintf(format, args...), Fixable: fixable})
+		}
+	}
+	add, addFixable := report(false), report(true)
+
+	for i, line := range strings.Split(string(data), "\n") {
+		for _, sp := range secretPatterns {
+			if m := sp.pattern.FindString(line); m != "" && (sp.plausible == nil || sp.plausible(m)) {
+				add(i+1, RuleSecret, SeverityError, "possible %s in prompt (%s); move it to an environment variable", sp.name, maskSecret(m))
+				break
+			}
+		}
+	}
+
+	fm := readFrontmatter(string(data))
+	switch {
+	case !fm.present:
+		addFixable(0, RuleMissingFrontmatter, SeverityWarning, "no YAML frontmatter with name, description and tools")
+	case fm.err != nil:
+		add(fm.line, RuleFrontmatter, SeverityError, "frontmatter is not valid YAML: %v", fm.err)
+	default:
+		lintFrontmatter(fm, agent, opts, add, addFixable)
+	}
+
+	for i, line := range strings.Split(body, "\n") {
+		for _, ip := range injectionPatterns {
+			loc := ip.pattern.FindStringIndex(line)
+			if loc == nil || negation.MatchString(lastClause(line[:loc[0]])) {
+				continue
+			}
+			add(bodyLine+i, RuleInjection, SeverityWarning, "%s", ip.message)
+			break
+		}
+	}
+	if words := len(strings.Fields(body)); words > MaxPromptWords {
+		add(0, RuleLongPrompt, SeverityWarning, "prompt is %d words (over %d); it is sent on every run, so move reference material into tools or trim it", words, MaxPromptWords)
+	}
+
+	hasFrontmatter := bodyLine > 1
+	if hasFrontmatter && fm.err == nil && len(agent.Tools) == 0 {
+		add(0, RuleBroadTools, SeverityWarning, "no tools listed in frontmatter, so the agent inherits every available tool; list only what it needs")
+	}
+
+	if grantsExecuteCode(agent.Tools) || opts.ExecuteCode {
+		if !strings.Contains(strings.ToLower(body), "executecode") {
+			add(0, RuleExecuteCode, SeverityWarning, "executeCode is granted but the prompt never says when or how it may be used; describe allowed code and data access")
+		}
+	}
+
+	if !guardrailHeading.MatchString(body) {
+		add(0, RuleMissingGuardrail, SeverityInfo, "no guardrails section (e.g. \"## Constraints\" or \"## Guardrails\") limiting what the agent may do")
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Line < findings[j].Line })
+	return findings, nil
+}
+
+// grantsExecuteCode reports whether tools include DataGen's executeCode,
+// either explicitly or through the whole datagen server
+func grantsExecuteCode(tools []string) bool {
+	for _, t := range tools {
+		if t == "datagen" || t == "mcp__datagen" || t == "mcp__datagen__executecode" {
+			return true
+		}
+	}
+	return false
+}
+
+// splitBody returns the markdown after the frontmatter and the 1-based line it
+// starts on; files without frontmatter start on line 1
+func splitBody(content string) (string, int) {
+	lines := strings.Split(content, "\n")
+	start := 0
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	if start >= len(lines) || strings.TrimSpace(lines[start]) != "---" {
+		return content, 1
+	}
+	for i := start + 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return strings.Join(lines[i+1:], "\n"), i + 2
+		}
+	}
+	return content, 1
+}
+
+func containsDigit(s string) bool {
+	return strings.ContainsAny(s, "0123456789")
+}
+
+func maskSecret(s string) string {
+	if len(s) <= 8 {
+		return strings.Repeat("*", len(s))
+	}
+	return s[:4] + "…" + s[len(s)-2:]
+}
+
+// frontmatter is the raw YAML header of an agent file as lint sees it
+type frontmatter struct {
+	present bool
+	err     error
+	// line and end are the 1-based lines of the opening and closing ---
+	line, end int
+	fields    map[string]any
+	// keys maps each top-level key to its 1-based line
+	keys map[string]int
+}
+
+var frontmatterKey = regexp.MustCompile(`^([A-Za-z_][\w-]*)\s*:`)
+
+func readFrontmatter(content string) frontmatter {
+	lines := strings.Split(content, "\n")
+	start := 0
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	if start >= len(lines) || strings.TrimSpace(lines[start]) != "---" {
+		return frontmatter{}
+	}
+	fm := frontmatter{present: true, line: start + 1, keys: map[string]int{}}
+	end := -1
+	for i := start + 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		fm.err = fmt.Errorf("no closing ---")
+		return fm
+	}
+	fm.end = end + 1
+
+	block := make([][]byte, 0, end-start-1)
+	for i := start + 1; i < end; i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		block = append(block, []byte(line))
+		if m := frontmatterKey.FindStringSubmatch(line); m != nil {
+			fm.keys[m[1]] = i + 1
+		}
+	}
+	if err := yaml.Unmarshal(preprocessYAML(block), &fm.fields); err != nil {
+		fm.err = err
+	}
+	return fm
+}
+
+// lintFrontmatter checks the name, description, model and tools keys
+func lintFrontmatter(fm frontmatter, agent Agent, opts LintOptions, add, addFixable reportFunc) {
+	stem := strings.TrimSuffix(filepath.Base(agent.Path), filepath.Ext(agent.Path))
+	name, _ := fm.fields["name"].(string)
+	switch name = strings.TrimSpace(name); {
+	case name == "":
+		addFixable(fm.line, RuleName, SeverityWarning, "no name in frontmatter; the file name %q is used", stem)
+	case !agentNamePattern.MatchString(name) && Slug(name) != "":
+		addFixable(fm.keys["name"], RuleName, SeverityWarning, "name %q should be lowercase letters, digits and hyphens (%q)", name, Slug(name))
+	case !agentNamePattern.MatchString(name):
+		add(fm.keys["name"], RuleName, SeverityError, "name %q has no letters or digits", name)
+	}
+
+	if desc, _ := fm.fields["description"].(string); strings.TrimSpace(desc) == "" {
+		add(0, RuleMissingDescription, SeverityWarning, "no description; datagen start and Claude use it to decide when to use the agent")
+	}
+
+	if raw, ok := fm.fields["model"]; ok {
+		model, _ := raw.(string)
+		verdict := models.Builtin().Check(model)
+		switch {
+		case strings.TrimSpace(model) == "":
+			add(fm.keys["model"], RuleModel, SeverityError, "model must be a model alias (sonnet, opus, haiku) or a Claude model ID")
+		case verdict.Status == models.StatusDeprecated:
+			add(fm.keys["model"], RuleModel, SeverityWarning, "%s", verdict)
+		case verdict.Status == models.StatusRetired:
+			add(fm.keys["model"], RuleModel, SeverityError, "%s", verdict)
+		case verdict.Status == models.StatusCurrent:
+		case canonicalModel(model) != "":
+			addFixable(fm.keys["model"], RuleModel, SeverityError, "model %q is not a known model; did you mean %q?", model, canonicalModel(model))
+		default:
+			add(fm.keys["model"], RuleModel, SeverityError, "%s", verdict)
+		}
+	}
+
+	if raw, ok := fm.fields["tools"]; ok && raw != nil {
+		entries, _, err := toolEntries(raw)
+		if err != nil {
+			add(fm.keys["tools"], RuleToolsSyntax, SeverityError, "%v", err)
+		} else if invalid := invalidTools(entries); len(invalid) > 0 {
+			add(fm.keys["tools"], RuleToolsSyntax, SeverityError, "invalid tool name(s) %s; use names like mcp__datagen__executeTool separated by commas", strings.Join(invalid, ", "))
+		} else if _, changed := cleanTools(entries); changed {
+			addFixable(fm.keys["tools"], RuleToolsSyntax, SeverityWarning, "tools has empty or duplicate entries")
+		}
+	}
+
+	configured := map[string]bool{}
+	for _, s := range opts.MCPServers {
+		configured[strings.ToLower(s)] = true
+	}
+	var unsupported []string
+	for _, s := range ExternalMCPServers(agent.Tools) {
+		if !configured[s] {
+			unsupported = append(unsupported, s)
+		}
+	}
+	if len(unsupported) > 0 {
+		add(fm.keys["tools"], RuleUnsupportedMCP, SeverityWarning, "tools use MCP server(s) %s, which the generated service does not connect; add them as [[service.mcp_servers]] in datagen.toml or remove them", strings.Join(unsupported, ", "))
+	}
+}
+
+// canonicalModel returns the catalog model that model differs from only in
+// case, spacing or dots for dashes, or ""
+func canonicalModel(model string) string {
+	return models.Builtin().Canonical(model)
+}
+
+// toolEntries splits the raw tools value into its entries, reporting whether
+// it was written as a YAML list
+func toolEntries(raw any) ([]string, bool, error) {
+	switch v := raw.(type) {
+	case string:
+		return strings.Split(v, ","), false, nil
+	case []any:
+		entries := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, true, fmt.Errorf("tools entry %v is not a tool name", item)
+			}
+			entries = append(entries, s)
+		}
+		return entries, true, nil
+	default:
+		return nil, false, fmt.Errorf("tools must be a comma-separated string or a list of tool names")
+	}
+}
+
+func invalidTools(entries []string) []string {
+	var invalid []string
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		rest, isMCP := strings.CutPrefix(strings.ToLower(e), "mcp__")
+		if !toolNamePattern.MatchString(e) || (isMCP && strings.TrimLeft(rest, "_") == "") {
+			invalid = append(invalid, fmt.Sprintf("%q", e))
+		}
+	}
+	return invalid
+}
+
+// cleanTools trims entries and drops empty and duplicate ones, reporting
+// whether anything changed
+func cleanTools(entries []string) ([]string, bool) {
+	seen := map[string]bool{}
+	var out []string
+	changed := false
+	for _, e := range entries {
+		t := strings.TrimSpace(e)
+		key := strings.ToLower(t)
+		if t == "" || seen[key] {
+			changed = true
+			continue
+		}
+		seen[key] = true
+		out = append(out, t)
+	}
+	return out, changed
+}
+
+// lastClause returns the text after the last sentence or clause break in s
+func lastClause(s string) string {
+	if i := strings.LastIndexAny(s, ".;:!?"); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
@@ -0,0 +1,221 @@
+// Package agentsync compares the agent files under .claude/agents with the
+// services in datagen.toml and works out the changes that bring them back in
+// step.
+package agentsync
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/agents"
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+// AgentsDir is where synced agent files live, relative to datagen.toml
+const AgentsDir = ".claude/agents"
+
+// Action is what a Change does to datagen.toml
+type Action string
+
+const (
+	// ActionAdd adds a service for a new agent file
+	ActionAdd Action = "add"
+	// ActionRemove removes a service whose agent file is gone
+	ActionRemove Action = "remove"
+	// ActionRename points a service at its agent file's new path
+	ActionRename Action = "rename"
+	// ActionSkip reports a new agent file that cannot be added
+	ActionSkip Action = "skip"
+)
+
+// Change is one step of a sync
+type Change struct {
+	Action Action
+	// Service is the service as it will be after the change; for removals,
+	// the service being removed
+	Service config.Service
+	// OldPrompt is the prompt path a renamed service had
+	OldPrompt string
+	// Agent is the agent file added, renamed to or skipped
+	Agent agents.Agent
+	// Reason says why an agent file is skipped
+	Reason string
+}
+
+// String describes the change in one line
+func (c Change) String() string {
+	switch c.Action {
+	case ActionAdd:
+		return fmt.Sprintf("add service %s (%s, %s)", c.Service.Name, c.Service.Type, c.Service.Prompt)
+	case ActionRemove:
+		return fmt.Sprintf("remove service %s (%s no longer exists)", c.Service.Name, c.Service.Prompt)
+	case ActionRename:
+		return fmt.Sprintf("move service %s from %s to %s", c.Service.Name, c.OldPrompt, c.Service.Prompt)
+	default:
+		return fmt.Sprintf("skip %s: %s", PromptPath(c.Agent), c.Reason)
+	}
+}
+
+// PromptPath is the datagen.toml prompt path of an agent found in AgentsDir
+func PromptPath(a agents.Agent) string {
+	return AgentsDir + "/" + a.RelPath
+}
+
+// Plan compares cfg's services with the agent files found in AgentsDir. New
+// agents become services deployed in mode. A service whose agent file is gone
+// is moved to a new file with the same name, or removed. Services whose
+// prompts live outside AgentsDir are left alone.
+func Plan(cfg *config.DatagenConfig, found []agents.Agent, mode string) []Change {
+	used := map[string]bool{}
+	names := map[string]bool{}
+	for _, svc := range cfg.Services {
+		for _, p := range svc.PromptFiles() {
+			used[cleanPrompt(p)] = true
+		}
+		names[svc.Name] = true
+	}
+	present := map[string]bool{}
+	var fresh []agents.Agent
+	for _, a := range found {
+		p := PromptPath(a)
+		present[p] = true
+		if !used[p] {
+			fresh = append(fresh, a)
+		}
+	}
+	sort.Slice(fresh, func(i, j int) bool { return fresh[i].RelPath < fresh[j].RelPath })
+
+	var changes []Change
+	moved := map[string]bool{}
+	for _, svc := range cfg.Services {
+		prompt := cleanPrompt(svc.Prompt)
+		if !strings.HasPrefix(prompt, AgentsDir+"/") || present[prompt] {
+			continue
+		}
+		renamed := false
+		for _, a := range fresh {
+			if !moved[a.Path] && config.NormalizeServiceName(a.Name) == svc.Name {
+				moved[a.Path] = true
+				updated := svc
+				updated.Prompt = PromptPath(a)
+				changes = append(changes, Change{Action: ActionRename, Service: updated, OldPrompt: svc.Prompt, Agent: a})
+				renamed = true
+				break
+			}
+		}
+		if !renamed {
+			changes = append(changes, Change{Action: ActionRemove, Service: svc})
+			delete(names, svc.Name)
+		}
+	}
+
+	for _, a := range fresh {
+		if moved[a.Path] {
+			continue
+		}
+		if a.Kind == agents.KindOtherMCP {
+			changes = append(changes, Change{Action: ActionSkip, Agent: a, Reason: "uses MCP servers other than datagen; add it with mcp_servers in datagen.toml"})
+			continue
+		}
+		svc := ServiceForAgent(a, PromptPath(a), mode)
+		if names[svc.Name] {
+			changes = append(changes, Change{Action: ActionSkip, Agent: a, Reason: fmt.Sprintf("service %s already exists; rename the agent in its frontmatter", svc.Name)})
+			continue
+		}
+		names[svc.Name] = true
+		changes = append(changes, Change{Action: ActionAdd, Service: svc, Agent: a})
+	}
+	return changes
+}
+
+// Apply makes changes to cfg's services
+func Apply(cfg *config.DatagenConfig, changes []Change) {
+	for _, c := range changes {
+		switch c.Action {
+		case ActionAdd:
+			cfg.Services = append(cfg.Services, c.Service)
+		case ActionRemove:
+			kept := cfg.Services[:0]
+			for _, svc := range cfg.Services {
+				if svc.Name != c.Service.Name {
+					kept = append(kept, svc)
+				}
+			}
+			cfg.Services = kept
+		case ActionRename:
+			for i := range cfg.Services {
+				if cfg.Services[i].Name == c.Service.Name {
+					cfg.Services[i].Prompt = c.Service.Prompt
+				}
+			}
+		}
+	}
+}
+
+// ServiceForAgent returns the service `datagen start` creates for agent a,
+// loading its prompt from promptRel and deployed in mode (api or webhook)
+func ServiceForAgent(a agents.Agent, promptRel, mode string) config.Service {
+	rawName := AgentName(a)
+	serviceName := config.NormalizeServiceName(rawName)
+
+	description := strings.TrimSpace(a.Description)
+	if description == "" {
+		t := a.Type
+		if t == "" {
+			t = agents.TypeAgent
+		}
+		description = fmt.Sprintf("Deploy %s %s", t, rawName)
+	}
+
+	svc := config.Service{
+		Name:        serviceName,
+		Type:        mode,
+		Description: description,
+		Prompt:      promptRel,
+		InputSchema: config.Schema{Fields: []config.Field{}},
+		Auth: &config.Auth{
+			Type:   "api_key",
+			Header: "X-API-Key",
+			EnvVar: config.NormalizeEnvVarName(serviceName) + "_API_KEY",
+		},
+	}
+
+	if a.Kind == agents.KindDatagenOnly {
+		svc.AllowedTools = &config.AllowedTools{
+			ExecuteTools:   true,
+			GetToolDetails: true,
+		}
+	}
+
+	switch mode {
+	case "webhook":
+		svc.WebhookPath = fmt.Sprintf("/webhook/%s", serviceName)
+		svc.Webhook = &config.WebhookConfig{
+			SignatureVerification: "none",
+			RetryEnabled:          false,
+		}
+	case "api":
+		svc.APIPath = fmt.Sprintf("/api/%s", serviceName)
+		svc.API = &config.APIConfig{
+			ResponseFormat:   "json",
+			Timeout:          30,
+			RateLimitEnabled: false,
+		}
+	}
+	return svc
+}
+
+// AgentName is the name a's service is derived from
+func AgentName(a agents.Agent) string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return strings.TrimSuffix(filepath.Base(a.Path), filepath.Ext(a.Path))
+}
+
+func cleanPrompt(p string) string {
+	return strings.TrimPrefix(path.Clean(strings.ReplaceAll(p, "\\", "/")), "./")
+}
@@ -0,0 +1,73 @@
+package agentsync
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/agents"
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+func TestPlan(t *testing.T) {
+	cfg := &config.DatagenConfig{Services: []config.Service{
+		{Name: "alpha", Type: "api", Prompt: ".claude/agents/alpha.md"},
+		{Name: "beta", Type: "webhook", Prompt: "./.claude/agents/beta.md"},
+		{Name: "gone", Type: "api", Prompt: ".claude/agents/gone.md"},
+		{Name: "custom", Type: "api", Prompt: "prompts/custom.md"},
+		{Name: "local", Type: "api", Prompt: ".claude/agents/local.md", Prompts: map[string]string{"fr": ".claude/agents/local.fr.md"}},
+	}}
+	found := []agents.Agent{
+		{Path: "/p/alpha.md", Name: "alpha", RelPath: "alpha.md", Kind: agents.KindNoMCP},
+		{Path: "/p/team/beta.md", Name: "beta", RelPath: "team/beta.md", Kind: agents.KindDatagenOnly},
+		{Path: "/p/local.md", Name: "local", RelPath: "local.md"},
+		{Path: "/p/local.fr.md", Name: "local-fr", RelPath: "local.fr.md"},
+		{Path: "/p/Triage Bot.md", Name: "Triage Bot", Description: "Triage", RelPath: "Triage Bot.md", Kind: agents.KindDatagenOnly},
+		{Path: "/p/github.md", Name: "github", RelPath: "github.md", Kind: agents.KindOtherMCP},
+		{Path: "/p/alpha2.md", Name: "alpha", RelPath: "alpha2.md", Kind: agents.KindNoMCP},
+	}
+
+	changes := Plan(cfg, found, "api")
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.String())
+	}
+	want := []string{
+		"move service beta from ./.claude/agents/beta.md to .claude/agents/team/beta.md",
+		"remove service gone (.claude/agents/gone.md no longer exists)",
+		"add service triage_bot (api, .claude/agents/Triage Bot.md)",
+		"skip .claude/agents/alpha2.md: service alpha already exists; rename the agent in its frontmatter",
+		"skip .claude/agents/github.md: uses MCP servers other than datagen; add it with mcp_servers in datagen.toml",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Plan() =\n%q\nwant\n%q", got, want)
+	}
+
+	added := changes[2].Service
+	if added.APIPath != "/api/triage_bot" || added.Description != "Triage" || !added.AllowedTools.ExecuteTools || added.Auth.EnvVar != "TRIAGE_BOT_API_KEY" {
+		t.Errorf("added service = %+v", added)
+	}
+
+	Apply(cfg, changes)
+	var names, prompts []string
+	for _, svc := range cfg.Services {
+		names = append(names, svc.Name)
+		prompts = append(prompts, svc.Prompt)
+	}
+	if want := []string{"alpha", "beta", "custom", "local", "triage_bot"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("services after Apply() = %v, want %v", names, want)
+	}
+	if prompts[1] != ".claude/agents/team/beta.md" {
+		t.Errorf("beta prompt = %q, want the new path", prompts[1])
+	}
+	if again := Plan(cfg, found, "api"); len(again) != 2 || again[0].Action != ActionSkip || again[1].Action != ActionSkip {
+		t.Errorf("Plan() after Apply() = %v, want only the skips", again)
+	}
+}
+
+func TestPlan_InSync(t *testing.T) {
+	cfg := &config.DatagenConfig{Services: []config.Service{{Name: "alpha", Prompt: ".claude/agents/alpha.md"}}}
+	found := []agents.Agent{{Path: "/p/alpha.md", Name: "alpha", RelPath: "alpha.md"}}
+	if changes := Plan(cfg, found, "api"); len(changes) != 0 {
+		t.Errorf("Plan() = %v, want no changes", changes)
+	}
+}
@@ -561,3 +561,47 @@ func (c *Client) RunCustomTool(toolUUID string, inputVars map[string]interface{}
 
 	return &resp, nil
 }
+
+// ==========================================
+// Account Methods
+// ==========================================
+
+// WhoAmI returns the account and key details for the client's API key
+func (c *Client) WhoAmI() (*WhoAmIResponse, error) {
+	body, err := c.doRequest("GET", "/api/cli/whoami", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp WhoAmIResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// ==========================================
+// CLI State Methods
+// ==========================================
+
+// GetCLIState returns the encrypted CLI state last pushed by this account
+func (c *Client) GetCLIState() (*CLIState, error) {
+	body, err := c.doRequest("GET", "/mcp/cli/state", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp CLIState
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// PutCLIState replaces the stored CLI state with an encrypted blob
+func (c *Client) PutCLIState(state []byte) error {
+	_, err := c.doRequest("PUT", "/mcp/cli/state", CLIState{State: state})
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -162,3 +163,46 @@ func TestValidateAndRunCustomTool(t *testing.T) {
 		t.Fatalf("HTTP calls = %d, want 2", callCount)
 	}
 }
+
+func TestWhoAmI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Fatalf("method = %s, want GET", r.Method)
+		}
+		if r.URL.Path != "/api/cli/whoami" {
+			t.Fatalf("path = %s, want /api/cli/whoami", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid api key"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"userId":"u_1","email":"ada@example.com","organization":"Acme","scopes":["mcp:read","deploy"],"expiresAt":"2027-01-01T00:00:00Z"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key")
+	client.BaseURL = server.URL
+	client.HTTPClient = server.Client()
+
+	resp, err := client.WhoAmI()
+	if err != nil {
+		t.Fatalf("WhoAmI() error = %v", err)
+	}
+	if resp.Email != "ada@example.com" || resp.Organization != "Acme" {
+		t.Fatalf("WhoAmI() = %+v, want ada@example.com at Acme", resp)
+	}
+	if !reflect.DeepEqual(resp.Scopes, []string{"mcp:read", "deploy"}) {
+		t.Fatalf("WhoAmI() scopes = %v, want [mcp:read deploy]", resp.Scopes)
+	}
+	if resp.ExpiresAt == nil || resp.ExpiresAt.Year() != 2027 {
+		t.Fatalf("WhoAmI() expiresAt = %v, want 2027-01-01", resp.ExpiresAt)
+	}
+
+	bad := NewClient("wrong-key")
+	bad.BaseURL = server.URL
+	bad.HTTPClient = server.Client()
+	if _, err := bad.WhoAmI(); err == nil || !strings.Contains(err.Error(), "API error (401)") {
+		t.Fatalf("WhoAmI() with bad key error = %v, want API error (401)", err)
+	}
+}
@@ -530,6 +530,27 @@ type ExecutionTranscriptResponse struct {
 	Transcript TranscriptInfo      `json:"transcript"`
 }
 
+// CLI state sync types
+
+// CLIState is the client-encrypted CLI state blob; the platform stores it opaquely
+type CLIState struct {
+	State     []byte     `json:"state"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// Account types
+
+// WhoAmIResponse describes the account and API key a request was made with
+type WhoAmIResponse struct {
+	UserID       string     `json:"userId"`
+	Email        string     `json:"email"`
+	Name         string     `json:"name,omitempty"`
+	Organization string     `json:"organization,omitempty"`
+	KeyName      string     `json:"keyName,omitempty"`
+	Scopes       []string   `json:"scopes,omitempty"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+}
+
 // Error response
 
 type ErrorResponse struct {
@@ -0,0 +1,279 @@
+// Package archive packs a generated project directory into a tarball or zip
+// with a manifest, for teams that deploy through their own pipelines.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ManifestFile is the manifest's name at the root of the archive
+const ManifestFile = "MANIFEST.json"
+
+// Supported archive formats
+const (
+	TarGz = "tar.gz"
+	Zip   = "zip"
+)
+
+// Manifest lists what an archive contains and where it came from
+type Manifest struct {
+	Name       string      `json:"name"`
+	CreatedAt  time.Time   `json:"created_at"`
+	CLIVersion string      `json:"cli_version"`
+	Source     string      `json:"source"`
+	Files      []FileEntry `json:"files"`
+}
+
+// FileEntry is one archived file
+type FileEntry struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Options controls how an archive is written
+type Options struct {
+	Format     string // TarGz or Zip
+	Name       string // top-level directory inside the archive
+	CLIVersion string
+	Source     string // e.g. "config:datagen.toml" or "directory:./out"
+	Exclude    []string
+	Now        time.Time
+}
+
+// FormatForPath infers the archive format from a file name, defaulting to TarGz
+func FormatForPath(name string) string {
+	if strings.HasSuffix(strings.ToLower(name), ".zip") {
+		return Zip
+	}
+	return TarGz
+}
+
+// ValidateFormat returns an error for unsupported formats
+func ValidateFormat(format string) error {
+	switch format {
+	case TarGz, Zip:
+		return nil
+	default:
+		return fmt.Errorf("unsupported archive format %q (use %s or %s)", format, TarGz, Zip)
+	}
+}
+
+// skipDirs are never archived: VCS data, local environments and caches
+var skipDirs = map[string]bool{
+	".git":         true,
+	".venv":        true,
+	"venv":         true,
+	"__pycache__":  true,
+	"node_modules": true,
+	".datagen":     true,
+}
+
+// skipped reports whether rel (slash-separated) should be left out of the archive.
+// Local .env files hold secrets; .env.example is kept.
+func skipped(rel string, isDir bool) bool {
+	base := path.Base(rel)
+	if isDir {
+		return skipDirs[base]
+	}
+	if base == ".env" || (strings.HasPrefix(base, ".env.") && base != ".env.example") {
+		return true
+	}
+	return strings.HasSuffix(base, ".pyc") || base == ".DS_Store"
+}
+
+// Collect lists the files in dir that belong in an archive, in path order.
+// exclude holds extra slash-separated paths relative to dir to leave out.
+func Collect(dir string, exclude ...string) ([]FileEntry, error) {
+	excluded := make(map[string]bool, len(exclude))
+	for _, rel := range exclude {
+		excluded[path.Clean(rel)] = true
+	}
+
+	var files []FileEntry
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if skipped(rel, d.IsDir()) || excluded[rel] {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		sum, size, err := hashFile(p)
+		if err != nil {
+			return err
+		}
+		files = append(files, FileEntry{Path: rel, Size: size, SHA256: sum})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func hashFile(p string) (string, int64, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// Write archives dir to w with a MANIFEST.json first, and returns the manifest
+func Write(w io.Writer, dir string, opts Options) (*Manifest, error) {
+	if err := ValidateFormat(opts.Format); err != nil {
+		return nil, err
+	}
+	files, err := Collect(dir, opts.Exclude...)
+	if err != nil {
+		return nil, err
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now().UTC()
+	}
+	manifest := &Manifest{
+		Name:       opts.Name,
+		CreatedAt:  now,
+		CLIVersion: opts.CLIVersion,
+		Source:     opts.Source,
+		Files:      files,
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	manifestData = append(manifestData, '\n')
+
+	var aw archiveWriter
+	if opts.Format == Zip {
+		aw = newZipWriter(w)
+	} else {
+		aw = newTarGzWriter(w)
+	}
+
+	prefix := func(rel string) string {
+		if opts.Name == "" {
+			return rel
+		}
+		return opts.Name + "/" + rel
+	}
+	if err := aw.add(prefix(ManifestFile), 0644, now, strings.NewReader(string(manifestData)), int64(len(manifestData))); err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if err := addFile(aw, filepath.Join(dir, filepath.FromSlash(file.Path)), prefix(file.Path), file.Size, now); err != nil {
+			return nil, err
+		}
+	}
+	if err := aw.close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func addFile(aw archiveWriter, src, name string, size int64, modTime time.Time) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return aw.add(name, info.Mode().Perm(), modTime, f, size)
+}
+
+type archiveWriter interface {
+	add(name string, mode fs.FileMode, modTime time.Time, r io.Reader, size int64) error
+	close() error
+}
+
+type tarGzWriter struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func newTarGzWriter(w io.Writer) *tarGzWriter {
+	gz := gzip.NewWriter(w)
+	return &tarGzWriter{gz: gz, tw: tar.NewWriter(gz)}
+}
+
+func (t *tarGzWriter) add(name string, mode fs.FileMode, modTime time.Time, r io.Reader, size int64) error {
+	if err := t.tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    int64(mode),
+		Size:    size,
+		ModTime: modTime,
+		Format:  tar.FormatPAX,
+	}); err != nil {
+		return err
+	}
+	_, err := io.Copy(t.tw, r)
+	return err
+}
+
+func (t *tarGzWriter) close() error {
+	if err := t.tw.Close(); err != nil {
+		return err
+	}
+	return t.gz.Close()
+}
+
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func newZipWriter(w io.Writer) *zipWriter {
+	return &zipWriter{zw: zip.NewWriter(w)}
+}
+
+func (z *zipWriter) add(name string, mode fs.FileMode, modTime time.Time, r io.Reader, _ int64) error {
+	header := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modTime}
+	header.SetMode(mode)
+	fw, err := z.zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, r)
+	return err
+}
+
+func (z *zipWriter) close() error {
+	return z.zw.Close()
+}
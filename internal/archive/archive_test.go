@@ -0,0 +1,136 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"app/main.py":           "print('hi')\n",
+		".env.example":          "KEY=\n",
+		".env":                  "KEY=secret\n",
+		".env.staging":          "KEY=secret\n",
+		"app/__pycache__/m.pyc": "x",
+		".git/HEAD":             "ref",
+		".datagen/state.json":   "{}",
+		"requirements.txt":      "fastapi\n",
+		"out/agents.tar.gz":     "old",
+	}
+	for name, body := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("MkdirAll error = %v", err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatalf("WriteFile error = %v", err)
+		}
+	}
+	return dir
+}
+
+func TestCollectSkipsSecretsAndCaches(t *testing.T) {
+	dir := writeProject(t)
+
+	files, err := Collect(dir, "out/agents.tar.gz")
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	var got []string
+	for _, f := range files {
+		got = append(got, f.Path)
+	}
+	want := []string{".env.example", "app/main.py", "requirements.txt"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Collect() = %v, want %v", got, want)
+	}
+}
+
+func TestWriteFormats(t *testing.T) {
+	for _, format := range []string{TarGz, Zip} {
+		t.Run(format, func(t *testing.T) {
+			dir := writeProject(t)
+			var buf bytes.Buffer
+			manifest, err := Write(&buf, dir, Options{Format: format, Name: "agents", Source: "directory:.", Exclude: []string{"out/agents.tar.gz"}})
+			if err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if len(manifest.Files) != 3 {
+				t.Fatalf("manifest has %d files, want 3", len(manifest.Files))
+			}
+
+			entries := readArchive(t, format, buf.Bytes())
+			if len(entries) != 4 {
+				t.Fatalf("archive has %d entries, want manifest + 3 files: %v", len(entries), entries)
+			}
+			var decoded Manifest
+			if err := json.Unmarshal([]byte(entries["agents/"+ManifestFile]), &decoded); err != nil {
+				t.Fatalf("manifest is not JSON: %v", err)
+			}
+			if decoded.Name != "agents" || len(decoded.Files) != 3 {
+				t.Fatalf("manifest = %+v", decoded)
+			}
+			if entries["agents/app/main.py"] != "print('hi')\n" {
+				t.Fatalf("app/main.py = %q", entries["agents/app/main.py"])
+			}
+		})
+	}
+}
+
+func readArchive(t *testing.T, format string, data []byte) map[string]string {
+	t.Helper()
+	entries := map[string]string{}
+	if format == Zip {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("zip.NewReader error = %v", err)
+		}
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatalf("Open(%s) error = %v", f.Name, err)
+			}
+			body, _ := io.ReadAll(rc)
+			rc.Close()
+			entries[f.Name] = string(body)
+		}
+		return entries
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("gzip.NewReader error = %v", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("tar Next error = %v", err)
+		}
+		body, _ := io.ReadAll(tr)
+		entries[hdr.Name] = string(body)
+	}
+	return entries
+}
+
+func TestFormatForPath(t *testing.T) {
+	tests := map[string]string{"a.zip": Zip, "a.ZIP": Zip, "a.tar.gz": TarGz, "": TarGz, "-": TarGz}
+	for name, want := range tests {
+		if got := FormatForPath(name); got != want {
+			t.Fatalf("FormatForPath(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
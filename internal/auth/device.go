@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DeviceCodeGrantType is the OAuth 2.0 device authorization grant (RFC 8628).
+const DeviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// devicePollUnit scales the server-provided polling interval; tests shrink it.
+var devicePollUnit = time.Second
+
+// DeviceCode is the server's response to a device authorization request.
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// RequestDeviceCode starts a device authorization flow. The user approves it
+// by visiting VerificationURI and entering UserCode on any device.
+func RequestDeviceCode(serverBaseURL string) (*DeviceCode, error) {
+	form := url.Values{}
+	form.Set("client_id", ClientID)
+	form.Set("scope", "read:user deployment:read deployment:run")
+
+	resp, err := http.Post(
+		serverBaseURL+"/api/oauth/device/code",
+		"application/x-www-form-urlencoded",
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("device code request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read device code response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("device code request failed (%d): %s", resp.StatusCode, string(body))
+	}
+
+	var code DeviceCode
+	if err := json.Unmarshal(body, &code); err != nil {
+		return nil, fmt.Errorf("failed to parse device code response: %w", err)
+	}
+	if code.DeviceCode == "" || code.UserCode == "" || code.VerificationURI == "" {
+		return nil, fmt.Errorf("incomplete device code response")
+	}
+	if code.Interval <= 0 {
+		code.Interval = 5
+	}
+	return &code, nil
+}
+
+// PollDeviceToken polls the token endpoint until the user approves or denies
+// the device code, the code expires, or ctx is cancelled. It honours the
+// server's interval and backs off on slow_down as RFC 8628 §3.5 requires.
+func PollDeviceToken(ctx context.Context, serverBaseURL string, code *DeviceCode) (*OAuthTokens, error) {
+	interval := time.Duration(code.Interval) * devicePollUnit
+	if code.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(code.ExpiresIn)*devicePollUnit)
+		defer cancel()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, fmt.Errorf("device code expired before login was approved")
+			}
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		tokens, errCode, err := requestDeviceToken(serverBaseURL, code.DeviceCode)
+		if err != nil {
+			return nil, err
+		}
+		switch errCode {
+		case "":
+			return tokens, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * devicePollUnit
+		case "access_denied":
+			return nil, fmt.Errorf("login was denied in the browser")
+		case "expired_token":
+			return nil, fmt.Errorf("device code expired before login was approved")
+		default:
+			return nil, fmt.Errorf("device login failed: %s", errCode)
+		}
+	}
+}
+
+// requestDeviceToken makes a single token request for a device code. It
+// returns the OAuth error code when the server has not issued tokens yet.
+func requestDeviceToken(serverBaseURL, deviceCode string) (*OAuthTokens, string, error) {
+	form := url.Values{}
+	form.Set("grant_type", DeviceCodeGrantType)
+	form.Set("device_code", deviceCode)
+	form.Set("client_id", ClientID)
+
+	resp, err := http.Post(
+		serverBaseURL+"/api/oauth/access_token",
+		"application/x-www-form-urlencoded",
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	var result struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		Error        string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, "", fmt.Errorf("token request failed (%d): %s", resp.StatusCode, string(body))
+	}
+	if result.Error != "" {
+		return nil, result.Error, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("token request failed (%d): %s", resp.StatusCode, string(body))
+	}
+	if result.AccessToken == "" {
+		return nil, "", fmt.Errorf("no access_token in response")
+	}
+	return &OAuthTokens{AccessToken: result.AccessToken, RefreshToken: result.RefreshToken}, "", nil
+}
+
+// IsRemoteSession reports whether the CLI is running over SSH, where a
+// browser on the user's machine cannot reach a localhost callback server.
+func IsRemoteSession() bool {
+	return os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != ""
+}
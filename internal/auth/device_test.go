@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeviceLoginFlow(t *testing.T) {
+	devicePollUnit = time.Millisecond
+	t.Cleanup(func() { devicePollUnit = time.Second })
+
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("ParseForm error = %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/oauth/device/code":
+			if got := r.Form.Get("client_id"); got != ClientID {
+				t.Fatalf("client_id = %q, want %q", got, ClientID)
+			}
+			_, _ = w.Write([]byte(`{"device_code":"dev-1","user_code":"ABCD-EFGH","verification_uri":"https://datagen.dev/device","expires_in":600,"interval":1}`))
+		case "/api/oauth/access_token":
+			if got := r.Form.Get("grant_type"); got != DeviceCodeGrantType {
+				t.Fatalf("grant_type = %q, want %q", got, DeviceCodeGrantType)
+			}
+			polls++
+			switch polls {
+			case 1:
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"authorization_pending"}`))
+			case 2:
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"slow_down"}`))
+			default:
+				_, _ = w.Write([]byte(`{"access_token":"at-1","refresh_token":"rt-1"}`))
+			}
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	code, err := RequestDeviceCode(server.URL)
+	if err != nil {
+		t.Fatalf("RequestDeviceCode() error = %v", err)
+	}
+	if code.UserCode != "ABCD-EFGH" {
+		t.Fatalf("RequestDeviceCode() user_code = %q, want ABCD-EFGH", code.UserCode)
+	}
+
+	tokens, err := PollDeviceToken(context.Background(), server.URL, code)
+	if err != nil {
+		t.Fatalf("PollDeviceToken() error = %v", err)
+	}
+	if tokens.AccessToken != "at-1" || tokens.RefreshToken != "rt-1" {
+		t.Fatalf("PollDeviceToken() = %+v, want at-1/rt-1", tokens)
+	}
+	if polls != 3 {
+		t.Fatalf("token polls = %d, want 3", polls)
+	}
+}
+
+func TestPollDeviceTokenDenied(t *testing.T) {
+	devicePollUnit = time.Millisecond
+	t.Cleanup(func() { devicePollUnit = time.Second })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"access_denied"}`))
+	}))
+	defer server.Close()
+
+	_, err := PollDeviceToken(context.Background(), server.URL, &DeviceCode{DeviceCode: "dev-1", Interval: 1, ExpiresIn: 600})
+	if err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Fatalf("PollDeviceToken() error = %v, want denied", err)
+	}
+}
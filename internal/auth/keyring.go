@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// KeyringService is the service name credentials are stored under in the OS
+// keychain (macOS Keychain, Windows Credential Manager, libsecret on Linux).
+const KeyringService = "datagen-cli"
+
+// SaveToKeyring stores value in the OS keychain under envVar.
+func SaveToKeyring(envVar, value string) error {
+	if err := keyring.Set(KeyringService, envVar, value); err != nil {
+		return fmt.Errorf("failed to save %s to the OS keychain: %w", envVar, err)
+	}
+	return nil
+}
+
+// LoadFromKeyring returns the value stored under envVar in the OS keychain.
+// ok is false when nothing is stored or no keychain is available.
+func LoadFromKeyring(envVar string) (value string, ok bool) {
+	v, err := keyring.Get(KeyringService, envVar)
+	if err != nil || strings.TrimSpace(v) == "" {
+		return "", false
+	}
+	return v, true
+}
+
+// DeleteFromKeyring removes envVar from the OS keychain. Deleting a missing
+// entry is not an error.
+func DeleteFromKeyring(envVar string) error {
+	err := keyring.Delete(KeyringService, envVar)
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("failed to remove %s from the OS keychain: %w", envVar, err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestFindEnvVarOrProfile_ReadsKeychain(t *testing.T) {
+	keyring.MockInit()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv(ProfileEnvVar, "")
+	t.Setenv("DATAGEN_TEST_KEY", "")
+
+	if _, _, ok := FindEnvVarOrProfile("DATAGEN_TEST_KEY"); ok {
+		t.Fatalf("FindEnvVarOrProfile() ok = true before saving")
+	}
+
+	if err := SaveToKeyring("DATAGEN_TEST_KEY", "secret"); err != nil {
+		t.Fatalf("SaveToKeyring() error = %v", err)
+	}
+	value, source, ok := FindEnvVarOrProfile("DATAGEN_TEST_KEY")
+	if !ok || value != "secret" || source != "keychain" {
+		t.Fatalf("FindEnvVarOrProfile() = %q, %q, %v; want secret from keychain", value, source, ok)
+	}
+
+	t.Setenv("DATAGEN_TEST_KEY", "from-env")
+	if value, source, _ := FindEnvVarOrProfile("DATAGEN_TEST_KEY"); value != "from-env" || source != "environment" {
+		t.Fatalf("FindEnvVarOrProfile() = %q, %q; want the environment to win", value, source)
+	}
+
+	if err := DeleteFromKeyring("DATAGEN_TEST_KEY"); err != nil {
+		t.Fatalf("DeleteFromKeyring() error = %v", err)
+	}
+	if err := DeleteFromKeyring("DATAGEN_TEST_KEY"); err != nil {
+		t.Fatalf("DeleteFromKeyring() on missing entry error = %v", err)
+	}
+}
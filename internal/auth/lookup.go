@@ -6,19 +6,31 @@ import (
 	"strings"
 )
 
-// FindEnvVarOrProfile returns the env var value either from the current process
-// environment or (if missing) from a "datagen login" block in common shell
-// profile files.
+// FindEnvVarOrProfile returns the env var value from the active named profile
+// (see ActiveProfile), then the current process environment, then the OS
+// keychain, then a "datagen login" block in common shell profile files.
+// A named profile wins over the environment so that switching profiles takes
+// effect even in shells that export the default key.
 func FindEnvVarOrProfile(envVar string) (value string, source string, ok bool) {
 	envVar = strings.TrimSpace(envVar)
 	if envVar == "" {
 		return "", "", false
 	}
 
+	if profile := ActiveProfile(); profile != DefaultProfile {
+		if v, found := LoadProfileKey(profile, envVar); found {
+			return v, "profile " + profile, true
+		}
+	}
+
 	if v, exists := os.LookupEnv(envVar); exists && strings.TrimSpace(v) != "" {
 		return v, "environment", true
 	}
 
+	if v, ok := LoadFromKeyring(envVar); ok {
+		return v, "keychain", true
+	}
+
 	home, err := os.UserHomeDir()
 	if err != nil || strings.TrimSpace(home) == "" {
 		return "", "", false
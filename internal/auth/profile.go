@@ -6,6 +6,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/backup"
+	"github.com/datagendev/datagen-cli/internal/debuglog"
 )
 
 type Shell string
@@ -150,6 +153,9 @@ func EnsureProfileUpdated(profilePath string, block string) error {
 	if err := os.MkdirAll(filepath.Dir(profilePath), 0755); err != nil {
 		return fmt.Errorf("failed to create profile parent dir: %w", err)
 	}
+	if err := backup.Save(profilePath); err != nil {
+		return err
+	}
 
 	return writeFileAtomic(profilePath, []byte(updated), mode)
 }
@@ -206,6 +212,7 @@ func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
 	dir := filepath.Dir(path)
 	tmp := filepath.Join(dir, "."+filepath.Base(path)+".datagen.tmp")
 
+	debuglog.FileWrite(path, data)
 	if err := os.WriteFile(tmp, data, mode); err != nil {
 		return fmt.Errorf("failed to write temp file: %w", err)
 	}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultProfile is the profile used when none is selected. Its key lives where
+// `datagen login` has always put it: the environment, keychain or shell profile.
+const DefaultProfile = "default"
+
+// ProfileEnvVar overrides the active profile for a single command or shell
+const ProfileEnvVar = "DATAGEN_PROFILE"
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// ValidProfileName reports whether name can be used as a profile name
+func ValidProfileName(name string) bool {
+	return profileNamePattern.MatchString(name)
+}
+
+// Profile is a named DataGen account
+type Profile struct {
+	Name     string
+	Keychain bool // key is stored in the OS keychain rather than the profiles file
+}
+
+type profileStore struct {
+	Active   string                  `json:"active,omitempty"`
+	Profiles map[string]profileEntry `json:"profiles,omitempty"`
+}
+
+type profileEntry struct {
+	Keychain bool              `json:"keychain,omitempty"`
+	Keys     map[string]string `json:"keys,omitempty"` // only used when no keychain is available
+}
+
+// ProfilesPath returns the path of the profiles file, next to credentials.json
+func ProfilesPath() (string, error) {
+	path, err := CredentialsPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "profiles.json"), nil
+}
+
+func loadProfileStore() (*profileStore, error) {
+	path, err := ProfilesPath()
+	if err != nil {
+		return nil, err
+	}
+	store := &profileStore{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return store, nil
+}
+
+func saveProfileStore(store *profileStore) error {
+	path, err := ProfilesPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'), 0600)
+}
+
+// ActiveProfile returns the profile selected by DATAGEN_PROFILE, else by
+// `datagen profile use`, else DefaultProfile
+func ActiveProfile() string {
+	if name := strings.TrimSpace(os.Getenv(ProfileEnvVar)); name != "" {
+		return name
+	}
+	store, err := loadProfileStore()
+	if err != nil || store.Active == "" {
+		return DefaultProfile
+	}
+	return store.Active
+}
+
+// ListProfiles returns every saved profile, plus DefaultProfile, in name order
+func ListProfiles() ([]Profile, error) {
+	store, err := loadProfileStore()
+	if err != nil {
+		return nil, err
+	}
+	profiles := []Profile{}
+	if _, ok := store.Profiles[DefaultProfile]; !ok {
+		profiles = append(profiles, Profile{Name: DefaultProfile})
+	}
+	for name, entry := range store.Profiles {
+		profiles = append(profiles, Profile{Name: name, Keychain: entry.Keychain})
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+// UseProfile makes name the active profile
+func UseProfile(name string) error {
+	store, err := loadProfileStore()
+	if err != nil {
+		return err
+	}
+	if _, ok := store.Profiles[name]; !ok && name != DefaultProfile {
+		return fmt.Errorf("unknown profile %q (create it with 'datagen login --profile %s')", name, name)
+	}
+	store.Active = name
+	if name == DefaultProfile {
+		store.Active = ""
+	}
+	return saveProfileStore(store)
+}
+
+func profileKeyringAccount(profile, envVar string) string {
+	return envVar + "@" + profile
+}
+
+// SaveProfileKey stores value for envVar under a named profile, in the OS
+// keychain when available and otherwise in the profiles file (mode 0600).
+// It returns a description of where the key was stored.
+func SaveProfileKey(profile, envVar, value string) (string, error) {
+	if !ValidProfileName(profile) {
+		return "", fmt.Errorf("invalid profile name %q (use letters, digits, - and _)", profile)
+	}
+	store, err := loadProfileStore()
+	if err != nil {
+		return "", err
+	}
+	if store.Profiles == nil {
+		store.Profiles = map[string]profileEntry{}
+	}
+	entry := store.Profiles[profile]
+
+	where := "the OS keychain"
+	if err := SaveToKeyring(profileKeyringAccount(profile, envVar), value); err == nil {
+		entry.Keychain = true
+		delete(entry.Keys, envVar)
+	} else {
+		if entry.Keys == nil {
+			entry.Keys = map[string]string{}
+		}
+		entry.Keys[envVar] = value
+		where, _ = ProfilesPath()
+	}
+	store.Profiles[profile] = entry
+	if err := saveProfileStore(store); err != nil {
+		return "", err
+	}
+	return where, nil
+}
+
+// LoadProfileKey returns the value saved for envVar under a named profile
+func LoadProfileKey(profile, envVar string) (string, bool) {
+	store, err := loadProfileStore()
+	if err != nil {
+		return "", false
+	}
+	entry, ok := store.Profiles[profile]
+	if !ok {
+		return "", false
+	}
+	if v, ok := entry.Keys[envVar]; ok && strings.TrimSpace(v) != "" {
+		return v, true
+	}
+	if entry.Keychain {
+		return LoadFromKeyring(profileKeyringAccount(profile, envVar))
+	}
+	return "", false
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func setupProfileHome(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv(ProfileEnvVar, "")
+	t.Setenv("DATAGEN_API_KEY", "default-key")
+}
+
+func TestNamedProfiles(t *testing.T) {
+	tests := []struct {
+		name         string
+		keychainErr  error
+		wantKeychain bool
+	}{
+		{name: "keychain", wantKeychain: true},
+		{name: "file fallback", keychainErr: errors.New("no keychain")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupProfileHome(t)
+			if tt.keychainErr != nil {
+				keyring.MockInitWithError(tt.keychainErr)
+			} else {
+				keyring.MockInit()
+			}
+
+			if _, err := SaveProfileKey("work", "DATAGEN_API_KEY", "work-key"); err != nil {
+				t.Fatalf("SaveProfileKey() error = %v", err)
+			}
+
+			if got, _, _ := FindEnvVarOrProfile("DATAGEN_API_KEY"); got != "default-key" {
+				t.Fatalf("FindEnvVarOrProfile() = %q before switching, want default-key", got)
+			}
+
+			if err := UseProfile("work"); err != nil {
+				t.Fatalf("UseProfile() error = %v", err)
+			}
+			got, source, _ := FindEnvVarOrProfile("DATAGEN_API_KEY")
+			if got != "work-key" || source != "profile work" {
+				t.Fatalf("FindEnvVarOrProfile() = %q, %q; want work-key from profile work", got, source)
+			}
+
+			t.Setenv(ProfileEnvVar, DefaultProfile)
+			if got, _, _ := FindEnvVarOrProfile("DATAGEN_API_KEY"); got != "default-key" {
+				t.Fatalf("FindEnvVarOrProfile() = %q with %s=default, want default-key", got, ProfileEnvVar)
+			}
+
+			profiles, err := ListProfiles()
+			if err != nil {
+				t.Fatalf("ListProfiles() error = %v", err)
+			}
+			if len(profiles) != 2 || profiles[0].Name != DefaultProfile || profiles[1].Name != "work" || profiles[1].Keychain != tt.wantKeychain {
+				t.Fatalf("ListProfiles() = %+v", profiles)
+			}
+		})
+	}
+}
+
+func TestUseProfileRejectsUnknown(t *testing.T) {
+	setupProfileHome(t)
+	if err := UseProfile("missing"); err == nil {
+		t.Fatalf("UseProfile(missing) error = nil, want unknown profile error")
+	}
+	if err := UseProfile(DefaultProfile); err != nil {
+		t.Fatalf("UseProfile(default) error = %v", err)
+	}
+	if got := ActiveProfile(); got != DefaultProfile {
+		t.Fatalf("ActiveProfile() = %q, want default", got)
+	}
+}
@@ -0,0 +1,212 @@
+// Package backup keeps copies of user files datagen rewrites (MCP client
+// configs, shell profiles) so a change can be reverted with `datagen restore`.
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/debuglog"
+)
+
+// Keep is how many backups are kept per file
+const Keep = 10
+
+// ErrNoBackup is returned by Restore for a file without backups
+var ErrNoBackup = errors.New("no backup found")
+
+// absentSuffix marks a backup of a file that did not exist yet; restoring it
+// removes the file
+const absentSuffix = ".absent"
+
+const timeLayout = "20060102T150405.000000000Z"
+
+// Backup is one saved version of a file
+type Backup struct {
+	// Path is the file that was backed up
+	Path string
+	// File holds the saved contents
+	File string
+	Time time.Time
+	// Absent is set when Path did not exist before datagen wrote it
+	Absent bool
+}
+
+// Dir returns ~/.datagen/backups
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".datagen", "backups"), nil
+}
+
+// Save copies path into the backup directory before it is rewritten. A file
+// that does not exist yet is recorded as absent. Older backups beyond Keep
+// are pruned.
+func Save(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	dir, err := fileDir(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	// Coarse clocks can repeat a timestamp; never overwrite an older backup
+	now := time.Now().UTC()
+	name := filepath.Join(dir, now.Format(timeLayout))
+	for exists(name) || exists(name+absentSuffix) {
+		now = now.Add(time.Nanosecond)
+		name = filepath.Join(dir, now.Format(timeLayout))
+	}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		err = os.WriteFile(name+absentSuffix, nil, 0o600)
+	case err != nil:
+		return err
+	default:
+		err = os.WriteFile(name, data, 0o600)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+
+	backups, err := list(path, dir)
+	if err != nil {
+		return err
+	}
+	for _, b := range backups[min(Keep, len(backups)):] {
+		_ = os.Remove(b.File)
+	}
+	return nil
+}
+
+// Latest returns the most recent backup of every backed-up file, newest first
+func Latest() ([]Backup, error) {
+	root, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var latest []Backup
+	for _, e := range entries {
+		path, err := url.QueryUnescape(e.Name())
+		if !e.IsDir() || err != nil {
+			continue
+		}
+		backups, err := list(path, filepath.Join(root, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if len(backups) > 0 {
+			latest = append(latest, backups[0])
+		}
+	}
+	sort.Slice(latest, func(i, j int) bool { return latest[i].Time.After(latest[j].Time) })
+	return latest, nil
+}
+
+// Restore puts back the most recent backup of path and removes it, so calling
+// Restore again reverts the change before that
+func Restore(path string) (Backup, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return Backup{}, err
+	}
+	dir, err := fileDir(path)
+	if err != nil {
+		return Backup{}, err
+	}
+	backups, err := list(path, dir)
+	if err != nil {
+		return Backup{}, err
+	}
+	if len(backups) == 0 {
+		return Backup{}, fmt.Errorf("%w for %s", ErrNoBackup, path)
+	}
+	b := backups[0]
+
+	if b.Absent {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return Backup{}, err
+		}
+	} else {
+		data, err := os.ReadFile(b.File)
+		if err != nil {
+			return Backup{}, err
+		}
+		mode := os.FileMode(0o600)
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return Backup{}, err
+		}
+		debuglog.FileWrite(path, data)
+		if err := os.WriteFile(path, data, mode); err != nil {
+			return Backup{}, err
+		}
+	}
+	return b, os.Remove(b.File)
+}
+
+// fileDir returns the directory holding the backups of path
+func fileDir(path string) (string, error) {
+	root, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, url.QueryEscape(filepath.ToSlash(path))), nil
+}
+
+// list returns the backups in dir, newest first
+func list(path, dir string) ([]Backup, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var backups []Backup
+	for _, e := range entries {
+		stamp, absent := strings.CutSuffix(e.Name(), absentSuffix)
+		t, err := time.Parse(timeLayout, stamp)
+		if e.IsDir() || err != nil {
+			continue
+		}
+		backups = append(backups, Backup{
+			Path:   filepath.FromSlash(path),
+			File:   filepath.Join(dir, e.Name()),
+			Time:   t,
+			Absent: absent,
+		})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Time.After(backups[j].Time) })
+	return backups, nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
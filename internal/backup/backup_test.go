@@ -0,0 +1,89 @@
+package backup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveAndRestore(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.toml")
+
+	// First write creates the file, then two edits follow
+	if err := Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	for _, contents := range []string{"v1\n", "v2\n"} {
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := Save(path); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	if err := os.WriteFile(path, []byte("v3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := Latest()
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+	if len(latest) != 1 || latest[0].Path != path {
+		t.Fatalf("Latest() = %+v, want one backup of %s", latest, path)
+	}
+
+	for _, want := range []string{"v2\n", "v1\n"} {
+		if _, err := Restore(path); err != nil {
+			t.Fatalf("Restore() error = %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Fatalf("after Restore() file = %q, want %q", data, want)
+		}
+	}
+
+	b, err := Restore(path)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if !b.Absent {
+		t.Fatalf("expected the oldest backup to record a missing file")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected %s removed, stat error = %v", path, err)
+	}
+
+	if _, err := Restore(path); !errors.Is(err, ErrNoBackup) {
+		t.Fatalf("Restore() error = %v, want ErrNoBackup", err)
+	}
+}
+
+func TestSavePrunesOldBackups(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), ".zshrc")
+	if err := os.WriteFile(path, []byte("export A=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < Keep+3; i++ {
+		if err := Save(path); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	dir, err := fileDir(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backups, err := list(path, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != Keep {
+		t.Fatalf("kept %d backups, want %d", len(backups), Keep)
+	}
+}
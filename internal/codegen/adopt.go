@@ -0,0 +1,222 @@
+package codegen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+// adoptedMainSection is appended to an adopted app/main.py. It gives the
+// injected handlers the names they use and the markers IncrementalAddService
+// injects between; the application's own code is left alone.
+const adoptedMainSection = `
+# === DATAGEN MANAGED SECTION ===
+# Added by "datagen adopt". Services added with "datagen add" are injected
+# between the markers below.
+import asyncio  # noqa: E402,F401
+import hashlib  # noqa: E402,F401
+import hmac  # noqa: E402,F401
+import json  # noqa: E402
+
+from fastapi import BackgroundTasks, Depends, Header, HTTPException, Request  # noqa: E402,F401
+from fastapi.responses import StreamingResponse  # noqa: E402,F401
+
+from app import canary, capture, lifecycle, locales, metrics, pipeline, stores, streams  # noqa: E402,F401
+from app.agent import agent_executors, load_agent, log_event  # noqa: E402
+from app.config import settings  # noqa: E402
+from app.models import *  # noqa: E402,F403
+%s
+
+def _load_datagen_agents():
+    """Load the agents of services managed by datagen."""
+    # === AGENT LOADING START ===
+    pass
+    # === AGENT LOADING END ===
+
+
+_load_datagen_agents()
+
+
+# === ENDPOINT HANDLERS START ===
+# === ENDPOINT HANDLERS END ===
+`
+
+const adoptedModelsSection = `
+# === DATAGEN MANAGED MODELS ===
+from typing import Any, Dict, List  # noqa: E402,F401
+
+from pydantic import BaseModel  # noqa: E402
+
+# === SERVICE MODELS START ===
+# === SERVICE MODELS END ===
+`
+
+// datagenModules are app modules datagen-managed handlers import; an existing
+// file is reused only if it is datagen's own
+var datagenModules = map[string]string{
+	"app/agent.py":  "def load_agent(",
+	"app/config.py": "Anthropic API key for Claude agent execution",
+}
+
+// AdoptProject prepares an existing FastAPI project in outputDir for
+// IncrementalAddService: it appends marker sections to app/main.py and
+// app/models.py and writes the support modules that are missing. appVar is
+// the name of the project's FastAPI instance. It returns the paths changed.
+func AdoptProject(cfg *config.DatagenConfig, outputDir, appVar string) ([]string, error) {
+	for path, signature := range datagenModules {
+		data, err := os.ReadFile(filepath.Join(outputDir, path))
+		if err == nil && !strings.Contains(string(data), signature) {
+			return nil, fmt.Errorf("%s already exists and was not generated by datagen; datagen-managed handlers import from it, so rename it first", path)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	var changed []string
+
+	mainPath := filepath.Join(outputDir, "app", "main.py")
+	data, err := os.ReadFile(mainPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read main.py: %w", err)
+	}
+	if main := string(data); !strings.Contains(main, "=== ENDPOINT HANDLERS START ===") {
+		alias := ""
+		if appVar != "app" {
+			alias = "\napp = " + appVar + "\n"
+		}
+		main = insertBeforeMainGuard(main, fmt.Sprintf(adoptedMainSection, alias))
+		if err := os.WriteFile(mainPath, []byte(main), 0644); err != nil {
+			return nil, err
+		}
+		changed = append(changed, "app/main.py")
+	}
+
+	modelsPath := filepath.Join(outputDir, "app", "models.py")
+	data, err = os.ReadFile(modelsPath)
+	switch {
+	case os.IsNotExist(err):
+		empty := *cfg
+		empty.Services = nil
+		if err := generateModelsPy(&empty, outputDir); err != nil {
+			return nil, fmt.Errorf("failed to generate models.py: %w", err)
+		}
+		changed = append(changed, "app/models.py")
+	case err != nil:
+		return nil, err
+	case !strings.Contains(string(data), "=== SERVICE MODELS START ==="):
+		content := strings.TrimRight(string(data), "\n") + "\n\n" + adoptedModelsSection
+		if err := os.WriteFile(modelsPath, []byte(content), 0644); err != nil {
+			return nil, err
+		}
+		changed = append(changed, "app/models.py")
+	}
+
+	type supportFile struct {
+		path     string
+		generate func(*config.DatagenConfig, string) error
+	}
+	missing := []supportFile{
+		{"app/__init__.py", withoutConfig(generateInitPy)},
+		{"app/agent.py", generateAgentPy},
+		{"app/config.py", generateConfigPy},
+		{".env.example", generateEnvExample},
+	}
+	for _, mod := range runtimeModules {
+		missing = append(missing, supportFile{"app/" + mod.name + ".py", withoutConfig(mod.generate)})
+	}
+	for _, f := range missing {
+		if _, err := os.Stat(filepath.Join(outputDir, f.path)); err == nil {
+			continue
+		}
+		if err := f.generate(cfg, outputDir); err != nil {
+			return nil, fmt.Errorf("failed to generate %s: %w", displayName(f.path), err)
+		}
+		changed = append(changed, f.path)
+	}
+
+	added, err := mergeRequirements(cfg, outputDir)
+	if err != nil {
+		return nil, err
+	}
+	if added {
+		changed = append(changed, "requirements.txt")
+	}
+
+	if err := generateMetadataJSON(cfg, outputDir); err != nil {
+		return nil, fmt.Errorf("failed to generate metadata.json: %w", err)
+	}
+	return append(changed, MetadataFile), nil
+}
+
+// insertBeforeMainGuard places section before a trailing
+// `if __name__ == "__main__":` block so handlers exist when run as a script
+func insertBeforeMainGuard(content, section string) string {
+	for _, guard := range []string{"\nif __name__ == \"__main__\":", "\nif __name__ == '__main__':"} {
+		if i := strings.LastIndex(content, guard); i >= 0 {
+			return content[:i+1] + strings.TrimLeft(section, "\n") + "\n\n" + content[i+1:]
+		}
+	}
+	return strings.TrimRight(content, "\n") + "\n\n" + section
+}
+
+// mergeRequirements adds the packages the generated runtime needs to an
+// existing requirements.txt, or writes a new one. It reports whether the file
+// changed.
+func mergeRequirements(cfg *config.DatagenConfig, outputDir string) (bool, error) {
+	path := filepath.Join(outputDir, "requirements.txt")
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return true, generateRequirementsTxt(cfg, outputDir)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	tmp, err := os.MkdirTemp("", "datagen-requirements")
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(tmp)
+	if err := generateRequirementsTxt(cfg, tmp); err != nil {
+		return false, err
+	}
+	wanted, err := os.ReadFile(filepath.Join(tmp, "requirements.txt"))
+	if err != nil {
+		return false, err
+	}
+
+	have := map[string]bool{}
+	for _, line := range strings.Split(string(existing), "\n") {
+		if name := requirementName(line); name != "" {
+			have[name] = true
+		}
+	}
+	var add []string
+	for _, line := range strings.Split(string(wanted), "\n") {
+		if name := requirementName(line); name != "" && !have[name] {
+			add = append(add, strings.TrimSpace(line))
+		}
+	}
+	if len(add) == 0 {
+		return false, nil
+	}
+	content := strings.TrimRight(string(existing), "\n") + "\n\n# Added by datagen adopt\n" + strings.Join(add, "\n") + "\n"
+	return true, os.WriteFile(path, []byte(content), 0644)
+}
+
+// requirementName returns the normalized package name of a requirements line
+func requirementName(line string) string {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+		return ""
+	}
+	end := strings.IndexAny(line, "[=<>~!; ")
+	if end >= 0 {
+		line = line[:end]
+	}
+	return strings.ReplaceAll(strings.ToLower(line), "_", "-")
+}
@@ -0,0 +1,108 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+func TestAdoptProjectThenAddService(t *testing.T) {
+	outDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(outDir, "app"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	original := `from fastapi import FastAPI
+
+api = FastAPI()
+
+
+@api.post("/enrich")
+async def enrich(payload: dict):
+    return {"ok": True}
+
+
+if __name__ == "__main__":
+    import uvicorn
+
+    uvicorn.run(api)
+`
+	if err := os.WriteFile(filepath.Join(outDir, "app", "main.py"), []byte(original), 0644); err != nil {
+		t.Fatalf("write main.py: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outDir, "requirements.txt"), []byte("fastapi==0.110.0\nPydantic>=2\n"), 0644); err != nil {
+		t.Fatalf("write requirements.txt: %v", err)
+	}
+
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{
+			{Name: "enrich", Type: "api", Description: "Enrich", Prompt: ".claude/agents/enrich.md", APIPath: "/enrich"},
+		},
+	}
+	if _, err := AdoptProject(cfg, outDir, "api"); err != nil {
+		t.Fatalf("AdoptProject() error = %v", err)
+	}
+
+	added := config.Service{Name: "summarize", Type: "api", Description: "Summarize", Prompt: ".claude/agents/summarize.md", APIPath: "/summarize"}
+	cfg.Services = append(cfg.Services, added)
+	if err := IncrementalAddService(cfg, &added, outDir); err != nil {
+		t.Fatalf("IncrementalAddService() after adopt error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "app", "main.py"))
+	if err != nil {
+		t.Fatalf("read main.py: %v", err)
+	}
+	main := string(data)
+	for _, want := range []string{
+		"@api.post(\"/enrich\")",
+		"app = api\n",
+		`    agent_executors["summarize"] = load_agent("summarize", ".claude/agents/summarize.md")`,
+		`@app.post("/summarize")`,
+	} {
+		if !strings.Contains(main, want) {
+			t.Fatalf("main.py missing %q:\n%s", want, main)
+		}
+	}
+	if strings.Index(main, "# === ENDPOINT HANDLERS END ===") > strings.Index(main, `if __name__ == "__main__":`) {
+		t.Fatalf("managed section should come before the __main__ guard:\n%s", main)
+	}
+
+	reqs, err := os.ReadFile(filepath.Join(outDir, "requirements.txt"))
+	if err != nil {
+		t.Fatalf("read requirements.txt: %v", err)
+	}
+	if !strings.Contains(string(reqs), "claude-agent-sdk") || strings.Count(strings.ToLower(string(reqs)), "pydantic~=") != 0 || strings.Count(string(reqs), "fastapi") != 1 {
+		t.Fatalf("requirements.txt not merged as expected:\n%s", reqs)
+	}
+	for _, f := range []string{"app/agent.py", "app/config.py", "app/models.py", "app/stores.py"} {
+		if _, err := os.Stat(filepath.Join(outDir, f)); err != nil {
+			t.Fatalf("%s not written: %v", f, err)
+		}
+	}
+}
+
+func TestAdoptProjectRejectsForeignConfigModule(t *testing.T) {
+	outDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(outDir, "app"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outDir, "app", "main.py"), []byte("app = FastAPI()\n"), 0644); err != nil {
+		t.Fatalf("write main.py: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outDir, "app", "config.py"), []byte("DEBUG = True\n"), 0644); err != nil {
+		t.Fatalf("write config.py: %v", err)
+	}
+	cfg := &config.DatagenConfig{DatagenAPIKeyEnv: "DATAGEN_API_KEY", ClaudeAPIKeyEnv: "ANTHROPIC_API_KEY"}
+	if _, err := AdoptProject(cfg, outDir, "app"); err == nil || !strings.Contains(err.Error(), "app/config.py") {
+		t.Fatalf("AdoptProject() error = %v, want app/config.py conflict", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(outDir, "app", "main.py"))
+	if string(data) != "app = FastAPI()\n" {
+		t.Fatalf("main.py changed despite the conflict")
+	}
+}
@@ -0,0 +1,159 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+func generateDockerfile(cfg *config.DatagenConfig, outputDir string) error {
+	content := ""
+	if cfg.DockerCacheMounts() {
+		content = "# syntax=docker/dockerfile:1\n"
+	}
+	if cfg.DockerMultiStage() {
+		content += multiStageDockerfile(cfg)
+	} else {
+		content += singleStageDockerfile(cfg)
+	}
+	return os.WriteFile(filepath.Join(outputDir, "Dockerfile"), []byte(content), 0644)
+}
+
+func singleStageDockerfile(cfg *config.DatagenConfig) string {
+	return baseImageComment(cfg) + `
+FROM ` + cfg.BaseImage() + `
+
+# Create a non-root user with home directory
+RUN groupadd -r appuser && useradd -r -g appuser -m -d /home/appuser appuser
+
+# Set working directory
+WORKDIR /app
+
+# Ensure appuser can write to home directory
+RUN mkdir -p /home/appuser && chown -R appuser:appuser /home/appuser
+
+` + dockerInstallSteps(cfg, false) + `
+# Bake the source commit into the image for the /version endpoint
+ARG GIT_COMMIT=""
+ENV GIT_COMMIT=${GIT_COMMIT}
+
+# Copy application code
+COPY . .
+
+# Change ownership to non-root user
+RUN chown -R appuser:appuser /app
+
+# Switch to non-root user
+USER appuser
+
+# Expose port (Railway will set PORT env var)
+EXPOSE 8000
+
+# Start the application using PORT environment variable
+CMD ` + serverCommand(cfg, "${PORT:-8000}") + `
+`
+}
+
+// multiStageDockerfile installs dependencies in a builder stage and copies
+// only the virtualenv into the runtime stage, so compilers, package caches
+// and the packaging tool stay out of the final image
+func multiStageDockerfile(cfg *config.DatagenConfig) string {
+	return `# Build stage: install dependencies into /opt/venv
+FROM ` + cfg.BaseImage() + ` AS builder
+
+WORKDIR /app
+
+` + dockerInstallSteps(cfg, true) + `
+# Runtime stage: the virtualenv and the application code only
+FROM ` + cfg.BaseImage() + `
+
+# Create a non-root user with home directory
+RUN groupadd -r appuser && useradd -r -g appuser -m -d /home/appuser appuser
+
+# Set working directory, writable by appuser
+WORKDIR /app
+RUN chown appuser:appuser /app
+
+# Use the dependencies installed by the build stage
+COPY --from=builder /opt/venv /opt/venv
+ENV VIRTUAL_ENV=/opt/venv PATH="/opt/venv/bin:$PATH"
+
+# Bake the source commit into the image for the /version endpoint
+ARG GIT_COMMIT=""
+ENV GIT_COMMIT=${GIT_COMMIT}
+
+# Copy application code owned by appuser, without a separate chown layer
+COPY --chown=appuser:appuser . .
+
+# Switch to non-root user
+USER appuser
+
+# Expose port (Railway will set PORT env var)
+EXPOSE 8000
+
+# Start the application using PORT environment variable
+CMD ` + serverCommand(cfg, "${PORT:-8000}") + `
+`
+}
+
+func baseImageComment(cfg *config.DatagenConfig) string {
+	if cfg.Docker != nil && cfg.Docker.BaseImage != "" {
+		return "# Base image from [docker] in datagen.toml"
+	}
+	return "# Use Python " + cfg.PythonVersion() + " slim image"
+}
+
+// dockerInstallSteps returns the Dockerfile lines that install dependencies.
+// uv and poetry install the locked versions when the lockfile was generated,
+// and fail the build when it is out of date with pyproject.toml. With venv
+// (the builder stage of a multi-stage build) everything goes into /opt/venv.
+func dockerInstallSteps(cfg *config.DatagenConfig, venv bool) string {
+	// cache returns the RUN prefix that keeps a tool's download cache between
+	// builds, when [docker] cache_mounts is set
+	cache := func(dir string) string {
+		if !cfg.DockerCacheMounts() {
+			return "RUN "
+		}
+		return "RUN --mount=type=cache,target=/root/.cache/" + dir + " "
+	}
+	createVenv := ""
+	if venv {
+		createVenv = `RUN python -m venv /opt/venv
+ENV VIRTUAL_ENV=/opt/venv PATH="/opt/venv/bin:$PATH"
+`
+	}
+
+	switch cfg.Packaging() {
+	case config.PackagingUV:
+		return `# Install uv and the locked dependencies into /opt/venv
+COPY --from=ghcr.io/astral-sh/uv:0.5 /uv /bin/uv
+ENV UV_PROJECT_ENVIRONMENT=/opt/venv UV_COMPILE_BYTECODE=1 UV_LINK_MODE=copy
+ENV PATH="/opt/venv/bin:$PATH"
+COPY pyproject.toml uv.lock* ./
+` + cache("uv") + `if [ -f uv.lock ]; then uv sync --locked --no-dev; else uv sync --no-dev; fi
+`
+	case config.PackagingPoetry:
+		install := "poetry install --only main --no-cache"
+		if cfg.DockerCacheMounts() {
+			install = "poetry install --only main"
+		}
+		return `# Install poetry and the locked dependencies
+ENV POETRY_VIRTUALENVS_CREATE=false POETRY_NO_INTERACTION=1
+RUN pip install --no-cache-dir poetry~=2.1.0
+` + createVenv + `COPY pyproject.toml poetry.lock* ./
+` + cache("pypoetry") + install + `
+`
+	default:
+		install := "pip install --no-cache-dir -r requirements.txt"
+		if cfg.DockerCacheMounts() {
+			install = "pip install -r requirements.txt"
+		}
+		return `# Copy requirements first for better caching
+COPY requirements.txt .
+
+# Install dependencies
+` + createVenv + cache("pip") + install + `
+`
+	}
+}
@@ -0,0 +1,224 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+// generateExamples writes examples/: a sample request body per service, and
+// curl.sh and httpie.sh, which send a service its sample with its auth headers
+func generateExamples(cfg *config.DatagenConfig, outputDir string) error {
+	dir := filepath.Join(outputDir, "examples")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, svc := range cfg.Services {
+		if err := os.WriteFile(filepath.Join(dir, svc.Name+".json"), examplePayload(svc.InputSchema.Fields), 0644); err != nil {
+			return err
+		}
+	}
+	for _, client := range []string{"curl", "httpie"} {
+		if err := os.WriteFile(filepath.Join(dir, client+".sh"), []byte(exampleScript(cfg, client)), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// examplePayload returns a sample JSON request body for fields, in schema
+// order: fields with a default use it, other required fields get a
+// placeholder of their type, and optional fields without a default are left
+// out
+func examplePayload(fields []config.Field) []byte {
+	var b bytes.Buffer
+	b.WriteString("{")
+	n := 0
+	for _, f := range fields {
+		value, ok := exampleValue(f)
+		if !ok {
+			continue
+		}
+		if n > 0 {
+			b.WriteString(",")
+		}
+		name, _ := json.Marshal(f.Name)
+		data, _ := json.Marshal(value)
+		fmt.Fprintf(&b, "\n  %s: %s", name, data)
+		n++
+	}
+	if n > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+func exampleValue(f config.Field) (any, bool) {
+	if f.Default != "" {
+		if value, ok := parseExampleDefault(f); ok {
+			return value, true
+		}
+	}
+	if !f.Required {
+		return nil, false
+	}
+	switch f.Type {
+	case "int":
+		return 1, true
+	case "float":
+		return 1.5, true
+	case "bool":
+		return true, true
+	case "list":
+		return []any{}, true
+	case "dict":
+		return map[string]any{}, true
+	default:
+		return "example " + strings.ReplaceAll(f.Name, "_", " "), true
+	}
+}
+
+// parseExampleDefault reads a field's default as a value of its type
+func parseExampleDefault(f config.Field) (any, bool) {
+	d := strings.Trim(f.Default, `"'`)
+	switch f.Type {
+	case "int":
+		n, err := strconv.Atoi(d)
+		return n, err == nil
+	case "float":
+		n, err := strconv.ParseFloat(d, 64)
+		return n, err == nil
+	case "bool":
+		b, err := strconv.ParseBool(strings.ToLower(d))
+		return b, err == nil
+	case "list", "dict":
+		var v any
+		err := json.Unmarshal([]byte(f.Default), &v)
+		return v, err == nil
+	default:
+		return d, true
+	}
+}
+
+// exampleScript renders examples/curl.sh or examples/httpie.sh: one case per
+// service sending its sample payload
+func exampleScript(cfg *config.DatagenConfig, client string) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&b, "# Send a service its sample request with %s. Generated by datagen from datagen.toml.\n", client)
+	fmt.Fprintf(&b, "# Usage: sh examples/%s.sh <service>\n", client)
+	b.WriteString("# BASE_URL defaults to the local server; auth headers read their keys from the\n")
+	b.WriteString("# environment (load .env with: set -a; . ./.env; set +a)\n")
+	b.WriteString("set -e\n")
+	b.WriteString("DIR=$(dirname \"$0\")\n")
+	b.WriteString("BASE_URL=\"${BASE_URL:-http://localhost:8000}\"\n\n")
+	b.WriteString("case \"$1\" in\n")
+	names := make([]string, len(cfg.Services))
+	for i := range cfg.Services {
+		svc := &cfg.Services[i]
+		names[i] = svc.Name
+		fmt.Fprintf(&b, "%s)\n", svc.Name)
+		for _, line := range exampleCommand(svc, client, "$DIR", "$BASE_URL") {
+			b.WriteString("  " + line + "\n")
+		}
+		b.WriteString("  ;;\n")
+	}
+	b.WriteString("*)\n")
+	fmt.Fprintf(&b, "  echo \"usage: sh examples/%s.sh <service>\" >&2\n", client)
+	fmt.Fprintf(&b, "  echo \"services: %s\" >&2\n", strings.Join(names, " "))
+	b.WriteString("  exit 2\n")
+	b.WriteString("  ;;\n")
+	b.WriteString("esac\n")
+	return b.String()
+}
+
+// exampleCommand returns the shell lines that send svc its sample payload,
+// read from dir, to baseURL
+func exampleCommand(svc *config.Service, client, dir, baseURL string) []string {
+	var lines []string
+	body := fmt.Sprintf("\"%s/%s.json\"", dir, svc.Name)
+	headers := exampleHeaders(svc)
+	if webhook := svc.Webhook; webhook != nil && webhook.SignatureVerification == "hmac_sha256" {
+		switch webhook.Provider {
+		case "stripe", "slack":
+			lines = append(lines, fmt.Sprintf("# %s signs %s differently; send a signed sample event with: datagen simulate", webhook.Provider, svc.Name))
+		default:
+			prefix := ""
+			if webhook.Provider == "github" {
+				prefix = "sha256="
+			}
+			lines = append(lines, fmt.Sprintf("SIGNATURE=%s$(openssl dgst -sha256 -hmac \"$%s\" < %s | sed 's/^.* //')", prefix, webhook.SecretEnv, body))
+			headers = append(headers, [2]string{webhook.SignatureHeader, "$SIGNATURE"})
+		}
+	}
+	target := fmt.Sprintf("\"%s%s\"", baseURL, examplePath(svc))
+
+	if client == "httpie" {
+		cmd := "http"
+		if svc.Type == "streaming" {
+			cmd += " --stream"
+		}
+		cmd += " POST " + target
+		for _, h := range headers {
+			cmd += fmt.Sprintf(" \"%s:%s\"", h[0], h[1])
+		}
+		return append(lines, cmd+" < "+body)
+	}
+
+	cmd := "curl -sS"
+	if svc.Type == "streaming" {
+		cmd += " -N"
+	}
+	lines = append(lines, cmd+" -X POST "+target+" \\", "  -H \"Content-Type: application/json\" \\")
+	for _, h := range headers {
+		lines = append(lines, fmt.Sprintf("  -H \"%s: %s\" \\", h[0], h[1]))
+	}
+	return append(lines, "  --data-binary @"+body)
+}
+
+// examplePath returns svc's path with each {param} filled in from the
+// sample value of the field of that name
+func examplePath(svc *config.Service) string {
+	path := svc.GetPath()
+	for {
+		start := strings.Index(path, "{")
+		end := strings.Index(path, "}")
+		if start < 0 || end < start {
+			return path
+		}
+		name := path[start+1 : end]
+		value := any("example")
+		for _, f := range svc.InputSchema.Fields {
+			if v, ok := exampleValue(f); ok && f.Name == name {
+				value = v
+			}
+		}
+		path = path[:start] + url.PathEscape(fmt.Sprint(value)) + path[end+1:]
+	}
+}
+
+// exampleHeaders returns the auth headers the generated app checks for svc
+func exampleHeaders(svc *config.Service) [][2]string {
+	if svc.Auth == nil || svc.Auth.EnvVar == "" {
+		return nil
+	}
+	switch svc.Auth.Type {
+	case "api_key":
+		header := svc.Auth.Header
+		if header == "" {
+			header = "X-API-Key"
+		}
+		return [][2]string{{header, "$" + svc.Auth.EnvVar}}
+	case "bearer_token":
+		return [][2]string{{"Authorization", "Bearer $" + svc.Auth.EnvVar}}
+	}
+	return nil
+}
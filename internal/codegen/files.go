@@ -0,0 +1,229 @@
+package codegen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/undo"
+)
+
+// generatedFile is one file written by GenerateProject
+type generatedFile struct {
+	path string
+	// together lists files derived from the same config values (e.g. the env
+	// var names in config.py and .env.example); they are regenerated as a group
+	together []string
+	// onDemand files are skipped by GenerateProject and only written by GenerateFiles
+	onDemand bool
+	// keep files are written by GenerateProject only when missing, so local
+	// edits survive rebuilds
+	keep bool
+	// runtime files are read by the running app or its image build, so every
+	// deploy needs them; see RequiredFiles
+	runtime bool
+	// dir entries generate a directory of files (one per service); each file
+	// in it is compared and copied on its own
+	dir bool
+	// when, if set, reports whether cfg has the file at all (requirements.txt
+	// only with pip packaging); GenerateFiles skips it otherwise
+	when     func(cfg *config.DatagenConfig) bool
+	generate func(cfg *config.DatagenConfig, outputDir string) error
+}
+
+func withoutConfig(fn func(outputDir string) error) func(*config.DatagenConfig, string) error {
+	return func(_ *config.DatagenConfig, outputDir string) error { return fn(outputDir) }
+}
+
+// generatedFiles is every generated file, in generation order
+var generatedFiles = []generatedFile{
+	{path: "app/main.py", together: []string{"app/models.py"}, runtime: true, generate: generateMainPy},
+	{path: "app/agent.py", runtime: true, generate: generateAgentPy},
+	{path: "app/config.py", together: []string{".env.example"}, runtime: true, generate: generateConfigPy},
+	{path: "app/models.py", together: []string{"app/main.py"}, runtime: true, generate: generateModelsPy},
+	{path: "app/__init__.py", runtime: true, generate: withoutConfig(generateInitPy)},
+	{path: "app/metrics.py", runtime: true, generate: withoutConfig(generateMetricsPy)},
+	{path: "app/lifecycle.py", runtime: true, generate: withoutConfig(generateLifecyclePy)},
+	{path: "app/canary.py", runtime: true, generate: withoutConfig(generateCanaryPy)},
+	{path: "app/locales.py", runtime: true, generate: withoutConfig(generateLocalesPy)},
+	{path: "app/stores.py", runtime: true, generate: withoutConfig(generateStoresPy)},
+	{path: "app/pipeline.py", runtime: true, generate: withoutConfig(generatePipelinePy)},
+	{path: "app/streams.py", runtime: true, generate: withoutConfig(generateStreamsPy)},
+	{path: "app/capture.py", runtime: true, generate: withoutConfig(generateCapturePy)},
+	{path: "scripts/stream_client.py", generate: generateStreamClientPy},
+	{path: "app/static/playground.html", runtime: true, generate: generatePlaygroundHTML},
+	{path: "requirements.txt", together: []string{"Dockerfile", "Procfile", "pyproject.toml"}, when: usesPip, runtime: true, generate: generateRequirementsTxt},
+	{path: "pyproject.toml", together: []string{"Dockerfile", "requirements.txt"}, when: usesPyproject, runtime: true, generate: generatePyprojectToml},
+	{path: "Dockerfile", together: []string{"requirements.txt", "pyproject.toml", "Procfile"}, generate: generateDockerfile},
+	{path: ".env.example", together: []string{"app/config.py"}, generate: generateEnvExample},
+	{path: "Procfile", together: []string{"Dockerfile", "requirements.txt"}, generate: generateProcfile},
+	{path: "railway.json", generate: generateRailwayJSON},
+	{path: "k8s/hpa.yaml", generate: generateK8sHPA},
+	{path: "README.md", generate: generateREADME},
+	{path: "examples", dir: true, generate: generateExamples},
+	{path: MetadataFile, runtime: true, generate: generateMetadataJSON},
+	{path: ".do/app.yaml", onDemand: true, generate: generateDOAppSpec},
+	{path: ".dockerignore", keep: true, generate: withoutConfig(generateDockerignore)},
+	{path: ".gitignore", keep: true, generate: withoutConfig(generateGitignore)},
+	{path: ".mcp.json", onDemand: true, generate: generateMCPJSON},
+}
+
+// GeneratedFiles returns every generated file path relative to the output
+// directory, including on-demand files such as .do/app.yaml
+func GeneratedFiles() []string {
+	paths := make([]string, len(generatedFiles))
+	for i, f := range generatedFiles {
+		paths[i] = f.path
+	}
+	return paths
+}
+
+func findGeneratedFile(name string) (*generatedFile, bool) {
+	name = filepath.ToSlash(filepath.Clean(name))
+	for i := range generatedFiles {
+		if generatedFiles[i].path == name {
+			return &generatedFiles[i], true
+		}
+	}
+	// Allow bare file names such as "config.py" when unambiguous
+	var match *generatedFile
+	for i := range generatedFiles {
+		if filepath.Base(generatedFiles[i].path) == name {
+			if match != nil {
+				return nil, false
+			}
+			match = &generatedFiles[i]
+		}
+	}
+	return match, match != nil
+}
+
+// ExpandGeneratedFiles resolves names to generated file paths plus the files
+// that must be regenerated with them, in generation order
+func ExpandGeneratedFiles(names []string) ([]string, error) {
+	wanted := map[string]bool{}
+	var queue []string
+	for _, name := range names {
+		f, ok := findGeneratedFile(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown generated file %q (available: %s)", name, strings.Join(GeneratedFiles(), ", "))
+		}
+		queue = append(queue, f.path)
+	}
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+		if wanted[path] {
+			continue
+		}
+		wanted[path] = true
+		f, _ := findGeneratedFile(path)
+		queue = append(queue, f.together...)
+	}
+
+	var paths []string
+	for _, f := range generatedFiles {
+		if wanted[f.path] {
+			paths = append(paths, f.path)
+		}
+	}
+	return paths, nil
+}
+
+// GenerateFiles regenerates only the named files (and the files that go with
+// them), leaving everything else in outputDir untouched. It returns the paths
+// written, leaving out companions cfg doesn't use.
+func GenerateFiles(cfg *config.DatagenConfig, outputDir string, names []string) ([]string, error) {
+	paths, err := ExpandGeneratedFiles(names)
+	if err != nil {
+		return nil, err
+	}
+	absOut, err := filepath.Abs(outputDir)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp("", "datagen-build")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	// Staged like GenerateProject, so each file is snapshotted for undo
+	// before it replaces the one in outputDir
+	stageDir := filepath.Join(tmp, filepath.Base(absOut))
+
+	var written []string
+	for _, path := range paths {
+		f, _ := findGeneratedFile(path)
+		if f.when != nil && !f.when(cfg) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Join(stageDir, filepath.Dir(f.path)), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", f.path, err)
+		}
+		if err := f.generate(cfg, stageDir); err != nil {
+			return nil, fmt.Errorf("failed to generate %s: %w", displayName(f.path), err)
+		}
+		if err := installStaged(stageDir, outputDir, f); err != nil {
+			return nil, err
+		}
+		written = append(written, f.path)
+	}
+	if err := addToManifest(outputDir, written); err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", ManifestFile, err)
+	}
+	return written, nil
+}
+
+// installStaged copies what f generated under stageDir into outputDir
+func installStaged(stageDir, outputDir string, f *generatedFile) error {
+	paths := []string{f.path}
+	if f.dir {
+		var err error
+		if paths, err = stagedFiles(stageDir, f.path); err != nil {
+			return err
+		}
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(filepath.Join(stageDir, path))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(outputDir, path)
+		if err := undo.Save(dest); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(dest, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", displayName(path), err)
+		}
+	}
+	return nil
+}
+
+// GenerateFile writes the single named file, without the files that are
+// normally regenerated with it. It returns the path written.
+func GenerateFile(cfg *config.DatagenConfig, outputDir, name string) (string, error) {
+	f, ok := findGeneratedFile(name)
+	if !ok {
+		return "", fmt.Errorf("unknown generated file %q (available: %s)", name, strings.Join(GeneratedFiles(), ", "))
+	}
+	if err := os.MkdirAll(filepath.Join(outputDir, filepath.Dir(f.path)), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory for %s: %w", f.path, err)
+	}
+	if err := f.generate(cfg, outputDir); err != nil {
+		return "", fmt.Errorf("failed to generate %s: %w", displayName(f.path), err)
+	}
+	return f.path, nil
+}
+
+// displayName drops the app/ prefix for error messages ("main.py", "k8s/hpa.yaml")
+func displayName(path string) string {
+	return strings.TrimPrefix(path, "app/")
+}
@@ -0,0 +1,180 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+func TestExpandGeneratedFiles(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  []string
+	}{
+		{[]string{"Dockerfile"}, []string{"requirements.txt", "pyproject.toml", "Dockerfile", "Procfile"}},
+		{[]string{".env.example"}, []string{"app/config.py", ".env.example"}},
+		{[]string{"hpa.yaml"}, []string{"k8s/hpa.yaml"}},
+		{[]string{"app/metrics.py", "railway.json"}, []string{"app/metrics.py", "railway.json"}},
+	}
+	for _, tt := range tests {
+		got, err := ExpandGeneratedFiles(tt.names)
+		if err != nil {
+			t.Fatalf("ExpandGeneratedFiles(%v) error = %v", tt.names, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("ExpandGeneratedFiles(%v) = %v, want %v", tt.names, got, tt.want)
+		}
+	}
+
+	if _, err := ExpandGeneratedFiles([]string{"app/custom.py"}); err == nil {
+		t.Fatal("ExpandGeneratedFiles(app/custom.py) error = nil, want unknown file error")
+	}
+}
+
+func TestGenerateFilesLeavesOtherFilesAlone(t *testing.T) {
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{
+			{Name: "chat", Type: "api", Prompt: ".claude/agents/chat.md", APIPath: "/chat"},
+		},
+	}
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	mainPath := filepath.Join(outDir, "app", "main.py")
+	if err := os.WriteFile(mainPath, []byte("# customized\n"), 0644); err != nil {
+		t.Fatalf("WriteFile error = %v", err)
+	}
+	if err := os.Remove(filepath.Join(outDir, "Dockerfile")); err != nil {
+		t.Fatalf("Remove error = %v", err)
+	}
+
+	written, err := GenerateFiles(cfg, outDir, []string{"Dockerfile"})
+	if err != nil {
+		t.Fatalf("GenerateFiles() error = %v", err)
+	}
+	if len(written) != 3 {
+		t.Fatalf("GenerateFiles() wrote %v, want Dockerfile with requirements.txt and Procfile", written)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "Dockerfile")); err != nil {
+		t.Fatalf("Dockerfile not regenerated: %v", err)
+	}
+	data, _ := os.ReadFile(mainPath)
+	if string(data) != "# customized\n" {
+		t.Fatalf("app/main.py was rewritten")
+	}
+}
+
+func TestDOAppSpecIsOnDemand(t *testing.T) {
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Scaling:          &config.ScalingConfig{MinReplicas: 2},
+		Services: []config.Service{
+			{Name: "chat", Type: "api", Prompt: ".claude/agents/chat.md", APIPath: "/chat",
+				Auth: &config.Auth{Type: "bearer_token", EnvVar: "CHAT_TOKEN"}},
+		},
+	}
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	specPath := filepath.Join(outDir, ".do", "app.yaml")
+	if _, err := os.Stat(specPath); !os.IsNotExist(err) {
+		t.Fatalf("GenerateProject wrote .do/app.yaml; it should be on demand only")
+	}
+
+	if _, err := GenerateFiles(cfg, outDir, []string{".do/app.yaml"}); err != nil {
+		t.Fatalf("GenerateFiles() error = %v", err)
+	}
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		t.Fatalf("read app.yaml: %v", err)
+	}
+	for _, want := range []string{"instance_count: 2", "key: CHAT_TOKEN\n        scope: RUN_TIME\n        type: SECRET", "http_path: /health"} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("app.yaml missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestGenerateFileWritesOnlyThatFile(t *testing.T) {
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+	}
+
+	for _, name := range []string{"Dockerfile", ".mcp.json", ".gitignore"} {
+		if _, err := GenerateFile(cfg, outDir, name); err != nil {
+			t.Fatalf("GenerateFile(%s) error = %v", name, err)
+		}
+	}
+	entries, err := os.ReadDir(outDir)
+	if err != nil {
+		t.Fatalf("ReadDir error = %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("GenerateFile wrote %d files, want only the 3 requested", len(entries))
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, ".mcp.json"))
+	if err != nil {
+		t.Fatalf("read .mcp.json: %v", err)
+	}
+	if !strings.Contains(string(data), `"X-API-Key": "${DATAGEN_API_KEY}"`) {
+		t.Fatalf(".mcp.json should read the key from the environment:\n%s", data)
+	}
+}
+
+func TestGenerateProjectOnlyRewritesChangedFiles(t *testing.T) {
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{
+			{Name: "chat", Type: "api", Prompt: ".claude/agents/chat.md", APIPath: "/chat"},
+		},
+	}
+	first, err := GenerateProject(cfg, outDir)
+	if err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	if len(first.Created) == 0 || len(first.Updated) != 0 || len(first.Unchanged) != 0 {
+		t.Fatalf("first GenerateProject = %s, want only created files", first)
+	}
+
+	dockerfile := filepath.Join(outDir, "Dockerfile")
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(dockerfile, old, old); err != nil {
+		t.Fatalf("Chtimes error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outDir, "app", "main.py"), []byte("# customized\n"), 0644); err != nil {
+		t.Fatalf("WriteFile error = %v", err)
+	}
+
+	second, err := GenerateProject(cfg, outDir)
+	if err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	if !reflect.DeepEqual(second.Updated, []string{"app/main.py"}) || len(second.Created) != 0 {
+		t.Fatalf("second GenerateProject = %+v, want only app/main.py updated", second)
+	}
+	if len(second.Unchanged) != len(first.Created)-1 {
+		t.Fatalf("second GenerateProject left %d unchanged, want %d", len(second.Unchanged), len(first.Created)-1)
+	}
+	info, err := os.Stat(dockerfile)
+	if err != nil {
+		t.Fatalf("Stat error = %v", err)
+	}
+	if !info.ModTime().Equal(old) {
+		t.Fatalf("unchanged Dockerfile was rewritten (mtime %v, want %v)", info.ModTime(), old)
+	}
+}
@@ -1,14 +1,23 @@
 package codegen
 
 import (
+	"bytes"
 	"embed"
+	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"text/template"
 
 	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/models"
+	"github.com/datagendev/datagen-cli/internal/undo"
 )
 
 //go:embed templates/*
@@ -21,13 +30,208 @@ var templateFuncs = template.FuncMap{
 	"replace": func(old, new, s string) string {
 		return strings.ReplaceAll(s, old, new)
 	},
+	"pyDict":         pyDict,
+	"pyMCPServers":   pyMCPServers,
+	"pyStages":       pyStages,
+	"pyOptions":      pyOptions,
+	"defaultModel":   func() string { return models.Default },
+	"join":           strings.Join,
+	"serviceSecrets": serviceSecrets,
+	"pyDefault":      pyDefault,
 }
 
-// GenerateProject creates the full project structure
-func GenerateProject(cfg *config.DatagenConfig, outputDir string) error {
+// pyDefault renders the default of a model field as the Python literal of
+// its type, or "None" for an optional field without one
+func pyDefault(f config.Field) (string, error) {
+	v, err := f.DefaultValue()
+	if err != nil {
+		return "", fmt.Errorf("field %s: %w", f.Name, err)
+	}
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+		return s, nil
+	case bool:
+		if v {
+			return "True", nil
+		}
+		return "False", nil
+	}
+	return "None", nil
+}
+
+// serviceSecret is a settings field config.py declares for a service's auth
+// or webhook signing secret
+type serviceSecret struct {
+	EnvVar      string
+	Description string
+}
+
+// serviceSecrets returns the auth and webhook secrets of services, once per
+// variable: services sharing [defaults].auth read the same one
+func serviceSecrets(services []config.Service) []serviceSecret {
+	seen := map[string]bool{}
+	var secrets []serviceSecret
+	add := func(envVar, description string) {
+		if key := strings.ToLower(envVar); key != "" && !seen[key] {
+			seen[key] = true
+			secrets = append(secrets, serviceSecret{envVar, description})
+		}
+	}
+	for _, svc := range services {
+		if svc.Auth != nil {
+			add(svc.Auth.EnvVar, "Auth secret for "+svc.Name+" service")
+		}
+		if svc.Webhook != nil {
+			add(svc.Webhook.SecretEnv, "HMAC secret for "+svc.Name+" webhook")
+		}
+	}
+	return secrets
+}
+
+// pyDict renders m as a Python dict literal with sorted keys
+func pyDict(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	items := make([]string, len(keys))
+	for i, k := range keys {
+		items[i] = strconv.Quote(k) + ": " + strconv.Quote(m[k])
+	}
+	return "{" + strings.Join(items, ", ") + "}"
+}
+
+// pyStages renders a pipeline's steps as the stages app/pipeline.py runs: a
+// list of stages, each a list of {"name", "input"} dicts
+func pyStages(svc *config.Service) string {
+	var stages [][]map[string]any
+	for _, stage := range svc.PipelineStages() {
+		steps := make([]map[string]any, len(stage))
+		for i, step := range stage {
+			steps[i] = map[string]any{"name": step.Name}
+			if len(step.Input) > 0 {
+				steps[i]["input"] = step.Input
+			}
+		}
+		stages = append(stages, steps)
+	}
+	// Only strings, lists and dicts: the JSON is a valid Python literal
+	data, _ := json.Marshal(stages)
+	return string(data)
+}
+
+// pyOptions renders a service's model and provider settings as the options
+// dict load_agent takes, or "" when the service uses the defaults
+func pyOptions(svc *config.Service) string {
+	opts := map[string]any{}
+	if svc.Model != "" {
+		opts["model"] = svc.Model
+	}
+	if svc.FallbackModel != "" {
+		opts["fallback_model"] = svc.FallbackModel
+	}
+	if name := svc.ProviderName(); name != config.ProviderAnthropic {
+		provider := map[string]string{"name": name}
+		if svc.Provider.Region != "" {
+			provider["region"] = svc.Provider.Region
+		}
+		if svc.Provider.ProjectID != "" {
+			provider["project_id"] = svc.Provider.ProjectID
+		}
+		opts["provider"] = provider
+	}
+	if len(opts) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(opts)
+	return string(data)
+}
+
+// missingEnvVars returns the vars that have no VAR= line in an env file yet
+func missingEnvVars(envContent string, vars []string) []string {
+	var missing []string
+	for _, v := range vars {
+		if !strings.Contains("\n"+envContent, "\n"+v+"=") {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
+
+// MCPServerEntry returns the Claude Agent SDK mcp_servers entry for s, with
+// ${VAR} references left unexpanded
+func MCPServerEntry(s config.MCPServer) map[string]any {
+	entry := map[string]any{}
+	if s.URL != "" {
+		entry["type"] = "http"
+		if s.Transport != "" {
+			entry["type"] = s.Transport
+		}
+		entry["url"] = s.URL
+		if len(s.Headers) > 0 {
+			entry["headers"] = s.Headers
+		}
+	} else {
+		entry["type"] = "stdio"
+		entry["command"] = s.Command
+		if len(s.Args) > 0 {
+			entry["args"] = s.Args
+		}
+		if len(s.Env) > 0 {
+			entry["env"] = s.Env
+		}
+	}
+	return entry
+}
+
+// pyMCPServers renders a service's extra MCP servers as the Python dict the
+// Claude Agent SDK takes for mcp_servers, keyed by server name
+func pyMCPServers(servers []config.MCPServer) string {
+	items := make([]string, len(servers))
+	for i, s := range servers {
+		// JSON strings, lists and objects are valid Python literals; a map of
+		// strings always marshals
+		data, _ := json.Marshal(MCPServerEntry(s))
+		items[i] = strconv.Quote(s.Name) + ": " + string(data)
+	}
+	return "{" + strings.Join(items, ", ") + "}"
+}
+
+// GenerateResult lists the files GenerateProject created, rewrote, or left
+// alone because their content was already current, relative to the output directory
+type GenerateResult struct {
+	Created   []string
+	Updated   []string
+	Unchanged []string
+
+	// Formatter is the python_formatter that ran over the generated Python,
+	// or "" when none is configured or it is not installed
+	Formatter string
+}
+
+// String summarizes the result as "2 created, 1 updated, 20 unchanged"
+func (r *GenerateResult) String() string {
+	return fmt.Sprintf("%d created, %d updated, %d unchanged", len(r.Created), len(r.Updated), len(r.Unchanged))
+}
+
+// GenerateProject creates the full project structure. Files are generated
+// concurrently into a staging directory and only copied into outputDir when
+// their content changed, so rebuilds keep mtimes (and Docker layer caches) of
+// unchanged files.
+func GenerateProject(cfg *config.DatagenConfig, outputDir string) (*GenerateResult, error) {
 	// Create output directory if it doesn't exist
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	// Create subdirectories
@@ -39,268 +243,177 @@ func GenerateProject(cfg *config.DatagenConfig, outputDir string) error {
 
 	for _, dir := range dirs {
 		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+			return nil, fmt.Errorf("failed to create directory %s: %w", dir, err)
 		}
 	}
 
-	// Generate files
-	if err := generateMainPy(cfg, outputDir); err != nil {
-		return fmt.Errorf("failed to generate main.py: %w", err)
-	}
-
-	if err := generateAgentPy(cfg, outputDir); err != nil {
-		return fmt.Errorf("failed to generate agent.py: %w", err)
+	result := &GenerateResult{}
+	var files []*generatedFile
+	for i := range generatedFiles {
+		file := &generatedFiles[i]
+		if file.onDemand {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(outputDir, file.path)); file.keep && err == nil {
+			result.Unchanged = append(result.Unchanged, file.path)
+			continue
+		}
+		files = append(files, file)
 	}
 
-	if err := generateConfigPy(cfg, outputDir); err != nil {
-		return fmt.Errorf("failed to generate config.py: %w", err)
+	absOut, err := filepath.Abs(outputDir)
+	if err != nil {
+		return nil, err
 	}
-
-	if err := generateModelsPy(cfg, outputDir); err != nil {
-		return fmt.Errorf("failed to generate models.py: %w", err)
+	tmp, err := os.MkdirTemp("", "datagen-build")
+	if err != nil {
+		return nil, err
 	}
+	defer os.RemoveAll(tmp)
+	// Keep the output directory's name, which pyproject.toml uses as the project name
+	stageDir := filepath.Join(tmp, filepath.Base(absOut))
 
-	if err := generateInitPy(outputDir); err != nil {
-		return fmt.Errorf("failed to generate __init__.py: %w", err)
+	errs := make([]error, len(files))
+	var wg sync.WaitGroup
+	for i, file := range files {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := os.MkdirAll(filepath.Join(stageDir, filepath.Dir(file.path)), 0755); err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = file.generate(cfg, stageDir)
+		}()
 	}
-
-	if err := generateRequirementsTxt(outputDir); err != nil {
-		return fmt.Errorf("failed to generate requirements.txt: %w", err)
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate %s: %w", displayName(files[i].path), err)
+		}
 	}
-
-	if err := generateDockerfile(outputDir); err != nil {
-		return fmt.Errorf("failed to generate Dockerfile: %w", err)
+	if FormatterAvailable(cfg) {
+		if err := formatPythonDir(cfg, stageDir); err != nil {
+			return nil, err
+		}
+		result.Formatter = cfg.PythonFormatter
 	}
 
-	if err := generateEnvExample(cfg, outputDir); err != nil {
-		return fmt.Errorf("failed to generate .env.example: %w", err)
+	var paths []string
+	for _, file := range files {
+		if !file.dir {
+			paths = append(paths, file.path)
+			continue
+		}
+		dirPaths, err := stagedFiles(stageDir, file.path)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, dirPaths...)
 	}
-
-	if err := generateProcfile(outputDir); err != nil {
-		return fmt.Errorf("failed to generate Procfile: %w", err)
+	for _, path := range paths {
+		data, err := os.ReadFile(filepath.Join(stageDir, path))
+		if os.IsNotExist(err) {
+			// Not used by cfg, e.g. requirements.txt with uv packaging
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		dest := filepath.Join(outputDir, path)
+		existing, err := os.ReadFile(dest)
+		switch {
+		case err == nil && bytes.Equal(existing, data):
+			result.Unchanged = append(result.Unchanged, path)
+			continue
+		case err == nil:
+			result.Updated = append(result.Updated, path)
+		case os.IsNotExist(err):
+			result.Created = append(result.Created, path)
+		default:
+			return nil, err
+		}
+		if err := undo.Save(dest); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(dest, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", displayName(path), err)
+		}
 	}
 
-	if err := generateRailwayJSON(outputDir); err != nil {
-		return fmt.Errorf("failed to generate railway.json: %w", err)
+	manifest := &Manifest{Files: slices.Concat(result.Created, result.Updated, result.Unchanged)}
+	if err := writeManifest(outputDir, manifest); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", ManifestFile, err)
 	}
+	return result, nil
+}
 
-	if err := generateREADME(cfg, outputDir); err != nil {
-		return fmt.Errorf("failed to generate README.md: %w", err)
+// stagedFiles lists the files a dir entry generated under stageDir, as
+// slash-separated paths relative to stageDir
+func stagedFiles(stageDir, dir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(filepath.Join(stageDir, dir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(stageDir, path)
+		paths = append(paths, filepath.ToSlash(rel))
+		return err
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
 	}
-
-	return nil
+	return paths, err
 }
 
-func generateMainPy(cfg *config.DatagenConfig, outputDir string) error {
-	tmpl, err := template.New("main.py.tmpl").Funcs(templateFuncs).ParseFS(templatesFS, "templates/main.py.tmpl")
+// executeTemplate renders the named app template with cfg into dest, tidying
+// the whitespace of Python output with normalizePython. A file of
+// the same name in the config's templates_dir replaces the embedded template.
+func executeTemplate(cfg *config.DatagenConfig, name, dest string) error {
+	tmpl := template.New(name).Funcs(templateFuncs)
+	var err error
+	if dir := cfg.TemplateOverrideDir(); dir != "" && fileExists(filepath.Join(dir, name)) {
+		tmpl, err = tmpl.ParseFiles(filepath.Join(dir, name))
+	} else {
+		tmpl, err = tmpl.ParseFS(templatesFS, "templates/"+name)
+	}
 	if err != nil {
 		return err
 	}
 
-	f, err := os.Create(filepath.Join(outputDir, "app/main.py"))
-	if err != nil {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, cfg); err != nil {
 		return err
 	}
-	defer f.Close()
+	out := buf.String()
+	if strings.HasSuffix(name, ".py.tmpl") {
+		out = normalizePython(out)
+	}
+	return os.WriteFile(dest, []byte(out), 0644)
+}
 
-	return tmpl.Execute(f, cfg)
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func generateMainPy(cfg *config.DatagenConfig, outputDir string) error {
+	return executeTemplate(cfg, "main.py.tmpl", filepath.Join(outputDir, "app/main.py"))
 }
 
 func generateAgentPy(cfg *config.DatagenConfig, outputDir string) error {
-	// Using raw string literal with proper escape for Python f-strings
-	content := "\"\"\"Agent loading and execution logic.\"\"\"\n\n" +
-		"import json\n" +
-		"import logging\n" +
-		"from dataclasses import dataclass\n" +
-		"from pathlib import Path\n" +
-		"from typing import Any, Dict, Optional\n\n" +
-		"import frontmatter\n" +
-		"from claude_agent_sdk import (\n" +
-		"    AssistantMessage,\n" +
-		"    ClaudeAgentOptions,\n" +
-		"    TextBlock,\n" +
-		"    ToolUseBlock,\n" +
-		"    query,\n" +
-		")\n\n" +
-		"from app.config import settings\n\n" +
-		"logger = logging.getLogger(__name__)\n\n\n" +
-		"def log_event(event: str, **data):\n" +
-		"    \"\"\"Emit structured JSON log for easy parsing.\"\"\"\n" +
-		"    payload = {\"event\": event, **data}\n" +
-		"    logger.info(json.dumps(payload, indent=2, ensure_ascii=False))\n\n\n" +
-		"@dataclass\n" +
-		"class AgentConfig:\n" +
-		"    \"\"\"Configuration loaded from agent.md file.\"\"\"\n\n" +
-		"    name: str\n" +
-		"    model: str\n" +
-		"    system_prompt: str\n" +
-		"    allowed_tools: list[str]\n" +
-		"    description: Optional[str] = None\n\n" +
-		"    @classmethod\n" +
-		"    def from_file(cls, path: Path) -> \"AgentConfig\":\n" +
-		"        \"\"\"Load agent configuration from markdown file.\"\"\"\n" +
-		"        if not path.exists():\n" +
-		"            raise FileNotFoundError(f\"Agent file not found: {path}\")\n\n" +
-		"        content = path.read_text(encoding=\"utf-8\")\n\n" +
-		"        try:\n" +
-		"            post = frontmatter.loads(content)\n" +
-		"            has_frontmatter = bool(post.metadata)\n" +
-		"        except Exception:\n" +
-		"            has_frontmatter = False\n" +
-		"            post = None\n\n" +
-		"        if has_frontmatter and post:\n" +
-		"            name = post.metadata.get(\"name\", path.stem)\n" +
-		"            model = post.metadata.get(\"model\", \"claude-sonnet-4-5\")\n" +
-		"            description = post.metadata.get(\"description\")\n\n" +
-		"            tools = post.metadata.get(\"tools\", [])\n" +
-		"            if isinstance(tools, str):\n" +
-		"                allowed_tools = [t.strip() for t in tools.split(\",\") if t.strip()]\n" +
-		"            else:\n" +
-		"                allowed_tools = tools if isinstance(tools, list) else []\n\n" +
-		"            system_prompt = post.content.strip()\n" +
-		"        else:\n" +
-		"            name = path.stem\n" +
-		"            model = \"claude-sonnet-4-5\"\n" +
-		"            description = None\n" +
-		"            allowed_tools = [\n" +
-		"                \"mcp__Datagen__getToolDetails\",\n" +
-		"                \"mcp__Datagen__executeTool\",\n" +
-		"            ]\n" +
-		"            system_prompt = content.strip()\n\n" +
-		"        return cls(\n" +
-		"            name=name,\n" +
-		"            model=model,\n" +
-		"            system_prompt=system_prompt,\n" +
-		"            allowed_tools=allowed_tools,\n" +
-		"            description=description,\n" +
-		"        )\n\n\n" +
-		"class AgentExecutor:\n" +
-		"    \"\"\"Execute Claude agent with MCP integration.\"\"\"\n\n" +
-		"    def __init__(self, agent_config: AgentConfig):\n" +
-		"        \"\"\"Initialize executor with agent configuration.\"\"\"\n" +
-		"        self.config = agent_config\n" +
-		"        self.model = settings.model_name or agent_config.model\n\n" +
-		"    def build_mcp_config(self) -> Dict[str, Any]:\n" +
-		"        \"\"\"Build MCP server configuration from environment.\"\"\"\n" +
-		"        mcp_servers = {}\n\n" +
-		"        if settings.datagen_api_key:\n" +
-		"            mcp_servers[\"datagen\"] = {\n" +
-		"                \"type\": \"http\",\n" +
-		"                \"url\": \"https://mcp.datagen.dev/mcp\",\n" +
-		"                \"headers\": {\"Authorization\": f\"Bearer {settings.datagen_api_key.strip()}\"},\n" +
-		"            }\n" +
-		"            log_event(\n" +
-		"                \"mcp_config\",\n" +
-		"                server=\"datagen\",\n" +
-		"                url=\"https://mcp.datagen.dev/mcp\",\n" +
-		"                authenticated=True,\n" +
-		"            )\n\n" +
-		"        return mcp_servers\n\n" +
-		"    def _build_options(self) -> ClaudeAgentOptions:\n" +
-		"        \"\"\"Compose Claude agent options.\"\"\"\n" +
-		"        return ClaudeAgentOptions(\n" +
-		"            model=self.model,\n" +
-		"            system_prompt=self.config.system_prompt,\n" +
-		"            permission_mode=settings.permission_mode,\n" +
-		"            mcp_servers=self.build_mcp_config(),\n" +
-		"            allowed_tools=self.config.allowed_tools if self.config.allowed_tools else None,\n" +
-		"        )\n\n" +
-		"    async def stream_execute(self, payload: Dict[str, Any], request_id: str, *, log_success: bool = True):\n" +
-		"        \"\"\"Async generator yielding text chunks for streaming responses.\"\"\"\n" +
-		"        log_event(\"agent_start\", request_id=request_id, agent=self.config.name)\n" +
-		"        user_message = self._format_payload(payload)\n" +
-		"        opts = self._build_options()\n\n" +
-		"        try:\n" +
-		"            async for msg in query(prompt=user_message, options=opts):\n" +
-		"                if isinstance(msg, AssistantMessage):\n" +
-		"                    for block in msg.content:\n" +
-		"                        if isinstance(block, TextBlock):\n" +
-		"                            text = block.text\n" +
-		"                            log_event(\n" +
-		"                                \"agent_chunk\",\n" +
-		"                                request_id=request_id,\n" +
-		"                                chunk=text[:500],\n" +
-		"                                truncated=len(text) > 500,\n" +
-		"                            )\n" +
-		"                            yield text\n" +
-		"                        elif isinstance(block, ToolUseBlock):\n" +
-		"                            log_event(\n" +
-		"                                \"agent_tool_use\",\n" +
-		"                                request_id=request_id,\n" +
-		"                                tool=block.name,\n" +
-		"                                input=block.input,\n" +
-		"                            )\n" +
-		"                else:\n" +
-		"                    log_event(\"agent_event\", request_id=request_id, msg_type=type(msg).__name__)\n\n" +
-		"        except Exception as e:\n" +
-		"            log_event(\n" +
-		"                \"agent_error\",\n" +
-		"                request_id=request_id,\n" +
-		"                error=str(e),\n" +
-		"                error_type=type(e).__name__,\n" +
-		"            )\n" +
-		"            raise\n" +
-		"        finally:\n" +
-		"            if log_success:\n" +
-		"                log_event(\"agent_success\", request_id=request_id, result_length=None)\n\n" +
-		"    async def execute(self, payload: Dict[str, Any], request_id: str) -> str:\n" +
-		"        \"\"\"Execute agent and return concatenated text (non-streaming).\"\"\"\n" +
-		"        collected_text: list[str] = []\n" +
-		"        async for chunk in self.stream_execute(payload, request_id, log_success=False):\n" +
-		"            collected_text.append(chunk)\n\n" +
-		"        result = \"\".join(collected_text)\n" +
-		"        log_event(\"agent_success\", request_id=request_id, result_length=len(result))\n" +
-		"        return result\n\n" +
-		"    def _format_payload(self, payload: Dict[str, Any]) -> str:\n" +
-		"        \"\"\"Format payload as JSON for the agent.\"\"\"\n" +
-		"        return f\"\"\"Here is the input data to process:\n\n" +
-		"```json\n" +
-		"{json.dumps(payload, indent=2, ensure_ascii=False)}\n" +
-		"```\n\n" +
-		"Process this data according to your system prompt instructions.\"\"\"\n\n\n" +
-		"# Agent executors will be loaded per service\n" +
-		"agent_executors = {}\n\n\n" +
-		"def load_agent(name: str, prompt_path: str) -> AgentExecutor:\n" +
-		"    \"\"\"Load an agent from a prompt file.\"\"\"\n" +
-		"    from pathlib import Path\n" +
-		"    base_dir = Path(__file__).resolve().parent.parent\n" +
-		"    agent_file = base_dir / prompt_path\n" +
-		"    agent_config = AgentConfig.from_file(agent_file)\n" +
-		"    executor = AgentExecutor(agent_config)\n" +
-		"    log_event(\"agent_loaded\", name=name, model=executor.model, file=str(agent_file))\n" +
-		"    return executor\n"
-
-	return os.WriteFile(filepath.Join(outputDir, "app/agent.py"), []byte(content), 0644)
+	return executeTemplate(cfg, "agent.py.tmpl", filepath.Join(outputDir, "app/agent.py"))
 }
 
 func generateConfigPy(cfg *config.DatagenConfig, outputDir string) error {
-	tmpl, err := template.New("config.py.tmpl").Funcs(templateFuncs).ParseFS(templatesFS, "templates/config.py.tmpl")
-	if err != nil {
-		return err
-	}
-
-	f, err := os.Create(filepath.Join(outputDir, "app/config.py"))
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	return tmpl.Execute(f, cfg)
+	return executeTemplate(cfg, "config.py.tmpl", filepath.Join(outputDir, "app/config.py"))
 }
 
 func generateModelsPy(cfg *config.DatagenConfig, outputDir string) error {
-	tmpl, err := template.New("models.py.tmpl").Funcs(templateFuncs).ParseFS(templatesFS, "templates/models.py.tmpl")
-	if err != nil {
-		return err
-	}
-
-	f, err := os.Create(filepath.Join(outputDir, "app/models.py"))
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	return tmpl.Execute(f, cfg)
+	return executeTemplate(cfg, "models.py.tmpl", filepath.Join(outputDir, "app/models.py"))
 }
 
 func generateInitPy(outputDir string) error {
@@ -309,164 +422,1168 @@ func generateInitPy(outputDir string) error {
 	return os.WriteFile(filepath.Join(outputDir, "app/__init__.py"), []byte(content), 0644)
 }
 
-func generateRequirementsTxt(outputDir string) error {
-	content := `# FastAPI and server
-fastapi~=0.115.0
-uvicorn[standard]~=0.32.0
+func generateMetricsPy(outputDir string) error {
+	content := `"""In-process load gauges exposed at /metrics for autoscalers."""
+
+from collections import defaultdict
+
+# Agent runs currently executing, per service
+inflight_executions: dict[str, int] = defaultdict(int)
+
+# Webhook payloads accepted but not yet picked up by a background task, per service
+queue_depth: dict[str, int] = defaultdict(int)
 
-# Anthropic and agent SDK
-anthropic~=0.39.0
-claude-agent-sdk~=0.1.0
+# Finished agent runs by (service, prompt variant, outcome)
+agent_runs: dict[tuple[str, str, str], int] = defaultdict(int)
 
-# DataGen SDK
-datagen-python-sdk~=0.1.0
 
-# HTTP client
-httpx~=0.27.0
+def mark_queued(service: str) -> None:
+    """Record a webhook payload waiting for a background task."""
+    queue_depth[service] += 1
 
-# Data validation
-pydantic~=2.10.0
-pydantic-settings~=2.6.0
 
-# Markdown parsing
-python-frontmatter~=1.1.0
-pyyaml~=6.0.2
+def mark_dequeued(service: str) -> None:
+    """Record a queued webhook payload starting to execute."""
+    queue_depth[service] = max(0, queue_depth[service] - 1)
+
+
+def total_inflight() -> int:
+    """Agent runs executing across all services."""
+    return sum(inflight_executions.values())
+
+
+def render_prometheus(services: list[str], target_concurrency: int) -> str:
+    """Render gauges in the Prometheus text exposition format."""
+    lines = [
+        "# HELP datagen_inflight_executions Agent executions currently running.",
+        "# TYPE datagen_inflight_executions gauge",
+    ]
+    for service in services:
+        lines.append(f'datagen_inflight_executions{{service="{service}"}} {inflight_executions[service]}')
+    lines += [
+        "# HELP datagen_queue_depth Webhook payloads waiting for a background task.",
+        "# TYPE datagen_queue_depth gauge",
+    ]
+    for service in services:
+        lines.append(f'datagen_queue_depth{{service="{service}"}} {queue_depth[service]}')
+    lines += [
+        "# HELP datagen_target_concurrency Per-replica in-flight target (0 = unbounded).",
+        "# TYPE datagen_target_concurrency gauge",
+        f"datagen_target_concurrency {target_concurrency}",
+        "# HELP datagen_agent_runs_total Finished agent runs by prompt variant and outcome.",
+        "# TYPE datagen_agent_runs_total counter",
+    ]
+    for (service, variant, outcome), count in sorted(agent_runs.items()):
+        lines.append(
+            f'datagen_agent_runs_total{{service="{service}",variant="{variant}",outcome="{outcome}"}} {count}'
+        )
+    return "\n".join(lines) + "\n"
 `
-	return os.WriteFile(filepath.Join(outputDir, "requirements.txt"), []byte(content), 0644)
+	return os.WriteFile(filepath.Join(outputDir, "app/metrics.py"), []byte(content), 0644)
 }
 
-func generateDockerfile(outputDir string) error {
-	content := `# Use Python 3.13 slim image
-FROM python:3.13-slim
+func generateLifecyclePy(outputDir string) error {
+	content := `"""Shutdown draining for background agent tasks."""
+
+import asyncio
+import time
 
-# Create a non-root user with home directory
-RUN groupadd -r appuser && useradd -r -g appuser -m -d /home/appuser appuser
+# Set once shutdown begins; webhook handlers reject new work while draining
+draining = False
 
-# Set working directory
-WORKDIR /app
+# Background tasks accepted but not yet finished: request_id -> service
+pending: dict[str, str] = {}
 
-# Ensure appuser can write to home directory
-RUN mkdir -p /home/appuser && chown -R appuser:appuser /home/appuser
 
-# Copy requirements first for better caching
-COPY requirements.txt .
+def start(request_id: str, service: str) -> None:
+    """Record a background task as accepted."""
+    pending[request_id] = service
 
-# Install dependencies
-RUN pip install --no-cache-dir -r requirements.txt
 
-# Copy application code
-COPY . .
+def finish(request_id: str) -> None:
+    """Record a background task as finished (successfully or not)."""
+    pending.pop(request_id, None)
 
-# Change ownership to non-root user
-RUN chown -R appuser:appuser /app
 
-# Switch to non-root user
-USER appuser
+async def drain(timeout: float) -> dict[str, str]:
+    """Stop accepting work and wait up to timeout seconds for pending tasks.
 
-# Expose port (Railway will set PORT env var)
-EXPOSE 8000
+    Returns the tasks still pending when the timeout expired.
+    """
+    global draining
+    draining = True
 
-# Start the application using PORT environment variable
-CMD uvicorn app.main:app --host 0.0.0.0 --port ${PORT:-8000}
+    deadline = time.monotonic() + timeout
+    while pending and time.monotonic() < deadline:
+        await asyncio.sleep(0.25)
+    return dict(pending)
 `
-	return os.WriteFile(filepath.Join(outputDir, "Dockerfile"), []byte(content), 0644)
+	return os.WriteFile(filepath.Join(outputDir, "app/lifecycle.py"), []byte(content), 0644)
 }
 
-func generateEnvExample(cfg *config.DatagenConfig, outputDir string) error {
-	content := fmt.Sprintf(`# Required
-%s=your-anthropic-api-key-here
-`, cfg.ClaudeAPIKeyEnv)
+func generateCanaryPy(outputDir string) error {
+	content := `"""Canary prompt rollout: send a share of one service's requests to a new prompt.
+
+Configured with CANARY_PROMPT (prompt file), CANARY_TRAFFIC (percent of requests),
+and CANARY_SERVICE (required when more than one service is defined). Outcomes per
+variant are exported as datagen_agent_runs_total at /metrics.
+"""
+
+import random
+
+from app.agent import AgentExecutor, load_agent, log_event
+from app.config import settings
+
+# Canary executors keyed by service name
+canary_executors: dict[str, AgentExecutor] = {}
+
+
+def load(executors: dict[str, AgentExecutor]) -> None:
+    """Load the canary prompt for the configured service, if any."""
+    if not settings.canary_prompt or settings.canary_traffic <= 0:
+        return
+
+    service = settings.canary_service
+    if not service:
+        if len(executors) != 1:
+            log_event("canary_disabled", reason="CANARY_SERVICE is required with multiple services")
+            return
+        service = next(iter(executors))
+    if service not in executors:
+        log_event("canary_disabled", reason="unknown service", service=service)
+        return
+
+    executor = load_agent(service, settings.canary_prompt)
+    executor.variant = "canary"
+    canary_executors[service] = executor
+    log_event(
+        "canary_enabled",
+        service=service,
+        prompt=settings.canary_prompt,
+        traffic=settings.canary_traffic,
+    )
+
+
+def pick(service: str, executors: dict[str, AgentExecutor]) -> AgentExecutor:
+    """Return the canary executor for a CANARY_TRAFFIC share of requests, else the stable one."""
+    canary = canary_executors.get(service)
+    if canary is not None and random.uniform(0, 100) < settings.canary_traffic:
+        return canary
+    return executors[service]
+`
+	return os.WriteFile(filepath.Join(outputDir, "app/canary.py"), []byte(content), 0644)
+}
+
+func generateLocalesPy(outputDir string) error {
+	content := `"""Per-locale prompts: serve localized agent behavior from one service.
+
+Services with prompts.<locale> entries in datagen.toml load one executor per
+locale. A request's locale comes from its "locale" input field, falling back to
+the Accept-Language header; anything unmatched uses the service's default prompt.
+"""
+
+from typing import Any, Optional
+
+from app.agent import AgentExecutor, load_agent, log_event
+
+# Localized executors keyed by service name, then lowercase language tag
+locale_executors: dict[str, dict[str, AgentExecutor]] = {}
+
+
+def load(service: str, prompts: dict[str, str]) -> None:
+    """Load one executor per locale prompt for service."""
+    executors = {}
+    for locale, prompt_path in prompts.items():
+        executors[locale.lower()] = load_agent(service, prompt_path)
+    locale_executors[service] = executors
+    log_event("locales_loaded", service=service, locales=sorted(executors))
+
+
+def candidates(payload: dict[str, Any], accept_language: Optional[str]) -> list[str]:
+    """Return requested language tags in preference order, each followed by its base language."""
+    requested = payload.get("locale") or accept_language or ""
+    tags: list[str] = []
+    for part in str(requested).split(","):
+        tag = part.split(";")[0].strip().lower().replace("_", "-")
+        if not tag or tag == "*":
+            continue
+        for candidate in (tag, tag.split("-")[0]):
+            if candidate not in tags:
+                tags.append(candidate)
+    return tags
+
+
+def pick(
+    service: str,
+    default: AgentExecutor,
+    payload: dict[str, Any],
+    accept_language: Optional[str],
+) -> AgentExecutor:
+    """Return the executor for the best matching locale, else default."""
+    localized = locale_executors.get(service)
+    if not localized:
+        return default
+    for tag in candidates(payload, accept_language):
+        if tag in localized:
+            return localized[tag]
+    return default
+`
+	return os.WriteFile(filepath.Join(outputDir, "app/locales.py"), []byte(content), 0644)
+}
+
+func generateStoresPy(outputDir string) error {
+	content := `"""Shared state for rate limits and webhook idempotency.
+
+With STATE_STORE=memory (the default) counters and seen keys live in this
+process, which is only correct for a single worker on a single replica. With
+STATE_STORE=redis and REDIS_URL set, every worker and replica shares them.
+"""
+
+import time
+from typing import Optional, Protocol
+
+from app.agent import log_event
+from app.config import settings
+
+
+class Store(Protocol):
+    async def hit(self, key: str, window: int) -> int:
+        """Count a hit in the current window of window seconds and return the total."""
+
+    async def claim(self, key: str, ttl: int) -> bool:
+        """Record key for ttl seconds; return False if it was already recorded."""
+
+
+class MemoryStore:
+    """Per-process store; state is lost on restart and not shared between workers."""
+
+    def __init__(self) -> None:
+        self._hits: dict[str, tuple[int, int]] = {}
+        self._claims: dict[str, float] = {}
+
+    async def hit(self, key: str, window: int) -> int:
+        bucket = int(time.time()) // window
+        start, count = self._hits.get(key, (bucket, 0))
+        count = count + 1 if start == bucket else 1
+        self._hits[key] = (bucket, count)
+        return count
+
+    async def claim(self, key: str, ttl: int) -> bool:
+        now = time.monotonic()
+        if len(self._claims) > 10000:
+            self._claims = {k: exp for k, exp in self._claims.items() if exp > now}
+        expires = self._claims.get(key)
+        if expires is not None and expires > now:
+            return False
+        self._claims[key] = now + ttl
+        return True
+
+
+class RedisStore:
+    """Store shared by every worker and replica through Redis."""
+
+    def __init__(self, url: str) -> None:
+        import redis.asyncio as redis
+
+        self._redis = redis.from_url(url)
+
+    async def hit(self, key: str, window: int) -> int:
+        bucket_key = f"datagen:rate:{key}:{int(time.time()) // window}"
+        async with self._redis.pipeline(transaction=True) as pipe:
+            pipe.incr(bucket_key)
+            pipe.expire(bucket_key, window)
+            count, _ = await pipe.execute()
+        return int(count)
+
+    async def claim(self, key: str, ttl: int) -> bool:
+        return bool(await self._redis.set(f"datagen:idem:{key}", "1", nx=True, ex=ttl))
+
+
+_store: Optional[Store] = None
+
+
+def get() -> Store:
+    """Return the configured store, creating it on first use."""
+    global _store
+    if _store is None:
+        if settings.state_store == "redis" and settings.redis_url:
+            _store = RedisStore(settings.redis_url)
+        else:
+            if settings.state_store == "redis":
+                log_event("state_store_fallback", reason="REDIS_URL is not set", backend="memory")
+            _store = MemoryStore()
+        log_event("state_store", backend=type(_store).__name__)
+    return _store
+
+
+async def check_rate_limit(service: str, client: str, rpm: int) -> Optional[int]:
+    """Count a request against client's per-minute limit; return seconds to wait when over it."""
+    count = await get().hit(f"{service}:{client}", 60)
+    if count > rpm:
+        return 60 - int(time.time()) % 60
+    return None
+
+
+async def claim_delivery(service: str, key: Optional[str], ttl: int = 86400) -> bool:
+    """Return False when a webhook delivery with this idempotency key was already accepted."""
+    if not key:
+        return True
+    return await get().claim(f"{service}:{key}", ttl)
+`
+	return os.WriteFile(filepath.Join(outputDir, "app/stores.py"), []byte(content), 0644)
+}
+
+func generatePipelinePy(outputDir string) error {
+	content := `"""Pipeline services: one endpoint running several agents in order.
+
+Each [[service.steps]] entry in datagen.toml is loaded as its own executor,
+keyed "<service>.<step>". Steps run one after another; a step marked parallel
+runs together with the step before it. A step's payload is built from its input
+mapping ("input.<field>" or "<step>.<field>"), or is the previous stage's result.
+"""
+
+import asyncio
+import json
+import re
+from typing import Any
+
+from app.agent import AgentExecutor, log_event
+
+_FENCED_JSON = re.compile(r"` + "```" + `(?:json)?\s*(.*?)` + "```" + `", re.DOTALL)
+
+
+class StepError(Exception):
+    """A pipeline step failed; the step name is kept for logging."""
+
+    def __init__(self, step: str, error: Exception):
+        super().__init__(f"step {step} failed: {error}")
+        self.step = step
+        self.error = error
+
+
+def parse_result(text: str) -> Any:
+    """Return a step's reply as JSON when it is (or fences) a JSON value, else the text."""
+    candidates = [text.strip()] + [m.strip() for m in _FENCED_JSON.findall(text)]
+    for candidate in candidates:
+        try:
+            return json.loads(candidate)
+        except ValueError:
+            continue
+    return text
 
+
+def resolve(source: str, sources: dict[str, Any]) -> Any:
+    """Look up a dotted path such as "input.url" or "extract.company.name"."""
+    head, _, rest = source.partition(".")
+    value = sources.get(head)
+    for key in rest.split(".") if rest else []:
+        if isinstance(value, dict):
+            value = value.get(key)
+        elif isinstance(value, list) and key.isdigit() and int(key) < len(value):
+            value = value[int(key)]
+        else:
+            return None
+    return value
+
+
+def _step_payload(step: dict[str, Any], sources: dict[str, Any], previous: Any) -> dict[str, Any]:
+    if step.get("input"):
+        return {field: resolve(source, sources) for field, source in step["input"].items()}
+    if isinstance(previous, dict):
+        return previous
+    return {"input": previous}
+
+
+async def _run_step(
+    executors: dict[str, AgentExecutor],
+    service: str,
+    step: dict[str, Any],
+    payload: dict[str, Any],
+    request_id: str,
+) -> Any:
+    name = step["name"]
+    log_event("pipeline_step_started", request_id=request_id, service=service, step=name)
+    try:
+        text = await executors[f"{service}.{name}"].execute(payload, request_id)
+    except Exception as e:
+        raise StepError(name, e) from e
+    log_event("pipeline_step_completed", request_id=request_id, service=service, step=name)
+    return parse_result(text)
+
+
+async def run(
+    executors: dict[str, AgentExecutor],
+    service: str,
+    stages: list[list[dict[str, Any]]],
+    payload: dict[str, Any],
+    request_id: str,
+) -> tuple[dict[str, Any], Any]:
+    """Run stages in order, the steps of a stage concurrently.
+
+    Returns every step's result by step name, and the final stage's result (a
+    dict by step name when the final stage has several steps).
+    """
+    sources: dict[str, Any] = {"input": payload}
+    results: dict[str, Any] = {}
+    previous: Any = payload
+    for stage in stages:
+        outputs = await asyncio.gather(
+            *[
+                _run_step(executors, service, step, _step_payload(step, sources, previous), request_id)
+                for step in stage
+            ]
+        )
+        for step, output in zip(stage, outputs):
+            sources[step["name"]] = output
+            results[step["name"]] = output
+        if len(stage) == 1:
+            previous = outputs[0]
+        else:
+            previous = {step["name"]: output for step, output in zip(stage, outputs)}
+    return results, previous
+`
+	return os.WriteFile(filepath.Join(outputDir, "app/pipeline.py"), []byte(content), 0644)
+}
+
+func generateStreamsPy(outputDir string) error {
+	content := `"""Streaming services: event framing and resumable streams.
+
+An agent run streams events: {"type": "text"}, {"type": "tool_use"}, then
+{"type": "done"} or {"type": "error"}. Each event has the ID
+"<stream_id>:<seq>". The run continues in the background when the client
+disconnects, and its events are kept for REPLAY_TTL seconds after it ends, so a
+client can reconnect with a Last-Event-ID header and receive what it missed.
+Streams are kept per process: behind several replicas, route a reconnect to the
+same replica (sticky sessions) or start a new request.
+"""
+
+import asyncio
+import json
+import time
+import uuid
+from typing import Any, AsyncIterator
+
+from fastapi import WebSocket, WebSocketDisconnect
+
+from app import lifecycle
+from app.agent import AgentExecutor, log_event
+
+# Seconds a finished stream can still be resumed
+REPLAY_TTL = 300
+
+# Streams kept at most; the oldest finished ones are dropped first
+MAX_STREAMS = 1000
+
+MEDIA_TYPES = {"sse": "text/event-stream", "ndjson": "application/x-ndjson"}
+
+
+class _Stream:
+    def __init__(self) -> None:
+        self.events: list[dict[str, Any]] = []
+        self.done = False
+        self.finished_at = 0.0
+        self.changed = asyncio.Condition()
+
+    async def add(self, event: dict[str, Any], *, last: bool = False) -> None:
+        async with self.changed:
+            self.events.append(event)
+            if last:
+                self.done = True
+                self.finished_at = time.monotonic()
+            self.changed.notify_all()
+
+
+_streams: dict[str, _Stream] = {}
+_tasks: set[asyncio.Task] = set()
+
+
+def _prune() -> None:
+    now = time.monotonic()
+    for stream_id, stream in list(_streams.items()):
+        if stream.done and now - stream.finished_at > REPLAY_TTL:
+            del _streams[stream_id]
+    for stream_id, stream in list(_streams.items()):
+        if len(_streams) < MAX_STREAMS:
+            break
+        if stream.done:
+            del _streams[stream_id]
+
+
+async def _run(stream: _Stream, service: str, executor: AgentExecutor, payload: dict[str, Any], request_id: str) -> None:
+    lifecycle.start(request_id, service)
+    try:
+        async for event in executor.stream_events(payload, request_id):
+            await stream.add(event)
+        await stream.add({"type": "done"}, last=True)
+    except Exception as e:
+        log_event("streaming_error", request_id=request_id, service=service, error=str(e))
+        await stream.add({"type": "error", "error": str(e)}, last=True)
+    finally:
+        lifecycle.finish(request_id)
+
+
+def start(service: str, executor: AgentExecutor, payload: dict[str, Any], request_id: str) -> str:
+    """Start an agent run in the background and return its stream ID."""
+    _prune()
+    stream = _Stream()
+    _streams[request_id] = stream
+    task = asyncio.create_task(_run(stream, service, executor, payload, request_id))
+    _tasks.add(task)
+    task.add_done_callback(_tasks.discard)
+    return request_id
+
+
+def exists(stream_id: str) -> bool:
+    """Report whether a stream can still be followed."""
+    return stream_id in _streams
+
+
+def parse_last_event_id(value: str | None) -> tuple[str, int] | None:
+    """Split a Last-Event-ID header into (stream_id, seq), or None."""
+    stream_id, sep, seq = (value or "").strip().rpartition(":")
+    if not sep or not stream_id or not seq.isdigit():
+        return None
+    return stream_id, int(seq)
+
+
+async def follow(stream_id: str, after: int = 0) -> AsyncIterator[tuple[int, dict[str, Any]]]:
+    """Yield (seq, event) for each event after seq "after" until the run ends."""
+    stream = _streams[stream_id]
+    seq = after
+    while True:
+        async with stream.changed:
+            await stream.changed.wait_for(lambda: stream.done or len(stream.events) > seq)
+            pending = stream.events[seq:]
+            done = stream.done
+        for event in pending:
+            seq += 1
+            yield seq, event
+        if done and seq >= len(stream.events):
+            return
+
+
+def frame(framing: str, fmt: str, stream_id: str, seq: int, event: dict[str, Any]) -> str:
+    """Write one event for the wire; "" when the format leaves it out.
+
+    ndjson writes every event as a JSON line. sse with the json format writes
+    every event as JSON under its type; the default format writes text chunks
+    as plain data and leaves tool calls out.
+    """
+    event_id = f"{stream_id}:{seq}"
+    if framing == "ndjson":
+        return json.dumps({"id": event_id, **event}, ensure_ascii=False) + "\n"
+    if fmt == "json":
+        return f"id: {event_id}\nevent: {event['type']}\ndata: {json.dumps(event, ensure_ascii=False)}\n\n"
+    if event["type"] == "text":
+        data = "\n".join(f"data: {line}" for line in event["text"].split("\n"))
+        return f"id: {event_id}\n{data}\n\n"
+    if event["type"] == "done":
+        return f"id: {event_id}\nevent: done\ndata: [DONE]\n\n"
+    if event["type"] == "error":
+        return f"id: {event_id}\nevent: error\ndata: {event['error']}\n\n"
+    return ""
+
+
+async def serve_websocket(websocket: WebSocket, service: str, pick, validate) -> None:
+    """Serve a stream over an accepted WebSocket.
+
+    The client's first message is {"payload": {...}} to start a run, or
+    {"last_event_id": "..."} to resume one. Events are sent as JSON messages
+    with an "id", and the socket is closed after the done or error event.
+    pick(payload) returns the executor; validate(payload) returns the payload
+    as a dict or raises ValueError.
+    """
+    try:
+        message = await websocket.receive_json()
+        resume = parse_last_event_id(message.get("last_event_id")) if isinstance(message, dict) else None
+        if resume is not None:
+            stream_id, after = resume
+            if not exists(stream_id):
+                await websocket.send_json({"type": "error", "error": "stream expired; start a new request"})
+                await websocket.close(code=1008)
+                return
+        else:
+            try:
+                payload = validate(message.get("payload", {}) if isinstance(message, dict) else {})
+            except ValueError as e:
+                await websocket.send_json({"type": "error", "error": str(e)})
+                await websocket.close(code=1003)
+                return
+            request_id = str(uuid.uuid4())
+            stream_id, after = start(service, pick(payload), payload, request_id), 0
+        async for seq, event in follow(stream_id, after):
+            await websocket.send_json({"id": f"{stream_id}:{seq}", **event})
+        await websocket.close()
+    except WebSocketDisconnect:
+        pass
+`
+	return os.WriteFile(filepath.Join(outputDir, "app/streams.py"), []byte(content), 0644)
+}
+
+func generateCapturePy(outputDir string) error {
+	content := `"""Request capture: record sanitized request/response pairs for datagen replay.
+
+Enabled by [capture] in datagen.toml. Each POST to a service is written as one
+JSON file under CAPTURE_DIR/<date>/ and, with CAPTURE_S3_BUCKET set, uploaded
+to S3. Auth, cookie and signature headers are dropped, and payload fields that
+look secret (or are listed in CAPTURE_REDACT) are masked before anything is
+written. CAPTURE_ENABLED=false turns recording off without a rebuild.
+"""
+
+import asyncio
+import json
+import re
+import time
+from datetime import datetime, timezone
+from pathlib import Path
+from typing import Any
+
+from fastapi import Request
+
+from app.agent import log_event
+from app.config import settings
+
+REDACTED = "[REDACTED]"
+
+# Headers worth keeping to replay a request; everything else is dropped
+_KEEP_HEADERS = {"content-type", "accept", "accept-language", "user-agent", "x-github-event", "x-request-id"}
+
+_SECRET_FIELD = re.compile(r"(pass(word)?|secret|token|api[_-]?key|authorization|credential|signature|ssn|card[_-]?number|cvv)", re.I)
+_SECRET_VALUE = re.compile(r"^(sk-[A-Za-z0-9_-]{8,}|Bearer\s+\S+|gh[pousr]_[A-Za-z0-9]{20,}|xox[abpr]-\S+|AKIA[0-9A-Z]{16})$")
+
+# Paths that are never recorded
+_SKIP_PATHS = {"/health", "/ready", "/metrics", "/version", "/playground", "/docs", "/openapi.json", "/redoc"}
+
+
+def _redact_fields() -> set[str]:
+    return {f.strip().lower() for f in settings.capture_redact.split(",") if f.strip()}
+
+
+def sanitize(value: Any, extra: set[str] | None = None) -> Any:
+    """Mask secret-looking fields and values in a decoded JSON payload."""
+    extra = extra if extra is not None else _redact_fields()
+    if isinstance(value, dict):
+        return {
+            k: REDACTED if _SECRET_FIELD.search(k) or k.lower() in extra else sanitize(v, extra)
+            for k, v in value.items()
+        }
+    if isinstance(value, list):
+        return [sanitize(v, extra) for v in value]
+    if isinstance(value, str) and _SECRET_VALUE.match(value.strip()):
+        return REDACTED
+    return value
+
+
+def _decode(body: bytes) -> Any:
+    text = body.decode("utf-8", errors="replace")
+    try:
+        return sanitize(json.loads(text))
+    except ValueError:
+        return text
+
+
+def _write(record: dict[str, Any]) -> None:
+    day = record["recorded_at"][:10]
+    slug = record["request"]["path"].strip("/").replace("/", "_") or "root"
+    name = f"{record['recorded_at'][11:19].replace(':', '')}-{slug}-{record['request_id']}.json"
+    data = json.dumps(record, indent=2, ensure_ascii=False)
+    path = Path(settings.capture_dir) / day / name
+    path.parent.mkdir(parents=True, exist_ok=True)
+    path.write_text(data + "\n")
+    if settings.capture_s3_bucket:
+        import boto3
+
+        key = f"{settings.capture_s3_prefix.rstrip('/')}/{day}/{name}".lstrip("/")
+        boto3.client("s3").put_object(
+            Bucket=settings.capture_s3_bucket, Key=key, Body=data.encode(), ContentType="application/json"
+        )
+
+
+async def _save(record: dict[str, Any]) -> None:
+    try:
+        await asyncio.to_thread(_write, record)
+    except Exception as e:
+        log_event("capture_failed", request_id=record["request_id"], error=str(e))
+
+
+async def record(request: Request, call_next):
+    """HTTP middleware: pass the request on and record it with its response."""
+    if not settings.capture_enabled or request.method != "POST" or request.url.path in _SKIP_PATHS:
+        return await call_next(request)
+
+    body = await request.body()
+    started = time.monotonic()
+    response = await call_next(request)
+    original = response.body_iterator
+
+    async def tee():
+        chunks: list[bytes] = []
+        try:
+            async for chunk in original:
+                chunks.append(chunk if isinstance(chunk, bytes) else chunk.encode())
+                yield chunk
+        finally:
+            await _save(
+                {
+                    "version": 1,
+                    "request_id": getattr(request.state, "request_id", None) or response.headers.get("x-request-id", "unknown"),
+                    "recorded_at": datetime.now(timezone.utc).isoformat(timespec="seconds"),
+                    "request": {
+                        "method": request.method,
+                        "path": request.url.path,
+                        "query": request.url.query,
+                        "headers": {k: v for k, v in request.headers.items() if k.lower() in _KEEP_HEADERS},
+                        "body": _decode(body),
+                    },
+                    "response": {
+                        "status": response.status_code,
+                        "content_type": response.headers.get("content-type", ""),
+                        "body": _decode(b"".join(chunks)),
+                        "duration_ms": round((time.monotonic() - started) * 1000),
+                    },
+                }
+            )
+
+    response.body_iterator = tee()
+    return response
+`
+	return os.WriteFile(filepath.Join(outputDir, "app/capture.py"), []byte(content), 0644)
+}
+
+func generateStreamClientPy(cfg *config.DatagenConfig, outputDir string) error {
+	if !cfg.UsesStreaming() {
+		return nil
+	}
+	content := `"""Client for this app's streaming services that survives dropped connections.
+
+    python scripts/stream_client.py http://localhost:8000/api/chat '{"message": "hi"}'
+
+Reads SSE or NDJSON and prints each event as a JSON line. When the connection
+drops before the done or error event, it reconnects with the ID of the last
+event it received (Last-Event-ID) and the run continues where it left off.
+"""
+
+import argparse
+import json
+import sys
+import time
+from typing import Any, Iterator
+
+import httpx
+
+
+def _sse_events(lines: Iterator[str]) -> Iterator[tuple[str | None, str, str]]:
+    event_id, event_type, data = None, "message", []
+    for line in lines:
+        if line == "":
+            if data:
+                yield event_id, event_type, "\n".join(data)
+            event_type, data = "message", []
+            continue
+        if line.startswith(":"):
+            continue
+        field, _, value = line.partition(":")
+        value = value[1:] if value.startswith(" ") else value
+        if field == "id":
+            event_id = value
+        elif field == "event":
+            event_type = value
+        elif field == "data":
+            data.append(value)
+
+
+def _sse_event(event_type: str, data: str) -> dict[str, Any]:
+    try:
+        parsed = json.loads(data)
+        if isinstance(parsed, dict) and "type" in parsed:
+            return parsed
+    except ValueError:
+        pass
+    if event_type == "done":
+        return {"type": "done"}
+    if event_type == "error":
+        return {"type": "error", "error": data}
+    return {"type": "text", "text": data}
+
+
+def _events(response: httpx.Response) -> Iterator[tuple[str | None, dict[str, Any]]]:
+    if response.headers.get("content-type", "").startswith("application/x-ndjson"):
+        for line in response.iter_lines():
+            if line.strip():
+                event = json.loads(line)
+                yield event.pop("id", None), event
+        return
+    for event_id, event_type, data in _sse_events(response.iter_lines()):
+        yield event_id, _sse_event(event_type, data)
+
+
+def stream(
+    url: str,
+    payload: dict[str, Any],
+    headers: dict[str, str] | None = None,
+    retries: int = 5,
+    backoff: float = 1.0,
+) -> Iterator[dict[str, Any]]:
+    """Yield a streaming service's events, resuming after dropped connections."""
+    headers = dict(headers or {})
+    last_id = None
+    failures = 0
+    with httpx.Client(timeout=httpx.Timeout(10.0, read=None)) as client:
+        while True:
+            if last_id:
+                headers["Last-Event-ID"] = last_id
+            try:
+                with client.stream("POST", url, json=payload, headers=headers) as response:
+                    if response.status_code == 410:
+                        raise RuntimeError("the stream expired before it could be resumed")
+                    response.raise_for_status()
+                    stream_id = response.headers.get("x-stream-id")
+                    if last_id is None and stream_id:
+                        last_id = f"{stream_id}:0"
+                    for event_id, event in _events(response):
+                        if event_id:
+                            last_id = event_id
+                        failures = 0
+                        yield event
+                        if event.get("type") in ("done", "error"):
+                            return
+            except httpx.TransportError as e:
+                error = e
+            else:
+                error = ConnectionError("stream ended before its done event")
+            failures += 1
+            if last_id is None or failures > retries:
+                raise error
+            print(f"reconnecting after {last_id}: {error}", file=sys.stderr)
+            time.sleep(backoff * failures)
+
+
+def main() -> None:
+    parser = argparse.ArgumentParser(description=__doc__.splitlines()[0])
+    parser.add_argument("url")
+    parser.add_argument("payload", help="request body as JSON")
+    parser.add_argument("-H", "--header", action="append", default=[], help="extra header, Name: value")
+    args = parser.parse_args()
+    headers = dict(h.split(":", 1) for h in args.header)
+    headers = {name.strip(): value.strip() for name, value in headers.items()}
+    for event in stream(args.url, json.loads(args.payload), headers):
+        print(json.dumps(event, ensure_ascii=False), flush=True)
+
+
+if __name__ == "__main__":
+    main()
+`
+	return os.WriteFile(filepath.Join(outputDir, "scripts/stream_client.py"), []byte(content), 0644)
+}
+
+func generatePlaygroundHTML(cfg *config.DatagenConfig, outputDir string) error {
+	if !cfg.UsesPlayground() {
+		return nil
+	}
+	content, err := templatesFS.ReadFile("templates/playground.html")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(outputDir, "app", "static"), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outputDir, "app/static/playground.html"), content, 0644)
+}
+
+// serverCommand returns the shell command that starts the generated app on port.
+// The graceful timeout bounds how long in-flight requests and their background
+// tasks may run after SIGTERM before they are cancelled and logged as abandoned.
+func serverCommand(cfg *config.DatagenConfig, port string) string {
+	workers := ""
+	if n := cfg.Workers(); n > 1 {
+		workers = " --workers " + strconv.Itoa(n)
+	}
+	if cfg.UsesHTTP2() {
+		return "hypercorn app.main:app --bind 0.0.0.0:" + port + workers + " --graceful-timeout ${SHUTDOWN_TIMEOUT:-25}"
+	}
+	return "uvicorn app.main:app --host 0.0.0.0 --port " + port + workers + " --timeout-graceful-shutdown ${SHUTDOWN_TIMEOUT:-25}"
+}
+
+func generateEnvExample(cfg *config.DatagenConfig, outputDir string) error {
+	// Keys no service needs move to the optional section: the DataGen key when
+	// no service uses DataGen tools, the Anthropic key when every service
+	// calls Claude through Bedrock or Vertex
+	required, optional := "", ""
+	claudeKey := fmt.Sprintf("%s=your-anthropic-api-key-here\n", cfg.ClaudeAPIKeyEnv)
+	if cfg.RequiresClaudeAPIKey() {
+		required += claudeKey
+	} else {
+		optional += claudeKey
+	}
+	datagenKey := fmt.Sprintf("%s=your-datagen-api-key-here\n", cfg.DatagenAPIKeyEnv)
 	if cfg.RequiresDatagenAPIKey() {
-		content += fmt.Sprintf("%s=your-datagen-api-key-here\n", cfg.DatagenAPIKeyEnv)
+		required += datagenKey
+	} else {
+		optional += datagenKey
 	}
 
-	content += fmt.Sprintf(`
-# Optional
+	content := ""
+	if required != "" {
+		content = "# Required\n" + required + "\n"
+	}
+	content += fmt.Sprintf(`# Optional
 %s
-MODEL_NAME=claude-sonnet-4-5
+MODEL_NAME=%s
 LOG_LEVEL=INFO
 PORT=8000
 PERMISSION_MODE=bypassPermissions
-`, func() string {
-		if cfg.RequiresDatagenAPIKey() {
-			return ""
+SHUTDOWN_TIMEOUT=25
+CLAUDE_MAX_RETRIES=%d
+CLAUDE_TIMEOUT=%d
+CLAUDE_MAX_CONCURRENCY=%d
+
+STATE_STORE=%s
+%s
+# Test mode: replace Claude with a deterministic fake agent (no API keys needed)
+# AGENT_FAKE=1
+# AGENT_FAKE_RESPONSE=canned reply
+`, optional, models.Default, cfg.ClaudeMaxRetries(), cfg.ClaudeTimeout(), cfg.ClaudeMaxConcurrency(), cfg.StateStore(), func() string {
+		if cfg.UsesRedis() {
+			return "REDIS_URL=redis://localhost:6379/0\n"
 		}
-		return fmt.Sprintf("%s=your-datagen-api-key-here\n", cfg.DatagenAPIKeyEnv)
+		return ""
 	}())
 
+	if cfg.UsesCapture() {
+		content += fmt.Sprintf("\n# Request capture for datagen replay\nCAPTURE_ENABLED=true\nCAPTURE_DIR=%s\n", cfg.CaptureDir())
+		if cfg.Capture.S3Bucket != "" {
+			content += fmt.Sprintf("CAPTURE_S3_BUCKET=%s\n", cfg.Capture.S3Bucket)
+			for _, v := range missingEnvVars(content, []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_REGION"}) {
+				content += v + "=\n"
+			}
+		}
+	}
+	if cfg.UsesPlayground() {
+		content += "\n# Browser playground at /playground; set false to hide it in production\nPLAYGROUND_ENABLED=true\n"
+	}
+
 	// Add service-specific env vars
 	for _, svc := range cfg.Services {
-		if svc.Auth != nil && svc.Auth.EnvVar != "" {
-			content += fmt.Sprintf("\n# Auth for %s service\n%s=your-secret-here\n", svc.Name, svc.Auth.EnvVar)
+		// Services sharing an auth variable get one line, under the first
+		if svc.Auth != nil && svc.Auth.EnvVar != "" && len(missingEnvVars(content, []string{svc.Auth.EnvVar})) > 0 {
+			content += fmt.Sprintf("\n# Auth for %s service\n%s=your-secret-here # secret\n", svc.Name, svc.Auth.EnvVar)
 		}
-		if svc.Webhook != nil && svc.Webhook.SecretEnv != "" {
-			content += fmt.Sprintf("%s=your-hmac-secret-here\n", svc.Webhook.SecretEnv)
+		if svc.Webhook != nil && svc.Webhook.SecretEnv != "" && len(missingEnvVars(content, []string{svc.Webhook.SecretEnv})) > 0 {
+			content += fmt.Sprintf("%s=your-hmac-secret-here # secret\n", svc.Webhook.SecretEnv)
+		}
+		if vars := missingEnvVars(content, svc.MCPEnvVars()); len(vars) > 0 {
+			content += fmt.Sprintf("\n# MCP servers for %s service\n", svc.Name)
+			for _, v := range vars {
+				content += v + "=\n"
+			}
+		}
+		if vars := missingEnvVars(content, svc.ProviderEnvVars()); len(vars) > 0 {
+			content += fmt.Sprintf("\n# Claude via %s for %s service\n", svc.ProviderName(), svc.Name)
+			for _, v := range vars {
+				content += v + "=\n"
+			}
 		}
 	}
 
 	return os.WriteFile(filepath.Join(outputDir, ".env.example"), []byte(content), 0644)
 }
 
-func generateProcfile(outputDir string) error {
-	content := `web: uvicorn app.main:app --host 0.0.0.0 --port $PORT
-`
+func generateProcfile(cfg *config.DatagenConfig, outputDir string) error {
+	content := "web: " + serverCommand(cfg, "$PORT") + "\n"
 	return os.WriteFile(filepath.Join(outputDir, "Procfile"), []byte(content), 0644)
 }
 
-func generateRailwayJSON(outputDir string) error {
-	content := `{
-  "$schema": "https://railway.com/railway.schema.json",
-  "build": {
-    "builder": "DOCKERFILE",
-    "dockerfilePath": "Dockerfile"
-  },
-  "deploy": {
-    "restartPolicyType": "ON_FAILURE",
-    "restartPolicyMaxRetries": 10
-  }
+// railwayJSON mirrors the subset of Railway's config-as-code schema datagen writes
+type railwayJSON struct {
+	Schema       string                        `json:"$schema"`
+	Build        railwayBuild                  `json:"build"`
+	Deploy       railwayDeploy                 `json:"deploy"`
+	Environments map[string]railwayEnvironment `json:"environments,omitempty"`
 }
-`
-	return os.WriteFile(filepath.Join(outputDir, "railway.json"), []byte(content), 0644)
+
+type railwayBuild struct {
+	Builder        string   `json:"builder,omitempty"`
+	DockerfilePath string   `json:"dockerfilePath,omitempty"`
+	WatchPatterns  []string `json:"watchPatterns,omitempty"`
 }
 
-func generateREADME(cfg *config.DatagenConfig, outputDir string) error {
-	content := "# DataGen Agent Project\n\n"
-	content += "Generated by DataGen CLI\n\n"
-	content += "## Services\n\n"
+type railwayDeploy struct {
+	HealthcheckPath         string `json:"healthcheckPath,omitempty"`
+	HealthcheckTimeout      int    `json:"healthcheckTimeout,omitempty"`
+	RestartPolicyType       string `json:"restartPolicyType,omitempty"`
+	RestartPolicyMaxRetries int    `json:"restartPolicyMaxRetries,omitempty"`
+	NumReplicas             int    `json:"numReplicas,omitempty"`
+	Region                  string `json:"region,omitempty"`
+	CronSchedule            string `json:"cronSchedule,omitempty"`
+}
+
+type railwayEnvironment struct {
+	Build  *railwayBuild  `json:"build,omitempty"`
+	Deploy *railwayDeploy `json:"deploy,omitempty"`
+}
+
+func generateRailwayJSON(cfg *config.DatagenConfig, outputDir string) error {
+	out := railwayJSON{
+		Schema: "https://railway.com/railway.schema.json",
+		Build:  railwayBuild{Builder: "DOCKERFILE", DockerfilePath: "Dockerfile"},
+		Deploy: railwayDeploy{
+			HealthcheckPath:         "/health",
+			RestartPolicyType:       "ON_FAILURE",
+			RestartPolicyMaxRetries: 10,
+		},
+	}
+	if cfg.Scaling != nil {
+		out.Deploy.NumReplicas = cfg.Scaling.MinReplicas
+	}
+	if r := cfg.Railway; r != nil {
+		out.Build.WatchPatterns = r.WatchPatterns
+		applyRailwayDeploy(&out.Deploy, r)
+	}
 
+	for _, name := range cfg.EnvironmentNames() {
+		r := cfg.Environments[name].Railway
+		if r == nil {
+			continue
+		}
+		env, err := config.ResolveEnvironment(cfg, name)
+		if err != nil {
+			return err
+		}
+		override := railwayEnvironment{}
+		if len(r.WatchPatterns) > 0 {
+			override.Build = &railwayBuild{WatchPatterns: r.WatchPatterns}
+		}
+		deploy := railwayDeploy{}
+		applyRailwayDeploy(&deploy, r)
+		if deploy != (railwayDeploy{}) {
+			override.Deploy = &deploy
+		}
+		if out.Environments == nil {
+			out.Environments = map[string]railwayEnvironment{}
+		}
+		out.Environments[env.RailwayEnvironment] = override
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outputDir, "railway.json"), append(data, '\n'), 0644)
+}
+
+// applyRailwayDeploy copies the deploy settings r sets onto d
+func applyRailwayDeploy(d *railwayDeploy, r *config.RailwayConfig) {
+	if r.HealthcheckPath != "" {
+		d.HealthcheckPath = r.HealthcheckPath
+	}
+	if r.HealthcheckTimeout > 0 {
+		d.HealthcheckTimeout = r.HealthcheckTimeout
+	}
+	if r.Replicas > 0 {
+		d.NumReplicas = r.Replicas
+	}
+	if r.Region != "" {
+		d.Region = r.Region
+	}
+	if r.CronSchedule != "" {
+		d.CronSchedule = r.CronSchedule
+	}
+}
+
+// generateK8sHPA writes a HorizontalPodAutoscaler targeting the in-flight gauge.
+// It is only generated when [scaling] sets both a target and a replica ceiling.
+func generateK8sHPA(cfg *config.DatagenConfig, outputDir string) error {
+	if cfg.Scaling == nil || cfg.Scaling.TargetConcurrency <= 0 || cfg.Scaling.MaxReplicas <= 0 {
+		return nil
+	}
+
+	minReplicas := cfg.Scaling.MinReplicas
+	if minReplicas <= 0 {
+		minReplicas = 1
+	}
+
+	if err := os.MkdirAll(filepath.Join(outputDir, "k8s"), 0755); err != nil {
+		return err
+	}
+
+	content := fmt.Sprintf(`# Scale on in-flight agent executions exported at /metrics.
+# Requires a custom metrics adapter (e.g. prometheus-adapter) that exposes
+# datagen_inflight_executions as a pods metric.
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: datagen-agents
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: datagen-agents
+  minReplicas: %d
+  maxReplicas: %d
+  metrics:
+    - type: Pods
+      pods:
+        metric:
+          name: datagen_inflight_executions
+        target:
+          type: AverageValue
+          averageValue: "%d"
+`, minReplicas, cfg.Scaling.MaxReplicas, cfg.Scaling.TargetConcurrency)
+
+	return os.WriteFile(filepath.Join(outputDir, "k8s", "hpa.yaml"), []byte(content), 0644)
+}
+
+// generateDOAppSpec writes a DigitalOcean App Platform spec. Secret variables
+// are declared without values; set them in the DO console or with doctl.
+func generateDOAppSpec(cfg *config.DatagenConfig, outputDir string) error {
+	instances := 1
+	if cfg.Scaling != nil && cfg.Scaling.MinReplicas > 0 {
+		instances = cfg.Scaling.MinReplicas
+	}
+
+	secrets := []string{cfg.ClaudeAPIKeyEnv, cfg.DatagenAPIKeyEnv}
 	for _, svc := range cfg.Services {
-		content += fmt.Sprintf("### %s (%s)\n", svc.Name, svc.Type)
-		content += fmt.Sprintf("- **Path**: %s\n", svc.GetPath())
-		content += fmt.Sprintf("- **Description**: %s\n", svc.Description)
-		content += fmt.Sprintf("- **Prompt**: %s\n\n", svc.Prompt)
-	}
-
-	content += "## Quick Start\n\n"
-	content += "1. Create a virtual environment:\n"
-	content += "   ```bash\n"
-	content += "   python -m venv venv\n"
-	content += "   source venv/bin/activate\n"
-	content += "   ```\n\n"
-	content += "2. Install dependencies:\n"
-	content += "   ```bash\n"
-	content += "   pip install -r requirements.txt\n"
-	content += "   ```\n\n"
-	content += "3. Set up environment variables:\n"
-	content += "   ```bash\n"
-	content += "   cp .env.example .env\n"
-	content += "   # Edit .env with your API keys\n"
-	content += "   ```\n\n"
-	content += "4. Run locally:\n"
-	content += "   ```bash\n"
-	content += "   uvicorn app.main:app --reload\n"
-	content += "   ```\n\n"
-	content += "5. Deploy to Railway:\n"
-	content += "   ```bash\n"
-	content += "   datagen deploy railway\n"
-	content += "   ```\n\n"
-	content += "## API Documentation\n\n"
-	content += "Once running, visit http://localhost:8000/docs for interactive API documentation.\n"
-
-	return os.WriteFile(filepath.Join(outputDir, "README.md"), []byte(content), 0644)
+		if svc.Auth != nil && svc.Auth.EnvVar != "" {
+			secrets = append(secrets, svc.Auth.EnvVar)
+		}
+		if svc.Webhook != nil && svc.Webhook.SecretEnv != "" {
+			secrets = append(secrets, svc.Webhook.SecretEnv)
+		}
+		for _, v := range append(svc.MCPEnvVars(), svc.ProviderEnvVars()...) {
+			if !slices.Contains(secrets, v) {
+				secrets = append(secrets, v)
+			}
+		}
+	}
+
+	var envs strings.Builder
+	for _, key := range secrets {
+		fmt.Fprintf(&envs, "      - key: %s\n        scope: RUN_TIME\n        type: SECRET\n", key)
+	}
+	for _, kv := range [][2]string{{"MODEL_NAME", models.Default}, {"LOG_LEVEL", "INFO"}, {"PORT", "8000"}} {
+		fmt.Fprintf(&envs, "      - key: %s\n        scope: RUN_TIME\n        value: \"%s\"\n", kv[0], kv[1])
+	}
+
+	if err := os.MkdirAll(filepath.Join(outputDir, ".do"), 0755); err != nil {
+		return err
+	}
+
+	content := fmt.Sprintf(`# DigitalOcean App Platform spec. Deploy with:
+#   doctl apps create --spec .do/app.yaml
+# Secrets are declared without values; set them in the console or with
+# 'doctl apps update <app-id> --spec .do/app.yaml' after adding values.
+name: datagen-agents
+services:
+  - name: web
+    dockerfile_path: Dockerfile
+    source_dir: /
+    http_port: 8000
+    instance_count: %d
+    instance_size_slug: basic-xs
+    health_check:
+      http_path: /health
+    envs:
+%s`, instances, envs.String())
+
+	return os.WriteFile(filepath.Join(outputDir, ".do", "app.yaml"), []byte(content), 0644)
 }
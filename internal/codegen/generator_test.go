@@ -1,8 +1,11 @@
 package codegen
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 
@@ -34,7 +37,7 @@ func TestGenerateProject_WebhookSignatureVerificationChecksSecretBeforeHeader(t
 		},
 	}
 
-	if err := GenerateProject(cfg, outDir); err != nil {
+	if _, err := GenerateProject(cfg, outDir); err != nil {
 		t.Fatalf("GenerateProject: %v", err)
 	}
 
@@ -86,7 +89,7 @@ func TestGenerateProject_WebhookNoSignatureVerificationOmitsSignatureHelper(t *t
 		},
 	}
 
-	if err := GenerateProject(cfg, outDir); err != nil {
+	if _, err := GenerateProject(cfg, outDir); err != nil {
 		t.Fatalf("GenerateProject: %v", err)
 	}
 
@@ -101,3 +104,1421 @@ func TestGenerateProject_WebhookNoSignatureVerificationOmitsSignatureHelper(t *t
 		t.Fatalf("did not expect signature verification helper to be generated when signature_verification=none")
 	}
 }
+
+func TestGenerateProject_TimestampedSignaturesRejectStaleRequests(t *testing.T) {
+	t.Parallel()
+
+	for _, provider := range []string{"stripe", "slack"} {
+		outDir := t.TempDir()
+		cfg := &config.DatagenConfig{
+			DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+			ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+			Services: []config.Service{
+				{
+					Name:        "events",
+					Type:        "webhook",
+					Description: "Events webhook",
+					Prompt:      ".claude/agents/events.md",
+					WebhookPath: "/webhook/events",
+					InputSchema: config.Schema{Fields: []config.Field{}},
+					Webhook: &config.WebhookConfig{
+						Provider:              provider,
+						SignatureVerification: "hmac_sha256",
+						SignatureHeader:       "X-Signature",
+						SecretEnv:             "EVENTS_WEBHOOK_SECRET",
+					},
+				},
+			},
+		}
+		if _, err := GenerateProject(cfg, outDir); err != nil {
+			t.Fatalf("%s: GenerateProject: %v", provider, err)
+		}
+		data, err := os.ReadFile(filepath.Join(outDir, "app", "main.py"))
+		if err != nil {
+			t.Fatalf("read main.py: %v", err)
+		}
+		src := string(data)
+		check := "if not timestamp.isdigit() or abs(time.time() - int(timestamp)) > 300:"
+		if !strings.Contains(src, check) || !strings.Contains(src, "\nimport time") {
+			t.Errorf("%s: signature check does not reject timestamps older than five minutes", provider)
+		}
+		if strings.Index(src, check) > strings.Index(src, "hmac.compare_digest") {
+			t.Errorf("%s: the timestamp is checked after the signature", provider)
+		}
+	}
+}
+
+func TestGenerateProject_ServerOptions(t *testing.T) {
+	t.Parallel()
+
+	newConfig := func(server *config.ServerConfig) *config.DatagenConfig {
+		return &config.DatagenConfig{
+			DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+			ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+			Server:           server,
+			Services: []config.Service{
+				{
+					Name:        "enrich",
+					Type:        "api",
+					Description: "Enrichment API",
+					Prompt:      ".claude/agents/enrich.md",
+					APIPath:     "/api/enrich",
+					InputSchema: config.Schema{Fields: []config.Field{}},
+				},
+			},
+		}
+	}
+
+	read := func(t *testing.T, dir, name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		return string(data)
+	}
+
+	defaultDir := t.TempDir()
+	if _, err := GenerateProject(newConfig(nil), defaultDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	if strings.Contains(read(t, defaultDir, "app/main.py"), "GZipMiddleware") {
+		t.Fatalf("expected no GZipMiddleware without [server] gzip")
+	}
+	if !strings.Contains(read(t, defaultDir, "Procfile"), "uvicorn app.main:app") {
+		t.Fatalf("expected Procfile to use uvicorn by default")
+	}
+
+	serverDir := t.TempDir()
+	if _, err := GenerateProject(newConfig(&config.ServerConfig{HTTP2: true, GZip: true}), serverDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	if !strings.Contains(read(t, serverDir, "app/main.py"), "app.add_middleware(GZipMiddleware") {
+		t.Fatalf("expected GZipMiddleware with [server] gzip = true")
+	}
+	for _, name := range []string{"Procfile", "Dockerfile"} {
+		if !strings.Contains(read(t, serverDir, name), "hypercorn app.main:app --bind 0.0.0.0:") {
+			t.Fatalf("expected %s to start hypercorn with [server] http2 = true", name)
+		}
+	}
+	if !strings.Contains(read(t, serverDir, "requirements.txt"), "hypercorn[h2]") {
+		t.Fatalf("expected requirements.txt to include hypercorn[h2]")
+	}
+}
+
+func TestGenerateProject_ScalingHints(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Scaling:          &config.ScalingConfig{TargetConcurrency: 4, MinReplicas: 2, MaxReplicas: 6},
+		Services: []config.Service{
+			{
+				Name:        "signup",
+				Type:        "webhook",
+				Description: "Signup webhook",
+				Prompt:      ".claude/agents/signup.md",
+				WebhookPath: "/webhook/signup",
+				InputSchema: config.Schema{Fields: []config.Field{}},
+			},
+		},
+	}
+
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	checks := map[string][]string{
+		"app/main.py":    {`@app.get("/metrics"`, `@app.get("/ready")`, `metrics.mark_queued("signup")`},
+		"app/config.py":  {"default=4,"},
+		"app/metrics.py": {"datagen_inflight_executions", "datagen_queue_depth"},
+		"railway.json":   {`"numReplicas": 2`},
+		"k8s/hpa.yaml":   {"minReplicas: 2", "maxReplicas: 6", `averageValue: "4"`},
+	}
+	for name, wants := range checks {
+		data, err := os.ReadFile(filepath.Join(outDir, name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(data), want) {
+				t.Fatalf("expected %s to contain %q", name, want)
+			}
+		}
+	}
+}
+
+func TestGenerateProject_WebhookDrainsOnShutdown(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{
+			{
+				Name:        "signup",
+				Type:        "webhook",
+				Description: "Signup webhook",
+				Prompt:      ".claude/agents/signup.md",
+				WebhookPath: "/webhook/signup",
+				InputSchema: config.Schema{Fields: []config.Field{}},
+			},
+		},
+	}
+
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "app", "main.py"))
+	if err != nil {
+		t.Fatalf("read main.py: %v", err)
+	}
+	src := string(data)
+	for _, want := range []string{
+		"abandoned = await lifecycle.drain(settings.shutdown_timeout)",
+		`log_event("task_abandoned", request_id=request_id, service="signup")`,
+		"if lifecycle.draining:",
+		`lifecycle.start(request_id, "signup")`,
+		"lifecycle.finish(request_id)",
+	} {
+		if !strings.Contains(src, want) {
+			t.Fatalf("expected main.py to contain %q", want)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(outDir, "app", "lifecycle.py")); err != nil {
+		t.Fatalf("expected app/lifecycle.py: %v", err)
+	}
+}
+
+func TestGenerateProject_CanaryRouting(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{
+			{
+				Name:        "enrich",
+				Type:        "api",
+				Description: "Enrich",
+				Prompt:      ".claude/agents/enrich.md",
+				InputSchema: config.Schema{Fields: []config.Field{}},
+			},
+		},
+	}
+
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	files := map[string][]string{
+		"main.py": {
+			"canary.load(agent_executors)",
+			`executor = canary.pick("enrich", agent_executors)`,
+		},
+		"canary.py": {
+			"settings.canary_traffic",
+			`executor.variant = "canary"`,
+		},
+		"metrics.py": {"datagen_agent_runs_total"},
+		"agent.py":   {"metrics.agent_runs[(self.service, self.variant, outcome)] += 1"},
+		"config.py":  {"canary_prompt", "canary_traffic", "canary_service"},
+	}
+	for name, wants := range files {
+		data, err := os.ReadFile(filepath.Join(outDir, "app", name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(data), want) {
+				t.Fatalf("expected %s to contain %q", name, want)
+			}
+		}
+	}
+}
+
+func TestGenerateProject_FakeAgentMode(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{
+			{
+				Name:        "enrich",
+				Type:        "api",
+				Description: "Enrich",
+				Prompt:      ".claude/agents/enrich.md",
+				InputSchema: config.Schema{Fields: []config.Field{}},
+			},
+		},
+	}
+
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	files := map[string][]string{
+		"app/agent.py": {
+			"async def fake_query(prompt: str, options: ClaudeAgentOptions):",
+			"run_query = fake_query if settings.agent_fake else query",
+		},
+		"app/config.py": {
+			"agent_fake: bool",
+			"agent_fake_response: Optional[str]",
+			"if self.agent_fake:\n            return self",
+		},
+		".env.example": {"# AGENT_FAKE=1"},
+	}
+	for name, wants := range files {
+		data, err := os.ReadFile(filepath.Join(outDir, name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(data), want) {
+				t.Fatalf("expected %s to contain %q", name, want)
+			}
+		}
+	}
+}
+
+func TestGenerateProject_ClaudeTuning(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Claude:           &config.ClaudeConfig{MaxRetries: 5, MaxConcurrency: 4},
+		Services: []config.Service{
+			{
+				Name:        "enrich",
+				Type:        "api",
+				Description: "Enrich",
+				Prompt:      ".claude/agents/enrich.md",
+				InputSchema: config.Schema{Fields: []config.Field{}},
+			},
+		},
+	}
+
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	files := map[string][]string{
+		"app/config.py": {
+			"claude_max_retries: int = Field(\n        default=5,",
+			"claude_timeout: int = Field(\n        default=600,",
+			"claude_max_concurrency: int = Field(\n        default=4,",
+		},
+		"app/agent.py": {
+			"asyncio.Semaphore(settings.claude_max_concurrency)",
+			"async with claude_slot():",
+			"env=self._claude_env(),",
+		},
+		".env.example": {"CLAUDE_MAX_RETRIES=5", "CLAUDE_TIMEOUT=600", "CLAUDE_MAX_CONCURRENCY=4"},
+	}
+	for name, wants := range files {
+		data, err := os.ReadFile(filepath.Join(outDir, name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(data), want) {
+				t.Fatalf("expected %s to contain %q", name, want)
+			}
+		}
+	}
+}
+
+func TestIncrementalAddService_KeepsCombinedImports(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	svc := config.Service{
+		Name:        "enrich",
+		Type:        "api",
+		Description: "Enrich",
+		Prompt:      ".claude/agents/enrich.md",
+		APIPath:     "/enrich",
+		InputSchema: config.Schema{Fields: []config.Field{}},
+	}
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services:         []config.Service{svc},
+	}
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	added := svc
+	added.Name = "summarize"
+	added.APIPath = "/summarize"
+	cfg.Services = append(cfg.Services, added)
+	if err := IncrementalAddService(cfg, &added, outDir); err != nil {
+		t.Fatalf("IncrementalAddService: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "app", "main.py"))
+	if err != nil {
+		t.Fatalf("read main.py: %v", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "from app import metrics" || line == "from app import lifecycle" {
+			t.Fatalf("main.py gained duplicate import %q", line)
+		}
+	}
+}
+
+func TestIncrementalRemoveService(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	base := config.Service{
+		Name:        "enrich",
+		Type:        "api",
+		Description: "Enrich",
+		Prompt:      ".claude/agents/enrich.md",
+		APIPath:     "/enrich",
+		InputSchema: config.Schema{Fields: []config.Field{}},
+	}
+	enrichV2 := base
+	enrichV2.Name = "enrich_v2"
+	enrichV2.APIPath = "/enrich_v2"
+	hook := base
+	hook.Name = "hook"
+	hook.Type = "webhook"
+	hook.WebhookPath = "/webhook/hook"
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services:         []config.Service{base, enrichV2, hook},
+	}
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	cfg.Services = []config.Service{enrichV2, hook}
+	if err := IncrementalRemoveService(cfg, "enrich", outDir); err != nil {
+		t.Fatalf("IncrementalRemoveService: %v", err)
+	}
+	moved := hook
+	moved.Prompt = ".claude/agents/hooks/hook.md"
+	cfg.Services[1] = moved
+	if err := IncrementalMovePrompt(cfg, &moved, hook.Prompt, outDir); err != nil {
+		t.Fatalf("IncrementalMovePrompt: %v", err)
+	}
+
+	files := map[string][]string{
+		"app/main.py": {
+			`agent_executors["enrich_v2"] = load_agent("enrich_v2", ".claude/agents/enrich.md")`,
+			`agent_executors["hook"] = load_agent("hook", ".claude/agents/hooks/hook.md")`,
+			"# API endpoint: enrich_v2",
+			"# Webhook endpoint: hook",
+			`"services": ["enrich_v2", "hook"],`,
+			"# === ENDPOINT HANDLERS END ===",
+		},
+		"app/models.py": {"# Models for enrich_v2 service", "# Models for hook service", "# === SERVICE MODELS END ==="},
+	}
+	for name, wants := range files {
+		data, err := os.ReadFile(filepath.Join(outDir, name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(data), want) {
+				t.Fatalf("expected %s to contain %q", name, want)
+			}
+		}
+	}
+	for file, gone := range map[string][]string{
+		"app/main.py":   {`agent_executors["enrich"]`, "# API endpoint: enrich\n", "async def enrich_handler("},
+		"app/models.py": {"# Models for enrich service", "class EnrichInput("},
+	} {
+		data, err := os.ReadFile(filepath.Join(outDir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range gone {
+			if strings.Contains(string(data), s) {
+				t.Errorf("%s still contains %q", file, s)
+			}
+		}
+	}
+}
+
+func TestIncrementalUpdateService(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	chat := config.Service{
+		Name:        "chat",
+		Type:        "api",
+		Description: "Chat",
+		Prompt:      ".claude/agents/chat.md",
+		APIPath:     "/chat",
+		InputSchema: config.Schema{Fields: []config.Field{{Name: "question", Type: "str", Required: true}}},
+	}
+	other := chat
+	other.Name = "other"
+	other.APIPath = "/other"
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services:         []config.Service{chat, other},
+	}
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	edited := chat
+	edited.Type = "webhook"
+	edited.APIPath = ""
+	edited.WebhookPath = "/webhook/chat"
+	edited.InputSchema = config.Schema{Fields: []config.Field{{Name: "event", Type: "dict", Required: true}}}
+	edited.Auth = &config.Auth{Type: "api_key", Header: "X-API-Key", EnvVar: "CHAT_API_KEY"}
+	cfg.Services[0] = edited
+	if err := IncrementalUpdateService(cfg, &edited, outDir); err != nil {
+		t.Fatalf("IncrementalUpdateService: %v", err)
+	}
+
+	main, err := os.ReadFile(filepath.Join(outDir, "app", "main.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	models, err := os.ReadFile(filepath.Join(outDir, "app", "models.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for file, counts := range map[string]map[string]int{
+		string(main): {
+			`agent_executors["chat"]`:    1,
+			"# Webhook endpoint: chat":   1,
+			"# API endpoint: chat":       0,
+			"async def verify_chat_auth": 1,
+			`"/webhook/chat"`:            1,
+			"# API endpoint: other":      1,
+		},
+		string(models): {"class ChatInput(": 1, "event: Dict[str, Any]": 1, "question: str": 1},
+	} {
+		for want, n := range counts {
+			if got := strings.Count(file, want); got != n {
+				t.Errorf("%q appears %d times, want %d", want, got, n)
+			}
+		}
+	}
+}
+
+func TestGenerateProject_LocalePrompts(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{
+			{
+				Name:        "enrich",
+				Type:        "api",
+				Description: "Enrich",
+				Prompt:      ".claude/agents/enrich.md",
+				Prompts:     map[string]string{"fr": ".claude/agents/enrich.fr.md", "de": ".claude/agents/enrich.de.md"},
+				InputSchema: config.Schema{Fields: []config.Field{}},
+			},
+			{
+				Name:        "plain",
+				Type:        "api",
+				Description: "Plain",
+				Prompt:      ".claude/agents/plain.md",
+				InputSchema: config.Schema{Fields: []config.Field{}},
+			},
+		},
+	}
+
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "app", "main.py"))
+	if err != nil {
+		t.Fatalf("read main.py: %v", err)
+	}
+	main := string(data)
+	for _, want := range []string{
+		"from app import canary, capture, lifecycle, locales, metrics",
+		`locales.load("enrich", {"de": ".claude/agents/enrich.de.md", "fr": ".claude/agents/enrich.fr.md"})`,
+		`executor = locales.pick("enrich", executor, payload.model_dump(), request.headers.get("accept-language"))`,
+	} {
+		if !strings.Contains(main, want) {
+			t.Fatalf("expected main.py to contain %q", want)
+		}
+	}
+	if strings.Contains(main, `locales.pick("plain"`) || strings.Contains(main, `locales.load("plain"`) {
+		t.Fatalf("main.py routes locales for a service without locale prompts")
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "app", "locales.py")); err != nil {
+		t.Fatalf("locales.py not generated: %v", err)
+	}
+}
+
+func TestGenerateProject_Pipeline(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{
+			{
+				Name:        "leads",
+				Type:        "pipeline",
+				Description: "Leads",
+				APIPath:     "/api/leads",
+				InputSchema: config.Schema{Fields: []config.Field{}},
+				Steps: []config.PipelineStep{
+					{Name: "extract", Prompt: ".claude/agents/extract.md"},
+					{Name: "score", Prompt: ".claude/agents/score.md"},
+					{Name: "tag", Prompt: ".claude/agents/tag.md", Parallel: true},
+					{Name: "write", Prompt: ".claude/agents/write.md", Input: map[string]string{"score": "score.value", "url": "input.url"}},
+				},
+			},
+		},
+	}
+
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "app", "main.py"))
+	if err != nil {
+		t.Fatalf("read main.py: %v", err)
+	}
+	main := string(data)
+	for _, want := range []string{
+		"from app import canary, capture, lifecycle, locales, metrics, pipeline, stores, streams",
+		`agent_executors["leads.extract"] = load_agent("leads.extract", ".claude/agents/extract.md")`,
+		`agent_executors["leads.write"] = load_agent("leads.write", ".claude/agents/write.md")`,
+		"# Pipeline endpoint: leads",
+		`@app.post("/api/leads")`,
+		"Type: Pipeline (extract -> score + tag -> write)",
+		`pipeline.run(agent_executors, "leads", [[{"name":"extract"}],[{"name":"score"},{"name":"tag"}],[{"input":{"score":"score.value","url":"input.url"},"name":"write"}]], payload.model_dump(), request_id)`,
+	} {
+		if !strings.Contains(main, want) {
+			t.Fatalf("expected main.py to contain %q", want)
+		}
+	}
+	if strings.Contains(main, `agent_executors["leads"]`) {
+		t.Fatalf("main.py loads an executor for the pipeline service itself")
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "app", "pipeline.py")); err != nil {
+		t.Fatalf("pipeline.py not generated: %v", err)
+	}
+
+	// Removing the pipeline drops its step executors and endpoint
+	cfg.Services = nil
+	if err := IncrementalRemoveService(cfg, "leads", outDir); err != nil {
+		t.Fatalf("IncrementalRemoveService: %v", err)
+	}
+	data, err = os.ReadFile(filepath.Join(outDir, "app", "main.py"))
+	if err != nil {
+		t.Fatalf("read main.py: %v", err)
+	}
+	if strings.Contains(string(data), "leads") {
+		t.Fatalf("main.py still mentions the removed pipeline:\n%s", data)
+	}
+}
+
+func TestGenerateProject_ModelProviders(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{
+			{
+				Name:          "enrich",
+				Type:          "api",
+				Description:   "Enrich",
+				Prompt:        ".claude/agents/enrich.md",
+				Model:         "us.anthropic.claude-sonnet-4-5-20250929-v1:0",
+				FallbackModel: "us.anthropic.claude-haiku-4-5-20251001-v1:0",
+				Provider:      &config.ProviderConfig{Name: "bedrock", Region: "us-east-1"},
+				InputSchema:   config.Schema{Fields: []config.Field{}},
+			},
+			{
+				Name:        "plain",
+				Type:        "api",
+				Description: "Plain",
+				Prompt:      ".claude/agents/plain.md",
+				Provider:    &config.ProviderConfig{Name: "vertex"},
+				InputSchema: config.Schema{Fields: []config.Field{}},
+			},
+		},
+	}
+
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	for file, wants := range map[string][]string{
+		"app/main.py": {
+			`load_agent("enrich", ".claude/agents/enrich.md", options={"fallback_model":"us.anthropic.claude-haiku-4-5-20251001-v1:0","model":"us.anthropic.claude-sonnet-4-5-20250929-v1:0","provider":{"name":"bedrock","region":"us-east-1"}})`,
+			`load_agent("plain", ".claude/agents/plain.md", options={"provider":{"name":"vertex"}})`,
+		},
+		"app/agent.py":  {"fallback_model=self.fallback_model", `"CLAUDE_CODE_USE_BEDROCK": "1"`, `"CLAUDE_CODE_USE_VERTEX": "1"`},
+		"app/config.py": {"aws_access_key_id: Optional[str]", "google_application_credentials_json: Optional[str]"},
+		".env.example": {
+			"# Optional\nANTHROPIC_API_KEY=",
+			"# Claude via bedrock for enrich service\nAWS_ACCESS_KEY_ID=\nAWS_SECRET_ACCESS_KEY=\n",
+			"# Claude via vertex for plain service\nANTHROPIC_VERTEX_PROJECT_ID=\nCLOUD_ML_REGION=\nGOOGLE_APPLICATION_CREDENTIALS_JSON=\n",
+		},
+	} {
+		data, err := os.ReadFile(filepath.Join(outDir, file))
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(data), want) {
+				t.Fatalf("expected %s to contain %q", file, want)
+			}
+		}
+	}
+	configPy, _ := os.ReadFile(filepath.Join(outDir, "app", "config.py"))
+	if strings.Contains(string(configPy), "ANTHROPIC_API_KEY is required") {
+		t.Fatalf("config.py requires the Anthropic key although no service calls the Anthropic API")
+	}
+}
+
+func TestGenerateProject_StreamingFraming(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{
+			{
+				Name:        "chat",
+				Type:        "streaming",
+				Description: "Chat",
+				APIPath:     "/api/chat",
+				Prompt:      ".claude/agents/chat.md",
+				InputSchema: config.Schema{Fields: []config.Field{}},
+			},
+			{
+				Name:        "feed",
+				Type:        "streaming",
+				Description: "Feed",
+				APIPath:     "/api/feed",
+				Prompt:      ".claude/agents/feed.md",
+				Streaming:   &config.StreamingConfig{Format: "default", BufferSize: 8192, Framing: "ndjson", WebSocket: true},
+				Auth:        &config.Auth{Type: "bearer_token", EnvVar: "FEED_TOKEN"},
+				InputSchema: config.Schema{Fields: []config.Field{}},
+			},
+		},
+	}
+
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	for file, wants := range map[string][]string{
+		"app/main.py": {
+			"Type: Streaming (SSE)",
+			`streams.frame("sse", "default", stream_id, seq, event)`,
+			`streams.frame("ndjson", "json", stream_id, seq, event)`,
+			`streams.parse_last_event_id(request.headers.get("last-event-id"))`,
+			`@app.websocket("/api/feed/ws")`,
+			`await verify_feed_auth(websocket.headers.get("authorization"))`,
+		},
+		"app/agent.py":             {"async def stream_events(", `yield {"type": "tool_use", "name": block.name, "input": block.input}`},
+		"app/streams.py":           {"def parse_last_event_id(", `"ndjson": "application/x-ndjson"`},
+		"scripts/stream_client.py": {`headers["Last-Event-ID"] = last_id`},
+		"README.md":                {"- **WebSocket**: /api/feed/ws"},
+		".env.example":             {"FEED_TOKEN=your-secret-here # secret\n"},
+	} {
+		data, err := os.ReadFile(filepath.Join(outDir, file))
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(data), want) {
+				t.Fatalf("expected %s to contain %q", file, want)
+			}
+		}
+	}
+	main, _ := os.ReadFile(filepath.Join(outDir, "app", "main.py"))
+	if strings.Contains(string(main), `@app.websocket("/api/chat/ws")`) {
+		t.Fatalf("main.py serves a WebSocket for a service without websocket = true")
+	}
+}
+
+func TestGenerateProject_Playground(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{
+			{
+				Name:        "chat",
+				Type:        "api",
+				Description: "Chat",
+				APIPath:     "/api/chat",
+				Prompt:      ".claude/agents/chat.md",
+				InputSchema: config.Schema{Fields: []config.Field{}},
+			},
+		},
+	}
+
+	outDir := t.TempDir()
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "app", "static", "playground.html")); !os.IsNotExist(err) {
+		t.Fatalf("playground.html written without [server] playground = true")
+	}
+	main, _ := os.ReadFile(filepath.Join(outDir, "app", "main.py"))
+	if strings.Contains(string(main), "HTMLResponse") {
+		t.Fatalf("main.py imports HTMLResponse without the playground")
+	}
+
+	cfg.Server = &config.ServerConfig{Playground: true}
+	outDir = t.TempDir()
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	for file, wants := range map[string][]string{
+		"app/main.py": {
+			"from fastapi.responses import HTMLResponse, JSONResponse",
+			`@app.get("/playground", response_class=HTMLResponse, include_in_schema=False)`,
+			"if not settings.playground_enabled:",
+		},
+		"app/config.py":              {"playground_enabled: bool = Field("},
+		".env.example":               {"PLAYGROUND_ENABLED=true"},
+		"app/static/playground.html": {`fetch("/openapi.json")`},
+	} {
+		data, err := os.ReadFile(filepath.Join(outDir, file))
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(data), want) {
+				t.Fatalf("expected %s to contain %q", file, want)
+			}
+		}
+	}
+}
+
+func TestGenerateProject_Capture(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{
+			{
+				Name:        "chat",
+				Type:        "api",
+				Description: "Chat",
+				APIPath:     "/api/chat",
+				Prompt:      ".claude/agents/chat.md",
+				InputSchema: config.Schema{Fields: []config.Field{}},
+			},
+		},
+	}
+
+	outDir := t.TempDir()
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	main, _ := os.ReadFile(filepath.Join(outDir, "app", "main.py"))
+	if strings.Contains(string(main), "capture.record") {
+		t.Fatalf("main.py records requests without [capture]")
+	}
+
+	cfg.Capture = &config.CaptureConfig{S3Bucket: "acme-recordings", Redact: []string{"email", "phone"}}
+	outDir = t.TempDir()
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	for file, wants := range map[string][]string{
+		"app/main.py": {"return await capture.record(request, call_next)"},
+		"app/config.py": {
+			`default="recordings", description="Directory recordings are written to"`,
+			`default="acme-recordings", description="S3 bucket recordings are also uploaded to"`,
+			`default="email,phone", description="Comma-separated payload fields to mask, besides secrets"`,
+		},
+		".env.example":     {"CAPTURE_ENABLED=true", "CAPTURE_S3_BUCKET=acme-recordings", "AWS_ACCESS_KEY_ID="},
+		"requirements.txt": {"boto3"},
+	} {
+		data, err := os.ReadFile(filepath.Join(outDir, file))
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(data), want) {
+				t.Fatalf("expected %s to contain %q", file, want)
+			}
+		}
+	}
+}
+
+func TestGenerateProject_PythonPackaging(t *testing.T) {
+	t.Parallel()
+
+	newConfig := func(packaging string) *config.DatagenConfig {
+		return &config.DatagenConfig{
+			DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+			ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+			PythonPackaging:  packaging,
+			Services: []config.Service{
+				{
+					Name:        "chat",
+					Type:        "api",
+					Description: "Chat",
+					APIPath:     "/api/chat",
+					Prompt:      ".claude/agents/chat.md",
+					InputSchema: config.Schema{Fields: []config.Field{}},
+				},
+			},
+		}
+	}
+
+	outDir := t.TempDir()
+	if _, err := GenerateProject(newConfig(""), outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "pyproject.toml")); !os.IsNotExist(err) {
+		t.Fatalf("pyproject.toml written for pip packaging")
+	}
+	docker, _ := os.ReadFile(filepath.Join(outDir, "Dockerfile"))
+	if !strings.Contains(string(docker), "RUN pip install --no-cache-dir -r requirements.txt") {
+		t.Fatalf("pip Dockerfile does not install requirements.txt:\n%s", docker)
+	}
+
+	for packaging, wants := range map[string]map[string][]string{
+		config.PackagingUV: {
+			"pyproject.toml": {`"fastapi~=0.115.0",`, "[tool.uv]\npackage = false"},
+			"Dockerfile":     {"COPY pyproject.toml uv.lock* ./", "uv sync --locked --no-dev"},
+			"README.md":      {"uv sync", "uv lock"},
+		},
+		config.PackagingPoetry: {
+			"pyproject.toml": {`"fastapi~=0.115.0",`, "[tool.poetry]\npackage-mode = false"},
+			"Dockerfile":     {"COPY pyproject.toml poetry.lock* ./", "poetry install --only main"},
+			"README.md":      {"poetry install", "poetry lock"},
+		},
+	} {
+		outDir := t.TempDir()
+		if _, err := GenerateProject(newConfig(packaging), outDir); err != nil {
+			t.Fatalf("GenerateProject(%s): %v", packaging, err)
+		}
+		if _, err := os.Stat(filepath.Join(outDir, "requirements.txt")); !os.IsNotExist(err) {
+			t.Errorf("%s: requirements.txt written alongside pyproject.toml", packaging)
+		}
+		for file, subs := range wants {
+			content, err := os.ReadFile(filepath.Join(outDir, file))
+			if err != nil {
+				t.Fatalf("%s: read %s: %v", packaging, file, err)
+			}
+			for _, want := range subs {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("%s: %s missing %q", packaging, file, want)
+				}
+			}
+		}
+	}
+}
+
+func TestRequirementsPins(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.DatagenConfig{Dependencies: map[string]string{"fastapi": "0.115.12", "uvicorn": "0.32.1"}}
+	outDir := t.TempDir()
+	if err := generateRequirementsTxt(cfg, outDir); err != nil {
+		t.Fatalf("generateRequirementsTxt: %v", err)
+	}
+	content, _ := os.ReadFile(filepath.Join(outDir, "requirements.txt"))
+	for _, want := range []string{"fastapi==0.115.12\n", "uvicorn[standard]==0.32.1\n", "anthropic~=0.39.0\n"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("requirements.txt missing %q:\n%s", want, content)
+		}
+	}
+	for _, r := range Requirements(cfg) {
+		if r.Name == "uvicorn" && (r.Extras != "[standard]" || r.Range != "~=0.32.0" || r.Version() != "0.32.1") {
+			t.Errorf("uvicorn requirement = %+v", r)
+		}
+		if r.Name == "anthropic" && r.Version() != "0.39.0" {
+			t.Errorf("anthropic Version() = %q, want 0.39.0", r.Version())
+		}
+	}
+}
+
+func TestGenerateProject_Docker(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{
+			{
+				Name:        "chat",
+				Type:        "api",
+				Description: "Chat",
+				APIPath:     "/api/chat",
+				Prompt:      ".claude/agents/chat.md",
+				InputSchema: config.Schema{Fields: []config.Field{}},
+			},
+		},
+	}
+	outDir := t.TempDir()
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	docker, _ := os.ReadFile(filepath.Join(outDir, "Dockerfile"))
+	if !strings.HasPrefix(string(docker), "# Use Python 3.13 slim image\nFROM python:3.13-slim\n") || strings.Contains(string(docker), "AS builder") {
+		t.Fatalf("default Dockerfile changed:\n%s", docker)
+	}
+	ignore, err := os.ReadFile(filepath.Join(outDir, ".dockerignore"))
+	if err != nil || !strings.Contains(string(ignore), ".env\n") {
+		t.Fatalf(".dockerignore = %q, %v; want it to exclude .env", ignore, err)
+	}
+	gitignore, err := os.ReadFile(filepath.Join(outDir, ".gitignore"))
+	if err != nil || !strings.Contains(string(gitignore), ".env\n") || !strings.Contains(string(gitignore), ".railway/") {
+		t.Fatalf(".gitignore = %q, %v; want it to ignore .env and .railway/", gitignore, err)
+	}
+
+	// A hand-edited .dockerignore survives rebuilds
+	if err := os.WriteFile(filepath.Join(outDir, ".dockerignore"), []byte("custom\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Docker = &config.DockerConfig{PythonVersion: "3.12", MultiStage: true, CacheMounts: true}
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	if ignore, _ := os.ReadFile(filepath.Join(outDir, ".dockerignore")); string(ignore) != "custom\n" {
+		t.Fatalf(".dockerignore overwritten: %q", ignore)
+	}
+	docker, _ = os.ReadFile(filepath.Join(outDir, "Dockerfile"))
+	for _, want := range []string{
+		"# syntax=docker/dockerfile:1\n",
+		"FROM python:3.12-slim AS builder\n",
+		"RUN python -m venv /opt/venv\n",
+		"RUN --mount=type=cache,target=/root/.cache/pip pip install -r requirements.txt\n",
+		"COPY --from=builder /opt/venv /opt/venv\n",
+		"COPY --chown=appuser:appuser . .\n",
+	} {
+		if !strings.Contains(string(docker), want) {
+			t.Errorf("multi-stage Dockerfile missing %q:\n%s", want, docker)
+		}
+	}
+	if strings.Contains(string(docker), "chown -R appuser:appuser /app") {
+		t.Errorf("multi-stage Dockerfile still chowns the whole app in a separate layer")
+	}
+
+	cfg.Docker = &config.DockerConfig{BaseImage: "registry.example.com/python:3.13"}
+	cfg.PythonPackaging = config.PackagingPoetry
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	docker, _ = os.ReadFile(filepath.Join(outDir, "Dockerfile"))
+	if !strings.Contains(string(docker), "FROM registry.example.com/python:3.13\n") || !strings.Contains(string(docker), "poetry install --only main --no-cache") {
+		t.Errorf("Dockerfile with base_image and poetry:\n%s", docker)
+	}
+}
+
+func TestGenerateProject_StateStore(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Server:           &config.ServerConfig{Workers: 4, StateStore: config.StateStoreRedis},
+		Services: []config.Service{
+			{
+				Name:        "enrich",
+				Type:        "api",
+				Description: "Enrich",
+				Prompt:      ".claude/agents/enrich.md",
+				API:         &config.APIConfig{Timeout: 30, RateLimitEnabled: true, RateLimitRPM: 60},
+				InputSchema: config.Schema{Fields: []config.Field{}},
+			},
+			{
+				Name:        "hook",
+				Type:        "webhook",
+				Description: "Hook",
+				Prompt:      ".claude/agents/hook.md",
+				InputSchema: config.Schema{Fields: []config.Field{}},
+			},
+		},
+	}
+
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	for file, wants := range map[string][]string{
+		"app/main.py": {
+			"from app import canary, capture, lifecycle, locales, metrics, pipeline, stores, streams",
+			`retry_after = await stores.check_rate_limit("enrich", client, 60)`,
+			`if not await stores.claim_delivery("hook", delivery_id):`,
+		},
+		"app/config.py":    {`default="redis"`, "redis_url: Optional[str]"},
+		"requirements.txt": {"redis~="},
+		".env.example":     {"STATE_STORE=redis", "REDIS_URL="},
+		"Procfile":         {"--workers 4"},
+	} {
+		data, err := os.ReadFile(filepath.Join(outDir, file))
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(data), want) {
+				t.Fatalf("expected %s to contain %q", file, want)
+			}
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "app", "stores.py")); err != nil {
+		t.Fatalf("stores.py not generated: %v", err)
+	}
+}
+
+func TestGenerateProject_MCPServers(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{
+			{
+				Name:        "triage",
+				Type:        "api",
+				APIPath:     "/triage",
+				Description: "Triage issues",
+				Prompt:      ".claude/agents/triage.md",
+				InputSchema: config.Schema{Fields: []config.Field{}},
+				MCPServers: []config.MCPServer{
+					{Name: "github", URL: "https://api.githubcopilot.com/mcp/", Headers: map[string]string{"Authorization": "Bearer ${GITHUB_TOKEN}"}},
+					{Name: "fs", Command: "npx", Args: []string{"-y", "@modelcontextprotocol/server-filesystem", "/data"}},
+				},
+			},
+		},
+	}
+
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	for file, wants := range map[string][]string{
+		"app/main.py": {
+			`load_agent("triage", ".claude/agents/triage.md", mcp_servers={"github": {"headers":{"Authorization":"Bearer ${GITHUB_TOKEN}"},"type":"http","url":"https://api.githubcopilot.com/mcp/"}, "fs": {"args":["-y","@modelcontextprotocol/server-filesystem","/data"],"command":"npx","type":"stdio"}})`,
+		},
+		"app/agent.py": {"service_mcp_servers[name] = mcp_servers", "expand_env(server)"},
+		".env.example": {"# MCP servers for triage service\nGITHUB_TOKEN=\n"},
+	} {
+		data, err := os.ReadFile(filepath.Join(outDir, file))
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(data), want) {
+				t.Fatalf("expected %s to contain %q", file, want)
+			}
+		}
+	}
+}
+
+func TestGenerateProject_SharedAuthEnvVar(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	auth := &config.Auth{Type: "api_key", Header: "X-API-Key", EnvVar: "AGENT_API_KEY"}
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Defaults:         &config.ServiceDefaults{Auth: auth},
+		Services: []config.Service{
+			{Name: "chat", Type: "api", APIPath: "/chat", Description: "Chat", Prompt: ".claude/agents/chat.md", InputSchema: config.Schema{Fields: []config.Field{}}},
+			{Name: "summarize", Type: "api", APIPath: "/summarize", Description: "Summarize", Prompt: ".claude/agents/summarize.md", InputSchema: config.Schema{Fields: []config.Field{}}},
+		},
+	}
+	cfg.ApplyDefaults()
+
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	for file, once := range map[string]string{
+		"app/config.py": "agent_api_key: Optional[str]",
+		".env.example":  "AGENT_API_KEY=",
+	} {
+		data, err := os.ReadFile(filepath.Join(outDir, file))
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		if n := strings.Count(string(data), once); n != 1 {
+			t.Errorf("%s has %q %d times, want once", file, once, n)
+		}
+	}
+}
+
+func TestGenerateProject_FieldDefaults(t *testing.T) {
+	t.Parallel()
+
+	fields := []config.Field{
+		{Name: "n", Type: "int", Default: "3"},
+		{Name: "ratio", Type: "float", Default: "2"},
+		{Name: "loud", Type: "bool", Default: "true"},
+		{Name: "title", Type: "str", Required: true, Default: `say "hi"`},
+		{Name: "note", Type: "str"},
+	}
+	want := []string{
+		"    n: int | None = 3\n",
+		"    ratio: float | None = 2.0\n",
+		"    loud: bool | None = True\n",
+		"    title: str = \"say \\\"hi\\\"\"\n",
+		"    note: str | None = None\n",
+	}
+	svc := func(name string) config.Service {
+		return config.Service{
+			Name: name, Type: "api", APIPath: "/" + name, Description: "Echo", Prompt: ".claude/agents/" + name + ".md",
+			InputSchema: config.Schema{Fields: fields},
+		}
+	}
+
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services:         []config.Service{svc("echo")},
+	}
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	// 'datagen add' renders the same fields
+	added := svc("echo2")
+	cfg.Services = append(cfg.Services, added)
+	if err := IncrementalAddService(cfg, &added, outDir); err != nil {
+		t.Fatalf("IncrementalAddService: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "app", "models.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range want {
+		if n := strings.Count(string(data), line); n != 2 {
+			t.Errorf("models.py has %q %d times, want it in both models", line, n)
+		}
+	}
+}
+
+func TestGenerateRailwayJSON(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		Scaling: &config.ScalingConfig{MinReplicas: 2},
+		Railway: &config.RailwayConfig{
+			HealthcheckTimeout: 120,
+			Region:             "us-west2",
+			WatchPatterns:      []string{"app/**", "requirements.txt"},
+		},
+		Environments: map[string]config.EnvironmentConfig{
+			"staging":    {RailwayEnvironment: "stage", Railway: &config.RailwayConfig{Replicas: 1, Region: "europe-west4"}},
+			"production": {},
+		},
+	}
+
+	if err := generateRailwayJSON(cfg, outDir); err != nil {
+		t.Fatalf("generateRailwayJSON() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, "railway.json"))
+	if err != nil {
+		t.Fatalf("read railway.json: %v", err)
+	}
+
+	var got railwayJSON
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("railway.json is not valid JSON: %v", err)
+	}
+	want := railwayDeploy{
+		HealthcheckPath:         "/health",
+		HealthcheckTimeout:      120,
+		RestartPolicyType:       "ON_FAILURE",
+		RestartPolicyMaxRetries: 10,
+		NumReplicas:             2,
+		Region:                  "us-west2",
+	}
+	if got.Deploy != want {
+		t.Fatalf("deploy = %+v, want %+v", got.Deploy, want)
+	}
+	if !reflect.DeepEqual(got.Build.WatchPatterns, []string{"app/**", "requirements.txt"}) {
+		t.Fatalf("watchPatterns = %v", got.Build.WatchPatterns)
+	}
+	if len(got.Environments) != 1 {
+		t.Fatalf("environments = %+v, want only stage", got.Environments)
+	}
+	stage := got.Environments["stage"]
+	if stage.Deploy == nil || *stage.Deploy != (railwayDeploy{NumReplicas: 1, Region: "europe-west4"}) || stage.Build != nil {
+		t.Fatalf("stage override = %+v", stage)
+	}
+}
+
+func TestGenerateProject_Examples(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{
+			{
+				Name:        "chat",
+				Type:        "api",
+				Description: "Chat",
+				APIPath:     "/api/chat",
+				Prompt:      ".claude/agents/chat.md",
+				InputSchema: config.Schema{Fields: []config.Field{
+					{Name: "question", Type: "str", Required: true},
+					{Name: "max_words", Type: "int", Default: "50"},
+					{Name: "tone", Type: "str"},
+					{Name: "tags", Type: "list", Required: true},
+				}},
+				Auth: &config.Auth{Type: "bearer_token", Header: "Authorization", EnvVar: "CHAT_TOKEN"},
+			},
+			{
+				Name:        "signup",
+				Type:        "webhook",
+				Description: "Signups",
+				WebhookPath: "/webhook/signup",
+				Prompt:      ".claude/agents/signup.md",
+				InputSchema: config.Schema{Fields: []config.Field{}},
+				Auth:        &config.Auth{Type: "api_key", Header: "X-Signup-Key", EnvVar: "SIGNUP_API_KEY"},
+				Webhook:     &config.WebhookConfig{SignatureVerification: "hmac_sha256", SignatureHeader: "X-Signature", SecretEnv: "HMAC_SECRET"},
+			},
+		},
+	}
+
+	outDir := t.TempDir()
+	result, err := GenerateProject(cfg, outDir)
+	if err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	for _, want := range []string{"examples/chat.json", "examples/signup.json", "examples/curl.sh", "examples/httpie.sh"} {
+		if !slices.Contains(result.Created, want) {
+			t.Fatalf("GenerateProject created %v, want %s", result.Created, want)
+		}
+	}
+
+	wantPayload := "{\n  \"question\": \"example question\",\n  \"max_words\": 50,\n  \"tags\": []\n}\n"
+	for file, wants := range map[string][]string{
+		"examples/chat.json":   {wantPayload},
+		"examples/signup.json": {"{}\n"},
+		"examples/curl.sh": {
+			"chat)\n  curl -sS -X POST \"$BASE_URL/api/chat\" \\\n  " +
+				"  -H \"Content-Type: application/json\" \\\n    -H \"Authorization: Bearer $CHAT_TOKEN\" \\\n    --data-binary @\"$DIR/chat.json\"\n  ;;",
+			`SIGNATURE=$(openssl dgst -sha256 -hmac "$HMAC_SECRET" < "$DIR/signup.json" | sed 's/^.* //')`,
+			`-H "X-Signup-Key: $SIGNUP_API_KEY" \`,
+			`-H "X-Signature: $SIGNATURE" \`,
+			`echo "services: chat signup" >&2`,
+		},
+		"examples/httpie.sh": {`http POST "$BASE_URL/api/chat" "Authorization:Bearer $CHAT_TOKEN" < "$DIR/chat.json"`},
+		"README.md":          {"- **Example**: `examples/chat.json`", "sh examples/curl.sh chat\n"},
+	} {
+		data, err := os.ReadFile(filepath.Join(outDir, file))
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(data), want) {
+				t.Fatalf("expected %s to contain %q, got:\n%s", file, want, data)
+			}
+		}
+	}
+
+	// Rebuilding compares the example files one by one
+	again, err := GenerateProject(cfg, outDir)
+	if err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	if !slices.Contains(again.Unchanged, "examples/chat.json") || len(again.Created)+len(again.Updated) != 0 {
+		t.Fatalf("rebuild = %+v, want every file unchanged", again)
+	}
+}
+
+func TestGenerateREADME(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Scaling:          &config.ScalingConfig{TargetConcurrency: 4, MaxReplicas: 6},
+		Services: []config.Service{
+			{
+				Name:         "summarize",
+				Type:         "api",
+				Description:  "Summarize a document",
+				APIPath:      "/api/summarize",
+				Prompt:       ".claude/agents/summarize.md",
+				InputSchema:  config.Schema{Fields: []config.Field{{Name: "text", Type: "str", Required: true}, {Name: "words", Type: "int", Default: "100"}}},
+				OutputSchema: &config.Schema{Fields: []config.Field{{Name: "summary", Type: "str", Required: true}}},
+				Auth:         &config.Auth{Type: "api_key", Header: "X-Summarize-Key", EnvVar: "SUMMARIZE_API_KEY"},
+				API:          &config.APIConfig{Timeout: 45, RateLimitEnabled: true, RateLimitRPM: 20},
+			},
+			{
+				Name:        "feed",
+				Type:        "streaming",
+				Description: "Stream updates",
+				APIPath:     "/api/feed",
+				Prompt:      ".claude/agents/feed.md",
+				InputSchema: config.Schema{Fields: []config.Field{}},
+				Streaming:   &config.StreamingConfig{Framing: config.FramingNDJSON},
+				Provider:    &config.ProviderConfig{Name: "bedrock", Region: "us-east-1"},
+			},
+		},
+	}
+
+	outDir := t.TempDir()
+	if err := generateREADME(cfg, outDir); err != nil {
+		t.Fatalf("generateREADME: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"| [summarize](#summarize) | api | `POST /api/summarize` | `X-Summarize-Key` header |",
+		"- **Auth**: `X-Summarize-Key: <SUMMARIZE_API_KEY>`",
+		"- **Type**: api; answers with the agent's result (timeout 45s)",
+		"- **Rate limit**: 20 requests per minute per client",
+		"| `words` | int | no | `100` |",
+		"**Response body**",
+		`-H "X-Summarize-Key: $SUMMARIZE_API_KEY" \`,
+		"```json\n{\n  \"text\": \"example text\",\n  \"words\": 100\n}\n```",
+		"```json\n{\n  \"summary\": \"example summary\"\n}\n```",
+		`{"id": "3f1c9a2e-8b4d-4e6f-9a0b-1c2d3e4f5a6b:1", "type": "text", "text": "Hello"}`,
+		"| `ANTHROPIC_API_KEY` | yes | Anthropic API key, used by summarize |",
+		"### feed\n\n| Variable | Required | Purpose |\n|----------|----------|---------|\n| `AWS_ACCESS_KEY_ID` | yes | Claude via bedrock |",
+		"| `SUMMARIZE_API_KEY` | no |",
+		"### Railway", "datagen env push\nrailway up\n", "doctl apps create --spec .do/app.yaml", "kubectl apply -f k8s/hpa.yaml",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("README.md missing %q", want)
+		}
+	}
+	if n := strings.Count(string(data), "**Response body**"); n != 1 {
+		t.Errorf("only the api service should document a response body:\n%s", data)
+	}
+}
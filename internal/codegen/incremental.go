@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
 
 	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/debuglog"
+	"github.com/datagendev/datagen-cli/internal/undo"
 )
 
 // IncrementalAddService adds a new service to existing project files
@@ -24,15 +27,175 @@ func IncrementalAddService(cfg *config.DatagenConfig, newService *config.Service
 	}
 
 	// Update .env.example if service has auth
-	if newService.Auth != nil || (newService.Webhook != nil && newService.Webhook.SecretEnv != "") {
+	if newService.Auth != nil || (newService.Webhook != nil && newService.Webhook.SecretEnv != "") || len(newService.MCPEnvVars()) > 0 || len(newService.ProviderEnvVars()) > 0 {
 		if err := updateEnvExample(newService, outputDir); err != nil {
 			return fmt.Errorf("failed to update .env.example: %w", err)
 		}
 	}
 
+	// The first streaming service brings the reconnecting client
+	if newService.Type == "streaming" {
+		if _, err := os.Stat(filepath.Join(outputDir, "scripts", "stream_client.py")); os.IsNotExist(err) {
+			if err := os.MkdirAll(filepath.Join(outputDir, "scripts"), 0755); err != nil {
+				return err
+			}
+			if err := undo.Save(filepath.Join(outputDir, "scripts", "stream_client.py")); err != nil {
+				return err
+			}
+			if err := generateStreamClientPy(cfg, outputDir); err != nil {
+				return fmt.Errorf("failed to generate stream_client.py: %w", err)
+			}
+		}
+	}
+
+	// Refresh generation metadata so /version reports the new service
+	if err := updateMetadataJSON(cfg, outputDir); err != nil {
+		return fmt.Errorf("failed to update metadata.json: %w", err)
+	}
+
 	return nil
 }
 
+// updateMetadataJSON refreshes metadata.json in an existing project
+func updateMetadataJSON(cfg *config.DatagenConfig, outputDir string) error {
+	if err := undo.Save(filepath.Join(outputDir, MetadataFile)); err != nil {
+		return err
+	}
+	return generateMetadataJSON(cfg, outputDir)
+}
+
+// IncrementalRemoveService removes the code IncrementalAddService (or a full
+// build) generated for the service named name. cfg no longer lists the service.
+// Statements and definitions are found by structure, so main.py and models.py
+// may have been reformatted since they were generated.
+func IncrementalRemoveService(cfg *config.DatagenConfig, name, outputDir string) error {
+	svc := &config.Service{Name: name}
+
+	mainPath := filepath.Join(outputDir, "app/main.py")
+	content, err := os.ReadFile(mainPath)
+	if err != nil {
+		return fmt.Errorf("failed to read main.py: %w", err)
+	}
+	lines := removeAgentLoading(strings.Split(string(content), "\n"), name)
+	lines = removeComment(lines, endpointHeaderLines(name))
+	for _, fn := range []string{"verify_" + name + "_auth", "verify_" + name + "_signature", svc.GetTaskName(), svc.GetFunctionName(), svc.GetFunctionName() + "_ws"} {
+		lines = removeDefinition(lines, fn)
+	}
+	mainContent := updateHealthCheckServices(strings.Join(lines, "\n"), cfg)
+	if err := undo.Save(mainPath); err != nil {
+		return err
+	}
+	debuglog.FileWrite(mainPath, []byte(mainContent))
+	if err := os.WriteFile(mainPath, []byte(mainContent), 0644); err != nil {
+		return err
+	}
+
+	modelsPath := filepath.Join(outputDir, "app/models.py")
+	content, err = os.ReadFile(modelsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read models.py: %w", err)
+	}
+	lines = removeComment(strings.Split(string(content), "\n"), []string{"# Models for " + name + " service"})
+	for _, class := range []string{svc.GetInputModelName(), svc.GetOutputModelName()} {
+		lines = removeDefinition(lines, class)
+	}
+	modelsContent := strings.Join(lines, "\n")
+	if err := undo.Save(modelsPath); err != nil {
+		return err
+	}
+	debuglog.FileWrite(modelsPath, []byte(modelsContent))
+	if err := os.WriteFile(modelsPath, []byte(modelsContent), 0644); err != nil {
+		return err
+	}
+
+	if err := updateMetadataJSON(cfg, outputDir); err != nil {
+		return fmt.Errorf("failed to update metadata.json: %w", err)
+	}
+	return nil
+}
+
+// IncrementalUpdateService regenerates the code for svc after its settings
+// changed; cfg lists it with the new settings. Its handlers, models and agent
+// loading are removed and generated again at the end of their sections, and
+// the rest of main.py and models.py is left as it is.
+func IncrementalUpdateService(cfg *config.DatagenConfig, svc *config.Service, outputDir string) error {
+	if err := IncrementalRemoveService(cfg, svc.Name, outputDir); err != nil {
+		return err
+	}
+	return IncrementalAddService(cfg, svc, outputDir)
+}
+
+// IncrementalMovePrompt points the generated agent loading for svc at its new
+// prompt path, oldPrompt being the path main.py loads today
+func IncrementalMovePrompt(cfg *config.DatagenConfig, svc *config.Service, oldPrompt, outputDir string) error {
+	mainPath := filepath.Join(outputDir, "app/main.py")
+	content, err := os.ReadFile(mainPath)
+	if err != nil {
+		return fmt.Errorf("failed to read main.py: %w", err)
+	}
+	lines := strings.Split(string(content), "\n")
+	moved := false
+	for _, stmt := range parsePyStatements(lines) {
+		if !strings.HasPrefix(stmt.code, fmt.Sprintf(`agent_executors["%s"]=load_agent("%s",`, svc.Name, svc.Name)) {
+			continue
+		}
+		// The prompt is the second argument, wherever the call wraps
+		for i := stmt.start; i < stmt.end && !moved; i++ {
+			if strings.Contains(lines[i], `"`+oldPrompt+`"`) {
+				lines[i] = strings.Replace(lines[i], `"`+oldPrompt+`"`, `"`+svc.Prompt+`"`, 1)
+				moved = true
+			}
+		}
+	}
+	if !moved {
+		return fmt.Errorf("main.py does not load %s for service %s - file may have been manually modified", oldPrompt, svc.Name)
+	}
+	mainContent := strings.Join(lines, "\n")
+	if err := undo.Save(mainPath); err != nil {
+		return err
+	}
+	debuglog.FileWrite(mainPath, []byte(mainContent))
+	if err := os.WriteFile(mainPath, []byte(mainContent), 0644); err != nil {
+		return err
+	}
+	if err := updateMetadataJSON(cfg, outputDir); err != nil {
+		return fmt.Errorf("failed to update metadata.json: %w", err)
+	}
+	return nil
+}
+
+// injectionMarkers are the comment markers IncrementalAddService injects
+// between, by file
+var injectionMarkers = []struct {
+	file    string
+	markers []string
+}{
+	{"app/main.py", []string{"=== AGENT LOADING START ===", "=== AGENT LOADING END ===", "=== ENDPOINT HANDLERS START ===", "=== ENDPOINT HANDLERS END ==="}},
+	{"app/models.py", []string{"=== SERVICE MODELS START ===", "=== SERVICE MODELS END ==="}},
+}
+
+// MissingMarkers returns "file: marker" for each injection marker missing
+// from the project in outputDir. Files that do not exist are reported too.
+func MissingMarkers(outputDir string) ([]string, error) {
+	var missing []string
+	for _, f := range injectionMarkers {
+		data, err := os.ReadFile(filepath.Join(outputDir, f.file))
+		if os.IsNotExist(err) {
+			missing = append(missing, f.file+": file not found")
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range f.markers {
+			if !strings.Contains(string(data), m) {
+				missing = append(missing, f.file+": "+m)
+			}
+		}
+	}
+	return missing, nil
+}
+
 // updateMainPy injects new endpoint handlers into main.py
 func updateMainPy(cfg *config.DatagenConfig, newService *config.Service, outputDir string) error {
 	mainPath := filepath.Join(outputDir, "app/main.py")
@@ -41,42 +204,212 @@ func updateMainPy(cfg *config.DatagenConfig, newService *config.Service, outputD
 		return fmt.Errorf("failed to read main.py: %w", err)
 	}
 
-	mainContent := string(content)
-
-	// Check for markers
-	if !strings.Contains(mainContent, "=== AGENT LOADING START ===") {
-		return fmt.Errorf("missing agent loading markers in main.py - file may have been manually modified")
+	lines := strings.Split(string(content), "\n")
+	var compat endpointCompat
+	if newService.Type == "webhook" {
+		// Webhook handlers stop taking work once lifespan starts draining
+		var drains bool
+		lines, drains = ensureDrainHook(lines)
+		compat.NoDrain = !drains
 	}
-	if !strings.Contains(mainContent, "=== ENDPOINT HANDLERS START ===") {
-		return fmt.Errorf("missing endpoint handlers markers in main.py - file may have been manually modified")
+	loadAt, indent, ok := agentLoadingSite(lines)
+	if !ok {
+		return fmt.Errorf("main.py loads no agents in lifespan and has no agent loading markers - file may have been manually modified; run 'datagen repair'")
 	}
 
 	// 1. Add agent loading
-	agentLoadingCode := fmt.Sprintf(`    agent_executors["%s"] = load_agent("%s", "%s")`,
-		newService.Name, newService.Name, newService.Prompt)
-	// Try with indentation first (newer templates), fall back to without (older files)
-	marker := "    # === AGENT LOADING END ==="
-	if !strings.Contains(mainContent, marker) {
-		marker = "# === AGENT LOADING END ===" // Fallback for older generated files
+	extraArgs := ""
+	options := pyOptions(newService)
+	if len(newService.MCPServers) > 0 || options != "" || newService.Type == "streaming" {
+		agentPy, err := os.ReadFile(filepath.Join(outputDir, "app", "agent.py"))
+		if err != nil {
+			return fmt.Errorf("failed to read agent.py: %w", err)
+		}
+		if newService.Type == "streaming" && !strings.Contains(string(agentPy), "def stream_events(") {
+			// Plain SSE text still works on the older stream_execute
+			if newService.StreamFormat() == "json" || newService.StreamWebSocket() {
+				return fmt.Errorf("app/agent.py predates structured streaming events, which json, ndjson and websocket streaming need; " +
+					"use plain SSE, or regenerate it with 'datagen build --file app/agent.py' (this replaces any changes made to agent.py)")
+			}
+			compat.LegacyStream = true
+		}
+		if len(newService.MCPServers) > 0 {
+			if !strings.Contains(string(agentPy), "mcp_servers=None") {
+				return fmt.Errorf("app/agent.py predates extra MCP servers; regenerate it with 'datagen build --file app/agent.py' (this replaces any changes made to agent.py)")
+			}
+			extraArgs += ", mcp_servers=" + pyMCPServers(newService.MCPServers)
+		}
+		if options != "" {
+			if !strings.Contains(string(agentPy), "options=None") {
+				return fmt.Errorf("app/agent.py predates per-service models and providers; regenerate it with 'datagen build --file app/agent.py' (this replaces any changes made to agent.py)")
+			}
+			extraArgs += ", options=" + options
+		}
+	}
+	var agentLoadingCode string
+	if newService.Type == "pipeline" {
+		// One executor per step, keyed "<service>.<step>" as app/pipeline.py expects
+		lines := make([]string, len(newService.Steps))
+		for i, step := range newService.Steps {
+			key := newService.Name + "." + step.Name
+			lines[i] = fmt.Sprintf(`    agent_executors["%s"] = load_agent("%s", "%s"%s)`, key, key, step.Prompt, extraArgs)
+		}
+		agentLoadingCode = strings.Join(lines, "\n")
+	} else {
+		agentLoadingCode = fmt.Sprintf(`    agent_executors["%s"] = load_agent("%s", "%s"%s)`, newService.Name, newService.Name, newService.Prompt, extraArgs)
+	}
+	if len(newService.Prompts) > 0 {
+		agentLoadingCode += fmt.Sprintf("\n    locales.load(\"%s\", %s)", newService.Name, pyDict(newService.Prompts))
+	}
+	// Indent like the existing agent loading, whatever the file uses
+	loading := strings.Split(agentLoadingCode, "\n")
+	for i, line := range loading {
+		loading[i] = indent + strings.TrimLeft(line, " ")
 	}
-	mainContent = injectBeforeMarker(mainContent, marker, agentLoadingCode+"\n")
 
 	// 2. Generate endpoint handler code
-	endpointCode, err := generateEndpointCode(newService)
+	endpointCode, err := generateEndpointCode(newService, compat)
 	if err != nil {
 		return fmt.Errorf("failed to generate endpoint code: %w", err)
 	}
 
-	// 3. Inject endpoint handler before END marker
-	mainContent = injectBeforeMarker(mainContent, "# === ENDPOINT HANDLERS END ===", endpointCode+"\n")
+	// 3. Inject the endpoint handler after the existing ones and the agent
+	// loading, the later site first so the earlier one stays put
+	if endpointAt := endpointSite(lines); endpointAt >= loadAt {
+		lines = insertPyBlock(lines, endpointAt, endpointCode)
+		lines = insertLines(lines, loadAt, loading...)
+	} else {
+		lines = insertLines(lines, loadAt, loading...)
+		lines = insertPyBlock(lines, endpointAt, endpointCode)
+	}
+	mainContent := strings.Join(lines, "\n")
 
 	// 4. Update health check services list
 	mainContent = updateHealthCheckServices(mainContent, cfg)
 
+	// 5. Projects generated by older CLIs lack the runtime helper modules the handlers use
+	mainContent, err = ensureRuntimeModules(mainContent, outputDir)
+	if err != nil {
+		return err
+	}
+
 	// Write back
+	if err := undo.Save(mainPath); err != nil {
+		return err
+	}
+	debuglog.FileWrite(mainPath, []byte(mainContent))
 	return os.WriteFile(mainPath, []byte(mainContent), 0644)
 }
 
+// runtimeModules are app/ helper modules imported by generated endpoint handlers
+var runtimeModules = []struct {
+	name     string
+	generate func(outputDir string) error
+}{
+	{"metrics", generateMetricsPy},
+	{"lifecycle", generateLifecyclePy},
+	{"canary", generateCanaryPy},
+	{"locales", generateLocalesPy},
+	{"stores", generateStoresPy},
+	{"pipeline", generatePipelinePy},
+	{"streams", generateStreamsPy},
+	{"capture", generateCapturePy},
+}
+
+// ensureRuntimeModules adds missing helper imports to main.py and writes any missing modules
+func ensureRuntimeModules(mainContent, outputDir string) (string, error) {
+	// Webhook and streaming handlers catch asyncio.CancelledError, and Stripe
+	// and Slack signature checks compare timestamps against time.time()
+	for _, module := range []string{"time", "asyncio"} {
+		if importsModule(mainContent, module) {
+			continue
+		}
+		lines := strings.Split(mainContent, "\n")
+		at := findLine(lines, 0, func(line string) bool {
+			return strings.HasPrefix(line, "import ") || strings.HasPrefix(line, "from ")
+		})
+		if at >= 0 {
+			mainContent = strings.Join(insertLines(lines, at, "import "+module), "\n")
+		}
+	}
+	for _, mod := range runtimeModules {
+		if importsAppModule(mainContent, mod.name) {
+			continue
+		}
+		importLine := "from app import " + mod.name
+		mainContent = strings.Replace(mainContent, "from app.agent import", importLine+"\nfrom app.agent import", 1)
+		path := filepath.Join(outputDir, "app", mod.name+".py")
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if err := undo.Save(path); err != nil {
+				return "", err
+			}
+			if err := mod.generate(outputDir); err != nil {
+				return "", fmt.Errorf("failed to generate %s.py: %w", mod.name, err)
+			}
+		}
+	}
+	return mainContent, nil
+}
+
+// importsModule reports whether main.py has a plain "import module" statement
+func importsModule(mainContent, module string) bool {
+	for _, s := range parsePyStatements(strings.Split(mainContent, "\n")) {
+		if names, ok := strings.CutPrefix(s.code, "import"); ok && slices.Contains(strings.Split(names, ","), module) {
+			return true
+		}
+	}
+	return false
+}
+
+// drainHook is what lifespan runs after yield so webhook tasks finish before
+// shutdown; older config.py files have no shutdown_timeout
+var drainHook = []string{
+	"# Reject new webhooks and give queued/running background tasks time to finish",
+	`log_event("app_draining", pending=len(lifecycle.pending), timeout=getattr(settings, "shutdown_timeout", 25))`,
+	`abandoned = await lifecycle.drain(getattr(settings, "shutdown_timeout", 25))`,
+	"for request_id, service in abandoned.items():",
+	`    log_event("task_abandoned", request_id=request_id, service=service)`,
+}
+
+// ensureDrainHook adds the drain hook after lifespan's yield when main.py
+// predates shutdown draining. It reports whether lifespan drains, which it
+// can't when the yield is not found.
+func ensureDrainHook(lines []string) ([]string, bool) {
+	if slices.ContainsFunc(lines, func(line string) bool { return strings.Contains(line, "lifecycle.drain(") }) {
+		return lines, true
+	}
+	start := findLine(lines, 0, func(line string) bool { return strings.HasPrefix(line, "async def lifespan(") })
+	if start < 0 {
+		return lines, false
+	}
+	at := findLine(lines, start+1, func(line string) bool { return strings.TrimSpace(line) == "yield" })
+	if at < 0 {
+		return lines, false
+	}
+	indent := indentOf(lines[at])
+	hook := make([]string, len(drainHook))
+	for i, line := range drainHook {
+		hook[i] = indent + line
+	}
+	return insertLines(lines, at+1, hook...), true
+}
+
+// importsAppModule reports whether a "from app import ..." statement in
+// mainContent names module, however it is wrapped or commented
+func importsAppModule(mainContent, module string) bool {
+	for _, s := range parsePyStatements(strings.Split(mainContent, "\n")) {
+		names, ok := strings.CutPrefix(s.code, "fromappimport")
+		if !ok {
+			continue
+		}
+		names = strings.Trim(names, "()")
+		if slices.Contains(strings.Split(names, ","), module) {
+			return true
+		}
+	}
+	return false
+}
+
 // updateModelsPy appends new models to models.py
 func updateModelsPy(newService *config.Service, outputDir string) error {
 	modelsPath := filepath.Join(outputDir, "app/models.py")
@@ -85,23 +418,27 @@ func updateModelsPy(newService *config.Service, outputDir string) error {
 		return fmt.Errorf("failed to read models.py: %w", err)
 	}
 
-	modelsContent := string(content)
-
-	// Check for marker
-	if !strings.Contains(modelsContent, "=== SERVICE MODELS START ===") {
-		return fmt.Errorf("missing service models markers in models.py - file may have been manually modified")
-	}
-
 	// Generate model code
 	modelCode, err := generateModelCode(newService)
 	if err != nil {
 		return fmt.Errorf("failed to generate model code: %w", err)
 	}
 
-	// Inject before END marker
-	modelsContent = injectBeforeMarker(modelsContent, "# === SERVICE MODELS END ===", modelCode+"\n")
+	// Inject before the END marker, or at the end of the file where the
+	// service models are generated
+	lines := strings.Split(string(content), "\n")
+	at := findLine(lines, 0, hasMarker("=== SERVICE MODELS END ==="))
+	if at < 0 {
+		at = trimmedEnd(lines)
+	}
+	lines = insertPyBlock(lines, at, modelCode)
+	modelsContent := strings.Join(lines, "\n")
 
 	// Write back
+	if err := undo.Save(modelsPath); err != nil {
+		return err
+	}
+	debuglog.FileWrite(modelsPath, []byte(modelsContent))
 	return os.WriteFile(modelsPath, []byte(modelsContent), 0644)
 }
 
@@ -121,7 +458,7 @@ func updateEnvExample(newService *config.Service, outputDir string) error {
 		varName := strings.ToUpper(newService.Auth.EnvVar)
 		if !strings.Contains(envContent, varName+"=") {
 			newVars = append(newVars, fmt.Sprintf("\n# %s authentication", newService.Name))
-			newVars = append(newVars, fmt.Sprintf("%s=your_%s_key_here", varName, strings.ToLower(newService.Name)))
+			newVars = append(newVars, fmt.Sprintf("%s=your_%s_key_here # secret", varName, strings.ToLower(newService.Name)))
 		}
 	}
 
@@ -130,40 +467,195 @@ func updateEnvExample(newService *config.Service, outputDir string) error {
 		varName := strings.ToUpper(newService.Webhook.SecretEnv)
 		if !strings.Contains(envContent, varName+"=") {
 			newVars = append(newVars, fmt.Sprintf("\n# %s webhook signature verification", newService.Name))
-			newVars = append(newVars, fmt.Sprintf("%s=your_webhook_secret_here", varName))
+			newVars = append(newVars, fmt.Sprintf("%s=your_webhook_secret_here # secret", varName))
+		}
+	}
+
+	// Add variables referenced by the service's MCP servers
+	if vars := missingEnvVars(envContent, newService.MCPEnvVars()); len(vars) > 0 {
+		newVars = append(newVars, fmt.Sprintf("\n# MCP servers for %s", newService.Name))
+		for _, v := range vars {
+			newVars = append(newVars, v+"=")
+		}
+	}
+
+	// Add the credentials of the service's Bedrock or Vertex provider
+	if vars := missingEnvVars(envContent, newService.ProviderEnvVars()); len(vars) > 0 {
+		newVars = append(newVars, fmt.Sprintf("\n# Claude via %s for %s", newService.ProviderName(), newService.Name))
+		for _, v := range vars {
+			newVars = append(newVars, v+"=")
 		}
 	}
 
 	if len(newVars) > 0 {
 		envContent += "\n" + strings.Join(newVars, "\n") + "\n"
+		if err := undo.Save(envPath); err != nil {
+			return err
+		}
+		debuglog.FileWrite(envPath, []byte(envContent))
 		return os.WriteFile(envPath, []byte(envContent), 0644)
 	}
 
 	return nil
 }
 
-// injectBeforeMarker inserts code before a marker line
-func injectBeforeMarker(content, marker, codeToInject string) string {
-	markerIndex := strings.Index(content, marker)
-	if markerIndex == -1 {
-		return content
+// agentLoadingSite returns the line new agent loading goes before and the
+// indentation it takes: the AGENT LOADING END marker if there is one, else
+// after the last agent loaded in the file, else before canary.load (or the
+// yield) in lifespan
+func agentLoadingSite(lines []string) (int, string, bool) {
+	if i := findLine(lines, 0, hasMarker("=== AGENT LOADING END ===")); i >= 0 {
+		return i, indentOf(lines[i]), true
+	}
+	stmts := parsePyStatements(lines)
+	for k := len(stmts) - 1; k >= 0; k-- {
+		if isAgentLoading(stmts[k]) {
+			return stmts[k].end, stmts[k].indent, true
+		}
+	}
+	for k, s := range stmts {
+		if !strings.HasPrefix(s.code, "asyncdeflifespan(") {
+			continue
+		}
+		for _, body := range stmts[k+1 : pyBlockEnd(stmts, k)] {
+			if body.code == "canary.load(agent_executors)" || body.code == "yield" {
+				return body.start, body.indent, true
+			}
+		}
 	}
+	return 0, "", false
+}
 
-	before := content[:markerIndex]
-	after := content[markerIndex:]
-	return before + codeToInject + after
+// endpointSite returns the line new endpoint handlers go before: the ENDPOINT
+// HANDLERS END marker if there is one, else the /health route (and the
+// comments right above it), else the __main__ guard, else the end of the file
+func endpointSite(lines []string) int {
+	if i := findLine(lines, 0, hasMarker("=== ENDPOINT HANDLERS END ===")); i >= 0 {
+		return i
+	}
+	for _, s := range parsePyStatements(lines) {
+		if s.indent != "" {
+			continue
+		}
+		if strings.HasPrefix(s.code, `@app.get("/health"`) {
+			i := s.start
+			for i > 0 && strings.HasPrefix(strings.TrimSpace(lines[i-1]), "#") {
+				i--
+			}
+			return i
+		}
+		if strings.HasPrefix(s.code, "if__name__==") {
+			return s.start
+		}
+	}
+	return trimmedEnd(lines)
+}
+
+// insertPyBlock inserts top-level Python code at line at, tidied by
+// normalizePython and set off from the code around it by two blank lines
+func insertPyBlock(lines []string, at int, code string) []string {
+	start, end := at, at
+	for start > 0 && strings.TrimSpace(lines[start-1]) == "" {
+		start--
+	}
+	for end < len(lines) && strings.TrimSpace(lines[end]) == "" {
+		end++
+	}
+	var block []string
+	if start > 0 {
+		block = append(block, "", "")
+	}
+	block = append(block, strings.Split(strings.TrimSuffix(normalizePython(code), "\n"), "\n")...)
+	if end == len(lines) {
+		block = append(block, "")
+	} else {
+		block = append(block, "", "")
+	}
+	return append(lines[:start:start], append(block, lines[end:]...)...)
+}
+
+// trimmedEnd returns the index after the last non-blank line
+func trimmedEnd(lines []string) int {
+	i := len(lines)
+	for i > 0 && strings.TrimSpace(lines[i-1]) == "" {
+		i--
+	}
+	return i
+}
+
+// endpointHeaderLines are the comments that open the endpoint block of the
+// service named name
+func endpointHeaderLines(name string) []string {
+	headers := make([]string, len(endpointHeaders))
+	for i, h := range endpointHeaders {
+		headers[i] = h + name
+	}
+	return headers
 }
 
-// updateHealthCheckServices updates the services list in health check endpoint
+// removeAgentLoading drops the agent_executors and locales.load statements of
+// the service named name, including the per-step executors of a pipeline and
+// calls a formatter wrapped over several lines
+func removeAgentLoading(lines []string, name string) []string {
+	stmts := parsePyStatements(lines)
+	for k := len(stmts) - 1; k >= 0; k-- {
+		code := stmts[k].code
+		if strings.HasPrefix(code, fmt.Sprintf(`agent_executors["%s"]=`, name)) ||
+			strings.HasPrefix(code, fmt.Sprintf(`agent_executors["%s.`, name)) ||
+			strings.HasPrefix(code, fmt.Sprintf(`locales.load("%s",`, name)) {
+			lines = append(lines[:stmts[k].start:stmts[k].start], lines[stmts[k].end:]...)
+		}
+	}
+	return lines
+}
+
+// removeComment drops the first line that is exactly one of comments, with
+// the blank lines after it
+func removeComment(lines, comments []string) []string {
+	for i, line := range lines {
+		for _, c := range comments {
+			if strings.TrimSpace(line) == c {
+				return removePyLines(lines, i, i+1)
+			}
+		}
+	}
+	return lines
+}
+
+// removeDefinition drops the top-level function or class named name, with
+// its decorators and the blank lines after it
+func removeDefinition(lines []string, name string) []string {
+	start, end, ok := pyDefinition(parsePyStatements(lines), name)
+	if !ok {
+		return lines
+	}
+	return removePyLines(lines, start, end)
+}
+
+// updateHealthCheckServices updates the services list in health check
+// endpoint, which a formatter may have spread over several lines
 func updateHealthCheckServices(content string, cfg *config.DatagenConfig) string {
 	// Find health check section
 	healthStart := strings.Index(content, `"services": [`)
 	if healthStart == -1 {
 		return content
 	}
+	listStart := healthStart + len(`"services": [`)
 
-	healthEnd := strings.Index(content[healthStart:], `],`)
-	if healthEnd == -1 {
+	listEnd := -1
+	depth := 1
+	for i := listStart; i < len(content) && listEnd < 0; i++ {
+		switch content[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				listEnd = i
+			}
+		}
+	}
+	if listEnd == -1 {
 		return content
 	}
 
@@ -172,16 +664,23 @@ func updateHealthCheckServices(content string, cfg *config.DatagenConfig) string
 	for _, svc := range cfg.Services {
 		serviceNames = append(serviceNames, fmt.Sprintf(`"%s"`, svc.Name))
 	}
-	newServicesList := strings.Join(serviceNames, ", ")
 
 	// Replace
-	before := content[:healthStart]
-	after := content[healthStart+healthEnd+2:] // +2 to skip past the "],"
-	return before + `"services": [` + newServicesList + `],` + after
+	return content[:listStart] + strings.Join(serviceNames, ", ") + content[listEnd:]
+}
+
+// endpointCompat adapts an injected handler to a main.py generated by an
+// older datagen
+type endpointCompat struct {
+	// NoDrain leaves out the shutdown check when lifespan never drains
+	NoDrain bool
+	// LegacyStream streams text chunks from stream_execute when agent.py has
+	// no stream_events
+	LegacyStream bool
 }
 
 // generateEndpointCode generates the endpoint handler code for a single service
-func generateEndpointCode(svc *config.Service) (string, error) {
+func generateEndpointCode(svc *config.Service, compat endpointCompat) (string, error) {
 	// Create a mini-template with just the endpoint handler
 	tmplStr := `
 {{if eq .Type "webhook"}}
@@ -222,15 +721,39 @@ def verify_{{.Name}}_signature(request: Request, body: bytes):
     if not secret:
         return  # Verification optional if secret not configured
 
+    {{if eq .Webhook.Provider "github"}}
+    expected = "sha256=" + hmac.new(secret.encode(), body, hashlib.sha256).hexdigest()
+    {{else if eq .Webhook.Provider "stripe"}}
+    parts = dict(p.split("=", 1) for p in signature.split(",") if "=" in p)
+    timestamp = parts.get("t", "")
+    # Reject replays of signatures more than five minutes old
+    if not timestamp.isdigit() or abs(time.time() - int(timestamp)) > 300:
+        raise HTTPException(status_code=401, detail="Stale or missing signature timestamp")
+    signed = timestamp.encode() + b"." + body
+    expected = hmac.new(secret.encode(), signed, hashlib.sha256).hexdigest()
+    signature = parts.get("v1", "")
+    {{else if eq .Webhook.Provider "slack"}}
+    timestamp = request.headers.get("X-Slack-Request-Timestamp", "")
+    # Reject replays of signatures more than five minutes old
+    if not timestamp.isdigit() or abs(time.time() - int(timestamp)) > 300:
+        raise HTTPException(status_code=401, detail="Stale or missing signature timestamp")
+    signed = b"v0:" + timestamp.encode() + b":" + body
+    expected = "v0=" + hmac.new(secret.encode(), signed, hashlib.sha256).hexdigest()
+    {{else}}
     expected = hmac.new(secret.encode(), body, hashlib.sha256).hexdigest()
+    {{end}}
     if not hmac.compare_digest(signature, expected):
         raise HTTPException(status_code=401, detail="Invalid signature")
 {{end}}
 
-async def {{.Name}}_task(payload: {{.GetInputModelName}}, request_id: str):
+async def {{.Name}}_task(payload: {{.GetInputModelName}}, request_id: str{{if .Prompts}}, accept_language: str | None = None{{end}}):
     """Background task for {{.Name}}."""
+    metrics.mark_dequeued("{{.Name}}")
     try:
-        executor = agent_executors["{{.Name}}"]
+        executor = canary.pick("{{.Name}}", agent_executors)
+        {{if .Prompts}}
+        executor = locales.pick("{{.Name}}", executor, payload.model_dump(), accept_language)
+        {{end}}
         await executor.execute(payload.model_dump(), request_id)
     except Exception as e:
         log_event(
@@ -240,6 +763,11 @@ async def {{.Name}}_task(payload: {{.GetInputModelName}}, request_id: str):
             error=str(e),
             error_type=type(e).__name__,
         )
+    except asyncio.CancelledError:
+        log_event("task_abandoned", request_id=request_id, service="{{.Name}}")
+        raise
+    finally:
+        lifecycle.finish(request_id)
 
 @app.post("{{.WebhookPath}}")
 async def {{.GetFunctionName}}(
@@ -255,13 +783,29 @@ async def {{.GetFunctionName}}(
     """
     request_id = request.state.request_id
 
+    {{if not .NoDrain}}
+    if lifecycle.draining:
+        raise HTTPException(status_code=503, detail="Server is shutting down")
+    {{end}}
+
     {{if and .Webhook .Webhook.SignatureVerification (eq .Webhook.SignatureVerification "hmac_sha256")}}
     body = await request.body()
     verify_{{.Name}}_signature(request, body)
     {{end}}
 
+    delivery_id = (
+        request.headers.get("Idempotency-Key")
+        or request.headers.get("X-GitHub-Delivery")
+        or request.headers.get("webhook-id")
+    )
+    if not await stores.claim_delivery("{{.Name}}", delivery_id):
+        log_event("webhook_duplicate", request_id=request_id, service="{{.Name}}", delivery_id=delivery_id)
+        return {"status": "duplicate", "request_id": request_id}
+
     log_event("webhook_queued", request_id=request_id, service="{{.Name}}")
-    background_tasks.add_task({{.Name}}_task, payload, request_id)
+    metrics.mark_queued("{{.Name}}")
+    lifecycle.start(request_id, "{{.Name}}")
+    background_tasks.add_task({{.Name}}_task, payload, request_id{{if .Prompts}}, request.headers.get("accept-language"){{end}})
 
     return {"status": "accepted", "request_id": request_id, "message": "Processing in background"}
 
@@ -305,9 +849,19 @@ async def {{.GetFunctionName}}(
     {{if .API}}Timeout: {{.API.Timeout}}s{{end}}
     """
     request_id = request.state.request_id
+    {{if and .API .API.RateLimitEnabled}}
+    client = request.headers.get("authorization") or (request.client.host if request.client else "unknown")
+    retry_after = await stores.check_rate_limit("{{.Name}}", client, {{.API.RateLimitRPM}})
+    if retry_after is not None:
+        log_event("rate_limited", request_id=request_id, service="{{.Name}}")
+        raise HTTPException(status_code=429, detail="Rate limit exceeded", headers={"Retry-After": str(retry_after)})
+    {{end}}
 
     try:
-        executor = agent_executors["{{.Name}}"]
+        executor = canary.pick("{{.Name}}", agent_executors)
+        {{if .Prompts}}
+        executor = locales.pick("{{.Name}}", executor, payload.model_dump(), request.headers.get("accept-language"))
+        {{end}}
         result = await executor.execute(payload.model_dump(), request_id)
         {{if .OutputSchema}}
         # TODO: Parse result into {{.GetOutputModelName}}
@@ -319,6 +873,60 @@ async def {{.GetFunctionName}}(
         log_event("api_error", request_id=request_id, service="{{.Name}}", error=str(e))
         raise HTTPException(status_code=500, detail="Agent execution failed")
 
+{{else if eq .Type "pipeline"}}
+# Pipeline endpoint: {{.Name}}
+{{if .Auth}}
+async def verify_{{.Name}}_auth({{if eq .Auth.Type "api_key"}}{{.Auth.Header | lower | replace "-" "_"}}: str | None = Header(None, alias="{{.Auth.Header}}"){{else if eq .Auth.Type "bearer_token"}}authorization: str | None = Header(None){{end}}):
+    """Verify authentication for {{.Name}} endpoint."""
+    {{if eq .Auth.Type "api_key"}}
+    expected_key = getattr(settings, "{{.Auth.EnvVar | lower}}", None)
+    if not expected_key:
+        return  # Auth optional if not configured
+    if {{.Auth.Header | lower | replace "-" "_"}} is None:
+        raise HTTPException(status_code=401, detail="API key required")
+    if {{.Auth.Header | lower | replace "-" "_"}} != expected_key:
+        raise HTTPException(status_code=401, detail="Invalid API key")
+    {{else if eq .Auth.Type "bearer_token"}}
+    expected_token = getattr(settings, "{{.Auth.EnvVar | lower}}", None)
+    if not expected_token:
+        return  # Auth optional if not configured
+    if authorization is None:
+        raise HTTPException(status_code=401, detail="Bearer token required")
+    if not authorization.startswith("Bearer "):
+        raise HTTPException(status_code=401, detail="Invalid authorization format")
+    token = authorization[7:]
+    if token != expected_token:
+        raise HTTPException(status_code=401, detail="Invalid bearer token")
+    {{end}}
+{{end}}
+
+@app.post("{{.APIPath}}")
+async def {{.GetFunctionName}}(
+    request: Request,
+    payload: {{.GetInputModelName}},
+    {{if .Auth}}_: None = Depends(verify_{{.Name}}_auth),{{end}}
+):
+    """
+    {{.Description}}
+
+    Type: Pipeline ({{range $i, $s := .Steps}}{{if $i}}{{if $s.Parallel}} + {{else}} -> {{end}}{{end}}{{$s.Name}}{{end}})
+    """
+    request_id = request.state.request_id
+    {{if and .API .API.RateLimitEnabled}}
+    client = request.headers.get("authorization") or (request.client.host if request.client else "unknown")
+    retry_after = await stores.check_rate_limit("{{.Name}}", client, {{.API.RateLimitRPM}})
+    if retry_after is not None:
+        log_event("rate_limited", request_id=request_id, service="{{.Name}}")
+        raise HTTPException(status_code=429, detail="Rate limit exceeded", headers={"Retry-After": str(retry_after)})
+    {{end}}
+
+    try:
+        steps, result = await pipeline.run(agent_executors, "{{.Name}}", {{pyStages .}}, payload.model_dump(), request_id)
+        return {"status": "completed", "request_id": request_id, "result": result, "steps": steps}
+    except pipeline.StepError as e:
+        log_event("pipeline_error", request_id=request_id, service="{{.Name}}", step=e.step, error=str(e.error))
+        raise HTTPException(status_code=500, detail=f"Pipeline step {e.step} failed")
+
 {{else if eq .Type "streaming"}}
 # Streaming endpoint: {{.Name}}
 {{if .Auth}}
@@ -355,20 +963,19 @@ async def {{.GetFunctionName}}(
     """
     {{.Description}}
 
-    Type: Streaming (SSE)
+    Type: Streaming ({{.StreamFraming | upper}})
     """
     request_id = request.state.request_id
+    {{if .LegacyStream}}
+    executor = canary.pick("{{.Name}}", agent_executors)
+    {{if .Prompts}}
+    executor = locales.pick("{{.Name}}", executor, payload.model_dump(), request.headers.get("accept-language"))
+    {{end}}
 
     async def event_generator():
         try:
-            executor = agent_executors["{{.Name}}"]
             async for chunk in executor.stream_execute(payload.model_dump(), request_id):
-                {{if and .Streaming (eq .Streaming.Format "json")}}
-                import json
-                yield f"data: {json.dumps({'text': chunk})}\n\n"
-                {{else}}
-                yield f"data: {chunk}\n\n"
-                {{end}}
+                yield "".join(f"data: {line}\n" for line in chunk.split("\n")) + "\n"
             yield "event: done\ndata: [DONE]\n\n"
         except Exception as e:
             log_event("streaming_error", request_id=request_id, service="{{.Name}}", error=str(e))
@@ -376,6 +983,56 @@ async def {{.GetFunctionName}}(
 
     headers = {"X-Request-ID": request_id}
     return StreamingResponse(event_generator(), media_type="text/event-stream", headers=headers)
+    {{else}}
+    resume = streams.parse_last_event_id(request.headers.get("last-event-id"))
+    if resume is not None:
+        stream_id, after = resume
+        if not streams.exists(stream_id):
+            raise HTTPException(status_code=410, detail="Stream expired; start a new request")
+    else:
+        executor = canary.pick("{{.Name}}", agent_executors)
+        {{if .Prompts}}
+        executor = locales.pick("{{.Name}}", executor, payload.model_dump(), request.headers.get("accept-language"))
+        {{end}}
+        stream_id, after = streams.start("{{.Name}}", executor, payload.model_dump(), request_id), 0
+
+    async def event_generator():
+        async for seq, event in streams.follow(stream_id, after):
+            chunk = streams.frame("{{.StreamFraming}}", "{{.StreamFormat}}", stream_id, seq, event)
+            if chunk:
+                yield chunk
+
+    headers = {"X-Request-ID": request_id, "X-Stream-ID": stream_id}
+    return StreamingResponse(event_generator(), media_type=streams.MEDIA_TYPES["{{.StreamFraming}}"], headers=headers)
+    {{end}}
+{{if .StreamWebSocket}}
+
+@app.websocket("{{.APIPath}}/ws")
+async def {{.GetFunctionName}}_ws(websocket: streams.WebSocket):
+    """WebSocket fallback for {{.Name}}: the same events as JSON messages."""
+    {{if .Auth}}
+    try:
+        await verify_{{.Name}}_auth({{if eq .Auth.Type "api_key"}}websocket.headers.get("{{.Auth.Header}}"){{else if eq .Auth.Type "bearer_token"}}websocket.headers.get("authorization"){{end}})
+    except HTTPException:
+        await websocket.close(code=1008)
+        return
+    {{end}}
+    await websocket.accept()
+
+    def pick(payload):
+        executor = canary.pick("{{.Name}}", agent_executors)
+        {{if .Prompts}}
+        executor = locales.pick("{{.Name}}", executor, payload, websocket.headers.get("accept-language"))
+        {{end}}
+        return executor
+
+    await streams.serve_websocket(
+        websocket,
+        "{{.Name}}",
+        pick,
+        lambda data: {{.GetInputModelName}}.model_validate(data).model_dump(),
+    )
+{{end}}
 
 {{end}}`
 
@@ -385,7 +1042,11 @@ async def {{.GetFunctionName}}(
 	}
 
 	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, svc); err != nil {
+	data := struct {
+		*config.Service
+		endpointCompat
+	}{svc, compat}
+	if err := tmpl.Execute(&buf, data); err != nil {
 		return "", err
 	}
 
@@ -397,16 +1058,16 @@ func generateModelCode(svc *config.Service) (string, error) {
 	tmplStr := `# Models for {{.Name}} service
 class {{.GetInputModelName}}(BaseModel):
     """Input model for {{.Name}} endpoint."""
-    {{range .InputSchema.Fields}}
-    {{.Name}}: {{if eq .Type "str"}}str{{else if eq .Type "int"}}int{{else if eq .Type "float"}}float{{else if eq .Type "bool"}}bool{{else if eq .Type "list"}}List[Any]{{else if eq .Type "dict"}}Dict[str, Any]{{else}}Any{{end}}{{if not .Required}} | None = None{{end}}{{if .Default}} = "{{.Default}}"{{end}}
-    {{end}}
+    {{- range .InputSchema.Fields}}
+    {{.Name}}: {{if eq .Type "str"}}str{{else if eq .Type "int"}}int{{else if eq .Type "float"}}float{{else if eq .Type "bool"}}bool{{else if eq .Type "list"}}List[Any]{{else if eq .Type "dict"}}Dict[str, Any]{{else}}Any{{end}}{{if not .Required}} | None{{end}}{{if or .Default (not .Required)}} = {{pyDefault .}}{{end}}
+    {{- end}}
 
 {{if .OutputSchema}}
 class {{.GetOutputModelName}}(BaseModel):
     """Output model for {{.Name}} endpoint."""
-    {{range .OutputSchema.Fields}}
-    {{.Name}}: {{if eq .Type "str"}}str{{else if eq .Type "int"}}int{{else if eq .Type "float"}}float{{else if eq .Type "bool"}}bool{{else if eq .Type "list"}}List[Any]{{else if eq .Type "dict"}}Dict[str, Any]{{else}}Any{{end}}{{if not .Required}} | None = None{{end}}{{if .Default}} = "{{.Default}}"{{end}}
-    {{end}}
+    {{- range .OutputSchema.Fields}}
+    {{.Name}}: {{if eq .Type "str"}}str{{else if eq .Type "int"}}int{{else if eq .Type "float"}}float{{else if eq .Type "bool"}}bool{{else if eq .Type "list"}}List[Any]{{else if eq .Type "dict"}}Dict[str, Any]{{else}}Any{{end}}{{if not .Required}} | None{{end}}{{if or .Default (not .Required)}} = {{pyDefault .}}{{end}}
+    {{- end}}
 {{end}}
 `
 
@@ -0,0 +1,219 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/undo"
+)
+
+// legacyProject copies testdata/legacy, a project generated by a datagen
+// that predates the runtime helper modules, into a temporary directory
+func legacyProject(t *testing.T) (string, *config.DatagenConfig) {
+	t.Helper()
+	dir := t.TempDir()
+	err := filepath.WalkDir(filepath.Join("testdata", "legacy"), func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(filepath.Join("testdata", "legacy"), path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(rel)), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, rel), data, 0644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{{
+			Name:        "chat",
+			Type:        "api",
+			Description: "Chat",
+			Prompt:      ".claude/agents/chat.md",
+			APIPath:     "/api/chat",
+			InputSchema: config.Schema{Fields: []config.Field{}},
+		}},
+	}
+	return dir, cfg
+}
+
+func TestIncrementalAddService_LegacyWebhook(t *testing.T) {
+	t.Parallel()
+
+	dir, cfg := legacyProject(t)
+	hook := config.Service{
+		Name:        "hook",
+		Type:        "webhook",
+		Description: "Hook",
+		Prompt:      ".claude/agents/hook.md",
+		WebhookPath: "/webhook/hook",
+		InputSchema: config.Schema{Fields: []config.Field{}},
+	}
+	cfg.Services = append(cfg.Services, hook)
+	if err := IncrementalAddService(cfg, &hook, dir); err != nil {
+		t.Fatalf("IncrementalAddService: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "app", "main.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := string(data)
+	for _, want := range []string{
+		"\nimport asyncio\n",
+		"\nimport time\n",
+		"except asyncio.CancelledError:",
+		"    yield\n    # Reject new webhooks",
+		`abandoned = await lifecycle.drain(getattr(settings, "shutdown_timeout", 25))`,
+		"if lifecycle.draining:",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("main.py missing %q", want)
+		}
+	}
+
+	// A second webhook finds the hook in place and adds neither again
+	second := hook
+	second.Name, second.WebhookPath = "hook2", "/webhook/hook2"
+	cfg.Services = append(cfg.Services, second)
+	if err := IncrementalAddService(cfg, &second, dir); err != nil {
+		t.Fatalf("IncrementalAddService: %v", err)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, "app", "main.py"))
+	for _, once := range []string{"import asyncio\n", "import time\n", "lifecycle.drain("} {
+		if n := strings.Count(string(data), once); n != 1 {
+			t.Errorf("main.py has %q %d times, want once", once, n)
+		}
+	}
+}
+
+func TestIncrementalAddService_WebhookWithoutLifespanYield(t *testing.T) {
+	t.Parallel()
+
+	dir, cfg := legacyProject(t)
+	mainPath := filepath.Join(dir, "app", "main.py")
+	data, err := os.ReadFile(mainPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A lifespan rewritten by hand, which the drain hook can't be placed in
+	custom := strings.Replace(string(data), "    yield\n", "    try:\n        yield\n    finally:\n        pass\n", 1)
+	custom = strings.Replace(custom, "        yield\n", "        yield  # app runs\n", 1)
+	if err := os.WriteFile(mainPath, []byte(custom), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	hook := config.Service{
+		Name:        "hook",
+		Type:        "webhook",
+		Description: "Hook",
+		Prompt:      ".claude/agents/hook.md",
+		WebhookPath: "/webhook/hook",
+		InputSchema: config.Schema{Fields: []config.Field{}},
+	}
+	cfg.Services = append(cfg.Services, hook)
+	if err := IncrementalAddService(cfg, &hook, dir); err != nil {
+		t.Fatalf("IncrementalAddService: %v", err)
+	}
+	data, _ = os.ReadFile(mainPath)
+	if strings.Contains(string(data), "lifecycle.draining") {
+		t.Error("the handler checks lifecycle.draining although lifespan never drains")
+	}
+}
+
+func TestIncrementalAddService_LegacyStreaming(t *testing.T) {
+	t.Parallel()
+
+	dir, cfg := legacyProject(t)
+	svc := config.Service{
+		Name:        "live",
+		Type:        "streaming",
+		Description: "Live",
+		Prompt:      ".claude/agents/live.md",
+		APIPath:     "/api/live",
+		InputSchema: config.Schema{Fields: []config.Field{}},
+	}
+	cfg.Services = append(cfg.Services, svc)
+	if err := IncrementalAddService(cfg, &svc, dir); err != nil {
+		t.Fatalf("IncrementalAddService: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "app", "main.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "executor.stream_execute(payload.model_dump(), request_id)") {
+		t.Error("the SSE handler does not stream from stream_execute")
+	}
+	if strings.Contains(string(data), "streams.start(") {
+		t.Error("the SSE handler needs stream_events, which app/agent.py lacks")
+	}
+
+	// Structured events need the newer agent.py
+	for _, streaming := range []config.StreamingConfig{
+		{Format: "json"},
+		{Framing: config.FramingNDJSON},
+		{WebSocket: true},
+	} {
+		dir, cfg := legacyProject(t)
+		svc := svc
+		svc.Streaming = &streaming
+		cfg.Services = append(cfg.Services, svc)
+		err := IncrementalAddService(cfg, &svc, dir)
+		if err == nil || !strings.Contains(err.Error(), "predates structured streaming events") {
+			t.Errorf("streaming %+v: got error %v, want agent.py to be too old", streaming, err)
+		}
+	}
+}
+
+// Not parallel: undo keeps the current snapshot in package state
+func TestIncrementalAddService_UndoRemovesRuntimeModules(t *testing.T) {
+	dir, cfg := legacyProject(t)
+	hook := config.Service{
+		Name:        "hook",
+		Type:        "webhook",
+		Description: "Hook",
+		Prompt:      ".claude/agents/hook.md",
+		WebhookPath: "/webhook/hook",
+		InputSchema: config.Schema{Fields: []config.Field{}},
+	}
+	cfg.Services = append(cfg.Services, hook)
+
+	if err := undo.Begin(dir, "add"); err != nil {
+		t.Fatal(err)
+	}
+	err := IncrementalAddService(cfg, &hook, dir)
+	if _, endErr := undo.End(); endErr != nil {
+		t.Fatal(endErr)
+	}
+	if err != nil {
+		t.Fatalf("IncrementalAddService: %v", err)
+	}
+	for _, mod := range runtimeModules {
+		if _, err := os.Stat(filepath.Join(dir, "app", mod.name+".py")); err != nil {
+			t.Errorf("app/%s.py was not generated: %v", mod.name, err)
+		}
+	}
+
+	if _, err := undo.Restore(dir); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	for _, mod := range runtimeModules {
+		if _, err := os.Stat(filepath.Join(dir, "app", mod.name+".py")); !os.IsNotExist(err) {
+			t.Errorf("app/%s.py is still there after undo", mod.name)
+		}
+	}
+	want, _ := os.ReadFile(filepath.Join("testdata", "legacy", "app", "main.py"))
+	if got, _ := os.ReadFile(filepath.Join(dir, "app", "main.py")); string(got) != string(want) {
+		t.Error("main.py was not restored")
+	}
+}
@@ -0,0 +1,128 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/undo"
+)
+
+// ManifestFile lists the files the last build produced, relative to the project root
+const ManifestFile = ".datagen/manifest.json"
+
+// Manifest records what GenerateProject wrote (or found current) in a project
+type Manifest struct {
+	Files []string `json:"files"`
+}
+
+// ReadManifest reads dir's build manifest. The error satisfies
+// os.IsNotExist when the project was built before manifests were written.
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ManifestFile, err)
+	}
+	return &m, nil
+}
+
+func writeManifest(dir string, m *Manifest) error {
+	slices.Sort(m.Files)
+	m.Files = slices.Compact(m.Files)
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	path := filepath.Join(dir, ManifestFile)
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		// Leave a rebuild that changed nothing out of the undo history
+		return nil
+	}
+	if err := undo.Save(path); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// addToManifest records files regenerated on their own (GenerateFiles) in an
+// existing manifest; projects without one are left alone
+func addToManifest(dir string, files []string) error {
+	m, err := ReadManifest(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if entry, ok := findGeneratedFile(f); ok && entry.dir {
+			paths, err := stagedFiles(dir, f)
+			if err != nil {
+				return err
+			}
+			m.Files = append(m.Files, paths...)
+			continue
+		}
+		if fileExists(filepath.Join(dir, f)) {
+			m.Files = append(m.Files, f)
+		}
+	}
+	return writeManifest(dir, m)
+}
+
+// RequiredFiles returns the files any deploy of the project in dir needs: the
+// app and packaging files its last build produced, per its manifest
+// (requirements.txt or pyproject.toml, app/static/playground.html only with
+// [server] playground). Platforms add the files they build from, such as the
+// Dockerfile.
+func RequiredFiles(dir string) ([]string, error) {
+	m, err := ReadManifest(dir)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s has no build manifest (%s); run 'datagen build' to write it", dir, ManifestFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, path := range m.Files {
+		if f, ok := manifestEntry(path); ok && f.runtime {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// manifestEntry returns the generatedFiles entry that produced path, matching
+// files under dir entries to their directory
+func manifestEntry(path string) (*generatedFile, bool) {
+	for i := range generatedFiles {
+		f := &generatedFiles[i]
+		if f.path == path || (f.dir && strings.HasPrefix(path, f.path+"/")) {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// MissingFiles returns the files in dir that are not there, in order
+func MissingFiles(dir string, files []string) []string {
+	var missing []string
+	for _, f := range files {
+		if !fileExists(filepath.Join(dir, f)) {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
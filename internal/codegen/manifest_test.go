@@ -0,0 +1,76 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+func TestRequiredFiles(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	if _, err := RequiredFiles(outDir); err == nil {
+		t.Fatalf("RequiredFiles() without a build: error = nil, want a missing manifest error")
+	}
+
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		PythonPackaging:  config.PackagingUV,
+		Services: []config.Service{{
+			Name:        "chat",
+			Type:        "api",
+			Prompt:      ".claude/agents/chat.md",
+			APIPath:     "/api/chat",
+			InputSchema: config.Schema{Fields: []config.Field{}},
+		}},
+	}
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	m, err := ReadManifest(outDir)
+	if err != nil {
+		t.Fatalf("ReadManifest: %v", err)
+	}
+	for _, want := range []string{"app/main.py", "pyproject.toml", "Dockerfile", "examples/chat.json", ".gitignore"} {
+		if !slices.Contains(m.Files, want) {
+			t.Errorf("manifest %v is missing %s", m.Files, want)
+		}
+	}
+
+	files, err := RequiredFiles(outDir)
+	if err != nil {
+		t.Fatalf("RequiredFiles: %v", err)
+	}
+	for _, want := range []string{"app/main.py", "app/streams.py", "pyproject.toml", MetadataFile} {
+		if !slices.Contains(files, want) {
+			t.Errorf("RequiredFiles() = %v, want %s", files, want)
+		}
+	}
+	// Packaging, platform and docs files the build did not produce or a
+	// deploy does not read are not required
+	for _, unwanted := range []string{"requirements.txt", "app/static/playground.html", "Dockerfile", "README.md", "examples/chat.json"} {
+		if slices.Contains(files, unwanted) {
+			t.Errorf("RequiredFiles() = %v, should not require %s", files, unwanted)
+		}
+	}
+
+	if err := os.Remove(filepath.Join(outDir, "pyproject.toml")); err != nil {
+		t.Fatal(err)
+	}
+	if missing := MissingFiles(outDir, files); !slices.Equal(missing, []string{"pyproject.toml"}) {
+		t.Fatalf("MissingFiles() = %v, want [pyproject.toml]", missing)
+	}
+
+	// Regenerating a single file keeps the manifest
+	if _, err := GenerateFiles(cfg, outDir, []string{"pyproject.toml"}); err != nil {
+		t.Fatalf("GenerateFiles: %v", err)
+	}
+	if m, err := ReadManifest(outDir); err != nil || !slices.Contains(m.Files, "pyproject.toml") || !slices.Contains(m.Files, "app/main.py") {
+		t.Fatalf("manifest after GenerateFiles = %+v, %v", m, err)
+	}
+}
@@ -0,0 +1,102 @@
+package codegen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/version"
+)
+
+// MetadataFile is where generation metadata is written, relative to the project root.
+// The generated app serves it from its /version endpoint.
+const MetadataFile = "app/metadata.json"
+
+// ProjectMetadata identifies the CLI, templates, and config a project was generated from
+type ProjectMetadata struct {
+	CLIVersion   string            `json:"cli_version"`
+	TemplateHash string            `json:"template_hash"`
+	Services     map[string]string `json:"services"`
+}
+
+// BuildProjectMetadata computes generation metadata for cfg
+func BuildProjectMetadata(cfg *config.DatagenConfig) (*ProjectMetadata, error) {
+	templateHash, err := TemplateHash()
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &ProjectMetadata{
+		CLIVersion:   version.Version,
+		TemplateHash: templateHash,
+		Services:     make(map[string]string, len(cfg.Services)),
+	}
+	for i := range cfg.Services {
+		hash, err := ServiceConfigHash(&cfg.Services[i])
+		if err != nil {
+			return nil, err
+		}
+		meta.Services[cfg.Services[i].Name] = hash
+	}
+	return meta, nil
+}
+
+// TemplateHash returns a SHA-256 over the embedded templates, in path order
+func TemplateHash() (string, error) {
+	var paths []string
+	err := fs.WalkDir(templatesFS, "templates", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(paths)
+
+	h := sha256.New()
+	for _, path := range paths {
+		data, err := templatesFS.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte(path))
+		h.Write([]byte{0})
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ServiceConfigHash returns a SHA-256 over the service's configuration
+func ServiceConfigHash(svc *config.Service) (string, error) {
+	data, err := json.Marshal(svc)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func generateMetadataJSON(cfg *config.DatagenConfig, outputDir string) error {
+	meta, err := BuildProjectMetadata(cfg)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	return os.WriteFile(filepath.Join(outputDir, MetadataFile), data, 0644)
+}
@@ -0,0 +1,86 @@
+package codegen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+func TestGenerateProject_WritesVersionMetadata(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{
+			{
+				Name:        "chat",
+				Type:        "api",
+				Description: "Chat API",
+				Prompt:      ".claude/agents/chat.md",
+				APIPath:     "/api/chat",
+				InputSchema: config.Schema{Fields: []config.Field{}},
+			},
+		},
+	}
+
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, MetadataFile))
+	if err != nil {
+		t.Fatalf("read metadata.json: %v", err)
+	}
+	var meta ProjectMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		t.Fatalf("unmarshal metadata.json: %v", err)
+	}
+
+	wantTemplateHash, err := TemplateHash()
+	if err != nil {
+		t.Fatalf("TemplateHash() error = %v", err)
+	}
+	if meta.TemplateHash != wantTemplateHash {
+		t.Fatalf("template_hash = %q, want %q", meta.TemplateHash, wantTemplateHash)
+	}
+	wantServiceHash, err := ServiceConfigHash(&cfg.Services[0])
+	if err != nil {
+		t.Fatalf("ServiceConfigHash() error = %v", err)
+	}
+	if meta.Services["chat"] != wantServiceHash {
+		t.Fatalf("services[chat] = %q, want %q", meta.Services["chat"], wantServiceHash)
+	}
+
+	mainPy, err := os.ReadFile(filepath.Join(outDir, "app", "main.py"))
+	if err != nil {
+		t.Fatalf("read main.py: %v", err)
+	}
+	if !strings.Contains(string(mainPy), `@app.get("/version")`) {
+		t.Fatalf("expected main.py to expose /version")
+	}
+}
+
+func TestServiceConfigHash_ChangesWithConfig(t *testing.T) {
+	t.Parallel()
+
+	svc := config.Service{Name: "chat", Type: "api", APIPath: "/api/chat"}
+	before, err := ServiceConfigHash(&svc)
+	if err != nil {
+		t.Fatalf("ServiceConfigHash() error = %v", err)
+	}
+
+	svc.APIPath = "/api/chat/v2"
+	after, err := ServiceConfigHash(&svc)
+	if err != nil {
+		t.Fatalf("ServiceConfigHash() error = %v", err)
+	}
+	if before == after {
+		t.Fatalf("ServiceConfigHash() unchanged after editing api_path")
+	}
+}
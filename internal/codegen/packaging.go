@@ -0,0 +1,233 @@
+package codegen
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/output"
+)
+
+// dependencyGroup is a commented block of requirements
+type dependencyGroup struct {
+	comment  string
+	packages []Requirement
+}
+
+// Requirement is one package the generated runtime depends on
+type Requirement struct {
+	Name   string // PyPI project name, "uvicorn"
+	Extras string // "[standard]"
+	Range  string // datagen's default range, "~=0.32.0"
+	Pin    string // exact version from [dependencies]
+}
+
+// String returns the requirement as written to requirements.txt: pinned with
+// == when datagen.toml pins it, else datagen's default range
+func (r Requirement) String() string {
+	if r.Pin != "" {
+		return r.Name + r.Extras + "==" + r.Pin
+	}
+	return r.Name + r.Extras + r.Range
+}
+
+// Version returns the pinned version, or the lowest the default range allows
+func (r Requirement) Version() string {
+	if r.Pin != "" {
+		return r.Pin
+	}
+	return strings.TrimLeft(r.Range, "~=<>!")
+}
+
+// parseRequirement splits "uvicorn[standard]~=0.32.0" into its parts
+func parseRequirement(spec string) Requirement {
+	i := strings.IndexAny(spec, "[~=<>!")
+	if i < 0 {
+		return Requirement{Name: spec}
+	}
+	r := Requirement{Name: spec[:i]}
+	rest := spec[i:]
+	if strings.HasPrefix(rest, "[") {
+		end := strings.Index(rest, "]") + 1
+		r.Extras, rest = rest[:end], rest[end:]
+	}
+	r.Range = rest
+	return r
+}
+
+// pythonDependencies lists the packages the generated runtime needs, grouped
+// as they appear in requirements.txt and pyproject.toml
+func pythonDependencies(cfg *config.DatagenConfig) []dependencyGroup {
+	group := func(comment string, specs ...string) dependencyGroup {
+		g := dependencyGroup{comment: comment}
+		for _, spec := range specs {
+			r := parseRequirement(spec)
+			r.Pin = cfg.Dependencies[r.Name]
+			g.packages = append(g.packages, r)
+		}
+		return g
+	}
+	groups := []dependencyGroup{
+		group("FastAPI and server", "fastapi~=0.115.0", "uvicorn[standard]~=0.32.0"),
+		group("Anthropic and agent SDK", "anthropic~=0.39.0", "claude-agent-sdk~=0.1.0"),
+		group("DataGen SDK", "datagen-python-sdk~=0.1.0"),
+		group("HTTP client", "httpx~=0.27.0"),
+		group("Data validation", "pydantic~=2.10.0", "pydantic-settings~=2.6.0"),
+		group("Markdown parsing", "python-frontmatter~=1.1.0", "pyyaml~=6.0.2"),
+	}
+	if cfg.UsesHTTP2() {
+		groups = append(groups, group("HTTP/2 server", "hypercorn[h2]~=0.17.0"))
+	}
+	if cfg.UsesRedis() {
+		groups = append(groups, group("Shared rate limit and idempotency state", "redis~=5.2.0"))
+	}
+	if cfg.UsesCapture() && cfg.Capture.S3Bucket != "" {
+		groups = append(groups, group("Uploading captured requests to S3", "boto3~=1.35.0"))
+	}
+	return groups
+}
+
+// Requirements returns every package the project generated from cfg depends
+// on, with the pins from datagen.toml applied
+func Requirements(cfg *config.DatagenConfig) []Requirement {
+	var reqs []Requirement
+	for _, g := range pythonDependencies(cfg) {
+		reqs = append(reqs, g.packages...)
+	}
+	return reqs
+}
+
+// DependencyFile returns the file that lists the generated project's
+// dependencies: requirements.txt for pip, else pyproject.toml
+func DependencyFile(cfg *config.DatagenConfig) string {
+	if usesPip(cfg) {
+		return "requirements.txt"
+	}
+	return "pyproject.toml"
+}
+
+func usesPip(cfg *config.DatagenConfig) bool {
+	return cfg.Packaging() == config.PackagingPip
+}
+
+func usesPyproject(cfg *config.DatagenConfig) bool {
+	return !usesPip(cfg)
+}
+
+func generateRequirementsTxt(cfg *config.DatagenConfig, outputDir string) error {
+	if !usesPip(cfg) {
+		return nil
+	}
+	var blocks []string
+	for _, g := range pythonDependencies(cfg) {
+		block := "# " + g.comment + "\n"
+		for _, pkg := range g.packages {
+			block += pkg.String() + "\n"
+		}
+		blocks = append(blocks, block)
+	}
+	content := strings.Join(blocks, "\n")
+	return os.WriteFile(filepath.Join(outputDir, "requirements.txt"), []byte(content), 0644)
+}
+
+// projectNamePattern matches characters not allowed in a Python project name
+var projectNamePattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// pyprojectName derives the [project] name from the output directory
+func pyprojectName(outputDir string) string {
+	abs, err := filepath.Abs(outputDir)
+	if err != nil {
+		abs = outputDir
+	}
+	name := strings.Trim(projectNamePattern.ReplaceAllString(strings.ToLower(filepath.Base(abs)), "-"), "-")
+	if name == "" {
+		return "datagen-app"
+	}
+	return name
+}
+
+func generatePyprojectToml(cfg *config.DatagenConfig, outputDir string) error {
+	if usesPip(cfg) {
+		return nil
+	}
+	content := fmt.Sprintf(`[project]
+name = "%s"
+version = "0.1.0"
+description = "Claude agent services generated by datagen"
+requires-python = ">=%s"
+dependencies = [
+`, pyprojectName(outputDir), cfg.PythonVersion())
+	for i, g := range pythonDependencies(cfg) {
+		if i > 0 {
+			content += "\n"
+		}
+		content += "    # " + g.comment + "\n"
+		for _, pkg := range g.packages {
+			content += fmt.Sprintf("    %q,\n", pkg.String())
+		}
+	}
+	content += "]\n"
+	switch cfg.Packaging() {
+	case config.PackagingUV:
+		content += `
+# An application, not a library: uv installs only the dependencies
+[tool.uv]
+package = false
+`
+	case config.PackagingPoetry:
+		content += `
+# An application, not a library: poetry installs only the dependencies
+[tool.poetry]
+package-mode = false
+`
+	}
+	return os.WriteFile(filepath.Join(outputDir, "pyproject.toml"), []byte(content), 0644)
+}
+
+// LockFile returns the lockfile the packaging tool writes, or "" for pip
+func LockFile(cfg *config.DatagenConfig) string {
+	switch cfg.Packaging() {
+	case config.PackagingUV:
+		return "uv.lock"
+	case config.PackagingPoetry:
+		return "poetry.lock"
+	default:
+		return ""
+	}
+}
+
+// InstallCommand returns the command that installs the generated project's
+// dependencies locally
+func InstallCommand(cfg *config.DatagenConfig) string {
+	switch cfg.Packaging() {
+	case config.PackagingUV:
+		return "uv sync"
+	case config.PackagingPoetry:
+		return "poetry install"
+	default:
+		return "pip install -r requirements.txt"
+	}
+}
+
+// LockDependencies runs uv lock or poetry lock in outputDir so images install
+// exactly the versions resolved now. It does nothing with pip.
+func LockDependencies(cfg *config.DatagenConfig, outputDir string) error {
+	tool := cfg.Packaging()
+	if tool == config.PackagingPip {
+		return nil
+	}
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return fmt.Errorf("%s is not installed: %w", tool, err)
+	}
+	cmd := exec.Command(path, "lock")
+	cmd.Dir = outputDir
+	if out, err := output.CombinedOutput(cmd); err != nil {
+		return fmt.Errorf("%s lock failed: %w\n%s", tool, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
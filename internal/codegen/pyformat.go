@@ -0,0 +1,151 @@
+package codegen
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+// normalizePython tidies the whitespace text/template leaves behind in
+// generated Python: trailing whitespace, runs of blank lines, blank lines
+// opening a block, and the two blank lines PEP 8 wants around top-level
+// definitions. Lines inside multi-line strings are left alone. It is
+// deterministic, so rebuilds don't rewrite unchanged files.
+func normalizePython(src string) string {
+	lines := strings.Split(src, "\n")
+	stmts := parsePyStatements(lines)
+
+	// Lines continuing a multi-line string keep their exact content, except
+	// in docstrings, whose trailing whitespace is insignificant
+	verbatim := map[int]bool{}
+	for _, s := range stmts {
+		if strings.Contains(s.code, "\n") && !isPyDocstring(s.code) {
+			for i := s.start + 1; i < s.end; i++ {
+				verbatim[i] = true
+			}
+		}
+	}
+	for i, line := range lines {
+		// The line opening a multi-line string ends inside it too
+		if !verbatim[i] && !verbatim[i+1] {
+			lines[i] = strings.TrimRight(line, " \t")
+		}
+	}
+
+	// blanksBefore[i] is how many blank lines line i should follow; -1 keeps
+	// the count found in the source, up to the limit for its indentation
+	blanksBefore := make([]int, len(lines))
+	for i := range blanksBefore {
+		blanksBefore[i] = -1
+	}
+	for k, s := range stmts {
+		if !strings.Contains(s.code, "\n") {
+			// No blank lines inside brackets
+			for i := s.start + 1; i < s.end; i++ {
+				blanksBefore[i] = 0
+			}
+		}
+		first := s.start
+		for first > 0 && strings.HasPrefix(strings.TrimSpace(lines[first-1]), "#") && !verbatim[first-1] {
+			first--
+		}
+		switch {
+		case k > 0 && strings.HasSuffix(stmts[k-1].code, ":") && len(s.indent) > len(stmts[k-1].indent):
+			// First statement of a block
+			blanksBefore[first] = 0
+		case k > 0 && strings.HasPrefix(stmts[k-1].code, "@"):
+			// Decorated definition
+			blanksBefore[first] = 0
+		case s.indent == "" && isPyDefinition(lines[s.start]):
+			blanksBefore[first] = 2
+		case s.indent == "" && k > 0 && stmts[k-1].indent != "" && pyDefinitionAt(lines, stmts, k):
+			// Top-level code after a definition's body
+			blanksBefore[first] = 2
+		}
+	}
+
+	var out []string
+	blank := 0
+	for i, line := range lines {
+		if line == "" && !verbatim[i] {
+			blank++
+			continue
+		}
+		n := blank
+		switch {
+		case len(out) == 0:
+			n = 0
+		case blanksBefore[i] >= 0:
+			n = blanksBefore[i]
+		case strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t"):
+			n = min(n, 1)
+		default:
+			n = min(n, 2)
+		}
+		for ; n > 0; n-- {
+			out = append(out, "")
+		}
+		out = append(out, line)
+		blank = 0
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+func isPyDocstring(code string) bool {
+	for _, q := range []string{`"""`, "'''"} {
+		if len(code) >= 6 && strings.HasPrefix(code, q) && strings.HasSuffix(code, q) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPyDefinition reports whether line starts a def, class or decorator
+func isPyDefinition(line string) bool {
+	line = strings.TrimSpace(line)
+	for _, prefix := range []string{"def ", "async def ", "class ", "@"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// pyDefinitionAt reports whether the top-level statement before stmts[k]
+// opens the block stmts[k] follows, i.e. stmts[k] ends a def or class
+func pyDefinitionAt(lines []string, stmts []pyStatement, k int) bool {
+	for j := k - 1; j >= 0; j-- {
+		if stmts[j].indent == "" {
+			return isPyDefinition(lines[stmts[j].start])
+		}
+	}
+	return false
+}
+
+// Python formatters python_formatter can name
+var pythonFormatters = map[string][]string{
+	config.FormatterRuff:  {"ruff", "format", "--quiet"},
+	config.FormatterBlack: {"black", "--quiet"},
+}
+
+// FormatterAvailable reports whether cfg's python_formatter is installed
+func FormatterAvailable(cfg *config.DatagenConfig) bool {
+	args, ok := pythonFormatters[cfg.PythonFormatter]
+	if !ok {
+		return false
+	}
+	_, err := exec.LookPath(args[0])
+	return err == nil
+}
+
+// formatPythonDir runs cfg's python_formatter over the Python files in dir
+func formatPythonDir(cfg *config.DatagenConfig, dir string) error {
+	args := pythonFormatters[cfg.PythonFormatter]
+	cmd := exec.Command(args[0], append(args[1:], dir)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w\n%s", strings.Join(args[:len(args)-1], " "), err, out)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package codegen
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+func TestNormalizePython(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "trailing whitespace and blank runs",
+			src:  "import os   \n\n\n\n\nx = 1\n\n\n\n",
+			want: "import os\n\n\nx = 1\n",
+		},
+		{
+			name: "definitions get two blank lines",
+			src:  "import os\ndef f():\n    pass\ndefaults = 1\n# About g\n@dec\n\ndef g():\n    pass\n",
+			want: "import os\n\n\ndef f():\n    pass\n\n\ndefaults = 1\n\n\n# About g\n@dec\ndef g():\n    pass\n",
+		},
+		{
+			name: "blank lines opening blocks and inside brackets",
+			src:  "def f(\n\n    a,\n):\n\n    if a:\n\n\n        return [\n\n            1,\n        ]\n\n\n\n    return a\n",
+			want: "def f(\n    a,\n):\n    if a:\n        return [\n            1,\n        ]\n\n    return a\n",
+		},
+		{
+			name: "multi-line strings",
+			src:  "def f():\n    \"\"\"\n    Doc   \n\n    \"\"\"\n    return '''a  \n\n\n\nb'''\n",
+			want: "def f():\n    \"\"\"\n    Doc\n\n    \"\"\"\n    return '''a  \n\n\n\nb'''\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normalizePython(tt.src)
+			if got != tt.want {
+				t.Fatalf("normalizePython() =\n%s\nwant:\n%s", got, tt.want)
+			}
+			if again := normalizePython(got); again != got {
+				t.Fatalf("normalizePython() is not idempotent:\n%s", again)
+			}
+		})
+	}
+}
+
+// TestGeneratedPython_LintClean checks the generated project, with and
+// without a service added incrementally, for the whitespace normalizePython
+// guarantees, and runs ruff check over it when ruff is installed
+func TestGeneratedPython_LintClean(t *testing.T) {
+	full := goldenConfig()
+	minimal := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services:         full.Services[:1],
+	}
+
+	fullDir := t.TempDir()
+	if _, err := GenerateProject(full, fullDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	addedDir := t.TempDir()
+	if _, err := GenerateProject(minimal, addedDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	if err := IncrementalAddService(full, &full.Services[1], addedDir); err != nil {
+		t.Fatalf("IncrementalAddService: %v", err)
+	}
+
+	for _, dir := range []string{fullDir, addedDir} {
+		files, err := filepath.Glob(filepath.Join(dir, "app", "*.py"))
+		if err != nil {
+			t.Fatalf("Glob: %v", err)
+		}
+		for _, f := range files {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				t.Fatalf("read %s: %v", f, err)
+			}
+			src := string(data)
+			if strings.Contains(src, "\n\n\n\n") || strings.HasSuffix(src, "\n\n") {
+				t.Errorf("%s has more than two consecutive blank lines", filepath.Base(f))
+			}
+			for i, line := range strings.Split(src, "\n") {
+				if line != strings.TrimRight(line, " \t") {
+					t.Errorf("%s:%d has trailing whitespace", filepath.Base(f), i+1)
+				}
+			}
+		}
+	}
+
+	if _, err := exec.LookPath("ruff"); err != nil {
+		t.Skip("ruff not installed")
+	}
+	for _, dir := range []string{fullDir, addedDir} {
+		out, err := exec.Command("ruff", "check", "--isolated", "--no-cache", filepath.Join(dir, "app")).CombinedOutput()
+		if err != nil {
+			t.Errorf("ruff check: %v\n%s", err, out)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package codegen
+
+import "strings"
+
+// A small structural view of Python source, enough to find the statements
+// and top-level definitions datagen edits in main.py and models.py without
+// depending on how a formatter such as black has laid them out: calls split
+// over several lines, magic trailing commas, changed blank lines and
+// indentation all parse to the same statements.
+
+// pyStatement is one logical Python statement: the physical lines it spans,
+// joined across brackets, triple-quoted strings and backslash continuations.
+// A compound statement's header ("async def f(...):") is a statement of its
+// own; its body follows as more deeply indented statements.
+type pyStatement struct {
+	start, end int // first line and one past the last line
+	indent     string
+	// code is the statement with comments and the whitespace outside string
+	// literals removed, so `load_agent( "a",\n "b",\n)` reads load_agent("a","b",)
+	code string
+}
+
+// parsePyStatements splits lines into logical statements; blank and
+// comment-only lines belong to none
+func parsePyStatements(lines []string) []pyStatement {
+	var (
+		stmts []pyStatement
+		cur   *pyStatement
+		code  strings.Builder
+		depth int
+		quote string // delimiter of the open string literal: ", ', """ or '''
+	)
+	for i, line := range lines {
+		if cur == nil {
+			t := strings.TrimSpace(line)
+			if t == "" || strings.HasPrefix(t, "#") {
+				continue
+			}
+			cur = &pyStatement{start: i, indent: indentOf(line)}
+			code.Reset()
+		}
+		continued := false
+		for j := 0; j < len(line); j++ {
+			c := line[j]
+			if quote != "" {
+				if c == '\\' && j+1 < len(line) {
+					code.WriteString(line[j : j+2])
+					j++
+					continue
+				}
+				if strings.HasPrefix(line[j:], quote) {
+					code.WriteString(quote)
+					j += len(quote) - 1
+					quote = ""
+					continue
+				}
+				code.WriteByte(c)
+				continue
+			}
+			switch c {
+			case '#':
+				j = len(line)
+			case '"', '\'':
+				quote = string(c)
+				if triple := strings.Repeat(quote, 3); strings.HasPrefix(line[j:], triple) {
+					quote = triple
+				}
+				code.WriteString(quote)
+				j += len(quote) - 1
+			case '(', '[', '{':
+				depth++
+				code.WriteByte(c)
+			case ')', ']', '}':
+				if depth > 0 {
+					depth--
+				}
+				code.WriteByte(c)
+			case ' ', '\t':
+			case '\\':
+				if j == len(line)-1 {
+					continued = true
+				} else {
+					code.WriteByte(c)
+				}
+			default:
+				code.WriteByte(c)
+			}
+		}
+		if len(quote) == 3 {
+			code.WriteByte('\n')
+			continue
+		}
+		// A single-quoted string cannot span lines; recover from malformed input
+		quote = ""
+		if depth > 0 || continued {
+			continue
+		}
+		cur.end = i + 1
+		cur.code = code.String()
+		stmts = append(stmts, *cur)
+		cur = nil
+	}
+	if cur != nil {
+		cur.end = len(lines)
+		cur.code = code.String()
+		stmts = append(stmts, *cur)
+	}
+	return stmts
+}
+
+// pyBlockEnd returns the index of the first statement after stmts[k] that is
+// not part of its body
+func pyBlockEnd(stmts []pyStatement, k int) int {
+	for i := k + 1; i < len(stmts); i++ {
+		if len(stmts[i].indent) <= len(stmts[k].indent) {
+			return i
+		}
+	}
+	return len(stmts)
+}
+
+// pyDefinition returns the line range of the top-level def or class named
+// name, including its decorators and body
+func pyDefinition(stmts []pyStatement, name string) (start, end int, ok bool) {
+	for k, s := range stmts {
+		if s.indent != "" {
+			continue
+		}
+		if !strings.HasPrefix(s.code, "def"+name+"(") && !strings.HasPrefix(s.code, "asyncdef"+name+"(") &&
+			!strings.HasPrefix(s.code, "class"+name+"(") && !strings.HasPrefix(s.code, "class"+name+":") {
+			continue
+		}
+		first := k
+		for first > 0 && stmts[first-1].indent == "" && strings.HasPrefix(stmts[first-1].code, "@") {
+			first--
+		}
+		last := pyBlockEnd(stmts, k) - 1
+		return stmts[first].start, stmts[last].end, true
+	}
+	return 0, 0, false
+}
+
+// removePyLines drops lines [start, end) together with the blank lines after them
+func removePyLines(lines []string, start, end int) []string {
+	for end < len(lines) && strings.TrimSpace(lines[end]) == "" {
+		end++
+	}
+	return append(lines[:start:start], lines[end:]...)
+}
+
+// isAgentLoading matches the statements generated between the agent loading
+// markers: agent_executors[...] = load_agent(...) and locales.load(...)
+func isAgentLoading(s pyStatement) bool {
+	return (strings.HasPrefix(s.code, "agent_executors[") && strings.Contains(s.code, "=load_agent(")) ||
+		strings.HasPrefix(s.code, "locales.load(")
+}
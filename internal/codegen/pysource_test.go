@@ -0,0 +1,151 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+func TestParsePyStatements(t *testing.T) {
+	src := `async def lifespan(app):
+    """Docstring with a # hash
+    over two lines."""
+    # comment
+    agent_executors["a"] = load_agent(
+        "a",
+        ".claude/agents/a.md",  # trailing comment
+    )
+    x = [1,
+         2] + \
+        [3]
+    yield
+`
+	stmts := parsePyStatements(strings.Split(src, "\n"))
+	want := []pyStatement{
+		{start: 0, end: 1, indent: "", code: "asyncdeflifespan(app):"},
+		{start: 1, end: 3, indent: "    ", code: `"""Docstring with a # hash` + "\n" + `    over two lines."""`},
+		{start: 4, end: 8, indent: "    ", code: `agent_executors["a"]=load_agent("a",".claude/agents/a.md",)`},
+		{start: 8, end: 11, indent: "    ", code: "x=[1,2]+[3]"},
+		{start: 11, end: 12, indent: "    ", code: "yield"},
+	}
+	if len(stmts) != len(want) {
+		t.Fatalf("parsePyStatements() = %+v, want %d statements", stmts, len(want))
+	}
+	for i := range want {
+		if stmts[i] != want[i] {
+			t.Errorf("statement %d = %+v, want %+v", i, stmts[i], want[i])
+		}
+	}
+	if end := pyBlockEnd(stmts, 0); end != len(stmts) {
+		t.Errorf("pyBlockEnd(lifespan) = %d, want %d", end, len(stmts))
+	}
+}
+
+// TestIncrementalEdits_FormattedSource checks add, move and remove on a
+// main.py reformatted the way black does it, with the markers gone
+func TestIncrementalEdits_FormattedSource(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	chat := config.Service{Name: "chat", Type: "api", Prompt: ".claude/agents/chat.md", APIPath: "/chat"}
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services:         []config.Service{chat},
+	}
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	mainPath := filepath.Join(outDir, "app", "main.py")
+	stripMarkers(t, mainPath)
+	stripMarkers(t, filepath.Join(outDir, "app", "models.py"))
+	data, err := os.ReadFile(mainPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	wrapped := "    agent_executors[\"chat\"] = load_agent(\n        \"chat\",\n        \".claude/agents/chat.md\",\n    )"
+	formatted := strings.Replace(string(data), `    agent_executors["chat"] = load_agent("chat", ".claude/agents/chat.md")`, wrapped, 1)
+	formatted = strings.Replace(formatted, `"services": ["chat"],`, "\"services\": [\n            \"chat\",\n        ],", 1)
+	if formatted == string(data) {
+		t.Fatal("main.py does not contain the expected agent loading")
+	}
+	if err := os.WriteFile(mainPath, []byte(formatted), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	summarize := config.Service{Name: "summarize", Type: "api", Prompt: ".claude/agents/summarize.md", APIPath: "/summarize"}
+	cfg.Services = append(cfg.Services, summarize)
+	if err := IncrementalAddService(cfg, &summarize, outDir); err != nil {
+		t.Fatalf("IncrementalAddService: %v", err)
+	}
+	moved := chat
+	moved.Prompt = ".claude/agents/support/chat.md"
+	cfg.Services[0] = moved
+	if err := IncrementalMovePrompt(cfg, &moved, chat.Prompt, outDir); err != nil {
+		t.Fatalf("IncrementalMovePrompt: %v", err)
+	}
+
+	data, err = os.ReadFile(mainPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	main := string(data)
+	load := strings.Index(main, "\n    agent_executors[\"summarize\"] = load_agent(\"summarize\", \".claude/agents/summarize.md\")\n")
+	if load < 0 || load < strings.Index(main, "\".claude/agents/support/chat.md\",") || load > strings.Index(main, "canary.load(agent_executors)") {
+		t.Fatalf("summarize is not loaded after chat in lifespan:\n%s", main)
+	}
+	endpoint := strings.Index(main, "# API endpoint: summarize")
+	if endpoint < strings.Index(main, "async def chat_handler(") || endpoint > strings.Index(main, "# Health check") {
+		t.Fatalf("summarize endpoint is not after chat and before /health:\n%s", main)
+	}
+	if !strings.Contains(main, `"services": ["chat", "summarize"],`) {
+		t.Fatalf("health services list not updated:\n%s", main)
+	}
+
+	cfg.Services = []config.Service{summarize}
+	if err := IncrementalRemoveService(cfg, "chat", outDir); err != nil {
+		t.Fatalf("IncrementalRemoveService: %v", err)
+	}
+	data, err = os.ReadFile(mainPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	for _, gone := range []string{`agent_executors["chat"]`, `"chat",`, ".claude/agents/support/chat.md", "# API endpoint: chat\n", "async def chat_handler("} {
+		if strings.Contains(string(data), gone) {
+			t.Errorf("main.py still contains %q:\n%s", gone, data)
+		}
+	}
+	for _, kept := range []string{`agent_executors["summarize"]`, "async def summarize_handler(", "# Health check"} {
+		if !strings.Contains(string(data), kept) {
+			t.Errorf("main.py lost %q", kept)
+		}
+	}
+	models, err := os.ReadFile(filepath.Join(outDir, "app", "models.py"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if strings.Contains(string(models), "class ChatInput(") || !strings.Contains(string(models), "class SummarizeInput(") {
+		t.Fatalf("models.py after remove:\n%s", models)
+	}
+}
+
+func TestImportsAppModule(t *testing.T) {
+	t.Parallel()
+
+	for _, src := range []string{
+		"from app import canary, metrics, streams  # noqa: F401\n",
+		"from app import (  # noqa: F401\n    canary,\n    metrics,\n    streams,\n)\n",
+	} {
+		for _, mod := range []string{"canary", "streams"} {
+			if !importsAppModule(src, mod) {
+				t.Errorf("importsAppModule(%q, %q) = false", src, mod)
+			}
+		}
+		if importsAppModule(src, "stores") {
+			t.Errorf("importsAppModule(%q, \"stores\") = true", src)
+		}
+	}
+}
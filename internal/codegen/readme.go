@@ -0,0 +1,441 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/models"
+)
+
+// readmeBaseURL is where the README's example requests are sent
+const readmeBaseURL = "http://localhost:8000"
+
+// generateREADME writes the project README: an endpoint reference per
+// service (auth, schemas, example request and response), the environment
+// variables grouped by the services that read them, and how to run and
+// deploy the project on each platform datagen writes config for
+func generateREADME(cfg *config.DatagenConfig, outputDir string) error {
+	var b strings.Builder
+	b.WriteString("# DataGen Agent Project\n\n")
+	b.WriteString("Generated by DataGen CLI from `datagen.toml`; run `datagen build` after changing it.\n\n")
+
+	b.WriteString("## Services\n\n")
+	b.WriteString("| Service | Type | Endpoint | Auth |\n")
+	b.WriteString("|---------|------|----------|------|\n")
+	for i := range cfg.Services {
+		svc := &cfg.Services[i]
+		fmt.Fprintf(&b, "| [%s](#%s) | %s | `POST %s` | %s |\n", svc.Name, readmeAnchor(svc.Name), svc.Type, svc.GetPath(), readmeAuthSummary(svc))
+	}
+	b.WriteString("\n")
+	for i := range cfg.Services {
+		writeReadmeService(&b, &cfg.Services[i])
+	}
+
+	writeReadmeEnv(&b, cfg)
+	writeReadmeQuickStart(&b, cfg)
+	writeReadmeDeploy(&b, cfg)
+
+	b.WriteString("## API Documentation\n\n")
+	fmt.Fprintf(&b, "Once running, visit %s/docs for interactive API documentation.\n", readmeBaseURL)
+	b.WriteString("\n## Example Requests\n\n")
+	b.WriteString("`examples/` has a sample request body for each service, built from its input schema\n")
+	b.WriteString("(required fields and defaults), and scripts that send it with the service's auth headers:\n\n")
+	b.WriteString("```bash\n")
+	b.WriteString("set -a; . ./.env; set +a   # the keys the auth headers use\n")
+	if len(cfg.Services) > 0 {
+		name := cfg.Services[0].Name
+		fmt.Fprintf(&b, "sh examples/curl.sh %s\n", name)
+		fmt.Fprintf(&b, "sh examples/httpie.sh %s\n", name)
+	}
+	b.WriteString("```\n\n")
+	fmt.Fprintf(&b, "Set `BASE_URL` to send them to a deployed project instead of %s.\n", readmeBaseURL)
+	if cfg.UsesPlayground() {
+		fmt.Fprintf(&b, "\nTo try the services from a browser, open %s/playground. It builds a\n", readmeBaseURL)
+		b.WriteString("form for each service from its input schema and shows streamed replies as they arrive.\n")
+		b.WriteString("Set `PLAYGROUND_ENABLED=false` to hide it in production.\n")
+	}
+	if cfg.UsesCapture() {
+		b.WriteString("\n## Request Capture\n\n")
+		fmt.Fprintf(&b, "Each service request and its response are recorded, with secrets masked, under `%s/`.\n", cfg.CaptureDir())
+		b.WriteString("Replay them against a changed prompt and compare the responses:\n\n")
+		b.WriteString("```bash\n")
+		fmt.Fprintf(&b, "datagen replay %s/ --diff\n", cfg.CaptureDir())
+		b.WriteString("```\n\n")
+		b.WriteString("Set `CAPTURE_ENABLED=false` to stop recording.\n")
+	}
+
+	return os.WriteFile(filepath.Join(outputDir, "README.md"), []byte(b.String()), 0644)
+}
+
+// readmeAnchor returns the GitHub heading anchor for a service name
+func readmeAnchor(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, " ", "-"))
+}
+
+// readmeAuthSummary names the header svc's callers authenticate with
+func readmeAuthSummary(svc *config.Service) string {
+	switch {
+	case svc.Auth != nil && svc.Auth.Type == "api_key":
+		return fmt.Sprintf("`%s` header", authHeader(svc.Auth))
+	case svc.Auth != nil && svc.Auth.Type == "bearer_token":
+		return "Bearer token"
+	case svc.Webhook != nil && svc.Webhook.SignatureVerification == "hmac_sha256":
+		return "HMAC signature"
+	}
+	return "none"
+}
+
+func authHeader(auth *config.Auth) string {
+	if auth.Header == "" {
+		return "X-API-Key"
+	}
+	return auth.Header
+}
+
+func writeReadmeService(b *strings.Builder, svc *config.Service) {
+	fmt.Fprintf(b, "### %s\n\n", svc.Name)
+	if svc.Description != "" {
+		b.WriteString(svc.Description + "\n\n")
+	}
+	fmt.Fprintf(b, "- **Endpoint**: `POST %s`\n", svc.GetPath())
+	switch svc.Type {
+	case "webhook":
+		b.WriteString("- **Type**: webhook; answers at once and runs the agent in the background. An `Idempotency-Key` header (or `X-GitHub-Delivery`, `webhook-id`) skips redeliveries\n")
+	case "api":
+		timeout := ""
+		if svc.API != nil && svc.API.Timeout > 0 {
+			timeout = fmt.Sprintf(" (timeout %ds)", svc.API.Timeout)
+		}
+		fmt.Fprintf(b, "- **Type**: api; answers with the agent's result%s\n", timeout)
+	case "pipeline":
+		b.WriteString("- **Type**: pipeline; runs its steps in order and answers with the last step's result\n")
+	case "streaming":
+		fmt.Fprintf(b, "- **Type**: streaming; sends the agent's output as %s while it runs\n", readmeFramingName(svc))
+	}
+	switch {
+	case svc.Auth != nil && svc.Auth.Type == "api_key":
+		fmt.Fprintf(b, "- **Auth**: `%s: <%s>`, checked while `%s` is set\n", authHeader(svc.Auth), svc.Auth.EnvVar, svc.Auth.EnvVar)
+	case svc.Auth != nil && svc.Auth.Type == "bearer_token":
+		fmt.Fprintf(b, "- **Auth**: `Authorization: Bearer <%s>`, checked while `%s` is set\n", svc.Auth.EnvVar, svc.Auth.EnvVar)
+	default:
+		b.WriteString("- **Auth**: none\n")
+	}
+	if webhook := svc.Webhook; webhook != nil && webhook.SignatureVerification == "hmac_sha256" {
+		fmt.Fprintf(b, "- **Signature**: `%s` holds the HMAC-SHA256 of the body, keyed with `%s`", webhook.SignatureHeader, webhook.SecretEnv)
+		if webhook.Provider != "" {
+			fmt.Fprintf(b, " (%s's scheme)", webhook.Provider)
+		}
+		b.WriteString("\n")
+	}
+	if svc.API != nil && svc.API.RateLimitEnabled {
+		fmt.Fprintf(b, "- **Rate limit**: %d requests per minute per client; over it the service answers 429 with `Retry-After`\n", svc.API.RateLimitRPM)
+	}
+	if svc.Prompt != "" {
+		fmt.Fprintf(b, "- **Prompt**: %s\n", svc.Prompt)
+	}
+	fmt.Fprintf(b, "- **Example**: `examples/%s.json` (send it with `sh examples/curl.sh %s`)\n", svc.Name, svc.Name)
+	if svc.Type == "streaming" {
+		fmt.Fprintf(b, "- **Framing**: %s; reconnect with `Last-Event-ID` to resume (see `scripts/stream_client.py`)\n", strings.ToUpper(svc.StreamFraming()))
+		if svc.StreamWebSocket() {
+			fmt.Fprintf(b, "- **WebSocket**: %s/ws\n", svc.APIPath)
+		}
+	}
+	if svc.Type == "pipeline" {
+		b.WriteString("- **Steps**:\n")
+		for i, step := range svc.Steps {
+			parallel := ""
+			if step.Parallel {
+				parallel = " (parallel with the previous step)"
+			}
+			fmt.Fprintf(b, "  %d. %s: %s%s\n", i+1, step.Name, step.Prompt, parallel)
+		}
+	}
+	b.WriteString("\n")
+
+	b.WriteString("**Request body**\n\n")
+	writeReadmeFields(b, svc.InputSchema.Fields, "The service takes no input fields; send `{}`.")
+	if svc.OutputSchema != nil && svc.Type == "api" {
+		b.WriteString("**Response body**\n\n")
+		writeReadmeFields(b, svc.OutputSchema.Fields, "")
+	}
+
+	b.WriteString("**Example request**\n\n")
+	b.WriteString("```bash\n")
+	for _, line := range exampleCommand(svc, "curl", "examples", readmeBaseURL) {
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("```\n\n")
+	b.WriteString("```json\n")
+	b.Write(examplePayload(svc.InputSchema.Fields))
+	b.WriteString("```\n\n")
+
+	b.WriteString("**Example response**\n\n")
+	if svc.Type == "streaming" {
+		writeReadmeStream(b, svc)
+		return
+	}
+	b.WriteString("```json\n")
+	b.Write(exampleResponse(svc))
+	b.WriteString("```\n\n")
+}
+
+// writeReadmeFields writes a schema's fields as a table, or empty when it has none
+func writeReadmeFields(b *strings.Builder, fields []config.Field, empty string) {
+	if len(fields) == 0 {
+		if empty != "" {
+			b.WriteString(empty + "\n\n")
+		}
+		return
+	}
+	b.WriteString("| Field | Type | Required | Default |\n")
+	b.WriteString("|-------|------|----------|---------|\n")
+	for _, f := range fields {
+		required := "no"
+		if f.Required {
+			required = "yes"
+		}
+		def := ""
+		if f.Default != "" {
+			def = "`" + f.Default + "`"
+		}
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s |\n", f.Name, f.Type, required, def)
+	}
+	b.WriteString("\n")
+}
+
+// readmeRequestID stands in for the request ID in example responses
+const readmeRequestID = "3f1c9a2e-8b4d-4e6f-9a0b-1c2d3e4f5a6b"
+
+// exampleResponse returns the JSON body the generated app answers svc's
+// sample request with, with sample values where the agent's result goes
+func exampleResponse(svc *config.Service) []byte {
+	var body any
+	switch svc.Type {
+	case "webhook":
+		body = struct {
+			Status    string `json:"status"`
+			RequestID string `json:"request_id"`
+			Message   string `json:"message"`
+		}{"accepted", readmeRequestID, "Processing in background"}
+	case "pipeline":
+		steps := map[string]string{}
+		result := "the last step's result"
+		for _, step := range svc.Steps {
+			steps[step.Name] = "result of " + step.Name
+			result = steps[step.Name]
+		}
+		body = struct {
+			Status    string            `json:"status"`
+			RequestID string            `json:"request_id"`
+			Result    string            `json:"result"`
+			Steps     map[string]string `json:"steps"`
+		}{"completed", readmeRequestID, result, steps}
+	default:
+		if svc.OutputSchema != nil && len(svc.OutputSchema.Fields) > 0 {
+			fields := make([]config.Field, len(svc.OutputSchema.Fields))
+			for i, f := range svc.OutputSchema.Fields {
+				f.Required = true
+				fields[i] = f
+			}
+			return examplePayload(fields)
+		}
+		body = struct {
+			Status    string `json:"status"`
+			RequestID string `json:"request_id"`
+			Result    string `json:"result"`
+		}{"completed", readmeRequestID, "The agent's reply"}
+	}
+	data, _ := json.MarshalIndent(body, "", "  ")
+	return append(data, '\n')
+}
+
+// readmeFramingName describes svc's stream for the Type line
+func readmeFramingName(svc *config.Service) string {
+	if svc.StreamFraming() == config.FramingNDJSON {
+		return "newline-delimited JSON events"
+	}
+	return "server-sent events"
+}
+
+// writeReadmeStream shows the first and last events of a sample stream in
+// svc's framing and format
+func writeReadmeStream(b *strings.Builder, svc *config.Service) {
+	id := readmeRequestID
+	b.WriteString("```\n")
+	switch {
+	case svc.StreamFraming() == config.FramingNDJSON:
+		fmt.Fprintf(b, "{\"id\": \"%s:1\", \"type\": \"text\", \"text\": \"Hello\"}\n", id)
+		fmt.Fprintf(b, "{\"id\": \"%s:2\", \"type\": \"done\"}\n", id)
+	case svc.StreamFormat() == "json":
+		fmt.Fprintf(b, "id: %s:1\nevent: text\ndata: {\"type\": \"text\", \"text\": \"Hello\"}\n\n", id)
+		fmt.Fprintf(b, "id: %s:2\nevent: done\ndata: {\"type\": \"done\"}\n", id)
+	default:
+		fmt.Fprintf(b, "id: %s:1\ndata: Hello\n\n", id)
+		fmt.Fprintf(b, "id: %s:2\nevent: done\ndata: [DONE]\n", id)
+	}
+	b.WriteString("```\n\n")
+	b.WriteString("The stream's ID is in the `X-Stream-ID` response header. If the connection drops, repeat\n")
+	b.WriteString("the request with `Last-Event-ID` set to the last `id` received to get the rest of the run.\n\n")
+}
+
+// writeReadmeEnv lists the environment variables the app reads: the shared
+// ones, then each service's own
+func writeReadmeEnv(b *strings.Builder, cfg *config.DatagenConfig) {
+	b.WriteString("## Environment Variables\n\n")
+	b.WriteString("Copy `.env.example` to `.env` for local runs; set the same variables on the deployment.\n\n")
+	b.WriteString("### All services\n\n")
+	b.WriteString("| Variable | Required | Purpose |\n")
+	b.WriteString("|----------|----------|---------|\n")
+
+	var claude, datagen []string
+	for i := range cfg.Services {
+		svc := &cfg.Services[i]
+		if svc.ProviderName() == config.ProviderAnthropic {
+			claude = append(claude, svc.Name)
+		}
+		if tools := svc.Tools(); tools.SearchTools || tools.ExecuteTools || tools.ExecuteCode || tools.GetToolDetails {
+			datagen = append(datagen, svc.Name)
+		}
+	}
+	fmt.Fprintf(b, "| `%s` | %s | Anthropic API key%s |\n", cfg.ClaudeAPIKeyEnv, readmeYesNo(cfg.RequiresClaudeAPIKey()), readmeUsedBy(claude))
+	fmt.Fprintf(b, "| `%s` | %s | DataGen API key for DataGen tools%s |\n", cfg.DatagenAPIKeyEnv, readmeYesNo(cfg.RequiresDatagenAPIKey()), readmeUsedBy(datagen))
+	fmt.Fprintf(b, "| `MODEL_NAME` | no | Claude model for services that don't set one (default `%s`) |\n", models.Default)
+	b.WriteString("| `PORT` | no | Port the server listens on (default `8000`) |\n")
+	b.WriteString("| `LOG_LEVEL` | no | Log level (default `INFO`) |\n")
+	fmt.Fprintf(b, "| `STATE_STORE` | no | Where idempotency keys and rate limits are kept (default `%s`) |\n", cfg.StateStore())
+	if cfg.UsesRedis() {
+		b.WriteString("| `REDIS_URL` | yes | Redis for the state store |\n")
+	}
+	if cfg.UsesCapture() {
+		b.WriteString("| `CAPTURE_ENABLED` | no | Record requests for `datagen replay` (default `true`) |\n")
+	}
+	if cfg.UsesPlayground() {
+		b.WriteString("| `PLAYGROUND_ENABLED` | no | Serve the browser playground (default `true`) |\n")
+	}
+	b.WriteString("| `AGENT_FAKE` | no | Set to `1` to replace Claude with a deterministic fake agent for tests |\n")
+	b.WriteString("\n")
+
+	for i := range cfg.Services {
+		svc := &cfg.Services[i]
+		var rows []string
+		if svc.Auth != nil && svc.Auth.EnvVar != "" {
+			rows = append(rows, fmt.Sprintf("| `%s` | no | Key or token callers must send; auth is off while it is unset |", svc.Auth.EnvVar))
+		}
+		if svc.Webhook != nil && svc.Webhook.SecretEnv != "" {
+			rows = append(rows, fmt.Sprintf("| `%s` | yes | Secret the request signature is checked with |", svc.Webhook.SecretEnv))
+		}
+		for _, v := range svc.MCPEnvVars() {
+			rows = append(rows, fmt.Sprintf("| `%s` | yes | Read by the service's MCP servers |", v))
+		}
+		for _, v := range svc.ProviderEnvVars() {
+			rows = append(rows, fmt.Sprintf("| `%s` | yes | Claude via %s |", v, svc.ProviderName()))
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		fmt.Fprintf(b, "### %s\n\n", svc.Name)
+		b.WriteString("| Variable | Required | Purpose |\n")
+		b.WriteString("|----------|----------|---------|\n")
+		b.WriteString(strings.Join(rows, "\n") + "\n\n")
+	}
+}
+
+func readmeYesNo(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "no"
+}
+
+func readmeUsedBy(services []string) string {
+	if len(services) == 0 {
+		return ""
+	}
+	return ", used by " + strings.Join(services, ", ")
+}
+
+func writeReadmeQuickStart(b *strings.Builder, cfg *config.DatagenConfig) {
+	b.WriteString("## Quick Start\n\n")
+	switch cfg.Packaging() {
+	case config.PackagingUV, config.PackagingPoetry:
+		activate := "source .venv/bin/activate"
+		if cfg.Packaging() == config.PackagingPoetry {
+			activate = "eval $(poetry env activate)"
+		}
+		b.WriteString("1. Install the locked dependencies into a virtual environment:\n")
+		b.WriteString("   ```bash\n")
+		b.WriteString("   " + InstallCommand(cfg) + "\n")
+		b.WriteString("   " + activate + "\n")
+		b.WriteString("   ```\n\n")
+		fmt.Fprintf(b, "2. After changing dependencies in pyproject.toml, update %s and commit it:\n", LockFile(cfg))
+		b.WriteString("   ```bash\n")
+		b.WriteString("   " + cfg.Packaging() + " lock\n")
+		b.WriteString("   ```\n\n")
+	default:
+		b.WriteString("1. Create a virtual environment:\n")
+		b.WriteString("   ```bash\n")
+		b.WriteString("   python -m venv venv\n")
+		b.WriteString("   source venv/bin/activate\n")
+		b.WriteString("   ```\n\n")
+		b.WriteString("2. Install dependencies:\n")
+		b.WriteString("   ```bash\n")
+		b.WriteString("   " + InstallCommand(cfg) + "\n")
+		b.WriteString("   ```\n\n")
+	}
+	b.WriteString("3. Set up environment variables:\n")
+	b.WriteString("   ```bash\n")
+	b.WriteString("   cp .env.example .env\n")
+	b.WriteString("   # Edit .env with your API keys\n")
+	b.WriteString("   ```\n\n")
+	b.WriteString("4. Run locally:\n")
+	b.WriteString("   ```bash\n")
+	b.WriteString("   uvicorn app.main:app --reload\n")
+	b.WriteString("   ```\n\n")
+	b.WriteString("   Set `AGENT_FAKE=1` to run without API keys; agents then return a\n")
+	b.WriteString("   deterministic echo of their input (or `AGENT_FAKE_RESPONSE`), which is\n")
+	b.WriteString("   useful for tests and CI.\n\n")
+}
+
+// writeReadmeDeploy describes deploying to each platform the project has
+// config for
+func writeReadmeDeploy(b *strings.Builder, cfg *config.DatagenConfig) {
+	b.WriteString("## Deploy\n\n")
+	b.WriteString("Set the variables from [Environment Variables](#environment-variables) on the platform first.\n\n")
+
+	b.WriteString("### Railway\n\n")
+	b.WriteString("`railway.json` builds the `Dockerfile` and sets the health check and restart policy. Link the project, push the variables from `.env`, then deploy:\n\n")
+	b.WriteString("```bash\n")
+	b.WriteString("railway link      # or railway init for a new project\n")
+	b.WriteString("datagen env push\n")
+	b.WriteString("railway up\n")
+	b.WriteString("```\n\n")
+
+	b.WriteString("### Docker\n\n")
+	b.WriteString("```bash\n")
+	b.WriteString("docker build -t datagen-agents .\n")
+	b.WriteString("docker run --env-file .env -p 8000:8000 datagen-agents\n")
+	b.WriteString("```\n\n")
+
+	b.WriteString("### DigitalOcean App Platform\n\n")
+	b.WriteString("Write the app spec, which declares the secrets without values, then create the app and set them in the console:\n\n")
+	b.WriteString("```bash\n")
+	b.WriteString("datagen generate do\n")
+	b.WriteString("doctl apps create --spec .do/app.yaml\n")
+	b.WriteString("```\n\n")
+
+	b.WriteString("### Heroku, Render and other buildpack platforms\n\n")
+	b.WriteString("The `Procfile` starts the server on `$PORT`; the platform installs the dependencies.\n\n")
+
+	if cfg.Scaling != nil && cfg.Scaling.TargetConcurrency > 0 && cfg.Scaling.MaxReplicas > 0 {
+		b.WriteString("### Kubernetes\n\n")
+		fmt.Fprintf(b, "`k8s/hpa.yaml` scales the `datagen-agents` Deployment to keep about %d in-flight executions per pod,\n", cfg.Scaling.TargetConcurrency)
+		b.WriteString("from the `datagen_inflight_executions` metric (served at `/metrics`; needs a metrics adapter):\n\n")
+		b.WriteString("```bash\n")
+		b.WriteString("kubectl apply -f k8s/hpa.yaml\n")
+		b.WriteString("```\n\n")
+	}
+}
@@ -0,0 +1,237 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/debuglog"
+	"github.com/datagendev/datagen-cli/internal/undo"
+)
+
+// ErrMarkersUnrepairable is returned by RepairMarkers when a file or marker
+// has no recognizable place left to go; regenerating the file from
+// datagen.toml is the remaining way back
+var ErrMarkersUnrepairable = errors.New("markers cannot be placed")
+
+// endpointHeaders start each generated endpoint block in main.py
+var endpointHeaders = []string{"# Webhook endpoint: ", "# API endpoint: ", "# Streaming endpoint: ", "# Pipeline endpoint: "}
+
+// RepairMarkers re-inserts the injection markers missing from app/main.py and
+// app/models.py in outputDir, at the places generated (and adopted) projects
+// have them, so IncrementalAddService works again without a rebuild. Nothing
+// is written unless every missing marker can be placed. It returns
+// "file: marker" for each marker restored.
+func RepairMarkers(outputDir string) ([]string, error) {
+	repairs := []struct {
+		file   string
+		repair func(lines []string) ([]string, []string, error)
+	}{
+		{"app/main.py", repairMainMarkers},
+		{"app/models.py", repairModelsMarkers},
+	}
+
+	contents := make([]string, len(repairs))
+	var restored []string
+	for i, r := range repairs {
+		data, err := os.ReadFile(filepath.Join(outputDir, r.file))
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s: file not found: %w", r.file, ErrMarkersUnrepairable)
+		}
+		if err != nil {
+			return nil, err
+		}
+		lines, added, err := r.repair(strings.Split(string(data), "\n"))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.file, err)
+		}
+		if len(added) == 0 {
+			continue
+		}
+		contents[i] = strings.Join(lines, "\n")
+		for _, m := range added {
+			restored = append(restored, r.file+": "+m)
+		}
+	}
+
+	for i, r := range repairs {
+		if contents[i] == "" {
+			continue
+		}
+		path := filepath.Join(outputDir, r.file)
+		if err := undo.Save(path); err != nil {
+			return nil, err
+		}
+		debuglog.FileWrite(path, []byte(contents[i]))
+		if err := os.WriteFile(path, []byte(contents[i]), 0644); err != nil {
+			return nil, err
+		}
+	}
+	return restored, nil
+}
+
+// repairMainMarkers restores the agent loading markers around the
+// load_agent calls in lifespan and the endpoint handler markers around the
+// generated endpoints, which end where the /health route starts
+func repairMainMarkers(lines []string) ([]string, []string, error) {
+	const (
+		loadStart    = "=== AGENT LOADING START ==="
+		loadEnd      = "=== AGENT LOADING END ==="
+		handlerStart = "=== ENDPOINT HANDLERS START ==="
+		handlerEnd   = "=== ENDPOINT HANDLERS END ==="
+	)
+	var added []string
+
+	if findLine(lines, 0, hasMarker(loadStart)) < 0 {
+		i := findLine(lines, 0, isAgentLoadingLine)
+		if i < 0 {
+			i = findLine(lines, 0, hasMarker(loadEnd))
+		}
+		if i < 0 {
+			i = findLine(lines, 0, func(l string) bool { return strings.TrimSpace(l) == "canary.load(agent_executors)" })
+		}
+		if i < 0 {
+			return nil, nil, fmt.Errorf("no agent loading code found for %q: %w", loadStart, ErrMarkersUnrepairable)
+		}
+		lines = insertLines(lines, i, indentOf(lines[i])+"# "+loadStart)
+		added = append(added, loadStart)
+	}
+
+	if findLine(lines, 0, hasMarker(loadEnd)) < 0 {
+		start := findLine(lines, 0, hasMarker(loadStart))
+		last := start
+		for k := start + 1; k < len(lines); k++ {
+			if strings.TrimSpace(lines[k]) == "" {
+				continue
+			}
+			if !isAgentLoadingLine(lines[k]) {
+				break
+			}
+			last = k
+		}
+		lines = insertLines(lines, last+1, indentOf(lines[start])+"# "+loadEnd)
+		added = append(added, loadEnd)
+	}
+
+	if findLine(lines, 0, hasMarker(handlerStart)) < 0 {
+		i := findLine(lines, 0, isEndpointHeader)
+		if i < 0 {
+			i = findLine(lines, 0, hasMarker(handlerEnd))
+		}
+		if i < 0 {
+			i = findLine(lines, 0, isHealthRoute)
+		}
+		if i < 0 {
+			return nil, nil, fmt.Errorf("no endpoint handlers or /health route found for %q: %w", handlerStart, ErrMarkersUnrepairable)
+		}
+		lines = insertLines(lines, i, "# "+handlerStart)
+		added = append(added, handlerStart)
+	}
+
+	if findLine(lines, 0, hasMarker(handlerEnd)) < 0 {
+		start := findLine(lines, 0, hasMarker(handlerStart))
+		i := findLine(lines, start+1, isHealthRoute)
+		if i < 0 {
+			i = findLine(lines, start+1, func(l string) bool { return strings.HasPrefix(l, "if __name__ ==") })
+		}
+		if i < 0 {
+			i = len(lines)
+			for i > start+1 && strings.TrimSpace(lines[i-1]) == "" {
+				i--
+			}
+		}
+		lines = insertLines(lines, i, "# "+handlerEnd, "")
+		added = append(added, handlerEnd)
+	}
+
+	return lines, added, nil
+}
+
+// repairModelsMarkers restores the service models markers around the
+// "# Models for" blocks, which run to the end of models.py
+func repairModelsMarkers(lines []string) ([]string, []string, error) {
+	const (
+		start = "=== SERVICE MODELS START ==="
+		end   = "=== SERVICE MODELS END ==="
+	)
+	var added []string
+
+	if findLine(lines, 0, hasMarker(start)) < 0 {
+		i := findLine(lines, 0, func(l string) bool { return strings.HasPrefix(l, "# Models for ") })
+		if i < 0 {
+			i = findLine(lines, 0, hasMarker(end))
+		}
+		if i < 0 {
+			// After the imports
+			i = 0
+			for k, l := range lines {
+				if strings.HasPrefix(l, "from ") || strings.HasPrefix(l, "import ") {
+					i = k + 1
+				}
+			}
+			lines = insertLines(lines, i, "", "")
+			i += 2
+		}
+		lines = insertLines(lines, i, "# "+start)
+		added = append(added, start)
+	}
+
+	if findLine(lines, 0, hasMarker(end)) < 0 {
+		i := len(lines)
+		for i > 0 && strings.TrimSpace(lines[i-1]) == "" {
+			i--
+		}
+		lines = append(lines[:i], "# "+end, "")
+		added = append(added, end)
+	}
+
+	return lines, added, nil
+}
+
+func hasMarker(marker string) func(string) bool {
+	return func(l string) bool { return strings.Contains(l, marker) }
+}
+
+// isAgentLoadingLine matches the lines injected between the agent loading markers
+func isAgentLoadingLine(l string) bool {
+	t := strings.TrimSpace(l)
+	return (strings.HasPrefix(t, "agent_executors[") && strings.Contains(t, "load_agent(")) ||
+		strings.HasPrefix(t, "locales.load(")
+}
+
+func isEndpointHeader(l string) bool {
+	for _, h := range endpointHeaders {
+		if strings.HasPrefix(l, h) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHealthRoute(l string) bool {
+	return l == "# Health check" || strings.HasPrefix(l, `@app.get("/health"`)
+}
+
+// findLine returns the index of the first line at or after from matching
+// match, or -1
+func findLine(lines []string, from int, match func(string) bool) int {
+	for i := from; i < len(lines); i++ {
+		if match(lines[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+func insertLines(lines []string, i int, insert ...string) []string {
+	out := make([]string, 0, len(lines)+len(insert))
+	out = append(out, lines[:i]...)
+	out = append(out, insert...)
+	return append(out, lines[i:]...)
+}
+
+func indentOf(l string) string {
+	return l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+}
@@ -0,0 +1,108 @@
+package codegen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+// stripMarkers deletes every line holding a datagen injection marker
+func stripMarkers(t *testing.T, path string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var kept []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.Contains(line, "# === ") {
+			kept = append(kept, line)
+		}
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(kept, "\n")), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+func TestRepairMarkers(t *testing.T) {
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{
+			{Name: "chat", Type: "api", Prompt: ".claude/agents/chat.md", APIPath: "/chat"},
+		},
+	}
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	stripMarkers(t, filepath.Join(outDir, "app", "main.py"))
+	stripMarkers(t, filepath.Join(outDir, "app", "models.py"))
+
+	restored, err := RepairMarkers(outDir)
+	if err != nil {
+		t.Fatalf("RepairMarkers: %v", err)
+	}
+	if len(restored) != 6 {
+		t.Fatalf("RepairMarkers restored %v, want all 6 markers", restored)
+	}
+	if missing, err := MissingMarkers(outDir); err != nil || len(missing) != 0 {
+		t.Fatalf("MissingMarkers after repair = %v, %v", missing, err)
+	}
+
+	mainPy, err := os.ReadFile(filepath.Join(outDir, "app", "main.py"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	main := string(mainPy)
+	loadStart := strings.Index(main, "    # === AGENT LOADING START ===")
+	load := strings.Index(main, `agent_executors["chat"] = load_agent(`)
+	loadEnd := strings.Index(main, "    # === AGENT LOADING END ===")
+	if loadStart < 0 || !(loadStart < load && load < loadEnd) {
+		t.Fatalf("agent loading markers do not surround the chat agent:\n%s", main)
+	}
+	handlerStart := strings.Index(main, "# === ENDPOINT HANDLERS START ===")
+	endpoint := strings.Index(main, "# API endpoint: chat")
+	handlerEnd := strings.Index(main, "# === ENDPOINT HANDLERS END ===")
+	health := strings.Index(main, "# Health check")
+	if !(handlerStart < endpoint && endpoint < handlerEnd && handlerEnd < health) {
+		t.Fatalf("endpoint handler markers do not surround the chat endpoint:\n%s", main)
+	}
+
+	// add works again
+	cfg.Services = append(cfg.Services, config.Service{Name: "summarize", Type: "api", Prompt: ".claude/agents/summarize.md", APIPath: "/summarize"})
+	if err := IncrementalAddService(cfg, &cfg.Services[1], outDir); err != nil {
+		t.Fatalf("IncrementalAddService after repair: %v", err)
+	}
+
+	// A second repair has nothing to do
+	if restored, err := RepairMarkers(outDir); err != nil || len(restored) != 0 {
+		t.Fatalf("second RepairMarkers = %v, %v; want nothing restored", restored, err)
+	}
+}
+
+func TestRepairMarkers_Unrepairable(t *testing.T) {
+	outDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(outDir, "app"), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	main := "from fastapi import FastAPI\n\napp = FastAPI()\n"
+	if err := os.WriteFile(filepath.Join(outDir, "app", "main.py"), []byte(main), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outDir, "app", "models.py"), []byte("from pydantic import BaseModel\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if _, err := RepairMarkers(outDir); !errors.Is(err, ErrMarkersUnrepairable) {
+		t.Fatalf("RepairMarkers error = %v, want ErrMarkersUnrepairable", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(outDir, "app", "models.py"))
+	if string(data) != "from pydantic import BaseModel\n" {
+		t.Fatalf("models.py was changed although main.py could not be repaired:\n%s", data)
+	}
+}
@@ -0,0 +1,110 @@
+package codegen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/mcpconfig"
+)
+
+// Scaffolding files that datagen does not need itself but that projects
+// adopting pieces of it usually want. Except for .dockerignore and
+// .gitignore, which GenerateProject writes when missing, they are on-demand:
+// only written by GenerateFile/GenerateFiles.
+
+// generateDockerignore keeps secrets, local state and files the app does not
+// run from out of the build context, so images stay small and rebuilds don't
+// invalidate the COPY . . layer needlessly
+func generateDockerignore(outputDir string) error {
+	content := `.git
+.env
+.env.*
+!.env.example
+.venv/
+venv/
+__pycache__/
+*.py[cod]
+.pytest_cache/
+.mypy_cache/
+.ruff_cache/
+.datagen/
+recordings/
+
+# Build and deploy files the running app does not read
+Dockerfile
+.dockerignore
+.github/
+.do/
+k8s/
+evals/
+samples/
+tests/
+*.log
+.DS_Store
+`
+	return os.WriteFile(filepath.Join(outputDir, ".dockerignore"), []byte(content), 0644)
+}
+
+// EnsureGitignore writes the default .gitignore into dir unless it already
+// has one, and reports whether it wrote it
+func EnsureGitignore(dir string) (bool, error) {
+	if _, err := os.Stat(filepath.Join(dir, ".gitignore")); err == nil {
+		return false, nil
+	}
+	return true, generateGitignore(dir)
+}
+
+// generateGitignore keeps secrets, virtualenvs and local datagen state out of
+// commits; .env in particular is easy to commit by accident
+func generateGitignore(outputDir string) error {
+	content := `# Secrets
+.env
+.env.*
+!.env.example
+
+# Python
+.venv/
+venv/
+__pycache__/
+*.py[cod]
+.pytest_cache/
+
+# datagen command transcripts and deploy records
+.datagen/logs/
+.datagen/state*
+.datagen/lock
+.datagen/undo/
+
+# Railway CLI link
+.railway/
+
+# Captured requests ([capture] in datagen.toml)
+recordings/
+
+.DS_Store
+`
+	return os.WriteFile(filepath.Join(outputDir, ".gitignore"), []byte(content), 0644)
+}
+
+// generateMCPJSON writes a project-level .mcp.json for Claude Code that
+// connects to the DataGen MCP server, reading the key from the environment
+func generateMCPJSON(cfg *config.DatagenConfig, outputDir string) error {
+	out := map[string]any{
+		"mcpServers": map[string]any{
+			"datagen": map[string]any{
+				"type": "http",
+				"url":  mcpconfig.DatagenMCPURL,
+				"headers": map[string]string{
+					"X-API-Key": "${" + cfg.DatagenAPIKeyEnv + "}",
+				},
+			},
+		},
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outputDir, ".mcp.json"), append(data, '\n'), 0644)
+}
@@ -0,0 +1,150 @@
+package codegen
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/models"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite testdata/*.golden from the current templates")
+
+func TestGenerateAgentPy_Golden(t *testing.T) {
+	outDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(outDir, "app"), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := generateAgentPy(&config.DatagenConfig{}, outDir); err != nil {
+		t.Fatalf("generateAgentPy: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(outDir, "app", "agent.py"))
+	if err != nil {
+		t.Fatalf("read agent.py: %v", err)
+	}
+
+	golden := filepath.Join("testdata", "agent.py.golden")
+	if *updateGolden {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatalf("write golden: %v", err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("read golden: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Fatalf("agent.py differs from %s; run go test ./internal/codegen -run Golden -update if the change is intended", golden)
+	}
+}
+
+func TestGenerateProject_TemplateOverride(t *testing.T) {
+	t.Parallel()
+
+	configDir := t.TempDir()
+	overrides := filepath.Join(configDir, "templates")
+	if err := os.MkdirAll(overrides, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	custom := "# custom agent for {{len .Services}} service(s), default {{defaultModel}}\n"
+	if err := os.WriteFile(filepath.Join(overrides, "agent.py.tmpl"), []byte(custom), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	outDir := t.TempDir()
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		TemplatesDir:     "templates",
+		Dir:              configDir,
+		Services: []config.Service{
+			{Name: "chat", Type: "api", Prompt: ".claude/agents/chat.md", APIPath: "/chat"},
+		},
+	}
+	if _, err := GenerateProject(cfg, outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	agent, err := os.ReadFile(filepath.Join(outDir, "app", "agent.py"))
+	if err != nil {
+		t.Fatalf("read agent.py: %v", err)
+	}
+	if string(agent) != "# custom agent for 1 service(s), default "+models.Default+"\n" {
+		t.Fatalf("agent.py = %q, want the override rendered", agent)
+	}
+	// Templates without an override still come from the embedded set
+	mainPy, err := os.ReadFile(filepath.Join(outDir, "app", "main.py"))
+	if err != nil {
+		t.Fatalf("read main.py: %v", err)
+	}
+	if len(mainPy) == 0 || string(mainPy) == string(agent) {
+		t.Fatalf("main.py was not generated from the embedded template")
+	}
+}
+
+// goldenConfig exercises the conditional parts of the app templates: auth,
+// webhook signatures, rate limits, list and dict fields, and output schemas
+func goldenConfig() *config.DatagenConfig {
+	return &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{
+			{
+				Name:        "enrich",
+				Type:        "api",
+				Description: "Enrich a lead",
+				Prompt:      ".claude/agents/enrich.md",
+				APIPath:     "/api/enrich",
+				Auth:        &config.Auth{Type: "api_key", Header: "X-API-Key", EnvVar: "ENRICH_API_KEY"},
+				API:         &config.APIConfig{ResponseFormat: "json", Timeout: 30, RateLimitEnabled: true, RateLimitRPM: 60},
+				InputSchema: config.Schema{Fields: []config.Field{
+					{Name: "email", Type: "str", Required: true},
+					{Name: "tags", Type: "list"},
+					{Name: "extra", Type: "dict"},
+				}},
+				OutputSchema: &config.Schema{Fields: []config.Field{{Name: "company", Type: "str", Required: true}}},
+			},
+			{
+				Name:        "hook",
+				Type:        "webhook",
+				Description: "Handle deliveries",
+				Prompt:      ".claude/agents/hook.md",
+				WebhookPath: "/webhook/hook",
+				Webhook: &config.WebhookConfig{
+					SignatureVerification: "hmac_sha256",
+					SignatureHeader:       "X-Signature",
+					SecretEnv:             "HOOK_SECRET",
+				},
+			},
+		},
+	}
+}
+
+func TestGenerateAppPy_Golden(t *testing.T) {
+	outDir := t.TempDir()
+	if _, err := GenerateProject(goldenConfig(), outDir); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	for _, name := range []string{"main.py", "models.py", "config.py"} {
+		got, err := os.ReadFile(filepath.Join(outDir, "app", name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		golden := filepath.Join("testdata", name+".golden")
+		if *updateGolden {
+			if err := os.WriteFile(golden, got, 0644); err != nil {
+				t.Fatalf("write golden: %v", err)
+			}
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("read golden: %v", err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s differs from %s; run go test ./internal/codegen -run Golden -update if the change is intended", name, golden)
+		}
+	}
+}
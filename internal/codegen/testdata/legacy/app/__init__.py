@@ -0,0 +1 @@
+"""FastAPI application package."""
@@ -0,0 +1,78 @@
+"""Configuration management using Pydantic Settings."""
+
+import os
+from typing import Optional
+
+from pydantic import Field, field_validator
+from pydantic_settings import BaseSettings, SettingsConfigDict
+
+
+class Settings(BaseSettings):
+    """Application settings loaded from environment variables."""
+
+    model_config = SettingsConfigDict(
+        env_file=".env",
+        env_file_encoding="utf-8",
+        case_sensitive=False,
+        extra="ignore",
+    )
+
+    # Required API keys
+    anthropic_api_key: str = Field(
+        ..., description="Anthropic API key for Claude agent execution"
+    )
+    
+    datagen_api_key: Optional[str] = Field(
+        default=None, description="DataGen API key for MCP integration (optional)"
+    )
+    
+
+    # Service-specific secrets
+    
+    
+    
+    
+
+    # Model configuration (optional)
+    model_name: str = Field(
+        default="claude-sonnet-4-5",
+        description="Claude model to use",
+    )
+
+    # Application settings
+    log_level: str = Field(default="INFO", description="Logging level")
+    port: int = Field(default=8000, description="Server port")
+    permission_mode: str = Field(
+        default="bypassPermissions",
+        description="Agent SDK permission mode",
+    )
+
+    # CORS settings (optional)
+    cors_enabled: bool = Field(
+        default=False, description="Enable CORS middleware"
+    )
+    cors_origins: str = Field(
+        default="*", description="Comma-separated list of allowed CORS origins"
+    )
+
+    @field_validator("anthropic_api_key")
+    @classmethod
+    def validate_anthropic_key(cls, v: str) -> str:
+        """Ensure Anthropic API key is set."""
+        if not v or not v.strip():
+            raise ValueError("ANTHROPIC_API_KEY is required")
+        return v.strip()
+
+    @field_validator("datagen_api_key")
+    @classmethod
+    def validate_datagen_key(cls, v: Optional[str]) -> Optional[str]:
+        """Ensure DataGen API key is set."""
+        
+        if not v:
+            return v
+        return v.strip()
+        
+
+
+# Global settings instance
+settings = Settings()
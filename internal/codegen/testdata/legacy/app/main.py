@@ -0,0 +1,157 @@
+"""FastAPI application entry point."""
+
+import hashlib
+import hmac
+import logging
+import uuid
+from contextlib import asynccontextmanager
+
+from fastapi import BackgroundTasks, Depends, FastAPI, Header, HTTPException, Request
+from fastapi.middleware.cors import CORSMiddleware
+from fastapi.responses import JSONResponse, StreamingResponse
+
+from app.agent import agent_executors, load_agent, log_event
+from app.config import settings
+from app.models import *
+
+# Configure logging
+logging.basicConfig(
+    level=getattr(logging, settings.log_level.upper()),
+    format="%(message)s",
+)
+logger = logging.getLogger(__name__)
+
+
+@asynccontextmanager
+async def lifespan(app: FastAPI):
+    """Application lifespan events."""
+    # Load agents for all services
+    # === AGENT LOADING START ===
+    
+    agent_executors["chat"] = load_agent("chat", ".claude/agents/chat.md")
+    
+    # === AGENT LOADING END ===
+    log_event("app_startup")
+    yield
+    log_event("app_shutdown")
+
+
+app = FastAPI(
+    title="DataGen Agent API",
+    description="FastAPI boilerplate for deploying Claude Code agents",
+    version="1.0.0",
+    lifespan=lifespan,
+)
+
+# CORS Middleware (if enabled)
+if settings.cors_enabled:
+    origins = [origin.strip() for origin in settings.cors_origins.split(",")]
+    app.add_middleware(
+        CORSMiddleware,
+        allow_origins=origins,
+        allow_credentials=True,
+        allow_methods=["*"],
+        allow_headers=["*"],
+    )
+    log_event("cors_enabled", origins=origins)
+
+
+# Middleware: Request ID injection
+@app.middleware("http")
+async def add_request_id(request: Request, call_next):
+    """Add unique request ID to all requests."""
+    request_id = str(uuid.uuid4())
+    request.state.request_id = request_id
+
+    log_event(
+        "http_request",
+        request_id=request_id,
+        method=request.method,
+        path=request.url.path,
+        client=request.client.host if request.client else None,
+    )
+
+    response = await call_next(request)
+
+    log_event(
+        "http_response",
+        request_id=request_id,
+        status_code=response.status_code,
+    )
+
+    return response
+
+
+# Middleware: Error handling
+@app.exception_handler(Exception)
+async def global_exception_handler(request: Request, exc: Exception):
+    """Handle uncaught exceptions with structured logging."""
+    request_id = getattr(request.state, "request_id", "unknown")
+
+    log_event(
+        "http_error",
+        request_id=request_id,
+        error=str(exc),
+        error_type=type(exc).__name__,
+        path=request.url.path,
+    )
+
+    return JSONResponse(
+        status_code=500,
+        content={
+            "status": "error",
+            "request_id": request_id,
+            "message": "Internal server error",
+            "detail": str(exc) if settings.log_level.upper() == "DEBUG" else None,
+        },
+    )
+
+
+# === ENDPOINT HANDLERS START ===
+
+
+# API endpoint: chat
+
+
+@app.post("/api/chat")
+async def chat_handler(
+    request: Request,
+    payload: ChatInput,
+    
+):
+    """
+    Chat
+
+    Type: API (synchronous)
+    
+    """
+    request_id = request.state.request_id
+
+    try:
+        executor = agent_executors["chat"]
+        result = await executor.execute(payload.model_dump(), request_id)
+        
+        return {"status": "completed", "request_id": request_id, "result": result}
+        
+    except Exception as e:
+        log_event("api_error", request_id=request_id, service="chat", error=str(e))
+        raise HTTPException(status_code=500, detail="Agent execution failed")
+
+
+
+# === ENDPOINT HANDLERS END ===
+
+# Health check
+@app.get("/health")
+def health():
+    """Health check endpoint."""
+    return {
+        "status": "ok",
+        "services": ["chat"],
+        "ready": True
+    }
+
+
+if __name__ == "__main__":
+    import uvicorn
+    uvicorn.run(app, host="0.0.0.0", port=settings.port)
@@ -0,0 +1,18 @@
+"""Pydantic models for request/response schemas."""
+
+from typing import Any, Dict, List, Optional
+
+from pydantic import BaseModel, Field
+
+
+# === SERVICE MODELS START ===
+
+# Models for chat service
+class ChatInput(BaseModel):
+    """Input model for chat endpoint."""
+    
+
+
+
+
+# === SERVICE MODELS END ===
@@ -0,0 +1,68 @@
+package config
+
+import "reflect"
+
+// ApplyDefaults gives each service a copy of the [defaults] settings it
+// leaves unset. ReadConfig applies them; commands that add services to a
+// loaded config call it again before generating code.
+func (c *DatagenConfig) ApplyDefaults() {
+	d := c.Defaults
+	if d == nil {
+		return
+	}
+	for i := range c.Services {
+		svc := &c.Services[i]
+		if svc.Auth == nil && d.Auth != nil {
+			auth := *d.Auth
+			svc.Auth = &auth
+		}
+		if svc.AllowedTools == nil && d.AllowedTools != nil {
+			tools := *d.AllowedTools
+			svc.AllowedTools = &tools
+		}
+		if svc.Model == "" {
+			svc.Model = d.Model
+		}
+		if svc.Type == "api" && d.Timeout > 0 {
+			if svc.API == nil {
+				svc.API = &APIConfig{}
+			}
+			if svc.API.Timeout == 0 {
+				api := *svc.API
+				api.Timeout = d.Timeout
+				svc.API = &api
+			}
+		}
+	}
+}
+
+// withoutDefaults returns services with each setting that matches d cleared,
+// so that it stays inherited when the config is written back rather than
+// being copied into every service
+func withoutDefaults(d *ServiceDefaults, services []Service) []Service {
+	if d == nil {
+		return services
+	}
+	out := make([]Service, len(services))
+	for i, svc := range services {
+		if d.Auth != nil && reflect.DeepEqual(svc.Auth, d.Auth) {
+			svc.Auth = nil
+		}
+		if d.AllowedTools != nil && reflect.DeepEqual(svc.AllowedTools, d.AllowedTools) {
+			svc.AllowedTools = nil
+		}
+		if svc.Model == d.Model {
+			svc.Model = ""
+		}
+		if svc.API != nil && d.Timeout > 0 && svc.API.Timeout == d.Timeout {
+			api := *svc.API
+			api.Timeout = 0
+			svc.API = &api
+			if api == (APIConfig{}) {
+				svc.API = nil
+			}
+		}
+		out[i] = svc
+	}
+	return out
+}
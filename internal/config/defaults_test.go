@@ -0,0 +1,126 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const defaultsConfig = `version = 1
+datagen_api_key_env = "DATAGEN_API_KEY"
+claude_api_key_env = "ANTHROPIC_API_KEY"
+
+[defaults]
+timeout = 45
+model = "sonnet"
+
+[defaults.auth]
+type = "api_key"
+header = "X-API-Key"
+env_var = "SERVICE_API_KEY"
+
+[defaults.allowed_tools]
+executeTools = true
+
+[[service]]
+name = "inherits"
+type = "api"
+prompt = "a.md"
+api_path = "/a"
+
+[[service]]
+name = "overrides"
+type = "api"
+prompt = "b.md"
+api_path = "/b"
+model = "opus"
+
+[service.auth]
+type = "none"
+
+[service.allowed_tools]
+searchTools = false
+
+[service.api]
+response_format = "text"
+timeout = 10
+
+[[service]]
+name = "hook"
+type = "webhook"
+prompt = "c.md"
+webhook_path = "/webhook/c"
+`
+
+func TestReadConfig_Defaults(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "datagen.toml")
+	if err := os.WriteFile(path, []byte(defaultsConfig), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	cfg, err := ReadConfig(path)
+	if err != nil {
+		t.Fatalf("ReadConfig: %v", err)
+	}
+
+	inherits, overrides, hook := cfg.Services[0], cfg.Services[1], cfg.Services[2]
+	if inherits.Auth == nil || inherits.Auth.EnvVar != "SERVICE_API_KEY" || !inherits.Tools().ExecuteTools || inherits.Model != "sonnet" || inherits.API == nil || inherits.API.Timeout != 45 {
+		t.Fatalf("inheriting service = %+v", inherits)
+	}
+	if inherits.Auth == cfg.Defaults.Auth || inherits.AllowedTools == cfg.Defaults.AllowedTools {
+		t.Fatal("services share the [defaults] tables instead of copies")
+	}
+	if overrides.Auth.Type != "none" || overrides.Tools().ExecuteTools || overrides.Model != "opus" || overrides.API.Timeout != 10 {
+		t.Fatalf("overriding service = %+v", overrides)
+	}
+	if hook.API != nil || hook.Auth == nil {
+		t.Fatalf("webhook service = %+v; want the default auth and no api table", hook)
+	}
+
+	// Inherited settings are not copied into the services when saved
+	if err := SaveConfig(cfg, path); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if got := strings.Count(string(data), "SERVICE_API_KEY"); got != 1 {
+		t.Fatalf("SERVICE_API_KEY written %d times, want once in [defaults]:\n%s", got, data)
+	}
+	if strings.Contains(string(data), `model = "sonnet"`+"\n\n[[service]]") || strings.Count(string(data), "timeout = 45") != 1 {
+		t.Fatalf("saved config repeats defaults:\n%s", data)
+	}
+	again, err := ReadConfig(path)
+	if err != nil {
+		t.Fatalf("ReadConfig after save: %v", err)
+	}
+	for i := range cfg.Services {
+		if !sameServices([]Service{again.Services[i]}, []Service{cfg.Services[i]}) {
+			t.Errorf("service %s changed on save:\n got %+v\nwant %+v", cfg.Services[i].Name, again.Services[i], cfg.Services[i])
+		}
+	}
+}
+
+func TestValidateDefaults(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		defaults *ServiceDefaults
+		wantErr  string
+	}{
+		{name: "negative timeout", defaults: &ServiceDefaults{Timeout: -1}, wantErr: "defaults.timeout"},
+		{name: "auth without env var", defaults: &ServiceDefaults{Auth: &Auth{Type: "api_key"}}, wantErr: "defaults.auth: env_var is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &DatagenConfig{DatagenAPIKeyEnv: "DATAGEN_API_KEY", ClaudeAPIKeyEnv: "ANTHROPIC_API_KEY", Defaults: tt.defaults}
+			if err := ValidateConfig(cfg, t.TempDir()); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateConfig error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResolveEnvironment returns the settings for the named environment with defaults filled in.
+// When cfg declares no [environments], any name is accepted and mapped to a Railway
+// environment of the same name and a .env.<name> variable file. Once environments are
+// declared, only those names are valid so typos don't silently target a new environment.
+func ResolveEnvironment(cfg *DatagenConfig, name string) (*EnvironmentConfig, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("environment name is required")
+	}
+
+	env := EnvironmentConfig{}
+	if cfg != nil && len(cfg.Environments) > 0 {
+		declared, ok := cfg.Environments[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown environment %q (declared: %s)", name, strings.Join(cfg.EnvironmentNames(), ", "))
+		}
+		env = declared
+	}
+
+	if env.RailwayEnvironment == "" {
+		env.RailwayEnvironment = name
+	}
+	if env.EnvFile == "" {
+		env.EnvFile = ".env." + name
+	}
+	return &env, nil
+}
+
+// EnvironmentNames returns the declared environment names in sorted order
+func (c *DatagenConfig) EnvironmentNames() []string {
+	names := make([]string, 0, len(c.Environments))
+	for name := range c.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,40 @@
+package config
+
+import "testing"
+
+func TestResolveEnvironment(t *testing.T) {
+	t.Parallel()
+
+	env, err := ResolveEnvironment(nil, "staging")
+	if err != nil {
+		t.Fatalf("ResolveEnvironment(nil) error = %v", err)
+	}
+	if env.RailwayEnvironment != "staging" || env.EnvFile != ".env.staging" {
+		t.Fatalf("ResolveEnvironment(nil) = %+v, want staging defaults", env)
+	}
+
+	cfg := &DatagenConfig{Environments: map[string]EnvironmentConfig{
+		"production": {RailwayEnvironment: "prod", EnvFile: ".env"},
+		"staging":    {Variables: map[string]string{"LOG_LEVEL": "debug"}},
+	}}
+
+	env, err = ResolveEnvironment(cfg, "production")
+	if err != nil {
+		t.Fatalf("ResolveEnvironment(production) error = %v", err)
+	}
+	if env.RailwayEnvironment != "prod" || env.EnvFile != ".env" {
+		t.Fatalf("ResolveEnvironment(production) = %+v, want prod/.env", env)
+	}
+
+	env, err = ResolveEnvironment(cfg, "staging")
+	if err != nil {
+		t.Fatalf("ResolveEnvironment(staging) error = %v", err)
+	}
+	if env.Variables["LOG_LEVEL"] != "debug" || env.RailwayEnvironment != "staging" || env.EnvFile != ".env.staging" {
+		t.Fatalf("ResolveEnvironment(staging) = %+v, want its variables with staging defaults", env)
+	}
+
+	if _, err := ResolveEnvironment(cfg, "qa"); err == nil {
+		t.Fatalf("ResolveEnvironment(qa) error = nil, want unknown environment")
+	}
+}
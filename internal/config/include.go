@@ -0,0 +1,174 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/debuglog"
+	"github.com/datagendev/datagen-cli/internal/undo"
+)
+
+// includeFile is what SaveConfig writes back to an included file: its
+// services and nothing else
+type includeFile struct {
+	Services []Service `toml:"service" json:"service" yaml:"service"`
+}
+
+// expandIncludes returns the files patterns name, relative patterns resolving
+// against dir. Patterns keep their order and each one's matches are sorted,
+// so services load in the same order on every machine; a file matched twice
+// is read once. A pattern without wildcards must name an existing file.
+func expandIncludes(patterns []string, dir string) ([]string, error) {
+	var files []string
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		p := pattern
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, fmt.Errorf("include %q: %w", pattern, err)
+		}
+		if len(matches) == 0 && !strings.ContainsAny(pattern, `*?[`) {
+			return nil, fmt.Errorf("include %q: file not found", pattern)
+		}
+		sort.Strings(matches)
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				files = append(files, m)
+			}
+		}
+	}
+	return files, nil
+}
+
+// readInclude reads the services defined in an included file, in the format
+// its extension names. Included files may only hold [[service]] tables.
+func readInclude(path string) ([]Service, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read included file: %w", err)
+	}
+	format := FormatOf(path)
+	var fragment DatagenConfig
+	if err := unmarshalConfig(format, data, &fragment); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := migrateConfig(format, data, &fragment); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	services := fragment.Services
+	fragment.Services, fragment.Version = nil, 0
+	if !reflect.DeepEqual(fragment, DatagenConfig{}) {
+		return nil, fmt.Errorf("%s: included files may only define services", path)
+	}
+	return services, nil
+}
+
+// mergeIncludes appends the services of config's included files to its own,
+// recording the file each came from. A service name defined twice across
+// the files is an error naming both.
+func mergeIncludes(config *DatagenConfig, path string) error {
+	if len(config.Include) == 0 {
+		return nil
+	}
+	files, err := expandIncludes(config.Include, filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	definedIn := map[string]string{}
+	for _, svc := range config.Services {
+		definedIn[svc.Name] = path
+	}
+	for _, file := range files {
+		services, err := readInclude(file)
+		if err != nil {
+			return err
+		}
+		for _, svc := range services {
+			if other, ok := definedIn[svc.Name]; ok {
+				return fmt.Errorf("service %q is defined in both %s and %s", svc.Name, other, file)
+			}
+			definedIn[svc.Name] = file
+			svc.File = file
+			config.Services = append(config.Services, svc)
+		}
+	}
+	return nil
+}
+
+// ownServices returns config without the services it read from included
+// files, which is what its own file holds
+func ownServices(config *DatagenConfig) *DatagenConfig {
+	own := *config
+	own.Services = nil
+	for _, svc := range config.Services {
+		if svc.File == "" {
+			own.Services = append(own.Services, svc)
+		}
+	}
+	return &own
+}
+
+// saveIncludes writes each included file's services back to it, leaving the
+// files whose services did not change untouched. A file whose services were
+// all removed is left with none.
+func saveIncludes(config *DatagenConfig, path string) error {
+	files, err := expandIncludes(config.Include, filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	byFile := map[string][]Service{}
+	for _, svc := range withoutDefaults(config.Defaults, config.Services) {
+		if svc.File == "" {
+			continue
+		}
+		if _, ok := byFile[svc.File]; !ok && !slices.Contains(files, svc.File) {
+			files = append(files, svc.File)
+		}
+		byFile[svc.File] = append(byFile[svc.File], svc)
+	}
+
+	for _, file := range files {
+		services := byFile[file]
+		if current, err := readInclude(file); err == nil && sameServices(current, services) {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := encodeAs(FormatOf(file), &includeFile{Services: services}, &buf); err != nil {
+			return err
+		}
+		if err := undo.Save(file); err != nil {
+			return err
+		}
+		debuglog.FileWrite(file, buf.Bytes())
+		if err := os.WriteFile(file, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write included file: %w", err)
+		}
+	}
+	return nil
+}
+
+// sameServices reports whether a file's services, as read back, match the
+// ones about to be written to it
+func sameServices(current, services []Service) bool {
+	if len(current) != len(services) {
+		return false
+	}
+	for i := range services {
+		svc := services[i]
+		svc.File = ""
+		if !reflect.DeepEqual(current[i], svc) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const includingConfig = `version = 1
+datagen_api_key_env = "DATAGEN_API_KEY"
+claude_api_key_env = "ANTHROPIC_API_KEY"
+include = ["services/*.toml", "extra.yaml", "services/a.toml"]
+
+[[service]]
+name = "main"
+type = "api"
+prompt = "main.md"
+api_path = "/main"
+`
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+}
+
+func includedProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"datagen.toml":    includingConfig,
+		"services/b.toml": "[[service]]\nname = \"b\"\ntype = \"webhook\"\nprompt = \"b.md\"\napi_path = \"/webhook/b\"\n",
+		"services/a.toml": "[[service]]\nname = \"a1\"\ntype = \"api\"\nprompt = \"a.md\"\napi_path = \"/a1\"\n\n[[service]]\nname = \"a2\"\ntype = \"api\"\nprompt = \"a.md\"\napi_path = \"/a2\"\n",
+		"extra.yaml":      "service:\n  - name: extra\n    type: api\n    prompt: extra.md\n    api_path: /extra\n",
+	})
+	return dir
+}
+
+func serviceNames(cfg *DatagenConfig) string {
+	var names []string
+	for _, svc := range cfg.Services {
+		names = append(names, svc.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestReadConfig_Includes(t *testing.T) {
+	t.Parallel()
+
+	dir := includedProject(t)
+	cfg, err := ReadConfig(filepath.Join(dir, "datagen.toml"))
+	if err != nil {
+		t.Fatalf("ReadConfig: %v", err)
+	}
+	if got := serviceNames(cfg); got != "main,a1,a2,b,extra" {
+		t.Fatalf("services = %s, want main,a1,a2,b,extra", got)
+	}
+	if cfg.Services[0].File != "" || cfg.Services[3].File != filepath.Join(dir, "services", "b.toml") {
+		t.Fatalf("File = %q, %q", cfg.Services[0].File, cfg.Services[3].File)
+	}
+	// Included files are migrated like the main one
+	if cfg.Services[3].WebhookPath != "/webhook/b" {
+		t.Fatalf("included webhook path = %+v", cfg.Services[3])
+	}
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "duplicate name",
+			files:   map[string]string{"extra.yaml": "service:\n  - name: a2\n    type: api\n    prompt: a.md\n"},
+			wantErr: `service "a2" is defined in both ` + filepath.Join(dir, "services", "a.toml"),
+		},
+		{
+			name:    "non-service key",
+			files:   map[string]string{"extra.yaml": "claude_api_key_env: OTHER\n"},
+			wantErr: "included files may only define services",
+		},
+	}
+	for _, tt := range tests {
+		writeFiles(t, dir, tt.files)
+		if _, err := ReadConfig(filepath.Join(dir, "datagen.toml")); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: ReadConfig error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if err := os.Remove(filepath.Join(dir, "extra.yaml")); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := ReadConfig(filepath.Join(dir, "datagen.toml")); err == nil || !strings.Contains(err.Error(), `include "extra.yaml": file not found`) {
+		t.Errorf("ReadConfig(missing include) error = %v", err)
+	}
+}
+
+func TestSaveConfig_Includes(t *testing.T) {
+	t.Parallel()
+
+	dir := includedProject(t)
+	path := filepath.Join(dir, "datagen.toml")
+	cfg, err := ReadConfig(path)
+	if err != nil {
+		t.Fatalf("ReadConfig: %v", err)
+	}
+	untouched, err := os.ReadFile(filepath.Join(dir, "services", "a.toml"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	cfg.Services[3].Description = "changed"
+	cfg.Services = append(cfg.Services[:4], Service{Name: "new", Type: "api", Prompt: "new.md", APIPath: "/new"})
+	if err := SaveConfig(cfg, path); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if strings.Count(string(data), "[[service]]") != 2 || !strings.Contains(string(data), `name = "new"`) {
+		t.Fatalf("datagen.toml should hold only main and the new service:\n%s", data)
+	}
+	if again, _ := os.ReadFile(filepath.Join(dir, "services", "a.toml")); string(again) != string(untouched) {
+		t.Fatalf("unchanged include was rewritten:\n%s", again)
+	}
+	if extra, _ := os.ReadFile(filepath.Join(dir, "extra.yaml")); strings.Contains(string(extra), "name:") {
+		t.Fatalf("removed service still in extra.yaml:\n%s", extra)
+	}
+
+	cfg, err = ReadConfig(path)
+	if err != nil {
+		t.Fatalf("ReadConfig after save: %v", err)
+	}
+	if got := serviceNames(cfg); got != "main,new,a1,a2,b" {
+		t.Fatalf("services after save = %s, want main,new,a1,a2,b", got)
+	}
+	if cfg.Services[4].Description != "changed" {
+		t.Fatalf("included service edit was not saved: %+v", cfg.Services[4])
+	}
+}
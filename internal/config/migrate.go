@@ -0,0 +1,316 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// CurrentVersion is the datagen.toml format version this CLI reads and
+// writes. Bump it together with a new entry in migrations whenever a field
+// changes shape or meaning, so older files keep loading.
+const CurrentVersion = 1
+
+// migration upgrades a decoded datagen.toml by one format version
+type migration struct {
+	version int // the version the migration produces
+	// apply edits doc in place and describes each change it made
+	apply func(doc map[string]any) []string
+}
+
+var migrations = []migration{
+	{1, migrateServicePaths},
+}
+
+// migrateServicePaths moves a service's path to the key its type reads:
+// webhook_path for webhooks, api_path for the other types. Unversioned
+// configs were written by hand with either key.
+func migrateServicePaths(doc map[string]any) []string {
+	var changes []string
+	services, _ := doc["service"].([]map[string]any)
+	for _, svc := range services {
+		from, to := "webhook_path", "api_path"
+		if svc["type"] == "webhook" {
+			from, to = to, from
+		}
+		path, ok := svc[from]
+		if _, taken := svc[to]; !ok || taken {
+			continue
+		}
+		svc[to] = path
+		delete(svc, from)
+		changes = append(changes, fmt.Sprintf("service %v: moved %s to %s", svc["name"], from, to))
+	}
+	return changes
+}
+
+// configVersion returns doc's format version; files without one predate versioning
+func configVersion(doc map[string]any) int {
+	v, _ := doc["version"].(int64)
+	return int(v)
+}
+
+// Migrate upgrades doc, a decoded datagen.toml, to CurrentVersion in place
+// and returns a description of each change. A file newer than this CLI is an
+// error rather than being silently misread.
+func Migrate(doc map[string]any) ([]string, error) {
+	version := configVersion(doc)
+	if version > CurrentVersion {
+		return nil, fmt.Errorf("config format version %d is newer than this datagen supports (%d); upgrade datagen", version, CurrentVersion)
+	}
+	var changes []string
+	for _, m := range migrations {
+		if m.version <= version {
+			continue
+		}
+		changes = append(changes, m.apply(doc)...)
+		doc["version"] = int64(m.version)
+		changes = append(changes, fmt.Sprintf("version = %d", m.version))
+	}
+	return changes, nil
+}
+
+// migrateConfig re-decodes data into config after upgrading it to
+// CurrentVersion, when it was written in an older format
+func migrateConfig(format string, data []byte, config *DatagenConfig) error {
+	if config.Version == CurrentVersion {
+		return nil
+	}
+	doc, err := decodeDocument(format, data)
+	if err != nil {
+		return err
+	}
+	if _, err := Migrate(doc); err != nil {
+		return err
+	}
+	// The keys are the same in every format, so TOML carries the upgraded document
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(doc); err != nil {
+		return fmt.Errorf("failed to migrate config: %w", err)
+	}
+	*config = DatagenConfig{}
+	if _, err := toml.Decode(buf.String(), config); err != nil {
+		return fmt.Errorf("failed to migrate config: %w", err)
+	}
+	return nil
+}
+
+// decodeDocument decodes data in format into a generic document shaped like
+// the TOML decoder's output, which migrations expect: int64 numbers and
+// []map[string]any for lists of tables such as service
+func decodeDocument(format string, data []byte) (map[string]any, error) {
+	doc := map[string]any{}
+	if err := unmarshalConfig(format, data, &doc); err != nil {
+		return nil, err
+	}
+	return normalizeDocument(doc).(map[string]any), nil
+}
+
+func normalizeDocument(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			if e == nil {
+				delete(v, k)
+				continue
+			}
+			v[k] = normalizeDocument(e)
+		}
+		return v
+	case []any:
+		tables := make([]map[string]any, 0, len(v))
+		for i, e := range v {
+			v[i] = normalizeDocument(e)
+			if t, ok := v[i].(map[string]any); ok {
+				tables = append(tables, t)
+			}
+		}
+		if len(v) > 0 && len(tables) == len(v) {
+			return tables
+		}
+		return v
+	case int:
+		return int64(v)
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v)
+		}
+	}
+	return v
+}
+
+// FileMigration is the upgrade of one config file: its content before and
+// after and the changes made
+type FileMigration struct {
+	Path    string
+	Before  string
+	After   string
+	Changes []string
+}
+
+// MigrateFiles upgrades the config file at path and the files it includes to
+// CurrentVersion, returning the files that change. TOML files are edited in
+// place, so comments and layout survive; YAML and JSON files are re-encoded
+// from the upgraded document. Nothing is written.
+func MigrateFiles(path string) ([]FileMigration, error) {
+	path = FindConfig(path)
+	m, doc, err := migrateFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var migrated []FileMigration
+	if len(m.Changes) > 0 {
+		migrated = append(migrated, *m)
+	}
+
+	var patterns []string
+	includes, _ := doc["include"].([]any)
+	for _, p := range includes {
+		if p, ok := p.(string); ok {
+			patterns = append(patterns, p)
+		}
+	}
+	files, err := expandIncludes(patterns, filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		m, _, err := migrateFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if len(m.Changes) > 0 {
+			migrated = append(migrated, *m)
+		}
+	}
+	return migrated, nil
+}
+
+// migrateFile upgrades one file and also returns its document as it was read
+func migrateFile(path string) (*FileMigration, map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	format := FormatOf(path)
+	original, err := decodeDocument(format, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, _ := decodeDocument(format, data)
+	changes, err := Migrate(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	m := &FileMigration{Path: path, Before: string(data), After: string(data), Changes: changes}
+	if len(changes) == 0 {
+		return m, original, nil
+	}
+
+	if format == FormatTOML {
+		if m.After, err = editTOML(m.Before, original, doc); err != nil {
+			return nil, nil, fmt.Errorf("%w; make these changes by hand: %s", err, strings.Join(changes, ", "))
+		}
+		return m, original, nil
+	}
+	var buf bytes.Buffer
+	if err := encodeAs(format, doc, &buf); err != nil {
+		return nil, nil, err
+	}
+	m.After = buf.String()
+	return m, original, nil
+}
+
+var tomlServiceHeader = regexp.MustCompile(`^\s*\[\[\s*service\s*\]\]`)
+
+// editTOML applies to text, the TOML source of original, the edits that
+// turned original into migrated: service keys moved to another name and the
+// version key. Every other line is kept as written.
+func editTOML(text string, original, migrated map[string]any) (string, error) {
+	lines := strings.Split(text, "\n")
+
+	before, _ := original["service"].([]map[string]any)
+	after, _ := migrated["service"].([]map[string]any)
+	var headers []int
+	for i, line := range lines {
+		if tomlServiceHeader.MatchString(line) {
+			headers = append(headers, i)
+		}
+	}
+	if len(headers) != len(before) {
+		return "", fmt.Errorf("can't find the [[service]] tables to edit")
+	}
+	for i, svc := range before {
+		for from, value := range svc {
+			if _, kept := after[i][from]; kept {
+				continue
+			}
+			for to, moved := range after[i] {
+				if _, existed := svc[to]; existed || !reflect.DeepEqual(moved, value) {
+					continue
+				}
+				if !renameTOMLKey(lines, headers[i]+1, from, to) {
+					return "", fmt.Errorf("can't find %s of service %v", from, svc["name"])
+				}
+			}
+		}
+	}
+
+	// The version goes with the top-level keys, before any table
+	version := fmt.Sprintf("version = %d", configVersion(migrated))
+	versionKey := regexp.MustCompile(`^\s*version\s*=`)
+	at := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			break
+		}
+		if versionKey.MatchString(line) {
+			lines[i] = version
+			return strings.Join(lines, "\n"), nil
+		}
+		if at < 0 && trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			at = i
+		}
+	}
+	if at < 0 {
+		// No top-level keys: the version opens the first table's block
+		at = findFirstTable(lines)
+		return strings.Join(slices.Insert(lines, at, version, ""), "\n"), nil
+	}
+	return strings.Join(slices.Insert(lines, at, version), "\n"), nil
+}
+
+// findFirstTable returns the index of the first table header, or the end
+// of lines when there is none
+func findFirstTable(lines []string) int {
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "[") {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+// renameTOMLKey renames the key from to to in the table whose keys start at
+// line start, keeping the value and any comment after it
+func renameTOMLKey(lines []string, start int, from, to string) bool {
+	key := regexp.MustCompile(`^(\s*)` + regexp.QuoteMeta(from) + `(\s*=)`)
+	for i := start; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), "[") {
+			return false
+		}
+		if key.MatchString(lines[i]) {
+			lines[i] = key.ReplaceAllString(lines[i], "${1}"+to+"${2}")
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const unversionedConfig = `datagen_api_key_env = "DATAGEN_API_KEY"
+claude_api_key_env = "ANTHROPIC_API_KEY"
+
+[[service]]
+name = "hook"
+type = "webhook"
+prompt = "hook.md"
+api_path = "/webhook/hook"
+
+[[service]]
+name = "chat"
+type = "api"
+prompt = "chat.md"
+api_path = "/chat"
+`
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	doc := map[string]any{
+		"service": []map[string]any{
+			{"name": "hook", "type": "webhook", "api_path": "/webhook/hook"},
+			{"name": "chat", "type": "api", "webhook_path": "/chat"},
+			{"name": "both", "type": "api", "api_path": "/both", "webhook_path": "/other"},
+		},
+	}
+	changes, err := Migrate(doc)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	want := []string{"service hook: moved api_path to webhook_path", "service chat: moved webhook_path to api_path", "version = 1"}
+	if strings.Join(changes, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("changes = %q, want %q", changes, want)
+	}
+	services := doc["service"].([]map[string]any)
+	if services[0]["webhook_path"] != "/webhook/hook" || services[1]["api_path"] != "/chat" || services[2]["api_path"] != "/both" {
+		t.Fatalf("services after Migrate = %v", services)
+	}
+	if configVersion(doc) != CurrentVersion {
+		t.Fatalf("version = %v, want %d", doc["version"], CurrentVersion)
+	}
+
+	if changes, err := Migrate(doc); err != nil || len(changes) != 0 {
+		t.Fatalf("Migrate(current) = %q, %v; want no changes", changes, err)
+	}
+	if _, err := Migrate(map[string]any{"version": int64(CurrentVersion + 1)}); err == nil {
+		t.Fatal("Migrate accepted a newer format version")
+	}
+}
+
+func TestReadConfig_MigratesOlderFormat(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "datagen.toml")
+	if err := os.WriteFile(path, []byte(unversionedConfig), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	cfg, err := ReadConfig(path)
+	if err != nil {
+		t.Fatalf("ReadConfig: %v", err)
+	}
+	if cfg.Version != CurrentVersion || cfg.Services[0].WebhookPath != "/webhook/hook" || cfg.Services[0].APIPath != "" {
+		t.Fatalf("ReadConfig = version %d, services %+v", cfg.Version, cfg.Services)
+	}
+	if cfg.Dir != filepath.Dir(path) {
+		t.Fatalf("Dir = %q, want %q", cfg.Dir, filepath.Dir(path))
+	}
+
+	if err := os.WriteFile(path, []byte("version = 99\n"+unversionedConfig), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := ReadConfig(path); err == nil || !strings.Contains(err.Error(), "upgrade datagen") {
+		t.Fatalf("ReadConfig(version 99) error = %v, want a newer-format error", err)
+	}
+}
+
+func TestMigrateFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "datagen.toml")
+	if err := os.WriteFile(path, []byte(unversionedConfig), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	migrations, err := MigrateFiles(path)
+	if err != nil {
+		t.Fatalf("MigrateFiles: %v", err)
+	}
+	if len(migrations) != 1 || migrations[0].Before != unversionedConfig || len(migrations[0].Changes) != 2 {
+		t.Fatalf("MigrateFiles = %+v", migrations)
+	}
+	after := migrations[0].After
+	want := "version = 1\n" + strings.Replace(unversionedConfig, `api_path = "/webhook/hook"`, `webhook_path = "/webhook/hook"`, 1)
+	if after != want {
+		t.Fatalf("migrated config:\n%s\nwant:\n%s", after, want)
+	}
+
+	if err := os.WriteFile(path, []byte(after), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if migrations, err := MigrateFiles(path); err != nil || len(migrations) != 0 {
+		t.Fatalf("MigrateFiles(migrated) = %+v, %v; want no changes", migrations, err)
+	}
+}
+
+func TestMigrateFiles_KeepsLayoutAndIncludes(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "datagen.toml")
+	main := `# Support agents
+
+include = ["services/*.toml"]
+
+[[service]]
+name = "hook"   # the intake webhook
+type = "webhook"
+prompt = "hook.md"
+  api_path = "/webhook/hook" # keep in sync with the dashboard
+
+[service.auth]
+type = "api_key"
+`
+	included := `[[service]]
+name = "chat"
+type = "api"
+prompt = "chat.md"
+webhook_path = "/chat"
+`
+	if err := os.MkdirAll(filepath.Join(dir, "services"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(main), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "services", "chat.toml"), []byte(included), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	migrations, err := MigrateFiles(path)
+	if err != nil {
+		t.Fatalf("MigrateFiles: %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("MigrateFiles = %+v, want datagen.toml and services/chat.toml", migrations)
+	}
+	wantMain := strings.Replace(main, "include =", "version = 1\ninclude =", 1)
+	wantMain = strings.Replace(wantMain, "  api_path =", "  webhook_path =", 1)
+	if migrations[0].After != wantMain {
+		t.Errorf("migrated datagen.toml:\n%s\nwant:\n%s", migrations[0].After, wantMain)
+	}
+	wantIncluded := "version = 1\n\n" + strings.Replace(included, "webhook_path", "api_path", 1)
+	if migrations[1].Path != filepath.Join(dir, "services", "chat.toml") || migrations[1].After != wantIncluded {
+		t.Errorf("migrated %s:\n%s\nwant:\n%s", migrations[1].Path, migrations[1].After, wantIncluded)
+	}
+
+	for _, m := range migrations {
+		if err := os.WriteFile(m.Path, []byte(m.After), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg, err := ReadConfig(path)
+	if err != nil {
+		t.Fatalf("ReadConfig(migrated): %v", err)
+	}
+	if cfg.Services[0].WebhookPath != "/webhook/hook" || cfg.Services[1].APIPath != "/chat" {
+		t.Fatalf("ReadConfig(migrated) services = %+v", cfg.Services)
+	}
+}
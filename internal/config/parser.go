@@ -1,46 +1,188 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/BurntSushi/toml"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/datagendev/datagen-cli/internal/debuglog"
+	"github.com/datagendev/datagen-cli/internal/undo"
 )
 
-// LoadConfig reads and parses a datagen.toml file
-func LoadConfig(path string) (*DatagenConfig, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+// Config file formats, chosen by the file's extension. The keys are the same
+// in each.
+const (
+	FormatTOML = "toml"
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// Formats lists the config file formats, TOML first as the default
+var Formats = []string{FormatTOML, FormatYAML, FormatJSON}
+
+// FormatOf returns the format of the config file at path: YAML for .yaml and
+// .yml, JSON for .json, and TOML otherwise
+func FormatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".json":
+		return FormatJSON
 	}
+	return FormatTOML
+}
 
-	var config DatagenConfig
-	if err := toml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse TOML: %w", err)
+// FileName returns the config file name for format: datagen.toml, datagen.yaml or datagen.json
+func FileName(format string) string {
+	return "datagen." + format
+}
+
+// FindConfig returns path, unless it is a datagen.toml that does not exist
+// and a datagen.yaml, datagen.yml or datagen.json does next to it. Commands
+// default --config to datagen.toml, so this lets them find projects kept in
+// the other formats.
+func FindConfig(path string) string {
+	if filepath.Base(path) != FileName(FormatTOML) {
+		return path
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return path
+	}
+	for _, name := range []string{"datagen.yaml", "datagen.yml", "datagen.json"} {
+		alt := filepath.Join(filepath.Dir(path), name)
+		if _, err := os.Stat(alt); err == nil {
+			return alt
+		}
+	}
+	return path
+}
+
+// LoadConfig reads and parses a datagen.toml (or .yaml/.json) file
+func LoadConfig(path string) (*DatagenConfig, error) {
+	path = FindConfig(path)
+	config, err := ReadConfig(path)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get config directory for resolving relative paths
 	configDir := filepath.Dir(path)
-	if err := ValidateConfig(&config, configDir); err != nil {
+	if err := ValidateConfig(config, configDir); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
 	}
 
+	return config, nil
+}
+
+// ReadConfig parses a datagen.toml file without validating it, for commands
+// that repair a config whose prompt files have moved
+func ReadConfig(path string) (*DatagenConfig, error) {
+	path = FindConfig(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	format := FormatOf(path)
+	var config DatagenConfig
+	if err := unmarshalConfig(format, data, &config); err != nil {
+		return nil, err
+	}
+	// Older formats are upgraded in memory; 'datagen migrate' rewrites the file
+	if err := migrateConfig(format, data, &config); err != nil {
+		return nil, err
+	}
+	if err := mergeIncludes(&config, path); err != nil {
+		return nil, err
+	}
+	config.ApplyDefaults()
+	config.Dir = filepath.Dir(path)
 	return &config, nil
 }
 
-// SaveConfig writes a DatagenConfig to a TOML file
+// SaveConfig writes a DatagenConfig to a config file, in the format its
+// extension names. Services read from included files are written back to
+// those files; new services go to the config file itself. Settings equal to
+// [defaults] are left out of each service so they stay inherited.
 func SaveConfig(config *DatagenConfig, path string) error {
-	f, err := os.Create(path)
-	if err != nil {
-		return fmt.Errorf("failed to create config file: %w", err)
+	path = FindConfig(path)
+	var buf bytes.Buffer
+	if err := encodeConfigAs(FormatOf(path), config, &buf); err != nil {
+		return err
+	}
+	if err := undo.Save(path); err != nil {
+		return err
+	}
+	debuglog.FileWrite(path, buf.Bytes())
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
 	}
-	defer f.Close()
+	if len(config.Include) > 0 {
+		return saveIncludes(config, path)
+	}
+	return nil
+}
 
-	encoder := toml.NewEncoder(f)
-	if err := encoder.Encode(config); err != nil {
+// EncodeConfig writes a DatagenConfig as TOML to w, in the current format
+// version; like SaveConfig it leaves out services from included files
+func EncodeConfig(config *DatagenConfig, w io.Writer) error {
+	return encodeConfigAs(FormatTOML, config, w)
+}
+
+func encodeConfigAs(format string, config *DatagenConfig, w io.Writer) error {
+	current := *ownServices(config)
+	current.Version = CurrentVersion
+	current.Services = withoutDefaults(current.Defaults, current.Services)
+	return encodeAs(format, &current, w)
+}
+
+// encodeAs writes v to w in format
+func encodeAs(format string, v any, w io.Writer) error {
+	switch format {
+	case FormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(v); err != nil {
+			return fmt.Errorf("failed to encode YAML: %w", err)
+		}
+		return encoder.Close()
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(v); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		return nil
+	}
+	encoder := toml.NewEncoder(w)
+	if err := encoder.Encode(v); err != nil {
 		return fmt.Errorf("failed to encode TOML: %w", err)
 	}
 
 	return nil
 }
+
+// unmarshalConfig decodes data in format into v, a *DatagenConfig or a
+// generic map[string]any document
+func unmarshalConfig(format string, data []byte, v any) error {
+	var err error
+	switch format {
+	case FormatYAML:
+		err = yaml.Unmarshal(data, v)
+	case FormatJSON:
+		err = json.Unmarshal(data, v)
+	default:
+		err = toml.Unmarshal(data, v)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", strings.ToUpper(format), err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigFormats_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	want := &DatagenConfig{
+		Version:          CurrentVersion,
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Server:           &ServerConfig{GZip: true, Workers: 2},
+		Services: []Service{
+			{
+				Name:         "chat",
+				Type:         "api",
+				Prompt:       "chat.md",
+				APIPath:      "/chat",
+				AllowedTools: &AllowedTools{SearchTools: true},
+				InputSchema:  Schema{Fields: []Field{{Name: "q", Type: "str", Required: true}}},
+				API:          &APIConfig{ResponseFormat: "json", Timeout: 30},
+			},
+			{
+				Name:        "hook",
+				Type:        "webhook",
+				Prompt:      "hook.md",
+				WebhookPath: "/webhook/hook",
+				InputSchema: Schema{Fields: []Field{{Name: "event", Type: "dict"}}},
+				Webhook:     &WebhookConfig{SignatureVerification: "hmac_sha256", SecretEnv: "HOOK_SECRET"},
+			},
+		},
+		Dependencies: map[string]string{"fastapi": "0.115.12"},
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{"datagen.toml", "datagen.yaml", "datagen.yml", "datagen.json"} {
+		path := filepath.Join(dir, name)
+		if err := SaveConfig(want, path); err != nil {
+			t.Fatalf("SaveConfig(%s): %v", name, err)
+		}
+		got, err := ReadConfig(path)
+		if err != nil {
+			t.Fatalf("ReadConfig(%s): %v", name, err)
+		}
+		got.Dir = ""
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s round trip:\ngot  %+v\nwant %+v", name, got, want)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "datagen.yaml"))
+	if err != nil {
+		t.Fatalf("read datagen.yaml: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "version: 1\n") || !strings.Contains(string(data), "webhook_path: /webhook/hook") {
+		t.Fatalf("datagen.yaml:\n%s", data)
+	}
+}
+
+// TestConfigFormats_SameKeys keeps the YAML and JSON keys of every config
+// field identical to its TOML key, which migrations rely on
+func TestConfigFormats_SameKeys(t *testing.T) {
+	t.Parallel()
+
+	seen := map[reflect.Type]bool{}
+	var check func(reflect.Type)
+	check = func(typ reflect.Type) {
+		for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct || seen[typ] {
+			return
+		}
+		seen[typ] = true
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			tag := f.Tag.Get("toml")
+			if tag == "" || f.Tag.Get("json") != tag || f.Tag.Get("yaml") != tag {
+				t.Errorf("%s.%s: toml %q, json %q, yaml %q", typ.Name(), f.Name, tag, f.Tag.Get("json"), f.Tag.Get("yaml"))
+			}
+			check(f.Type)
+		}
+	}
+	check(reflect.TypeOf(DatagenConfig{}))
+}
+
+func TestFindConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	tomlPath := filepath.Join(dir, "datagen.toml")
+	if got := FindConfig(tomlPath); got != tomlPath {
+		t.Fatalf("FindConfig(no config) = %q, want %q", got, tomlPath)
+	}
+	yamlPath := filepath.Join(dir, "datagen.yaml")
+	if err := os.WriteFile(yamlPath, []byte("version: 1\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if got := FindConfig(tomlPath); got != yamlPath {
+		t.Fatalf("FindConfig = %q, want %q", got, yamlPath)
+	}
+	if other := filepath.Join(dir, "other.toml"); FindConfig(other) != other {
+		t.Fatalf("FindConfig replaced an explicit config path")
+	}
+	if err := os.WriteFile(tomlPath, []byte("version = 1\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if got := FindConfig(tomlPath); got != tomlPath {
+		t.Fatalf("FindConfig(both) = %q, want the existing datagen.toml", got)
+	}
+}
+
+func TestReadConfig_MigratesOlderYAMLAndJSON(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"datagen.yaml": "datagen_api_key_env: DATAGEN_API_KEY\nclaude_api_key_env: ANTHROPIC_API_KEY\nservice:\n  - name: hook\n    type: webhook\n    prompt: hook.md\n    api_path: /webhook/hook\n    webhook:\n      retry_enabled: true\n      max_retries: 3\n",
+		"datagen.json": `{"datagen_api_key_env": "DATAGEN_API_KEY", "claude_api_key_env": "ANTHROPIC_API_KEY", "service": [{"name": "hook", "type": "webhook", "prompt": "hook.md", "api_path": "/webhook/hook", "webhook": {"retry_enabled": true, "max_retries": 3}}]}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		cfg, err := ReadConfig(path)
+		if err != nil {
+			t.Fatalf("ReadConfig(%s): %v", name, err)
+		}
+		svc := cfg.Services[0]
+		if cfg.Version != CurrentVersion || svc.WebhookPath != "/webhook/hook" || svc.Webhook == nil || svc.Webhook.MaxRetries != 3 {
+			t.Errorf("ReadConfig(%s) = version %d, service %+v", name, cfg.Version, svc)
+		}
+	}
+}
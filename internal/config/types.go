@@ -1,98 +1,633 @@
 package config
 
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
 // DatagenConfig represents the full datagen.toml configuration
 type DatagenConfig struct {
-	DatagenAPIKeyEnv string    `toml:"datagen_api_key_env"`
-	ClaudeAPIKeyEnv  string    `toml:"claude_api_key_env"`
-	Services         []Service `toml:"service"`
+	Version          int            `toml:"version,omitempty" json:"version,omitempty" yaml:"version,omitempty"` // format version, see CurrentVersion; absent in files that predate it
+	DatagenAPIKeyEnv string         `toml:"datagen_api_key_env" json:"datagen_api_key_env" yaml:"datagen_api_key_env"`
+	ClaudeAPIKeyEnv  string         `toml:"claude_api_key_env" json:"claude_api_key_env" yaml:"claude_api_key_env"`
+	Server           *ServerConfig  `toml:"server,omitempty" json:"server,omitempty" yaml:"server,omitempty"`
+	Scaling          *ScalingConfig `toml:"scaling,omitempty" json:"scaling,omitempty" yaml:"scaling,omitempty"`
+	Claude           *ClaudeConfig  `toml:"claude,omitempty" json:"claude,omitempty" yaml:"claude,omitempty"`
+	Railway          *RailwayConfig `toml:"railway,omitempty" json:"railway,omitempty" yaml:"railway,omitempty"`
+	Capture          *CaptureConfig `toml:"capture,omitempty" json:"capture,omitempty" yaml:"capture,omitempty"`
+	Docker           *DockerConfig  `toml:"docker,omitempty" json:"docker,omitempty" yaml:"docker,omitempty"`
+	PythonPackaging  string         `toml:"python_packaging,omitempty" json:"python_packaging,omitempty" yaml:"python_packaging,omitempty"` // pip (default), uv, poetry
+	TemplatesDir     string         `toml:"templates_dir,omitempty" json:"templates_dir,omitempty" yaml:"templates_dir,omitempty"`          // overrides for the app templates (agent.py.tmpl, ...), relative to datagen.toml
+	PythonFormatter  string         `toml:"python_formatter,omitempty" json:"python_formatter,omitempty" yaml:"python_formatter,omitempty"` // ruff or black, run over the generated Python when installed
+
+	// Defaults are inherited by every service that does not set them itself
+	Defaults *ServiceDefaults `toml:"defaults,omitempty" json:"defaults,omitempty" yaml:"defaults,omitempty"`
+
+	// Include lists files or glob patterns, relative to datagen.toml, whose
+	// [[service]] tables are appended to Services when the config is read
+	Include  []string  `toml:"include,omitempty" json:"include,omitempty" yaml:"include,omitempty"`
+	Services []Service `toml:"service" json:"service" yaml:"service"`
+
+	// Environments holds per-environment overrides keyed by name (e.g. staging, production)
+	Environments map[string]EnvironmentConfig `toml:"environments,omitempty" json:"environments,omitempty" yaml:"environments,omitempty"`
+
+	// Dependencies pins runtime packages to exact versions by name, as
+	// written by datagen deps refresh
+	Dependencies map[string]string `toml:"dependencies,omitempty" json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+
+	// Dir is the directory datagen.toml was read from, which relative paths
+	// such as templates_dir resolve against; empty for configs built in memory
+	Dir string `toml:"-" json:"-" yaml:"-"`
+}
+
+// ServiceDefaults holds the settings services repeat most often. A service
+// inherits each one it leaves unset: no auth table, no allowed_tools table,
+// no model, or for api services no api.timeout.
+type ServiceDefaults struct {
+	Auth         *Auth         `toml:"auth,omitempty" json:"auth,omitempty" yaml:"auth,omitempty"`
+	Timeout      int           `toml:"timeout,omitempty" json:"timeout,omitempty" yaml:"timeout,omitempty"` // seconds, for api services
+	AllowedTools *AllowedTools `toml:"allowed_tools,omitempty" json:"allowed_tools,omitempty" yaml:"allowed_tools,omitempty"`
+	Model        string        `toml:"model,omitempty" json:"model,omitempty" yaml:"model,omitempty"`
+}
+
+// EnvironmentConfig describes where and with which variables an environment runs
+type EnvironmentConfig struct {
+	RailwayEnvironment string            `toml:"railway_environment,omitempty" json:"railway_environment,omitempty" yaml:"railway_environment,omitempty"` // defaults to the environment name
+	RailwayProject     string            `toml:"railway_project,omitempty" json:"railway_project,omitempty" yaml:"railway_project,omitempty"`             // rejected: the Railway CLI only targets the linked project
+	EnvFile            string            `toml:"env_file,omitempty" json:"env_file,omitempty" yaml:"env_file,omitempty"`                                  // defaults to .env.<name>
+	Variables          map[string]string `toml:"variables,omitempty" json:"variables,omitempty" yaml:"variables,omitempty"`                               // non-secret values applied on top of env_file
+	Railway            *RailwayConfig    `toml:"railway,omitempty" json:"railway,omitempty" yaml:"railway,omitempty"`                                     // railway.json deploy overrides for this environment
+}
+
+// RailwayConfig holds Railway config-as-code settings written to railway.json
+type RailwayConfig struct {
+	HealthcheckPath    string   `toml:"healthcheck_path,omitempty" json:"healthcheck_path,omitempty" yaml:"healthcheck_path,omitempty"`          // defaults to /health
+	HealthcheckTimeout int      `toml:"healthcheck_timeout,omitempty" json:"healthcheck_timeout,omitempty" yaml:"healthcheck_timeout,omitempty"` // seconds Railway waits for a healthy deploy
+	Region             string   `toml:"region,omitempty" json:"region,omitempty" yaml:"region,omitempty"`                                        // e.g. us-west2, europe-west4
+	Replicas           int      `toml:"replicas,omitempty" json:"replicas,omitempty" yaml:"replicas,omitempty"`                                  // overrides scaling.min_replicas
+	CronSchedule       string   `toml:"cron_schedule,omitempty" json:"cron_schedule,omitempty" yaml:"cron_schedule,omitempty"`                   // run as a Railway cron job instead of a long-lived server
+	WatchPatterns      []string `toml:"watch_patterns,omitempty" json:"watch_patterns,omitempty" yaml:"watch_patterns,omitempty"`                // only redeploy when matching files change
+}
+
+// CaptureConfig makes the generated app record sanitized request/response
+// pairs of its services for 'datagen replay'
+type CaptureConfig struct {
+	Dir      string   `toml:"dir,omitempty" json:"dir,omitempty" yaml:"dir,omitempty"`                   // local directory, relative to the app (default recordings)
+	S3Bucket string   `toml:"s3_bucket,omitempty" json:"s3_bucket,omitempty" yaml:"s3_bucket,omitempty"` // also upload each recording to this bucket
+	S3Prefix string   `toml:"s3_prefix,omitempty" json:"s3_prefix,omitempty" yaml:"s3_prefix,omitempty"` // key prefix in the bucket (default recordings/)
+	Redact   []string `toml:"redact,omitempty" json:"redact,omitempty" yaml:"redact,omitempty"`          // extra payload field names to mask, besides secrets
+}
+
+// DockerConfig shapes the generated Dockerfile
+type DockerConfig struct {
+	PythonVersion string `toml:"python_version,omitempty" json:"python_version,omitempty" yaml:"python_version,omitempty"` // Python the image runs (default 3.13)
+	BaseImage     string `toml:"base_image,omitempty" json:"base_image,omitempty" yaml:"base_image,omitempty"`             // image for both stages (default python:<python_version>-slim)
+	MultiStage    bool   `toml:"multi_stage,omitempty" json:"multi_stage,omitempty" yaml:"multi_stage,omitempty"`          // install dependencies in a builder stage and copy only the virtualenv
+	CacheMounts   bool   `toml:"cache_mounts,omitempty" json:"cache_mounts,omitempty" yaml:"cache_mounts,omitempty"`       // keep the package cache between builds (needs BuildKit)
+}
+
+// DefaultPythonVersion is the Python the generated image runs
+const DefaultPythonVersion = "3.13"
+
+// PythonVersion returns the Python version the generated project targets
+func (c *DatagenConfig) PythonVersion() string {
+	if c.Docker == nil || c.Docker.PythonVersion == "" {
+		return DefaultPythonVersion
+	}
+	return c.Docker.PythonVersion
+}
+
+// BaseImage returns the image the generated Dockerfile builds on
+func (c *DatagenConfig) BaseImage() string {
+	if c.Docker == nil || c.Docker.BaseImage == "" {
+		return "python:" + c.PythonVersion() + "-slim"
+	}
+	return c.Docker.BaseImage
+}
+
+// DockerMultiStage reports whether the Dockerfile uses a builder stage
+func (c *DatagenConfig) DockerMultiStage() bool {
+	return c.Docker != nil && c.Docker.MultiStage
+}
+
+// DockerCacheMounts reports whether dependency installs use BuildKit cache mounts
+func (c *DatagenConfig) DockerCacheMounts() bool {
+	return c.Docker != nil && c.Docker.CacheMounts
+}
+
+// Python packaging tools the generated project can be managed with
+const (
+	PackagingPip    = "pip"
+	PackagingUV     = "uv"
+	PackagingPoetry = "poetry"
+)
+
+// Packaging returns how the generated project declares its dependencies:
+// requirements.txt for pip, pyproject.toml and a lockfile for uv and poetry
+func (c *DatagenConfig) Packaging() string {
+	if c.PythonPackaging == "" {
+		return PackagingPip
+	}
+	return c.PythonPackaging
+}
+
+// Formatters python_formatter can run over the generated Python
+const (
+	FormatterRuff  = "ruff"
+	FormatterBlack = "black"
+)
+
+// TemplateOverrideDir returns templates_dir resolved against the config
+// directory, or "" when the embedded templates are used as-is
+func (c *DatagenConfig) TemplateOverrideDir() string {
+	if c.TemplatesDir == "" || filepath.IsAbs(c.TemplatesDir) {
+		return c.TemplatesDir
+	}
+	return filepath.Join(c.Dir, c.TemplatesDir)
+}
+
+// DefaultCaptureDir is where recordings go when [capture] sets no dir
+const DefaultCaptureDir = "recordings"
+
+// UsesCapture reports whether the generated app records requests
+func (c *DatagenConfig) UsesCapture() bool {
+	return c.Capture != nil
+}
+
+// CaptureDir returns the directory recordings are written to
+func (c *DatagenConfig) CaptureDir() string {
+	if c.Capture == nil || c.Capture.Dir == "" {
+		return DefaultCaptureDir
+	}
+	return c.Capture.Dir
+}
+
+// ServerConfig contains options for the generated HTTP server
+type ServerConfig struct {
+	HTTP2      bool   `toml:"http2" json:"http2" yaml:"http2"`                                                 // serve with hypercorn for HTTP/2 support
+	GZip       bool   `toml:"gzip" json:"gzip" yaml:"gzip"`                                                    // compress responses with GZipMiddleware
+	Workers    int    `toml:"workers,omitempty" json:"workers,omitempty" yaml:"workers,omitempty"`             // server worker processes per replica (default 1)
+	StateStore string `toml:"state_store,omitempty" json:"state_store,omitempty" yaml:"state_store,omitempty"` // memory (default) or redis, for rate limits and webhook idempotency
+	Playground bool   `toml:"playground,omitempty" json:"playground,omitempty" yaml:"playground,omitempty"`    // serve a /playground page for trying the services from a browser
+}
+
+// State stores for rate limits and webhook idempotency keys
+const (
+	StateStoreMemory = "memory"
+	StateStoreRedis  = "redis"
+)
+
+// ScalingConfig contains horizontal scaling hints for the generated app
+type ScalingConfig struct {
+	TargetConcurrency int `toml:"target_concurrency" json:"target_concurrency" yaml:"target_concurrency"`             // in-flight agent runs per replica before it reports not ready
+	MinReplicas       int `toml:"min_replicas,omitempty" json:"min_replicas,omitempty" yaml:"min_replicas,omitempty"` // replicas to start with
+	MaxReplicas       int `toml:"max_replicas,omitempty" json:"max_replicas,omitempty" yaml:"max_replicas,omitempty"` // upper bound for autoscalers
+}
+
+// TargetConcurrency returns the per-replica in-flight execution target (0 means unbounded)
+func (c *DatagenConfig) TargetConcurrency() int {
+	if c.Scaling == nil {
+		return 0
+	}
+	return c.Scaling.TargetConcurrency
+}
+
+// ClaudeConfig tunes how the generated app calls the Claude API
+type ClaudeConfig struct {
+	MaxRetries     int `toml:"max_retries" json:"max_retries" yaml:"max_retries"`             // retries per request on 429/529 and network errors (0 uses the default)
+	Timeout        int `toml:"timeout" json:"timeout" yaml:"timeout"`                         // per-request timeout in seconds (0 uses the default)
+	MaxConcurrency int `toml:"max_concurrency" json:"max_concurrency" yaml:"max_concurrency"` // agent runs calling Claude at once per replica (0 = unbounded)
+}
+
+// Defaults used when [claude] leaves a setting unset
+const (
+	DefaultClaudeMaxRetries = 2
+	DefaultClaudeTimeout    = 600
+)
+
+// ClaudeMaxRetries returns the configured retry count, defaulting to DefaultClaudeMaxRetries
+func (c *DatagenConfig) ClaudeMaxRetries() int {
+	if c.Claude == nil || c.Claude.MaxRetries == 0 {
+		return DefaultClaudeMaxRetries
+	}
+	return c.Claude.MaxRetries
+}
+
+// ClaudeTimeout returns the per-request timeout in seconds, defaulting to DefaultClaudeTimeout
+func (c *DatagenConfig) ClaudeTimeout() int {
+	if c.Claude == nil || c.Claude.Timeout == 0 {
+		return DefaultClaudeTimeout
+	}
+	return c.Claude.Timeout
+}
+
+// ClaudeMaxConcurrency returns the per-replica cap on concurrent Claude runs (0 means unbounded)
+func (c *DatagenConfig) ClaudeMaxConcurrency() int {
+	if c.Claude == nil {
+		return 0
+	}
+	return c.Claude.MaxConcurrency
+}
+
+// UsesHTTP2 reports whether the generated server should run under hypercorn with HTTP/2
+func (c *DatagenConfig) UsesHTTP2() bool {
+	return c.Server != nil && c.Server.HTTP2
+}
+
+// UsesPlayground reports whether the generated app serves the /playground page
+func (c *DatagenConfig) UsesPlayground() bool {
+	return c.Server != nil && c.Server.Playground
+}
+
+// Workers returns the number of server worker processes per replica
+func (c *DatagenConfig) Workers() int {
+	if c.Server == nil || c.Server.Workers < 1 {
+		return 1
+	}
+	return c.Server.Workers
+}
+
+// StateStore returns where the generated app keeps rate limit and idempotency state
+func (c *DatagenConfig) StateStore() string {
+	if c.Server == nil || c.Server.StateStore == "" {
+		return StateStoreMemory
+	}
+	return c.Server.StateStore
+}
+
+// UsesRedis reports whether the generated app needs the Redis client
+func (c *DatagenConfig) UsesRedis() bool {
+	return c.StateStore() == StateStoreRedis
+}
+
+// HasSharedState reports whether any service keeps per-process state that
+// must be shared across workers: API rate limits or webhook idempotency keys
+func (c *DatagenConfig) HasSharedState() bool {
+	for _, svc := range c.Services {
+		if svc.Type == "webhook" {
+			return true
+		}
+		if svc.API != nil && svc.API.RateLimitEnabled {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesStreaming reports whether any service streams its reply
+func (c *DatagenConfig) UsesStreaming() bool {
+	for _, svc := range c.Services {
+		if svc.Type == "streaming" {
+			return true
+		}
+	}
+	return false
+}
+
+// StateStoreWarning explains why in-memory state is unsafe for this config,
+// or returns "" when it is fine: a single worker and replica, no stateful
+// features, or a Redis store
+func (c *DatagenConfig) StateStoreWarning() string {
+	if c.UsesRedis() || !c.HasSharedState() {
+		return ""
+	}
+	replicas := 1
+	if c.Scaling != nil {
+		replicas = max(c.Scaling.MinReplicas, c.Scaling.MaxReplicas, 1)
+	}
+	if c.Railway != nil && c.Railway.Replicas > replicas {
+		replicas = c.Railway.Replicas
+	}
+	if c.Workers() == 1 && replicas == 1 {
+		return ""
+	}
+	return fmt.Sprintf("%d worker(s) x %d replica(s) with the in-memory state store: rate limits and webhook idempotency keys are tracked per process; set [server] state_store = \"redis\" and REDIS_URL to share them", c.Workers(), replicas)
 }
 
 // RequiresDatagenAPIKey reports whether the generated runtime should require a DataGen API key.
 // This is inferred from whether any service enables DataGen tool usage.
 func (c *DatagenConfig) RequiresDatagenAPIKey() bool {
 	for _, svc := range c.Services {
-		if svc.AllowedTools.SearchTools ||
-			svc.AllowedTools.ExecuteTools ||
-			svc.AllowedTools.ExecuteCode ||
-			svc.AllowedTools.GetToolDetails {
+		if tools := svc.Tools(); tools.SearchTools ||
+			tools.ExecuteTools ||
+			tools.ExecuteCode ||
+			tools.GetToolDetails {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiresClaudeAPIKey reports whether any service calls Claude through the
+// Anthropic API rather than Bedrock or Vertex
+func (c *DatagenConfig) RequiresClaudeAPIKey() bool {
+	if len(c.Services) == 0 {
+		return true
+	}
+	for _, svc := range c.Services {
+		if svc.ProviderName() == ProviderAnthropic {
 			return true
 		}
 	}
 	return false
 }
 
+// ProviderEnvVars returns the provider variables of all services, sorted and
+// de-duplicated
+func (c *DatagenConfig) ProviderEnvVars() []string {
+	seen := map[string]bool{}
+	var vars []string
+	for _, svc := range c.Services {
+		for _, v := range svc.ProviderEnvVars() {
+			if !seen[v] {
+				seen[v] = true
+				vars = append(vars, v)
+			}
+		}
+	}
+	sort.Strings(vars)
+	return vars
+}
+
 // Service represents a single service/endpoint configuration
 type Service struct {
-	Name         string       `toml:"name"`
-	Type         string       `toml:"type"` // webhook, api, streaming
-	Description  string       `toml:"description"`
-	Prompt       string       `toml:"prompt"`
-	AllowedTools AllowedTools `toml:"allowed_tools"`
-	InputSchema  Schema       `toml:"input_schema"`
-	OutputSchema *Schema      `toml:"output_schema,omitempty"` // Only for API endpoints
-	Auth         *Auth        `toml:"auth,omitempty"`
+	Name         string            `toml:"name" json:"name" yaml:"name"`
+	Type         string            `toml:"type" json:"type" yaml:"type"` // webhook, api, streaming, pipeline
+	Description  string            `toml:"description" json:"description" yaml:"description"`
+	Prompt       string            `toml:"prompt" json:"prompt" yaml:"prompt"`
+	Prompts      map[string]string `toml:"prompts,omitempty" json:"prompts,omitempty" yaml:"prompts,omitempty"`                   // per-locale prompt files keyed by language tag (e.g. fr, pt-br)
+	AllowedTools *AllowedTools     `toml:"allowed_tools,omitempty" json:"allowed_tools,omitempty" yaml:"allowed_tools,omitempty"` // nil inherits [defaults] allowed_tools
+	InputSchema  Schema            `toml:"input_schema" json:"input_schema" yaml:"input_schema"`
+	OutputSchema *Schema           `toml:"output_schema,omitempty" json:"output_schema,omitempty" yaml:"output_schema,omitempty"` // Only for API endpoints
+	Auth         *Auth             `toml:"auth,omitempty" json:"auth,omitempty" yaml:"auth,omitempty"`
 
 	// Type-specific configurations
-	Webhook   *WebhookConfig   `toml:"webhook,omitempty"`
-	API       *APIConfig       `toml:"api,omitempty"`
-	Streaming *StreamingConfig `toml:"streaming,omitempty"`
+	Webhook   *WebhookConfig   `toml:"webhook,omitempty" json:"webhook,omitempty" yaml:"webhook,omitempty"`
+	API       *APIConfig       `toml:"api,omitempty" json:"api,omitempty" yaml:"api,omitempty"`
+	Streaming *StreamingConfig `toml:"streaming,omitempty" json:"streaming,omitempty" yaml:"streaming,omitempty"`
+
+	// MCPServers are connected to the agent in addition to DataGen MCP
+	MCPServers []MCPServer `toml:"mcp_servers,omitempty" json:"mcp_servers,omitempty" yaml:"mcp_servers,omitempty"`
+
+	// Model overrides the prompt's frontmatter model and MODEL_NAME
+	Model string `toml:"model,omitempty" json:"model,omitempty" yaml:"model,omitempty"`
+	// FallbackModel is used when Model is overloaded or unavailable
+	FallbackModel string          `toml:"fallback_model,omitempty" json:"fallback_model,omitempty" yaml:"fallback_model,omitempty"`
+	Provider      *ProviderConfig `toml:"provider,omitempty" json:"provider,omitempty" yaml:"provider,omitempty"`
+
+	// Steps are the agents a pipeline service runs, in order; pipelines have
+	// no prompt of their own
+	Steps []PipelineStep `toml:"steps,omitempty" json:"steps,omitempty" yaml:"steps,omitempty"`
 
 	// Paths (mutually exclusive based on type)
-	WebhookPath string `toml:"webhook_path,omitempty"`
-	APIPath     string `toml:"api_path,omitempty"`
+	WebhookPath string `toml:"webhook_path,omitempty" json:"webhook_path,omitempty" yaml:"webhook_path,omitempty"`
+	APIPath     string `toml:"api_path,omitempty" json:"api_path,omitempty" yaml:"api_path,omitempty"`
+
+	// File is the included file the service was read from, which SaveConfig
+	// writes it back to; empty for services defined in datagen.toml itself
+	File string `toml:"-" json:"-" yaml:"-"`
+}
+
+// Model providers a service can call Claude through
+const (
+	ProviderAnthropic = "anthropic"
+	ProviderBedrock   = "bedrock"
+	ProviderVertex    = "vertex"
+)
+
+// ProviderConfig routes a service's Claude calls through a cloud provider
+type ProviderConfig struct {
+	Name      string `toml:"name" json:"name" yaml:"name"`                                                 // anthropic (default), bedrock or vertex
+	Region    string `toml:"region,omitempty" json:"region,omitempty" yaml:"region,omitempty"`             // AWS region (bedrock) or Vertex AI region (vertex); read from the environment when unset
+	ProjectID string `toml:"project_id,omitempty" json:"project_id,omitempty" yaml:"project_id,omitempty"` // Google Cloud project (vertex); read from ANTHROPIC_VERTEX_PROJECT_ID when unset
+}
+
+// PipelineStep is one agent of a pipeline service
+type PipelineStep struct {
+	Name   string `toml:"name" json:"name" yaml:"name"`
+	Prompt string `toml:"prompt" json:"prompt" yaml:"prompt"`
+	// Parallel runs the step alongside the step before it
+	Parallel bool `toml:"parallel,omitempty" json:"parallel,omitempty" yaml:"parallel,omitempty"`
+	// Input builds the step's payload: each field maps to input.<field> of the
+	// request or <step>.<field> of an earlier step's result. Without it the
+	// step gets the previous step's result (the request for the first step).
+	Input map[string]string `toml:"input,omitempty" json:"input,omitempty" yaml:"input,omitempty"`
+}
+
+// Tools returns the DataGen tools the service's agent can use; none when it
+// sets no allowed_tools
+func (s *Service) Tools() AllowedTools {
+	if s.AllowedTools == nil {
+		return AllowedTools{}
+	}
+	return *s.AllowedTools
 }
 
 // AllowedTools defines which DataGen tools the agent can use
 type AllowedTools struct {
-	SearchTools    bool `toml:"searchTools"`
-	ExecuteTools   bool `toml:"executeTools"`
-	ExecuteCode    bool `toml:"executeCode"`
-	GetToolDetails bool `toml:"getToolDetails"`
+	SearchTools    bool `toml:"searchTools" json:"searchTools" yaml:"searchTools"`
+	ExecuteTools   bool `toml:"executeTools" json:"executeTools" yaml:"executeTools"`
+	ExecuteCode    bool `toml:"executeCode" json:"executeCode" yaml:"executeCode"`
+	GetToolDetails bool `toml:"getToolDetails" json:"getToolDetails" yaml:"getToolDetails"`
 }
 
 // Schema defines input or output data structure
 type Schema struct {
-	Name   string  `toml:"name,omitempty"`
-	Fields []Field `toml:"fields"`
+	Name   string  `toml:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty"`
+	Fields []Field `toml:"fields" json:"fields" yaml:"fields"`
 }
 
 // Field represents a single field in a schema
 type Field struct {
-	Name     string `toml:"name"`
-	Type     string `toml:"type"` // str, int, float, bool, list, dict
-	Required bool   `toml:"required"`
-	Default  string `toml:"default,omitempty"`
+	Name     string `toml:"name" json:"name" yaml:"name"`
+	Type     string `toml:"type" json:"type" yaml:"type"` // str, int, float, bool, list, dict
+	Required bool   `toml:"required" json:"required" yaml:"required"`
+	Default  string `toml:"default,omitempty" json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+// DefaultValue parses the field's default as its type: a string, int64,
+// float64 or bool. It returns nil when the field has no default.
+func (f Field) DefaultValue() (any, error) {
+	if f.Default == "" {
+		return nil, nil
+	}
+	switch f.Type {
+	case "str":
+		return f.Default, nil
+	case "int":
+		v, err := strconv.ParseInt(f.Default, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("default %q is not an int", f.Default)
+		}
+		return v, nil
+	case "float":
+		v, err := strconv.ParseFloat(f.Default, 64)
+		if err != nil || math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil, fmt.Errorf("default %q is not a finite float", f.Default)
+		}
+		return v, nil
+	case "bool":
+		v, err := strconv.ParseBool(f.Default)
+		if err != nil {
+			return nil, fmt.Errorf("default %q is not a bool (use true or false)", f.Default)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("defaults are only supported for str, int, float and bool fields, not %s", f.Type)
 }
 
 // Auth defines authentication configuration
 type Auth struct {
-	Type   string `toml:"type"` // api_key, bearer_token, oauth, none
-	Header string `toml:"header,omitempty"`
-	EnvVar string `toml:"env_var,omitempty"`
+	Type   string `toml:"type" json:"type" yaml:"type"` // api_key, bearer_token, oauth, none
+	Header string `toml:"header,omitempty" json:"header,omitempty" yaml:"header,omitempty"`
+	EnvVar string `toml:"env_var,omitempty" json:"env_var,omitempty" yaml:"env_var,omitempty"`
 }
 
 // WebhookConfig contains webhook-specific configuration
 type WebhookConfig struct {
-	SignatureVerification string `toml:"signature_verification,omitempty"` // hmac_sha256, custom, none
-	SignatureHeader       string `toml:"signature_header,omitempty"`
-	SecretEnv             string `toml:"secret_env,omitempty"`
-	RetryEnabled          bool   `toml:"retry_enabled"`
-	MaxRetries            int    `toml:"max_retries,omitempty"`
-	BackoffStrategy       string `toml:"backoff_strategy,omitempty"` // exponential, linear
+	SignatureVerification string `toml:"signature_verification,omitempty" json:"signature_verification,omitempty" yaml:"signature_verification,omitempty"` // hmac_sha256, custom, none
+	SignatureHeader       string `toml:"signature_header,omitempty" json:"signature_header,omitempty" yaml:"signature_header,omitempty"`
+	SecretEnv             string `toml:"secret_env,omitempty" json:"secret_env,omitempty" yaml:"secret_env,omitempty"`
+	RetryEnabled          bool   `toml:"retry_enabled" json:"retry_enabled" yaml:"retry_enabled"`
+	MaxRetries            int    `toml:"max_retries,omitempty" json:"max_retries,omitempty" yaml:"max_retries,omitempty"`
+	BackoffStrategy       string `toml:"backoff_strategy,omitempty" json:"backoff_strategy,omitempty" yaml:"backoff_strategy,omitempty"` // exponential, linear
+	Provider              string `toml:"provider,omitempty" json:"provider,omitempty" yaml:"provider,omitempty"`                         // github, stripe, slack (for datagen hooks register)
 }
 
 // APIConfig contains API-specific configuration
 type APIConfig struct {
-	ResponseFormat   string `toml:"response_format"` // json, text, custom
-	Timeout          int    `toml:"timeout"`         // seconds
-	RateLimitEnabled bool   `toml:"rate_limit_enabled"`
-	RateLimitRPM     int    `toml:"rate_limit_rpm,omitempty"` // requests per minute
+	ResponseFormat   string `toml:"response_format" json:"response_format" yaml:"response_format"`       // json, text, custom
+	Timeout          int    `toml:"timeout,omitempty" json:"timeout,omitempty" yaml:"timeout,omitempty"` // seconds; 0 inherits [defaults] timeout
+	RateLimitEnabled bool   `toml:"rate_limit_enabled" json:"rate_limit_enabled" yaml:"rate_limit_enabled"`
+	RateLimitRPM     int    `toml:"rate_limit_rpm,omitempty" json:"rate_limit_rpm,omitempty" yaml:"rate_limit_rpm,omitempty"` // requests per minute
 }
 
 // StreamingConfig contains streaming-specific configuration
 type StreamingConfig struct {
-	Format     string `toml:"format"`      // default, json, custom
-	BufferSize int    `toml:"buffer_size"` // bytes
+	Format     string `toml:"format" json:"format" yaml:"format"`                // default, json, custom
+	BufferSize int    `toml:"buffer_size" json:"buffer_size" yaml:"buffer_size"` // bytes
+	// Framing is how events are written to the response: sse (default) or
+	// ndjson, one JSON event per line
+	Framing string `toml:"framing,omitempty" json:"framing,omitempty" yaml:"framing,omitempty"`
+	// WebSocket also serves the events at <api_path>/ws for clients that
+	// cannot read a streamed HTTP response
+	WebSocket bool `toml:"websocket,omitempty" json:"websocket,omitempty" yaml:"websocket,omitempty"`
+}
+
+// Streaming framings
+const (
+	FramingSSE    = "sse"
+	FramingNDJSON = "ndjson"
+)
+
+// StreamFraming returns the service's streaming framing, sse by default
+func (s *Service) StreamFraming() string {
+	if s.Streaming == nil || s.Streaming.Framing == "" {
+		return FramingSSE
+	}
+	return s.Streaming.Framing
+}
+
+// StreamFormat returns the service's SSE event format: json for structured
+// events, default for bare text chunks. NDJSON is always structured.
+func (s *Service) StreamFormat() string {
+	if s.StreamFraming() == FramingNDJSON || (s.Streaming != nil && s.Streaming.Format == "json") {
+		return "json"
+	}
+	return "default"
+}
+
+// StreamWebSocket reports whether the service also serves its events over a
+// WebSocket
+func (s *Service) StreamWebSocket() bool {
+	return s.Streaming != nil && s.Streaming.WebSocket
+}
+
+// MCPServer is an extra MCP server a service's agent connects to, either
+// remote (url) or a local process (command). Header and env values may
+// reference environment variables as ${VAR}; they are expanded at runtime.
+type MCPServer struct {
+	Name      string            `toml:"name" json:"name" yaml:"name"`
+	URL       string            `toml:"url,omitempty" json:"url,omitempty" yaml:"url,omitempty"`
+	Transport string            `toml:"transport,omitempty" json:"transport,omitempty" yaml:"transport,omitempty"` // http (default) or sse, for url servers
+	Headers   map[string]string `toml:"headers,omitempty" json:"headers,omitempty" yaml:"headers,omitempty"`
+	Command   string            `toml:"command,omitempty" json:"command,omitempty" yaml:"command,omitempty"`
+	Args      []string          `toml:"args,omitempty" json:"args,omitempty" yaml:"args,omitempty"`
+	Env       map[string]string `toml:"env,omitempty" json:"env,omitempty" yaml:"env,omitempty"`
+}
+
+// envRefPattern matches ${VAR} references in MCP server settings
+var envRefPattern = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// EnvVars returns the environment variables the server's headers and env
+// reference, sorted
+func (m MCPServer) EnvVars() []string {
+	seen := map[string]bool{}
+	var vars []string
+	for _, values := range []map[string]string{m.Headers, m.Env} {
+		for _, v := range values {
+			for _, ref := range envRefPattern.FindAllStringSubmatch(v, -1) {
+				if !seen[ref[1]] {
+					seen[ref[1]] = true
+					vars = append(vars, ref[1])
+				}
+			}
+		}
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+// MCPEnvVars returns the environment variables referenced by all of the
+// service's MCP servers, sorted and de-duplicated
+func (s *Service) MCPEnvVars() []string {
+	seen := map[string]bool{}
+	var vars []string
+	for _, m := range s.MCPServers {
+		for _, v := range m.EnvVars() {
+			if !seen[v] {
+				seen[v] = true
+				vars = append(vars, v)
+			}
+		}
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+// ProviderName returns the provider the service calls Claude through
+func (s *Service) ProviderName() string {
+	if s.Provider == nil || s.Provider.Name == "" {
+		return ProviderAnthropic
+	}
+	return s.Provider.Name
+}
+
+// ProviderEnvVars returns the credential and location variables the service's
+// provider reads from the environment, sorted
+func (s *Service) ProviderEnvVars() []string {
+	var vars []string
+	switch s.ProviderName() {
+	case ProviderBedrock:
+		vars = []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"}
+		if s.Provider.Region == "" {
+			vars = append(vars, "AWS_REGION")
+		}
+	case ProviderVertex:
+		vars = []string{"GOOGLE_APPLICATION_CREDENTIALS_JSON"}
+		if s.Provider.ProjectID == "" {
+			vars = append(vars, "ANTHROPIC_VERTEX_PROJECT_ID")
+		}
+		if s.Provider.Region == "" {
+			vars = append(vars, "CLOUD_ML_REGION")
+		}
+	}
+	sort.Strings(vars)
+	return vars
 }
 
 // GetPath returns the appropriate path based on endpoint type
@@ -100,13 +635,48 @@ func (s *Service) GetPath() string {
 	switch s.Type {
 	case "webhook":
 		return s.WebhookPath
-	case "api", "streaming":
+	case "api", "streaming", "pipeline":
 		return s.APIPath
 	default:
 		return ""
 	}
 }
 
+// PromptFiles returns every prompt file the service loads: its prompt, its
+// per-locale prompts (sorted by locale) and its pipeline steps' prompts
+func (s *Service) PromptFiles() []string {
+	var files []string
+	if s.Prompt != "" {
+		files = append(files, s.Prompt)
+	}
+	locales := make([]string, 0, len(s.Prompts))
+	for locale := range s.Prompts {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	for _, locale := range locales {
+		files = append(files, s.Prompts[locale])
+	}
+	for _, step := range s.Steps {
+		files = append(files, step.Prompt)
+	}
+	return files
+}
+
+// PipelineStages groups a pipeline's steps into stages that run one after
+// another; the steps of a stage run concurrently
+func (s *Service) PipelineStages() [][]PipelineStep {
+	var stages [][]PipelineStep
+	for i, step := range s.Steps {
+		if step.Parallel && i > 0 {
+			stages[len(stages)-1] = append(stages[len(stages)-1], step)
+			continue
+		}
+		stages = append(stages, []PipelineStep{step})
+	}
+	return stages
+}
+
 // GetFunctionName returns a snake_case function name from the service name
 func (s *Service) GetFunctionName() string {
 	// Simple snake_case conversion - you can enhance this
@@ -4,7 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/agents"
 )
 
 // ValidateConfig checks if the configuration is valid
@@ -17,6 +21,108 @@ func ValidateConfig(cfg *DatagenConfig, configDir string) error {
 		return fmt.Errorf("claude_api_key_env is required")
 	}
 
+	if cfg.Scaling != nil {
+		if cfg.Scaling.TargetConcurrency < 0 {
+			return fmt.Errorf("scaling.target_concurrency must not be negative")
+		}
+		if cfg.Scaling.MinReplicas < 0 || cfg.Scaling.MaxReplicas < 0 {
+			return fmt.Errorf("scaling replicas must not be negative")
+		}
+		if cfg.Scaling.MaxReplicas > 0 && cfg.Scaling.MinReplicas > cfg.Scaling.MaxReplicas {
+			return fmt.Errorf("scaling.min_replicas (%d) exceeds scaling.max_replicas (%d)", cfg.Scaling.MinReplicas, cfg.Scaling.MaxReplicas)
+		}
+	}
+
+	if cfg.Server != nil {
+		if cfg.Server.Workers < 0 {
+			return fmt.Errorf("server.workers must not be negative")
+		}
+		switch cfg.Server.StateStore {
+		case "", StateStoreMemory, StateStoreRedis:
+		default:
+			return fmt.Errorf("server.state_store must be %q or %q", StateStoreMemory, StateStoreRedis)
+		}
+	}
+
+	switch cfg.PythonPackaging {
+	case "", PackagingPip, PackagingUV, PackagingPoetry:
+	default:
+		return fmt.Errorf("python_packaging must be %q, %q or %q", PackagingPip, PackagingUV, PackagingPoetry)
+	}
+	switch cfg.PythonFormatter {
+	case "", FormatterRuff, FormatterBlack:
+	default:
+		return fmt.Errorf("python_formatter must be %q or %q", FormatterRuff, FormatterBlack)
+	}
+	if cfg.TemplatesDir != "" {
+		dir := cfg.TemplatesDir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(configDir, dir)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return fmt.Errorf("templates_dir %s is not a directory", cfg.TemplatesDir)
+		}
+	}
+	pinned := make([]string, 0, len(cfg.Dependencies))
+	for name := range cfg.Dependencies {
+		pinned = append(pinned, name)
+	}
+	sort.Strings(pinned)
+	for _, name := range pinned {
+		if !exactVersionPattern.MatchString(cfg.Dependencies[name]) {
+			return fmt.Errorf("dependencies.%s: %q is not an exact version like 0.115.6", name, cfg.Dependencies[name])
+		}
+	}
+
+	if cfg.Claude != nil {
+		if cfg.Claude.MaxRetries < 0 || cfg.Claude.Timeout < 0 || cfg.Claude.MaxConcurrency < 0 {
+			return fmt.Errorf("claude.max_retries, claude.timeout and claude.max_concurrency must not be negative")
+		}
+	}
+
+	if cfg.Defaults != nil {
+		if cfg.Defaults.Timeout < 0 {
+			return fmt.Errorf("defaults.timeout must not be negative")
+		}
+		if cfg.Defaults.Auth != nil {
+			if err := validateAuth(cfg.Defaults.Auth); err != nil {
+				return fmt.Errorf("defaults.auth: %w", err)
+			}
+		}
+	}
+
+	if cfg.Docker != nil {
+		if v := cfg.Docker.PythonVersion; v != "" && !pythonVersionPattern.MatchString(v) {
+			return fmt.Errorf("docker.python_version %q must be a Python 3 minor version such as 3.12 (3.10 or later)", v)
+		}
+		if strings.ContainsAny(cfg.Docker.BaseImage, " \t\n") {
+			return fmt.Errorf("docker.base_image %q must be an image reference such as python:3.13-slim", cfg.Docker.BaseImage)
+		}
+	}
+
+	if cfg.Capture != nil {
+		if err := validateCaptureConfig(cfg.Capture); err != nil {
+			return fmt.Errorf("capture: %w", err)
+		}
+	}
+
+	if cfg.Railway != nil {
+		if err := validateRailwayConfig(cfg.Railway); err != nil {
+			return fmt.Errorf("railway: %w", err)
+		}
+	}
+	for _, name := range cfg.EnvironmentNames() {
+		if cfg.Environments[name].RailwayProject != "" {
+			// The Railway CLI only acts on the project a directory is linked to
+			return fmt.Errorf("environments.%s.railway_project is not supported; link a separate directory to that project with 'railway link' and pass it with --output, or use railway_environment", name)
+		}
+		if r := cfg.Environments[name].Railway; r != nil {
+			if err := validateRailwayConfig(r); err != nil {
+				return fmt.Errorf("environments.%s.railway: %w", name, err)
+			}
+		}
+	}
+
 	// Check that at least one service is defined
 	if len(cfg.Services) == 0 {
 		return fmt.Errorf("at least one service must be defined")
@@ -32,6 +138,38 @@ func ValidateConfig(cfg *DatagenConfig, configDir string) error {
 	return nil
 }
 
+var (
+	s3BucketPattern    = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+	redactFieldPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+)
+
+// pythonVersionPattern matches Python 3.10 and later, which the agent SDK needs
+var pythonVersionPattern = regexp.MustCompile(`^3\.(1[0-9]|[2-9][0-9])$`)
+
+// exactVersionPattern matches a final release version such as 0.115.6
+var exactVersionPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*$`)
+
+func validateCaptureConfig(c *CaptureConfig) error {
+	if filepath.IsAbs(c.Dir) || strings.HasPrefix(filepath.Clean(c.Dir), "..") {
+		return fmt.Errorf("dir must be a path inside the project")
+	}
+	if c.S3Bucket != "" && !s3BucketPattern.MatchString(c.S3Bucket) {
+		return fmt.Errorf("s3_bucket %q is not a valid S3 bucket name", c.S3Bucket)
+	}
+	if c.S3Prefix != "" && c.S3Bucket == "" {
+		return fmt.Errorf("s3_prefix needs s3_bucket")
+	}
+	if strings.ContainsAny(c.Dir+c.S3Prefix, "\"\\") {
+		return fmt.Errorf("dir and s3_prefix must not contain quotes or backslashes")
+	}
+	for _, field := range c.Redact {
+		if !redactFieldPattern.MatchString(field) {
+			return fmt.Errorf("redact entry %q must be a field name (letters, digits, _ . -)", field)
+		}
+	}
+	return nil
+}
+
 func validateService(svc *Service, index int, configDir string) error {
 	// Check required fields
 	if svc.Name == "" {
@@ -43,23 +181,44 @@ func validateService(svc *Service, index int, configDir string) error {
 	if svc.Description == "" {
 		return fmt.Errorf("description is required")
 	}
-	if svc.Prompt == "" {
+	if svc.Prompt == "" && svc.Type != "pipeline" {
 		return fmt.Errorf("prompt is required")
 	}
 
 	// Validate type
-	validTypes := map[string]bool{"webhook": true, "api": true, "streaming": true}
+	validTypes := map[string]bool{"webhook": true, "api": true, "streaming": true, "pipeline": true}
 	if !validTypes[svc.Type] {
-		return fmt.Errorf("invalid type '%s', must be one of: webhook, api, streaming", svc.Type)
+		return fmt.Errorf("invalid type '%s', must be one of: webhook, api, streaming, pipeline", svc.Type)
 	}
 
-	// Check that prompt file exists (resolve relative to config directory)
-	promptPath := svc.Prompt
-	if !filepath.IsAbs(promptPath) {
-		promptPath = filepath.Join(configDir, promptPath)
+	if err := validateMCPServers(svc.MCPServers); err != nil {
+		return err
 	}
-	if _, err := os.Stat(promptPath); os.IsNotExist(err) {
-		return fmt.Errorf("prompt file not found: %s", svc.Prompt)
+	if svc.Type == "pipeline" {
+		if svc.Prompt != "" || len(svc.Prompts) > 0 {
+			return fmt.Errorf("pipeline services run their steps' prompts; move prompt into a [[service.steps]] entry")
+		}
+		if err := validatePipelineSteps(svc.Steps, configDir, svc.MCPServers); err != nil {
+			return err
+		}
+	} else {
+		if len(svc.Steps) > 0 {
+			return fmt.Errorf("steps are only supported for pipeline type")
+		}
+		if err := validatePromptFile(svc.Prompt, configDir, svc.MCPServers); err != nil {
+			return err
+		}
+	}
+	for locale, prompt := range svc.Prompts {
+		if !localeTagPattern.MatchString(locale) {
+			return fmt.Errorf("prompts.%s: locale must be a language tag such as fr or pt-br", locale)
+		}
+		if err := validatePromptFile(prompt, configDir, svc.MCPServers); err != nil {
+			return fmt.Errorf("prompts.%s: %w", locale, err)
+		}
+	}
+	if err := validateModelSettings(svc); err != nil {
+		return err
 	}
 
 	// Validate paths based on type
@@ -88,6 +247,18 @@ func validateService(svc *Service, index int, configDir string) error {
 				return fmt.Errorf("api config: %w", err)
 			}
 		}
+	case "pipeline":
+		if svc.APIPath == "" {
+			return fmt.Errorf("api_path is required for pipeline type")
+		}
+		if !strings.HasPrefix(svc.APIPath, "/") {
+			return fmt.Errorf("api_path must start with /")
+		}
+		if svc.API != nil {
+			if err := validateAPIConfig(svc.API); err != nil {
+				return fmt.Errorf("api config: %w", err)
+			}
+		}
 	case "streaming":
 		if svc.APIPath == "" {
 			return fmt.Errorf("api_path is required for streaming type")
@@ -139,6 +310,9 @@ func validateField(field *Field) error {
 	if !validTypes[field.Type] {
 		return fmt.Errorf("invalid type '%s', must be one of: str, int, float, bool, list, dict, any", field.Type)
 	}
+	if _, err := field.DefaultValue(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -153,6 +327,180 @@ func validateAuth(auth *Auth) error {
 	return nil
 }
 
+// localeTagPattern matches lowercase language tags such as fr, pt-br or zh-hant
+// validateModelSettings checks a service's model, fallback model and provider
+func validateModelSettings(svc *Service) error {
+	for field, model := range map[string]string{"model": svc.Model, "fallback_model": svc.FallbackModel} {
+		if strings.ContainsAny(model, " \t\n\"") {
+			return fmt.Errorf("%s %q must be a model ID without spaces or quotes", field, model)
+		}
+	}
+	if svc.FallbackModel != "" && svc.FallbackModel == svc.Model {
+		return fmt.Errorf("fallback_model must differ from model")
+	}
+	if svc.Provider == nil {
+		return nil
+	}
+	switch svc.ProviderName() {
+	case ProviderAnthropic:
+		if svc.Provider.Region != "" || svc.Provider.ProjectID != "" {
+			return fmt.Errorf("provider: region and project_id only apply to bedrock and vertex")
+		}
+	case ProviderBedrock:
+		if svc.Provider.ProjectID != "" {
+			return fmt.Errorf("provider: project_id only applies to vertex")
+		}
+	case ProviderVertex:
+	default:
+		return fmt.Errorf("provider: invalid name '%s', must be one of: anthropic, bedrock, vertex", svc.Provider.Name)
+	}
+	return nil
+}
+
+// stepNamePattern keeps pipeline step names usable as executor keys and as
+// the first part of input mappings
+var stepNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// validatePipelineSteps checks each step's name and prompt, and that input
+// mappings only refer to the request or to steps of an earlier stage
+func validatePipelineSteps(steps []PipelineStep, configDir string, servers []MCPServer) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("pipeline type needs at least one [[service.steps]] entry")
+	}
+	done := map[string]bool{}
+	stage := map[string]bool{}
+	for i, step := range steps {
+		if !stepNamePattern.MatchString(step.Name) {
+			return fmt.Errorf("steps[%d]: name %q must be lowercase letters, digits and underscores, starting with a letter", i, step.Name)
+		}
+		if step.Name == "input" {
+			return fmt.Errorf("steps[%d]: name \"input\" is reserved for the request", i)
+		}
+		if done[step.Name] || stage[step.Name] {
+			return fmt.Errorf("steps[%d]: duplicate step name %q", i, step.Name)
+		}
+		if step.Prompt == "" {
+			return fmt.Errorf("steps.%s: prompt is required", step.Name)
+		}
+		if err := validatePromptFile(step.Prompt, configDir, servers); err != nil {
+			return fmt.Errorf("steps.%s: %w", step.Name, err)
+		}
+
+		if !step.Parallel {
+			for name := range stage {
+				done[name] = true
+			}
+			stage = map[string]bool{}
+		}
+		for field, source := range step.Input {
+			from, _, _ := strings.Cut(source, ".")
+			switch {
+			case from == "input" || done[from]:
+			case stage[from]:
+				return fmt.Errorf("steps.%s.input.%s: %s runs in parallel with this step, so its result is not available yet", step.Name, field, from)
+			default:
+				return fmt.Errorf("steps.%s.input.%s: %q must start with input or the name of an earlier step", step.Name, field, source)
+			}
+		}
+		stage[step.Name] = true
+	}
+	return nil
+}
+
+var localeTagPattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// validatePromptFile checks that prompt exists (relative to the config directory)
+// and only requests tools the generated app can provide
+func validatePromptFile(prompt, configDir string, servers []MCPServer) error {
+	promptPath := prompt
+	if !filepath.IsAbs(promptPath) {
+		promptPath = filepath.Join(configDir, promptPath)
+	}
+	if _, err := os.Stat(promptPath); os.IsNotExist(err) {
+		return fmt.Errorf("prompt file not found: %s", prompt)
+	}
+	return validatePromptTools(prompt, promptPath, servers)
+}
+
+// validatePromptTools rejects agents whose frontmatter requests tools from MCP
+// servers the generated app never connects to (neither DataGen MCP nor one of
+// the service's mcp_servers), so such tools would silently be missing at runtime
+func validatePromptTools(prompt, promptPath string, servers []MCPServer) error {
+	agent, err := agents.ParseFile(promptPath)
+	if err != nil {
+		return fmt.Errorf("prompt file %s: %w", prompt, err)
+	}
+	declared := map[string]bool{}
+	for _, s := range servers {
+		declared[s.Name] = true
+	}
+	var missing []string
+	for _, name := range agents.ExternalMCPServers(agent.Tools) {
+		if !declared[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("prompt %s requests tools from MCP server(s) %s, but the service does not declare them; add them under [[service.mcp_servers]] or remove those tools from the frontmatter",
+		prompt, strings.Join(missing, ", "))
+}
+
+// mcpServerNamePattern matches names usable in mcp__<server>__<tool> tool names
+var mcpServerNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func validateMCPServers(servers []MCPServer) error {
+	seen := map[string]bool{}
+	for i, s := range servers {
+		if !mcpServerNamePattern.MatchString(s.Name) {
+			return fmt.Errorf("mcp_servers[%d]: name %q must contain only letters, digits, _ and -", i, s.Name)
+		}
+		if s.Name == "datagen" {
+			return fmt.Errorf("mcp_servers[%d]: datagen is configured automatically", i)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("mcp_servers[%d]: duplicate name %q", i, s.Name)
+		}
+		seen[s.Name] = true
+
+		switch {
+		case s.URL != "" && s.Command != "":
+			return fmt.Errorf("mcp_servers.%s: set either url or command, not both", s.Name)
+		case s.URL != "":
+			if !strings.HasPrefix(s.URL, "https://") && !strings.HasPrefix(s.URL, "http://") {
+				return fmt.Errorf("mcp_servers.%s: url must start with http:// or https://", s.Name)
+			}
+			if s.Transport != "" && s.Transport != "http" && s.Transport != "sse" {
+				return fmt.Errorf("mcp_servers.%s: invalid transport '%s', must be one of: http, sse", s.Name, s.Transport)
+			}
+			if len(s.Args) > 0 || len(s.Env) > 0 {
+				return fmt.Errorf("mcp_servers.%s: args and env only apply to command servers", s.Name)
+			}
+		case s.Command != "":
+			if s.Transport != "" || len(s.Headers) > 0 {
+				return fmt.Errorf("mcp_servers.%s: transport and headers only apply to url servers", s.Name)
+			}
+		default:
+			return fmt.Errorf("mcp_servers.%s: url or command is required", s.Name)
+		}
+	}
+	return nil
+}
+
+func validateRailwayConfig(r *RailwayConfig) error {
+	if r.HealthcheckPath != "" && !strings.HasPrefix(r.HealthcheckPath, "/") {
+		return fmt.Errorf("healthcheck_path must start with /")
+	}
+	if r.HealthcheckTimeout < 0 || r.Replicas < 0 {
+		return fmt.Errorf("healthcheck_timeout and replicas must not be negative")
+	}
+	if r.CronSchedule != "" && len(strings.Fields(r.CronSchedule)) != 5 {
+		return fmt.Errorf("cron_schedule %q must have 5 fields (minute hour day month weekday)", r.CronSchedule)
+	}
+	return nil
+}
+
 func validateWebhookConfig(wh *WebhookConfig) error {
 	if wh.SignatureVerification != "" {
 		validTypes := map[string]bool{"hmac_sha256": true, "custom": true, "none": true}
@@ -168,6 +516,12 @@ func validateWebhookConfig(wh *WebhookConfig) error {
 			}
 		}
 	}
+	if wh.Provider != "" {
+		validProviders := map[string]bool{"github": true, "stripe": true, "slack": true}
+		if !validProviders[wh.Provider] {
+			return fmt.Errorf("invalid provider '%s', must be one of: github, stripe, slack", wh.Provider)
+		}
+	}
 	if wh.RetryEnabled && wh.MaxRetries <= 0 {
 		return fmt.Errorf("max_retries must be > 0 when retry_enabled is true")
 	}
@@ -196,5 +550,8 @@ func validateStreamingConfig(stream *StreamingConfig) error {
 	if stream.BufferSize <= 0 {
 		return fmt.Errorf("buffer_size must be > 0")
 	}
+	if stream.Framing != "" && stream.Framing != FramingSSE && stream.Framing != FramingNDJSON {
+		return fmt.Errorf("invalid framing '%s', must be sse or ndjson", stream.Framing)
+	}
 	return nil
 }
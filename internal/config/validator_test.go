@@ -0,0 +1,430 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateConfigRejectsUnconfiguredMCPTools(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(name, body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	write("ok.md", "---\nname: ok\ntools: Read, mcp__Datagen__executeTool\n---\nhi\n")
+	write("gh.md", "---\nname: gh\ntools:\n  - mcp__github__create_issue\n---\nhi\n")
+
+	cfg := &DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []Service{
+			{Name: "ok", Type: "api", Description: "d", Prompt: "ok.md", APIPath: "/ok"},
+		},
+	}
+	if err := ValidateConfig(cfg, dir); err != nil {
+		t.Fatalf("ValidateConfig() error = %v", err)
+	}
+
+	cfg.Services[0].Prompt = "gh.md"
+	err := ValidateConfig(cfg, dir)
+	if err == nil || !strings.Contains(err.Error(), "github") {
+		t.Fatalf("ValidateConfig() error = %v, want MCP server github error", err)
+	}
+
+	cfg.Services[0].MCPServers = []MCPServer{{Name: "github", URL: "https://api.githubcopilot.com/mcp/"}}
+	if err := ValidateConfig(cfg, dir); err != nil {
+		t.Fatalf("ValidateConfig() with declared server error = %v", err)
+	}
+}
+
+func TestValidateMCPServers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		servers []MCPServer
+		wantErr string
+	}{
+		{"remote and stdio", []MCPServer{
+			{Name: "github", URL: "https://api.githubcopilot.com/mcp/", Headers: map[string]string{"Authorization": "Bearer ${GITHUB_TOKEN}"}},
+			{Name: "fs", Command: "npx", Args: []string{"-y", "@modelcontextprotocol/server-filesystem"}},
+		}, ""},
+		{"reserved name", []MCPServer{{Name: "datagen", URL: "https://mcp.datagen.dev/mcp"}}, "configured automatically"},
+		{"duplicate", []MCPServer{{Name: "a", Command: "x"}, {Name: "a", Command: "y"}}, "duplicate"},
+		{"bad name", []MCPServer{{Name: "my server", Command: "x"}}, "letters, digits"},
+		{"url and command", []MCPServer{{Name: "a", URL: "https://x", Command: "x"}}, "not both"},
+		{"neither", []MCPServer{{Name: "a"}}, "url or command is required"},
+		{"bad transport", []MCPServer{{Name: "a", URL: "https://x", Transport: "ws"}}, "invalid transport"},
+		{"headers on command", []MCPServer{{Name: "a", Command: "x", Headers: map[string]string{"k": "v"}}}, "only apply to url servers"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMCPServers(tt.servers)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateMCPServers() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validateMCPServers() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceMCPEnvVars(t *testing.T) {
+	t.Parallel()
+
+	svc := Service{MCPServers: []MCPServer{
+		{Name: "github", URL: "https://x", Headers: map[string]string{"Authorization": "Bearer ${GITHUB_TOKEN}"}},
+		{Name: "pg", Command: "pg-mcp", Env: map[string]string{"DSN": "postgres://${PG_USER}:${PG_PASS}@db", "GH": "${GITHUB_TOKEN}"}},
+	}}
+	got := strings.Join(svc.MCPEnvVars(), ",")
+	if got != "GITHUB_TOKEN,PG_PASS,PG_USER" {
+		t.Fatalf("MCPEnvVars() = %s", got)
+	}
+}
+
+func TestValidateConfigLocalePrompts(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"chat.md", "chat.fr.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("hi\n"), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		prompts map[string]string
+		wantErr string
+	}{
+		{name: "valid", prompts: map[string]string{"fr": "chat.fr.md"}},
+		{name: "bad tag", prompts: map[string]string{"French": "chat.fr.md"}, wantErr: "language tag"},
+		{name: "missing file", prompts: map[string]string{"de": "chat.de.md"}, wantErr: "prompts.de: prompt file not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &DatagenConfig{
+				DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+				ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+				Services: []Service{
+					{Name: "chat", Type: "api", Description: "d", Prompt: "chat.md", Prompts: tt.prompts, APIPath: "/chat"},
+				},
+			}
+			err := ValidateConfig(cfg, dir)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateConfig() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateConfigPipeline(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"extract.md", "score.md", "tag.md", "write.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("hi\n"), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	valid := []PipelineStep{
+		{Name: "extract", Prompt: "extract.md"},
+		{Name: "score", Prompt: "score.md"},
+		{Name: "tag", Prompt: "tag.md", Parallel: true},
+		{Name: "write", Prompt: "write.md", Input: map[string]string{"score": "score.value", "tags": "tag", "url": "input.url"}},
+	}
+	tests := []struct {
+		name    string
+		prompt  string
+		steps   []PipelineStep
+		wantErr string
+	}{
+		{name: "valid", steps: valid},
+		{name: "no steps", wantErr: "at least one"},
+		{name: "own prompt", prompt: "extract.md", steps: valid, wantErr: "move prompt into"},
+		{name: "bad name", steps: []PipelineStep{{Name: "Extract", Prompt: "extract.md"}}, wantErr: "must be lowercase"},
+		{name: "reserved name", steps: []PipelineStep{{Name: "input", Prompt: "extract.md"}}, wantErr: "reserved"},
+		{name: "duplicate", steps: []PipelineStep{{Name: "a", Prompt: "extract.md"}, {Name: "a", Prompt: "score.md"}}, wantErr: "duplicate step name"},
+		{name: "missing prompt", steps: []PipelineStep{{Name: "a", Prompt: "nope.md"}}, wantErr: "steps.a: prompt file not found"},
+		{name: "later step", steps: []PipelineStep{{Name: "a", Prompt: "extract.md", Input: map[string]string{"x": "b.x"}}, {Name: "b", Prompt: "score.md"}}, wantErr: "earlier step"},
+		{name: "parallel sibling", steps: []PipelineStep{{Name: "a", Prompt: "extract.md"}, {Name: "b", Prompt: "score.md", Parallel: true, Input: map[string]string{"x": "a.x"}}}, wantErr: "runs in parallel"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &DatagenConfig{
+				DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+				ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+				Services: []Service{
+					{Name: "leads", Type: "pipeline", Description: "d", Prompt: tt.prompt, Steps: tt.steps, APIPath: "/leads"},
+				},
+			}
+			err := ValidateConfig(cfg, dir)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateConfig() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateModelSettings(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		svc     Service
+		wantErr string
+	}{
+		{name: "defaults"},
+		{name: "bedrock", svc: Service{Model: "us.anthropic.claude-sonnet-4-5-20250929-v1:0", FallbackModel: "us.anthropic.claude-haiku-4-5-20251001-v1:0", Provider: &ProviderConfig{Name: "bedrock", Region: "us-east-1"}}},
+		{name: "vertex", svc: Service{Provider: &ProviderConfig{Name: "vertex", Region: "us-east5", ProjectID: "acme"}}},
+		{name: "same fallback", svc: Service{Model: "claude-opus-4-1", FallbackModel: "claude-opus-4-1"}, wantErr: "must differ"},
+		{name: "spaces", svc: Service{Model: "claude sonnet"}, wantErr: "without spaces"},
+		{name: "unknown provider", svc: Service{Provider: &ProviderConfig{Name: "azure"}}, wantErr: "invalid name 'azure'"},
+		{name: "bedrock project", svc: Service{Provider: &ProviderConfig{Name: "bedrock", ProjectID: "acme"}}, wantErr: "only applies to vertex"},
+		{name: "anthropic region", svc: Service{Provider: &ProviderConfig{Name: "anthropic", Region: "us"}}, wantErr: "only apply to bedrock and vertex"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateModelSettings(&tt.svc)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateModelSettings() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validateModelSettings() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProviderEnvVars(t *testing.T) {
+	t.Parallel()
+
+	cfg := &DatagenConfig{Services: []Service{
+		{Name: "a", Provider: &ProviderConfig{Name: "bedrock", Region: "us-east-1"}},
+		{Name: "b", Provider: &ProviderConfig{Name: "vertex", ProjectID: "acme"}},
+	}}
+	if got := strings.Join(cfg.ProviderEnvVars(), ","); got != "AWS_ACCESS_KEY_ID,AWS_SECRET_ACCESS_KEY,CLOUD_ML_REGION,GOOGLE_APPLICATION_CREDENTIALS_JSON" {
+		t.Fatalf("ProviderEnvVars() = %s", got)
+	}
+	if cfg.RequiresClaudeAPIKey() {
+		t.Fatalf("RequiresClaudeAPIKey() = true with only Bedrock and Vertex services")
+	}
+	cfg.Services = append(cfg.Services, Service{Name: "c"})
+	if !cfg.RequiresClaudeAPIKey() {
+		t.Fatalf("RequiresClaudeAPIKey() = false with an Anthropic service")
+	}
+}
+
+func TestValidateStreamingConfig(t *testing.T) {
+	t.Parallel()
+
+	if err := validateStreamingConfig(&StreamingConfig{Format: "json", BufferSize: 8192, Framing: "ndjson"}); err != nil {
+		t.Fatalf("validateStreamingConfig(ndjson) error = %v", err)
+	}
+	err := validateStreamingConfig(&StreamingConfig{Format: "json", BufferSize: 8192, Framing: "websocket"})
+	if err == nil || !strings.Contains(err.Error(), "invalid framing") {
+		t.Fatalf("validateStreamingConfig(websocket) error = %v, want invalid framing", err)
+	}
+	svc := Service{Streaming: &StreamingConfig{Format: "default", Framing: "ndjson"}}
+	if svc.StreamFormat() != "json" {
+		t.Fatalf("StreamFormat() = %q, want json for ndjson framing", svc.StreamFormat())
+	}
+}
+
+func TestValidateCaptureConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		capture CaptureConfig
+		wantErr string
+	}{
+		{"defaults", CaptureConfig{}, ""},
+		{"bucket", CaptureConfig{S3Bucket: "acme-recordings", S3Prefix: "prod/", Redact: []string{"email"}}, ""},
+		{"outside project", CaptureConfig{Dir: "../recordings"}, "inside the project"},
+		{"bad bucket", CaptureConfig{S3Bucket: "Acme_Recordings"}, "not a valid S3 bucket name"},
+		{"prefix without bucket", CaptureConfig{S3Prefix: "prod/"}, "needs s3_bucket"},
+		{"bad redact", CaptureConfig{Redact: []string{"user email"}}, "must be a field name"},
+	}
+	for _, tt := range tests {
+		err := validateCaptureConfig(&tt.capture)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%s: validateCaptureConfig() error = %v", tt.name, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: validateCaptureConfig() error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// validProject returns a config that passes validation and the directory
+// holding its prompt
+func validProject(t *testing.T) (*DatagenConfig, string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "chat.md"), []byte("---\nname: chat\n---\nhi\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return &DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services:         []Service{{Name: "chat", Type: "api", Description: "d", Prompt: "chat.md", APIPath: "/chat"}},
+	}, dir
+}
+
+func TestValidatePythonPackaging(t *testing.T) {
+	t.Parallel()
+
+	for packaging, wantErr := range map[string]bool{"": false, "pip": false, "uv": false, "poetry": false, "conda": true} {
+		cfg, dir := validProject(t)
+		cfg.PythonPackaging = packaging
+		err := ValidateConfig(cfg, dir)
+		if wantErr != (err != nil) || (err != nil && !strings.Contains(err.Error(), "python_packaging")) {
+			t.Errorf("ValidateConfig(python_packaging = %q) error = %v", packaging, err)
+		}
+	}
+	for formatter, wantErr := range map[string]bool{"": false, "ruff": false, "black": false, "yapf": true} {
+		cfg, dir := validProject(t)
+		cfg.PythonFormatter = formatter
+		err := ValidateConfig(cfg, dir)
+		if wantErr != (err != nil) || (err != nil && !strings.Contains(err.Error(), "python_formatter")) {
+			t.Errorf("ValidateConfig(python_formatter = %q) error = %v", formatter, err)
+		}
+	}
+	cfg, dir := validProject(t)
+	cfg.Dependencies = map[string]string{"fastapi": "0.115.12"}
+	if err := ValidateConfig(cfg, dir); err != nil {
+		t.Errorf("ValidateConfig(exact pin) error = %v", err)
+	}
+	cfg.Dependencies = map[string]string{"fastapi": "~=0.115"}
+	if err := ValidateConfig(cfg, dir); err == nil || !strings.Contains(err.Error(), "dependencies.fastapi") {
+		t.Errorf("ValidateConfig(range pin) error = %v, want dependencies.fastapi error", err)
+	}
+	if got := (&DatagenConfig{}).Packaging(); got != PackagingPip {
+		t.Errorf("Packaging() = %q, want pip by default", got)
+	}
+}
+
+func TestValidateDockerConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		docker  DockerConfig
+		wantErr string
+	}{
+		{"defaults", DockerConfig{}, ""},
+		{"version", DockerConfig{PythonVersion: "3.12", MultiStage: true, CacheMounts: true}, ""},
+		{"base image", DockerConfig{BaseImage: "ghcr.io/acme/python:3.13-slim"}, ""},
+		{"patch version", DockerConfig{PythonVersion: "3.12.4"}, "python_version"},
+		{"too old", DockerConfig{PythonVersion: "3.9"}, "python_version"},
+		{"spaces in image", DockerConfig{BaseImage: "python 3.13"}, "base_image"},
+	}
+	for _, tt := range tests {
+		cfg, dir := validProject(t)
+		cfg.Docker = &tt.docker
+		err := ValidateConfig(cfg, dir)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%s: ValidateConfig() error = %v", tt.name, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: ValidateConfig() error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+	cfg := &DatagenConfig{Docker: &DockerConfig{PythonVersion: "3.12"}}
+	if cfg.BaseImage() != "python:3.12-slim" {
+		t.Errorf("BaseImage() = %q, want python:3.12-slim", cfg.BaseImage())
+	}
+}
+
+func TestValidateEnvironments(t *testing.T) {
+	t.Parallel()
+
+	cfg, dir := validProject(t)
+	cfg.Environments = map[string]EnvironmentConfig{"staging": {RailwayEnvironment: "stage"}}
+	if err := ValidateConfig(cfg, dir); err != nil {
+		t.Fatalf("ValidateConfig() error = %v", err)
+	}
+	cfg.Environments["production"] = EnvironmentConfig{RailwayProject: "agents-prod"}
+	if err := ValidateConfig(cfg, dir); err == nil || !strings.Contains(err.Error(), "environments.production.railway_project") {
+		t.Fatalf("ValidateConfig() error = %v, want railway_project rejected", err)
+	}
+}
+
+func TestPipelineStages(t *testing.T) {
+	t.Parallel()
+
+	svc := Service{Steps: []PipelineStep{
+		{Name: "a"}, {Name: "b"}, {Name: "c", Parallel: true}, {Name: "d", Parallel: true}, {Name: "e"},
+	}}
+	var got []string
+	for _, stage := range svc.PipelineStages() {
+		var names []string
+		for _, step := range stage {
+			names = append(names, step.Name)
+		}
+		got = append(got, strings.Join(names, "+"))
+	}
+	if strings.Join(got, " ") != "a b+c+d e" {
+		t.Fatalf("PipelineStages() = %v, want [a b+c+d e]", got)
+	}
+}
+
+func TestStateStoreWarning(t *testing.T) {
+	t.Parallel()
+
+	webhook := []Service{{Name: "hook", Type: "webhook"}}
+	tests := []struct {
+		name   string
+		cfg    DatagenConfig
+		expect bool
+	}{
+		{name: "single worker", cfg: DatagenConfig{Services: webhook}},
+		{name: "workers in memory", cfg: DatagenConfig{Server: &ServerConfig{Workers: 4}, Services: webhook}, expect: true},
+		{name: "replicas in memory", cfg: DatagenConfig{Railway: &RailwayConfig{Replicas: 2}, Services: webhook}, expect: true},
+		{name: "workers with redis", cfg: DatagenConfig{Server: &ServerConfig{Workers: 4, StateStore: StateStoreRedis}, Services: webhook}},
+		{name: "no shared state", cfg: DatagenConfig{Server: &ServerConfig{Workers: 4}, Services: []Service{{Name: "chat", Type: "api"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.StateStoreWarning(); (got != "") != tt.expect {
+				t.Fatalf("StateStoreWarning() = %q, want warning %v", got, tt.expect)
+			}
+		})
+	}
+
+	cfg := &DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Server:           &ServerConfig{StateStore: "memcached"},
+	}
+	if err := ValidateConfig(cfg, t.TempDir()); err == nil || !strings.Contains(err.Error(), "state_store") {
+		t.Fatalf("ValidateConfig() error = %v, want state_store error", err)
+	}
+}
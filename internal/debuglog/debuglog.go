@@ -0,0 +1,296 @@
+// Package debuglog writes the --debug session log: a JSON-lines record of the
+// external commands, API calls and file writes of one datagen invocation, kept
+// under ~/.datagen/logs so a failure can be diagnosed after the fact.
+package debuglog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/transcript"
+)
+
+// Keep is how many session logs are kept
+const Keep = 50
+
+// maxOutput caps how much of a command's stdout or stderr is logged
+const maxOutput = 64 << 10
+
+var (
+	mu      sync.Mutex
+	file    *os.File
+	logger  *slog.Logger
+	started time.Time
+)
+
+// Dir returns ~/.datagen/logs
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".datagen", "logs"), nil
+}
+
+// Enabled reports whether a session log is being written
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return logger != nil
+}
+
+// Start opens a session log for args (os.Args) and routes API calls made
+// through http.DefaultTransport into it. It returns the log's path.
+func Start(args []string, version string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	now := time.Now()
+	// Name the log after the subcommand
+	name := "datagen"
+	for _, arg := range args[min(1, len(args)):] {
+		if !strings.HasPrefix(arg, "-") {
+			name = sanitize(arg)
+			break
+		}
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.log", now.Format("20060102-150405.000"), name))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+	prune(dir)
+
+	mu.Lock()
+	file = f
+	logger = slog.New(slog.NewJSONHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	started = now
+	mu.Unlock()
+
+	cwd, _ := os.Getwd()
+	log("session.start",
+		"args", transcript.Redact(strings.Join(args, " ")),
+		"version", version,
+		"os", runtime.GOOS+"/"+runtime.GOARCH,
+		"cwd", cwd,
+	)
+	http.DefaultTransport = &transport{next: http.DefaultTransport}
+	return path, nil
+}
+
+// Close records how the session ended and closes the log
+func Close(runErr error) {
+	if !Enabled() {
+		return
+	}
+	attrs := []any{"duration", time.Since(started).String()}
+	if runErr != nil {
+		attrs = append(attrs, "error", transcript.Redact(runErr.Error()))
+	}
+	log("session.end", attrs...)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if t, ok := http.DefaultTransport.(*transport); ok {
+		http.DefaultTransport = t.next
+	}
+	_ = file.Close()
+	file, logger = nil, nil
+}
+
+// Logf records a free-form message
+func Logf(format string, args ...any) {
+	log("message", "text", transcript.Redact(fmt.Sprintf(format, args...)))
+}
+
+func log(event string, attrs ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+	if logger != nil {
+		logger.Debug(event, attrs...)
+	}
+}
+
+// Command records cmd, whose arguments have already been masked as args, and
+// returns a function to call with its result. Stdout and stderr writers set
+// on cmd are teed into the log, so call it before cmd runs; output returned
+// by cmd.Output can be passed to the result function instead.
+func Command(cmd *exec.Cmd, args []string, redact ...string) func(err error, output []byte) {
+	if !Enabled() {
+		return func(error, []byte) {}
+	}
+	var stdout, stderr capBuffer
+	cmd.Stdout = tee(cmd.Stdout, &stdout)
+	cmd.Stderr = tee(cmd.Stderr, &stderr)
+	start := time.Now()
+	return func(err error, output []byte) {
+		attrs := []any{
+			"args", transcript.Redact(strings.Join(args, " "), redact...),
+			"dir", cmd.Dir,
+			"duration", time.Since(start).String(),
+		}
+		out := append(stdout.Bytes(), output...)
+		errOut := stderr.Bytes()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			errOut = append(errOut, exitErr.Stderr...)
+		}
+		if len(out) > 0 {
+			attrs = append(attrs, "stdout", transcript.Redact(truncate(out), redact...))
+		}
+		if len(errOut) > 0 {
+			attrs = append(attrs, "stderr", transcript.Redact(truncate(errOut), redact...))
+		}
+		if err != nil {
+			attrs = append(attrs, "error", err.Error())
+		}
+		log("exec", attrs...)
+	}
+}
+
+// tee copies writes to w into buf. Pipes set up by cmd.StdoutPipe are left
+// alone since exec writes to them directly.
+func tee(w io.Writer, buf *capBuffer) io.Writer {
+	if w == nil {
+		return nil
+	}
+	if f, ok := w.(*os.File); ok && f != os.Stdout && f != os.Stderr {
+		return w
+	}
+	return io.MultiWriter(w, buf)
+}
+
+// FileWrite records that path is about to be replaced by data, with a diff
+// against its current contents. Call it before writing.
+func FileWrite(path string, data []byte) {
+	if !Enabled() {
+		return
+	}
+	abs, err := filepath.Abs(path)
+	if err == nil {
+		path = abs
+	}
+	old, err := os.ReadFile(path)
+	attrs := []any{"path", path, "bytes", len(data)}
+	switch {
+	case err != nil:
+		attrs = append(attrs, "created", true)
+	case bytes.Equal(old, data):
+		attrs = append(attrs, "unchanged", true)
+	default:
+		before, after := string(old), string(data)
+		if isDotEnv(path) {
+			before, after = maskDotEnv(before), maskDotEnv(after)
+		}
+		diff := transcript.Redact(Diff(before, after))
+		if diff == "" {
+			diff = "(only masked values changed)"
+		}
+		attrs = append(attrs, "diff", diff)
+	}
+	log("file.write", attrs...)
+}
+
+// transport records each HTTP request's method, URL, status and timing.
+// Bodies and headers are left out since they carry credentials.
+type transport struct {
+	next http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	u := *req.URL
+	u.RawQuery = ""
+	attrs := []any{"method", req.Method, "url", u.String(), "duration", time.Since(start).String()}
+	if err != nil {
+		attrs = append(attrs, "error", err.Error())
+	} else {
+		attrs = append(attrs, "status", resp.StatusCode)
+	}
+	log("http", attrs...)
+	return resp, err
+}
+
+// capBuffer keeps the first maxOutput bytes written to it
+type capBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *capBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if room := maxOutput - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+func (b *capBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...)
+}
+
+func truncate(data []byte) string {
+	if len(data) > maxOutput {
+		return string(data[:maxOutput]) + "\n[truncated]"
+	}
+	return string(data)
+}
+
+var dotEnvValuePattern = regexp.MustCompile(`(?m)^(\s*(?:export\s+)?[A-Za-z_][A-Za-z0-9_]*\s*=).*$`)
+
+func isDotEnv(path string) bool {
+	base := filepath.Base(path)
+	return base == ".env" || strings.HasPrefix(base, ".env.")
+}
+
+// maskDotEnv hides every value in a .env file, not only ones named like keys
+func maskDotEnv(contents string) string {
+	return dotEnvValuePattern.ReplaceAllString(contents, "${1}[REDACTED]")
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+func sanitize(s string) string {
+	return unsafeNameChars.ReplaceAllString(s, "_")
+}
+
+// prune removes all but the newest Keep session logs
+func prune(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	var logs []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".log") {
+			logs = append(logs, e.Name())
+		}
+	}
+	// Names start with a sortable timestamp
+	sort.Sort(sort.Reverse(sort.StringSlice(logs)))
+	for _, name := range logs[min(Keep, len(logs)):] {
+		_ = os.Remove(filepath.Join(dir, name))
+	}
+}
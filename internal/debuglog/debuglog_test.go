@@ -0,0 +1,104 @@
+package debuglog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	after := "a\nb\nc\nd\nE\nf\ng\nh\ni\nj\nk\n"
+	want := "@@ -2,9 +2,10 @@\n b\n c\n d\n-e\n+E\n f\n g\n h\n i\n j\n+k\n"
+	if got := Diff(before, after); got != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, want)
+	}
+	if got := Diff("same\n", "same\n"); got != "" {
+		t.Errorf("Diff() of equal input = %q, want empty", got)
+	}
+}
+
+func TestSession(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	path, err := Start([]string{"datagen", "--debug", "env", "pull"}, "v1.2.3")
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if !strings.HasSuffix(path, "-env.log") || filepath.Dir(path) != filepath.Join(home, ".datagen", "logs") {
+		t.Errorf("Start() path = %s", path)
+	}
+
+	resp, err := http.Get(server.URL + "/whoami?token=secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	cmd := exec.Command("echo", "ANTHROPIC_API_KEY=sk-ant-abc123")
+	done := Command(cmd, cmd.Args)
+	out, err := cmd.Output()
+	done(err, out)
+
+	envPath := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envPath, []byte("DATABASE_URL=postgres://old\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	FileWrite(envPath, []byte("DATABASE_URL=postgres://new\nPORT=8080\n"))
+
+	Close(nil)
+	if Enabled() {
+		t.Fatal("expected logging to stop after Close")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for _, leak := range []string{"secret", "sk-ant-abc123", "postgres://"} {
+		if strings.Contains(text, leak) {
+			t.Errorf("log leaks %q:\n%s", leak, text)
+		}
+	}
+
+	events := map[string]map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("line is not JSON: %q", line)
+		}
+		events[entry["msg"].(string)] = entry
+	}
+	for _, event := range []string{"session.start", "http", "exec", "file.write", "session.end"} {
+		if events[event] == nil {
+			t.Errorf("missing %s event in:\n%s", event, text)
+		}
+	}
+	if status := events["http"]["status"]; status != float64(http.StatusTeapot) {
+		t.Errorf("http status = %v, want 418", status)
+	}
+	if diff, _ := events["file.write"]["diff"].(string); !strings.Contains(diff, " DATABASE_URL=[REDACTED]\n+PORT=[REDACTED]") {
+		t.Errorf("file.write diff = %q", diff)
+	}
+}
+
+func TestDiff_SeparateHunks(t *testing.T) {
+	before := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	after := "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n"
+	want := "@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n"
+	if got := Diff(before, after); got != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, want)
+	}
+}
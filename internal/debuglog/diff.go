@@ -0,0 +1,98 @@
+package debuglog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDiffLines bounds the quadratic line diff; larger files are summarised
+const maxDiffLines = 4000
+
+// diffContext is how many unchanged lines surround each change
+const diffContext = 3
+
+// Diff returns a unified-style line diff of before and after
+func Diff(before, after string) string {
+	a, b := splitLines(before), splitLines(after)
+	if len(a) > maxDiffLines || len(b) > maxDiffLines {
+		return fmt.Sprintf("(%d lines -> %d lines; too large to diff)", len(a), len(b))
+	}
+
+	// lcs[i][j] is the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+		// aLine and bLine are the 1-based positions the line starts at
+		aLine, bLine int
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, line{' ', a[i], i + 1, j + 1})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', a[i], i + 1, j + 1})
+			i++
+		default:
+			lines = append(lines, line{'+', b[j], i + 1, j + 1})
+			j++
+		}
+	}
+
+	var out strings.Builder
+	for start := 0; start < len(lines); {
+		if lines[start].op == ' ' {
+			start++
+			continue
+		}
+		// Grow the hunk until diffContext*2 unchanged lines separate changes
+		from := max(0, start-diffContext)
+		end := start
+		for k := start; k < len(lines) && k-end <= diffContext*2; k++ {
+			if lines[k].op != ' ' {
+				end = k
+			}
+		}
+		to := min(len(lines), end+diffContext+1)
+
+		aCount, bCount := 0, 0
+		for _, l := range lines[from:to] {
+			if l.op != '+' {
+				aCount++
+			}
+			if l.op != '-' {
+				bCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", lines[from].aLine, aCount, lines[from].bLine, bCount)
+		for _, l := range lines[from:to] {
+			fmt.Fprintf(&out, "%c%s\n", l.op, l.text)
+		}
+		start = to
+	}
+	return out.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
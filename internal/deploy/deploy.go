@@ -0,0 +1,108 @@
+// Package deploy puts each hosting platform behind a single Platform interface
+// so commands can ship, inspect and tear down generated projects without
+// knowing which platform they target.
+package deploy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/codegen"
+)
+
+// Project identifies the platform resources a project directory deploys to
+type Project struct {
+	Dir         string
+	Name        string
+	ID          string
+	Environment string
+	Service     string
+}
+
+// Status describes a project's most recent deployment
+type Status struct {
+	DeploymentID string
+	State        string
+	CreatedAt    time.Time
+}
+
+// Platform is a hosting target for generated projects
+type Platform interface {
+	// Name is the platform's flag value (e.g. "railway")
+	Name() string
+	// Detect reports whether dir is already set up for this platform
+	Detect(dir string) bool
+	// RequiredFiles lists the files the platform builds or configures a
+	// deploy from, relative to the project directory
+	RequiredFiles() []string
+	// EnsureAuth checks the platform tooling is installed and logged in
+	EnsureAuth() error
+	// EnsureProject returns the project dir deploys to, creating one if needed
+	EnsureProject(dir string) (*Project, error)
+	// SetVariables sets environment variables on the project's service
+	SetVariables(p *Project, vars map[string]string) error
+	// Deploy uploads the project directory and starts a deployment
+	Deploy(p *Project) error
+	// Status returns the latest deployment, or nil if there has been none
+	Status(p *Project) (*Status, error)
+	// URL returns the public base URL of the deployed service
+	URL(p *Project) (string, error)
+	// Destroy deletes the project, or only its service when keepProject is set
+	Destroy(p *Project, keepProject bool) error
+}
+
+// Names returns the supported platform names
+func Names() []string {
+	return []string{"railway"}
+}
+
+// New returns the platform called name
+func New(name string) (Platform, error) {
+	switch name {
+	case "railway":
+		return NewRailway(), nil
+	default:
+		return nil, fmt.Errorf("unsupported platform %q (supported: %s)", name, strings.Join(Names(), ", "))
+	}
+}
+
+// Detect returns the first supported platform dir is set up for
+func Detect(dir string) (Platform, error) {
+	for _, name := range Names() {
+		p, err := New(name)
+		if err != nil {
+			return nil, err
+		}
+		if p.Detect(dir) {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not set up for any supported platform (%s)", dir, strings.Join(Names(), ", "))
+}
+
+// MissingFiles returns the files a deploy of dir to p needs that dir lacks:
+// the app and packaging files its build manifest lists, and the files p builds
+// from. p may be nil to check only the build's files.
+func MissingFiles(dir string, p Platform) ([]string, error) {
+	files, err := codegen.RequiredFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if p != nil {
+		files = append(files, p.RequiredFiles()...)
+	}
+	return codegen.MissingFiles(dir, files), nil
+}
+
+// CheckRequiredFiles returns an error naming the files MissingFiles reports
+func CheckRequiredFiles(dir string, p Platform) error {
+	missing, err := MissingFiles(dir, p)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s is missing %s; run 'datagen build' to regenerate them", dir, strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package deploy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/railway"
+	"github.com/datagendev/datagen-cli/internal/transcript"
+)
+
+// runFunc runs a Railway CLI command in dir, streaming its output
+type runFunc func(dir string, args ...string) error
+
+func runRailway(dir string, args ...string) error {
+	cmd := railway.Command(dir, args...)
+	cmd.Stdout = output.Progress()
+	rec := transcript.Attach(cmd)
+	if err := output.Run(cmd); err != nil {
+		return rec.Fail(dir, fmt.Errorf("railway %s failed: %w", args[0], err))
+	}
+	return nil
+}
+
+// Railway deploys through the Railway CLI and reads or deletes resources
+// through the Railway GraphQL API
+type Railway struct {
+	run       runFunc
+	newClient func() (*railway.Client, error)
+}
+
+// NewRailway returns the Railway platform
+func NewRailway() *Railway {
+	return &Railway{
+		run: runRailway,
+		newClient: func() (*railway.Client, error) {
+			token, err := railway.APIToken()
+			if err != nil {
+				return nil, err
+			}
+			return railway.NewClient(token), nil
+		},
+	}
+}
+
+// ProjectFromLink converts a `railway link` entry into a Project
+func ProjectFromLink(link *railway.LinkedProject) *Project {
+	return &Project{
+		Dir:         link.ProjectPath,
+		Name:        link.Name,
+		ID:          link.Project,
+		Environment: link.Environment,
+		Service:     link.Service,
+	}
+}
+
+func (r *Railway) Name() string { return "railway" }
+
+func (r *Railway) Detect(dir string) bool {
+	if _, err := railway.FindLinkedProject(dir); err == nil {
+		return true
+	}
+	for _, name := range []string{"railway.json", "railway.toml"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiredFiles is the Dockerfile Railway builds and railway.json, which
+// selects that builder and sets the health check
+func (r *Railway) RequiredFiles() []string {
+	return []string{"Dockerfile", "railway.json"}
+}
+
+func (r *Railway) EnsureAuth() error {
+	if err := railway.EnsureCLI(); err != nil {
+		return err
+	}
+	if err := r.run("", "whoami"); err != nil {
+		return fmt.Errorf("not logged in to Railway (run 'railway login'): %w", err)
+	}
+	return nil
+}
+
+// EnsureProject returns dir's linked project, running `railway init` to create
+// and link one when dir is not linked yet
+func (r *Railway) EnsureProject(dir string) (*Project, error) {
+	if link, err := railway.FindLinkedProject(dir); err == nil {
+		return ProjectFromLink(link), nil
+	}
+	if err := r.run(dir, "init"); err != nil {
+		return nil, err
+	}
+	link, err := railway.FindLinkedProject(dir)
+	if err != nil {
+		return nil, err
+	}
+	return ProjectFromLink(link), nil
+}
+
+func (r *Railway) SetVariables(p *Project, vars map[string]string) error {
+	return railway.SetVariables(railway.VariablesTarget{
+		Dir:         p.Dir,
+		Service:     p.Service,
+		Environment: p.Environment,
+	}, vars)
+}
+
+func (r *Railway) Deploy(p *Project) error {
+	args := []string{"up", "--detach"}
+	if p.Service != "" {
+		args = append(args, "--service", p.Service)
+	}
+	if p.Environment != "" {
+		args = append(args, "--environment", p.Environment)
+	}
+	return r.run(p.Dir, args...)
+}
+
+func (r *Railway) Status(p *Project) (*Status, error) {
+	if p.Service == "" {
+		return nil, fmt.Errorf("no Railway service is linked for %s (run 'railway service')", p.Dir)
+	}
+	client, err := r.newClient()
+	if err != nil {
+		return nil, err
+	}
+	dep, err := client.LatestDeployment(p.ID, p.Environment, p.Service)
+	if err != nil || dep == nil {
+		return nil, err
+	}
+	return &Status{DeploymentID: dep.ID, State: dep.Status, CreatedAt: dep.CreatedAt}, nil
+}
+
+func (r *Railway) URL(p *Project) (string, error) {
+	if p.Service == "" {
+		return "", fmt.Errorf("no Railway service is linked for %s (run 'railway service')", p.Dir)
+	}
+	client, err := r.newClient()
+	if err != nil {
+		return "", err
+	}
+	domain, err := client.ServiceDomain(p.ID, p.Environment, p.Service)
+	if err != nil {
+		return "", err
+	}
+	return "https://" + domain, nil
+}
+
+func (r *Railway) Destroy(p *Project, keepProject bool) error {
+	if keepProject && p.Service == "" {
+		return fmt.Errorf("no service is linked for %s (run 'railway service' to pick one)", p.Dir)
+	}
+	client, err := r.newClient()
+	if err != nil {
+		return err
+	}
+	if keepProject {
+		return client.DeleteService(p.Service)
+	}
+	return client.DeleteProject(p.ID)
+}
@@ -0,0 +1,169 @@
+package deploy
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/railway"
+)
+
+// fakeRailway returns a Railway platform whose API calls go to handler and
+// whose CLI invocations are recorded in calls
+func fakeRailway(t *testing.T, handler http.HandlerFunc) (*Railway, *[][]string) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	var calls [][]string
+	r := &Railway{
+		run: func(dir string, args ...string) error {
+			calls = append(calls, append([]string{dir}, args...))
+			return nil
+		},
+		newClient: func() (*railway.Client, error) {
+			client := railway.NewClient("tok")
+			client.URL = server.URL
+			return client, nil
+		},
+	}
+	return r, &calls
+}
+
+func graphQLHandler(t *testing.T, wantQuery, response string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		var payload struct {
+			Query string `json:"query"`
+		}
+		_ = json.Unmarshal(body, &payload)
+		if !strings.Contains(payload.Query, wantQuery) {
+			t.Errorf("query = %q, want it to contain %q", payload.Query, wantQuery)
+		}
+		_, _ = w.Write([]byte(response))
+	}
+}
+
+func TestRailwayDeployArgs(t *testing.T) {
+	r, calls := fakeRailway(t, nil)
+	project := &Project{Dir: "/tmp/app", Service: "s1", Environment: "e1"}
+
+	if err := r.Deploy(project); err != nil {
+		t.Fatalf("Deploy() error = %v", err)
+	}
+	want := [][]string{{"/tmp/app", "up", "--detach", "--service", "s1", "--environment", "e1"}}
+	if !reflect.DeepEqual(*calls, want) {
+		t.Fatalf("Deploy() ran %v, want %v", *calls, want)
+	}
+}
+
+func TestRailwayURL(t *testing.T) {
+	r, _ := fakeRailway(t, graphQLHandler(t, "domains(", `{"data": {"domains": {"customDomains": [], "serviceDomains": [{"domain": "agents.up.railway.app"}]}}}`))
+
+	url, err := r.URL(&Project{ID: "p1", Environment: "e1", Service: "s1"})
+	if err != nil {
+		t.Fatalf("URL() error = %v", err)
+	}
+	if url != "https://agents.up.railway.app" {
+		t.Fatalf("URL() = %q", url)
+	}
+
+	if _, err := r.URL(&Project{ID: "p1"}); err == nil {
+		t.Fatalf("URL() without service error = nil, want error")
+	}
+}
+
+func TestRailwayStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     *Status
+	}{
+		{
+			name:     "deployed",
+			response: `{"data": {"deployments": {"edges": [{"node": {"id": "d1", "status": "SUCCESS", "createdAt": "2026-01-02T03:04:05Z"}}]}}}`,
+			want:     &Status{DeploymentID: "d1", State: "SUCCESS"},
+		},
+		{
+			name:     "never deployed",
+			response: `{"data": {"deployments": {"edges": []}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := fakeRailway(t, graphQLHandler(t, "deployments(", tt.response))
+			got, err := r.Status(&Project{ID: "p1", Environment: "e1", Service: "s1"})
+			if err != nil {
+				t.Fatalf("Status() error = %v", err)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("Status() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.DeploymentID != tt.want.DeploymentID || got.State != tt.want.State || got.CreatedAt.IsZero() {
+				t.Fatalf("Status() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRailwayDestroy(t *testing.T) {
+	tests := []struct {
+		name        string
+		keepProject bool
+		wantQuery   string
+	}{
+		{name: "project", wantQuery: "projectDelete"},
+		{name: "service only", keepProject: true, wantQuery: "serviceDelete"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := fakeRailway(t, graphQLHandler(t, tt.wantQuery, `{"data": {}}`))
+			if err := r.Destroy(&Project{ID: "p1", Service: "s1"}, tt.keepProject); err != nil {
+				t.Fatalf("Destroy() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRailwayEnsureProjectUsesExistingLink(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, "proj")
+	if err := os.MkdirAll(filepath.Join(home, ".railway"), 0o755); err != nil {
+		t.Fatalf("MkdirAll error = %v", err)
+	}
+	cfg := `{"projects": {"` + dir + `": {"projectPath": "` + dir + `", "name": "agents", "project": "p1", "environment": "e1", "service": "s1"}}}`
+	if err := os.WriteFile(filepath.Join(home, ".railway", "config.json"), []byte(cfg), 0o600); err != nil {
+		t.Fatalf("WriteFile error = %v", err)
+	}
+
+	r, calls := fakeRailway(t, nil)
+	project, err := r.EnsureProject(dir)
+	if err != nil {
+		t.Fatalf("EnsureProject() error = %v", err)
+	}
+	if project.ID != "p1" || project.Service != "s1" || project.Name != "agents" {
+		t.Fatalf("EnsureProject() = %+v", project)
+	}
+	if len(*calls) != 0 {
+		t.Fatalf("EnsureProject() ran %v, want no railway init for a linked dir", *calls)
+	}
+	if !r.Detect(dir) {
+		t.Fatalf("Detect() = false for a linked dir")
+	}
+}
+
+func TestNewUnsupportedPlatform(t *testing.T) {
+	if _, err := New("heroku"); err == nil {
+		t.Fatalf("New(heroku) error = nil, want unsupported platform error")
+	}
+}
@@ -0,0 +1,157 @@
+// Package deps resolves the generated runtime's Python packages against PyPI
+// so projects can pin exact versions, and flags upgrades likely to break the
+// generated code.
+package deps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultIndexURL serves PyPI's JSON API
+	DefaultIndexURL = "https://pypi.org/pypi"
+
+	// DefaultTimeout bounds a single package lookup
+	DefaultTimeout = 15 * time.Second
+)
+
+// ReleaseNotes links the changelogs of the packages whose APIs the generated
+// code calls directly. Upgrades to these are checked for breaking changes.
+var ReleaseNotes = map[string]string{
+	"fastapi":          "https://fastapi.tiangolo.com/release-notes/",
+	"claude-agent-sdk": "https://github.com/anthropics/claude-agent-sdk-python/blob/main/CHANGELOG.md",
+}
+
+// Client looks up releases on a package index
+type Client struct {
+	HTTPClient *http.Client
+	IndexURL   string
+}
+
+// New returns a Client for PyPI
+func New() *Client {
+	return &Client{
+		HTTPClient: &http.Client{Timeout: DefaultTimeout},
+		IndexURL:   DefaultIndexURL,
+	}
+}
+
+// project is the part of PyPI's /pypi/<name>/json reply Latest reads
+type project struct {
+	Releases map[string][]struct {
+		Yanked bool `json:"yanked"`
+	} `json:"releases"`
+}
+
+// Latest returns the newest final release of name that has files and was not
+// yanked. Pre-releases, dev and post releases are skipped.
+func (c *Client) Latest(ctx context.Context, name string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(c.IndexURL, "/")+"/"+url.PathEscape(name)+"/json", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("could not reach the package index: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%s is not on the package index", name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("package index returned %d for %s: %s", resp.StatusCode, name, strings.TrimSpace(string(body)))
+	}
+	var p project
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		return "", fmt.Errorf("could not parse the package index reply for %s: %w", name, err)
+	}
+
+	var best string
+	var bestParts []int
+	for version, files := range p.Releases {
+		parts, ok := ParseVersion(version)
+		if !ok || len(files) == 0 {
+			continue
+		}
+		available := false
+		for _, f := range files {
+			available = available || !f.Yanked
+		}
+		if available && (bestParts == nil || Compare(parts, bestParts) > 0) {
+			best, bestParts = version, parts
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("%s has no final releases", name)
+	}
+	return best, nil
+}
+
+// ParseVersion splits a final release version such as "0.115.6" into its
+// numbers. It reports false for pre-releases ("1.0rc1") and anything else
+// with letters.
+func ParseVersion(v string) ([]int, bool) {
+	if v == "" {
+		return nil, false
+	}
+	fields := strings.Split(v, ".")
+	parts := make([]int, len(fields))
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// Compare orders two parsed versions, treating missing numbers as zero
+func Compare(a, b []int) int {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// Breaking reports whether moving from one version to a newer one may break
+// callers: a new major version, or a new minor version while the major is 0,
+// since 0.x packages break their API in minor releases
+func Breaking(from, to string) bool {
+	a, okA := ParseVersion(from)
+	b, okB := ParseVersion(to)
+	if !okA || !okB || Compare(b, a) <= 0 {
+		return false
+	}
+	a, b = append(a, 0, 0), append(b, 0, 0)
+	if a[0] != b[0] {
+		return true
+	}
+	return a[0] == 0 && a[1] != b[1]
+}
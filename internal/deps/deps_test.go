@@ -0,0 +1,68 @@
+package deps
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLatest(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fastapi/json":
+			w.Write([]byte(`{"releases": {
+				"0.9.0":    [{"yanked": false}],
+				"0.115.6":  [{"yanked": false}],
+				"0.116.0":  [{"yanked": true}],
+				"0.115.12": [{"yanked": false}, {"yanked": true}],
+				"0.117.0rc1": [{"yanked": false}],
+				"0.118.0":  []
+			}}`))
+		case "/prerelease-only/json":
+			w.Write([]byte(`{"releases": {"1.0b1": [{"yanked": false}]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	c := &Client{HTTPClient: srv.Client(), IndexURL: srv.URL}
+
+	got, err := c.Latest(context.Background(), "fastapi")
+	if err != nil || got != "0.115.12" {
+		t.Fatalf("Latest(fastapi) = %q, %v; want 0.115.12", got, err)
+	}
+	if _, err := c.Latest(context.Background(), "prerelease-only"); err == nil || !strings.Contains(err.Error(), "no final releases") {
+		t.Fatalf("Latest(prerelease-only) error = %v, want no final releases", err)
+	}
+	if _, err := c.Latest(context.Background(), "missing"); err == nil || !strings.Contains(err.Error(), "not on the package index") {
+		t.Fatalf("Latest(missing) error = %v, want not on the package index", err)
+	}
+}
+
+func TestBreaking(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{"0.115.0", "0.115.12", false},
+		{"0.115.0", "0.116.1", true},
+		{"0.1.0", "0.1.9", false},
+		{"0.1.0", "0.2.0", true},
+		{"2.10.0", "2.11.3", false},
+		{"2.10.0", "3.0.0", true},
+		{"1", "1.2", false},
+		{"0.116.1", "0.115.0", false},
+		{"0.1.0", "1.0rc1", false},
+	}
+	for _, tt := range tests {
+		if got := Breaking(tt.from, tt.to); got != tt.want {
+			t.Errorf("Breaking(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Package diagnose asks Claude to explain a failed deploy from its saved
+// command transcript.
+package diagnose
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/models"
+	"github.com/datagendev/datagen-cli/internal/transcript"
+)
+
+const (
+	// DefaultBaseURL is the Anthropic API
+	DefaultBaseURL = "https://api.anthropic.com"
+
+	// DefaultModel matches the default model of generated agents
+	DefaultModel = models.Default
+
+	// MaxLogBytes is how much of a transcript is sent; the tail is kept since
+	// failures are usually reported last
+	MaxLogBytes = 60 * 1024
+)
+
+const systemPrompt = `You are helping a developer whose deploy of a DataGen agent service failed.
+The service is a generated FastAPI app (Python, Claude Agent SDK) deployed to Railway.
+You are given the redacted output of the failing command. Reply with:
+1. The most likely cause, in one or two sentences.
+2. Up to three concrete fixes, most likely first, with exact commands or file edits where possible.
+3. Anything in the log that is ambiguous and worth checking.
+Be brief. Do not repeat the log back.`
+
+// Client calls the Anthropic Messages API
+type Client struct {
+	HTTPClient *http.Client
+	BaseURL    string
+	APIKey     string
+	Model      string
+}
+
+// New returns a client for the Anthropic API
+func New(apiKey, model string) *Client {
+	if model == "" {
+		model = DefaultModel
+	}
+	return &Client{
+		HTTPClient: &http.Client{Timeout: 2 * time.Minute},
+		BaseURL:    DefaultBaseURL,
+		APIKey:     apiKey,
+		Model:      model,
+	}
+}
+
+type messageRequest struct {
+	Model     string    `json:"model"`
+	MaxTokens int       `json:"max_tokens"`
+	System    string    `json:"system"`
+	Messages  []message `json:"messages"`
+}
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type messageResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Prompt builds the user message for a transcript, redacting and trimming it
+func Prompt(log string) string {
+	log = transcript.Redact(log)
+	if len(log) > MaxLogBytes {
+		log = "[earlier output truncated]\n" + log[len(log)-MaxLogBytes:]
+	}
+	return "The deploy failed. Command output:\n\n<log>\n" + log + "\n</log>"
+}
+
+// Diagnose returns Claude's explanation of the failure in log
+func (c *Client) Diagnose(ctx context.Context, log string) (string, error) {
+	return c.Ask(ctx, systemPrompt, Prompt(log), 1024)
+}
+
+// Ask sends a single user message with the given system prompt and returns
+// the text of Claude's reply
+func (c *Client) Ask(ctx context.Context, system, user string, maxTokens int) (string, error) {
+	payload, err := json.Marshal(messageRequest{
+		Model:     c.Model,
+		MaxTokens: maxTokens,
+		System:    system,
+		Messages:  []message{{Role: "user", Content: user}},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(c.BaseURL, "/")+"/v1/messages", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("x-api-key", c.APIKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request to Claude failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Claude response: %w", err)
+	}
+
+	var out messageResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return "", fmt.Errorf("failed to parse Claude response (%d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if out.Error != nil {
+			return "", fmt.Errorf("Claude API error (%d): %s", resp.StatusCode, out.Error.Message)
+		}
+		return "", fmt.Errorf("Claude API error (%d)", resp.StatusCode)
+	}
+
+	var text strings.Builder
+	for _, block := range out.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("Claude returned no text")
+	}
+	return strings.TrimSpace(text.String()), nil
+}
@@ -0,0 +1,72 @@
+package diagnose
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDiagnose(t *testing.T) {
+	var captured messageRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Fatalf("path = %s, want /v1/messages", r.URL.Path)
+		}
+		if got := r.Header.Get("x-api-key"); got != "sk-ant-test" {
+			t.Fatalf("x-api-key = %q, want sk-ant-test", got)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			t.Fatalf("Decode(request) error = %v", err)
+		}
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"  Missing requirements.txt.  "}]}`))
+	}))
+	defer server.Close()
+
+	c := New("sk-ant-test", "")
+	c.BaseURL = server.URL
+	c.HTTPClient = server.Client()
+
+	got, err := c.Diagnose(context.Background(), "ERROR: no requirements.txt\nANTHROPIC_API_KEY=sk-ant-leaked")
+	if err != nil {
+		t.Fatalf("Diagnose() error = %v", err)
+	}
+	if got != "Missing requirements.txt." {
+		t.Fatalf("Diagnose() = %q, want trimmed text", got)
+	}
+	if captured.Model != DefaultModel {
+		t.Fatalf("model = %q, want %q", captured.Model, DefaultModel)
+	}
+	if len(captured.Messages) != 1 || !strings.Contains(captured.Messages[0].Content, "no requirements.txt") {
+		t.Fatalf("messages = %+v, want the log", captured.Messages)
+	}
+	if strings.Contains(captured.Messages[0].Content, "sk-ant-leaked") {
+		t.Fatalf("log sent unredacted: %q", captured.Messages[0].Content)
+	}
+}
+
+func TestDiagnoseAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`))
+	}))
+	defer server.Close()
+
+	c := New("bad", "")
+	c.BaseURL = server.URL
+	c.HTTPClient = server.Client()
+
+	if _, err := c.Diagnose(context.Background(), "log"); err == nil || !strings.Contains(err.Error(), "invalid x-api-key") {
+		t.Fatalf("Diagnose() error = %v, want invalid x-api-key", err)
+	}
+}
+
+func TestPromptKeepsTail(t *testing.T) {
+	log := strings.Repeat("a", MaxLogBytes) + "THE ERROR"
+	got := Prompt(log)
+	if !strings.Contains(got, "THE ERROR") || !strings.Contains(got, "[earlier output truncated]") {
+		t.Fatalf("Prompt() did not keep the tail of a long log")
+	}
+}
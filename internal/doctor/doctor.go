@@ -0,0 +1,323 @@
+// Package doctor checks the local environment for what datagen commands and
+// generated projects need, with a suggested fix for each problem.
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/agents"
+	"github.com/datagendev/datagen-cli/internal/api"
+	"github.com/datagendev/datagen-cli/internal/auth"
+	"github.com/datagendev/datagen-cli/internal/codegen"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/deploy"
+	"github.com/datagendev/datagen-cli/internal/mcpconfig"
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/railway"
+)
+
+// MinPython is the oldest Python the generated app supports (claude-agent-sdk)
+var MinPython = [2]int{3, 10}
+
+// Status is the outcome of a check
+type Status int
+
+const (
+	OK Status = iota
+	Warn
+	Fail
+	Skip
+)
+
+// Result is the outcome of one check. Fix is empty for passing checks.
+type Result struct {
+	Name   string
+	Status Status
+	Detail string
+	Fix    string
+}
+
+// Options selects what Run checks
+type Options struct {
+	// Dir is the project directory
+	Dir string
+	// ConfigPath is the datagen.toml to validate
+	ConfigPath string
+	// Offline skips the API key validation and network checks
+	Offline bool
+}
+
+// Run performs every check in order
+func Run(opts Options) []Result {
+	results := []Result{CheckPython()}
+	results = append(results, CheckDeployCLIs()...)
+	results = append(results,
+		CheckAPIKey(opts.Offline),
+		CheckAgents(opts.Dir),
+		CheckConfig(opts.ConfigPath),
+		CheckMarkers(opts.Dir),
+		CheckProjectFiles(opts.Dir),
+	)
+	if opts.Offline {
+		results = append(results, Result{Name: "Network", Status: Skip, Detail: "skipped (--offline)"})
+	} else {
+		results = append(results, CheckReachable("DataGen MCP", mcpconfig.DatagenMCPURL))
+	}
+	return results
+}
+
+var pythonVersionPattern = regexp.MustCompile(`Python (\d+)\.(\d+)(?:\.(\d+))?`)
+
+// CheckPython looks for python3 (or python) and checks its version
+func CheckPython() Result {
+	r := Result{Name: "Python"}
+	for _, bin := range []string{"python3", "python"} {
+		path, err := exec.LookPath(bin)
+		if err != nil {
+			continue
+		}
+		out, err := output.CombinedOutput(exec.Command(path, "--version"))
+		if err != nil {
+			continue
+		}
+		major, minor, ok := ParsePythonVersion(string(out))
+		if !ok {
+			continue
+		}
+		version := strings.TrimSpace(string(out))
+		if major < MinPython[0] || (major == MinPython[0] && minor < MinPython[1]) {
+			r.Status = Fail
+			r.Detail = fmt.Sprintf("%s at %s", version, path)
+			r.Fix = fmt.Sprintf("Install Python %d.%d or newer (generated apps use claude-agent-sdk)", MinPython[0], MinPython[1])
+			return r
+		}
+		r.Detail = fmt.Sprintf("%s at %s", version, path)
+		return r
+	}
+	r.Status = Fail
+	r.Detail = "python3 not found on PATH"
+	r.Fix = fmt.Sprintf("Install Python %d.%d or newer from https://www.python.org/downloads/ to run generated apps locally", MinPython[0], MinPython[1])
+	return r
+}
+
+// ParsePythonVersion extracts major and minor from `python --version` output
+func ParsePythonVersion(out string) (major, minor int, ok bool) {
+	m := pythonVersionPattern.FindStringSubmatch(out)
+	if m == nil {
+		return 0, 0, false
+	}
+	major, _ = strconv.Atoi(m[1])
+	minor, _ = strconv.Atoi(m[2])
+	return major, minor, true
+}
+
+// deployCLIs are the platform CLIs deploy targets shell out to. find, when
+// set, also looks outside PATH; windows replaces install on Windows.
+var deployCLIs = []struct {
+	name    string
+	bins    []string
+	find    func() (string, error)
+	install string
+	windows string
+}{
+	{"Railway CLI", []string{"railway"}, railway.Binary, "datagen install railway (needed for railway up, datagen logs and datagen env)", ""},
+	{"Fly CLI", []string{"flyctl", "fly"}, nil, "curl -L https://fly.io/install.sh | sh (only needed to deploy to Fly.io)",
+		`powershell -Command "iwr https://fly.io/install.ps1 -useb | iex" (only needed to deploy to Fly.io)`},
+	{"Google Cloud CLI", []string{"gcloud"}, nil, "see https://cloud.google.com/sdk/docs/install (only needed to deploy to Cloud Run)", ""},
+}
+
+// CheckDeployCLIs reports which platform CLIs are installed. Missing ones are
+// warnings since each is only needed for its own platform.
+func CheckDeployCLIs() []Result {
+	var results []Result
+	for _, c := range deployCLIs {
+		install := c.install
+		if runtime.GOOS == "windows" && c.windows != "" {
+			install = c.windows
+		}
+		r := Result{Name: c.name, Status: Warn, Detail: "not found on PATH", Fix: "Install with: " + install}
+		for _, bin := range c.bins {
+			if path, err := exec.LookPath(bin); err == nil {
+				r = Result{Name: c.name, Detail: path}
+				break
+			}
+		}
+		if r.Status == Warn && c.find != nil {
+			if path, err := c.find(); err == nil {
+				r = Result{Name: c.name, Detail: path}
+			}
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// CheckAPIKey resolves DATAGEN_API_KEY like other commands and, unless
+// offline, validates it against the DataGen API
+func CheckAPIKey(offline bool) Result {
+	r := Result{Name: "DATAGEN_API_KEY"}
+	key, source, ok := auth.FindEnvVarOrProfile("DATAGEN_API_KEY")
+	if !ok {
+		r.Status = Fail
+		r.Detail = "not found in the active profile, environment, keychain or shell profiles"
+		r.Fix = "Run 'datagen login'"
+		return r
+	}
+	r.Detail = "found in " + source
+	if offline {
+		return r
+	}
+
+	me, err := api.NewClient(key).WhoAmI()
+	switch {
+	case err == nil:
+		r.Detail = fmt.Sprintf("valid for %s (from %s)", me.Email, source)
+	case strings.Contains(err.Error(), "API error (401)") || strings.Contains(err.Error(), "API error (403)"):
+		r.Status = Fail
+		r.Detail = fmt.Sprintf("rejected by the DataGen API (from %s)", source)
+		r.Fix = "Run 'datagen login' to store a new key"
+	default:
+		r.Status = Warn
+		r.Detail = fmt.Sprintf("found in %s but could not be validated: %v", source, err)
+		r.Fix = "Check your network connection, then run 'datagen whoami'"
+	}
+	return r
+}
+
+// CheckAgents looks for agent prompts in dir/.claude/agents
+func CheckAgents(dir string) Result {
+	r := Result{Name: "Agents"}
+	agentsDir := filepath.Join(dir, ".claude", "agents")
+	found, err := agents.Discover(agentsDir)
+	switch {
+	case err != nil && !os.IsNotExist(err):
+		r.Status = Fail
+		r.Detail = err.Error()
+		r.Fix = "Fix the frontmatter of the agent files in " + agentsDir
+	case len(found) == 0:
+		r.Status = Warn
+		r.Detail = "no agents in " + agentsDir
+		r.Fix = "Add a prompt such as .claude/agents/<name>.md, or run 'datagen start' to create one"
+	default:
+		r.Detail = fmt.Sprintf("%d agent(s) in %s", len(found), agentsDir)
+	}
+	return r
+}
+
+// CheckConfig validates the datagen.toml at path
+func CheckConfig(path string) Result {
+	r := Result{Name: "datagen.toml"}
+	path = config.FindConfig(path)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		r.Status = Skip
+		r.Detail = path + " not found (not a datagen project)"
+		return r
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		r.Status = Fail
+		r.Detail = err.Error()
+		r.Fix = "Edit " + path + " to fix the error above"
+		return r
+	}
+	r.Detail = fmt.Sprintf("%s is valid (%d service(s))", path, len(cfg.Services))
+	if migrations, err := config.MigrateFiles(path); err == nil && len(migrations) > 0 {
+		r.Status = Warn
+		r.Detail += fmt.Sprintf(", in a config format older than version %d", config.CurrentVersion)
+		r.Fix = "Run 'datagen migrate' to upgrade it"
+	}
+	return r
+}
+
+// CheckMarkers checks that a generated app still has the markers 'datagen
+// add' injects between
+func CheckMarkers(dir string) Result {
+	r := Result{Name: "Generated code"}
+	if _, err := os.Stat(filepath.Join(dir, "app", "main.py")); os.IsNotExist(err) {
+		r.Status = Skip
+		r.Detail = "no generated app in " + dir
+		return r
+	}
+	missing, err := codegen.MissingMarkers(dir)
+	if err != nil {
+		r.Status = Fail
+		r.Detail = err.Error()
+		return r
+	}
+	if len(missing) > 0 {
+		r.Status = Fail
+		r.Detail = "missing markers: " + strings.Join(missing, "; ")
+		r.Fix = "Run 'datagen repair' to restore the marker comments; 'datagen add' cannot inject services without them"
+		return r
+	}
+	r.Detail = "datagen markers intact in app/main.py and app/models.py"
+	return r
+}
+
+// CheckProjectFiles checks that a generated app still has the files its last
+// build produced that deploys need, and those its deploy platform builds from
+func CheckProjectFiles(dir string) Result {
+	r := Result{Name: "Project files"}
+	if _, err := os.Stat(filepath.Join(dir, "app", "main.py")); os.IsNotExist(err) {
+		r.Status = Skip
+		r.Detail = "no generated app in " + dir
+		return r
+	}
+	if _, err := codegen.ReadManifest(dir); os.IsNotExist(err) {
+		r.Status = Warn
+		r.Detail = "no build manifest (" + codegen.ManifestFile + ")"
+		r.Fix = "Run 'datagen build' to record the files it generates"
+		return r
+	}
+	// Without a platform set up, only the app and packaging files are checked
+	platform, _ := deploy.Detect(dir)
+	missing, err := deploy.MissingFiles(dir, platform)
+	switch {
+	case err != nil:
+		r.Status = Fail
+		r.Detail = err.Error()
+	case len(missing) > 0:
+		r.Status = Fail
+		r.Detail = "missing " + strings.Join(missing, ", ")
+		r.Fix = "Run 'datagen build' to regenerate them"
+	case platform != nil:
+		r.Detail = "app, packaging and " + platform.Name() + " files present"
+	default:
+		r.Detail = "app and packaging files present"
+	}
+	return r
+}
+
+// CheckReachable reports whether url answers HTTPS requests. Any HTTP
+// response counts; the endpoint may reject unauthenticated calls.
+func CheckReachable(name, url string) Result {
+	r := Result{Name: name}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		r.Status = Fail
+		r.Detail = err.Error()
+		return r
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		r.Status = Fail
+		r.Detail = fmt.Sprintf("%s unreachable: %v", url, err)
+		r.Fix = "Check your network, firewall or proxy (HTTPS_PROXY) settings"
+		return r
+	}
+	resp.Body.Close()
+	r.Detail = fmt.Sprintf("%s reachable (HTTP %d)", url, resp.StatusCode)
+	return r
+}
@@ -0,0 +1,120 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/codegen"
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+func TestParsePythonVersion(t *testing.T) {
+	tests := []struct {
+		out          string
+		major, minor int
+		ok           bool
+	}{
+		{"Python 3.12.4\n", 3, 12, true},
+		{"Python 3.9", 3, 9, true},
+		{"Python 2.7.18", 2, 7, true},
+		{"python: command not found", 0, 0, false},
+	}
+	for _, tt := range tests {
+		major, minor, ok := ParsePythonVersion(tt.out)
+		if major != tt.major || minor != tt.minor || ok != tt.ok {
+			t.Errorf("ParsePythonVersion(%q) = %d, %d, %v; want %d, %d, %v", tt.out, major, minor, ok, tt.major, tt.minor, tt.ok)
+		}
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	if r := CheckConfig(filepath.Join(dir, "datagen.toml")); r.Status != Skip {
+		t.Errorf("missing config: status = %v, want Skip", r.Status)
+	}
+
+	invalid := filepath.Join(dir, "invalid.toml")
+	if err := os.WriteFile(invalid, []byte("[[service]]\nname = \"x\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if r := CheckConfig(invalid); r.Status != Fail || r.Fix == "" {
+		t.Errorf("invalid config: got %+v, want Fail with a fix", r)
+	}
+}
+
+func TestCheckMarkers(t *testing.T) {
+	dir := t.TempDir()
+	if r := CheckMarkers(dir); r.Status != Skip {
+		t.Fatalf("no app: status = %v, want Skip", r.Status)
+	}
+
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{{
+			Name:   "summarize",
+			Type:   "api",
+			Prompt: ".claude/agents/summarize.md",
+		}},
+	}
+	if _, err := codegen.GenerateProject(cfg, dir); err != nil {
+		t.Fatalf("GenerateProject() error = %v", err)
+	}
+	if r := CheckMarkers(dir); r.Status != OK {
+		t.Fatalf("generated app: got %+v, want OK", r)
+	}
+
+	mainPath := filepath.Join(dir, "app", "main.py")
+	data, err := os.ReadFile(mainPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.ReplaceAll(string(data), "=== ENDPOINT HANDLERS END ===", "")
+	if err := os.WriteFile(mainPath, []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := CheckMarkers(dir)
+	if r.Status != Fail || !strings.Contains(r.Detail, "app/main.py: === ENDPOINT HANDLERS END ===") {
+		t.Fatalf("edited app: got %+v, want Fail naming the missing marker", r)
+	}
+}
+
+func TestCheckProjectFiles(t *testing.T) {
+	dir := t.TempDir()
+	if r := CheckProjectFiles(dir); r.Status != Skip {
+		t.Fatalf("no app: status = %v, want Skip", r.Status)
+	}
+
+	cfg := &config.DatagenConfig{
+		DatagenAPIKeyEnv: "DATAGEN_API_KEY",
+		ClaudeAPIKeyEnv:  "ANTHROPIC_API_KEY",
+		Services: []config.Service{{
+			Name:   "summarize",
+			Type:   "api",
+			Prompt: ".claude/agents/summarize.md",
+		}},
+	}
+	if _, err := codegen.GenerateProject(cfg, dir); err != nil {
+		t.Fatalf("GenerateProject() error = %v", err)
+	}
+	if r := CheckProjectFiles(dir); r.Status != OK || !strings.Contains(r.Detail, "railway") {
+		t.Fatalf("generated app: got %+v, want OK with the railway files", r)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "Dockerfile")); err != nil {
+		t.Fatal(err)
+	}
+	if r := CheckProjectFiles(dir); r.Status != Fail || r.Detail != "missing Dockerfile" {
+		t.Fatalf("no Dockerfile: got %+v, want Fail naming it", r)
+	}
+
+	if err := os.Remove(filepath.Join(dir, codegen.ManifestFile)); err != nil {
+		t.Fatal(err)
+	}
+	if r := CheckProjectFiles(dir); r.Status != Warn {
+		t.Fatalf("no manifest: got %+v, want Warn", r)
+	}
+}
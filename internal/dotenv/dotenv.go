@@ -0,0 +1,366 @@
+// Package dotenv reads and writes .env files the way python-dotenv (which the
+// generated app loads them with, through pydantic-settings) does: optional
+// `export` prefixes, single- and double-quoted values that may span lines,
+// escapes in double quotes, inline comments after whitespace, and ${VAR} or
+// ${VAR:-default} references outside single quotes.
+package dotenv
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Entry is one variable assignment in a .env file
+type Entry struct {
+	Key   string
+	Value string
+	// Comment is the last comment line above the entry's group of lines, if any
+	Comment string
+	// InlineComment is the comment after the value on the same line, if any
+	InlineComment string
+	// Line is the 1-based line the assignment starts on
+	Line int
+
+	end    int  // line the assignment (a multiline value) ends on
+	export bool // written with an export prefix
+}
+
+// SyntaxError is a line Parse cannot read
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+var (
+	keyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+	// refPattern matches ${VAR} and ${VAR:-default}; Railway's ${{ ... }}
+	// references don't match and are kept as written
+	refPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+)
+
+// Read parses the .env file at path. The error satisfies os.IsNotExist when
+// there is no file.
+func Read(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// Load reads the .env file at path into a map; a key assigned twice has its
+// last value
+func Load(path string) (map[string]string, error) {
+	entries, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+	return Values(entries), nil
+}
+
+// Values maps each key to its last value
+func Values(entries []Entry) map[string]string {
+	values := make(map[string]string, len(entries))
+	for _, e := range entries {
+		values[e.Key] = e.Value
+	}
+	return values
+}
+
+// Parse reads the assignments in data. Lines without an = (a bare key) are
+// skipped; references resolve to keys assigned earlier in data, then to the
+// process environment.
+func Parse(data []byte) ([]Entry, error) {
+	p := &parser{src: strings.ReplaceAll(string(data), "\r\n", "\n"), line: 1}
+	values := map[string]string{}
+	var entries []Entry
+	comment := ""
+	for !p.done() {
+		p.skipBlanks()
+		switch {
+		case p.done():
+			continue
+		case p.peek() == '\n':
+			comment = ""
+			p.next()
+			continue
+		case p.peek() == '#':
+			comment = strings.TrimSpace(p.restOfLine()[1:])
+			p.endOfLine()
+			continue
+		}
+
+		start := p.line
+		e, ok, err := p.assignment()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if e.quote != '\'' {
+			e.Value = expand(e.Value, values)
+		}
+		values[e.Key] = e.Value
+		entries = append(entries, Entry{Key: e.Key, Value: e.Value, Comment: comment, InlineComment: e.comment, Line: start, end: p.line, export: e.export})
+		p.endOfLine()
+	}
+	return entries, nil
+}
+
+type parser struct {
+	src  string
+	pos  int
+	line int
+}
+
+type assignment struct {
+	Key     string
+	Value   string
+	quote   byte
+	comment string
+	export  bool
+}
+
+func (p *parser) done() bool { return p.pos >= len(p.src) }
+func (p *parser) peek() byte { return p.src[p.pos] }
+
+func (p *parser) next() byte {
+	c := p.src[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+func (p *parser) skipBlanks() {
+	for !p.done() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+// restOfLine consumes up to (not including) the next newline
+func (p *parser) restOfLine() string {
+	end := strings.IndexByte(p.src[p.pos:], '\n')
+	if end < 0 {
+		end = len(p.src) - p.pos
+	}
+	s := p.src[p.pos : p.pos+end]
+	p.pos += end
+	return s
+}
+
+// endOfLine consumes the newline ending the current line
+func (p *parser) endOfLine() {
+	if !p.done() {
+		p.next()
+	}
+}
+
+// assignment reads `[export] KEY = value`. ok is false for a line with no =.
+func (p *parser) assignment() (assignment, bool, error) {
+	line := p.line
+	export := false
+	if rest := p.src[p.pos:]; strings.HasPrefix(rest, "export ") || strings.HasPrefix(rest, "export\t") {
+		p.pos += len("export")
+		p.skipBlanks()
+		export = true
+	}
+	keyEnd := strings.IndexAny(p.src[p.pos:], "= \t\n#")
+	if keyEnd < 0 {
+		keyEnd = len(p.src) - p.pos
+	}
+	key := p.src[p.pos : p.pos+keyEnd]
+	p.pos += keyEnd
+	p.skipBlanks()
+	if p.done() || p.peek() == '\n' {
+		return assignment{}, false, nil
+	}
+	if p.peek() != '=' {
+		return assignment{}, false, &SyntaxError{Line: line, Msg: fmt.Sprintf("expected = after %q", key)}
+	}
+	if !keyPattern.MatchString(key) {
+		return assignment{}, false, &SyntaxError{Line: line, Msg: fmt.Sprintf("invalid variable name %q", key)}
+	}
+	p.next()
+	p.skipBlanks()
+
+	a := assignment{Key: key, export: export}
+	if p.done() || (p.peek() != '\'' && p.peek() != '"') {
+		a.Value, a.comment = unquotedValue(p.restOfLine())
+		return a, true, nil
+	}
+
+	a.quote = p.next()
+	var b strings.Builder
+	for {
+		if p.done() {
+			return a, false, &SyntaxError{Line: line, Msg: fmt.Sprintf("unterminated %s value for %s", quoteName(a.quote), key)}
+		}
+		c := p.next()
+		if c == a.quote {
+			break
+		}
+		if c == '\\' && !p.done() {
+			b.WriteString(unescape(a.quote, p.next()))
+			continue
+		}
+		b.WriteByte(c)
+	}
+	a.Value = b.String()
+
+	p.skipBlanks()
+	if !p.done() && p.peek() != '\n' && p.peek() != '#' {
+		return a, false, &SyntaxError{Line: p.line, Msg: fmt.Sprintf("unexpected %q after the quoted value of %s", p.restOfLine(), key)}
+	}
+	if rest := p.restOfLine(); rest != "" {
+		a.comment = strings.TrimSpace(rest[1:])
+	}
+	return a, true, nil
+}
+
+// unquotedValue splits off an inline comment (a # after whitespace) and
+// drops trailing whitespace
+func unquotedValue(s string) (value, comment string) {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '#' && (s[i-1] == ' ' || s[i-1] == '\t') {
+			s, comment = s[:i], strings.TrimSpace(s[i+1:])
+			break
+		}
+	}
+	return strings.TrimRight(s, " \t"), comment
+}
+
+func quoteName(q byte) string {
+	if q == '\'' {
+		return "single-quoted"
+	}
+	return "double-quoted"
+}
+
+// unescape decodes the escape \c: single quotes only know \\ and \', double
+// quotes also the control characters. Unknown escapes are kept as written.
+func unescape(quote, c byte) string {
+	switch c {
+	case '\\', '\'':
+		return string(c)
+	}
+	if quote == '"' {
+		switch c {
+		case '"':
+			return `"`
+		case 'n':
+			return "\n"
+		case 'r':
+			return "\r"
+		case 't':
+			return "\t"
+		case 'a':
+			return "\a"
+		case 'b':
+			return "\b"
+		case 'f':
+			return "\f"
+		case 'v':
+			return "\v"
+		}
+	}
+	return `\` + string(c)
+}
+
+// expand resolves ${VAR} and ${VAR:-default}: VAR from the keys assigned so
+// far, then the environment; the default only when VAR is unset
+func expand(s string, values map[string]string) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	return refPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		m := refPattern.FindStringSubmatch(ref)
+		if v, ok := values[m[1]]; ok {
+			return v
+		}
+		if v, ok := os.LookupEnv(m[1]); ok {
+			return v
+		}
+		return m[2]
+	})
+}
+
+// bareValue matches values written without quotes
+var bareValue = regexp.MustCompile(`^[A-Za-z0-9_./:@+,%=-]*$`)
+
+// Format writes key=value so Parse (and python-dotenv) read value back
+// exactly: bare when it is plain, in double quotes when only spaces or #
+// need quoting, otherwise in single quotes, which are never expanded
+func Format(key, value string) string {
+	switch {
+	case bareValue.MatchString(value):
+		return key + "=" + value
+	case !strings.ContainsAny(value, "\"\\$'\n\r"):
+		return key + `="` + value + `"`
+	default:
+		value = strings.ReplaceAll(value, `\`, `\\`)
+		value = strings.ReplaceAll(value, `'`, `\'`)
+		return key + "='" + value + "'"
+	}
+}
+
+// Update sets values in the .env contents data. Each assignment of a key
+// (every line of a multiline value) is replaced in place, keeping its export
+// prefix and inline comment, comments and other lines; keys data lacks are
+// appended in sorted order.
+func Update(data []byte, values map[string]string) ([]byte, error) {
+	entries, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	var lines []string
+	if text != "" {
+		lines = strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	}
+
+	found := map[string]bool{}
+	// Replace from the bottom up so earlier line numbers stay valid
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		v, ok := values[e.Key]
+		if !ok {
+			continue
+		}
+		found[e.Key] = true
+		end := min(e.end, len(lines))
+		line := Format(e.Key, v)
+		if e.export {
+			line = "export " + line
+		}
+		if e.InlineComment != "" {
+			line += " # " + e.InlineComment
+		}
+		lines = append(lines[:e.Line-1], append([]string{line}, lines[end:]...)...)
+	}
+
+	var missing []string
+	for k := range values {
+		if !found[k] {
+			missing = append(missing, k)
+		}
+	}
+	sort.Strings(missing)
+	for _, k := range missing {
+		lines = append(lines, Format(k, values[k]))
+	}
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
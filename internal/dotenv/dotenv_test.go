@@ -0,0 +1,174 @@
+package dotenv
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]string
+	}{
+		{"plain", "A=1\nB=two", map[string]string{"A": "1", "B": "two"}},
+		{"spaces around =", "A = 1 \n  B\t=\t2", map[string]string{"A": "1", "B": "2"}},
+		{"export prefix", "export A=1\nexport\tB=2", map[string]string{"A": "1", "B": "2"}},
+		{"key named export", "export=1", map[string]string{"export": "1"}},
+		{"empty value", "A=\nB=''\nC=\"\"", map[string]string{"A": "", "B": "", "C": ""}},
+		{"comments and blanks", "# top\n\n  # indented\nA=1\n", map[string]string{"A": "1"}},
+		{"inline comment", "A=1 # note\nB=x\t# tab", map[string]string{"A": "1", "B": "x"}},
+		{"hash without space", "URL=http://x/#frag\nC=#notcomment", map[string]string{"URL": "http://x/#frag", "C": "#notcomment"}},
+		{"= in value", "DSN=postgres://u:p@h/db?sslmode=require", map[string]string{"DSN": "postgres://u:p@h/db?sslmode=require"}},
+		{"double quotes keep # and =", `A="x # y = z"`, map[string]string{"A": "x # y = z"}},
+		{"single quotes keep # and =", `A='x # y = z'`, map[string]string{"A": "x # y = z"}},
+		{"comment after quotes", `A="x" # note`, map[string]string{"A": "x"}},
+		{"double-quote escapes", `A="l1\nl2\t\"q\"\\"`, map[string]string{"A": "l1\nl2\t\"q\"\\"}},
+		{"unknown escape kept", `A="C:\path"`, map[string]string{"A": `C:\path`}},
+		{"single quotes are literal", `A='l1\nl2 ${B}'`, map[string]string{"A": `l1\nl2 ${B}`}},
+		{"single-quote escapes", `A='it\'s \\'`, map[string]string{"A": `it's \`}},
+		{"multiline double", "A=\"line1\nline2\"\nB=3", map[string]string{"A": "line1\nline2", "B": "3"}},
+		{"multiline single", "KEY='-----BEGIN KEY-----\nabc\n-----END KEY-----'", map[string]string{"KEY": "-----BEGIN KEY-----\nabc\n-----END KEY-----"}},
+		{"crlf", "A=1\r\nB=\"x\r\ny\"\r\n", map[string]string{"A": "1", "B": "x\ny"}},
+		{"bare key skipped", "A\nB=1", map[string]string{"B": "1"}},
+		{"last assignment wins", "A=1\nA=2", map[string]string{"A": "2"}},
+		{"reference", "HOST=db\nURL=postgres://${HOST}/x\nQ=\"${HOST}\"", map[string]string{"HOST": "db", "URL": "postgres://db/x", "Q": "db"}},
+		{"reference default", "A=${MISSING_DOTENV_TEST_VAR:-fallback}", map[string]string{"A": "fallback"}},
+		{"empty is not unset", "E=\nA=${E:-fallback}", map[string]string{"E": "", "A": ""}},
+		{"railway reference kept", "DB=${{Postgres.DATABASE_URL}}", map[string]string{"DB": "${{Postgres.DATABASE_URL}}"}},
+		{"dollar without braces kept", "P=pa$$word", map[string]string{"P": "pa$$word"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := Parse([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := Values(entries); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEnvironmentReference(t *testing.T) {
+	t.Setenv("DOTENV_TEST_HOME", "/home/x")
+	entries, err := Parse([]byte("A=${DOTENV_TEST_HOME}/data\nDOTENV_TEST_HOME=/override\nB=${DOTENV_TEST_HOME}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Values(entries)
+	if got["A"] != "/home/x/data" || got["B"] != "/override" {
+		t.Errorf("Parse() = %q; want A from the environment, B from the file", got)
+	}
+}
+
+func TestParseEntries(t *testing.T) {
+	input := "# Required\nANTHROPIC_API_KEY=your-anthropic-api-key-here\n\nMODEL_NAME=claude-sonnet-4-5\n\n# Auth for triage service\nAPI_KEY=your-secret-here # secret\nPEM=\"a\nb\"\t#  plain \nHMAC=\n"
+	entries, err := Parse([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Entry{
+		{Key: "ANTHROPIC_API_KEY", Value: "your-anthropic-api-key-here", Comment: "Required", Line: 2, end: 2},
+		{Key: "MODEL_NAME", Value: "claude-sonnet-4-5", Line: 4, end: 4},
+		{Key: "API_KEY", Value: "your-secret-here", Comment: "Auth for triage service", InlineComment: "secret", Line: 7, end: 7},
+		{Key: "PEM", Value: "a\nb", Comment: "Auth for triage service", InlineComment: "plain", Line: 8, end: 9},
+		{Key: "HMAC", Value: "", Comment: "Auth for triage service", Line: 10, end: 10},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Parse() = %+v\nwant %+v", entries, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		line  int
+	}{
+		{"unterminated double", "A=1\nB=\"open\nC=3", 2},
+		{"unterminated single", "A='open", 1},
+		{"text after quotes", "A=1\n\nB=\"x\" y", 3},
+		{"space in key", "MY KEY=1", 1},
+		{"invalid key", "1A=1", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.input))
+			var syntax *SyntaxError
+			if !errors.As(err, &syntax) {
+				t.Fatalf("Parse() error = %v, want a *SyntaxError", err)
+			}
+			if syntax.Line != tt.line {
+				t.Errorf("error on line %d, want %d (%v)", syntax.Line, tt.line, err)
+			}
+		})
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	values := []string{
+		"",
+		"plain-value_1.2",
+		"postgres://u:p@h/db?sslmode=require",
+		"hello world",
+		"a#b",
+		`quote " inside`,
+		"it's",
+		`back\slash`,
+		"pa$$word ${NOT_EXPANDED}",
+		"line1\nline2",
+		"-----BEGIN KEY-----\nabc\\n'x'\n-----END KEY-----",
+	}
+	for _, v := range values {
+		line := Format("K", v)
+		entries, err := Parse([]byte(line + "\n"))
+		if err != nil {
+			t.Errorf("Parse(Format(%q)) = %q: error %v", v, line, err)
+			continue
+		}
+		if len(entries) != 1 || entries[0].Value != v {
+			t.Errorf("Parse(%q) = %+v, want %q back", line, entries, v)
+		}
+	}
+	if got := Format("GREETING", "hello world"); got != `GREETING="hello world"` {
+		t.Errorf("Format() = %s, want double quotes for a value with spaces", got)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	input := "# Required\nexport A=old # note\n\nPEM=\"l1\nl2\"\nB=keep\nA=dup\n"
+	got, err := Update([]byte(input), map[string]string{"A": "new", "PEM": "x", "Z": "z z", "C": "c"})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	want := "# Required\nexport A=new # note\n\nPEM=x\nB=keep\nA=new\nC=c\nZ=\"z z\"\n"
+	if string(got) != want {
+		t.Errorf("Update() = %q, want %q", got, want)
+	}
+
+	if got, _ := Update(nil, map[string]string{"A": "1"}); string(got) != "A=1\n" {
+		t.Errorf("Update(nil) = %q", got)
+	}
+	if _, err := Update([]byte("A=\"open"), map[string]string{"A": "1"}); err == nil {
+		t.Error("Update() of an unparsable file should fail rather than guess")
+	}
+}
+
+func TestRead(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Read(filepath.Join(dir, ".env")); !os.IsNotExist(err) {
+		t.Errorf("Read() of a missing file error = %v, want not-exist", err)
+	}
+	path := filepath.Join(dir, ".env")
+	if err := os.WriteFile(path, []byte("A=1\nB='x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !errors.As(err, new(*SyntaxError)) {
+		t.Errorf("Load() error = %v, want a syntax error naming the file", err)
+	}
+}
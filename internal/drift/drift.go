@@ -0,0 +1,155 @@
+package drift
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/codegen"
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+// RemoteVersion is the payload served by the generated app's /version endpoint
+type RemoteVersion struct {
+	CLIVersion   string            `json:"cli_version"`
+	TemplateHash string            `json:"template_hash"`
+	GitCommit    string            `json:"git_commit"`
+	Services     map[string]string `json:"services"`
+}
+
+// OpenAPI is the subset of an OpenAPI document needed to compare request schemas
+type OpenAPI struct {
+	Paths      map[string]map[string]OpenAPIOperation `json:"paths"`
+	Components struct {
+		Schemas map[string]OpenAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+// OpenAPIOperation is a single path operation
+type OpenAPIOperation struct {
+	RequestBody struct {
+		Content map[string]struct {
+			Schema OpenAPISchema `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+}
+
+// OpenAPISchema is a JSON schema object or reference
+type OpenAPISchema struct {
+	Ref        string                   `json:"$ref"`
+	Properties map[string]OpenAPISchema `json:"properties"`
+	Required   []string                 `json:"required"`
+}
+
+// Finding describes one difference between local and deployed state
+type Finding struct {
+	Service string
+	Message string
+}
+
+func (f Finding) String() string {
+	if f.Service == "" {
+		return f.Message
+	}
+	return fmt.Sprintf("%s: %s", f.Service, f.Message)
+}
+
+// Compare reports drift between the local config/metadata and a deployed instance.
+// openapi may be nil when the deployment does not publish a schema.
+func Compare(cfg *config.DatagenConfig, local *codegen.ProjectMetadata, remote *RemoteVersion, openapi *OpenAPI) []Finding {
+	var findings []Finding
+
+	if remote.CLIVersion != local.CLIVersion {
+		findings = append(findings, Finding{Message: fmt.Sprintf("CLI version differs (local %s, deployed %s)", local.CLIVersion, valueOrUnknown(remote.CLIVersion))})
+	}
+	if remote.TemplateHash != local.TemplateHash {
+		findings = append(findings, Finding{Message: "templates differ (regenerate with the current CLI and redeploy)"})
+	}
+
+	for _, svc := range cfg.Services {
+		remoteHash, ok := remote.Services[svc.Name]
+		if !ok {
+			findings = append(findings, Finding{Service: svc.Name, Message: "missing from deployment"})
+			continue
+		}
+		if remoteHash != local.Services[svc.Name] {
+			findings = append(findings, Finding{Service: svc.Name, Message: "config differs from deployment"})
+		}
+		if openapi != nil {
+			findings = append(findings, compareSchema(&svc, openapi)...)
+		}
+	}
+
+	var extra []string
+	for name := range remote.Services {
+		if _, ok := local.Services[name]; !ok {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		findings = append(findings, Finding{Service: name, Message: "deployed but not in local config"})
+	}
+
+	return findings
+}
+
+func compareSchema(svc *config.Service, openapi *OpenAPI) []Finding {
+	path := svc.GetPath()
+	op, ok := openapi.Paths[path]["post"]
+	if !ok {
+		return []Finding{{Service: svc.Name, Message: fmt.Sprintf("POST %s not found in deployed OpenAPI schema", path)}}
+	}
+
+	media, ok := op.RequestBody.Content["application/json"]
+	if !ok {
+		return []Finding{{Service: svc.Name, Message: "deployed endpoint has no JSON request body"}}
+	}
+	schema := resolveRef(media.Schema, openapi)
+
+	var findings []Finding
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	localFields := map[string]bool{}
+	for _, field := range svc.InputSchema.Fields {
+		localFields[field.Name] = true
+		if _, ok := schema.Properties[field.Name]; !ok {
+			findings = append(findings, Finding{Service: svc.Name, Message: fmt.Sprintf("input field %q missing from deployment", field.Name)})
+			continue
+		}
+		if field.Required != required[field.Name] {
+			findings = append(findings, Finding{Service: svc.Name, Message: fmt.Sprintf("input field %q required=%t locally, %t deployed", field.Name, field.Required, required[field.Name])})
+		}
+	}
+
+	var extra []string
+	for name := range schema.Properties {
+		if !localFields[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		findings = append(findings, Finding{Service: svc.Name, Message: fmt.Sprintf("input field %q deployed but not in local config", name)})
+	}
+
+	return findings
+}
+
+func resolveRef(schema OpenAPISchema, openapi *OpenAPI) OpenAPISchema {
+	const prefix = "#/components/schemas/"
+	if !strings.HasPrefix(schema.Ref, prefix) {
+		return schema
+	}
+	return openapi.Components.Schemas[strings.TrimPrefix(schema.Ref, prefix)]
+}
+
+func valueOrUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
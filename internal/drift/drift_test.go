@@ -0,0 +1,109 @@
+package drift
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/codegen"
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+func testConfig() *config.DatagenConfig {
+	return &config.DatagenConfig{
+		Services: []config.Service{
+			{
+				Name:    "chat",
+				Type:    "api",
+				APIPath: "/api/chat",
+				InputSchema: config.Schema{Fields: []config.Field{
+					{Name: "message", Type: "str", Required: true},
+					{Name: "tone", Type: "str"},
+				}},
+			},
+		},
+	}
+}
+
+const testOpenAPI = `{
+  "paths": {
+    "/api/chat": {
+      "post": {
+        "requestBody": {
+          "content": {
+            "application/json": {"schema": {"$ref": "#/components/schemas/ChatInput"}}
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "ChatInput": {
+        "properties": {"message": {}, "tone": {}},
+        "required": ["message"]
+      }
+    }
+  }
+}`
+
+func TestCompare_NoDrift(t *testing.T) {
+	cfg := testConfig()
+	local, err := codegen.BuildProjectMetadata(cfg)
+	if err != nil {
+		t.Fatalf("BuildProjectMetadata() error = %v", err)
+	}
+
+	var openapi OpenAPI
+	if err := json.Unmarshal([]byte(testOpenAPI), &openapi); err != nil {
+		t.Fatalf("unmarshal openapi: %v", err)
+	}
+
+	remote := &RemoteVersion{
+		CLIVersion:   local.CLIVersion,
+		TemplateHash: local.TemplateHash,
+		Services:     map[string]string{"chat": local.Services["chat"]},
+	}
+
+	if findings := Compare(cfg, local, remote, &openapi); len(findings) != 0 {
+		t.Fatalf("Compare() = %v, want no findings", findings)
+	}
+}
+
+func TestCompare_ReportsDrift(t *testing.T) {
+	cfg := testConfig()
+	local, err := codegen.BuildProjectMetadata(cfg)
+	if err != nil {
+		t.Fatalf("BuildProjectMetadata() error = %v", err)
+	}
+
+	var openapi OpenAPI
+	if err := json.Unmarshal([]byte(testOpenAPI), &openapi); err != nil {
+		t.Fatalf("unmarshal openapi: %v", err)
+	}
+	cfg.Services[0].InputSchema.Fields[1].Required = true
+
+	remote := &RemoteVersion{
+		CLIVersion:   "v0.0.1",
+		TemplateHash: local.TemplateHash,
+		Services:     map[string]string{"chat": "stale", "legacy": "abc"},
+	}
+
+	findings := Compare(cfg, local, remote, &openapi)
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.String())
+	}
+	joined := strings.Join(got, "\n")
+
+	for _, want := range []string{
+		"CLI version differs",
+		"chat: config differs from deployment",
+		`chat: input field "tone" required=true locally, false deployed`,
+		"legacy: deployed but not in local config",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("Compare() findings missing %q:\n%s", want, joined)
+		}
+	}
+}
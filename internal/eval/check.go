@@ -0,0 +1,314 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Reply is a service's answer to one case
+type Reply struct {
+	Status int
+	// Text is the agent's reply: the result field of a JSON reply, or the
+	// text events of a stream joined together
+	Text string
+	// JSON is the decoded reply body, or the decoded Text for streams that
+	// answer in JSON; nil when neither is JSON
+	JSON any
+	// Error is the error event of a stream that failed part way
+	Error string
+}
+
+// ParseReply reads a response body by its content type: SSE and NDJSON
+// streams are joined into their text, JSON replies are decoded.
+func ParseReply(status int, contentType, body string) Reply {
+	r := Reply{Status: status}
+	switch {
+	case strings.HasPrefix(contentType, "text/event-stream"):
+		for _, block := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n\n") {
+			r.addSSE(block)
+		}
+	case strings.HasPrefix(contentType, "application/x-ndjson"):
+		for _, line := range strings.Split(body, "\n") {
+			var event map[string]any
+			if json.Unmarshal([]byte(line), &event) == nil {
+				r.addEvent(event)
+			}
+		}
+	default:
+		if json.Unmarshal([]byte(body), &r.JSON) == nil {
+			if obj, ok := r.JSON.(map[string]any); ok {
+				if result, ok := obj["result"].(string); ok {
+					r.Text = result
+					return r
+				}
+			}
+		}
+		r.Text = body
+		return r
+	}
+	var decoded any
+	if json.Unmarshal([]byte(r.Text), &decoded) == nil {
+		r.JSON = decoded
+	}
+	return r
+}
+
+func (r *Reply) addSSE(block string) {
+	eventType := "message"
+	var data []string
+	for _, line := range strings.Split(block, "\n") {
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if len(data) == 0 {
+		return
+	}
+	text := strings.Join(data, "\n")
+	var event map[string]any
+	if json.Unmarshal([]byte(text), &event) == nil && event["type"] != nil {
+		r.addEvent(event)
+		return
+	}
+	switch eventType {
+	case "message":
+		r.Text += text
+	case "error":
+		r.Error = text
+	}
+}
+
+func (r *Reply) addEvent(event map[string]any) {
+	switch event["type"] {
+	case "text":
+		text, _ := event["text"].(string)
+		r.Text += text
+	case "error":
+		r.Error = fmt.Sprint(event["error"])
+	}
+}
+
+// CheckResult is the outcome of one assertion
+type CheckResult struct {
+	Kind   string
+	Passed bool
+	// Detail explains a failure, or holds the grader's reason for rubrics
+	Detail string
+}
+
+// Asker sends one prompt to Claude; *diagnose.Client implements it
+type Asker interface {
+	Ask(ctx context.Context, system, user string, maxTokens int) (string, error)
+}
+
+// Check runs one assertion against a reply. grader is only used for rubrics
+// and may be nil otherwise.
+func Check(ctx context.Context, a Assertion, input map[string]any, reply Reply, grader Asker) CheckResult {
+	res := CheckResult{Kind: a.Kind()}
+	switch res.Kind {
+	case "contains":
+		res.Passed = strings.Contains(strings.ToLower(reply.Text), strings.ToLower(a.Contains))
+		if !res.Passed {
+			res.Detail = fmt.Sprintf("reply does not contain %q", a.Contains)
+		}
+	case "not_contains":
+		res.Passed = !strings.Contains(strings.ToLower(reply.Text), strings.ToLower(a.NotContains))
+		if !res.Passed {
+			res.Detail = fmt.Sprintf("reply contains %q", a.NotContains)
+		}
+	case "json_path":
+		got, err := Lookup(reply.JSON, a.JSONPath)
+		if err != nil {
+			res.Detail = err.Error()
+			break
+		}
+		res.Passed = equal(got, a.Equals)
+		if !res.Passed {
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(a.Equals)
+			res.Detail = fmt.Sprintf("%s = %s, want %s", a.JSONPath, gotJSON, wantJSON)
+		}
+	case "rubric":
+		if grader == nil {
+			res.Detail = "no grader configured"
+			break
+		}
+		score, reason, err := Grade(ctx, grader, a.Rubric, input, reply.Text)
+		if err != nil {
+			res.Detail = err.Error()
+			break
+		}
+		min := a.MinScore
+		if min == 0 {
+			min = DefaultMinScore
+		}
+		res.Passed = score >= min
+		res.Detail = fmt.Sprintf("scored %d/5 (needs %d): %s", score, min, reason)
+	}
+	return res
+}
+
+// equal compares a YAML value with a decoded JSON value by their JSON form,
+// so 3 equals 3.0 and map key types don't matter
+func equal(got, want any) bool {
+	data, err := json.Marshal(want)
+	if err != nil {
+		return false
+	}
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(got, normalized)
+}
+
+var pathIndex = regexp.MustCompile(`^([^\[\]]*)((?:\[\d+\])*)$`)
+
+// parsePath splits result.items[0].name into result, items, 0, name
+func parsePath(path string) ([]string, error) {
+	var parts []string
+	for _, segment := range strings.Split(path, ".") {
+		m := pathIndex.FindStringSubmatch(segment)
+		if m == nil || (m[1] == "" && m[2] == "") {
+			return nil, fmt.Errorf("invalid json_path %q", path)
+		}
+		if m[1] != "" {
+			parts = append(parts, m[1])
+		}
+		for _, idx := range strings.Split(strings.Trim(m[2], "[]"), "][") {
+			if idx != "" {
+				parts = append(parts, idx)
+			}
+		}
+	}
+	return parts, nil
+}
+
+// Lookup returns the value at a dotted path in decoded JSON. Array elements
+// are selected with [n] or .n.
+func Lookup(v any, path string) (any, error) {
+	if v == nil {
+		return nil, fmt.Errorf("reply is not JSON")
+	}
+	parts, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, part := range parts {
+		switch node := v.(type) {
+		case map[string]any:
+			next, ok := node[part]
+			if !ok {
+				return nil, fmt.Errorf("%s: no field %q", path, strings.Join(parts[:i+1], "."))
+			}
+			v = next
+		case []any:
+			n, err := strconv.Atoi(part)
+			if err != nil || n < 0 || n >= len(node) {
+				return nil, fmt.Errorf("%s: no element %s", path, part)
+			}
+			v = node[n]
+		default:
+			return nil, fmt.Errorf("%s: %s is not an object or array", path, strings.Join(parts[:i], "."))
+		}
+	}
+	return v, nil
+}
+
+const graderPrompt = `You grade the replies of an AI agent service against a rubric.
+You are given the rubric, the request payload the service received and the service's reply.
+Score how well the reply meets the rubric from 1 (not at all) to 5 (fully).
+Answer with only a JSON object: {"score": <1-5>, "reason": "<one sentence>"}`
+
+var gradeJSON = regexp.MustCompile(`(?s)\{.*\}`)
+
+// Grade asks Claude to score reply against rubric from 1 to 5
+func Grade(ctx context.Context, grader Asker, rubric string, input map[string]any, reply string) (int, string, error) {
+	payload, err := json.MarshalIndent(input, "", "  ")
+	if err != nil {
+		return 0, "", err
+	}
+	user := fmt.Sprintf("<rubric>\n%s\n</rubric>\n\n<request>\n%s\n</request>\n\n<reply>\n%s\n</reply>", rubric, payload, reply)
+	answer, err := grader.Ask(ctx, graderPrompt, user, 300)
+	if err != nil {
+		return 0, "", fmt.Errorf("grading failed: %w", err)
+	}
+	var grade struct {
+		Score  int    `json:"score"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(gradeJSON.FindString(answer)), &grade); err != nil || grade.Score < 1 || grade.Score > 5 {
+		return 0, "", fmt.Errorf("grader returned no score: %q", answer)
+	}
+	return grade.Score, grade.Reason, nil
+}
+
+// Sender posts a case's input to the suite's endpoint and returns the reply
+type Sender func(ctx context.Context, input map[string]any) (Reply, error)
+
+// Result is the outcome of one run of one case
+type Result struct {
+	Suite  string
+	Case   string
+	Checks []CheckResult
+	// Err is set when the request failed, returned an error status or the
+	// stream ended with an error; all of the case's assertions then fail
+	Err error
+}
+
+// Passed counts the passing assertions
+func (r Result) Passed() int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+// Run sends each case runs times and checks its assertions
+func (s *Suite) Run(ctx context.Context, send Sender, grader Asker, runs int) []Result {
+	var results []Result
+	for _, c := range s.Cases {
+		for i := 0; i < runs; i++ {
+			res := Result{Suite: s.Name(), Case: c.Name}
+			reply, err := send(ctx, c.Input)
+			switch {
+			case err != nil:
+				res.Err = err
+			case reply.Status < 200 || reply.Status > 299:
+				res.Err = fmt.Errorf("HTTP %d: %s", reply.Status, truncate(reply.Text, 200))
+			case reply.Error != "":
+				res.Err = fmt.Errorf("stream error: %s", reply.Error)
+			}
+			for _, a := range c.Assert {
+				if res.Err != nil {
+					res.Checks = append(res.Checks, CheckResult{Kind: a.Kind(), Detail: "not checked"})
+					continue
+				}
+				res.Checks = append(res.Checks, Check(ctx, a, c.Input, reply, grader))
+			}
+			results = append(results, res)
+		}
+	}
+	return results
+}
+
+func truncate(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
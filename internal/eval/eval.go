@@ -0,0 +1,185 @@
+// Package eval runs evaluation suites (evals/*.yaml) against a project's
+// services and scores the replies, so prompt changes can be gated in CI.
+//
+// A suite names one service and lists cases, each an input payload with
+// assertions on the reply:
+//
+//	service: support-triage
+//	cases:
+//	  - name: refund request
+//	    input: {message: "I want my money back"}
+//	    assert:
+//	      - contains: refund
+//	      - json_path: result.priority
+//	        equals: high
+//	      - rubric: Apologizes and explains the refund policy
+package eval
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yaml "go.yaml.in/yaml/v3"
+)
+
+// DefaultDir is where suites live in a project
+const DefaultDir = "evals"
+
+// DefaultMinScore is the rubric grade (1-5) an assertion needs to pass
+const DefaultMinScore = 4
+
+// Suite is one evals/*.yaml file
+type Suite struct {
+	// Service is the name of the service in datagen.toml
+	Service string `yaml:"service"`
+	// Path is the endpoint path, for suites that don't name a service
+	Path  string `yaml:"path"`
+	Cases []Case `yaml:"cases"`
+
+	// File is the path the suite was read from
+	File string `yaml:"-"`
+}
+
+// Case is one input payload and the assertions on its reply
+type Case struct {
+	Name   string         `yaml:"name"`
+	Input  map[string]any `yaml:"input"`
+	Assert []Assertion    `yaml:"assert"`
+}
+
+// Assertion checks one thing about a reply. Exactly one of Contains,
+// NotContains, JSONPath or Rubric is set.
+type Assertion struct {
+	// Contains passes when the reply text contains the string, ignoring case
+	Contains string `yaml:"contains,omitempty"`
+	// NotContains passes when the reply text does not contain the string
+	NotContains string `yaml:"not_contains,omitempty"`
+	// JSONPath selects a value in the JSON reply (result.items[0].name),
+	// which must equal Equals
+	JSONPath string `yaml:"json_path,omitempty"`
+	Equals   any    `yaml:"equals,omitempty"`
+	// Rubric is graded by Claude from 1 to 5; MinScore (default 4) passes
+	Rubric   string `yaml:"rubric,omitempty"`
+	MinScore int    `yaml:"min_score,omitempty"`
+}
+
+// Kind names the assertion type: contains, not_contains, json_path or rubric
+func (a Assertion) Kind() string {
+	switch {
+	case a.Contains != "":
+		return "contains"
+	case a.NotContains != "":
+		return "not_contains"
+	case a.JSONPath != "":
+		return "json_path"
+	case a.Rubric != "":
+		return "rubric"
+	default:
+		return ""
+	}
+}
+
+// Kinds lists the assertion kinds in the order they are reported
+var Kinds = []string{"contains", "not_contains", "json_path", "rubric"}
+
+// Name returns the suite name: its file name without the extension
+func (s *Suite) Name() string {
+	base := filepath.Base(s.File)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Load reads and validates one suite
+func Load(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Suite
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	s.File = path
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &s, nil
+}
+
+func (s *Suite) validate() error {
+	if (s.Service == "") == (s.Path == "") {
+		return fmt.Errorf("set one of service or path")
+	}
+	if s.Path != "" && !strings.HasPrefix(s.Path, "/") {
+		return fmt.Errorf("path must start with /")
+	}
+	if len(s.Cases) == 0 {
+		return fmt.Errorf("no cases")
+	}
+	seen := map[string]bool{}
+	for i, c := range s.Cases {
+		if c.Name == "" {
+			return fmt.Errorf("case %d: name is required", i+1)
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("case %q: duplicate name", c.Name)
+		}
+		seen[c.Name] = true
+		if len(c.Assert) == 0 {
+			return fmt.Errorf("case %q: no assertions", c.Name)
+		}
+		for j, a := range c.Assert {
+			set := 0
+			for _, v := range []string{a.Contains, a.NotContains, a.JSONPath, a.Rubric} {
+				if v != "" {
+					set++
+				}
+			}
+			if set != 1 {
+				return fmt.Errorf("case %q: assertion %d must set exactly one of contains, not_contains, json_path, rubric", c.Name, j+1)
+			}
+			if a.JSONPath != "" {
+				if _, err := parsePath(a.JSONPath); err != nil {
+					return fmt.Errorf("case %q: %w", c.Name, err)
+				}
+			}
+			if a.MinScore != 0 && (a.Rubric == "" || a.MinScore < 1 || a.MinScore > 5) {
+				return fmt.Errorf("case %q: min_score must be 1-5 and goes with rubric", c.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Find expands paths into suite files: files are kept, directories are
+// searched recursively for .yaml and .yml files. The result is sorted.
+func Find(paths []string) ([]string, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		err = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if ext := filepath.Ext(path); !d.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,155 @@
+package eval
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const suiteYAML = `service: triage
+cases:
+  - name: refund
+    input: {message: "I want my money back", priority: 2}
+    assert:
+      - contains: REFUND
+      - not_contains: as an AI
+      - json_path: result.tags[1]
+        equals: billing
+      - rubric: Apologizes
+        min_score: 3
+`
+
+func writeSuite(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Load(writeSuite(t, dir, "triage.yaml", suiteYAML))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if s.Name() != "triage" || len(s.Cases) != 1 || len(s.Cases[0].Assert) != 4 {
+		t.Fatalf("Load() = %+v", s)
+	}
+	var kinds []string
+	for _, a := range s.Cases[0].Assert {
+		kinds = append(kinds, a.Kind())
+	}
+	if strings.Join(kinds, ",") != "contains,not_contains,json_path,rubric" {
+		t.Errorf("kinds = %v", kinds)
+	}
+
+	bad := map[string]string{
+		"no target":       "cases:\n  - name: a\n    assert: [{contains: x}]\n",
+		"two kinds":       "service: a\ncases:\n  - name: a\n    assert: [{contains: x, rubric: y}]\n",
+		"no assertions":   "service: a\ncases:\n  - name: a\n",
+		"duplicate case":  "service: a\ncases:\n  - name: a\n    assert: [{contains: x}]\n  - name: a\n    assert: [{contains: x}]\n",
+		"bad path":        "service: a\ncases:\n  - name: a\n    assert: [{json_path: \"a..b\", equals: 1}]\n",
+		"min_score range": "service: a\ncases:\n  - name: a\n    assert: [{rubric: x, min_score: 9}]\n",
+	}
+	for name, content := range bad {
+		if _, err := Load(writeSuite(t, dir, "bad.yaml", content)); err == nil {
+			t.Errorf("Load(%s) error = nil", name)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	writeSuite(t, dir, "b.yml", suiteYAML)
+	writeSuite(t, dir, "a.yaml", suiteYAML)
+	writeSuite(t, dir, "notes.md", "")
+	got, err := Find([]string{dir})
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if len(got) != 2 || filepath.Base(got[0]) != "a.yaml" || filepath.Base(got[1]) != "b.yml" {
+		t.Errorf("Find() = %v", got)
+	}
+}
+
+func TestParseReply(t *testing.T) {
+	tests := []struct {
+		name, contentType, body string
+		text, err               string
+		json                    bool
+	}{
+		{"api result", "application/json", `{"status":"completed","result":"Refund issued"}`, "Refund issued", "", true},
+		{"output schema", "application/json", `{"result":{"priority":"high"}}`, `{"result":{"priority":"high"}}`, "", true},
+		{"sse text", "text/event-stream", "id: s:1\ndata: Hello\n\nid: s:2\ndata:  world\ndata: again\n\nid: s:3\nevent: done\ndata: [DONE]\n\n", "Hello world\nagain", "", false},
+		{"sse json", "text/event-stream; charset=utf-8", "id: s:1\nevent: text\ndata: {\"type\":\"text\",\"text\":\"{\\\"a\\\": 1}\"}\n\nid: s:2\nevent: tool_use\ndata: {\"type\":\"tool_use\",\"name\":\"x\"}\n\n", `{"a": 1}`, "", true},
+		{"sse error", "text/event-stream", "id: s:1\ndata: Hi\n\nid: s:2\nevent: error\ndata: boom\n\n", "Hi", "boom", false},
+		{"ndjson", "application/x-ndjson", "{\"id\":\"s:1\",\"type\":\"text\",\"text\":\"Hi\"}\n{\"id\":\"s:2\",\"type\":\"done\"}\n", "Hi", "", false},
+	}
+	for _, tt := range tests {
+		r := ParseReply(200, tt.contentType, tt.body)
+		if r.Text != tt.text || r.Error != tt.err || (r.JSON != nil) != tt.json {
+			t.Errorf("%s: ParseReply() = %+v", tt.name, r)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	r := ParseReply(200, "application/json", `{"result":{"tags":["urgent","billing"],"items":[{"n":3}]}}`)
+	for path, want := range map[string]any{"result.tags[1]": "billing", "result.items.0.n": float64(3)} {
+		got, err := Lookup(r.JSON, path)
+		if err != nil || got != want {
+			t.Errorf("Lookup(%s) = %v, %v, want %v", path, got, err, want)
+		}
+	}
+	for _, path := range []string{"result.missing", "result.tags[5]", "result.tags[0].x"} {
+		if _, err := Lookup(r.JSON, path); err == nil {
+			t.Errorf("Lookup(%s) error = nil", path)
+		}
+	}
+}
+
+type fakeGrader struct{ answer string }
+
+func (g fakeGrader) Ask(ctx context.Context, system, user string, maxTokens int) (string, error) {
+	return g.answer, nil
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Load(writeSuite(t, dir, "triage.yaml", suiteYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(ctx context.Context, input map[string]any) (Reply, error) {
+		if input["priority"] != 2 {
+			t.Errorf("input = %v", input)
+		}
+		return ParseReply(200, "application/json", `{"result":{"answer":"Sorry, your refund is on its way","tags":["urgent","billing"]}}`), nil
+	}
+	results := s.Run(context.Background(), send, fakeGrader{"Here you go: {\"score\": 3, \"reason\": \"apologizes\"}"}, 2)
+	if len(results) != 2 {
+		t.Fatalf("Run() returned %d results, want 2", len(results))
+	}
+	for _, r := range results {
+		if r.Err != nil || r.Passed() != 4 {
+			t.Errorf("Run() = %+v, want all 4 passing", r)
+		}
+	}
+
+	results = s.Run(context.Background(), send, fakeGrader{`{"score": 2, "reason": "curt"}`}, 1)
+	if got := results[0].Checks[3]; got.Passed || !strings.Contains(got.Detail, "scored 2/5") {
+		t.Errorf("low rubric grade = %+v", got)
+	}
+
+	failing := func(ctx context.Context, input map[string]any) (Reply, error) {
+		return ParseReply(500, "application/json", `{"detail":"boom"}`), nil
+	}
+	results = s.Run(context.Background(), failing, nil, 1)
+	if results[0].Err == nil || results[0].Passed() != 0 || len(results[0].Checks) != 4 {
+		t.Errorf("Run() on HTTP 500 = %+v", results[0])
+	}
+}
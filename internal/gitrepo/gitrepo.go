@@ -0,0 +1,90 @@
+// Package gitrepo puts a new project under git, making sure secrets such as
+// .env are ignored before anything is committed.
+package gitrepo
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/output"
+)
+
+// ErrNoGit is returned when the git binary is not on PATH
+var ErrNoGit = errors.New("git is not installed")
+
+// Inside reports whether dir is already inside a git work tree, including
+// one of its parents'
+func Inside(dir string) bool {
+	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
+// Init runs git init in dir, stages everything that is not ignored and makes
+// the first commit. It stops before staging when an env file other than
+// .env.example would be committed.
+func Init(dir, message string) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return ErrNoGit
+	}
+	if _, err := git(dir, "init", "--quiet"); err != nil {
+		return err
+	}
+	exposed, err := UnignoredEnvFiles(dir)
+	if err != nil {
+		return err
+	}
+	if len(exposed) > 0 {
+		return fmt.Errorf("not committing: %s would be committed; add it to .gitignore and commit by hand", strings.Join(exposed, ", "))
+	}
+	if _, err := git(dir, "add", "--all"); err != nil {
+		return err
+	}
+	if _, err := git(dir, "commit", "--quiet", "-m", message); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UnignoredEnvFiles lists the env files in dir (.env, .env.production...)
+// that git would commit. .env.example holds no secrets and is not listed.
+func UnignoredEnvFiles(dir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, ".env*"))
+	if err != nil {
+		return nil, err
+	}
+	var exposed []string
+	for _, path := range matches {
+		name := filepath.Base(path)
+		if name == ".env.example" {
+			continue
+		}
+		// check-ignore exits 1 when the path is not ignored
+		cmd := exec.Command("git", "check-ignore", "--quiet", name)
+		cmd.Dir = dir
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		switch {
+		case err == nil:
+		case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+			exposed = append(exposed, name)
+		default:
+			return nil, fmt.Errorf("git check-ignore %s: %w", name, err)
+		}
+	}
+	return exposed, nil
+}
+
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := output.CombinedOutput(cmd)
+	if err != nil {
+		return out, fmt.Errorf("git %s failed: %w\n%s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
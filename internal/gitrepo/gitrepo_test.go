@@ -0,0 +1,53 @@
+package gitrepo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(key, "datagen test")
+	}
+	for _, key := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(key, "test@example.com")
+	}
+
+	dir := t.TempDir()
+	write := func(name, body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("datagen.toml", "datagen_api_key_env = \"DATAGEN_API_KEY\"\n")
+	write(".env", "ANTHROPIC_API_KEY=sk-secret\n")
+	write(".env.example", "ANTHROPIC_API_KEY=\n")
+
+	err := Init(dir, "Initial datagen project")
+	if err == nil || !strings.Contains(err.Error(), ".env would be committed") {
+		t.Fatalf("Init() without .gitignore error = %v, want .env refusal", err)
+	}
+
+	write(".gitignore", ".env\n")
+	if err := Init(dir, "Initial datagen project"); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if !Inside(dir) {
+		t.Fatalf("Inside() = false after Init")
+	}
+	out, err := exec.Command("git", "-C", dir, "ls-files").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := strings.Fields(string(out))
+	if strings.Join(files, " ") != ".env.example .gitignore datagen.toml" {
+		t.Fatalf("committed %v, want .env.example .gitignore datagen.toml", files)
+	}
+}
@@ -0,0 +1,187 @@
+package history
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	created_at    TEXT    NOT NULL,
+	service       TEXT    NOT NULL,
+	url           TEXT    NOT NULL,
+	payload       TEXT    NOT NULL,
+	output        TEXT    NOT NULL,
+	status_code   INTEGER NOT NULL,
+	duration_ms   INTEGER NOT NULL,
+	input_tokens  INTEGER,
+	output_tokens INTEGER,
+	error         TEXT    NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS runs_service_idx ON runs(service);
+`
+
+// Run is one recorded local agent invocation
+type Run struct {
+	ID           int64
+	CreatedAt    time.Time
+	Service      string
+	URL          string
+	Payload      string
+	Output       string
+	StatusCode   int
+	Duration     time.Duration
+	InputTokens  *int64
+	OutputTokens *int64
+	Error        string
+}
+
+// ListOptions filters List results
+type ListOptions struct {
+	Service string
+	Search  string // substring match on payload and output
+	Limit   int
+}
+
+// Store is a SQLite-backed run history
+type Store struct {
+	db *sql.DB
+}
+
+// DefaultPath returns ~/.datagen/history.db
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".datagen", "history.db"), nil
+}
+
+// Open opens (creating if needed) the history database at path
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize history database: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Record stores run and returns its ID
+func (s *Store) Record(run *Run) (int64, error) {
+	if run.CreatedAt.IsZero() {
+		run.CreatedAt = time.Now()
+	}
+	res, err := s.db.Exec(
+		`INSERT INTO runs (created_at, service, url, payload, output, status_code, duration_ms, input_tokens, output_tokens, error)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.CreatedAt.UTC().Format(time.RFC3339Nano), run.Service, run.URL, run.Payload, run.Output,
+		run.StatusCode, run.Duration.Milliseconds(), run.InputTokens, run.OutputTokens, run.Error,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record run: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	run.ID = id
+	return id, nil
+}
+
+// Get returns the run with the given ID
+func (s *Store) Get(id int64) (*Run, error) {
+	row := s.db.QueryRow(`SELECT `+runColumns+` FROM runs WHERE id = ?`, id)
+	run, err := scanRun(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("run %d not found", id)
+	}
+	return run, err
+}
+
+// List returns the most recent runs matching opts, newest first
+func (s *Store) List(opts ListOptions) ([]*Run, error) {
+	var where []string
+	var args []any
+	if opts.Service != "" {
+		where = append(where, "service = ?")
+		args = append(args, opts.Service)
+	}
+	if opts.Search != "" {
+		where = append(where, "(payload LIKE ? OR output LIKE ?)")
+		pattern := "%" + opts.Search + "%"
+		args = append(args, pattern, pattern)
+	}
+
+	query := `SELECT ` + runColumns + ` FROM runs`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if opts.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, opts.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*Run
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+const runColumns = `id, created_at, service, url, payload, output, status_code, duration_ms, input_tokens, output_tokens, error`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRun(row scanner) (*Run, error) {
+	var run Run
+	var createdAt string
+	var durationMS int64
+	var inputTokens, outputTokens sql.NullInt64
+	if err := row.Scan(&run.ID, &createdAt, &run.Service, &run.URL, &run.Payload, &run.Output,
+		&run.StatusCode, &durationMS, &inputTokens, &outputTokens, &run.Error); err != nil {
+		return nil, err
+	}
+
+	run.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	run.Duration = time.Duration(durationMS) * time.Millisecond
+	if inputTokens.Valid {
+		run.InputTokens = &inputTokens.Int64
+	}
+	if outputTokens.Valid {
+		run.OutputTokens = &outputTokens.Int64
+	}
+	return &run, nil
+}
@@ -0,0 +1,55 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoreRecordListGet(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer store.Close()
+
+	tokens := int64(42)
+	runs := []*Run{
+		{Service: "chat", URL: "http://localhost:8000/api/chat", Payload: `{"message":"hi"}`, Output: `{"result":"hello"}`, StatusCode: 200, Duration: 1500 * time.Millisecond, OutputTokens: &tokens},
+		{Service: "enrich", URL: "http://localhost:8000/api/enrich", Payload: `{"email":"a@b.co"}`, Output: `{"result":"acme"}`, StatusCode: 200, Duration: time.Second},
+		{Service: "chat", URL: "http://localhost:8000/api/chat", Payload: `{"message":"bye"}`, Output: "", StatusCode: 500, Error: "Agent execution failed"},
+	}
+	for _, run := range runs {
+		if _, err := store.Record(run); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	chatRuns, err := store.List(ListOptions{Service: "chat"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(chatRuns) != 2 || chatRuns[0].ID != runs[2].ID {
+		t.Fatalf("List(service=chat) = %d runs (first %d), want 2 newest first", len(chatRuns), chatRuns[0].ID)
+	}
+
+	found, err := store.List(ListOptions{Search: "acme"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(found) != 1 || found[0].Service != "enrich" {
+		t.Fatalf("List(search=acme) = %+v, want the enrich run", found)
+	}
+
+	got, err := store.Get(runs[0].ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Duration != 1500*time.Millisecond || got.OutputTokens == nil || *got.OutputTokens != 42 || got.InputTokens != nil {
+		t.Fatalf("Get() = %+v, want duration 1.5s and 42 output tokens", got)
+	}
+
+	if _, err := store.Get(999); err == nil {
+		t.Fatalf("Get(999) error = nil, want not found")
+	}
+}
@@ -0,0 +1,282 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Target is the deployed endpoint a provider should deliver events to
+type Target struct {
+	URL    string
+	Secret string
+	Events []string
+}
+
+// Result describes a created subscription
+type Result struct {
+	ID string
+	// Secret is set when the provider generated the signing secret
+	Secret string
+	// Note is extra follow-up information for the user
+	Note string
+}
+
+// Provider creates webhook subscriptions on a third-party service
+type Provider interface {
+	Name() string
+	Register(ctx context.Context, target Target) (*Result, error)
+}
+
+// Options carries provider-specific settings from flags or the environment
+type Options struct {
+	// Repo is the GitHub owner/name (default $GITHUB_REPOSITORY)
+	Repo string
+	// AppID is the Slack app ID (default $SLACK_APP_ID)
+	AppID string
+}
+
+// Names lists the supported providers
+func Names() []string {
+	return []string{"github", "slack", "stripe"}
+}
+
+// New returns the provider called name, reading its token from the environment
+func New(name string, opts Options) (Provider, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	switch name {
+	case "github":
+		repo := firstNonEmpty(opts.Repo, os.Getenv("GITHUB_REPOSITORY"))
+		if repo == "" {
+			return nil, fmt.Errorf("github needs a repository: pass --repo owner/name or set GITHUB_REPOSITORY")
+		}
+		token, err := requireEnv("GITHUB_TOKEN")
+		if err != nil {
+			return nil, err
+		}
+		return &GitHub{BaseURL: "https://api.github.com", Token: token, Repo: repo, HTTPClient: client}, nil
+	case "stripe":
+		token, err := requireEnv("STRIPE_API_KEY")
+		if err != nil {
+			return nil, err
+		}
+		return &Stripe{BaseURL: "https://api.stripe.com", Token: token, HTTPClient: client}, nil
+	case "slack":
+		appID := firstNonEmpty(opts.AppID, os.Getenv("SLACK_APP_ID"))
+		if appID == "" {
+			return nil, fmt.Errorf("slack needs an app ID: pass --app-id or set SLACK_APP_ID")
+		}
+		token, err := requireEnv("SLACK_CONFIG_TOKEN")
+		if err != nil {
+			return nil, err
+		}
+		return &Slack{BaseURL: "https://slack.com/api", Token: token, AppID: appID, HTTPClient: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q (supported: %s)", name, strings.Join(Names(), ", "))
+	}
+}
+
+func requireEnv(key string) (string, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return "", fmt.Errorf("%s is not set", key)
+	}
+	return v, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// doJSON sends req and decodes a JSON response into out, treating non-2xx as errors
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return nil
+}
+
+// GitHub creates a repository webhook
+type GitHub struct {
+	BaseURL    string
+	Token      string
+	Repo       string
+	HTTPClient *http.Client
+}
+
+func (g *GitHub) Name() string { return "github" }
+
+func (g *GitHub) Register(ctx context.Context, target Target) (*Result, error) {
+	events := target.Events
+	if len(events) == 0 {
+		events = []string{"push"}
+	}
+	hookConfig := map[string]any{"url": target.URL, "content_type": "json"}
+	if target.Secret != "" {
+		hookConfig["secret"] = target.Secret
+	}
+	body, err := json.Marshal(map[string]any{
+		"name":   "web",
+		"active": true,
+		"events": events,
+		"config": hookConfig,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s/repos/%s/hooks", g.BaseURL, g.Repo), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+g.Token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	if err := doJSON(g.HTTPClient, req, &created); err != nil {
+		return nil, fmt.Errorf("github: %w", err)
+	}
+	return &Result{ID: fmt.Sprint(created.ID)}, nil
+}
+
+// Stripe creates a webhook endpoint; Stripe generates the signing secret
+type Stripe struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+func (s *Stripe) Name() string { return "stripe" }
+
+func (s *Stripe) Register(ctx context.Context, target Target) (*Result, error) {
+	events := target.Events
+	if len(events) == 0 {
+		events = []string{"*"}
+	}
+	form := url.Values{}
+	form.Set("url", target.URL)
+	for _, e := range events {
+		form.Add("enabled_events[]", e)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.BaseURL+"/v1/webhook_endpoints",
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(s.Token, "")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var created struct {
+		ID     string `json:"id"`
+		Secret string `json:"secret"`
+	}
+	if err := doJSON(s.HTTPClient, req, &created); err != nil {
+		return nil, fmt.Errorf("stripe: %w", err)
+	}
+	return &Result{ID: created.ID, Secret: created.Secret}, nil
+}
+
+// Slack points an app's Events API request URL at the target by updating its
+// manifest. Slack sends a url_verification challenge to the new URL.
+type Slack struct {
+	BaseURL    string
+	Token      string
+	AppID      string
+	HTTPClient *http.Client
+}
+
+func (s *Slack) Name() string { return "slack" }
+
+type slackResponse struct {
+	OK       bool           `json:"ok"`
+	Error    string         `json:"error"`
+	Manifest map[string]any `json:"manifest"`
+}
+
+func (s *Slack) call(ctx context.Context, method string, payload map[string]any) (*slackResponse, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.BaseURL+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.Token)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	var resp slackResponse
+	if err := doJSON(s.HTTPClient, req, &resp); err != nil {
+		return nil, fmt.Errorf("slack %s: %w", method, err)
+	}
+	if !resp.OK {
+		return nil, fmt.Errorf("slack %s: %s", method, resp.Error)
+	}
+	return &resp, nil
+}
+
+func (s *Slack) Register(ctx context.Context, target Target) (*Result, error) {
+	exported, err := s.call(ctx, "apps.manifest.export", map[string]any{"app_id": s.AppID})
+	if err != nil {
+		return nil, err
+	}
+	manifest := exported.Manifest
+	if manifest == nil {
+		manifest = map[string]any{}
+	}
+
+	settings, _ := manifest["settings"].(map[string]any)
+	if settings == nil {
+		settings = map[string]any{}
+		manifest["settings"] = settings
+	}
+	subscriptions, _ := settings["event_subscriptions"].(map[string]any)
+	if subscriptions == nil {
+		subscriptions = map[string]any{}
+		settings["event_subscriptions"] = subscriptions
+	}
+	subscriptions["request_url"] = target.URL
+	if len(target.Events) > 0 {
+		subscriptions["bot_events"] = target.Events
+	}
+
+	if _, err := s.call(ctx, "apps.manifest.update", map[string]any{"app_id": s.AppID, "manifest": manifest}); err != nil {
+		return nil, err
+	}
+	return &Result{
+		ID:   s.AppID,
+		Note: "Slack verifies the request URL with a url_verification challenge; the endpoint must echo it",
+	}, nil
+}
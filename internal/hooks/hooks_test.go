@@ -0,0 +1,138 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGitHubRegister(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/app/hooks" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer gh-token" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 42}`))
+	}))
+	defer srv.Close()
+
+	g := &GitHub{BaseURL: srv.URL, Token: "gh-token", Repo: "acme/app", HTTPClient: srv.Client()}
+	res, err := g.Register(context.Background(), Target{URL: "https://x.up.railway.app/webhook/gh", Secret: "s3"})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if res.ID != "42" {
+		t.Fatalf("ID = %q, want 42", res.ID)
+	}
+	cfg, _ := got["config"].(map[string]any)
+	if cfg["url"] != "https://x.up.railway.app/webhook/gh" || cfg["secret"] != "s3" {
+		t.Fatalf("config = %v", cfg)
+	}
+}
+
+func TestStripeRegisterReturnsSecret(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("url") != "https://x/webhook/stripe" || r.PostForm["enabled_events[]"][0] != "invoice.paid" {
+			t.Errorf("form = %v", r.PostForm)
+		}
+		w.Write([]byte(`{"id": "we_1", "secret": "whsec_abc"}`))
+	}))
+	defer srv.Close()
+
+	s := &Stripe{BaseURL: srv.URL, Token: "sk_test", HTTPClient: srv.Client()}
+	res, err := s.Register(context.Background(), Target{URL: "https://x/webhook/stripe", Events: []string{"invoice.paid"}})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if res.ID != "we_1" || res.Secret != "whsec_abc" {
+		t.Fatalf("Register() = %+v", res)
+	}
+}
+
+func TestSlackRegisterUpdatesManifest(t *testing.T) {
+	var updated map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apps.manifest.export":
+			w.Write([]byte(`{"ok": true, "manifest": {"display_information": {"name": "bot"}}}`))
+		case "/apps.manifest.update":
+			json.NewDecoder(r.Body).Decode(&updated)
+			w.Write([]byte(`{"ok": true}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	s := &Slack{BaseURL: srv.URL, Token: "xoxe", AppID: "A1", HTTPClient: srv.Client()}
+	if _, err := s.Register(context.Background(), Target{URL: "https://x/webhook/slack"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	manifest := updated["manifest"].(map[string]any)
+	subs := manifest["settings"].(map[string]any)["event_subscriptions"].(map[string]any)
+	if subs["request_url"] != "https://x/webhook/slack" {
+		t.Fatalf("request_url = %v", subs["request_url"])
+	}
+}
+
+func TestNewRequiresToken(t *testing.T) {
+	t.Setenv("STRIPE_API_KEY", "")
+	if _, err := New("stripe", Options{}); err == nil {
+		t.Fatal("New(stripe) without STRIPE_API_KEY should fail")
+	}
+	if _, err := New("pagerduty", Options{}); err == nil {
+		t.Fatal("New(pagerduty) should fail")
+	}
+}
+
+func TestFixture(t *testing.T) {
+	provider, body, err := Fixture("stripe:invoice.paid")
+	if err != nil {
+		t.Fatalf("Fixture() error = %v", err)
+	}
+	if provider != "stripe" || !json.Valid(body) {
+		t.Fatalf("Fixture() = %q, valid JSON %v", provider, json.Valid(body))
+	}
+	if _, _, err := Fixture("stripe:nope"); err == nil {
+		t.Fatal("Fixture(stripe:nope) should fail")
+	}
+	for _, name := range Fixtures() {
+		if _, body, err := Fixture(name); err != nil || !json.Valid(body) {
+			t.Fatalf("fixture %s is not valid JSON: %v", name, err)
+		}
+	}
+}
+
+func TestSignatureHeaders(t *testing.T) {
+	now := time.Unix(1730000000, 0)
+	body := []byte(`{"a":1}`)
+
+	tests := []struct {
+		provider string
+		header   string
+		want     string
+	}{
+		{"", "X-Sig", hmacHex("s", body)},
+		{"github", "X-Hub-Signature-256", "sha256=" + hmacHex("s", body)},
+		{"stripe", "Stripe-Signature", "t=1730000000,v1=" + hmacHex("s", []byte("1730000000."+string(body)))},
+		{"slack", "X-Slack-Signature", "v0=" + hmacHex("s", []byte("v0:1730000000:"+string(body)))},
+	}
+	for _, tt := range tests {
+		h := SignatureHeaders(tt.provider, "push", "X-Sig", "s", body, now)
+		if got := h.Get(tt.header); got != tt.want {
+			t.Errorf("%s: %s = %q, want %q", tt.provider, tt.header, got, tt.want)
+		}
+		if got := h.Get("X-Sig"); got != tt.want {
+			t.Errorf("%s: configured header = %q, want %q", tt.provider, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Package keycheck verifies API keys against their providers before they are
+// pushed to a deployment, so a typo surfaces locally instead of as a 500 from
+// the booted service.
+package keycheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/mcpconfig"
+)
+
+const (
+	// DefaultAnthropicURL lists models, which any valid key may call
+	DefaultAnthropicURL = "https://api.anthropic.com/v1/models"
+
+	// DefaultTimeout bounds a single provider check
+	DefaultTimeout = 10 * time.Second
+)
+
+// Kind identifies which provider a key belongs to
+type Kind string
+
+const (
+	Anthropic Kind = "anthropic"
+	Datagen   Kind = "datagen"
+)
+
+// Result is the outcome of checking one variable
+type Result struct {
+	Key  string
+	Kind Kind
+	// Err is set when the key could not be confirmed valid
+	Err error
+	// Rejected is true when the provider answered and refused the key, as
+	// opposed to the check failing on the network
+	Rejected bool
+}
+
+// Checker validates keys over HTTP
+type Checker struct {
+	HTTPClient    *http.Client
+	AnthropicURL  string
+	DatagenMCPURL string
+}
+
+// New returns a Checker for the production endpoints
+func New() *Checker {
+	return &Checker{
+		HTTPClient:    &http.Client{Timeout: DefaultTimeout},
+		AnthropicURL:  DefaultAnthropicURL,
+		DatagenMCPURL: mcpconfig.DatagenMCPURL,
+	}
+}
+
+// Check validates the variables named in kinds that are present in vars.
+// Results are returned in the order of names.
+func (c *Checker) Check(ctx context.Context, vars map[string]string, names []string, kinds map[string]Kind) []Result {
+	var results []Result
+	for _, name := range names {
+		kind, ok := kinds[name]
+		if !ok {
+			continue
+		}
+		value := strings.TrimSpace(vars[name])
+		if value == "" {
+			continue
+		}
+		res := Result{Key: name, Kind: kind}
+		switch kind {
+		case Anthropic:
+			res.Rejected, res.Err = c.checkAnthropic(ctx, value)
+		case Datagen:
+			res.Rejected, res.Err = c.checkDatagen(ctx, value)
+		default:
+			continue
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+func (c *Checker) checkAnthropic(ctx context.Context, key string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.AnthropicURL+"?limit=1", nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("x-api-key", key)
+	req.Header.Set("anthropic-version", "2023-06-01")
+	return c.do(req, "Anthropic")
+}
+
+// checkDatagen performs an MCP initialize handshake with the key
+func (c *Checker) checkDatagen(ctx context.Context, key string) (bool, error) {
+	body := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"datagen-cli","version":"keycheck"}}}`
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.DatagenMCPURL, strings.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+key)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	return c.do(req, "DataGen MCP")
+}
+
+func (c *Checker) do(req *http.Request, provider string) (bool, error) {
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("could not reach %s: %w", provider, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return true, fmt.Errorf("%s rejected the key (%d)", provider, resp.StatusCode)
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	default:
+		return false, fmt.Errorf("%s returned %d", provider, resp.StatusCode)
+	}
+}
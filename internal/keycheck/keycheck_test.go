@@ -0,0 +1,93 @@
+package keycheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/models":
+			if r.Header.Get("anthropic-version") == "" {
+				t.Fatalf("anthropic-version header missing")
+			}
+			if r.Header.Get("x-api-key") != "sk-ant-good" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":[]}`))
+		case "/mcp":
+			if r.Method != http.MethodPost {
+				t.Fatalf("MCP method = %s, want POST", r.Method)
+			}
+			if r.Header.Get("Authorization") != "Bearer dg-good" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+		case "/down/mcp":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	kinds := map[string]Kind{"ANTHROPIC_API_KEY": Anthropic, "DATAGEN_API_KEY": Datagen}
+	names := []string{"ANTHROPIC_API_KEY", "DATAGEN_API_KEY", "LOG_LEVEL"}
+
+	tests := []struct {
+		name         string
+		mcpPath      string
+		vars         map[string]string
+		wantRejected map[string]bool
+		wantErr      map[string]bool
+	}{
+		{
+			name:    "valid keys",
+			mcpPath: "/mcp",
+			vars:    map[string]string{"ANTHROPIC_API_KEY": "sk-ant-good", "DATAGEN_API_KEY": "dg-good", "LOG_LEVEL": "INFO"},
+		},
+		{
+			name:         "typoed keys",
+			mcpPath:      "/mcp",
+			vars:         map[string]string{"ANTHROPIC_API_KEY": "sk-ant-goood", "DATAGEN_API_KEY": "dg-goo"},
+			wantRejected: map[string]bool{"ANTHROPIC_API_KEY": true, "DATAGEN_API_KEY": true},
+			wantErr:      map[string]bool{"ANTHROPIC_API_KEY": true, "DATAGEN_API_KEY": true},
+		},
+		{
+			name:    "provider unavailable",
+			mcpPath: "/down/mcp",
+			vars:    map[string]string{"DATAGEN_API_KEY": "dg-good"},
+			wantErr: map[string]bool{"DATAGEN_API_KEY": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Checker{
+				HTTPClient:    server.Client(),
+				AnthropicURL:  server.URL + "/v1/models",
+				DatagenMCPURL: server.URL + tt.mcpPath,
+			}
+			results := c.Check(context.Background(), tt.vars, names, kinds)
+			present := 0
+			for _, name := range names {
+				if _, ok := kinds[name]; ok && tt.vars[name] != "" {
+					present++
+				}
+			}
+			if len(results) != present {
+				t.Fatalf("Check() returned %d results, want %d", len(results), present)
+			}
+			for _, r := range results {
+				if r.Rejected != tt.wantRejected[r.Key] {
+					t.Fatalf("%s Rejected = %v, want %v (err %v)", r.Key, r.Rejected, tt.wantRejected[r.Key], r.Err)
+				}
+				if (r.Err != nil) != tt.wantErr[r.Key] {
+					t.Fatalf("%s Err = %v, want error %v", r.Key, r.Err, tt.wantErr[r.Key])
+				}
+			}
+		})
+	}
+}
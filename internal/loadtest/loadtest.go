@@ -0,0 +1,316 @@
+// Package loadtest drives synthetic requests at a service at a fixed rate and
+// summarizes latency, errors and estimated model cost.
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/models"
+)
+
+// ErrSaturated marks a request that was not sent because MaxInFlight requests
+// were already waiting for a reply
+var ErrSaturated = errors.New("too many requests in flight")
+
+// Sample is the outcome of one request
+type Sample struct {
+	Latency   time.Duration
+	FirstByte time.Duration
+	Status    int
+	Err       error
+
+	RequestBytes  int
+	ResponseBytes int
+}
+
+// Failed reports whether the request errored or got a non-2xx reply
+func (s Sample) Failed() bool {
+	return s.Err != nil || s.Status < 200 || s.Status > 299
+}
+
+// Sender sends request number i
+type Sender func(ctx context.Context, i int) Sample
+
+// Options controls a run
+type Options struct {
+	// RPS is how many requests start per second, whatever the replies do
+	RPS      float64
+	Duration time.Duration
+	// MaxInFlight caps concurrent requests; further requests are recorded
+	// as ErrSaturated instead of being sent
+	MaxInFlight int
+	// Progress, when set, is called about every ProgressEvery with the
+	// counts so far
+	Progress      func(Progress)
+	ProgressEvery time.Duration
+}
+
+// Progress is a snapshot of a running test
+type Progress struct {
+	Elapsed  time.Duration
+	Sent     int
+	Done     int
+	InFlight int
+	Failed   int
+}
+
+// Run starts requests at opts.RPS for opts.Duration, waits for the last
+// replies and returns every sample with the wall time the run took
+func Run(ctx context.Context, opts Options, send Sender) ([]Sample, time.Duration) {
+	interval := time.Duration(float64(time.Second) / opts.RPS)
+	total := int(opts.Duration / interval)
+	if total < 1 {
+		total = 1
+	}
+	samples := make([]Sample, total)
+
+	var (
+		wg       sync.WaitGroup
+		inFlight atomic.Int64
+		done     atomic.Int64
+		failed   atomic.Int64
+	)
+	start := time.Now()
+	stopProgress := make(chan struct{})
+	if opts.Progress != nil && opts.ProgressEvery > 0 {
+		ticker := time.NewTicker(opts.ProgressEvery)
+		go func() {
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					d := int(done.Load())
+					opts.Progress(Progress{
+						Elapsed:  time.Since(start),
+						Sent:     d + int(inFlight.Load()),
+						Done:     d,
+						InFlight: int(inFlight.Load()),
+						Failed:   int(failed.Load()),
+					})
+				case <-stopProgress:
+					return
+				}
+			}
+		}()
+	}
+
+	sent := 0
+	for i := 0; i < total; i++ {
+		if wait := time.Until(start.Add(time.Duration(i) * interval)); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		sent++
+		if opts.MaxInFlight > 0 && inFlight.Load() >= int64(opts.MaxInFlight) {
+			samples[i] = Sample{Err: ErrSaturated}
+			done.Add(1)
+			failed.Add(1)
+			continue
+		}
+		inFlight.Add(1)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			samples[i] = send(ctx, i)
+			inFlight.Add(-1)
+			done.Add(1)
+			if samples[i].Failed() {
+				failed.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(stopProgress)
+	return samples[:sent], time.Since(start)
+}
+
+// Percentiles summarizes a latency distribution
+type Percentiles struct {
+	P50, P90, P95, P99, Max time.Duration
+}
+
+// percentiles uses the nearest-rank method
+func percentiles(d []time.Duration) Percentiles {
+	if len(d) == 0 {
+		return Percentiles{}
+	}
+	sort.Slice(d, func(i, j int) bool { return d[i] < d[j] })
+	rank := func(p float64) time.Duration {
+		i := int(p*float64(len(d))+0.999999) - 1
+		return d[max(0, min(i, len(d)-1))]
+	}
+	return Percentiles{P50: rank(0.50), P90: rank(0.90), P95: rank(0.95), P99: rank(0.99), Max: d[len(d)-1]}
+}
+
+// Report summarizes a run
+type Report struct {
+	Requests  int
+	Succeeded int
+	Failed    int
+	// Errors counts failures by kind: "HTTP 503", "timeout", ...
+	Errors  map[string]int
+	Elapsed time.Duration
+	// Throughput is successful replies per second
+	Throughput float64
+	// Latency and FirstByte cover successful requests only
+	Latency   Percentiles
+	FirstByte Percentiles
+
+	AvgRequestBytes  float64
+	AvgResponseBytes float64
+}
+
+// ErrorRate is the share of requests that failed
+func (r Report) ErrorRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Failed) / float64(r.Requests)
+}
+
+// Summarize builds the report for a run's samples
+func Summarize(samples []Sample, elapsed time.Duration) Report {
+	r := Report{Requests: len(samples), Errors: map[string]int{}, Elapsed: elapsed}
+	var latencies, firstBytes []time.Duration
+	var reqBytes, respBytes int
+	for _, s := range samples {
+		if s.Failed() {
+			r.Failed++
+			r.Errors[errorKind(s)]++
+			continue
+		}
+		r.Succeeded++
+		latencies = append(latencies, s.Latency)
+		firstBytes = append(firstBytes, s.FirstByte)
+		reqBytes += s.RequestBytes
+		respBytes += s.ResponseBytes
+	}
+	r.Latency = percentiles(latencies)
+	r.FirstByte = percentiles(firstBytes)
+	if r.Succeeded > 0 {
+		r.AvgRequestBytes = float64(reqBytes) / float64(r.Succeeded)
+		r.AvgResponseBytes = float64(respBytes) / float64(r.Succeeded)
+	}
+	if elapsed > 0 {
+		r.Throughput = float64(r.Succeeded) / elapsed.Seconds()
+	}
+	return r
+}
+
+func errorKind(s Sample) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(s.Err, ErrSaturated):
+		return "not sent (max in flight)"
+	case errors.Is(s.Err, context.Canceled):
+		return "cancelled"
+	case errors.Is(s.Err, context.DeadlineExceeded), errors.As(s.Err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case s.Err != nil:
+		return "connection error"
+	default:
+		return fmt.Sprintf("HTTP %d", s.Status)
+	}
+}
+
+// bytesPerToken is a rough average for English text and JSON
+const bytesPerToken = 4
+
+// CostEstimate is a rough model cost from request and reply sizes
+type CostEstimate struct {
+	Model        string
+	InputTokens  float64
+	OutputTokens float64
+	// PerRequest is in US dollars; zero when the model has no known price
+	PerRequest float64
+	Priced     bool
+}
+
+// EstimateCost prices one model call per request: the system prompt and
+// payload in, the reply out. Agents that call tools make more calls, so this
+// is a lower bound.
+func EstimateCost(r Report, model string, promptBytes int) CostEstimate {
+	c := CostEstimate{
+		Model:        model,
+		InputTokens:  (float64(promptBytes) + r.AvgRequestBytes) / bytesPerToken,
+		OutputTokens: r.AvgResponseBytes / bytesPerToken,
+	}
+	if price, ok := models.Pricing(model); ok {
+		c.PerRequest = price.Cost(c.InputTokens, c.OutputTokens)
+		c.Priced = true
+	}
+	return c
+}
+
+// sampleText varies string inputs so replies aren't served from a cache
+var sampleText = []string{
+	"Summarize the main points of our last quarterly report.",
+	"What is the status of order 48213?",
+	"Draft a short reply thanking the customer for their feedback.",
+	"List three risks in the attached project plan.",
+	"Translate 'the shipment is delayed' into French.",
+}
+
+// Payload builds a synthetic request body from a service's input schema.
+// Fields with a default use it; others get a placeholder of their type that
+// varies with i.
+func Payload(fields []config.Field, i int) map[string]any {
+	payload := map[string]any{}
+	for _, f := range fields {
+		if f.Default != "" {
+			if v, ok := parseDefault(f); ok {
+				payload[f.Name] = v
+				continue
+			}
+		}
+		switch f.Type {
+		case "int":
+			payload[f.Name] = i + 1
+		case "float":
+			payload[f.Name] = float64(i%100) + 0.5
+		case "bool":
+			payload[f.Name] = i%2 == 0
+		case "list":
+			payload[f.Name] = []any{sampleText[i%len(sampleText)]}
+		case "dict":
+			payload[f.Name] = map[string]any{"id": i + 1}
+		default:
+			payload[f.Name] = sampleText[i%len(sampleText)]
+		}
+	}
+	return payload
+}
+
+func parseDefault(f config.Field) (any, bool) {
+	d := strings.Trim(f.Default, `"'`)
+	switch f.Type {
+	case "int":
+		n, err := strconv.Atoi(d)
+		return n, err == nil
+	case "float":
+		n, err := strconv.ParseFloat(d, 64)
+		return n, err == nil
+	case "bool":
+		b, err := strconv.ParseBool(strings.ToLower(d))
+		return b, err == nil
+	case "list", "dict":
+		return nil, false
+	default:
+		return d, true
+	}
+}
@@ -0,0 +1,106 @@
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+func TestRun(t *testing.T) {
+	var calls atomic.Int64
+	samples, elapsed := Run(context.Background(), Options{RPS: 100, Duration: 200 * time.Millisecond}, func(ctx context.Context, i int) Sample {
+		calls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return Sample{Status: 200, Latency: 20 * time.Millisecond}
+	})
+	if len(samples) != 20 || calls.Load() != 20 {
+		t.Fatalf("Run() sent %d requests (%d calls), want 20", len(samples), calls.Load())
+	}
+	if elapsed < 190*time.Millisecond {
+		t.Errorf("Run() took %s, want requests spread over the duration", elapsed)
+	}
+}
+
+func TestRunMaxInFlight(t *testing.T) {
+	release := make(chan struct{})
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		close(release)
+	}()
+	samples, _ := Run(context.Background(), Options{RPS: 100, Duration: 100 * time.Millisecond, MaxInFlight: 3}, func(ctx context.Context, i int) Sample {
+		<-release
+		return Sample{Status: 200}
+	})
+	saturated := 0
+	for _, s := range samples {
+		if errors.Is(s.Err, ErrSaturated) {
+			saturated++
+		}
+	}
+	if len(samples) != 10 || saturated != 7 {
+		t.Errorf("Run() = %d samples, %d saturated; want 10 and 7", len(samples), saturated)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	var samples []Sample
+	for i := 1; i <= 100; i++ {
+		samples = append(samples, Sample{Status: 200, Latency: time.Duration(i) * time.Millisecond, RequestBytes: 40, ResponseBytes: 400})
+	}
+	samples = append(samples,
+		Sample{Status: 503},
+		Sample{Status: 503},
+		Sample{Err: context.DeadlineExceeded},
+		Sample{Err: ErrSaturated},
+	)
+	r := Summarize(samples, 10*time.Second)
+	if r.Requests != 104 || r.Succeeded != 100 || r.Failed != 4 {
+		t.Fatalf("Summarize() counts = %d/%d/%d", r.Requests, r.Succeeded, r.Failed)
+	}
+	if r.Errors["HTTP 503"] != 2 || r.Errors["timeout"] != 1 || r.Errors["not sent (max in flight)"] != 1 {
+		t.Errorf("Errors = %v", r.Errors)
+	}
+	want := Percentiles{P50: 50 * time.Millisecond, P90: 90 * time.Millisecond, P95: 95 * time.Millisecond, P99: 99 * time.Millisecond, Max: 100 * time.Millisecond}
+	if r.Latency != want {
+		t.Errorf("Latency = %+v, want %+v", r.Latency, want)
+	}
+	if r.Throughput != 10 || r.AvgResponseBytes != 400 {
+		t.Errorf("Throughput = %v, AvgResponseBytes = %v", r.Throughput, r.AvgResponseBytes)
+	}
+
+	cost := EstimateCost(r, "claude-sonnet-4-5", 3960)
+	if cost.InputTokens != 1000 || cost.OutputTokens != 100 || !cost.Priced || cost.PerRequest != 0.0045 {
+		t.Errorf("EstimateCost() = %+v", cost)
+	}
+	if cost := EstimateCost(r, "anthropic.claude-v2", 0); cost.Priced {
+		t.Errorf("EstimateCost(unknown model) = %+v, want unpriced", cost)
+	}
+}
+
+func TestPayload(t *testing.T) {
+	fields := []config.Field{
+		{Name: "message", Type: "str", Required: true},
+		{Name: "count", Type: "int"},
+		{Name: "strict", Type: "bool", Default: "True"},
+		{Name: "tone", Type: "str", Default: "friendly"},
+		{Name: "tags", Type: "list"},
+		{Name: "meta", Type: "dict"},
+	}
+	a, b := Payload(fields, 0), Payload(fields, 1)
+	if a["message"] == b["message"] || a["count"] != 1 || b["count"] != 2 {
+		t.Errorf("Payload() does not vary: %v, %v", a, b)
+	}
+	if a["strict"] != true || a["tone"] != "friendly" {
+		t.Errorf("Payload() ignores defaults: %v", a)
+	}
+	if _, ok := a["tags"].([]any); !ok {
+		t.Errorf("tags = %#v, want a list", a["tags"])
+	}
+	if _, ok := a["meta"].(map[string]any); !ok {
+		t.Errorf("meta = %#v, want an object", a["meta"])
+	}
+}
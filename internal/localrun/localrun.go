@@ -0,0 +1,220 @@
+// Package localrun executes an agent file on this machine with the Claude
+// Agent SDK, configured the way the generated app would run it, so prompts
+// can be tried against a payload without building or deploying.
+package localrun
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/agents"
+	"github.com/datagendev/datagen-cli/internal/codegen"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/mcpconfig"
+	"github.com/datagendev/datagen-cli/internal/models"
+	"github.com/datagendev/datagen-cli/internal/output"
+)
+
+//go:embed runner.py
+var runnerScript []byte
+
+// DefaultModel matches the generated app's default model
+const DefaultModel = models.Default
+
+// Spec is what the runner script needs to execute one agent run
+type Spec struct {
+	Model          string                    `json:"model"`
+	SystemPrompt   string                    `json:"system_prompt"`
+	Prompt         string                    `json:"prompt"`
+	AllowedTools   []string                  `json:"allowed_tools"`
+	MCPServers     map[string]map[string]any `json:"mcp_servers"`
+	PermissionMode string                    `json:"permission_mode"`
+	MaxTurns       int                       `json:"max_turns,omitempty"`
+}
+
+// Options adjust how a Spec is built
+type Options struct {
+	// Model overrides the agent's model
+	Model string
+	// DatagenAPIKey connects DataGen MCP when set
+	DatagenAPIKey string
+	// MCPServers are the service's extra servers from datagen.toml
+	MCPServers []config.MCPServer
+	MaxTurns   int
+}
+
+// BuildSpec prepares a run of prompt on payload
+func BuildSpec(prompt agents.Prompt, payload any, opts Options) (Spec, error) {
+	user, err := FormatPayload(payload)
+	if err != nil {
+		return Spec{}, err
+	}
+	model := opts.Model
+	if model == "" {
+		model = prompt.Model
+	}
+	if model == "" {
+		model = DefaultModel
+	}
+
+	servers := map[string]map[string]any{}
+	if opts.DatagenAPIKey != "" {
+		servers["datagen"] = map[string]any{
+			"type":    "http",
+			"url":     mcpconfig.DatagenMCPURL,
+			"headers": map[string]string{"Authorization": "Bearer " + strings.TrimSpace(opts.DatagenAPIKey)},
+		}
+	}
+	for _, s := range opts.MCPServers {
+		servers[s.Name] = expandEnv(codegen.MCPServerEntry(s)).(map[string]any)
+	}
+
+	tools := prompt.AllowedTools
+	if tools == nil {
+		tools = []string{}
+	}
+	return Spec{
+		Model:          model,
+		SystemPrompt:   prompt.SystemPrompt,
+		Prompt:         user,
+		AllowedTools:   tools,
+		MCPServers:     servers,
+		PermissionMode: "bypassPermissions",
+		MaxTurns:       opts.MaxTurns,
+	}, nil
+}
+
+// FormatPayload renders payload as the user message the generated app sends
+func FormatPayload(payload any) (string, error) {
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode payload: %w", err)
+	}
+	return "Here is the input data to process:\n\n```json\n" + string(data) + "\n```\n\nProcess this data according to your system prompt instructions.", nil
+}
+
+var envRef = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// expandEnv replaces ${VAR} references like the generated app's expand_env
+func expandEnv(v any) any {
+	switch t := v.(type) {
+	case string:
+		return envRef.ReplaceAllStringFunc(t, func(ref string) string {
+			return os.Getenv(envRef.FindStringSubmatch(ref)[1])
+		})
+	case map[string]string:
+		out := make(map[string]any, len(t))
+		for k, s := range t {
+			out[k] = expandEnv(s)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, s := range t {
+			out[k] = expandEnv(s)
+		}
+		return out
+	case []string:
+		out := make([]any, len(t))
+		for i, s := range t {
+			out[i] = expandEnv(s)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// Event is one line of runner output
+type Event struct {
+	// Type is text, tool_use, tool_result, result or error
+	Type  string         `json:"type"`
+	Text  string         `json:"text,omitempty"`
+	ID    string         `json:"id,omitempty"`
+	Name  string         `json:"name,omitempty"`
+	Input map[string]any `json:"input,omitempty"`
+
+	IsError    bool    `json:"is_error,omitempty"`
+	NumTurns   int     `json:"num_turns,omitempty"`
+	DurationMs int64   `json:"duration_ms,omitempty"`
+	CostUSD    float64 `json:"cost_usd,omitempty"`
+	Message    string  `json:"message,omitempty"`
+}
+
+// FindPython returns python3, or python, from PATH
+func FindPython() (string, error) {
+	for _, bin := range []string{"python3", "python"} {
+		if path, err := exec.LookPath(bin); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("python3 not found on PATH; install Python 3.10+ and claude-agent-sdk")
+}
+
+// Run executes spec with python and calls handle for every event as it
+// arrives. env is added to the current environment. The runner's stderr goes
+// to stderr.
+func Run(ctx context.Context, python string, spec Spec, env []string, stderr io.Writer, handle func(Event)) error {
+	dir, err := os.MkdirTemp("", "datagen-run-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	script := filepath.Join(dir, "runner.py")
+	if err := os.WriteFile(script, runnerScript, 0o600); err != nil {
+		return err
+	}
+
+	input, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, python, script)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stderr = stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	done := output.Trace(cmd)
+	if err := cmd.Start(); err != nil {
+		done(err)
+		return fmt.Errorf("failed to start %s: %w", python, err)
+	}
+
+	var failure string
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var ev Event
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			// Stray prints from the SDK or MCP servers
+			handle(Event{Type: "text", Text: scanner.Text() + "\n"})
+			continue
+		}
+		if ev.Type == "error" {
+			failure = ev.Message
+		}
+		handle(ev)
+	}
+	err = cmd.Wait()
+	done(err)
+	switch {
+	case failure != "":
+		return fmt.Errorf("agent run failed: %s", failure)
+	case err != nil:
+		return fmt.Errorf("agent run failed: %w", err)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,137 @@
+package localrun
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/agents"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/mcpconfig"
+)
+
+func TestBuildSpec(t *testing.T) {
+	t.Setenv("SLACK_TOKEN", "xoxb-1")
+	path := filepath.Join(t.TempDir(), "triage.md")
+	content := "---\nname: triage\ndescription: Triage\nmodel: haiku\ntools: mcp__datagen__executeTool, mcp__slack__post\n---\n\n## Role\nTriage tickets.\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prompt, err := agents.LoadPrompt(path)
+	if err != nil {
+		t.Fatalf("LoadPrompt() error = %v", err)
+	}
+
+	spec, err := BuildSpec(prompt, map[string]any{"id": 7}, Options{
+		DatagenAPIKey: "dg_key\n",
+		MCPServers: []config.MCPServer{
+			{Name: "slack", URL: "https://slack.example/mcp", Headers: map[string]string{"Authorization": "Bearer ${SLACK_TOKEN}"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("BuildSpec() error = %v", err)
+	}
+	if spec.Model != "haiku" || spec.SystemPrompt != "## Role\nTriage tickets." {
+		t.Errorf("spec = %+v", spec)
+	}
+	if want := []string{"mcp__datagen__executeTool", "mcp__slack__post"}; !reflect.DeepEqual(spec.AllowedTools, want) {
+		t.Errorf("AllowedTools = %v, want %v", spec.AllowedTools, want)
+	}
+	if !strings.Contains(spec.Prompt, "```json\n{\n  \"id\": 7\n}\n```") {
+		t.Errorf("Prompt = %q, want the payload as indented JSON", spec.Prompt)
+	}
+	datagen := spec.MCPServers["datagen"]
+	if datagen["url"] != mcpconfig.DatagenMCPURL || !reflect.DeepEqual(datagen["headers"], map[string]string{"Authorization": "Bearer dg_key"}) {
+		t.Errorf("datagen server = %v", datagen)
+	}
+	slack := spec.MCPServers["slack"]
+	if !reflect.DeepEqual(slack["headers"], map[string]any{"Authorization": "Bearer xoxb-1"}) {
+		t.Errorf("slack server = %v, want ${SLACK_TOKEN} expanded", slack)
+	}
+
+	spec, err = BuildSpec(prompt, nil, Options{Model: "opus"})
+	if err != nil {
+		t.Fatalf("BuildSpec() error = %v", err)
+	}
+	if spec.Model != "opus" || len(spec.MCPServers) != 0 {
+		t.Errorf("spec = %+v, want the model override and no servers", spec)
+	}
+}
+
+func TestLoadPrompt_NoFrontmatter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plain.md")
+	if err := os.WriteFile(path, []byte("\nSummarize the input.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prompt, err := agents.LoadPrompt(path)
+	if err != nil {
+		t.Fatalf("LoadPrompt() error = %v", err)
+	}
+	spec, err := BuildSpec(prompt, map[string]any{}, Options{})
+	if err != nil {
+		t.Fatalf("BuildSpec() error = %v", err)
+	}
+	if spec.Model != DefaultModel || spec.SystemPrompt != "Summarize the input." || !reflect.DeepEqual(spec.AllowedTools, agents.DefaultTools) {
+		t.Errorf("spec = %+v", spec)
+	}
+}
+
+// fakePython writes a script standing in for python that ignores the runner
+// and prints script
+func fakePython(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the interpreter")
+	}
+	path := filepath.Join(t.TempDir(), "python")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\ncat > /dev/null\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	python := fakePython(t, `echo '{"type":"text","text":"Looking up"}'
+echo '{"type":"tool_use","id":"t1","name":"mcp__datagen__executeTool","input":{"q":"a"}}'
+echo 'stray output'
+echo '{"type":"tool_result","id":"t1","text":"done","is_error":false}'
+echo '{"type":"result","num_turns":2,"duration_ms":1500,"cost_usd":0.01}'
+`)
+	var events []Event
+	err := Run(context.Background(), python, Spec{Model: "haiku"}, nil, io.Discard, func(ev Event) {
+		events = append(events, ev)
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	var types []string
+	for _, ev := range events {
+		types = append(types, ev.Type)
+	}
+	if want := []string{"text", "tool_use", "text", "tool_result", "result"}; !reflect.DeepEqual(types, want) {
+		t.Fatalf("event types = %v, want %v", types, want)
+	}
+	if events[1].Name != "mcp__datagen__executeTool" || events[1].Input["q"] != "a" || events[4].NumTurns != 2 {
+		t.Errorf("events = %+v", events)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	python := fakePython(t, `echo '{"type":"error","message":"claude-agent-sdk is not installed"}'
+exit 2
+`)
+	err := Run(context.Background(), python, Spec{}, nil, io.Discard, func(Event) {})
+	if err == nil || !strings.Contains(err.Error(), "claude-agent-sdk is not installed") {
+		t.Fatalf("Run() error = %v, want the runner's message", err)
+	}
+
+	python = fakePython(t, "exit 3\n")
+	if err := Run(context.Background(), python, Spec{}, nil, io.Discard, func(Event) {}); err == nil {
+		t.Fatalf("expected an error for a non-zero exit")
+	}
+}
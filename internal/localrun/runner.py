@@ -0,0 +1,91 @@
+"""Run an agent once with the Claude Agent SDK for `datagen agents run --local`.
+
+Reads a JSON spec on stdin and writes one JSON event per line to stdout.
+"""
+
+import asyncio
+import json
+import sys
+
+
+def emit(event):
+    sys.stdout.write(json.dumps(event, default=str) + "\n")
+    sys.stdout.flush()
+
+
+try:
+    from claude_agent_sdk import (
+        AssistantMessage,
+        ClaudeAgentOptions,
+        ResultMessage,
+        TextBlock,
+        ToolResultBlock,
+        ToolUseBlock,
+        UserMessage,
+        query,
+    )
+except ImportError:
+    emit({
+        "type": "error",
+        "message": f"claude-agent-sdk is not installed for {sys.executable}; run: {sys.executable} -m pip install claude-agent-sdk",
+    })
+    sys.exit(2)
+
+
+def result_text(content):
+    if content is None:
+        return ""
+    if isinstance(content, str):
+        return content
+    parts = []
+    for item in content:
+        if isinstance(item, dict) and item.get("type") == "text":
+            parts.append(item.get("text", ""))
+        else:
+            parts.append(json.dumps(item, default=str))
+    return "\n".join(parts)
+
+
+async def main():
+    spec = json.load(sys.stdin)
+    options = ClaudeAgentOptions(
+        model=spec["model"],
+        system_prompt=spec["system_prompt"],
+        permission_mode=spec["permission_mode"],
+        mcp_servers=spec["mcp_servers"],
+        allowed_tools=spec["allowed_tools"],
+        max_turns=spec.get("max_turns"),
+    )
+    async for msg in query(prompt=spec["prompt"], options=options):
+        if isinstance(msg, AssistantMessage):
+            for block in msg.content:
+                if isinstance(block, TextBlock):
+                    emit({"type": "text", "text": block.text})
+                elif isinstance(block, ToolUseBlock):
+                    emit({"type": "tool_use", "id": block.id, "name": block.name, "input": block.input})
+        elif isinstance(msg, UserMessage) and isinstance(msg.content, list):
+            for block in msg.content:
+                if isinstance(block, ToolResultBlock):
+                    emit({
+                        "type": "tool_result",
+                        "id": block.tool_use_id,
+                        "text": result_text(block.content),
+                        "is_error": bool(block.is_error),
+                    })
+        elif isinstance(msg, ResultMessage):
+            emit({
+                "type": "result",
+                "is_error": msg.is_error,
+                "num_turns": msg.num_turns,
+                "duration_ms": msg.duration_ms,
+                "cost_usd": msg.total_cost_usd,
+            })
+
+
+try:
+    asyncio.run(main())
+except KeyboardInterrupt:
+    sys.exit(130)
+except Exception as e:
+    emit({"type": "error", "message": f"{type(e).__name__}: {e}"})
+    sys.exit(1)
@@ -0,0 +1,246 @@
+package mcpconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+func WindsurfConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".codeium", "windsurf", "mcp_config.json"), nil
+}
+
+// ZedConfigPath returns Zed's settings.json, which holds context_servers
+// alongside every other Zed setting
+func ZedConfigPath() (string, error) {
+	if runtime.GOOS == "windows" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, "Zed", "settings.json"), nil
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" && runtime.GOOS == "linux" {
+		return filepath.Join(xdg, "zed", "settings.json"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "zed", "settings.json"), nil
+}
+
+// ClineConfigPath returns the MCP settings of the Cline VS Code extension
+func ClineConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "Code", "User", "globalStorage", "saoudrizwan.claude-dev", "settings", "cline_mcp_settings.json"), nil
+}
+
+func UpdateWindsurfConfigFile(path string, apiKey string) (bool, error) {
+	return updateJSONConfigFile(path, apiKey, UpdateWindsurfConfig)
+}
+
+// UpdateWindsurfConfig sets mcpServers.datagen; Windsurf names the remote
+// endpoint serverUrl
+func UpdateWindsurfConfig(contents string, apiKey string) (string, bool, error) {
+	return upsertJSONServer(contents, "mcpServers", apiKey, map[string]any{
+		"serverUrl": DatagenMCPURL,
+		"headers":   map[string]any{"X-API-Key": apiKey},
+	})
+}
+
+func UpdateZedConfigFile(path string, apiKey string) (bool, error) {
+	return updateJSONConfigFile(path, apiKey, UpdateZedConfig)
+}
+
+// UpdateZedConfig sets context_servers.datagen in Zed's settings. Zed allows
+// comments and trailing commas in settings.json; they are accepted but not
+// preserved when the file is rewritten.
+func UpdateZedConfig(contents string, apiKey string) (string, bool, error) {
+	return upsertJSONServer(contents, "context_servers", apiKey, map[string]any{
+		"url":     DatagenMCPURL,
+		"headers": map[string]any{"X-API-Key": apiKey},
+	})
+}
+
+func UpdateClineConfigFile(path string, apiKey string) (bool, error) {
+	return updateJSONConfigFile(path, apiKey, UpdateClineConfig)
+}
+
+// UpdateClineConfig sets mcpServers.datagen as a streamable HTTP server
+func UpdateClineConfig(contents string, apiKey string) (string, bool, error) {
+	return upsertJSONServer(contents, "mcpServers", apiKey, map[string]any{
+		"type":     "streamableHttp",
+		"url":      DatagenMCPURL,
+		"headers":  map[string]any{"X-API-Key": apiKey},
+		"disabled": false,
+	})
+}
+
+// HasJSONComments reports whether contents uses comments or trailing commas,
+// which are dropped when the file is rewritten
+func HasJSONComments(contents string) bool {
+	return stripJSONC(contents) != contents
+}
+
+// updateJSONConfigFile applies update to the file at path, creating it (but
+// not its directory) when it does not exist yet
+func updateJSONConfigFile(path string, apiKey string, update func(string, string) (string, bool, error)) (bool, error) {
+	raw, mode, err := readFileWithMode(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return false, err
+		}
+		raw, mode = "", 0o600
+	}
+
+	updated, changed, err := update(raw, apiKey)
+	if err != nil {
+		return false, err
+	}
+	if !changed {
+		return false, nil
+	}
+	return true, writeFileAtomic(path, []byte(updated), mode)
+}
+
+// upsertJSONServer sets root[container].datagen to server, leaving the file
+// untouched when the entry already matches
+func upsertJSONServer(contents, container, apiKey string, server map[string]any) (string, bool, error) {
+	if strings.TrimSpace(apiKey) == "" {
+		return "", false, errors.New("api key is required")
+	}
+	return upsertJSONServers(contents, container, map[string]map[string]any{"datagen": server})
+}
+
+// upsertJSONServers sets root[container][name] for every entry, leaving the
+// file untouched when all of them already match
+func upsertJSONServers(contents, container string, entries map[string]map[string]any) (string, bool, error) {
+	var root map[string]any
+	if stripped := stripJSONC(contents); strings.TrimSpace(stripped) != "" {
+		if err := json.Unmarshal([]byte(stripped), &root); err != nil {
+			return "", false, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+	}
+	if root == nil {
+		root = map[string]any{}
+	}
+
+	servers, _ := root[container].(map[string]any)
+	if servers == nil {
+		servers = map[string]any{}
+		root[container] = servers
+	}
+
+	changed := false
+	for name, server := range entries {
+		// Compare through a JSON round trip so numbers and nested maps match
+		encoded, err := json.Marshal(server)
+		if err != nil {
+			return "", false, err
+		}
+		var desired any
+		if err := json.Unmarshal(encoded, &desired); err != nil {
+			return "", false, err
+		}
+		if !reflect.DeepEqual(servers[name], desired) {
+			servers[name] = desired
+			changed = true
+		}
+	}
+	if !changed {
+		return ensureTrailingNewline(contents), false, nil
+	}
+
+	out, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return "", false, err
+	}
+	outStr := string(out) + "\n"
+	return outStr, outStr != contents, nil
+}
+
+// stripJSONC removes // and /* */ comments and trailing commas outside of
+// strings, turning JSON-with-comments into plain JSON
+func stripJSONC(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	inString := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			b.WriteByte(c)
+			if c == '\\' && i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch {
+		case c == '"':
+			inString = true
+			b.WriteByte(c)
+		case c == '/' && i+1 < len(s) && s[i+1] == '/':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+			if i < len(s) {
+				b.WriteByte('\n')
+			}
+		case c == '/' && i+1 < len(s) && s[i+1] == '*':
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				i = len(s)
+			} else {
+				i += end + 3
+			}
+		case c == ',':
+			// Drop the comma when only whitespace and comments precede } or ]
+			if next := nextToken(s, i+1); next == '}' || next == ']' {
+				continue
+			}
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// nextToken returns the first byte at or after i that is not whitespace or
+// part of a comment, or 0 at the end of s
+func nextToken(s string, i int) byte {
+	for i < len(s) {
+		switch {
+		case s[i] == ' ' || s[i] == '\t' || s[i] == '\r' || s[i] == '\n':
+			i++
+		case strings.HasPrefix(s[i:], "//"):
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return 0
+			}
+			i += end + 4
+		default:
+			return s[i]
+		}
+	}
+	return 0
+}
@@ -7,6 +7,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/backup"
+	"github.com/datagendev/datagen-cli/internal/debuglog"
 )
 
 const (
@@ -37,6 +40,57 @@ func GeminiConfigPath() (string, error) {
 	return filepath.Join(home, ".gemini", "settings.json"), nil
 }
 
+func CursorConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cursor", "mcp.json"), nil
+}
+
+// CursorProjectConfigPath returns the project-level Cursor config under dir
+func CursorProjectConfigPath(dir string) string {
+	return filepath.Join(dir, ".cursor", "mcp.json")
+}
+
+// CursorEnvHeader references envVarName through Cursor's config interpolation,
+// so a committed project config never contains the key itself
+func CursorEnvHeader(envVarName string) string {
+	return "${env:" + envVarName + "}"
+}
+
+// VSCodeConfigPath returns the user-level VS Code mcp.json
+func VSCodeConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "Code", "User", "mcp.json"), nil
+}
+
+// VSCodeProjectConfigPath returns the workspace VS Code config under dir
+func VSCodeProjectConfigPath(dir string) string {
+	return filepath.Join(dir, ".vscode", "mcp.json")
+}
+
+// VSCodeInputID names the input variable VS Code prompts for once and stores
+// in its secret storage; the config only holds ${input:datagen-api-key}
+const VSCodeInputID = "datagen-api-key"
+
+// EnvReference returns how client's config refers to the environment
+// variable envVarName, and false when the client cannot expand variables in
+// its config
+func EnvReference(client, envVarName string) (string, bool) {
+	switch client {
+	case "claude", "gemini":
+		return "${" + envVarName + "}", true
+	case "cursor", "windsurf":
+		return CursorEnvHeader(envVarName), true
+	default:
+		return "", false
+	}
+}
+
 func ClaudeConfigPathLegacy() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -70,18 +124,34 @@ func UpdateCodexConfig(contents string, apiKey string, useEnvHeaders bool, envVa
 		return "", false, errors.New("api key is required for http_headers")
 	}
 
+	doc, err := decodeTOML(contents)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to parse TOML: %w", err)
+	}
+
 	original := contents
-	contents = ensureFeaturesRmcpClientTrue(contents)
-	contents = upsertTomlTable(contents, "mcp_servers.datagen", renderCodexDatagenTable(apiKey, useEnvHeaders, envVarName))
+	nl := tomlLineEnding(contents)
+	contents, err = ensureFeaturesRmcpClientTrue(contents, doc)
+	if err != nil {
+		return "", false, err
+	}
+	contents, err = upsertCodexServer(contents, "datagen", renderCodexDatagenTable(apiKey, useEnvHeaders, envVarName, nl))
+	if err != nil {
+		return "", false, err
+	}
 
 	if !strings.HasSuffix(contents, "\n") {
-		contents += "\n"
+		contents += nl
+	}
+	// Never write a file Codex cannot read
+	if _, err := decodeTOML(contents); err != nil {
+		return "", false, fmt.Errorf("updated config is not valid TOML: %w", err)
 	}
 
 	return contents, contents != original, nil
 }
 
-func renderCodexDatagenTable(apiKey string, useEnvHeaders bool, envVarName string) string {
+func renderCodexDatagenTable(apiKey string, useEnvHeaders bool, envVarName string, nl string) string {
 	var headerLine string
 	if useEnvHeaders {
 		headerLine = fmt.Sprintf(`env_http_headers = { "x-api-key" = %q }`, envVarName)
@@ -94,105 +164,7 @@ func renderCodexDatagenTable(apiKey string, useEnvHeaders bool, envVarName strin
 		fmt.Sprintf("url = %q", DatagenMCPURL),
 		headerLine,
 		"",
-	}, "\n")
-}
-
-func ensureFeaturesRmcpClientTrue(contents string) string {
-	// If [features] exists, rewrite rmcp_client to true or insert it.
-	lines := strings.Split(contents, "\n")
-	for i := 0; i < len(lines); i++ {
-		if strings.TrimSpace(lines[i]) != "[features]" {
-			continue
-		}
-		indent := ""
-		for j := i + 1; j < len(lines); j++ {
-			trim := strings.TrimSpace(lines[j])
-			if strings.HasPrefix(trim, "[") {
-				// Insert rmcp_client before the next table.
-				lines = append(lines[:j], append([]string{indent + "rmcp_client = true"}, lines[j:]...)...)
-				return strings.Join(lines, "\n")
-			}
-			if indent == "" && lines[j] != "" {
-				indent = lines[j][:len(lines[j])-len(strings.TrimLeft(lines[j], " \t"))]
-			}
-			if trim == "" {
-				continue
-			}
-			if strings.HasPrefix(trim, "#") {
-				continue
-			}
-			if strings.HasPrefix(trim, "rmcp_client") {
-				prefix := lines[j][:len(lines[j])-len(strings.TrimLeft(lines[j], " \t"))]
-				lines[j] = prefix + "rmcp_client = true"
-				return strings.Join(lines, "\n")
-			}
-		}
-		// Reached EOF inside features table; append.
-		if len(lines) > 0 && lines[len(lines)-1] != "" {
-			lines = append(lines, "")
-		}
-		lines = append(lines, "rmcp_client = true")
-		return strings.Join(lines, "\n")
-	}
-
-	// No [features] table; append a minimal one.
-	contents = strings.TrimRight(contents, "\n")
-	if contents != "" {
-		contents += "\n\n"
-	}
-	contents += strings.Join([]string{
-		"[features]",
-		"rmcp_client = true",
-		"",
-	}, "\n")
-	return contents
-}
-
-func upsertTomlTable(contents string, tableName string, desiredTable string) string {
-	header := "[" + tableName + "]"
-	lines := strings.Split(contents, "\n")
-
-	start := -1
-	for i, line := range lines {
-		if strings.TrimSpace(line) == header {
-			start = i
-			break
-		}
-	}
-
-	if start == -1 {
-		contents = strings.TrimRight(contents, "\n")
-		if contents != "" {
-			contents += "\n\n"
-		}
-		return contents + strings.TrimLeft(desiredTable, "\n")
-	}
-
-	end := len(lines)
-	for i := start + 1; i < len(lines); i++ {
-		if strings.HasPrefix(strings.TrimSpace(lines[i]), "[") {
-			end = i
-			break
-		}
-	}
-
-	before := strings.Join(lines[:start], "\n")
-	after := strings.Join(lines[end:], "\n")
-	desired := strings.TrimLeft(desiredTable, "\n")
-
-	before = strings.TrimRight(before, "\n")
-	after = strings.TrimLeft(after, "\n")
-
-	switch {
-	case before == "" && after == "":
-		return desired
-	case before == "":
-		return desired + "\n" + after
-	case after == "":
-		return before + "\n\n" + desired
-	default:
-		return before + "\n\n" + desired + "\n" + after
-	}
+	}, nl)
 }
 
 func UpdateClaudeConfigFile(path string, apiKey string) (bool, error) {
@@ -321,6 +293,188 @@ func UpdateGeminiConfig(contents string, apiKey string) (string, bool, error) {
 	return outStr, outStr != contents, nil
 }
 
+// UpdateCursorConfigFile writes the Datagen server into a Cursor mcp.json,
+// creating the file (but not its directory) when it does not exist yet
+func UpdateCursorConfigFile(path string, headerValue string) (bool, error) {
+	raw, mode, err := readFileWithMode(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return false, err
+		}
+		raw, mode = "", 0o600
+	}
+
+	updated, changed, err := UpdateCursorConfig(raw, headerValue)
+	if err != nil {
+		return false, err
+	}
+	if !changed {
+		return false, nil
+	}
+	return true, writeFileAtomic(path, []byte(updated), mode)
+}
+
+// UpdateCursorConfig sets mcpServers.datagen to Cursor's remote server shape:
+// a url plus headers, with no type or command
+func UpdateCursorConfig(contents string, headerValue string) (string, bool, error) {
+	if strings.TrimSpace(headerValue) == "" {
+		return "", false, errors.New("api key is required")
+	}
+
+	var root map[string]any
+	if strings.TrimSpace(contents) != "" {
+		if err := json.Unmarshal([]byte(contents), &root); err != nil {
+			return "", false, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+	}
+	if root == nil {
+		root = map[string]any{}
+	}
+
+	servers, _ := root["mcpServers"].(map[string]any)
+	if servers == nil {
+		servers = map[string]any{}
+		root["mcpServers"] = servers
+	}
+
+	if cursorDatagenServerIsCurrent(servers["datagen"], headerValue) {
+		return ensureTrailingNewline(contents), false, nil
+	}
+
+	type cursorServer struct {
+		URL     string            `json:"url"`
+		Headers map[string]string `json:"headers"`
+	}
+	encoded, err := json.Marshal(cursorServer{
+		URL: DatagenMCPURL,
+		Headers: map[string]string{
+			"X-API-Key": headerValue,
+		},
+	})
+	if err != nil {
+		return "", false, err
+	}
+	servers["datagen"] = json.RawMessage(encoded)
+
+	out, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return "", false, err
+	}
+	outStr := string(out) + "\n"
+	return outStr, outStr != contents, nil
+}
+
+// UpdateVSCodeConfigFile writes the Datagen server into a VS Code mcp.json,
+// creating the file (but not its directory) when it does not exist yet
+func UpdateVSCodeConfigFile(path string) (bool, error) {
+	raw, mode, err := readFileWithMode(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return false, err
+		}
+		raw, mode = "", 0o644
+	}
+
+	updated, changed, err := UpdateVSCodeConfig(raw)
+	if err != nil {
+		return false, err
+	}
+	if !changed {
+		return false, nil
+	}
+	return true, writeFileAtomic(path, []byte(updated), mode)
+}
+
+// UpdateVSCodeConfig sets servers.datagen to an http server whose API key
+// header comes from a password input, adding the input when it is missing
+func UpdateVSCodeConfig(contents string) (string, bool, error) {
+	var root map[string]any
+	if strings.TrimSpace(contents) != "" {
+		if err := json.Unmarshal([]byte(contents), &root); err != nil {
+			return "", false, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+	}
+	if root == nil {
+		root = map[string]any{}
+	}
+
+	servers, _ := root["servers"].(map[string]any)
+	if servers == nil {
+		servers = map[string]any{}
+		root["servers"] = servers
+	}
+	inputs, _ := root["inputs"].([]any)
+
+	hasInput := false
+	for _, in := range inputs {
+		if m, _ := in.(map[string]any); m != nil && m["id"] == VSCodeInputID {
+			hasInput = true
+			break
+		}
+	}
+
+	if hasInput && vscodeDatagenServerIsCurrent(servers["datagen"]) {
+		return ensureTrailingNewline(contents), false, nil
+	}
+
+	if !hasInput {
+		root["inputs"] = append(inputs, map[string]any{
+			"type":        "promptString",
+			"id":          VSCodeInputID,
+			"description": "DataGen API key",
+			"password":    true,
+		})
+	}
+
+	type vscodeServer struct {
+		Type    string            `json:"type"`
+		URL     string            `json:"url"`
+		Headers map[string]string `json:"headers"`
+	}
+	encoded, err := json.Marshal(vscodeServer{
+		Type: "http",
+		URL:  DatagenMCPURL,
+		Headers: map[string]string{
+			"X-API-Key": "${input:" + VSCodeInputID + "}",
+		},
+	})
+	if err != nil {
+		return "", false, err
+	}
+	servers["datagen"] = json.RawMessage(encoded)
+
+	out, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return "", false, err
+	}
+	outStr := string(out) + "\n"
+	return outStr, outStr != contents, nil
+}
+
+func vscodeDatagenServerIsCurrent(v any) bool {
+	m, _ := v.(map[string]any)
+	if m == nil || m["type"] != "http" || m["url"] != DatagenMCPURL {
+		return false
+	}
+	headers, _ := m["headers"].(map[string]any)
+	if headers == nil {
+		return false
+	}
+	return headers["X-API-Key"] == "${input:"+VSCodeInputID+"}"
+}
+
+func cursorDatagenServerIsCurrent(v any, headerValue string) bool {
+	m, _ := v.(map[string]any)
+	if m == nil || m["url"] != DatagenMCPURL {
+		return false
+	}
+	headers, _ := m["headers"].(map[string]any)
+	if headers == nil {
+		return false
+	}
+	return headers["X-API-Key"] == headerValue
+}
+
 func claudeDatagenServerIsCurrent(v any, apiKey string) bool {
 	switch t := v.(type) {
 	case map[string]any:
@@ -379,7 +533,13 @@ func readFileWithMode(path string) (string, os.FileMode, error) {
 	return string(data), info.Mode().Perm(), nil
 }
 
+// writeFileAtomic replaces path with data, backing up the previous version
+// for `datagen restore` first
 func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	if err := backup.Save(path); err != nil {
+		return err
+	}
+	debuglog.FileWrite(path, data)
 	dir := filepath.Dir(path)
 	tmp := filepath.Join(dir, "."+filepath.Base(path)+".datagen.tmp")
 	if err := os.WriteFile(tmp, data, mode); err != nil {
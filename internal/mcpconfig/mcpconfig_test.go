@@ -2,8 +2,12 @@ package mcpconfig
 
 import (
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/config"
 )
 
 func TestUpdateCodexConfig_PreservesOtherFeatures(t *testing.T) {
@@ -104,3 +108,251 @@ func TestUpdateClaudeConfig_WritesTopLevelMCPServers(t *testing.T) {
 		t.Fatalf("expected cachedGrowthBookFeatures preserved")
 	}
 }
+
+func TestUpdateCursorConfig(t *testing.T) {
+	input := `{
+  "mcpServers": {
+    "other": {"command": "npx", "args": ["other-mcp"]}
+  }
+}`
+
+	out, changed, err := UpdateCursorConfig(input, CursorEnvHeader("DATAGEN_API_KEY"))
+	if err != nil {
+		t.Fatalf("UpdateCursorConfig() error = %v", err)
+	}
+	if !changed {
+		t.Fatalf("expected changed=true")
+	}
+
+	var root map[string]any
+	if err := json.Unmarshal([]byte(out), &root); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	servers, _ := root["mcpServers"].(map[string]any)
+	if _, ok := servers["other"]; !ok {
+		t.Fatalf("expected other server preserved, got:\n%s", out)
+	}
+	datagen, _ := servers["datagen"].(map[string]any)
+	if datagen["url"] != DatagenMCPURL {
+		t.Fatalf("datagen url = %v, want %s", datagen["url"], DatagenMCPURL)
+	}
+	if _, ok := datagen["type"]; ok {
+		t.Fatalf("Cursor server should not set type, got:\n%s", out)
+	}
+	headers, _ := datagen["headers"].(map[string]any)
+	if headers["X-API-Key"] != "${env:DATAGEN_API_KEY}" {
+		t.Fatalf("X-API-Key = %v, want ${env:DATAGEN_API_KEY}", headers["X-API-Key"])
+	}
+
+	again, changed, err := UpdateCursorConfig(out, CursorEnvHeader("DATAGEN_API_KEY"))
+	if err != nil {
+		t.Fatalf("UpdateCursorConfig() second run error = %v", err)
+	}
+	if changed || again != out {
+		t.Fatalf("expected second run to be a no-op")
+	}
+}
+
+func TestUpdateCursorConfigFile_CreatesMissingFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "mcp.json")
+
+	changed, err := UpdateCursorConfigFile(path, "k123")
+	if err != nil {
+		t.Fatalf("UpdateCursorConfigFile() error = %v", err)
+	}
+	if !changed {
+		t.Fatalf("expected changed=true")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile error = %v", err)
+	}
+	if !strings.Contains(string(data), `"X-API-Key": "k123"`) {
+		t.Fatalf("expected key header, got:\n%s", data)
+	}
+}
+
+func TestUpdateVSCodeConfig(t *testing.T) {
+	input := `{
+  "inputs": [{"type": "promptString", "id": "other-token", "password": true}],
+  "servers": {
+    "other": {"type": "stdio", "command": "npx", "args": ["other-mcp"]}
+  }
+}`
+
+	out, changed, err := UpdateVSCodeConfig(input)
+	if err != nil {
+		t.Fatalf("UpdateVSCodeConfig() error = %v", err)
+	}
+	if !changed {
+		t.Fatalf("expected changed=true")
+	}
+
+	var root map[string]any
+	if err := json.Unmarshal([]byte(out), &root); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	servers, _ := root["servers"].(map[string]any)
+	if _, ok := servers["other"]; !ok {
+		t.Fatalf("expected other server preserved, got:\n%s", out)
+	}
+	datagen, _ := servers["datagen"].(map[string]any)
+	if datagen["type"] != "http" || datagen["url"] != DatagenMCPURL {
+		t.Fatalf("datagen server = %v, want http %s", datagen, DatagenMCPURL)
+	}
+	headers, _ := datagen["headers"].(map[string]any)
+	if headers["X-API-Key"] != "${input:datagen-api-key}" {
+		t.Fatalf("X-API-Key = %v, want ${input:datagen-api-key}", headers["X-API-Key"])
+	}
+	inputs, _ := root["inputs"].([]any)
+	if len(inputs) != 2 {
+		t.Fatalf("expected existing input kept and datagen input added, got:\n%s", out)
+	}
+
+	again, changed, err := UpdateVSCodeConfig(out)
+	if err != nil {
+		t.Fatalf("UpdateVSCodeConfig() second run error = %v", err)
+	}
+	if changed || again != out {
+		t.Fatalf("expected second run to be a no-op")
+	}
+}
+
+func TestUpdateEditorConfigs(t *testing.T) {
+	tests := []struct {
+		name      string
+		update    func(string, string) (string, bool, error)
+		input     string
+		container string
+		urlKey    string
+	}{
+		{"windsurf", UpdateWindsurfConfig, `{"mcpServers": {"other": {"command": "npx"}}}`, "mcpServers", "serverUrl"},
+		{"cline", UpdateClineConfig, `{"mcpServers": {"other": {"command": "npx"}}}`, "mcpServers", "url"},
+		{"zed", UpdateZedConfig, `// Zed settings
+{
+  "theme": "One Dark", /* keep */
+  "context_servers": {
+    "other": {"command": "npx", "args": ["other-mcp"],},
+  },
+}`, "context_servers", "url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, changed, err := tt.update(tt.input, "k123")
+			if err != nil {
+				t.Fatalf("update error = %v", err)
+			}
+			if !changed {
+				t.Fatalf("expected changed=true")
+			}
+
+			var root map[string]any
+			if err := json.Unmarshal([]byte(out), &root); err != nil {
+				t.Fatalf("output is not valid JSON: %v\n%s", err, out)
+			}
+			servers, _ := root[tt.container].(map[string]any)
+			if _, ok := servers["other"]; !ok {
+				t.Fatalf("expected other server preserved, got:\n%s", out)
+			}
+			datagen, _ := servers["datagen"].(map[string]any)
+			if datagen[tt.urlKey] != DatagenMCPURL {
+				t.Fatalf("datagen %s = %v, want %s", tt.urlKey, datagen[tt.urlKey], DatagenMCPURL)
+			}
+			headers, _ := datagen["headers"].(map[string]any)
+			if headers["X-API-Key"] != "k123" {
+				t.Fatalf("X-API-Key = %v, want k123", headers["X-API-Key"])
+			}
+
+			again, changed, err := tt.update(out, "k123")
+			if err != nil {
+				t.Fatalf("second run error = %v", err)
+			}
+			if changed || again != out {
+				t.Fatalf("expected second run to be a no-op")
+			}
+		})
+	}
+}
+
+func TestStripJSONC(t *testing.T) {
+	in := `{"url": "https://x//y", "a": [1, 2,], /* c */ "s": "\"//\"",}`
+	var v map[string]any
+	if err := json.Unmarshal([]byte(stripJSONC(in)), &v); err != nil {
+		t.Fatalf("stripJSONC output is not JSON: %v\n%s", err, stripJSONC(in))
+	}
+	if v["url"] != "https://x//y" || v["s"] != `"//"` {
+		t.Fatalf("strings were altered: %v", v)
+	}
+	if HasJSONComments(`{"a": 1}`) {
+		t.Fatalf("HasJSONComments() = true for plain JSON")
+	}
+}
+
+func TestEnvReference(t *testing.T) {
+	tests := []struct {
+		client string
+		want   string
+		ok     bool
+	}{
+		{"claude", "${DATAGEN_API_KEY}", true},
+		{"gemini", "${DATAGEN_API_KEY}", true},
+		{"cursor", "${env:DATAGEN_API_KEY}", true},
+		{"windsurf", "${env:DATAGEN_API_KEY}", true},
+		{"zed", "", false},
+		{"cline", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := EnvReference(tt.client, "DATAGEN_API_KEY")
+		if got != tt.want || ok != tt.ok {
+			t.Fatalf("EnvReference(%q) = %q, %v; want %q, %v", tt.client, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	out, _, err := UpdateClaudeConfig(`{}`, "${DATAGEN_API_KEY}")
+	if err != nil {
+		t.Fatalf("UpdateClaudeConfig() error = %v", err)
+	}
+	if !strings.Contains(out, `"X-API-Key": "${DATAGEN_API_KEY}"`) {
+		t.Fatalf("expected env reference in Claude config, got:\n%s", out)
+	}
+}
+
+func TestUpdateServersConfig(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "ghp_local")
+	servers := []config.MCPServer{
+		{Name: "github", URL: "https://api.githubcopilot.com/mcp/", Headers: map[string]string{"Authorization": "Bearer ${GITHUB_TOKEN}"}},
+		{Name: "fs", Command: "npx", Args: []string{"-y", "server-filesystem"}},
+	}
+
+	tests := []struct {
+		client string
+		want   []string
+	}{
+		{"claude", []string{`"type": "http"`, `"Bearer ${GITHUB_TOKEN}"`, `"type": "stdio"`}},
+		{"gemini", []string{`"httpUrl": "https://api.githubcopilot.com/mcp/"`}},
+		{"cursor", []string{`"Bearer ${env:GITHUB_TOKEN}"`}},
+		{"windsurf", []string{`"serverUrl": "https://api.githubcopilot.com/mcp/"`}},
+		{"cline", []string{`"type": "streamableHttp"`, `"Bearer ghp_local"`, `"disabled": false`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.client, func(t *testing.T) {
+			input := `{"mcpServers": {"other": {"url": "https://example.com/mcp"}}}`
+			out, changed, err := UpdateServersConfig(tt.client, input, servers)
+			if err != nil {
+				t.Fatalf("UpdateServersConfig() error = %v", err)
+			}
+			if !changed {
+				t.Fatalf("expected changed=true")
+			}
+			for _, want := range append(tt.want, `"other"`, `"command": "npx"`) {
+				if !strings.Contains(out, want) {
+					t.Fatalf("expected %s in output, got:\n%s", want, out)
+				}
+			}
+			if _, changed, _ := UpdateServersConfig(tt.client, out, servers); changed {
+				t.Fatalf("expected second run to be a no-op")
+			}
+		})
+	}
+}
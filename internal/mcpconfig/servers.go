@@ -0,0 +1,104 @@
+package mcpconfig
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+// ServerClients are the clients UpdateServersConfig can add the extra MCP
+// servers of a datagen.toml to; all keep servers in a top-level mcpServers
+// object
+var ServerClients = []string{"claude", "gemini", "cursor", "windsurf", "cline"}
+
+var envRefPattern = regexp.MustCompile(`\$\{(\w+)\}`)
+
+func UpdateServersConfigFile(client, path string, servers []config.MCPServer) (bool, error) {
+	return updateJSONConfigFile(path, "", func(contents, _ string) (string, bool, error) {
+		return UpdateServersConfig(client, contents, servers)
+	})
+}
+
+// UpdateServersConfig sets mcpServers.<name> for each server in the shape
+// client expects. ${VAR} references in headers and env become the client's
+// own environment references where it has them and are expanded from the
+// current environment otherwise.
+func UpdateServersConfig(client, contents string, servers []config.MCPServer) (string, bool, error) {
+	entries := map[string]map[string]any{}
+	for _, s := range servers {
+		entry, err := serverEntry(client, s)
+		if err != nil {
+			return "", false, err
+		}
+		entries[s.Name] = entry
+	}
+	return upsertJSONServers(contents, "mcpServers", entries)
+}
+
+func serverEntry(client string, s config.MCPServer) (map[string]any, error) {
+	entry := map[string]any{}
+	if s.URL != "" {
+		sse := s.Transport == "sse"
+		switch client {
+		case "claude":
+			entry["type"] = "http"
+			if sse {
+				entry["type"] = "sse"
+			}
+			entry["url"] = s.URL
+		case "gemini":
+			// Gemini takes SSE servers as url and streamable HTTP as httpUrl
+			if sse {
+				entry["url"] = s.URL
+			} else {
+				entry["httpUrl"] = s.URL
+			}
+		case "cursor":
+			entry["url"] = s.URL
+		case "windsurf":
+			entry["serverUrl"] = s.URL
+		case "cline":
+			entry["type"] = "streamableHttp"
+			if sse {
+				entry["type"] = "sse"
+			}
+			entry["url"] = s.URL
+		default:
+			return nil, fmt.Errorf("extra MCP servers are not supported for %s", client)
+		}
+		if len(s.Headers) > 0 {
+			entry["headers"] = clientEnvValues(client, s.Headers)
+		}
+	} else {
+		if client == "claude" {
+			entry["type"] = "stdio"
+		}
+		entry["command"] = s.Command
+		if len(s.Args) > 0 {
+			entry["args"] = s.Args
+		}
+		if len(s.Env) > 0 {
+			entry["env"] = clientEnvValues(client, s.Env)
+		}
+	}
+	if client == "cline" {
+		entry["disabled"] = false
+	}
+	return entry, nil
+}
+
+func clientEnvValues(client string, values map[string]string) map[string]string {
+	out := make(map[string]string, len(values))
+	for k, v := range values {
+		out[k] = envRefPattern.ReplaceAllStringFunc(v, func(ref string) string {
+			name := envRefPattern.FindStringSubmatch(ref)[1]
+			if clientRef, ok := EnvReference(client, name); ok {
+				return clientRef
+			}
+			return os.Getenv(name)
+		})
+	}
+	return out
+}
@@ -0,0 +1,389 @@
+package mcpconfig
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Codex's config.toml is edited statement by statement instead of being
+// decoded and re-encoded, so comments, ordering and formatting survive.
+
+type tomlStatementKind int
+
+const (
+	tomlBlank tomlStatementKind = iota // empty or comment-only line
+	tomlTable
+	tomlArrayTable
+	tomlKeyValue
+)
+
+// tomlStatement is one top-level statement of a TOML document. start and end
+// cover the whole lines it occupies, including the final line break.
+type tomlStatement struct {
+	kind tomlStatementKind
+	// key is the table path of a header, or the dotted key of a key/value
+	key []string
+	// table is the path of the table a key/value belongs to
+	table                []string
+	start, end           int
+	valueStart, valueEnd int
+}
+
+// path returns the full key path of a key/value statement
+func (st tomlStatement) path() []string {
+	return append(slices.Clone(st.table), st.key...)
+}
+
+func parseTOMLStatements(s string) ([]tomlStatement, error) {
+	var stmts []tomlStatement
+	var table []string
+	for pos := 0; pos < len(s); {
+		st := tomlStatement{kind: tomlBlank, start: pos}
+		i := skipTOMLSpace(s, pos)
+		var err error
+		switch {
+		case i >= len(s) || s[i] == '\r' || s[i] == '\n' || s[i] == '#':
+		case s[i] == '[':
+			st.kind, i = tomlTable, i+1
+			closing := "]"
+			if i < len(s) && s[i] == '[' {
+				st.kind, i, closing = tomlArrayTable, i+1, "]]"
+			}
+			if st.key, i, err = parseTOMLKey(s, i); err != nil {
+				return nil, err
+			}
+			i = skipTOMLSpace(s, i)
+			if !strings.HasPrefix(s[i:], closing) {
+				return nil, tomlError(s, i, "expected %q after table name", closing)
+			}
+			i += len(closing)
+			table = st.key
+		default:
+			st.kind, st.table = tomlKeyValue, table
+			if st.key, i, err = parseTOMLKey(s, i); err != nil {
+				return nil, err
+			}
+			i = skipTOMLSpace(s, i)
+			if i >= len(s) || s[i] != '=' {
+				return nil, tomlError(s, i, "expected '=' after key")
+			}
+			st.valueStart = skipTOMLSpace(s, i+1)
+			if st.valueEnd, err = scanTOMLValue(s, st.valueStart); err != nil {
+				return nil, err
+			}
+			i = st.valueEnd
+		}
+
+		// Only a comment may follow on the same line
+		i = skipTOMLSpace(s, i)
+		if i < len(s) && s[i] != '#' && s[i] != '\r' && s[i] != '\n' {
+			return nil, tomlError(s, i, "unexpected text after statement")
+		}
+		st.end = len(s)
+		if nl := strings.IndexByte(s[i:], '\n'); nl >= 0 {
+			st.end = i + nl + 1
+		}
+		stmts = append(stmts, st)
+		pos = st.end
+	}
+	return stmts, nil
+}
+
+// parseTOMLKey parses a bare, quoted or dotted key starting at i
+func parseTOMLKey(s string, i int) ([]string, int, error) {
+	var key []string
+	for {
+		i = skipTOMLSpace(s, i)
+		if i >= len(s) {
+			return nil, i, tomlError(s, i, "expected a key")
+		}
+		switch s[i] {
+		case '"', '\'':
+			end, err := scanTOMLValue(s, i)
+			if err != nil {
+				return nil, i, err
+			}
+			part := s[i+1 : end-1]
+			if s[i] == '"' {
+				if unquoted, err := strconv.Unquote(s[i:end]); err == nil {
+					part = unquoted
+				}
+			}
+			key, i = append(key, part), end
+		default:
+			j := i
+			for j < len(s) && isBareKeyChar(s[j]) {
+				j++
+			}
+			if j == i {
+				return nil, i, tomlError(s, i, "expected a key")
+			}
+			key, i = append(key, s[i:j]), j
+		}
+		i = skipTOMLSpace(s, i)
+		if i >= len(s) || s[i] != '.' {
+			return key, i, nil
+		}
+		i++
+	}
+}
+
+// scanTOMLValue returns the offset just past the value starting at i. Strings,
+// arrays and inline tables may span several lines.
+func scanTOMLValue(s string, i int) (int, error) {
+	switch {
+	case strings.HasPrefix(s[i:], `"""`), strings.HasPrefix(s[i:], `'''`):
+		delim := s[i : i+3]
+		for j := i + 3; j < len(s); j++ {
+			if s[j] == '\\' && delim == `"""` {
+				j++
+				continue
+			}
+			if strings.HasPrefix(s[j:], delim) {
+				// Up to two quotes may sit right before the closing delimiter
+				end := j + 3
+				for k := 0; k < 2 && end < len(s) && s[end] == delim[0]; k++ {
+					end++
+				}
+				return end, nil
+			}
+		}
+		return 0, tomlError(s, i, "unterminated multi-line string")
+	case i < len(s) && (s[i] == '"' || s[i] == '\''):
+		for j := i + 1; j < len(s) && s[j] != '\n'; j++ {
+			if s[j] == '\\' && s[i] == '"' {
+				j++
+				continue
+			}
+			if s[j] == s[i] {
+				return j + 1, nil
+			}
+		}
+		return 0, tomlError(s, i, "unterminated string")
+	case i < len(s) && (s[i] == '[' || s[i] == '{'):
+		depth := 0
+		for j := i; j < len(s); {
+			switch s[j] {
+			case '[', '{':
+				depth++
+				j++
+			case ']', '}':
+				depth--
+				j++
+				if depth == 0 {
+					return j, nil
+				}
+			case '"', '\'':
+				end, err := scanTOMLValue(s, j)
+				if err != nil {
+					return 0, err
+				}
+				j = end
+			case '#':
+				for j < len(s) && s[j] != '\n' {
+					j++
+				}
+			default:
+				j++
+			}
+		}
+		return 0, tomlError(s, i, "unterminated array or inline table")
+	default:
+		// Numbers, booleans and dates run to the end of the line or a comment
+		j := i
+		for j < len(s) && s[j] != '#' && s[j] != '\r' && s[j] != '\n' {
+			j++
+		}
+		for j > i && (s[j-1] == ' ' || s[j-1] == '\t') {
+			j--
+		}
+		if j == i {
+			return 0, tomlError(s, i, "expected a value")
+		}
+		return j, nil
+	}
+}
+
+func skipTOMLSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func tomlError(s string, i int, format string, args ...any) error {
+	line := strings.Count(s[:min(i, len(s))], "\n") + 1
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func hasKeyPrefix(key []string, prefix ...string) bool {
+	return len(key) >= len(prefix) && slices.Equal(key[:len(prefix)], prefix)
+}
+
+// tomlLineEnding returns the line break the document already uses
+func tomlLineEnding(contents string) string {
+	if strings.Contains(contents, "\r\n") {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// ensureFeaturesRmcpClientTrue sets features.rmcp_client = true wherever the
+// document defines its features: a [features] table, dotted features.* keys or
+// a features inline table. doc is the decoded document.
+func ensureFeaturesRmcpClientTrue(contents string, doc map[string]any) (string, error) {
+	stmts, err := parseTOMLStatements(contents)
+	if err != nil {
+		return "", err
+	}
+	nl := tomlLineEnding(contents)
+	features, _ := doc["features"].(map[string]any)
+	current, defined := features["rmcp_client"]
+
+	last := -1
+	for i, st := range stmts {
+		switch st.kind {
+		case tomlTable:
+			if slices.Equal(st.key, []string{"features"}) {
+				last = i
+			}
+		case tomlKeyValue:
+			path := st.path()
+			switch {
+			case slices.Equal(path, []string{"features", "rmcp_client"}):
+				return contents[:st.valueStart] + "true" + contents[st.valueEnd:], nil
+			case slices.Equal(path, []string{"features"}):
+				return setInlineFeature(contents, st, current, defined)
+			case len(path) == 2 && path[0] == "features":
+				last = i
+			}
+		}
+	}
+
+	if last < 0 {
+		contents = strings.TrimRight(contents, "\r\n")
+		if contents != "" {
+			contents += nl + nl
+		}
+		return contents + "[features]" + nl + "rmcp_client = true" + nl, nil
+	}
+
+	// Add the key after the last feature, matching its indentation and form
+	st := stmts[last]
+	line := contents[st.start:st.end]
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	key := "rmcp_client"
+	if st.kind == tomlKeyValue {
+		key = strings.Join(append(st.key[:len(st.key)-1:len(st.key)-1], key), ".")
+	} else {
+		indent = ""
+	}
+	before := contents[:st.end]
+	if !strings.HasSuffix(before, "\n") {
+		before += nl
+	}
+	return before + indent + key + " = true" + nl + contents[st.end:], nil
+}
+
+// setInlineFeature adds rmcp_client to a `features = { ... }` inline table
+func setInlineFeature(contents string, st tomlStatement, current any, defined bool) (string, error) {
+	if current == true {
+		return contents, nil
+	}
+	if defined {
+		return "", errors.New("features.rmcp_client is set inside an inline table; set it to true or move it to a [features] table")
+	}
+	value := contents[st.valueStart:st.valueEnd]
+	if !strings.HasPrefix(value, "{") {
+		return "", errors.New("features must be a table")
+	}
+	inner := strings.TrimSpace(value[1 : len(value)-1])
+	if inner == "" {
+		value = "{ rmcp_client = true }"
+	} else {
+		value = "{ " + inner + ", rmcp_client = true }"
+	}
+	return contents[:st.valueStart] + value + contents[st.valueEnd:], nil
+}
+
+// upsertCodexServer replaces every definition of mcp_servers.<name> (its
+// table and subtables, dotted keys or an inline table under [mcp_servers])
+// with table. A replaced [mcp_servers.<name>] table keeps its position;
+// otherwise table is appended.
+func upsertCodexServer(contents, name, table string) (string, error) {
+	stmts, err := parseTOMLStatements(contents)
+	if err != nil {
+		return "", err
+	}
+
+	type span struct{ start, end int }
+	var cuts []span
+	at := -1
+	inServer := false
+	for _, st := range stmts {
+		switch st.kind {
+		case tomlTable, tomlArrayTable:
+			wasServer := inServer
+			inServer = hasKeyPrefix(st.key, "mcp_servers", name)
+			switch {
+			case inServer && wasServer:
+				// A subtable continues the block being removed
+				cuts[len(cuts)-1].end = st.end
+			case inServer:
+				if at < 0 && len(cuts) == 0 {
+					at = st.start
+				}
+				cuts = append(cuts, span{st.start, st.end})
+			}
+		case tomlKeyValue:
+			path := st.path()
+			switch {
+			case slices.Equal(path, []string{"mcp_servers"}):
+				return "", errors.New("mcp_servers is an inline table; move its servers to [mcp_servers.<name>] tables first")
+			case inServer:
+				cuts[len(cuts)-1].end = st.end
+			case hasKeyPrefix(path, "mcp_servers", name):
+				cuts = append(cuts, span{st.start, st.end})
+			}
+		}
+	}
+
+	var b strings.Builder
+	prev := 0
+	for _, c := range cuts {
+		b.WriteString(contents[prev:c.start])
+		if c.start == at {
+			b.WriteString(table)
+		}
+		prev = c.end
+	}
+	b.WriteString(contents[prev:])
+	out := b.String()
+	if at >= 0 {
+		return out, nil
+	}
+
+	out = strings.TrimRight(out, "\r\n")
+	if out != "" {
+		nl := tomlLineEnding(contents)
+		out += nl + nl
+	}
+	return out + table, nil
+}
+
+func decodeTOML(contents string) (map[string]any, error) {
+	doc := map[string]any{}
+	if _, err := toml.Decode(contents, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -0,0 +1,218 @@
+package mcpconfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// codexConfigCorpus holds config.toml files in the shapes Codex users write
+var codexConfigCorpus = []struct {
+	name  string
+	input string
+	// keep lists text that must survive the edit untouched
+	keep []string
+}{
+	{
+		name:  "empty",
+		input: "",
+	},
+	{
+		name: "typical",
+		input: `# Codex configuration
+model = "gpt-5-codex"
+model_reasoning_effort = "high"
+approval_policy = "on-request"
+notify = [
+  "python3",
+  "/Users/me/.codex/notify.py",
+]
+
+[projects."/Users/me/code/api"]
+trust_level = "trusted"
+
+[mcp_servers.github]
+command = "npx"
+args = ["-y", "@modelcontextprotocol/server-github"]
+env = { GITHUB_PERSONAL_ACCESS_TOKEN = "ghp_x" }
+
+[tui]
+notifications = true
+`,
+		keep: []string{"# Codex configuration", "notify = [\n  \"python3\",\n", `[projects."/Users/me/code/api"]`, `env = { GITHUB_PERSONAL_ACCESS_TOKEN = "ghp_x" }`, "[tui]\nnotifications = true\n"},
+	},
+	{
+		name: "multi-line arrays starting with brackets",
+		input: `[features]
+web_search_request = true
+
+[sandbox_workspace_write]
+writable_roots = [
+["/tmp"],
+["/var/tmp"],
+]
+network_access = false
+`,
+		keep: []string{"web_search_request = true\nrmcp_client = true\n", "[\"/tmp\"],\n[\"/var/tmp\"],\n]\nnetwork_access = false"},
+	},
+	{
+		name: "multi-line strings",
+		input: `instructions = """
+[features]
+rmcp_client = false
+[mcp_servers.datagen]
+"""
+developer_note = '''
+[[not a table]]
+'''
+`,
+		keep: []string{"\"\"\"\n[features]\nrmcp_client = false\n[mcp_servers.datagen]\n\"\"\"", "'''\n[[not a table]]\n'''"},
+	},
+	{
+		name: "crlf",
+		input: "model = \"o3\"\r\n\r\n[features]\r\nrmcp_client = false\r\n\r\n" +
+			"[mcp_servers.datagen]\r\nurl = \"https://old.example/mcp\"\r\n\r\n[mcp_servers.other]\r\nurl = \"https://example.com/mcp\"\r\n",
+		keep: []string{"model = \"o3\"\r\n", "[mcp_servers.other]\r\nurl = \"https://example.com/mcp\"\r\n"},
+	},
+	{
+		name: "inline tables",
+		input: `features = { web_search_request = true }
+
+[mcp_servers]
+github = { command = "npx", args = ["-y", "@modelcontextprotocol/server-github"] }
+datagen = { url = "https://old.example/mcp", http_headers = { "x-api-key" = "old" } }
+linear = { url = "https://mcp.linear.app/mcp" }
+`,
+		keep: []string{"features = { web_search_request = true, rmcp_client = true }", `github = { command = "npx", args = ["-y", "@modelcontextprotocol/server-github"] }`, "linear = { url"},
+	},
+	{
+		name: "dotted keys",
+		input: `features.rmcp_client = false
+mcp_servers.datagen.url = "https://old.example/mcp"
+mcp_servers.docs.url = "https://docs.example/mcp"
+`,
+		keep: []string{"features.rmcp_client = true\n", `mcp_servers.docs.url = "https://docs.example/mcp"`},
+	},
+	{
+		name: "arrays of tables and subtables",
+		input: `[features]
+# experimental MCP client
+  rmcp_client = false  # flipped by datagen
+
+[[profiles_list]]
+name = "fast"
+
+[mcp_servers.datagen]
+url = "https://old.example/mcp"
+startup_timeout_sec = 20
+
+[mcp_servers.datagen.env]
+TOKEN = "x"
+
+[[mcp_servers.datagen.tools]]
+name = "search"
+
+[[profiles_list]]
+name = "deep"
+`,
+		keep: []string{"# experimental MCP client\n  rmcp_client = true  # flipped by datagen\n", "[[profiles_list]]\nname = \"fast\"\n\n[mcp_servers.datagen]", "\n\n[[profiles_list]]\nname = \"deep\"\n"},
+	},
+	{
+		name: "spaced headers with comments",
+		input: `[ features ]  # toggles
+web_search_request = true
+[ mcp_servers . datagen ] # added by datagen
+url = "https://old.example/mcp"
+[mcp_servers."context7"]
+url = "https://mcp.context7.com/mcp"`,
+		keep: []string{"[ features ]  # toggles\nweb_search_request = true\nrmcp_client = true\n", "[mcp_servers.\"context7\"]\nurl = \"https://mcp.context7.com/mcp\"\n"},
+	},
+}
+
+func TestUpdateCodexConfig_Corpus(t *testing.T) {
+	for _, tc := range codexConfigCorpus {
+		t.Run(tc.name, func(t *testing.T) {
+			out, changed, err := UpdateCodexConfig(tc.input, "", true, "DATAGEN_API_KEY")
+			if err != nil {
+				t.Fatalf("UpdateCodexConfig() error = %v", err)
+			}
+			if !changed {
+				t.Fatalf("expected changed=true")
+			}
+			for _, s := range tc.keep {
+				if !strings.Contains(out, s) {
+					t.Errorf("expected %q preserved, got:\n%s", s, out)
+				}
+			}
+			if strings.Contains(tc.input, "\r\n") && strings.Count(out, "\n") != strings.Count(out, "\r\n") {
+				t.Errorf("expected CRLF line endings throughout, got %q", out)
+			}
+
+			// Everything but the two datagen settings decodes as before
+			want, err := decodeTOML(tc.input)
+			if err != nil {
+				t.Fatalf("decodeTOML(input) error = %v", err)
+			}
+			features, _ := want["features"].(map[string]any)
+			if features == nil {
+				features = map[string]any{}
+				want["features"] = features
+			}
+			features["rmcp_client"] = true
+			servers, _ := want["mcp_servers"].(map[string]any)
+			if servers == nil {
+				servers = map[string]any{}
+				want["mcp_servers"] = servers
+			}
+			servers["datagen"] = map[string]any{
+				"url":              DatagenMCPURL,
+				"env_http_headers": map[string]any{"x-api-key": "DATAGEN_API_KEY"},
+			}
+			got, err := decodeTOML(out)
+			if err != nil {
+				t.Fatalf("output is not valid TOML: %v\n%s", err, out)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("decoded output = %#v\nwant %#v\n%s", got, want, out)
+			}
+
+			again, changed, err := UpdateCodexConfig(out, "", true, "DATAGEN_API_KEY")
+			if err != nil {
+				t.Fatalf("second UpdateCodexConfig() error = %v", err)
+			}
+			if changed || again != out {
+				t.Errorf("expected second run to be a no-op, got:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestUpdateCodexConfig_KeepsTablePosition(t *testing.T) {
+	input := "[mcp_servers.datagen]\nurl = \"https://old.example/mcp\"\n\n[mcp_servers.other]\nurl = \"https://example.com/mcp\"\n\n[features]\nrmcp_client = true\n"
+	out, _, err := UpdateCodexConfig(input, "k", false, "")
+	if err != nil {
+		t.Fatalf("UpdateCodexConfig() error = %v", err)
+	}
+	want := "[mcp_servers.datagen]\nurl = \"" + DatagenMCPURL + "\"\nhttp_headers = { \"x-api-key\" = \"k\" }\n\n[mcp_servers.other]\nurl = \"https://example.com/mcp\"\n\n[features]\nrmcp_client = true\n"
+	if out != want {
+		t.Fatalf("UpdateCodexConfig() =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestUpdateCodexConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"invalid toml", "[features\nrmcp_client = true\n"},
+		{"inline mcp_servers", "mcp_servers = { other = { url = \"https://example.com/mcp\" } }\n"},
+		{"inline rmcp_client", "features = { rmcp_client = false }\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := UpdateCodexConfig(tt.input, "k", false, ""); err == nil {
+				t.Fatalf("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+// Package models is the catalog of Claude models agents can name in their
+// frontmatter or in datagen.toml. The built-in catalog ships with the CLI;
+// Fetch adds the models an Anthropic API key can see today.
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Default is the model generated apps use when neither the prompt nor
+// datagen.toml names one
+const Default = "claude-sonnet-4-5"
+
+const (
+	// DefaultBaseURL is the Anthropic API
+	DefaultBaseURL = "https://api.anthropic.com"
+
+	// DefaultTimeout bounds a live catalog fetch
+	DefaultTimeout = 10 * time.Second
+)
+
+// Status says whether a model can still be used
+type Status string
+
+const (
+	StatusCurrent    Status = "current"
+	StatusDeprecated Status = "deprecated" // still served, with a retirement date announced
+	StatusRetired    Status = "retired"    // requests fail
+	StatusUnknown    Status = "unknown"    // not in the catalog
+)
+
+// Model is one model alias or ID
+type Model struct {
+	ID     string
+	Status Status
+	// Replacement is the current model to move deprecated and retired models to
+	Replacement string
+}
+
+// Catalog is a list of models, aliases first
+type Catalog []Model
+
+// Aliases are the short names the Claude Agent SDK resolves to its current
+// model of each family
+var Aliases = []string{"sonnet", "opus", "haiku"}
+
+// builtin is the catalog as of this release; keep newest models first within
+// a family so Nearest prefers them
+var builtin = Catalog{
+	{ID: "sonnet", Status: StatusCurrent},
+	{ID: "opus", Status: StatusCurrent},
+	{ID: "haiku", Status: StatusCurrent},
+	{ID: "claude-sonnet-4-5", Status: StatusCurrent},
+	{ID: "claude-sonnet-4-5-20250929", Status: StatusCurrent},
+	{ID: "claude-haiku-4-5", Status: StatusCurrent},
+	{ID: "claude-haiku-4-5-20251001", Status: StatusCurrent},
+	{ID: "claude-opus-4-5", Status: StatusCurrent},
+	{ID: "claude-opus-4-5-20251101", Status: StatusCurrent},
+	{ID: "claude-opus-4-1", Status: StatusCurrent},
+	{ID: "claude-opus-4-1-20250805", Status: StatusCurrent},
+	{ID: "claude-opus-4-0", Status: StatusCurrent},
+	{ID: "claude-opus-4-20250514", Status: StatusCurrent},
+	{ID: "claude-sonnet-4", Status: StatusCurrent},
+	{ID: "claude-sonnet-4-0", Status: StatusCurrent},
+	{ID: "claude-sonnet-4-20250514", Status: StatusCurrent},
+	{ID: "claude-3-7-sonnet-latest", Status: StatusDeprecated, Replacement: "claude-sonnet-4-5"},
+	{ID: "claude-3-7-sonnet-20250219", Status: StatusDeprecated, Replacement: "claude-sonnet-4-5"},
+	{ID: "claude-3-5-haiku-latest", Status: StatusDeprecated, Replacement: "claude-haiku-4-5"},
+	{ID: "claude-3-5-haiku-20241022", Status: StatusDeprecated, Replacement: "claude-haiku-4-5"},
+	{ID: "claude-3-haiku-20240307", Status: StatusDeprecated, Replacement: "claude-haiku-4-5"},
+	{ID: "claude-3-5-sonnet-latest", Status: StatusRetired, Replacement: "claude-sonnet-4-5"},
+	{ID: "claude-3-5-sonnet-20241022", Status: StatusRetired, Replacement: "claude-sonnet-4-5"},
+	{ID: "claude-3-5-sonnet-20240620", Status: StatusRetired, Replacement: "claude-sonnet-4-5"},
+	{ID: "claude-3-opus-latest", Status: StatusRetired, Replacement: "claude-opus-4-1"},
+	{ID: "claude-3-opus-20240229", Status: StatusRetired, Replacement: "claude-opus-4-1"},
+	{ID: "claude-3-sonnet-20240229", Status: StatusRetired, Replacement: "claude-sonnet-4-5"},
+	{ID: "claude-2.1", Status: StatusRetired, Replacement: "claude-sonnet-4-5"},
+	{ID: "claude-2.0", Status: StatusRetired, Replacement: "claude-sonnet-4-5"},
+	{ID: "claude-instant-1.2", Status: StatusRetired, Replacement: "claude-haiku-4-5"},
+}
+
+// Price is a model's list price in US dollars per million tokens
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// prices are matched against a model ID by prefix, most specific first;
+// aliases resolve to the newest model of their family
+var prices = []struct {
+	prefix string
+	price  Price
+}{
+	{"opus", Price{5, 25}},
+	{"sonnet", Price{3, 15}},
+	{"haiku", Price{1, 5}},
+	{"claude-opus-4-5", Price{5, 25}},
+	{"claude-opus-4", Price{15, 75}},
+	{"claude-3-opus", Price{15, 75}},
+	{"claude-haiku-4", Price{1, 5}},
+	{"claude-3-5-haiku", Price{0.8, 4}},
+	{"claude-3-haiku", Price{0.25, 1.25}},
+	{"claude-sonnet-4", Price{3, 15}},
+	{"claude-3-7-sonnet", Price{3, 15}},
+	{"claude-3-5-sonnet", Price{3, 15}},
+}
+
+// Pricing returns the list price of model, or false for models it doesn't
+// know (including Bedrock and Vertex model names)
+func Pricing(model string) (Price, bool) {
+	for _, p := range prices {
+		if strings.HasPrefix(model, p.prefix) {
+			return p.price, true
+		}
+	}
+	return Price{}, false
+}
+
+// Cost is the price of a call with the given token counts
+func (p Price) Cost(inputTokens, outputTokens float64) float64 {
+	return (inputTokens*p.Input + outputTokens*p.Output) / 1e6
+}
+
+// Builtin returns the catalog shipped with the CLI
+func Builtin() Catalog {
+	return append(Catalog(nil), builtin...)
+}
+
+// Lookup returns the catalog entry for id
+func (c Catalog) Lookup(id string) (Model, bool) {
+	for _, m := range c {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return Model{}, false
+}
+
+// Canonical returns the catalog model id differs from only in case, spacing
+// or dots for dashes ("Claude Sonnet 4.5" for claude-sonnet-4-5), or ""
+func (c Catalog) Canonical(id string) string {
+	m := strings.ToLower(strings.TrimSpace(id))
+	m = strings.NewReplacer(".", "-", " ", "-", "_", "-").Replace(m)
+	if _, ok := c.Lookup(m); ok {
+		return m
+	}
+	return ""
+}
+
+// Verdict is the result of checking a model name
+type Verdict struct {
+	Model  string
+	Status Status
+	// Suggestion is the current model to use instead: the spelling in the
+	// catalog, the replacement of a deprecated or retired model, or the
+	// nearest current model to an unknown one
+	Suggestion string
+}
+
+// Check looks model up in the catalog
+func (c Catalog) Check(model string) Verdict {
+	v := Verdict{Model: model, Status: StatusUnknown}
+	if m, ok := c.Lookup(model); ok {
+		v.Status, v.Suggestion = m.Status, m.Replacement
+		return v
+	}
+	if canonical := c.Canonical(model); canonical != "" {
+		// Misspelled: suggest the catalog spelling, or the replacement of a
+		// model that is no longer current
+		m, _ := c.Lookup(canonical)
+		v.Suggestion = canonical
+		if m.Status != StatusCurrent {
+			v.Status, v.Suggestion = m.Status, m.Replacement
+		}
+		return v
+	}
+	v.Suggestion = c.Nearest(model)
+	return v
+}
+
+// String describes a verdict that needs attention, or "" for a current model
+func (v Verdict) String() string {
+	switch v.Status {
+	case StatusCurrent:
+		return ""
+	case StatusDeprecated:
+		return fmt.Sprintf("model %q is deprecated; use %q", v.Model, v.Suggestion)
+	case StatusRetired:
+		return fmt.Sprintf("model %q has been retired and no longer serves requests; use %q", v.Model, v.Suggestion)
+	}
+	if v.Suggestion != "" {
+		return fmt.Sprintf("model %q is not a known model; did you mean %q?", v.Model, v.Suggestion)
+	}
+	return fmt.Sprintf("model %q is not a known model (use %s or a Claude model ID)", v.Model, strings.Join(Aliases, ", "))
+}
+
+// Nearest returns the current model closest to model by edit distance, or ""
+// when nothing is reasonably close
+func (c Catalog) Nearest(model string) string {
+	m := strings.ToLower(strings.TrimSpace(model))
+	best, bestDist := "", -1
+	for _, candidate := range c {
+		if candidate.Status != StatusCurrent {
+			continue
+		}
+		d := distance(m, candidate.ID)
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = candidate.ID, d
+		}
+	}
+	// More than a third of the name changed is a different model, not a typo
+	if best == "" || bestDist*3 > len(best) {
+		return ""
+	}
+	return best
+}
+
+// distance is the Levenshtein distance between a and b
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// WithLive returns the catalog plus the live model IDs it lacks, as current
+// models. Models the API lists are never reported retired.
+func (c Catalog) WithLive(ids []string) Catalog {
+	merged := append(Catalog(nil), c...)
+	for _, id := range ids {
+		i := -1
+		for j, m := range merged {
+			if m.ID == id {
+				i = j
+				break
+			}
+		}
+		switch {
+		case i < 0:
+			merged = append(merged, Model{ID: id, Status: StatusCurrent})
+		case merged[i].Status == StatusRetired:
+			merged[i].Status = StatusDeprecated
+		}
+	}
+	return merged
+}
+
+// Fetch lists the model IDs apiKey can use from the Anthropic models endpoint
+// at baseURL, following pagination
+func Fetch(ctx context.Context, client *http.Client, baseURL, apiKey string) ([]string, error) {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	var ids []string
+	after := ""
+	for {
+		q := url.Values{"limit": {"1000"}}
+		if after != "" {
+			q.Set("after_id", after)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(baseURL, "/")+"/v1/models?"+q.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("x-api-key", apiKey)
+		req.Header.Set("anthropic-version", "2023-06-01")
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("could not reach Anthropic: %w", err)
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("Anthropic models endpoint returned %d", resp.StatusCode)
+		}
+		var page struct {
+			Data []struct {
+				ID string `json:"id"`
+			} `json:"data"`
+			HasMore bool   `json:"has_more"`
+			LastID  string `json:"last_id"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("decoding models: %w", err)
+		}
+		for _, m := range page.Data {
+			ids = append(ids, m.ID)
+		}
+		if !page.HasMore || page.LastID == "" {
+			break
+		}
+		after = page.LastID
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		model      string
+		status     Status
+		suggestion string
+	}{
+		{"sonnet", StatusCurrent, ""},
+		{"claude-sonnet-4-5", StatusCurrent, ""},
+		{"Claude Sonnet 4.5", StatusUnknown, "claude-sonnet-4-5"},
+		{"claude-3-5-haiku-20241022", StatusDeprecated, "claude-haiku-4-5"},
+		{"claude-3-5-sonnet-20241022", StatusRetired, "claude-sonnet-4-5"},
+		{"claude-3.5-sonnet-latest", StatusRetired, "claude-sonnet-4-5"},
+		{"claude-sonet-4-5", StatusUnknown, "claude-sonnet-4-5"},
+		{"gpt-4o", StatusUnknown, ""},
+	}
+	for _, tt := range tests {
+		got := Builtin().Check(tt.model)
+		if got.Status != tt.status || got.Suggestion != tt.suggestion {
+			t.Errorf("Check(%q) = %+v, want status %s, suggestion %q", tt.model, got, tt.status, tt.suggestion)
+		}
+		if (got.String() == "") != (tt.status == StatusCurrent) {
+			t.Errorf("Check(%q).String() = %q", tt.model, got.String())
+		}
+	}
+}
+
+func TestWithLive(t *testing.T) {
+	c := Builtin().WithLive([]string{"claude-sonnet-4-6", "claude-3-opus-20240229", "sonnet"})
+	if m, ok := c.Lookup("claude-sonnet-4-6"); !ok || m.Status != StatusCurrent {
+		t.Errorf("live model = %+v, %v, want current", m, ok)
+	}
+	if m, _ := c.Lookup("claude-3-opus-20240229"); m.Status != StatusDeprecated {
+		t.Errorf("retired model still served = %s, want deprecated", m.Status)
+	}
+	if len(c) != len(Builtin())+1 {
+		t.Errorf("len(WithLive()) = %d, want one new model", len(c))
+	}
+}
+
+func TestPricing(t *testing.T) {
+	tests := []struct {
+		model string
+		price Price
+		ok    bool
+	}{
+		{"sonnet", Price{3, 15}, true},
+		{"claude-opus-4-5-20251101", Price{5, 25}, true},
+		{"claude-opus-4-1", Price{15, 75}, true},
+		{"claude-3-5-haiku-20241022", Price{0.8, 4}, true},
+		{"anthropic.claude-sonnet-4-5-20250929-v1:0", Price{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := Pricing(tt.model)
+		if got != tt.price || ok != tt.ok {
+			t.Errorf("Pricing(%q) = %v, %v, want %v, %v", tt.model, got, ok, tt.price, tt.ok)
+		}
+	}
+	if got := (Price{3, 15}).Cost(1000, 500); got != 0.0105 {
+		t.Errorf("Cost() = %v, want 0.0105", got)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			t.Fatalf("path = %s, want /v1/models", r.URL.Path)
+		}
+		if got := r.Header.Get("x-api-key"); got != "sk-ant-test" {
+			t.Fatalf("x-api-key = %q, want sk-ant-test", got)
+		}
+		if r.URL.Query().Get("after_id") == "" {
+			_, _ = w.Write([]byte(`{"data":[{"id":"claude-sonnet-4-6"},{"id":"claude-opus-4-5"}],"has_more":true,"last_id":"claude-opus-4-5"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"id":"claude-haiku-4-5"}],"has_more":false,"last_id":"claude-haiku-4-5"}`))
+	}))
+	defer server.Close()
+
+	got, err := Fetch(context.Background(), server.Client(), server.URL, "sk-ant-test")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	want := []string{"claude-haiku-4-5", "claude-opus-4-5", "claude-sonnet-4-6"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() = %v, want %v", got, want)
+	}
+}
+
+func TestFetchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	if _, err := Fetch(context.Background(), server.Client(), server.URL, "bad"); err == nil {
+		t.Fatal("Fetch() error = nil, want the status")
+	}
+}
@@ -0,0 +1,717 @@
+// Package openapi turns the operations of an OpenAPI 3 document into datagen
+// services, so an existing API contract can be served by agents.
+package openapi
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+	"go.yaml.in/yaml/v3"
+)
+
+// Document is the part of an OpenAPI 3 document that maps onto services
+type Document struct {
+	OpenAPI    string                `yaml:"openapi"`
+	Swagger    string                `yaml:"swagger"`
+	Info       Info                  `yaml:"info"`
+	Paths      Paths                 `yaml:"paths"`
+	Security   []map[string][]string `yaml:"security"`
+	Components Components            `yaml:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+}
+
+// Paths keeps the document's paths in the order they are written
+type Paths []PathEntry
+
+// PathEntry is one path and its operations
+type PathEntry struct {
+	Path string
+	Item PathItem
+}
+
+// PathItem holds the operations of a path; HEAD, OPTIONS and TRACE are not
+// read
+type PathItem struct {
+	Parameters []Parameter `yaml:"parameters"`
+	Get        *Operation  `yaml:"get"`
+	Put        *Operation  `yaml:"put"`
+	Post       *Operation  `yaml:"post"`
+	Patch      *Operation  `yaml:"patch"`
+	Delete     *Operation  `yaml:"delete"`
+}
+
+// Operation is a single API operation
+type Operation struct {
+	OperationID string              `yaml:"operationId"`
+	Summary     string              `yaml:"summary"`
+	Description string              `yaml:"description"`
+	Parameters  []Parameter         `yaml:"parameters"`
+	RequestBody *RequestBody        `yaml:"requestBody"`
+	Responses   map[string]Response `yaml:"responses"`
+	Callbacks   map[string]any      `yaml:"callbacks"`
+	// Security is nil when the operation uses the document's security and
+	// empty when it needs none
+	Security *[]map[string][]string `yaml:"security"`
+}
+
+// Parameter is a path, query, header or cookie parameter
+type Parameter struct {
+	Ref      string  `yaml:"$ref"`
+	Name     string  `yaml:"name"`
+	In       string  `yaml:"in"`
+	Required bool    `yaml:"required"`
+	Schema   *Schema `yaml:"schema"`
+}
+
+// RequestBody is an operation's request body
+type RequestBody struct {
+	Ref      string               `yaml:"$ref"`
+	Required bool                 `yaml:"required"`
+	Content  map[string]MediaType `yaml:"content"`
+}
+
+// Response is one of an operation's responses
+type Response struct {
+	Ref     string               `yaml:"$ref"`
+	Content map[string]MediaType `yaml:"content"`
+}
+
+// MediaType is the schema of one content type
+type MediaType struct {
+	Schema *Schema `yaml:"schema"`
+}
+
+// Schema is the part of a JSON schema that maps onto fields
+type Schema struct {
+	Ref        string     `yaml:"$ref"`
+	Type       SchemaType `yaml:"type"`
+	Properties Properties `yaml:"properties"`
+	Required   []string   `yaml:"required"`
+	AllOf      []*Schema  `yaml:"allOf"`
+}
+
+// SchemaType is a schema's type: one name, or a list in OpenAPI 3.1 such as
+// [string, "null"]
+type SchemaType []string
+
+// UnmarshalYAML reads a single type name or a list of them
+func (t *SchemaType) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*t = SchemaType{n.Value}
+		return nil
+	}
+	var types []string
+	if err := n.Decode(&types); err != nil {
+		return err
+	}
+	*t = types
+	return nil
+}
+
+// Properties keeps a schema's properties in the order they are written
+type Properties []Property
+
+// Property is one named property of an object schema
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Components holds the definitions $ref can point to
+type Components struct {
+	Schemas         map[string]*Schema        `yaml:"schemas"`
+	Parameters      map[string]Parameter      `yaml:"parameters"`
+	RequestBodies   map[string]RequestBody    `yaml:"requestBodies"`
+	Responses       map[string]Response       `yaml:"responses"`
+	SecuritySchemes map[string]SecurityScheme `yaml:"securitySchemes"`
+}
+
+// SecurityScheme is how an API authenticates requests
+type SecurityScheme struct {
+	Type   string `yaml:"type"`   // apiKey, http, oauth2, openIdConnect
+	Name   string `yaml:"name"`   // apiKey header name
+	In     string `yaml:"in"`     // apiKey: header, query or cookie
+	Scheme string `yaml:"scheme"` // http: bearer, basic...
+}
+
+// UnmarshalYAML keeps paths in document order
+func (p *Paths) UnmarshalYAML(n *yaml.Node) error {
+	return decodeOrdered(n, func(key string, value *yaml.Node) error {
+		entry := PathEntry{Path: key}
+		if err := value.Decode(&entry.Item); err != nil {
+			return err
+		}
+		*p = append(*p, entry)
+		return nil
+	})
+}
+
+// UnmarshalYAML keeps properties in document order
+func (p *Properties) UnmarshalYAML(n *yaml.Node) error {
+	return decodeOrdered(n, func(key string, value *yaml.Node) error {
+		prop := Property{Name: key, Schema: &Schema{}}
+		if err := value.Decode(prop.Schema); err != nil {
+			return err
+		}
+		*p = append(*p, prop)
+		return nil
+	})
+}
+
+func decodeOrdered(n *yaml.Node, add func(key string, value *yaml.Node) error) error {
+	if n.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping", n.Line)
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if err := add(n.Content[i].Value, n.Content[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load reads an OpenAPI 3 document in YAML or JSON
+func Load(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// Parse parses an OpenAPI 3 document in YAML or JSON
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing OpenAPI document: %w", err)
+	}
+	if doc.Swagger != "" {
+		return nil, fmt.Errorf("swagger %s documents are not supported; convert the spec to OpenAPI 3 first", doc.Swagger)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("not an OpenAPI 3 document (missing openapi: 3.x)")
+	}
+	if len(doc.Paths) == 0 {
+		return nil, fmt.Errorf("the document has no paths")
+	}
+	return &doc, nil
+}
+
+// Service pairs a proposed datagen service with the operation it came from
+type Service struct {
+	Method  string
+	Path    string
+	Service config.Service
+	// Notes are parts of the operation the service could not keep as-is
+	Notes []string
+}
+
+// Skipped is an operation that did not become a service
+type Skipped struct {
+	Method string
+	Path   string
+	Reason string
+}
+
+// Services maps each operation to a service named after its operationId
+// and served at its path. Operations that stream (text/event-stream or
+// NDJSON responses) become streaming services, operations that only accept
+// work (202 responses or callbacks) become webhooks, and the rest become api
+// services. Path and query parameters and the JSON request body's properties
+// become input fields, and a JSON response's properties the output fields.
+//
+// Generated services all accept POST, so when several operations share a
+// path the first one in POST, PUT, PATCH, DELETE, GET order is kept and the
+// others are skipped.
+func (d *Document) Services() ([]Service, []Skipped, error) {
+	var services []Service
+	var skipped []Skipped
+	names := map[string]bool{}
+	for _, entry := range d.Paths {
+		pathOwner := ""
+		for _, m := range []struct {
+			method string
+			op     *Operation
+		}{
+			{"POST", entry.Item.Post}, {"PUT", entry.Item.Put}, {"PATCH", entry.Item.Patch},
+			{"DELETE", entry.Item.Delete}, {"GET", entry.Item.Get},
+		} {
+			if m.op == nil {
+				continue
+			}
+			if pathOwner != "" {
+				skipped = append(skipped, Skipped{m.method, entry.Path, fmt.Sprintf("path already served by %s", pathOwner)})
+				continue
+			}
+			s, err := d.service(m.method, entry.Path, entry.Item.Parameters, m.op)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s %s: %w", m.method, entry.Path, err)
+			}
+			s.Service.Name = uniqueName(s.Service.Name, names)
+			s.Service.Prompt = fmt.Sprintf(".claude/agents/%s.md", s.Service.Name)
+			pathOwner = s.Service.Name
+			services = append(services, s)
+		}
+	}
+	return services, skipped, nil
+}
+
+func (d *Document) service(method, path string, shared []Parameter, op *Operation) (Service, error) {
+	s := Service{Method: method, Path: path}
+	svc := config.Service{
+		Name:        serviceName(method, path, op.OperationID),
+		Description: firstLine(op.Summary),
+		InputSchema: config.Schema{Fields: []config.Field{}},
+	}
+	if svc.Description == "" {
+		svc.Description = firstLine(op.Description)
+	}
+	if svc.Description == "" {
+		svc.Description = fmt.Sprintf("%s %s", method, path)
+	}
+
+	// Input fields: parameters first, then the request body
+	params := map[string]Parameter{}
+	var order []string
+	for _, list := range [][]Parameter{shared, op.Parameters} {
+		for _, p := range list {
+			p, err := d.parameter(p)
+			if err != nil {
+				return s, err
+			}
+			key := p.In + ":" + p.Name
+			if _, ok := params[key]; !ok {
+				order = append(order, key)
+			}
+			params[key] = p // operation parameters override the path's
+		}
+	}
+	for _, key := range order {
+		p := params[key]
+		if p.In != "path" && p.In != "query" {
+			s.Notes = append(s.Notes, fmt.Sprintf("%s parameter %s is not an input field", p.In, p.Name))
+			continue
+		}
+		field := config.Field{Name: p.Name, Type: d.fieldType(p.Schema, 0), Required: p.Required || p.In == "path"}
+		svc.InputSchema.Fields = d.addField(svc.InputSchema.Fields, field, &s.Notes)
+	}
+	if op.RequestBody != nil {
+		body, err := d.requestBody(*op.RequestBody)
+		if err != nil {
+			return s, err
+		}
+		if schema, ok := jsonSchema(body.Content); ok {
+			fields, err := d.objectFields(schema)
+			if err != nil {
+				return s, err
+			}
+			if fields == nil {
+				// Not an object: the whole body is one field
+				fields = []config.Field{{Name: "body", Type: d.fieldType(schema, 0), Required: body.Required}}
+			}
+			for _, f := range fields {
+				svc.InputSchema.Fields = d.addField(svc.InputSchema.Fields, f, &s.Notes)
+			}
+		} else if len(body.Content) > 0 {
+			s.Notes = append(s.Notes, fmt.Sprintf("%s request body is not an input field", contentTypes(body.Content)))
+		}
+	}
+
+	// Type from the success responses
+	success, err := d.successResponses(op.Responses)
+	if err != nil {
+		return s, err
+	}
+	streaming, framing := streams(success)
+	_, returns := success["200"]
+	_, created := success["201"]
+	_, accepted := success["202"]
+	switch {
+	case streaming:
+		svc.Type = "streaming"
+		svc.APIPath = path
+		svc.Streaming = &config.StreamingConfig{Format: "default", BufferSize: 8192, Framing: framing}
+	case len(op.Callbacks) > 0 || (accepted && !returns && !created):
+		svc.Type = "webhook"
+		svc.WebhookPath = path
+		svc.Webhook = &config.WebhookConfig{SignatureVerification: "none"}
+	default:
+		svc.Type = "api"
+		svc.APIPath = path
+		svc.API = &config.APIConfig{ResponseFormat: "json", Timeout: 30}
+		if err := d.outputSchema(&svc, success); err != nil {
+			return s, err
+		}
+	}
+
+	auth, note := d.auth(op, svc.Name)
+	svc.Auth = auth
+	if note != "" {
+		s.Notes = append(s.Notes, note)
+	}
+	s.Service = svc
+	return s, nil
+}
+
+// outputSchema sets an api service's output fields and response format from
+// its 200 or 201 response
+func (d *Document) outputSchema(svc *config.Service, success map[string]Response) error {
+	resp, ok := success["200"]
+	if !ok {
+		resp = success["201"]
+	}
+	if schema, ok := jsonSchema(resp.Content); ok {
+		fields, err := d.objectFields(schema)
+		if err != nil {
+			return err
+		}
+		if len(fields) > 0 {
+			svc.OutputSchema = &config.Schema{Fields: fields}
+		}
+		return nil
+	}
+	if _, ok := resp.Content["text/plain"]; ok {
+		svc.API.ResponseFormat = "text"
+	}
+	return nil
+}
+
+// successResponses returns the 2xx responses by status code, with $refs
+// resolved
+func (d *Document) successResponses(responses map[string]Response) (map[string]Response, error) {
+	success := map[string]Response{}
+	for code, r := range responses {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		if r.Ref != "" {
+			name, err := refName(r.Ref, "responses")
+			if err != nil {
+				return nil, err
+			}
+			resolved, ok := d.Components.Responses[name]
+			if !ok {
+				return nil, fmt.Errorf("$ref %q: no such response", r.Ref)
+			}
+			r = resolved
+		}
+		success[code] = r
+	}
+	return success, nil
+}
+
+// streams reports whether any response streams events, and their framing
+func streams(responses map[string]Response) (bool, string) {
+	for _, r := range responses {
+		for contentType := range r.Content {
+			switch mediaType(contentType) {
+			case "text/event-stream":
+				return true, config.FramingSSE
+			case "application/x-ndjson", "application/ndjson", "application/jsonl":
+				return true, config.FramingNDJSON
+			}
+		}
+	}
+	return false, ""
+}
+
+// auth maps the operation's first security scheme to service auth
+func (d *Document) auth(op *Operation, service string) (*config.Auth, string) {
+	requirements := d.Security
+	if op.Security != nil {
+		requirements = *op.Security
+	}
+	if len(requirements) == 0 || len(requirements[0]) == 0 {
+		return nil, ""
+	}
+	names := make([]string, 0, len(requirements[0]))
+	for name := range requirements[0] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	name := names[0]
+	scheme, ok := d.Components.SecuritySchemes[name]
+	envVar := config.NormalizeEnvVarName(snakeCase(name))
+	switch {
+	case !ok:
+		return nil, fmt.Sprintf("security scheme %s is not defined; no auth", name)
+	case scheme.Type == "apiKey" && scheme.In == "header":
+		return &config.Auth{Type: "api_key", Header: scheme.Name, EnvVar: envVar}, ""
+	case scheme.Type == "apiKey":
+		return &config.Auth{Type: "api_key", Header: "X-API-Key", EnvVar: envVar}, fmt.Sprintf("%s api key is read from the X-API-Key header instead of the %s", name, scheme.In)
+	case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"):
+		return &config.Auth{Type: "bearer_token", Header: "Authorization", EnvVar: envVar}, ""
+	case scheme.Type == "oauth2" || scheme.Type == "openIdConnect":
+		return &config.Auth{Type: "oauth", Header: "Authorization", EnvVar: envVar}, ""
+	}
+	return nil, fmt.Sprintf("%s %s authentication is not supported; no auth", scheme.Type, scheme.Scheme)
+}
+
+func (d *Document) parameter(p Parameter) (Parameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	name, err := refName(p.Ref, "parameters")
+	if err != nil {
+		return p, err
+	}
+	resolved, ok := d.Components.Parameters[name]
+	if !ok {
+		return p, fmt.Errorf("$ref %q: no such parameter", p.Ref)
+	}
+	return resolved, nil
+}
+
+func (d *Document) requestBody(b RequestBody) (RequestBody, error) {
+	if b.Ref == "" {
+		return b, nil
+	}
+	name, err := refName(b.Ref, "requestBodies")
+	if err != nil {
+		return b, err
+	}
+	resolved, ok := d.Components.RequestBodies[name]
+	if !ok {
+		return b, fmt.Errorf("$ref %q: no such request body", b.Ref)
+	}
+	return resolved, nil
+}
+
+// maxDepth bounds $ref and allOf resolution, so self-referencing schemas end
+const maxDepth = 16
+
+// resolve follows a schema's $ref
+func (d *Document) resolve(s *Schema, depth int) (*Schema, error) {
+	for s != nil && s.Ref != "" {
+		if depth > maxDepth {
+			return nil, fmt.Errorf("$ref %q: too deeply nested", s.Ref)
+		}
+		name, err := refName(s.Ref, "schemas")
+		if err != nil {
+			return nil, err
+		}
+		resolved, ok := d.Components.Schemas[name]
+		if !ok {
+			return nil, fmt.Errorf("$ref %q: no such schema", s.Ref)
+		}
+		s = resolved
+		depth++
+	}
+	return s, nil
+}
+
+// objectFields returns the fields of an object schema, merging allOf
+// parts, or nil when the schema is not an object
+func (d *Document) objectFields(s *Schema) ([]config.Field, error) {
+	return d.mergeFields(s, 0)
+}
+
+func (d *Document) mergeFields(s *Schema, depth int) ([]config.Field, error) {
+	s, err := d.resolve(s, depth)
+	if err != nil || s == nil || depth > maxDepth {
+		return nil, err
+	}
+	var fields []config.Field
+	for _, part := range s.AllOf {
+		partFields, err := d.mergeFields(part, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, partFields...)
+	}
+	if len(s.Properties) == 0 && !s.Type.is("object") {
+		return fields, nil
+	}
+	if fields == nil {
+		fields = []config.Field{}
+	}
+	required := map[string]bool{}
+	for _, name := range s.Required {
+		required[name] = true
+	}
+	for _, p := range s.Properties {
+		fields = append(fields, config.Field{Name: p.Name, Type: d.fieldType(p.Schema, depth+1), Required: required[p.Name]})
+	}
+	return fields, nil
+}
+
+// fieldType maps a schema to a datagen field type
+func (d *Document) fieldType(s *Schema, depth int) string {
+	s, err := d.resolve(s, depth)
+	if err != nil || s == nil {
+		return "any"
+	}
+	for _, t := range s.Type {
+		switch t {
+		case "string":
+			return "str"
+		case "integer":
+			return "int"
+		case "number":
+			return "float"
+		case "boolean":
+			return "bool"
+		case "array":
+			return "list"
+		case "object":
+			return "dict"
+		}
+	}
+	if len(s.Properties) > 0 {
+		return "dict"
+	}
+	if len(s.AllOf) == 1 && depth < maxDepth {
+		return d.fieldType(s.AllOf[0], depth+1)
+	}
+	return "any"
+}
+
+func (t SchemaType) is(name string) bool {
+	for _, n := range t {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// addField appends f, renaming it when its name is not a Python identifier
+// and skipping it when the service already has a field of that name
+func (d *Document) addField(fields []config.Field, f config.Field, notes *[]string) []config.Field {
+	if name := pythonName(f.Name); name != f.Name {
+		*notes = append(*notes, fmt.Sprintf("field %s is renamed %s", f.Name, name))
+		f.Name = name
+	}
+	for _, existing := range fields {
+		if existing.Name == f.Name {
+			*notes = append(*notes, fmt.Sprintf("field %s appears more than once; keeping the first", f.Name))
+			return fields
+		}
+	}
+	return append(fields, f)
+}
+
+// jsonSchema returns the schema of a JSON content type
+func jsonSchema(content map[string]MediaType) (*Schema, bool) {
+	for contentType, media := range content {
+		t := mediaType(contentType)
+		if (t == "application/json" || strings.HasSuffix(t, "+json")) && media.Schema != nil {
+			return media.Schema, true
+		}
+	}
+	return nil, false
+}
+
+func contentTypes(content map[string]MediaType) string {
+	types := make([]string, 0, len(content))
+	for t := range content {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return strings.Join(types, ", ")
+}
+
+func mediaType(contentType string) string {
+	t, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(t))
+}
+
+// refName returns the component a local $ref such as
+// #/components/schemas/User names
+func refName(ref, kind string) (string, error) {
+	prefix := "#/components/" + kind + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return "", fmt.Errorf("$ref %q: only local %s references are supported", ref, prefix)
+	}
+	return strings.TrimPrefix(ref, prefix), nil
+}
+
+// serviceName names a service after its operationId, or its method and path
+func serviceName(method, path, operationID string) string {
+	if operationID != "" {
+		return config.NormalizeServiceName(snakeCase(operationID))
+	}
+	parts := []string{strings.ToLower(method)}
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.Trim(segment, "{}")
+		if segment != "" {
+			parts = append(parts, snakeCase(segment))
+		}
+	}
+	return config.NormalizeServiceName(strings.Join(parts, "_"))
+}
+
+// uniqueName returns name, or name with a number appended when it is taken
+func uniqueName(name string, taken map[string]bool) string {
+	unique := name
+	for i := 2; taken[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", name, i)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// snakeCase splits camelCase words with underscores: getUserById becomes
+// get_User_By_Id, which NormalizeServiceName lowercases
+func snakeCase(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+			(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// pythonKeywords cannot be used as pydantic field names
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true, "async": true,
+	"await": true, "break": true, "class": true, "continue": true, "def": true, "del": true, "elif": true,
+	"else": true, "except": true, "finally": true, "for": true, "from": true, "global": true, "if": true,
+	"import": true, "in": true, "is": true, "lambda": true, "nonlocal": true, "not": true, "or": true,
+	"pass": true, "raise": true, "return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+// pythonName turns a property name into a Python identifier
+func pythonName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if r == '_' || (r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))) {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	out := strings.TrimLeft(b.String(), "_")
+	if out == "" {
+		out = "field"
+	}
+	if unicode.IsDigit(rune(out[0])) {
+		out = "field_" + out
+	}
+	if pythonKeywords[out] {
+		out += "_"
+	}
+	return out
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(line)
+}
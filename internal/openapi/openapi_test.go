@@ -0,0 +1,217 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+const petstore = `openapi: 3.1.0
+info:
+  title: Pets
+security:
+  - ApiKeyAuth: []
+paths:
+  /pets/{petId}:
+    parameters:
+      - $ref: '#/components/parameters/PetId'
+    get:
+      operationId: getPetById
+      summary: Find a pet
+      responses:
+        "200":
+          description: The pet
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+    post:
+      operationId: updatePet
+      description: |
+        Update a pet.
+        Fields not sent are left alone.
+      parameters:
+        - name: X-Request-Id
+          in: header
+          schema: {type: string}
+        - name: dry-run
+          in: query
+          schema: {type: boolean}
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              allOf:
+                - $ref: '#/components/schemas/Pet'
+                - type: object
+                  required: [reason]
+                  properties:
+                    reason: {type: string}
+      responses:
+        "200":
+          $ref: '#/components/responses/PetResponse'
+  /pets/import:
+    post:
+      operationId: importPets
+      security: []
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: array
+              items: {$ref: '#/components/schemas/Pet'}
+      responses:
+        "202":
+          description: Accepted
+  /pets/feed:
+    get:
+      summary: Stream pet events
+      security:
+        - Bearer: []
+      responses:
+        "200":
+          description: Events
+          content:
+            text/event-stream:
+              schema: {type: string}
+components:
+  parameters:
+    PetId:
+      name: petId
+      in: path
+      required: true
+      schema: {type: integer}
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string}
+        tags: {type: array, items: {type: string}}
+        weight: {type: [number, "null"]}
+        owner: {$ref: '#/components/schemas/Owner'}
+    Owner:
+      type: object
+      properties:
+        email: {type: string}
+  responses:
+    PetResponse:
+      description: The pet
+      content:
+        application/json:
+          schema: {$ref: '#/components/schemas/Pet'}
+  securitySchemes:
+    ApiKeyAuth: {type: apiKey, in: header, name: X-Pets-Key}
+    Bearer: {type: http, scheme: bearer}
+`
+
+func TestServices(t *testing.T) {
+	doc, err := Parse([]byte(petstore))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	services, skipped, err := doc.Services()
+	if err != nil {
+		t.Fatalf("Services: %v", err)
+	}
+
+	pet := []config.Field{
+		{Name: "name", Type: "str", Required: true},
+		{Name: "tags", Type: "list"},
+		{Name: "weight", Type: "float"},
+		{Name: "owner", Type: "dict"},
+	}
+	apiKey := &config.Auth{Type: "api_key", Header: "X-Pets-Key", EnvVar: "API_KEY_AUTH"}
+	want := []config.Service{
+		{
+			Name:        "update_pet",
+			Type:        "api",
+			Description: "Update a pet.",
+			Prompt:      ".claude/agents/update_pet.md",
+			APIPath:     "/pets/{petId}",
+			InputSchema: config.Schema{Fields: append([]config.Field{
+				{Name: "petId", Type: "int", Required: true},
+				{Name: "dry_run", Type: "bool"},
+			}, append(pet, config.Field{Name: "reason", Type: "str", Required: true})...)},
+			OutputSchema: &config.Schema{Fields: pet},
+			Auth:         apiKey,
+			API:          &config.APIConfig{ResponseFormat: "json", Timeout: 30},
+		},
+		{
+			Name:        "import_pets",
+			Type:        "webhook",
+			Description: "POST /pets/import",
+			Prompt:      ".claude/agents/import_pets.md",
+			WebhookPath: "/pets/import",
+			InputSchema: config.Schema{Fields: []config.Field{{Name: "body", Type: "list"}}},
+			Webhook:     &config.WebhookConfig{SignatureVerification: "none"},
+		},
+		{
+			Name:        "get_pets_feed",
+			Type:        "streaming",
+			Description: "Stream pet events",
+			Prompt:      ".claude/agents/get_pets_feed.md",
+			APIPath:     "/pets/feed",
+			InputSchema: config.Schema{Fields: []config.Field{}},
+			Auth:        &config.Auth{Type: "bearer_token", Header: "Authorization", EnvVar: "BEARER"},
+			Streaming:   &config.StreamingConfig{Format: "default", BufferSize: 8192, Framing: config.FramingSSE},
+		},
+	}
+	if len(services) != len(want) {
+		t.Fatalf("got %d services, want %d: %+v", len(services), len(want), services)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(services[i].Service, want[i]) {
+			t.Errorf("service %d =\n %+v\nwant\n %+v", i, services[i].Service, want[i])
+		}
+	}
+	if notes := strings.Join(services[0].Notes, "; "); notes != "header parameter X-Request-Id is not an input field; field dry-run is renamed dry_run" {
+		t.Errorf("notes = %q", notes)
+	}
+	if len(skipped) != 1 || skipped[0].Method != "GET" || skipped[0].Reason != "path already served by update_pet" {
+		t.Errorf("skipped = %+v, want GET /pets/{petId}", skipped)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{name: "swagger 2", doc: "swagger: '2.0'\npaths: {}", wantErr: "convert the spec to OpenAPI 3"},
+		{name: "not openapi", doc: `{"name": "package.json"}`, wantErr: "not an OpenAPI 3 document"},
+		{name: "no paths", doc: `{"openapi": "3.0.3", "paths": {}}`, wantErr: "no paths"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.doc)); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Parse error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	doc, err := Parse([]byte(`{"openapi": "3.0.3", "paths": {"/a": {"post": {"requestBody": {"content": {"application/json": {"schema": {"$ref": "other.yaml#/Pet"}}}}}}}}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if _, _, err := doc.Services(); err == nil || !strings.Contains(err.Error(), "only local #/components/schemas/ references") {
+		t.Fatalf("Services error = %v, want an external $ref error", err)
+	}
+}
+
+func TestServiceName(t *testing.T) {
+	tests := []struct{ method, path, id, want string }{
+		{"GET", "/pets/{petId}", "getPetByID", "get_pet_by_id"},
+		{"POST", "/v1/HTTPServer-events", "", "post_v1_http_server_events"},
+		{"POST", "/chat", "chat.completions.create", "chat_completions_create"},
+	}
+	for _, tt := range tests {
+		if got := serviceName(tt.method, tt.path, tt.id); got != tt.want {
+			t.Errorf("serviceName(%s, %s, %q) = %q, want %q", tt.method, tt.path, tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+// Package output is the shared layer commands print progress, warnings and
+// external command traces through, so --quiet, --verbose and --no-color
+// apply everywhere.
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2/core"
+	"github.com/datagendev/datagen-cli/internal/debuglog"
+)
+
+var (
+	// Quiet suppresses progress output; results, warnings and errors still print
+	Quiet bool
+	// Verbose traces every external command datagen runs, with timing
+	Verbose bool
+	// NoColor disables ANSI colors, including in interactive prompts.
+	// Defaults to on when NO_COLOR is set (https://no-color.org).
+	NoColor = os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb"
+)
+
+// Stderr is where warnings and traces are written
+var Stderr io.Writer = os.Stderr
+
+const redacted = "***"
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Configure checks the flag combination and applies NoColor to prompts.
+// Call it once flags are parsed.
+func Configure() error {
+	if Quiet && Verbose {
+		return errors.New("--quiet and --verbose cannot be used together")
+	}
+	core.DisableColor = NoColor
+	return nil
+}
+
+// Progress returns where progress output goes: stdout, or nowhere in quiet
+// mode
+func Progress() io.Writer {
+	if Quiet {
+		return io.Discard
+	}
+	return os.Stdout
+}
+
+// Printf prints progress output
+func Printf(format string, args ...any) {
+	fmt.Fprintf(Progress(), format, args...)
+}
+
+// Println prints a line of progress output
+func Println(args ...any) {
+	fmt.Fprintln(Progress(), args...)
+}
+
+// Warnf prints a warning to stderr, even in quiet mode
+func Warnf(format string, args ...any) {
+	fmt.Fprintf(Stderr, "%s %s\n", yellow("Warning:"), strings.TrimSuffix(fmt.Sprintf(format, args...), "\n"))
+}
+
+// Debugf prints a diagnostic line to stderr in verbose mode
+func Debugf(format string, args ...any) {
+	if Verbose {
+		fmt.Fprintf(Stderr, "%s\n", dim(strings.TrimSuffix(fmt.Sprintf(format, args...), "\n")))
+	}
+}
+
+// Trace logs cmd in verbose mode and to the --debug session log, and returns
+// a function that logs how it ended. Call it before cmd starts and use it
+// around cmd.Run or Start/Wait:
+//
+//	done := output.Trace(cmd)
+//	err := cmd.Run()
+//	done(err)
+//
+// Values of NAME=value arguments and any redact values are masked.
+func Trace(cmd *exec.Cmd, redact ...string) func(error) {
+	done := trace(cmd, redact)
+	return func(err error) { done(err, nil) }
+}
+
+func trace(cmd *exec.Cmd, redact []string) func(error, []byte) {
+	args := maskArgs(cmd.Args, redact)
+	logged := debuglog.Command(cmd, args, redact...)
+	if Verbose {
+		line := "$ " + strings.Join(args, " ")
+		if cmd.Dir != "" {
+			line += "  (in " + cmd.Dir + ")"
+		}
+		Debugf("%s", line)
+	}
+	start := time.Now()
+	return func(err error, out []byte) {
+		logged(err, out)
+		if !Verbose {
+			return
+		}
+		elapsed := time.Since(start).Round(time.Millisecond)
+		if err != nil {
+			Debugf("  ↳ %s failed after %s: %v", cmd.Args[0], elapsed, err)
+			return
+		}
+		Debugf("  ↳ %s finished in %s", cmd.Args[0], elapsed)
+	}
+}
+
+// maskArgs hides values that may be secrets from a traced command line
+func maskArgs(args, redact []string) []string {
+	masked := make([]string, len(args))
+	for i, arg := range args {
+		if name, _, ok := strings.Cut(arg, "="); ok && envNamePattern.MatchString(name) {
+			arg = name + "=" + redacted
+		}
+		for _, v := range redact {
+			if v != "" {
+				arg = strings.ReplaceAll(arg, v, redacted)
+			}
+		}
+		masked[i] = arg
+	}
+	return masked
+}
+
+// Run runs cmd, tracing it
+func Run(cmd *exec.Cmd, redact ...string) error {
+	done := Trace(cmd, redact...)
+	err := cmd.Run()
+	done(err)
+	return err
+}
+
+// Start starts cmd without waiting for it, tracing the launch
+func Start(cmd *exec.Cmd, redact ...string) error {
+	args := maskArgs(cmd.Args, redact)
+	logged := debuglog.Command(cmd, args, redact...)
+	if Verbose {
+		Debugf("$ %s  (in background)", strings.Join(args, " "))
+	}
+	err := cmd.Start()
+	if err != nil && Verbose {
+		Debugf("  ↳ %s failed to start: %v", cmd.Args[0], err)
+	}
+	logged(err, nil)
+	return err
+}
+
+// Output runs cmd and returns its stdout, tracing it
+func Output(cmd *exec.Cmd, redact ...string) ([]byte, error) {
+	done := trace(cmd, redact)
+	out, err := cmd.Output()
+	done(err, out)
+	return out, err
+}
+
+// CombinedOutput runs cmd and returns its stdout and stderr, tracing it
+func CombinedOutput(cmd *exec.Cmd, redact ...string) ([]byte, error) {
+	done := trace(cmd, redact)
+	out, err := cmd.CombinedOutput()
+	done(err, out)
+	return out, err
+}
+
+// colorize wraps s in an ANSI color code when colors are on and stderr, the
+// only stream datagen colors, is a terminal
+func colorize(code, s string) string {
+	if NoColor || !isTerminal(os.Stderr) {
+		return s
+	}
+	return "\033[" + code + "m" + s + "\033[0m"
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func yellow(s string) string { return colorize("33", s) }
+
+func dim(s string) string { return colorize("2", s) }
@@ -0,0 +1,63 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestConfigure_QuietAndVerbose(t *testing.T) {
+	defer func() { Quiet, Verbose = false, false }()
+	Quiet, Verbose = true, true
+	if err := Configure(); err == nil {
+		t.Fatal("expected an error for --quiet with --verbose")
+	}
+}
+
+func TestMaskArgs(t *testing.T) {
+	got := maskArgs([]string{"railway", "variables", "--set", "API_KEY=sk-123", "--service", "web"}, nil)
+	want := "railway variables --set API_KEY=*** --service web"
+	if strings.Join(got, " ") != want {
+		t.Errorf("maskArgs() = %q, want %q", strings.Join(got, " "), want)
+	}
+
+	got = maskArgs([]string{"setx", "DATAGEN_API_KEY", "dg_secret"}, []string{"dg_secret"})
+	if strings.Join(got, " ") != "setx DATAGEN_API_KEY ***" {
+		t.Errorf("maskArgs() with redact = %q", strings.Join(got, " "))
+	}
+}
+
+func TestTrace(t *testing.T) {
+	var buf bytes.Buffer
+	Stderr = &buf
+	defer func() { Stderr, Verbose = os.Stderr, false }()
+
+	cmd := exec.Command("railway", "up", "--detach")
+	cmd.Dir = "/tmp/app"
+
+	Trace(cmd)(nil)
+	if buf.Len() != 0 {
+		t.Fatalf("expected no trace without --verbose, got %q", buf.String())
+	}
+
+	Verbose = true
+	Trace(cmd)(nil)
+	out := buf.String()
+	if !strings.Contains(out, "$ railway up --detach  (in /tmp/app)") || !strings.Contains(out, "railway finished in") {
+		t.Errorf("unexpected trace: %q", out)
+	}
+}
+
+func TestWarnf_PrintsWhenQuiet(t *testing.T) {
+	var buf bytes.Buffer
+	Stderr = &buf
+	Quiet = true
+	defer func() { Stderr, Quiet = os.Stderr, false }()
+
+	Warnf("could not record history: %v\n", "disk full")
+	if buf.String() != "Warning: could not record history: disk full\n" {
+		t.Errorf("Warnf() wrote %q", buf.String())
+	}
+}
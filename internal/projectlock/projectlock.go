@@ -0,0 +1,184 @@
+// Package projectlock serializes the commands that modify a generated project
+// (build, add, edit, sync, repair) with a lockfile in its .datagen directory,
+// and notices when a command died holding it, possibly mid-write.
+package projectlock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// File is the lock's path relative to the project directory
+const File = ".datagen/lock"
+
+var (
+	// Wait is how long Acquire waits for another command to release the lock
+	Wait = 30 * time.Second
+	// StaleAfter is how old a lock taken on another host (which cannot be
+	// checked for a live process) must be before it counts as abandoned
+	StaleAfter = 30 * time.Minute
+
+	pollInterval = 200 * time.Millisecond
+	hostname     = func() string { h, _ := os.Hostname(); return h }
+)
+
+// Holder is the command a lock was taken by
+type Holder struct {
+	PID     int       `json:"pid"`
+	Host    string    `json:"host"`
+	Command string    `json:"command"`
+	Started time.Time `json:"started"`
+}
+
+func (h *Holder) String() string {
+	if h.PID == 0 {
+		return "a datagen command"
+	}
+	return fmt.Sprintf("'datagen %s' (pid %d on %s, started %s)", h.Command, h.PID, h.Host, h.Started.Local().Format("15:04:05"))
+}
+
+// HeldError is returned by Acquire when another command still holds the lock
+// after Wait
+type HeldError struct {
+	Path   string
+	Holder *Holder
+}
+
+func (e *HeldError) Error() string {
+	return fmt.Sprintf("%s is modifying the project; wait for it to finish, or delete %s if it is no longer running", e.Holder, e.Path)
+}
+
+// Lock is a held project lock
+type Lock struct {
+	path string
+	// Abandoned is set when Acquire took over a lock whose command had died
+	// without releasing it, so the project may hold half-written files
+	Abandoned *Holder
+}
+
+// Acquire takes dir's lock for command. While a live command holds it,
+// Acquire calls waiting once with its holder and retries until Wait has
+// passed, then returns a *HeldError.
+func Acquire(dir, command string, waiting func(*Holder)) (*Lock, error) {
+	path := filepath.Join(dir, File)
+	me := Holder{PID: os.Getpid(), Host: hostname(), Command: command, Started: time.Now().UTC()}
+	data, err := json.Marshal(me)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &Lock{path: path}
+	deadline := time.Now().Add(Wait)
+	warned := false
+	for {
+		// Each time round: a released lock takes the emptied .datagen with it
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = f.Write(append(data, '\n'))
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, err
+			}
+			return lock, nil
+		}
+		if os.IsNotExist(err) {
+			continue // .datagen removed by a release since MkdirAll
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		holder, err := readHolder(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue // released between our attempts
+		}
+		if err != nil {
+			return nil, err
+		}
+		if abandoned(holder) {
+			lock.Abandoned = holder
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, &HeldError{Path: path, Holder: holder}
+		}
+		if !warned && waiting != nil {
+			waiting(holder)
+			warned = true
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// Release removes the lock, and the .datagen directory when nothing else is
+// in it
+func (l *Lock) Release() error {
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	os.Remove(filepath.Dir(l.path))
+	return nil
+}
+
+// readHolder reads the lock at path. A lock left empty or partly written by a
+// command that died while taking it reads as a holder with no PID, aged by the
+// file's modification time.
+func readHolder(path string) (*Holder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var h Holder
+	if err := json.Unmarshal(data, &h); err != nil || h.PID == 0 {
+		info, statErr := os.Stat(path)
+		if statErr != nil {
+			return nil, statErr
+		}
+		return &Holder{Started: info.ModTime().UTC()}, nil
+	}
+	return &h, nil
+}
+
+// abandoned reports whether h's command is gone: its process no longer runs
+// on this host, it was taken on another host longer than StaleAfter ago, or
+// its lockfile has stayed unreadable for more than a few seconds
+func abandoned(h *Holder) bool {
+	switch {
+	case h.PID == 0:
+		return time.Since(h.Started) > 5*time.Second
+	case h.Host == hostname():
+		return !processAlive(h.PID)
+	default:
+		return time.Since(h.Started) > StaleAfter
+	}
+}
+
+// processAlive reports whether a process with pid runs on this machine
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// On Windows FindProcess opens the process, so it only succeeds while it runs
+	if runtime.GOOS == "windows" {
+		p.Release()
+		return true
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,135 @@
+package projectlock
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeLock(t *testing.T, dir string, h Holder) string {
+	t.Helper()
+	path := filepath.Join(dir, File)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAcquireRelease(t *testing.T) {
+	dir := t.TempDir()
+	lock, err := Acquire(dir, "build", nil)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if lock.Abandoned != nil {
+		t.Errorf("Abandoned = %v, want nil for a fresh lock", lock.Abandoned)
+	}
+	h, err := readHolder(filepath.Join(dir, File))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.PID != os.Getpid() || h.Command != "build" {
+		t.Errorf("holder = %+v, want this process running build", h)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".datagen")); !os.IsNotExist(err) {
+		t.Errorf(".datagen should be removed once empty, stat error = %v", err)
+	}
+}
+
+func TestAcquireWaitsForLiveHolder(t *testing.T) {
+	defer func(w time.Duration) { Wait = w }(Wait)
+	Wait = 300 * time.Millisecond
+
+	dir := t.TempDir()
+	writeLock(t, dir, Holder{PID: os.Getpid(), Host: hostname(), Command: "add", Started: time.Now()})
+
+	var waitedFor *Holder
+	_, err := Acquire(dir, "build", func(h *Holder) { waitedFor = h })
+	var held *HeldError
+	if !errors.As(err, &held) {
+		t.Fatalf("Acquire() error = %v, want *HeldError", err)
+	}
+	if held.Holder.Command != "add" || waitedFor == nil || waitedFor.Command != "add" {
+		t.Errorf("held by %v, waited for %v; want the add command", held.Holder, waitedFor)
+	}
+}
+
+func TestAcquireWaitsUntilReleased(t *testing.T) {
+	dir := t.TempDir()
+	first, err := Acquire(dir, "add", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		first.Release()
+	}()
+	second, err := Acquire(dir, "build", nil)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v, want the lock once add released it", err)
+	}
+	second.Release()
+}
+
+func TestAcquireTakesOverAbandonedLock(t *testing.T) {
+	// A process that has exited, so its pid is (almost certainly) not running
+	exited := exec.Command(os.Args[0], "-test.run=^$")
+	if err := exited.Run(); err != nil {
+		t.Fatal(err)
+	}
+	pid := exited.Process.Pid
+
+	tests := []struct {
+		name string
+		lock func(t *testing.T, dir string)
+	}{
+		{"dead process", func(t *testing.T, dir string) {
+			writeLock(t, dir, Holder{PID: pid, Host: hostname(), Command: "add", Started: time.Now()})
+		}},
+		{"old lock from another host", func(t *testing.T, dir string) {
+			writeLock(t, dir, Holder{PID: os.Getpid(), Host: "elsewhere", Command: "add", Started: time.Now().Add(-time.Hour)})
+		}},
+		{"unreadable old lock", func(t *testing.T, dir string) {
+			path := filepath.Join(dir, File)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(`{"pid":`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			old := time.Now().Add(-time.Minute)
+			if err := os.Chtimes(path, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.lock(t, dir)
+			lock, err := Acquire(dir, "build", nil)
+			if err != nil {
+				t.Fatalf("Acquire() error = %v", err)
+			}
+			defer lock.Release()
+			if lock.Abandoned == nil {
+				t.Error("Abandoned = nil, want the previous holder")
+			}
+		})
+	}
+}
@@ -2,29 +2,50 @@ package prompts
 
 import (
 	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/datagendev/datagen-cli/internal/config"
 )
 
-// CollectServiceConfig interactively collects configuration for a service
-func CollectServiceConfig() (*config.Service, error) {
-	svc := &config.Service{
-		InputSchema: config.Schema{Fields: []config.Field{}},
+// CollectServiceConfig interactively collects configuration for a service.
+// Settings defaults covers are offered as-is and left unset when accepted, so
+// the service inherits them; defaults may be nil.
+func CollectServiceConfig(defaults *config.ServiceDefaults) (*config.Service, error) {
+	return collectService(&config.Service{}, defaults)
+}
+
+// EditServiceConfig asks the CollectServiceConfig questions again with
+// current's settings as the answers to keep, and returns the edited service.
+// The name stays the same; current is not modified.
+func EditServiceConfig(current *config.Service, defaults *config.ServiceDefaults) (*config.Service, error) {
+	svc := *current
+	return collectService(&svc, defaults)
+}
+
+// collectService fills in svc, offering the settings it already has as the
+// default answers
+func collectService(svc *config.Service, defaults *config.ServiceDefaults) (*config.Service, error) {
+	if defaults == nil {
+		defaults = &config.ServiceDefaults{}
 	}
+	editing := svc.Name != ""
 
 	// Service name
-	if err := survey.AskOne(&survey.Input{
-		Message: "Service name (lowercase, no spaces):",
-		Help:    "E.g., enrichment, chat, generate",
-	}, &svc.Name, survey.WithValidator(survey.Required)); err != nil {
-		return nil, err
+	if !editing {
+		if err := AskOne(&survey.Input{
+			Message: "Service name (lowercase, no spaces):",
+			Help:    "E.g., enrichment, chat, generate",
+		}, &svc.Name, survey.WithValidator(survey.Required)); err != nil {
+			return nil, err
+		}
 	}
 
 	// Endpoint type selection
 	endpointType := ""
-	if err := survey.AskOne(&survey.Select{
+	typeQuestion := &survey.Select{
 		Message: "What type of endpoint do you want to create?",
 		Options: []string{"webhook", "api", "streaming"},
 		Description: func(value string, index int) string {
@@ -39,62 +60,92 @@ func CollectServiceConfig() (*config.Service, error) {
 				return ""
 			}
 		},
-	}, &endpointType, survey.WithValidator(survey.Required)); err != nil {
+	}
+	if editing {
+		typeQuestion.Message = "Endpoint type:"
+		typeQuestion.Default = svc.Type
+	}
+	if err := AskOne(typeQuestion, &endpointType, survey.WithValidator(survey.Required)); err != nil {
 		return nil, err
 	}
+	typeChanged := endpointType != svc.Type
 	svc.Type = endpointType
 
 	// Path based on type
 	var path string
 	if endpointType == "webhook" {
-		if err := survey.AskOne(&survey.Input{
+		defaultPath := fmt.Sprintf("/webhook/%s", svc.Name)
+		if svc.WebhookPath != "" && !typeChanged {
+			defaultPath = svc.WebhookPath
+		}
+		if err := AskOne(&survey.Input{
 			Message: "Webhook path:",
-			Default: fmt.Sprintf("/webhook/%s", svc.Name),
+			Default: defaultPath,
 			Help:    "E.g., /webhook/signup",
 		}, &path, survey.WithValidator(survey.Required)); err != nil {
 			return nil, err
 		}
-		svc.WebhookPath = path
+		svc.WebhookPath, svc.APIPath = path, ""
 	} else {
-		if err := survey.AskOne(&survey.Input{
+		defaultPath := fmt.Sprintf("/api/%s", svc.Name)
+		if svc.APIPath != "" {
+			defaultPath = svc.APIPath
+		}
+		if err := AskOne(&survey.Input{
 			Message: "API path:",
-			Default: fmt.Sprintf("/api/%s", svc.Name),
+			Default: defaultPath,
 			Help:    "E.g., /api/chat or /stream/generate",
 		}, &path, survey.WithValidator(survey.Required)); err != nil {
 			return nil, err
 		}
-		svc.APIPath = path
+		svc.APIPath, svc.WebhookPath = path, ""
 	}
 
 	// Description
-	if err := survey.AskOne(&survey.Input{
+	if err := AskOne(&survey.Input{
 		Message: "Description:",
+		Default: svc.Description,
 		Help:    "Brief description of what this endpoint does",
 	}, &svc.Description, survey.WithValidator(survey.Required)); err != nil {
 		return nil, err
 	}
 
 	// Prompt file path
-	if err := survey.AskOne(&survey.Input{
+	defaultPrompt := fmt.Sprintf(".claude/agents/%s.md", svc.Name)
+	if svc.Prompt != "" {
+		defaultPrompt = svc.Prompt
+	}
+	if err := AskOne(&survey.Input{
 		Message: "Agent prompt file path:",
-		Default: fmt.Sprintf(".claude/agents/%s.md", svc.Name),
+		Default: defaultPrompt,
 		Help:    "Path to the agent markdown file",
 	}, &svc.Prompt, survey.WithValidator(survey.Required)); err != nil {
 		return nil, err
 	}
 
 	// Input schema fields
-	fmt.Println("\n📋 Define input schema fields (press Enter with empty name to finish):")
-	if err := collectSchemaFields(&svc.InputSchema); err != nil {
+	if keep, err := keepFields("input", svc.InputSchema.Fields); err != nil {
 		return nil, err
+	} else if !keep {
+		schema := config.Schema{Name: svc.InputSchema.Name, Fields: []config.Field{}}
+		fmt.Println("\n📋 Define input schema fields (press Enter with empty name to finish):")
+		if err := collectSchemaFields(&schema); err != nil {
+			return nil, err
+		}
+		// An edited service that still has no fields is left as it was
+		if !editing || len(schema.Fields) > 0 || len(svc.InputSchema.Fields) > 0 {
+			svc.InputSchema = schema
+		}
 	}
 
 	// Output schema fields (only for API endpoints)
-	if endpointType == "api" {
+	if endpointType != "api" {
+		svc.OutputSchema = nil
+	} else if svc.OutputSchema == nil || len(svc.OutputSchema.Fields) == 0 {
 		addOutput := false
-		if err := survey.AskOne(&survey.Confirm{
+		if err := AskOne(&survey.Confirm{
 			Message: "Define output schema?",
-			Default: true,
+			Default: !editing,
 			Help:    "Specify the structure of the response data",
 		}, &addOutput); err != nil {
 			return nil, err
@@ -107,21 +158,46 @@ func CollectServiceConfig() (*config.Service, error) {
 				return nil, err
 			}
 		}
+	} else if keep, err := keepFields("output", svc.OutputSchema.Fields); err != nil {
+		return nil, err
+	} else if !keep {
+		svc.OutputSchema = &config.Schema{Name: svc.OutputSchema.Name, Fields: []config.Field{}}
+		fmt.Println("\n📤 Define output schema fields (press Enter with empty name to finish):")
+		if err := collectSchemaFields(svc.OutputSchema); err != nil {
+			return nil, err
+		}
 	}
 
 	// Allowed tools
-	if err := collectAllowedTools(&svc.AllowedTools); err != nil {
+	inherited := defaults.AllowedTools != nil && (svc.AllowedTools == nil || reflect.DeepEqual(svc.AllowedTools, defaults.AllowedTools))
+	if useDefault, err := confirmDefault(defaults.AllowedTools != nil, inherited, "Use the default DataGen tools?"); err != nil {
 		return nil, err
+	} else if useDefault {
+		svc.AllowedTools = nil
+	} else {
+		current := svc.AllowedTools
+		if current == nil && editing {
+			current = &config.AllowedTools{}
+		}
+		tools := &config.AllowedTools{}
+		if err := collectAllowedTools(tools, current); err != nil {
+			return nil, err
+		}
+		// No tools needs no table, unless it would inherit the default tools
+		if *tools != (config.AllowedTools{}) || svc.AllowedTools != nil || defaults.AllowedTools != nil {
+			svc.AllowedTools = tools
+		}
 	}
 
 	// Type-specific configuration
+	svc.Webhook, svc.API, svc.Streaming = typeConfig(svc, endpointType)
 	switch endpointType {
 	case "webhook":
 		if err := collectWebhookConfig(svc); err != nil {
 			return nil, err
 		}
 	case "api":
-		if err := collectAPIConfig(svc); err != nil {
+		if err := collectAPIConfig(svc, defaults.Timeout); err != nil {
 			return nil, err
 		}
 	case "streaming":
@@ -131,17 +207,80 @@ func CollectServiceConfig() (*config.Service, error) {
 	}
 
 	// Auth configuration
-	if err := collectAuthConfig(svc); err != nil {
+	inherited = defaults.Auth != nil && (svc.Auth == nil || reflect.DeepEqual(svc.Auth, defaults.Auth))
+	if useDefault, err := confirmDefault(defaults.Auth != nil, inherited, "Use the default authentication?"); err != nil {
 		return nil, err
+	} else if useDefault {
+		svc.Auth = nil
+	} else {
+		if err := collectAuthConfig(svc, editing); err != nil {
+			return nil, err
+		}
+		if svc.Auth == nil && defaults.Auth != nil {
+			// Without an auth table the service would inherit the default
+			svc.Auth = &config.Auth{Type: "none"}
+		}
 	}
 
 	return svc, nil
 }
 
+// typeConfig returns the type-specific settings svc keeps as endpointType:
+// its current ones for that type and none for the others
+func typeConfig(svc *config.Service, endpointType string) (*config.WebhookConfig, *config.APIConfig, *config.StreamingConfig) {
+	switch endpointType {
+	case "webhook":
+		return svc.Webhook, nil, nil
+	case "api":
+		return nil, svc.API, nil
+	case "streaming":
+		return nil, nil, svc.Streaming
+	}
+	return nil, nil, nil
+}
+
+// keepFields asks whether to keep a service's current schema fields; there
+// is nothing to keep for a new service
+func keepFields(schema string, fields []config.Field) (bool, error) {
+	if len(fields) == 0 {
+		return false, nil
+	}
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name + ":" + f.Type
+	}
+	keep := true
+	if err := AskOne(&survey.Confirm{
+		Message: fmt.Sprintf("Keep the %s fields (%s)?", schema, strings.Join(names, ", ")),
+		Default: true,
+		Help:    "Answer no to define the fields again",
+	}, &keep); err != nil {
+		return false, err
+	}
+	return keep, nil
+}
+
+// confirmDefault asks whether to keep a [defaults] setting, when there is
+// one; inherited is whether the service uses it today
+func confirmDefault(hasDefault, inherited bool, message string) (bool, error) {
+	if !hasDefault {
+		return false, nil
+	}
+	useDefault := inherited
+	if err := AskOne(&survey.Confirm{
+		Message: message,
+		Default: inherited,
+		Help:    "Set in [defaults] in datagen.toml",
+	}, &useDefault); err != nil {
+		return false, err
+	}
+	return useDefault, nil
+}
+
 func collectSchemaFields(schema *config.Schema) error {
 	for {
 		var fieldName string
-		if err := survey.AskOne(&survey.Input{
+		if err := AskOne(&survey.Input{
 			Message: "Field name (or press Enter to finish):",
 		}, &fieldName); err != nil {
 			return err
@@ -154,7 +293,7 @@ func collectSchemaFields(schema *config.Schema) error {
 		field := config.Field{Name: fieldName}
 
 		// Field type
-		if err := survey.AskOne(&survey.Select{
+		if err := AskOne(&survey.Select{
 			Message: fmt.Sprintf("Type for '%s':", fieldName),
 			Options: []string{"str", "int", "float", "bool", "list", "dict", "any"},
 			Default: "str",
@@ -163,7 +302,7 @@ func collectSchemaFields(schema *config.Schema) error {
 		}
 
 		// Required?
-		if err := survey.AskOne(&survey.Confirm{
+		if err := AskOne(&survey.Confirm{
 			Message: "Required?",
 			Default: true,
 		}, &field.Required); err != nil {
@@ -172,7 +311,7 @@ func collectSchemaFields(schema *config.Schema) error {
 
 		// Default value (optional)
 		var defaultVal string
-		if err := survey.AskOne(&survey.Input{
+		if err := AskOne(&survey.Input{
 			Message: "Default value (optional, press Enter to skip):",
 		}, &defaultVal); err != nil {
 			return err
@@ -187,12 +326,25 @@ func collectSchemaFields(schema *config.Schema) error {
 	return nil
 }
 
-func collectAllowedTools(tools *config.AllowedTools) error {
+// collectAllowedTools asks for tools, preselecting current's when set
+func collectAllowedTools(tools, current *config.AllowedTools) error {
+	preselected := []string{"executeTools", "getToolDetails"}
+	if current != nil {
+		preselected = []string{}
+		for _, tool := range []struct {
+			name string
+			on   bool
+		}{{"searchTools", current.SearchTools}, {"executeTools", current.ExecuteTools}, {"executeCode", current.ExecuteCode}, {"getToolDetails", current.GetToolDetails}} {
+			if tool.on {
+				preselected = append(preselected, tool.name)
+			}
+		}
+	}
 	selected := []string{}
-	if err := survey.AskOne(&survey.MultiSelect{
+	if err := AskOne(&survey.MultiSelect{
 		Message: "Select allowed DataGen tools:",
 		Options: []string{"searchTools", "executeTools", "executeCode", "getToolDetails"},
-		Default: []string{"executeTools", "getToolDetails"},
+		Default: preselected,
 	}, &selected); err != nil {
 		return err
 	}
@@ -213,147 +365,200 @@ func collectAllowedTools(tools *config.AllowedTools) error {
 	return nil
 }
 
+// orDefault returns value, or fallback when value is empty
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// itoa formats n for a question's default answer, using fallback for 0
+func itoa(n int, fallback string) string {
+	if n == 0 {
+		return fallback
+	}
+	return strconv.Itoa(n)
+}
+
 func collectWebhookConfig(svc *config.Service) error {
-	svc.Webhook = &config.WebhookConfig{}
+	webhook := config.WebhookConfig{}
+	if svc.Webhook != nil {
+		webhook = *svc.Webhook
+	}
+	svc.Webhook = &webhook
 
 	// Signature verification
 	var sigType string
-	if err := survey.AskOne(&survey.Select{
+	if err := AskOne(&survey.Select{
 		Message: "Signature verification method:",
 		Options: []string{"hmac_sha256", "custom", "none"},
-		Default: "none",
+		Default: orDefault(webhook.SignatureVerification, "none"),
 		Help:    "How to verify webhook authenticity",
 	}, &sigType); err != nil {
 		return err
 	}
-	svc.Webhook.SignatureVerification = sigType
+	webhook.SignatureVerification = sigType
 
 	if sigType == "hmac_sha256" {
-		if err := survey.AskOne(&survey.Input{
+		if err := AskOne(&survey.Input{
 			Message: "Signature header name:",
-			Default: "X-Signature",
-		}, &svc.Webhook.SignatureHeader); err != nil {
+			Default: orDefault(webhook.SignatureHeader, "X-Signature"),
+		}, &webhook.SignatureHeader); err != nil {
 			return err
 		}
 
-		if err := survey.AskOne(&survey.Input{
+		if err := AskOne(&survey.Input{
 			Message: "Secret environment variable name:",
-			Default: "HMAC_SECRET",
-		}, &svc.Webhook.SecretEnv); err != nil {
+			Default: orDefault(webhook.SecretEnv, "HMAC_SECRET"),
+		}, &webhook.SecretEnv); err != nil {
 			return err
 		}
 	}
 
 	// Retry policy
-	if err := survey.AskOne(&survey.Confirm{
+	if err := AskOne(&survey.Confirm{
 		Message: "Enable retry policy?",
-		Default: false,
-	}, &svc.Webhook.RetryEnabled); err != nil {
+		Default: webhook.RetryEnabled,
+	}, &webhook.RetryEnabled); err != nil {
 		return err
 	}
 
-	if svc.Webhook.RetryEnabled {
-		if err := survey.AskOne(&survey.Input{
+	if webhook.RetryEnabled {
+		retriesStr := itoa(webhook.MaxRetries, "3")
+		if err := AskOne(&survey.Input{
 			Message: "Max retries:",
-			Default: "3",
-		}, &svc.Webhook.MaxRetries); err != nil {
+			Default: retriesStr,
+		}, &retriesStr); err != nil {
 			return err
 		}
+		fmt.Sscanf(retriesStr, "%d", &webhook.MaxRetries)
 
 		var strategy string
-		if err := survey.AskOne(&survey.Select{
+		if err := AskOne(&survey.Select{
 			Message: "Backoff strategy:",
 			Options: []string{"exponential", "linear"},
-			Default: "exponential",
+			Default: orDefault(webhook.BackoffStrategy, "exponential"),
 		}, &strategy); err != nil {
 			return err
 		}
-		svc.Webhook.BackoffStrategy = strategy
+		webhook.BackoffStrategy = strategy
 	}
 
 	return nil
 }
 
-func collectAPIConfig(svc *config.Service) error {
-	svc.API = &config.APIConfig{}
+func collectAPIConfig(svc *config.Service, defaultTimeout int) error {
+	api := config.APIConfig{}
+	if svc.API != nil {
+		api = *svc.API
+	}
+	svc.API = &api
 
 	// Response format
-	if err := survey.AskOne(&survey.Select{
+	if err := AskOne(&survey.Select{
 		Message: "Response format:",
 		Options: []string{"json", "text", "custom"},
-		Default: "json",
-	}, &svc.API.ResponseFormat); err != nil {
+		Default: orDefault(api.ResponseFormat, "json"),
+	}, &api.ResponseFormat); err != nil {
 		return err
 	}
 
 	// Timeout
-	var timeoutStr string
-	if err := survey.AskOne(&survey.Input{
+	timeoutStr := itoa(api.Timeout, itoa(defaultTimeout, "30"))
+	if err := AskOne(&survey.Input{
 		Message: "Timeout (seconds):",
-		Default: "30",
+		Default: timeoutStr,
 	}, &timeoutStr); err != nil {
 		return err
 	}
-	fmt.Sscanf(timeoutStr, "%d", &svc.API.Timeout)
+	fmt.Sscanf(timeoutStr, "%d", &api.Timeout)
+	if api.Timeout == defaultTimeout {
+		// Inherited from [defaults]
+		api.Timeout = 0
+	}
 
 	// Rate limiting
-	if err := survey.AskOne(&survey.Confirm{
+	if err := AskOne(&survey.Confirm{
 		Message: "Enable rate limiting?",
-		Default: false,
-	}, &svc.API.RateLimitEnabled); err != nil {
+		Default: api.RateLimitEnabled,
+	}, &api.RateLimitEnabled); err != nil {
 		return err
 	}
 
-	if svc.API.RateLimitEnabled {
-		var rpmStr string
-		if err := survey.AskOne(&survey.Input{
+	if api.RateLimitEnabled {
+		rpmStr := itoa(api.RateLimitRPM, "60")
+		if err := AskOne(&survey.Input{
 			Message: "Requests per minute:",
-			Default: "60",
+			Default: rpmStr,
 		}, &rpmStr); err != nil {
 			return err
 		}
-		fmt.Sscanf(rpmStr, "%d", &svc.API.RateLimitRPM)
+		fmt.Sscanf(rpmStr, "%d", &api.RateLimitRPM)
 	}
 
 	return nil
 }
 
 func collectStreamingConfig(svc *config.Service) error {
-	svc.Streaming = &config.StreamingConfig{}
+	streaming := config.StreamingConfig{}
+	if svc.Streaming != nil {
+		streaming = *svc.Streaming
+	}
+	svc.Streaming = &streaming
 
 	// Format
-	if err := survey.AskOne(&survey.Select{
+	if err := AskOne(&survey.Select{
 		Message: "SSE format:",
 		Options: []string{"default", "json", "custom"},
-		Default: "default",
-	}, &svc.Streaming.Format); err != nil {
+		Default: orDefault(streaming.Format, "default"),
+	}, &streaming.Format); err != nil {
 		return err
 	}
 
 	// Buffer size
-	var bufferStr string
-	if err := survey.AskOne(&survey.Input{
+	bufferStr := itoa(streaming.BufferSize, "8192")
+	if err := AskOne(&survey.Input{
 		Message: "Buffer size (bytes):",
-		Default: "8192",
+		Default: bufferStr,
 	}, &bufferStr); err != nil {
 		return err
 	}
-	fmt.Sscanf(bufferStr, "%d", &svc.Streaming.BufferSize)
+	fmt.Sscanf(bufferStr, "%d", &streaming.BufferSize)
+
+	// Framing
+	if err := AskOne(&survey.Select{
+		Message: "Framing:",
+		Options: []string{config.FramingSSE, config.FramingNDJSON},
+		Default: orDefault(streaming.Framing, config.FramingSSE),
+	}, &streaming.Framing); err != nil {
+		return err
+	}
 
 	return nil
 }
 
-func collectAuthConfig(svc *config.Service) error {
+// collectAuthConfig asks for svc's auth, offering its current auth as the
+// default answers; an edited service without auth keeps none by default
+func collectAuthConfig(svc *config.Service, editing bool) error {
+	current := config.Auth{Type: "api_key"}
+	if svc.Auth != nil {
+		current = *svc.Auth
+	} else if editing {
+		current.Type = "none"
+	}
+
 	var authType string
-	if err := survey.AskOne(&survey.Select{
+	if err := AskOne(&survey.Select{
 		Message: "Authentication method:",
 		Options: []string{"api_key", "bearer_token", "oauth", "none"},
-		Default: "api_key",
+		Default: current.Type,
 	}, &authType); err != nil {
 		return err
 	}
 
 	if authType == "none" {
+		svc.Auth = nil
 		return nil
 	}
 
@@ -364,7 +569,10 @@ func collectAuthConfig(svc *config.Service) error {
 	if authType == "bearer_token" {
 		defaultHeader = "Authorization"
 	}
-	if err := survey.AskOne(&survey.Input{
+	if current.Type == authType && current.Header != "" {
+		defaultHeader = current.Header
+	}
+	if err := AskOne(&survey.Input{
 		Message: "Header name:",
 		Default: defaultHeader,
 	}, &svc.Auth.Header); err != nil {
@@ -372,8 +580,8 @@ func collectAuthConfig(svc *config.Service) error {
 	}
 
 	// Environment variable
-	defaultEnv := strings.ToUpper(svc.Name) + "_API_KEY"
-	if err := survey.AskOne(&survey.Input{
+	defaultEnv := orDefault(current.EnvVar, strings.ToUpper(svc.Name)+"_API_KEY")
+	if err := AskOne(&survey.Input{
 		Message: "Environment variable name:",
 		Default: defaultEnv,
 	}, &svc.Auth.EnvVar); err != nil {
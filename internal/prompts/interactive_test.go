@@ -0,0 +1,81 @@
+package prompts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/datagendev/datagen-cli/internal/config"
+)
+
+func editableService() *config.Service {
+	return &config.Service{
+		Name:         "chat",
+		Type:         "api",
+		Description:  "Answer questions",
+		Prompt:       ".claude/agents/chat.md",
+		APIPath:      "/api/chat",
+		AllowedTools: &config.AllowedTools{ExecuteTools: true},
+		InputSchema:  config.Schema{Fields: []config.Field{{Name: "question", Type: "str", Required: true}}},
+		OutputSchema: &config.Schema{Fields: []config.Field{{Name: "answer", Type: "str"}}},
+		Auth:         &config.Auth{Type: "bearer_token", Header: "Authorization", EnvVar: "CHAT_TOKEN"},
+		API:          &config.APIConfig{ResponseFormat: "text", Timeout: 90, RateLimitEnabled: true, RateLimitRPM: 20},
+		Model:        "opus",
+	}
+}
+
+func TestEditServiceConfig_KeepsAnswers(t *testing.T) {
+	withSimpleInput(t, strings.Repeat("\n", 40))
+
+	current := editableService()
+	got, err := EditServiceConfig(current, nil)
+	if err != nil {
+		t.Fatalf("EditServiceConfig: %v", err)
+	}
+	if !reflect.DeepEqual(got, editableService()) {
+		t.Fatalf("accepting every answer changed the service:\n got %+v\nwant %+v", got, editableService())
+	}
+	if !reflect.DeepEqual(current, editableService()) {
+		t.Fatal("EditServiceConfig modified the current service")
+	}
+}
+
+func TestEditServiceConfig_ChangeType(t *testing.T) {
+	// webhook type, then Enter for the path, description and prompt, keep the
+	// input fields and tools, retry policy on, and no auth
+	withSimpleInput(t, "1\n\n\n\n\n\n\ny\n\n\n4\n")
+
+	got, err := EditServiceConfig(editableService(), nil)
+	if err != nil {
+		t.Fatalf("EditServiceConfig: %v", err)
+	}
+	if got.Type != "webhook" || got.WebhookPath != "/webhook/chat" || got.APIPath != "" {
+		t.Fatalf("type and path = %s %q %q", got.Type, got.WebhookPath, got.APIPath)
+	}
+	if got.API != nil || got.OutputSchema != nil || got.Webhook == nil || !got.Webhook.RetryEnabled || got.Webhook.MaxRetries != 3 {
+		t.Fatalf("type-specific settings = api %+v, output %+v, webhook %+v", got.API, got.OutputSchema, got.Webhook)
+	}
+	if got.Auth != nil || len(got.InputSchema.Fields) != 1 || got.Model != "opus" {
+		t.Fatalf("edited service = %+v", got)
+	}
+}
+
+func TestCollectServiceConfig_Defaults(t *testing.T) {
+	// name, type api, Enter through path and prompt with a description, no
+	// input fields or output schema, default tools, Enter through the api
+	// settings, default auth
+	withSimpleInput(t, "chat\n2\n\nAnswers\n\n\nn\n\n\n\n\n\n")
+
+	defaults := &config.ServiceDefaults{
+		Timeout:      60,
+		AllowedTools: &config.AllowedTools{SearchTools: true},
+		Auth:         &config.Auth{Type: "api_key", Header: "X-API-Key", EnvVar: "SERVICE_API_KEY"},
+	}
+	got, err := CollectServiceConfig(defaults)
+	if err != nil {
+		t.Fatalf("CollectServiceConfig: %v", err)
+	}
+	if got.AllowedTools != nil || got.Auth != nil || got.API == nil || got.API.Timeout != 0 {
+		t.Fatalf("service should inherit its defaults: %+v, api %+v", got, got.API)
+	}
+}
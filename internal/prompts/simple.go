@@ -0,0 +1,274 @@
+package prompts
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"golang.org/x/term"
+)
+
+// SimpleMode replaces survey's arrow-key widgets with numbered plain-text
+// questions, which work with screen readers and dumb terminals
+var SimpleMode = os.Getenv("DATAGEN_SIMPLE_PROMPTS") != ""
+
+var (
+	simpleIn            = bufio.NewReader(os.Stdin)
+	simpleOut io.Writer = os.Stdout
+	// stdinTerminal reports whether answers are typed at a terminal, where
+	// passwords can be read without echoing them
+	stdinTerminal = func() bool { return term.IsTerminal(int(os.Stdin.Fd())) }
+)
+
+// AskOne asks p with survey, or as a plain-text question in SimpleMode.
+// Input, Password, Confirm, Select, and MultiSelect prompts are supported.
+func AskOne(p survey.Prompt, response interface{}, opts ...survey.AskOpt) error {
+	if !SimpleMode {
+		return survey.AskOne(p, response, opts...)
+	}
+
+	var options survey.AskOptions
+	for _, opt := range opts {
+		if err := opt(&options); err != nil {
+			return err
+		}
+	}
+
+	for {
+		answer, err := askSimple(p)
+		if err != nil {
+			return err
+		}
+		if err := validateSimple(answer, options.Validators); err != nil {
+			fmt.Fprintf(simpleOut, "Invalid answer: %v\n", err)
+			continue
+		}
+		return assignSimple(answer, response)
+	}
+}
+
+func validateSimple(answer interface{}, validators []survey.Validator) error {
+	for _, v := range validators {
+		if err := v(answer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readLine() (string, error) {
+	line, err := simpleIn.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// askSimple returns a string, bool, or []string answer
+func askSimple(p survey.Prompt) (interface{}, error) {
+	switch q := p.(type) {
+	case *survey.Input:
+		if q.Default != "" {
+			fmt.Fprintf(simpleOut, "%s [%s] ", q.Message, q.Default)
+		} else {
+			fmt.Fprintf(simpleOut, "%s ", q.Message)
+		}
+		line, err := readLine()
+		if err != nil {
+			return nil, err
+		}
+		if line == "" {
+			return q.Default, nil
+		}
+		return line, nil
+
+	case *survey.Password:
+		// Input already buffered (pasted ahead) can't be hidden any more
+		if simpleIn.Buffered() == 0 && stdinTerminal() {
+			fmt.Fprintf(simpleOut, "%s (input is hidden) ", q.Message)
+			secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+			fmt.Fprintln(simpleOut)
+			if err != nil {
+				return nil, err
+			}
+			return strings.TrimSpace(string(secret)), nil
+		}
+		fmt.Fprintf(simpleOut, "%s (input is visible) ", q.Message)
+		return readLine()
+
+	case *survey.Confirm:
+		hint := "y/N"
+		if q.Default {
+			hint = "Y/n"
+		}
+		for {
+			fmt.Fprintf(simpleOut, "%s (%s) ", q.Message, hint)
+			line, err := readLine()
+			if err != nil {
+				return nil, err
+			}
+			switch strings.ToLower(line) {
+			case "":
+				return q.Default, nil
+			case "y", "yes":
+				return true, nil
+			case "n", "no":
+				return false, nil
+			}
+			fmt.Fprintln(simpleOut, "Please answer y or n.")
+		}
+
+	case *survey.Select:
+		printSimpleOptions(q.Message, q.Options, q.Description)
+		def := defaultIndexes(q.Options, q.Default)
+		for {
+			if len(def) > 0 {
+				fmt.Fprintf(simpleOut, "Enter a number [%d]: ", def[0]+1)
+			} else {
+				fmt.Fprint(simpleOut, "Enter a number: ")
+			}
+			line, err := readLine()
+			if err != nil {
+				return nil, err
+			}
+			if line == "" && len(def) > 0 {
+				return q.Options[def[0]], nil
+			}
+			picked, err := parseSimpleChoices(line, len(q.Options))
+			if err == nil && len(picked) == 1 {
+				return q.Options[picked[0]], nil
+			}
+			fmt.Fprintf(simpleOut, "Please enter one number from 1 to %d.\n", len(q.Options))
+		}
+
+	case *survey.MultiSelect:
+		printSimpleOptions(q.Message, q.Options, q.Description)
+		def := defaultIndexes(q.Options, q.Default)
+		for {
+			if len(def) > 0 {
+				nums := make([]string, len(def))
+				for i, d := range def {
+					nums[i] = strconv.Itoa(d + 1)
+				}
+				fmt.Fprintf(simpleOut, "Enter numbers separated by commas, or 'none' [%s]: ", strings.Join(nums, ","))
+			} else {
+				fmt.Fprint(simpleOut, "Enter numbers separated by commas, or 'none': ")
+			}
+			line, err := readLine()
+			if err != nil {
+				return nil, err
+			}
+			var picked []int
+			switch {
+			case line == "":
+				picked = def
+			case strings.EqualFold(line, "none"):
+			default:
+				if picked, err = parseSimpleChoices(line, len(q.Options)); err != nil {
+					fmt.Fprintf(simpleOut, "Please enter numbers from 1 to %d.\n", len(q.Options))
+					continue
+				}
+			}
+			values := make([]string, len(picked))
+			for i, idx := range picked {
+				values[i] = q.Options[idx]
+			}
+			return values, nil
+		}
+
+	default:
+		return nil, fmt.Errorf("prompt type %T is not supported with --simple-prompts", p)
+	}
+}
+
+func printSimpleOptions(message string, options []string, describe func(string, int) string) {
+	fmt.Fprintln(simpleOut, message)
+	for i, opt := range options {
+		line := fmt.Sprintf("  %d. %s", i+1, opt)
+		if describe != nil {
+			if desc := describe(opt, i); desc != "" {
+				line += " - " + desc
+			}
+		}
+		fmt.Fprintln(simpleOut, line)
+	}
+}
+
+// defaultIndexes resolves a survey Default (string, int, []string, or []int) to option indexes
+func defaultIndexes(options []string, def interface{}) []int {
+	indexOf := func(value string) int {
+		for i, opt := range options {
+			if opt == value {
+				return i
+			}
+		}
+		return -1
+	}
+
+	var idx []int
+	switch d := def.(type) {
+	case string:
+		if i := indexOf(d); i >= 0 {
+			idx = append(idx, i)
+		}
+	case int:
+		if d >= 0 && d < len(options) {
+			idx = append(idx, d)
+		}
+	case []string:
+		for _, v := range d {
+			if i := indexOf(v); i >= 0 {
+				idx = append(idx, i)
+			}
+		}
+	case []int:
+		for _, i := range d {
+			if i >= 0 && i < len(options) {
+				idx = append(idx, i)
+			}
+		}
+	}
+	return idx
+}
+
+// parseSimpleChoices parses "1, 3" into zero-based indexes below n
+func parseSimpleChoices(line string, n int) ([]int, error) {
+	var picked []int
+	seen := map[int]bool{}
+	for _, part := range strings.Split(line, ",") {
+		num, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || num < 1 || num > n {
+			return nil, fmt.Errorf("invalid choice %q", part)
+		}
+		if !seen[num-1] {
+			seen[num-1] = true
+			picked = append(picked, num-1)
+		}
+	}
+	return picked, nil
+}
+
+func assignSimple(answer, response interface{}) error {
+	switch r := response.(type) {
+	case *string:
+		if s, ok := answer.(string); ok {
+			*r = s
+			return nil
+		}
+	case *bool:
+		if b, ok := answer.(bool); ok {
+			*r = b
+			return nil
+		}
+	case *[]string:
+		if s, ok := answer.([]string); ok {
+			*r = s
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot store %T answer in %T", answer, response)
+}
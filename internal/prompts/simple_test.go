@@ -0,0 +1,92 @@
+package prompts
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+func withSimpleInput(t *testing.T, input string) *bytes.Buffer {
+	t.Helper()
+	prevMode, prevIn, prevOut, prevTerminal := SimpleMode, simpleIn, simpleOut, stdinTerminal
+	out := &bytes.Buffer{}
+	SimpleMode, simpleIn, simpleOut = true, bufio.NewReader(strings.NewReader(input)), out
+	stdinTerminal = func() bool { return false }
+	t.Cleanup(func() { SimpleMode, simpleIn, simpleOut, stdinTerminal = prevMode, prevIn, prevOut, prevTerminal })
+	return out
+}
+
+func TestSimpleSelectRetriesInvalidNumber(t *testing.T) {
+	out := withSimpleInput(t, "7\n2\n")
+
+	var got string
+	err := AskOne(&survey.Select{Message: "Type?", Options: []string{"webhook", "api", "streaming"}}, &got)
+	if err != nil {
+		t.Fatalf("AskOne() error = %v", err)
+	}
+	if got != "api" {
+		t.Fatalf("AskOne() = %q, want api", got)
+	}
+	if !strings.Contains(out.String(), "  2. api") {
+		t.Fatalf("output missing numbered options:\n%s", out.String())
+	}
+}
+
+func TestSimpleMultiSelectDefaults(t *testing.T) {
+	withSimpleInput(t, "\n1,3\n")
+
+	opts := []string{"a", "b", "c"}
+	var got []string
+	if err := AskOne(&survey.MultiSelect{Message: "Pick", Options: opts, Default: []string{"b"}}, &got); err != nil {
+		t.Fatalf("AskOne() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"b"}) {
+		t.Fatalf("default answer = %v, want [b]", got)
+	}
+	if err := AskOne(&survey.MultiSelect{Message: "Pick", Options: opts}, &got); err != nil {
+		t.Fatalf("AskOne() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"a", "c"}) {
+		t.Fatalf("answer = %v, want [a c]", got)
+	}
+}
+
+func TestSimpleInputAndConfirm(t *testing.T) {
+	withSimpleInput(t, "\nchat\nmaybe\ny\n")
+
+	var name string
+	if err := AskOne(&survey.Input{Message: "Name:"}, &name, survey.WithValidator(survey.Required)); err != nil {
+		t.Fatalf("AskOne(Input) error = %v", err)
+	}
+	if name != "chat" {
+		t.Fatalf("Input = %q, want chat (empty answer should be re-asked)", name)
+	}
+
+	var ok bool
+	if err := AskOne(&survey.Confirm{Message: "Continue?"}, &ok); err != nil {
+		t.Fatalf("AskOne(Confirm) error = %v", err)
+	}
+	if !ok {
+		t.Fatal("Confirm = false, want true")
+	}
+}
+
+func TestSimplePasswordFromPipe(t *testing.T) {
+	out := withSimpleInput(t, "sk-secret\n")
+
+	var secret string
+	if err := AskOne(&survey.Password{Message: "API key:"}, &secret); err != nil {
+		t.Fatalf("AskOne(Password) error = %v", err)
+	}
+	if secret != "sk-secret" {
+		t.Fatalf("Password = %q, want sk-secret", secret)
+	}
+	// Piped input can't be hidden, so the prompt says so
+	if !strings.Contains(out.String(), "(input is visible)") {
+		t.Fatalf("prompt = %q, want the visible-input note", out.String())
+	}
+}
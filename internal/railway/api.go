@@ -0,0 +1,256 @@
+package railway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is Railway's public GraphQL endpoint
+const DefaultAPIURL = "https://backboard.railway.com/graphql/v2"
+
+// LinkedProject is the project/service a directory was linked to with `railway link`
+type LinkedProject struct {
+	ProjectPath     string `json:"projectPath"`
+	Name            string `json:"name"`
+	Project         string `json:"project"`
+	Environment     string `json:"environment"`
+	EnvironmentName string `json:"environmentName"`
+	Service         string `json:"service"`
+}
+
+type cliConfig struct {
+	Projects map[string]LinkedProject `json:"projects"`
+	User     struct {
+		Token string `json:"token"`
+	} `json:"user"`
+}
+
+// ConfigPath returns the Railway CLI config location (~/.railway/config.json)
+func ConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".railway", "config.json"), nil
+}
+
+func loadCLIConfig() (*cliConfig, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Railway config (run 'railway login'): %w", err)
+	}
+	var cfg cliConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse Railway config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// FindLinkedProject returns the Railway link for dir or its closest linked parent
+func FindLinkedProject(dir string) (*LinkedProject, error) {
+	cfg, err := loadCLIConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if link, ok := linkFor(cfg.Projects, abs, runtime.GOOS); ok {
+			return &link, nil
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			break
+		}
+		abs = parent
+	}
+	return nil, fmt.Errorf("%s is not linked to a Railway project (run 'railway link')", dir)
+}
+
+// linkFor returns the link recorded for path. Windows paths are compared
+// without case, since the CLI may have recorded c:\ where Go reports C:\.
+func linkFor(projects map[string]LinkedProject, path, goos string) (LinkedProject, bool) {
+	if link, ok := projects[path]; ok || goos != "windows" {
+		return link, ok
+	}
+	for p, link := range projects {
+		if strings.EqualFold(strings.TrimRight(p, `\/`), strings.TrimRight(path, `\/`)) {
+			return link, true
+		}
+	}
+	return LinkedProject{}, false
+}
+
+// APIToken returns a Railway API token from RAILWAY_API_TOKEN or the CLI login
+func APIToken() (string, error) {
+	if token := os.Getenv("RAILWAY_API_TOKEN"); token != "" {
+		return token, nil
+	}
+	cfg, err := loadCLIConfig()
+	if err != nil {
+		return "", err
+	}
+	if cfg.User.Token == "" {
+		return "", fmt.Errorf("no Railway API token found (set RAILWAY_API_TOKEN or run 'railway login')")
+	}
+	return cfg.User.Token, nil
+}
+
+// Client is a minimal Railway GraphQL API client
+type Client struct {
+	URL        string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// NewClient creates a Railway API client
+func NewClient(token string) *Client {
+	return &Client{
+		URL:        DefaultAPIURL,
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// do runs a GraphQL operation and unmarshals its data into out (if non-nil)
+func (c *Client) do(query string, variables map[string]any, out any) error {
+	body, err := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("Railway API error (%d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var gqlResp graphQLResponse
+	if err := json.Unmarshal(respBody, &gqlResp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(gqlResp.Errors) > 0 {
+		return fmt.Errorf("Railway API error: %s", gqlResp.Errors[0].Message)
+	}
+	if out != nil && len(gqlResp.Data) > 0 {
+		if err := json.Unmarshal(gqlResp.Data, out); err != nil {
+			return fmt.Errorf("failed to parse response data: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteService deletes a service and all of its deployments
+func (c *Client) DeleteService(serviceID string) error {
+	return c.do(`mutation serviceDelete($id: String!) { serviceDelete(id: $id) }`,
+		map[string]any{"id": serviceID}, nil)
+}
+
+// DeleteProject deletes a project and every service in it
+func (c *Client) DeleteProject(projectID string) error {
+	return c.do(`mutation projectDelete($id: String!) { projectDelete(id: $id) }`,
+		map[string]any{"id": projectID}, nil)
+}
+
+// ServiceDomain returns the public domain of a service, preferring a custom domain
+func (c *Client) ServiceDomain(projectID, environmentID, serviceID string) (string, error) {
+	var out struct {
+		Domains struct {
+			CustomDomains []struct {
+				Domain string `json:"domain"`
+			} `json:"customDomains"`
+			ServiceDomains []struct {
+				Domain string `json:"domain"`
+			} `json:"serviceDomains"`
+		} `json:"domains"`
+	}
+	err := c.do(`query domains($projectId: String!, $environmentId: String!, $serviceId: String!) {
+  domains(projectId: $projectId, environmentId: $environmentId, serviceId: $serviceId) {
+    customDomains { domain }
+    serviceDomains { domain }
+  }
+}`, map[string]any{"projectId": projectID, "environmentId": environmentID, "serviceId": serviceID}, &out)
+	if err != nil {
+		return "", err
+	}
+	if len(out.Domains.CustomDomains) > 0 {
+		return out.Domains.CustomDomains[0].Domain, nil
+	}
+	if len(out.Domains.ServiceDomains) > 0 {
+		return out.Domains.ServiceDomains[0].Domain, nil
+	}
+	return "", fmt.Errorf("service has no public domain (run 'railway domain')")
+}
+
+// Deployment is a single Railway deployment of a service
+type Deployment struct {
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// LatestDeployment returns the most recent deployment of a service, or nil if
+// it has never been deployed
+func (c *Client) LatestDeployment(projectID, environmentID, serviceID string) (*Deployment, error) {
+	var out struct {
+		Deployments struct {
+			Edges []struct {
+				Node Deployment `json:"node"`
+			} `json:"edges"`
+		} `json:"deployments"`
+	}
+	err := c.do(`query deployments($projectId: String!, $environmentId: String!, $serviceId: String!) {
+  deployments(first: 1, input: {projectId: $projectId, environmentId: $environmentId, serviceId: $serviceId}) {
+    edges { node { id status createdAt } }
+  }
+}`, map[string]any{"projectId": projectID, "environmentId": environmentID, "serviceId": serviceID}, &out)
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Deployments.Edges) == 0 {
+		return nil, nil
+	}
+	return &out.Deployments.Edges[0].Node, nil
+}
@@ -0,0 +1,106 @@
+package railway
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindLinkedProject_WalksUpToLinkedParent(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	projectDir := filepath.Join(home, "proj")
+	nested := filepath.Join(projectDir, "app")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("MkdirAll error = %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(home, ".railway"), 0o755); err != nil {
+		t.Fatalf("MkdirAll error = %v", err)
+	}
+	cfg := `{"projects": {"` + projectDir + `": {"projectPath": "` + projectDir + `", "name": "agents", "project": "p1", "environment": "e1", "service": "s1"}}, "user": {"token": "tok"}}`
+	if err := os.WriteFile(filepath.Join(home, ".railway", "config.json"), []byte(cfg), 0o600); err != nil {
+		t.Fatalf("WriteFile error = %v", err)
+	}
+
+	link, err := FindLinkedProject(nested)
+	if err != nil {
+		t.Fatalf("FindLinkedProject() error = %v", err)
+	}
+	if link.Project != "p1" || link.Service != "s1" {
+		t.Fatalf("FindLinkedProject() = %+v, want project p1 service s1", link)
+	}
+
+	t.Setenv("RAILWAY_API_TOKEN", "")
+	token, err := APIToken()
+	if err != nil {
+		t.Fatalf("APIToken() error = %v", err)
+	}
+	if token != "tok" {
+		t.Fatalf("APIToken() = %q, want tok", token)
+	}
+
+	if _, err := FindLinkedProject(home); err == nil {
+		t.Fatalf("FindLinkedProject(home) error = nil, want not linked error")
+	}
+}
+
+func TestClientDeleteService(t *testing.T) {
+	var gotAuth string
+	var gotReq graphQLRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &gotReq)
+		_, _ = w.Write([]byte(`{"data": {"serviceDelete": true}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("tok")
+	client.URL = server.URL
+	if err := client.DeleteService("s1"); err != nil {
+		t.Fatalf("DeleteService() error = %v", err)
+	}
+	if gotAuth != "Bearer tok" {
+		t.Fatalf("Authorization = %q, want Bearer tok", gotAuth)
+	}
+	if !strings.Contains(gotReq.Query, "serviceDelete") || gotReq.Variables["id"] != "s1" {
+		t.Fatalf("request = %+v, want serviceDelete with id s1", gotReq)
+	}
+}
+
+func TestClientReturnsGraphQLErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errors": [{"message": "Not Authorized"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("tok")
+	client.URL = server.URL
+	err := client.DeleteProject("p1")
+	if err == nil || !strings.Contains(err.Error(), "Not Authorized") {
+		t.Fatalf("DeleteProject() error = %v, want Not Authorized", err)
+	}
+}
+
+func TestClientServiceDomainPrefersCustomDomain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data": {"domains": {"customDomains": [{"domain": "agents.example.com"}], "serviceDomains": [{"domain": "app.up.railway.app"}]}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("tok")
+	client.URL = server.URL
+	domain, err := client.ServiceDomain("p1", "e1", "s1")
+	if err != nil {
+		t.Fatalf("ServiceDomain() error = %v", err)
+	}
+	if domain != "agents.example.com" {
+		t.Fatalf("ServiceDomain() = %q, want agents.example.com", domain)
+	}
+}
@@ -0,0 +1,254 @@
+package railway
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DefaultReleaseURL returns the latest Railway CLI release from GitHub
+const DefaultReleaseURL = "https://api.github.com/repos/railwayapp/cli/releases/latest"
+
+// BinDir returns where 'datagen install railway' puts the CLI (~/.datagen/bin)
+func BinDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".datagen", "bin"), nil
+}
+
+// exeName returns the CLI's file name on goos
+func exeName(goos string) string {
+	if goos == "windows" {
+		return CLI + ".exe"
+	}
+	return CLI
+}
+
+// Binary returns the Railway CLI to run: the one on PATH, else the one an
+// Installer placed in BinDir
+func Binary() (string, error) {
+	if found, err := exec.LookPath(CLI); err == nil {
+		return found, nil
+	}
+	if dir, err := BinDir(); err == nil {
+		installed := filepath.Join(dir, exeName(runtime.GOOS))
+		if info, err := os.Stat(installed); err == nil && !info.IsDir() {
+			return installed, nil
+		}
+	}
+	return "", errors.New("railway CLI not found (install it with 'datagen install railway')")
+}
+
+// releaseTarget returns the Rust target triple Railway names its release
+// archives after, for goos/goarch
+func releaseTarget(goos, goarch string) (string, bool) {
+	target, ok := map[string]string{
+		"darwin/amd64":  "x86_64-apple-darwin",
+		"darwin/arm64":  "aarch64-apple-darwin",
+		"linux/amd64":   "x86_64-unknown-linux-musl",
+		"linux/arm64":   "aarch64-unknown-linux-musl",
+		"linux/386":     "i686-unknown-linux-musl",
+		"windows/amd64": "x86_64-pc-windows-msvc",
+		"windows/arm64": "aarch64-pc-windows-msvc",
+		"windows/386":   "i686-pc-windows-msvc",
+	}[goos+"/"+goarch]
+	return target, ok
+}
+
+// Release is a GitHub release of the Railway CLI
+type Release struct {
+	Tag    string         `json:"tag_name"`
+	Assets []ReleaseAsset `json:"assets"`
+}
+
+// ReleaseAsset is one downloadable file of a release
+type ReleaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// archiveFor picks the release archive for goos/goarch and its checksum file
+// when the release has one
+func (r *Release) archiveFor(goos, goarch string) (archive, checksum *ReleaseAsset, err error) {
+	target, ok := releaseTarget(goos, goarch)
+	if !ok {
+		return nil, nil, fmt.Errorf("no Railway CLI build for %s/%s", goos, goarch)
+	}
+	for i, a := range r.Assets {
+		if strings.Contains(a.Name, target) && (strings.HasSuffix(a.Name, ".tar.gz") || strings.HasSuffix(a.Name, ".zip")) {
+			archive = &r.Assets[i]
+			break
+		}
+	}
+	if archive == nil {
+		return nil, nil, fmt.Errorf("Railway CLI release %s has no archive for %s", r.Tag, target)
+	}
+	for i, a := range r.Assets {
+		if a.Name == archive.Name+".sha256" {
+			checksum = &r.Assets[i]
+		}
+	}
+	return archive, checksum, nil
+}
+
+// Installer downloads the Railway CLI from its GitHub releases, so deploys
+// work without npm, bash or curl (in particular on Windows)
+type Installer struct {
+	HTTPClient *http.Client
+	ReleaseURL string
+	GOOS       string
+	GOARCH     string
+}
+
+// NewInstaller returns an Installer for the latest release and this platform
+func NewInstaller() *Installer {
+	return &Installer{
+		HTTPClient: &http.Client{Timeout: 5 * time.Minute},
+		ReleaseURL: DefaultReleaseURL,
+		GOOS:       runtime.GOOS,
+		GOARCH:     runtime.GOARCH,
+	}
+}
+
+// Install downloads the release archive for this platform, verifies it
+// against the release's checksum when one is published, and writes the
+// binary into dir. It returns the binary's path and the release tag.
+func (i *Installer) Install(ctx context.Context, dir string) (string, string, error) {
+	body, err := i.get(ctx, i.ReleaseURL)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to look up the latest Railway CLI release: %w", err)
+	}
+	var rel Release
+	if err := json.Unmarshal(body, &rel); err != nil {
+		return "", "", fmt.Errorf("failed to parse the Railway CLI release: %w", err)
+	}
+	archive, checksum, err := rel.archiveFor(i.GOOS, i.GOARCH)
+	if err != nil {
+		return "", "", err
+	}
+
+	data, err := i.get(ctx, archive.URL)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to download %s: %w", archive.Name, err)
+	}
+	if checksum != nil {
+		sum, err := i.get(ctx, checksum.URL)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to download %s: %w", checksum.Name, err)
+		}
+		fields := strings.Fields(string(sum))
+		got := sha256.Sum256(data)
+		if len(fields) == 0 || !strings.EqualFold(fields[0], hex.EncodeToString(got[:])) {
+			return "", "", fmt.Errorf("%s does not match its published checksum", archive.Name)
+		}
+	}
+
+	name := exeName(i.GOOS)
+	binary, err := extractBinary(archive.Name, data, name)
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", err
+	}
+	// Write next to the destination and rename, so an interrupted download
+	// never leaves a truncated binary behind
+	tmp, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return "", "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return "", "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return "", "", err
+	}
+	dest := filepath.Join(dir, name)
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return "", "", fmt.Errorf("failed to install %s: %w", dest, err)
+	}
+	return dest, rel.Tag, nil
+}
+
+func (i *Installer) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := i.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// extractBinary returns the file called name from a .tar.gz or .zip archive
+func extractBinary(archive string, data []byte, name string) ([]byte, error) {
+	if strings.HasSuffix(archive, ".zip") {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", archive, err)
+		}
+		for _, f := range zr.File {
+			if path.Base(f.Name) != name || f.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return io.ReadAll(rc)
+		}
+		return nil, fmt.Errorf("%s does not contain %s", archive, name)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", archive, err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s does not contain %s", archive, name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", archive, err)
+		}
+		if hdr.Typeflag == tar.TypeReg && path.Base(hdr.Name) == name {
+			return io.ReadAll(tr)
+		}
+	}
+}
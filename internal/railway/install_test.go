@@ -0,0 +1,155 @@
+package railway
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func tarGz(t *testing.T, name string, body []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write(body)
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func zipped(t *testing.T, name string, body []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(body)
+	zw.Close()
+	return buf.Bytes()
+}
+
+// releaseServer serves a release with one archive and, unless checksum is
+// empty, its .sha256 file
+func releaseServer(t *testing.T, archiveName string, archive []byte, checksum string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	mux.HandleFunc("/latest", func(w http.ResponseWriter, r *http.Request) {
+		rel := Release{Tag: "v4.0.0", Assets: []ReleaseAsset{{Name: archiveName, URL: srv.URL + "/" + archiveName}}}
+		if checksum != "" {
+			rel.Assets = append(rel.Assets, ReleaseAsset{Name: archiveName + ".sha256", URL: srv.URL + "/" + archiveName + ".sha256"})
+		}
+		json.NewEncoder(w).Encode(rel)
+	})
+	mux.HandleFunc("/"+archiveName, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	})
+	mux.HandleFunc("/"+archiveName+".sha256", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(checksum + "  " + archiveName + "\n"))
+	})
+	return srv
+}
+
+func TestInstall(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho railway\n")
+	tests := []struct {
+		name    string
+		goos    string
+		archive string
+		data    []byte
+		want    string
+	}{
+		{"linux tar.gz", "linux", "railway-v4.0.0-x86_64-unknown-linux-musl.tar.gz", tarGz(t, "railway-v4.0.0/railway", binary), "railway"},
+		{"windows zip", "windows", "railway-v4.0.0-x86_64-pc-windows-msvc.zip", zipped(t, "railway.exe", binary), "railway.exe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sum := sha256.Sum256(tt.data)
+			srv := releaseServer(t, tt.archive, tt.data, hex.EncodeToString(sum[:]))
+			dir := filepath.Join(t.TempDir(), "bin")
+			inst := &Installer{ReleaseURL: srv.URL + "/latest", GOOS: tt.goos, GOARCH: "amd64"}
+
+			path, tag, err := inst.Install(context.Background(), dir)
+			if err != nil {
+				t.Fatalf("Install() error = %v", err)
+			}
+			if tag != "v4.0.0" || path != filepath.Join(dir, tt.want) {
+				t.Fatalf("Install() = %s, %s, want %s, v4.0.0", path, tag, filepath.Join(dir, tt.want))
+			}
+			got, err := os.ReadFile(path)
+			if err != nil || !bytes.Equal(got, binary) {
+				t.Fatalf("installed binary = %q, %v", got, err)
+			}
+			entries, _ := os.ReadDir(dir)
+			if len(entries) != 1 {
+				t.Fatalf("install dir has %d entries, want only the binary", len(entries))
+			}
+		})
+	}
+}
+
+func TestInstall_ChecksumMismatch(t *testing.T) {
+	archive := "railway-v4.0.0-x86_64-unknown-linux-musl.tar.gz"
+	srv := releaseServer(t, archive, tarGz(t, "railway", []byte("tampered")), strings.Repeat("0", 64))
+	dir := t.TempDir()
+	inst := &Installer{ReleaseURL: srv.URL + "/latest", GOOS: "linux", GOARCH: "amd64"}
+
+	_, _, err := inst.Install(context.Background(), dir)
+	if err == nil || !strings.Contains(err.Error(), "does not match its published checksum") {
+		t.Fatalf("Install() error = %v, want checksum mismatch", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "railway")); !os.IsNotExist(err) {
+		t.Fatalf("binary was installed despite the checksum mismatch")
+	}
+}
+
+func TestArchiveFor(t *testing.T) {
+	rel := &Release{Tag: "v4.0.0", Assets: []ReleaseAsset{
+		{Name: "railway-v4.0.0-aarch64-apple-darwin.tar.gz"},
+		{Name: "railway-v4.0.0-x86_64-pc-windows-msvc.zip"},
+		{Name: "railway-v4.0.0-x86_64-pc-windows-msvc.zip.sha256"},
+		{Name: "railway_4.0.0_amd64.deb"},
+	}}
+	archive, checksum, err := rel.archiveFor("windows", "amd64")
+	if err != nil || archive.Name != "railway-v4.0.0-x86_64-pc-windows-msvc.zip" || checksum == nil {
+		t.Fatalf("archiveFor(windows/amd64) = %v, %v, %v", archive, checksum, err)
+	}
+	archive, checksum, err = rel.archiveFor("darwin", "arm64")
+	if err != nil || archive.Name != "railway-v4.0.0-aarch64-apple-darwin.tar.gz" || checksum != nil {
+		t.Fatalf("archiveFor(darwin/arm64) = %v, %v, %v", archive, checksum, err)
+	}
+	if _, _, err := rel.archiveFor("linux", "amd64"); err == nil {
+		t.Fatalf("archiveFor(linux/amd64) error = nil, want missing archive")
+	}
+	if _, _, err := rel.archiveFor("plan9", "amd64"); err == nil {
+		t.Fatalf("archiveFor(plan9/amd64) error = nil, want unsupported platform")
+	}
+}
+
+func TestLinkFor(t *testing.T) {
+	projects := map[string]LinkedProject{`c:\Users\dev\agents\`: {Project: "p1"}}
+
+	if link, ok := linkFor(projects, `C:\Users\dev\agents`, "windows"); !ok || link.Project != "p1" {
+		t.Fatalf("linkFor(windows) = %+v, %v, want p1", link, ok)
+	}
+	if _, ok := linkFor(projects, `C:\Users\dev\agents`, "linux"); ok {
+		t.Fatalf("linkFor(linux) matched a path that differs in case")
+	}
+}
@@ -0,0 +1,70 @@
+package railway
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// CLI is the name of the Railway CLI binary
+const CLI = "railway"
+
+// EnsureCLI returns an error when the Railway CLI is neither on PATH nor
+// installed by 'datagen install railway'
+func EnsureCLI() error {
+	_, err := Binary()
+	return err
+}
+
+// Command builds a Railway CLI invocation rooted at dir.
+// Stdin and stderr are wired to the current process so Railway can prompt
+// and report errors; callers decide what to do with stdout.
+func Command(dir string, args ...string) *exec.Cmd {
+	bin, err := Binary()
+	if err != nil {
+		bin = CLI
+	}
+	cmd := exec.Command(bin, args...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+// LogsOptions controls which logs `railway logs` returns
+type LogsOptions struct {
+	Service     string
+	Environment string
+	Since       string
+	Follow      bool
+	Build       bool
+	Lines       int
+}
+
+// LogsArgs returns the `railway logs` arguments for opts.
+// Railway streams by default; a line limit or --since window makes it
+// print a snapshot and exit, so those are only passed when not following.
+func LogsArgs(opts LogsOptions) []string {
+	args := []string{"logs"}
+	if opts.Service != "" {
+		args = append(args, "--service", opts.Service)
+	}
+	if opts.Environment != "" {
+		args = append(args, "--environment", opts.Environment)
+	}
+	if opts.Build {
+		args = append(args, "--build")
+	} else {
+		args = append(args, "--deployment")
+	}
+	if opts.Follow {
+		return args
+	}
+	if opts.Since != "" {
+		args = append(args, "--since", opts.Since)
+	}
+	if opts.Lines > 0 {
+		args = append(args, "--lines", strconv.Itoa(opts.Lines))
+	}
+	return args
+}
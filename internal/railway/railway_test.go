@@ -0,0 +1,65 @@
+package railway
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLogsArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		opts LogsOptions
+		want []string
+	}{
+		{
+			name: "snapshot with line limit",
+			opts: LogsOptions{Lines: 200},
+			want: []string{"logs", "--deployment", "--lines", "200"},
+		},
+		{
+			name: "service and since window",
+			opts: LogsOptions{Service: "api", Since: "1h"},
+			want: []string{"logs", "--service", "api", "--deployment", "--since", "1h"},
+		},
+		{
+			name: "environment",
+			opts: LogsOptions{Environment: "staging", Lines: 10},
+			want: []string{"logs", "--environment", "staging", "--deployment", "--lines", "10"},
+		},
+		{
+			name: "follow build logs ignores snapshot options",
+			opts: LogsOptions{Follow: true, Build: true, Since: "1h", Lines: 50},
+			want: []string{"logs", "--build"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LogsArgs(tt.opts)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("LogsArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseKV(t *testing.T) {
+	got := ParseKV("ANTHROPIC_API_KEY=sk-ant-123\nRAILWAY_ENVIRONMENT=production\nEMPTY=\nURL=https://x.dev/?a=b\nnot a var\n")
+	want := map[string]string{
+		"ANTHROPIC_API_KEY": "sk-ant-123",
+		"EMPTY":             "",
+		"URL":               "https://x.dev/?a=b",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseKV() = %v, want %v", got, want)
+	}
+}
+
+func TestVariablesTargetArgs(t *testing.T) {
+	target := VariablesTarget{Service: "api", Environment: "staging"}
+	got := target.args("--set", "A=1")
+	want := []string{"variables", "--set", "A=1", "--service", "api", "--environment", "staging"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("args() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,88 @@
+package railway
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/output"
+	"github.com/datagendev/datagen-cli/internal/transcript"
+)
+
+// VariablesTarget selects which service/environment `railway variables` acts on.
+// Empty fields fall back to the directory's linked service and environment.
+type VariablesTarget struct {
+	Dir         string
+	Service     string
+	Environment string
+}
+
+func (t VariablesTarget) args(base ...string) []string {
+	args := append([]string{"variables"}, base...)
+	if t.Service != "" {
+		args = append(args, "--service", t.Service)
+	}
+	if t.Environment != "" {
+		args = append(args, "--environment", t.Environment)
+	}
+	return args
+}
+
+// Variables returns the user-defined variables of target, excluding the
+// RAILWAY_* variables the platform injects.
+func Variables(target VariablesTarget) (map[string]string, error) {
+	cmd := Command(target.Dir, target.args("--kv")...)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := output.Run(cmd); err != nil {
+		return nil, fmt.Errorf("railway variables failed: %w", err)
+	}
+	return ParseKV(stdout.String()), nil
+}
+
+// SetVariables sets vars on target in a single `railway variables` call
+func SetVariables(target VariablesTarget, vars map[string]string) error {
+	if len(vars) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var base []string
+	for _, k := range keys {
+		base = append(base, "--set", k+"="+vars[k])
+	}
+
+	cmd := Command(target.Dir, target.args(base...)...)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	rec := transcript.Attach(cmd)
+	for _, k := range keys {
+		rec.Redact(vars[k])
+	}
+	if err := output.Run(cmd); err != nil {
+		return rec.Fail(target.Dir, fmt.Errorf("railway variables --set failed: %w", err))
+	}
+	return nil
+}
+
+// ParseKV parses `railway variables --kv` output, skipping RAILWAY_* system variables
+func ParseKV(output string) map[string]string {
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || key == "" || strings.HasPrefix(key, "RAILWAY_") {
+			continue
+		}
+		vars[key] = value
+	}
+	return vars
+}
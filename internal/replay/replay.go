@@ -0,0 +1,133 @@
+// Package replay reads the request/response recordings a generated app writes
+// with [capture] enabled, and compares a replayed response with the recorded
+// one.
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Recording is one captured request and its response
+type Recording struct {
+	Version    int      `json:"version"`
+	RequestID  string   `json:"request_id"`
+	RecordedAt string   `json:"recorded_at"`
+	Request    Request  `json:"request"`
+	Response   Response `json:"response"`
+
+	// File is the path the recording was read from
+	File string `json:"-"`
+}
+
+// Request is the captured request. Body is the decoded JSON payload with
+// secrets masked, or the raw text when it was not JSON.
+type Request struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+// Response is the captured response
+type Response struct {
+	Status      int             `json:"status"`
+	ContentType string          `json:"content_type"`
+	Body        json.RawMessage `json:"body"`
+	DurationMS  int64           `json:"duration_ms"`
+}
+
+// Load reads one recording
+func Load(path string) (*Recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rec Recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if rec.Version != 1 || rec.Request.Path == "" {
+		return nil, fmt.Errorf("%s: not a datagen recording", path)
+	}
+	rec.File = path
+	return &rec, nil
+}
+
+// Find expands paths into recording files: files are kept, directories are
+// searched recursively for .json files. The result is sorted, so recordings
+// replay in the order they were captured.
+func Find(paths []string) ([]string, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		err = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(path, ".json") {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// RequestBody returns the body to send: the JSON payload, or the raw text
+func (r *Recording) RequestBody() []byte {
+	var text string
+	if json.Unmarshal(r.Request.Body, &text) == nil {
+		return []byte(text)
+	}
+	return r.Request.Body
+}
+
+// ResponseBody returns the recorded response body as sent
+func (r *Recording) ResponseBody() string {
+	var text string
+	if json.Unmarshal(r.Response.Body, &text) == nil {
+		return text
+	}
+	return string(r.Response.Body)
+}
+
+var (
+	// sseIDLine matches SSE event ids, which differ on every run
+	sseIDLine = regexp.MustCompile(`(?m)^id: .*\n`)
+	// uuidPattern matches request ids embedded anywhere in a body
+	uuidPattern = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+)
+
+// Normalize prepares a response body for comparison: JSON is re-indented
+// with sorted keys, and request and event ids, which differ on every run,
+// are blanked
+func Normalize(body string) string {
+	body = sseIDLine.ReplaceAllString(body, "")
+	body = uuidPattern.ReplaceAllString(body, "<id>")
+	var v any
+	if json.Unmarshal([]byte(body), &v) == nil {
+		if pretty, err := json.MarshalIndent(v, "", "  "); err == nil {
+			return string(pretty)
+		}
+	}
+	return strings.TrimSpace(body)
+}
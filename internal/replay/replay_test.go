@@ -0,0 +1,106 @@
+package replay
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const sample = `{
+  "version": 1,
+  "request_id": "3f2b8c1e-1d2a-4b7c-9e5f-0a1b2c3d4e5f",
+  "recorded_at": "2026-10-16T09:30:00+00:00",
+  "request": {
+    "method": "POST",
+    "path": "/api/chat",
+    "query": "",
+    "headers": {"content-type": "application/json"},
+    "body": {"message": "hi", "api_key": "[REDACTED]"}
+  },
+  "response": {
+    "status": 200,
+    "content_type": "application/json",
+    "body": {"result": "hello", "request_id": "3f2b8c1e-1d2a-4b7c-9e5f-0a1b2c3d4e5f"},
+    "duration_ms": 1200
+  }
+}`
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rec.json")
+	if err := os.WriteFile(path, []byte(sample), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if rec.Request.Path != "/api/chat" || rec.Response.Status != 200 || rec.File != path {
+		t.Errorf("Load() = %+v", rec)
+	}
+	if got := string(rec.RequestBody()); got != `{"message": "hi", "api_key": "[REDACTED]"}` {
+		t.Errorf("RequestBody() = %s", got)
+	}
+
+	other := filepath.Join(dir, "other.json")
+	if err := os.WriteFile(other, []byte(`{"name": "not a recording"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(other); err == nil {
+		t.Error("Load(other JSON) error = nil, want not a datagen recording")
+	}
+}
+
+func TestRequestBodyText(t *testing.T) {
+	rec := Recording{Request: Request{Body: []byte(`"payload=1"`)}, Response: Response{Body: []byte(`"data: hi\n\n"`)}}
+	if got := string(rec.RequestBody()); got != "payload=1" {
+		t.Errorf("RequestBody() = %q, want the raw text", got)
+	}
+	if got := rec.ResponseBody(); got != "data: hi\n\n" {
+		t.Errorf("ResponseBody() = %q, want the raw text", got)
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"2026-10-16/093000-api_chat-b.json", "2026-10-15/120000-api_chat-a.json", "2026-10-16/notes.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(sample), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := Find([]string{dir})
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "2026-10-15/120000-api_chat-a.json"),
+		filepath.Join(dir, "2026-10-16/093000-api_chat-b.json"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Find() = %v, want %v", got, want)
+	}
+	if _, err := Find([]string{filepath.Join(dir, "missing")}); err == nil {
+		t.Error("Find(missing) error = nil")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	a := `{"result": "hello", "request_id": "3f2b8c1e-1d2a-4b7c-9e5f-0a1b2c3d4e5f"}`
+	b := `{"request_id":"9a8b7c6d-1d2a-4b7c-9e5f-0a1b2c3d4e5f","result":"hello"}`
+	if Normalize(a) != Normalize(b) {
+		t.Errorf("Normalize() differs on key order and ids:\n%s\n%s", Normalize(a), Normalize(b))
+	}
+	sseA := "id: 3f2b8c1e-1d2a-4b7c-9e5f-0a1b2c3d4e5f:1\nevent: message\ndata: hi\n\n"
+	sseB := "id: 9a8b7c6d-1d2a-4b7c-9e5f-0a1b2c3d4e5f:1\nevent: message\ndata: hi\n\n"
+	if Normalize(sseA) != Normalize(sseB) {
+		t.Errorf("Normalize() differs on SSE ids")
+	}
+	if Normalize(`{"result": "hello"}`) == Normalize(`{"result": "bye"}`) {
+		t.Error("Normalize() hides a changed reply")
+	}
+}
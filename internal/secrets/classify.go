@@ -0,0 +1,113 @@
+package secrets
+
+import (
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/datagendev/datagen-cli/internal/dotenv"
+)
+
+// Variable kinds: secrets are stored sealed where the platform can, plain
+// config is set as ordinary, readable variables
+const (
+	KindSecret = "secret"
+	KindPlain  = "plain"
+)
+
+// secretWords are the name words that mark a credential, e.g. the KEY in
+// ANTHROPIC_API_KEY or the SECRET in GITHUB_WEBHOOK_SECRET
+var secretWords = map[string]bool{
+	"KEY": true, "KEYS": true, "APIKEY": true, "SECRET": true, "SECRETS": true,
+	"TOKEN": true, "TOKENS": true, "PASSWORD": true, "PASSWD": true, "PASS": true,
+	"PWD": true, "CREDENTIAL": true, "CREDENTIALS": true, "PRIVATE": true,
+	"HMAC": true, "DSN": true, "SALT": true,
+}
+
+// publicWords mark keys that are meant to be shared, such as
+// STRIPE_PUBLISHABLE_KEY, even though they contain a secret word
+var publicWords = map[string]bool{"PUBLIC": true, "PUBLISHABLE": true}
+
+// Classifier decides which variables are secrets
+type Classifier struct {
+	// Marks holds the explicit kind of keys marked `# secret` or `# plain`
+	// in .env.example; they override the guess from name and value
+	Marks map[string]string
+}
+
+// LoadClassifier reads the `# secret` and `# plain` marks of the .env.example
+// at path. A missing file yields a classifier that only guesses.
+func LoadClassifier(path string) (*Classifier, error) {
+	entries, err := dotenv.Read(path)
+	if os.IsNotExist(err) {
+		return &Classifier{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := &Classifier{Marks: map[string]string{}}
+	for _, e := range entries {
+		if kind := markedKind(e.InlineComment); kind != "" {
+			c.Marks[e.Key] = kind
+		}
+	}
+	return c, nil
+}
+
+// markedKind returns the kind an inline comment names: "secret", "plain", or
+// either as its first word ("secret: rotated monthly")
+func markedKind(comment string) string {
+	word, _, _ := strings.Cut(strings.ToLower(comment), " ")
+	switch strings.TrimRight(word, ":,;.") {
+	case KindSecret:
+		return KindSecret
+	case KindPlain:
+		return KindPlain
+	}
+	return ""
+}
+
+// Kind classifies key: its .env.example mark if it has one, otherwise secret
+// when the name has a credential word or the value embeds a credential (a URL
+// with a password, a PEM private key), and plain config otherwise
+func (c *Classifier) Kind(key, value string) string {
+	if kind, ok := c.Marks[key]; ok {
+		return kind
+	}
+	if IsSecretName(key) || isSecretValue(value) {
+		return KindSecret
+	}
+	return KindPlain
+}
+
+// IsSecretName reports whether a variable name looks like it holds a
+// credential: one of its words is KEY, TOKEN, SECRET, PASSWORD, ... and none
+// is PUBLIC or PUBLISHABLE
+func IsSecretName(key string) bool {
+	words := strings.FieldsFunc(strings.ToUpper(key), func(r rune) bool {
+		return (r < 'A' || r > 'Z') && (r < '0' || r > '9')
+	})
+	secret := false
+	for _, w := range words {
+		if publicWords[w] {
+			return false
+		}
+		secret = secret || secretWords[w]
+	}
+	return secret
+}
+
+func isSecretValue(v string) bool {
+	if strings.Contains(v, "-----BEGIN") && strings.Contains(v, "PRIVATE KEY-----") {
+		return true
+	}
+	if !strings.Contains(v, "://") {
+		return false
+	}
+	u, err := url.Parse(v)
+	if err != nil || u.User == nil {
+		return false
+	}
+	_, ok := u.User.Password()
+	return ok
+}
@@ -0,0 +1,49 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Doppler downloads every secret of a Doppler config through the Doppler CLI.
+// Project and Config fall back to the directory's `doppler setup` when empty.
+type Doppler struct {
+	Project string
+	Config  string
+	run     runFunc
+}
+
+// NewDoppler returns a Doppler source backed by the doppler CLI
+func NewDoppler(project, config string) *Doppler {
+	return &Doppler{Project: project, Config: config, run: runCommand}
+}
+
+// Load returns the config's secrets, minus the DOPPLER_* metadata keys
+func (d *Doppler) Load(ctx context.Context) (map[string]string, error) {
+	args := []string{"secrets", "download", "--no-file", "--format", "json"}
+	if d.Project != "" {
+		args = append(args, "--project", d.Project)
+	}
+	if d.Config != "" {
+		args = append(args, "--config", d.Config)
+	}
+	out, err := d.run(ctx, "doppler", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var all map[string]string
+	if err := json.Unmarshal(out, &all); err != nil {
+		return nil, fmt.Errorf("failed to parse doppler output: %w", err)
+	}
+	values := map[string]string{}
+	for k, v := range all {
+		if strings.HasPrefix(k, "DOPPLER_") {
+			continue
+		}
+		values[k] = v
+	}
+	return values, nil
+}
@@ -0,0 +1,192 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/output"
+)
+
+// Reference is a parsed scheme://path#field secret reference
+type Reference struct {
+	Raw    string
+	Scheme string
+	Path   string
+	Field  string
+}
+
+// Resolver fetches the value behind a reference for one scheme
+type Resolver interface {
+	Scheme() string
+	Resolve(ctx context.Context, ref *Reference) (string, error)
+}
+
+// runFunc executes an external CLI and returns its stdout
+type runFunc func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("%s CLI not found on PATH", name)
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	out, err := output.Output(cmd)
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("%s failed: %s", name, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("%s failed: %w", name, err)
+	}
+	return out, nil
+}
+
+// DefaultTimeout bounds a single secret lookup
+const DefaultTimeout = 30 * time.Second
+
+// Registry maps schemes to resolvers
+type Registry struct {
+	resolvers map[string]Resolver
+}
+
+// NewRegistry returns a registry with the given resolvers
+func NewRegistry(resolvers ...Resolver) *Registry {
+	r := &Registry{resolvers: map[string]Resolver{}}
+	for _, res := range resolvers {
+		r.resolvers[res.Scheme()] = res
+	}
+	return r
+}
+
+// DefaultRegistry supports 1Password (op://), Vault (vault://), and AWS Secrets Manager (aws-sm://)
+func DefaultRegistry() *Registry {
+	return NewRegistry(
+		&OnePassword{run: runCommand},
+		&Vault{run: runCommand},
+		&AWSSecretsManager{run: runCommand},
+	)
+}
+
+// Parse returns the reference in value when it uses a registered scheme
+func (r *Registry) Parse(value string) (*Reference, bool) {
+	scheme, rest, ok := strings.Cut(value, "://")
+	if !ok || rest == "" {
+		return nil, false
+	}
+	if _, known := r.resolvers[scheme]; !known {
+		return nil, false
+	}
+	ref := &Reference{Raw: value, Scheme: scheme, Path: rest}
+	if path, field, ok := strings.Cut(rest, "#"); ok {
+		ref.Path, ref.Field = path, field
+	}
+	return ref, true
+}
+
+// IsReference reports whether value is a secret reference this registry can resolve
+func (r *Registry) IsReference(value string) bool {
+	_, ok := r.Parse(value)
+	return ok
+}
+
+// Resolve returns value unchanged unless it is a secret reference, in which case
+// the referenced secret is fetched
+func (r *Registry) Resolve(ctx context.Context, value string) (string, error) {
+	ref, ok := r.Parse(value)
+	if !ok {
+		return value, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	secret, err := r.resolvers[ref.Scheme].Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", ref.Raw, err)
+	}
+	return secret, nil
+}
+
+// ResolveAll resolves every reference in vars in place. The returned set lists
+// the keys whose values came from a secret manager.
+func (r *Registry) ResolveAll(ctx context.Context, vars map[string]string) (map[string]bool, error) {
+	resolved := map[string]bool{}
+	for key, value := range vars {
+		if !r.IsReference(value) {
+			continue
+		}
+		secret, err := r.Resolve(ctx, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		vars[key] = secret
+		resolved[key] = true
+	}
+	return resolved, nil
+}
+
+// OnePassword resolves op://vault/item/field through the 1Password CLI
+type OnePassword struct {
+	run runFunc
+}
+
+func (o *OnePassword) Scheme() string { return "op" }
+
+func (o *OnePassword) Resolve(ctx context.Context, ref *Reference) (string, error) {
+	out, err := o.run(ctx, "op", "read", "--no-newline", ref.Raw)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// Vault resolves vault://<kv path>#<field> through the Vault CLI (kv get)
+type Vault struct {
+	run runFunc
+}
+
+func (v *Vault) Scheme() string { return "vault" }
+
+func (v *Vault) Resolve(ctx context.Context, ref *Reference) (string, error) {
+	if ref.Field == "" {
+		return "", fmt.Errorf("vault references need a field: vault://<path>#<field>")
+	}
+	out, err := v.run(ctx, "vault", "kv", "get", "-field="+ref.Field, ref.Path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// AWSSecretsManager resolves aws-sm://<secret id>[#<json key>] through the AWS CLI
+type AWSSecretsManager struct {
+	run runFunc
+}
+
+func (a *AWSSecretsManager) Scheme() string { return "aws-sm" }
+
+func (a *AWSSecretsManager) Resolve(ctx context.Context, ref *Reference) (string, error) {
+	out, err := a.run(ctx, "aws", "secretsmanager", "get-secret-value",
+		"--secret-id", ref.Path, "--query", "SecretString", "--output", "text")
+	if err != nil {
+		return "", err
+	}
+	secret := strings.TrimRight(string(out), "\r\n")
+	if ref.Field == "" {
+		return secret, nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object, cannot select %q", ref.Path, ref.Field)
+	}
+	value, ok := fields[ref.Field]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no key %q", ref.Path, ref.Field)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
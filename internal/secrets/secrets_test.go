@@ -0,0 +1,106 @@
+package secrets
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type fakeRun struct {
+	calls [][]string
+	out   map[string]string
+}
+
+func (f *fakeRun) run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	call := append([]string{name}, args...)
+	f.calls = append(f.calls, call)
+	return []byte(f.out[name]), nil
+}
+
+func TestRegistryParse(t *testing.T) {
+	r := DefaultRegistry()
+
+	ref, ok := r.Parse("vault://secret/myapp#api_key")
+	if !ok {
+		t.Fatalf("Parse(vault) ok = false")
+	}
+	if ref.Scheme != "vault" || ref.Path != "secret/myapp" || ref.Field != "api_key" {
+		t.Fatalf("Parse(vault) = %+v", ref)
+	}
+
+	for _, plain := range []string{"sk-ant-123", "https://example.com", "", "op://"} {
+		if r.IsReference(plain) {
+			t.Fatalf("IsReference(%q) = true, want false", plain)
+		}
+	}
+}
+
+func TestResolveAll(t *testing.T) {
+	fake := &fakeRun{out: map[string]string{
+		"op":    "sk-ant-from-1password",
+		"vault": "vault-secret\n",
+		"aws":   `{"token": "aws-token", "port": 5432}` + "\n",
+	}}
+	r := NewRegistry(&OnePassword{run: fake.run}, &Vault{run: fake.run}, &AWSSecretsManager{run: fake.run})
+
+	vars := map[string]string{
+		"ANTHROPIC_API_KEY": "op://Engineering/Anthropic/credential",
+		"HMAC_SECRET":       "vault://secret/agents#hmac",
+		"DB_TOKEN":          "aws-sm://prod/agents#token",
+		"LOG_LEVEL":         "INFO",
+	}
+	resolved, err := r.ResolveAll(context.Background(), vars)
+	if err != nil {
+		t.Fatalf("ResolveAll() error = %v", err)
+	}
+
+	want := map[string]string{
+		"ANTHROPIC_API_KEY": "sk-ant-from-1password",
+		"HMAC_SECRET":       "vault-secret",
+		"DB_TOKEN":          "aws-token",
+		"LOG_LEVEL":         "INFO",
+	}
+	if !reflect.DeepEqual(vars, want) {
+		t.Fatalf("vars = %v, want %v", vars, want)
+	}
+	if len(resolved) != 3 || resolved["LOG_LEVEL"] {
+		t.Fatalf("resolved = %v, want the three references", resolved)
+	}
+
+	var sawVaultField bool
+	for _, call := range fake.calls {
+		if call[0] == "vault" && strings.Join(call, " ") == "vault kv get -field=hmac secret/agents" {
+			sawVaultField = true
+		}
+	}
+	if !sawVaultField {
+		t.Fatalf("calls = %v, want vault kv get -field=hmac secret/agents", fake.calls)
+	}
+}
+
+func TestVaultRequiresField(t *testing.T) {
+	r := NewRegistry(&Vault{run: (&fakeRun{}).run})
+	if _, err := r.Resolve(context.Background(), "vault://secret/agents"); err == nil {
+		t.Fatalf("Resolve() error = nil, want missing field error")
+	}
+}
+
+func TestDopplerLoad(t *testing.T) {
+	fake := &fakeRun{out: map[string]string{
+		"doppler": `{"ANTHROPIC_API_KEY":"sk-1","DOPPLER_PROJECT":"api","DOPPLER_CONFIG":"prd"}`,
+	}}
+	d := &Doppler{Project: "api", Config: "prd", run: fake.run}
+
+	values, err := d.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if want := map[string]string{"ANTHROPIC_API_KEY": "sk-1"}; !reflect.DeepEqual(values, want) {
+		t.Fatalf("Load() = %v, want %v", values, want)
+	}
+	want := []string{"doppler", "secrets", "download", "--no-file", "--format", "json", "--project", "api", "--config", "prd"}
+	if !reflect.DeepEqual(fake.calls[0], want) {
+		t.Fatalf("doppler args = %v, want %v", fake.calls[0], want)
+	}
+}
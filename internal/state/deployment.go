@@ -0,0 +1,118 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DeploymentFile is the per-project deployment record, relative to the project directory
+const DeploymentFile = ".datagen/state.json"
+
+// Deployment records what datagen manages on the platform for one project directory
+type Deployment struct {
+	Platform      string     `json:"platform"`
+	ProjectID     string     `json:"project_id,omitempty"`
+	ProjectName   string     `json:"project_name,omitempty"`
+	EnvironmentID string     `json:"environment_id,omitempty"`
+	ServiceID     string     `json:"service_id,omitempty"`
+	ConfigHash    string     `json:"config_hash,omitempty"`
+	VariableKeys  []string   `json:"variable_keys,omitempty"`
+	DeployedAt    *time.Time `json:"deployed_at,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// LoadDeployment reads dir's deployment record; it returns nil, nil when there is none
+func LoadDeployment(dir string) (*Deployment, error) {
+	data, err := os.ReadFile(filepath.Join(dir, DeploymentFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var d Deployment
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", DeploymentFile, err)
+	}
+	return &d, nil
+}
+
+// SaveDeployment writes d to dir's deployment record
+func SaveDeployment(dir string, d *Deployment) error {
+	d.UpdatedAt = time.Now().UTC()
+	sort.Strings(d.VariableKeys)
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, DeploymentFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// RemoveDeployment deletes dir's deployment record
+func RemoveDeployment(dir string) error {
+	err := os.Remove(filepath.Join(dir, DeploymentFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// AddVariableKeys merges keys into the recorded variable keys
+func (d *Deployment) AddVariableKeys(keys ...string) {
+	seen := map[string]bool{}
+	for _, k := range d.VariableKeys {
+		seen[k] = true
+	}
+	for _, k := range keys {
+		if !seen[k] {
+			seen[k] = true
+			d.VariableKeys = append(d.VariableKeys, k)
+		}
+	}
+}
+
+// MarkDeployed records that the platform now runs with the config file at
+// configPath, which ConfigChanged later compares against
+func (d *Deployment) MarkDeployed(configPath string) error {
+	hash, err := HashFile(configPath)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	d.ConfigHash = hash
+	d.DeployedAt = &now
+	return nil
+}
+
+// ConfigChanged reports whether the config file no longer matches the
+// hash recorded at the last deploy. It is false when nothing was recorded.
+func (d *Deployment) ConfigChanged(configPath string) (bool, error) {
+	if d.ConfigHash == "" {
+		return false, nil
+	}
+	hash, err := HashFile(configPath)
+	if err != nil {
+		return false, err
+	}
+	return hash != d.ConfigHash, nil
+}
+
+// HashFile returns the hex SHA-256 of a file's contents
+func HashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
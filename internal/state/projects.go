@@ -0,0 +1,75 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"time"
+)
+
+const projectsPrefix = "projects/"
+
+// ProjectLink ties a local project directory to its deployed service
+type ProjectLink struct {
+	Dir           string    `json:"dir"`
+	Name          string    `json:"name,omitempty"`
+	Platform      string    `json:"platform"`
+	ProjectID     string    `json:"project_id,omitempty"`
+	EnvironmentID string    `json:"environment_id,omitempty"`
+	ServiceID     string    `json:"service_id,omitempty"`
+	URL           string    `json:"url,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func projectKey(dir string) string {
+	sum := sha256.Sum256([]byte(dir))
+	return projectsPrefix + hex.EncodeToString(sum[:8])
+}
+
+// SaveProjectLink records link under its absolute directory
+func SaveProjectLink(s Store, link ProjectLink) error {
+	abs, err := filepath.Abs(link.Dir)
+	if err != nil {
+		return err
+	}
+	link.Dir = abs
+	if link.UpdatedAt.IsZero() {
+		link.UpdatedAt = time.Now().UTC()
+	}
+	data, err := json.MarshalIndent(link, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.Put(projectKey(abs), data)
+}
+
+// RemoveProjectLink forgets the link for dir
+func RemoveProjectLink(s Store, dir string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	return s.Delete(projectKey(abs))
+}
+
+// ProjectLinks returns every recorded link
+func ProjectLinks(s Store) ([]ProjectLink, error) {
+	keys, err := s.List(projectsPrefix)
+	if err != nil {
+		return nil, err
+	}
+	links := make([]ProjectLink, 0, len(keys))
+	for _, key := range keys {
+		data, err := s.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		var link ProjectLink
+		if err := json.Unmarshal(data, &link); err != nil {
+			continue
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
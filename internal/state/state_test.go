@@ -0,0 +1,136 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	s := NewFileStore(t.TempDir())
+
+	if _, err := s.Get("projects/a"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+	if err := s.Put("projects/a", []byte("1")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := s.Put("deploys/my app", []byte("2")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	keys, err := s.List("")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if want := []string{"deploys/my app", "projects/a"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("List() = %v, want %v", keys, want)
+	}
+
+	if err := s.Delete("projects/a"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if keys, _ := s.List("projects/"); len(keys) != 0 {
+		t.Fatalf("List(projects/) after Delete = %v", keys)
+	}
+	if err := s.Put("../escape", nil); err == nil {
+		t.Fatal("Put(../escape) should fail")
+	}
+}
+
+func TestProjectLinks(t *testing.T) {
+	s := NewFileStore(t.TempDir())
+	dir := t.TempDir()
+
+	if err := SaveProjectLink(s, ProjectLink{Dir: dir, Platform: "railway", ServiceID: "s1"}); err != nil {
+		t.Fatalf("SaveProjectLink() error = %v", err)
+	}
+	links, err := ProjectLinks(s)
+	if err != nil {
+		t.Fatalf("ProjectLinks() error = %v", err)
+	}
+	if len(links) != 1 || links[0].Dir != dir || links[0].ServiceID != "s1" {
+		t.Fatalf("ProjectLinks() = %+v", links)
+	}
+
+	if err := RemoveProjectLink(s, dir); err != nil {
+		t.Fatalf("RemoveProjectLink() error = %v", err)
+	}
+	if links, _ := ProjectLinks(s); len(links) != 0 {
+		t.Fatalf("ProjectLinks() after remove = %+v", links)
+	}
+}
+
+func TestSealUnseal(t *testing.T) {
+	b := Bundle{"projects/a": []byte(`{"dir":"/x"}`)}
+
+	sealed, err := Seal(b, "correct horse")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	got, err := Unseal(sealed, "correct horse")
+	if err != nil {
+		t.Fatalf("Unseal() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, b) {
+		t.Fatalf("Unseal() = %v, want %v", got, b)
+	}
+	if _, err := Unseal(sealed, "wrong"); err == nil {
+		t.Fatal("Unseal() with wrong passphrase should fail")
+	}
+}
+
+func TestDeploymentRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	if d, err := LoadDeployment(dir); err != nil || d != nil {
+		t.Fatalf("LoadDeployment(empty) = %v, %v; want nil, nil", d, err)
+	}
+
+	configPath := filepath.Join(dir, "datagen.toml")
+	if err := os.WriteFile(configPath, []byte("a"), 0o644); err != nil {
+		t.Fatalf("WriteFile error = %v", err)
+	}
+	hash, err := HashFile(configPath)
+	if err != nil {
+		t.Fatalf("HashFile() error = %v", err)
+	}
+
+	d := &Deployment{Platform: "railway", ServiceID: "s1", ConfigHash: hash}
+	d.AddVariableKeys("B", "A", "B")
+	if err := SaveDeployment(dir, d); err != nil {
+		t.Fatalf("SaveDeployment() error = %v", err)
+	}
+
+	loaded, err := LoadDeployment(dir)
+	if err != nil {
+		t.Fatalf("LoadDeployment() error = %v", err)
+	}
+	if loaded.ServiceID != "s1" || !reflect.DeepEqual(loaded.VariableKeys, []string{"A", "B"}) {
+		t.Fatalf("LoadDeployment() = %+v", loaded)
+	}
+	if changed, _ := loaded.ConfigChanged(configPath); changed {
+		t.Fatal("ConfigChanged() = true before editing the config")
+	}
+	if err := os.WriteFile(configPath, []byte("b"), 0o644); err != nil {
+		t.Fatalf("WriteFile error = %v", err)
+	}
+	if changed, _ := loaded.ConfigChanged(configPath); !changed {
+		t.Fatal("ConfigChanged() = false after editing the config")
+	}
+	if err := loaded.MarkDeployed(configPath); err != nil {
+		t.Fatalf("MarkDeployed() error = %v", err)
+	}
+	if changed, _ := loaded.ConfigChanged(configPath); changed || loaded.DeployedAt == nil {
+		t.Fatalf("after MarkDeployed: ConfigChanged() = %v, DeployedAt = %v; want false and set", changed, loaded.DeployedAt)
+	}
+
+	if err := RemoveDeployment(dir); err != nil {
+		t.Fatalf("RemoveDeployment() error = %v", err)
+	}
+	if d, _ := LoadDeployment(dir); d != nil {
+		t.Fatalf("LoadDeployment() after remove = %+v", d)
+	}
+}
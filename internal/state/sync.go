@@ -0,0 +1,133 @@
+package state
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+)
+
+// Bundle is every key of a store, used for sync
+type Bundle map[string][]byte
+
+// Export reads every key of s into a bundle
+func Export(s Store) (Bundle, error) {
+	keys, err := s.List("")
+	if err != nil {
+		return nil, err
+	}
+	b := Bundle{}
+	for _, key := range keys {
+		value, err := s.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		b[key] = value
+	}
+	return b, nil
+}
+
+// Import writes every key of b into s, overwriting existing values
+func Import(s Store, b Bundle) error {
+	for key, value := range b {
+		if err := s.Put(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	kdfIterations = 600000
+	saltSize      = 16
+	keySize       = 32
+)
+
+// encryptedBundle is the sealed form uploaded for sync; the platform never
+// sees the passphrase or plaintext
+type encryptedBundle struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	return pbkdf2.Key(sha256.New, passphrase, salt, kdfIterations, keySize)
+}
+
+// Seal encrypts b with AES-256-GCM under a key derived from passphrase
+func Seal(b Bundle, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("a sync passphrase is required")
+	}
+	plaintext, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(encryptedBundle{
+		Version:    1,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	})
+}
+
+// Unseal decrypts data produced by Seal
+func Unseal(data []byte, passphrase string) (Bundle, error) {
+	var enc encryptedBundle
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return nil, fmt.Errorf("failed to parse synced state: %w", err)
+	}
+	if enc.Version != 1 {
+		return nil, fmt.Errorf("unsupported synced state version %d", enc.Version)
+	}
+
+	key, err := deriveKey(passphrase, enc.Salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, enc.Nonce, enc.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt synced state (wrong passphrase?)")
+	}
+
+	var b Bundle
+	if err := json.Unmarshal(plaintext, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse synced state: %w", err)
+	}
+	return b, nil
+}
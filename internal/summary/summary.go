@@ -0,0 +1,146 @@
+package summary
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// Step outcomes
+const (
+	OK      = "ok"
+	Failed  = "failed"
+	Skipped = "skipped"
+)
+
+// Step is one timed unit of work in a command
+type Step struct {
+	Name       string        `json:"name"`
+	Outcome    string        `json:"outcome"`
+	Duration   time.Duration `json:"-"`
+	DurationMS int64         `json:"duration_ms"`
+	Detail     string        `json:"detail,omitempty"`
+}
+
+// Summary records the steps of a command and prints them as a final table or JSON
+type Summary struct {
+	Command   string   `json:"command"`
+	Steps     []Step   `json:"steps"`
+	NextSteps []string `json:"next_steps,omitempty"`
+	Succeeded bool     `json:"succeeded"`
+	TotalMS   int64    `json:"total_ms"`
+
+	start time.Time
+}
+
+// New starts timing command
+func New(command string) *Summary {
+	return &Summary{Command: command, Steps: []Step{}, start: time.Now()}
+}
+
+// ValidateFormat checks a --summary value
+func ValidateFormat(format string) error {
+	switch format {
+	case "table", "json", "none":
+		return nil
+	default:
+		return fmt.Errorf("invalid --summary %q (expected table, json, or none)", format)
+	}
+}
+
+// Run times fn as a step named name and returns its error
+func (s *Summary) Run(name string, fn func() error) error {
+	started := time.Now()
+	err := fn()
+	step := Step{Name: name, Outcome: OK, Duration: time.Since(started)}
+	if err != nil {
+		step.Outcome = Failed
+		step.Detail = err.Error()
+	}
+	s.add(step)
+	return err
+}
+
+// Skip records a step that did not run
+func (s *Summary) Skip(name, reason string) {
+	s.add(Step{Name: name, Outcome: Skipped, Detail: reason})
+}
+
+func (s *Summary) add(step Step) {
+	step.DurationMS = step.Duration.Milliseconds()
+	s.Steps = append(s.Steps, step)
+}
+
+// Next adds follow-up commands shown after a successful run
+func (s *Summary) Next(lines ...string) {
+	s.NextSteps = append(s.NextSteps, lines...)
+}
+
+func (s *Summary) finish() {
+	s.TotalMS = time.Since(s.start).Milliseconds()
+	s.Succeeded = true
+	for _, step := range s.Steps {
+		if step.Outcome == Failed {
+			s.Succeeded = false
+		}
+	}
+}
+
+// Write prints the summary in format ("table", "json", or "none")
+func (s *Summary) Write(w io.Writer, format string) error {
+	s.finish()
+	switch format {
+	case "none":
+		return nil
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STEP\tDURATION\tRESULT")
+	for _, step := range s.Steps {
+		result := "✅ " + step.Outcome
+		switch step.Outcome {
+		case Failed:
+			result = "❌ " + step.Outcome
+		case Skipped:
+			result = "⏭️  " + step.Outcome
+		}
+		if step.Detail != "" {
+			result += " (" + step.Detail + ")"
+		}
+		duration := "-"
+		if step.Outcome != Skipped {
+			duration = formatDuration(step.Duration)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", step.Name, duration, result)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	status := "succeeded"
+	if !s.Succeeded {
+		status = "failed"
+	}
+	fmt.Fprintf(w, "\n%s %s in %s\n", s.Command, status, formatDuration(time.Duration(s.TotalMS)*time.Millisecond))
+	if s.Succeeded && len(s.NextSteps) > 0 {
+		fmt.Fprintln(w, "\nNext steps:")
+		for _, line := range s.NextSteps {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	}
+	return nil
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
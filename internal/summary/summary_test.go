@@ -0,0 +1,49 @@
+package summary
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSummaryTable(t *testing.T) {
+	s := New("build")
+	if err := s.Run("Load config", func() error { return nil }); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	s.Skip("Copy prompts", "same directory")
+	s.Next("datagen invoke chat")
+
+	var out bytes.Buffer
+	if err := s.Write(&out, "table"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	for _, want := range []string{"Load config", "✅ ok", "skipped (same directory)", "build succeeded", "datagen invoke chat"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("table missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestSummaryJSONFailure(t *testing.T) {
+	s := New("build")
+	wantErr := errors.New("boom")
+	if err := s.Run("Generate project", func() error { return wantErr }); err != wantErr {
+		t.Fatalf("Run() error = %v, want %v", err, wantErr)
+	}
+	s.Next("never shown")
+
+	var out bytes.Buffer
+	if err := s.Write(&out, "json"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	var got Summary
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got.Succeeded || len(got.Steps) != 1 || got.Steps[0].Outcome != Failed || got.Steps[0].Detail != "boom" {
+		t.Fatalf("summary = %+v", got)
+	}
+}
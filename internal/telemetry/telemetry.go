@@ -0,0 +1,240 @@
+// Package telemetry sends opt-in, anonymous usage events: which command ran,
+// for how long, on which OS, and what kind of error it ended with. Events
+// never carry arguments, file contents, keys or error messages.
+package telemetry
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/api"
+	"github.com/datagendev/datagen-cli/internal/config"
+	"github.com/datagendev/datagen-cli/internal/transcript"
+)
+
+// EnvVar turns telemetry off for a process when set to 0, false or off
+const EnvVar = "DATAGEN_TELEMETRY"
+
+// sendTimeout bounds how long a command waits for its event to be delivered
+const sendTimeout = 1500 * time.Millisecond
+
+// Settings is the saved telemetry choice
+type Settings struct {
+	Enabled bool `json:"enabled"`
+	// InstallID is a random identifier created when telemetry is turned on,
+	// so events from one machine can be grouped without identifying anyone
+	InstallID string `json:"install_id,omitempty"`
+}
+
+// Event is everything an event sends
+type Event struct {
+	InstallID  string            `json:"install_id"`
+	Command    string            `json:"command"`
+	DurationMs int64             `json:"duration_ms"`
+	OS         string            `json:"os"`
+	Arch       string            `json:"arch"`
+	Version    string            `json:"version"`
+	ErrorClass string            `json:"error_class,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+}
+
+// Path returns the settings file, next to the update-check cache
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "datagen", "telemetry.json"), nil
+}
+
+// Load returns the saved settings; telemetry is off until turned on
+func Load() (Settings, error) {
+	var s Settings
+	path, err := Path()
+	if err != nil {
+		return s, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// SetEnabled saves the telemetry choice. Turning telemetry on creates an
+// install ID; turning it off deletes it.
+func SetEnabled(enabled bool) (Settings, error) {
+	s := Settings{Enabled: enabled}
+	if enabled {
+		current, _ := Load()
+		s.InstallID = current.InstallID
+		if s.InstallID == "" {
+			id := make([]byte, 16)
+			if _, err := rand.Read(id); err != nil {
+				return s, err
+			}
+			s.InstallID = hex.EncodeToString(id)
+		}
+	}
+
+	path, err := Path()
+	if err != nil {
+		return s, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return s, err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return s, err
+	}
+	return s, os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// DisabledByEnv returns the environment variable that turns telemetry off
+// for this process, if any
+func DisabledByEnv() string {
+	switch strings.ToLower(os.Getenv(EnvVar)) {
+	case "0", "false", "off", "no":
+		return EnvVar
+	}
+	if v := os.Getenv("DO_NOT_TRACK"); v != "" && v != "0" {
+		return "DO_NOT_TRACK"
+	}
+	return ""
+}
+
+// Endpoint is where events are sent
+func Endpoint() string {
+	base := os.Getenv("DATAGEN_API_BASE_URL")
+	if base == "" {
+		base = api.DefaultBaseURL
+	}
+	return strings.TrimSuffix(base, "/") + "/api/cli/telemetry"
+}
+
+var (
+	tagsMu sync.Mutex
+	tags   = map[string]string{}
+)
+
+// Tag attaches a property to this run's event, such as the deploy platform
+// or service types used. Only pass values from a fixed set (flag choices,
+// service types), never user input.
+func Tag(key, value string) {
+	tagsMu.Lock()
+	defer tagsMu.Unlock()
+	tags[key] = value
+}
+
+// TagConfig tags the service types cfg uses, e.g. "api,webhook"
+func TagConfig(cfg *config.DatagenConfig) {
+	seen := map[string]bool{}
+	var types []string
+	for _, svc := range cfg.Services {
+		if !seen[svc.Type] {
+			seen[svc.Type] = true
+			types = append(types, svc.Type)
+		}
+	}
+	sort.Strings(types)
+	Tag("service_types", strings.Join(types, ","))
+}
+
+// NewEvent builds the event for a command that ran for duration and ended
+// with err
+func NewEvent(command string, duration time.Duration, version string, err error) Event {
+	tagsMu.Lock()
+	defer tagsMu.Unlock()
+	e := Event{
+		Command:    command,
+		DurationMs: duration.Milliseconds(),
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		Version:    version,
+		ErrorClass: ErrorClass(err),
+	}
+	if len(tags) > 0 {
+		e.Tags = make(map[string]string, len(tags))
+		for k, v := range tags {
+			e.Tags[k] = v
+		}
+	}
+	return e
+}
+
+// ErrorClass buckets err without exposing its message
+func ErrorClass(err error) string {
+	if err == nil {
+		return ""
+	}
+	var failure *transcript.Failure
+	var netErr net.Error
+	msg := err.Error()
+	switch {
+	case errors.As(err, &failure):
+		return "external_command"
+	case errors.As(err, &netErr):
+		return "network"
+	case strings.Contains(msg, "API error ("):
+		// Keep only the status code, e.g. api_401
+		code, _, _ := strings.Cut(msg[strings.Index(msg, "API error (")+len("API error ("):], ")")
+		return "api_" + code
+	case strings.HasPrefix(msg, "unknown command") || strings.HasPrefix(msg, "unknown flag") ||
+		strings.HasPrefix(msg, "unknown shorthand flag") || strings.Contains(msg, "arg(s)") ||
+		strings.HasPrefix(msg, "invalid argument"):
+		return "usage"
+	case strings.Contains(msg, "config validation failed") || strings.Contains(msg, "failed to parse TOML"):
+		return "config"
+	case errors.Is(err, fs.ErrNotExist):
+		return "file_not_found"
+	case errors.Is(err, fs.ErrPermission):
+		return "permission"
+	default:
+		return "other"
+	}
+}
+
+// Send delivers e when telemetry is on and not disabled by the environment.
+// Failures are ignored; telemetry never gets in the way of a command.
+func Send(e Event) {
+	if DisabledByEnv() != "" {
+		return
+	}
+	s, err := Load()
+	if err != nil || !s.Enabled || s.InstallID == "" {
+		return
+	}
+	e.InstallID = s.InstallID
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	client := &http.Client{Timeout: sendTimeout}
+	resp, err := client.Post(Endpoint(), "application/json", bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+}
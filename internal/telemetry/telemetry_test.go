@@ -0,0 +1,96 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/datagendev/datagen-cli/internal/transcript"
+)
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{fmt.Errorf("API error (401): invalid key sk-123"), "api_401"},
+		{fmt.Errorf("whoami: %w", fmt.Errorf("API error (503): down")), "api_503"},
+		{&transcript.Failure{Err: errors.New("railway up failed"), Path: "x.log"}, "external_command"},
+		{fmt.Errorf(`unknown command "deplyo" for "datagen"`), "usage"},
+		{fmt.Errorf("config validation failed: service foo: missing prompt"), "config"},
+		{fmt.Errorf("read: %w", os.ErrNotExist), "file_not_found"},
+		{errors.New("something about /Users/me/secret-project"), "other"},
+	}
+	for _, tt := range tests {
+		if got := ErrorClass(tt.err); got != tt.want {
+			t.Errorf("ErrorClass(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(EnvVar, "")
+	t.Setenv("DO_NOT_TRACK", "")
+
+	var received []Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/cli/telemetry" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var e Event
+		if err := json.Unmarshal(body, &e); err != nil {
+			t.Errorf("invalid event %s", body)
+		}
+		received = append(received, e)
+	}))
+	defer server.Close()
+	t.Setenv("DATAGEN_API_BASE_URL", server.URL)
+
+	event := NewEvent("env pull", 2*time.Second, "v1.0.0", nil)
+
+	// Off by default
+	Send(event)
+	if len(received) != 0 {
+		t.Fatalf("expected no events before opting in, got %d", len(received))
+	}
+
+	s, err := SetEnabled(true)
+	if err != nil {
+		t.Fatalf("SetEnabled(true) error = %v", err)
+	}
+	if len(s.InstallID) != 32 {
+		t.Errorf("InstallID = %q, want 32 hex chars", s.InstallID)
+	}
+	Send(event)
+	if len(received) != 1 || received[0].InstallID != s.InstallID || received[0].Command != "env pull" || received[0].DurationMs != 2000 {
+		t.Fatalf("received = %+v", received)
+	}
+
+	t.Setenv("DO_NOT_TRACK", "1")
+	Send(event)
+	if len(received) != 1 {
+		t.Errorf("expected DO_NOT_TRACK to suppress the event")
+	}
+	t.Setenv("DO_NOT_TRACK", "")
+
+	if _, err := SetEnabled(false); err != nil {
+		t.Fatalf("SetEnabled(false) error = %v", err)
+	}
+	Send(event)
+	if len(received) != 1 {
+		t.Errorf("expected no events after opting out")
+	}
+	if s, _ := Load(); s.InstallID != "" {
+		t.Errorf("expected opting out to drop the install ID, got %q", s.InstallID)
+	}
+}
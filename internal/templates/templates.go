@@ -0,0 +1,180 @@
+// Package templates fetches the curated starter projects `datagen init`
+// creates from the datagen-templates GitHub repository.
+//
+// A source has an index.json at its root listing each template and its files,
+// which live under a directory named after the template:
+//
+//	{"templates": [{"name": "slack-bot", "description": "...",
+//	  "files": ["datagen.toml", ".claude/agents/slack-bot.md", "samples/slack_bot.json"]}]}
+package templates
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultURL is where templates are fetched from
+const DefaultURL = "https://raw.githubusercontent.com/datagendev/datagen-templates/main"
+
+// URLEnvVar overrides DefaultURL with another base URL or a local directory,
+// e.g. a checkout of the templates repo
+const URLEnvVar = "DATAGEN_TEMPLATES_URL"
+
+const fetchTimeout = 30 * time.Second
+
+// Template is one starter project
+type Template struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Files       []string `json:"files"`
+}
+
+type index struct {
+	Templates []Template `json:"templates"`
+}
+
+// Source reads templates from a base URL or a local directory
+type Source struct {
+	Base   string
+	client *http.Client
+}
+
+// NewSource returns a source reading from base, an http(s) URL or a directory
+func NewSource(base string) *Source {
+	return &Source{
+		Base:   strings.TrimSuffix(base, "/"),
+		client: &http.Client{Timeout: fetchTimeout},
+	}
+}
+
+// DefaultSource returns the source named by DATAGEN_TEMPLATES_URL, or DefaultURL
+func DefaultSource() *Source {
+	if base := os.Getenv(URLEnvVar); base != "" {
+		return NewSource(base)
+	}
+	return NewSource(DefaultURL)
+}
+
+// List returns the available templates sorted by name
+func (s *Source) List() ([]Template, error) {
+	data, err := s.read("index.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch template index: %w", err)
+	}
+	var idx index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("failed to parse template index: %w", err)
+	}
+	sort.Slice(idx.Templates, func(i, j int) bool { return idx.Templates[i].Name < idx.Templates[j].Name })
+	return idx.Templates, nil
+}
+
+// Get returns the template called name
+func (s *Source) Get(name string) (*Template, error) {
+	list, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for i := range list {
+		if list[i].Name == name {
+			return &list[i], nil
+		}
+		names = append(names, list[i].Name)
+	}
+	return nil, fmt.Errorf("unknown template %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// Fetch downloads every file of t, keyed by its slash-separated path
+func (s *Source) Fetch(t *Template) (map[string][]byte, error) {
+	if !validName(t.Name) {
+		return nil, fmt.Errorf("invalid template name %q", t.Name)
+	}
+	files := make(map[string][]byte, len(t.Files))
+	for _, f := range t.Files {
+		if !ValidPath(f) {
+			return nil, fmt.Errorf("template %s lists unsafe path %q", t.Name, f)
+		}
+		data, err := s.read(t.Name + "/" + f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s/%s: %w", t.Name, f, err)
+		}
+		files[f] = data
+	}
+	if _, ok := files["datagen.toml"]; !ok {
+		return nil, fmt.Errorf("template %s has no datagen.toml", t.Name)
+	}
+	return files, nil
+}
+
+func (s *Source) read(name string) ([]byte, error) {
+	if !strings.HasPrefix(s.Base, "http://") && !strings.HasPrefix(s.Base, "https://") {
+		return os.ReadFile(filepath.Join(s.Base, filepath.FromSlash(name)))
+	}
+	resp, err := s.client.Get(s.Base + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s/%s returned %d", s.Base, name, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func validName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, `/\`) && name != "." && name != ".."
+}
+
+// ValidPath reports whether p is a relative slash path that stays inside the
+// project directory
+func ValidPath(p string) bool {
+	if p == "" || strings.Contains(p, `\`) || path.IsAbs(p) || filepath.IsAbs(p) {
+		return false
+	}
+	clean := path.Clean(p)
+	return clean == p && clean != "." && clean != ".." && !strings.HasPrefix(clean, "../")
+}
+
+// Write writes files into dir. Existing files are only replaced when force is
+// set; otherwise nothing is written and the conflicts are reported.
+func Write(dir string, files map[string][]byte, force bool) ([]string, error) {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	if !force {
+		var conflicts []string
+		for _, p := range paths {
+			if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(p))); !errors.Is(err, fs.ErrNotExist) {
+				conflicts = append(conflicts, p)
+			}
+		}
+		if len(conflicts) > 0 {
+			return nil, fmt.Errorf("would overwrite %s in %s (use --force to overwrite)", strings.Join(conflicts, ", "), dir)
+		}
+	}
+
+	for _, p := range paths {
+		dest := filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(dest, files[p], 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
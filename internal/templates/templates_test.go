@@ -0,0 +1,132 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeSource(t *testing.T, index string, files map[string]string) *Source {
+	t.Helper()
+	dir := t.TempDir()
+	files["index.json"] = index
+	for p, content := range files {
+		dest := filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(dest, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewSource(dir)
+}
+
+func TestSource_ListGetFetch(t *testing.T) {
+	src := writeSource(t, `{"templates": [
+		{"name": "slack-bot", "description": "Slack bot", "files": ["datagen.toml", ".claude/agents/slack-bot.md"]},
+		{"name": "lead-enrichment-api", "description": "Leads", "files": ["datagen.toml", "samples/enrich.json"]}
+	]}`, map[string]string{
+		"lead-enrichment-api/datagen.toml":        "[[service]]\n",
+		"lead-enrichment-api/samples/enrich.json": `{"email": "a@example.com"}`,
+	})
+
+	list, err := src.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 2 || list[0].Name != "lead-enrichment-api" {
+		t.Fatalf("List() = %+v, want sorted by name", list)
+	}
+
+	tmpl, err := src.Get("lead-enrichment-api")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	files, err := src.Fetch(tmpl)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	want := map[string][]byte{
+		"datagen.toml":        []byte("[[service]]\n"),
+		"samples/enrich.json": []byte(`{"email": "a@example.com"}`),
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("Fetch() = %q, want %q", files, want)
+	}
+
+	if _, err := src.Get("missing"); err == nil || !strings.Contains(err.Error(), "slack-bot") {
+		t.Errorf("Get(missing) error = %v, want the available names", err)
+	}
+	if _, err := src.Fetch(&list[1]); err == nil {
+		t.Errorf("expected Fetch() to fail for missing files")
+	}
+}
+
+func TestFetch_RejectsUnsafeTemplates(t *testing.T) {
+	src := writeSource(t, `{"templates": []}`, map[string]string{})
+	tests := []Template{
+		{Name: "../evil", Files: []string{"datagen.toml"}},
+		{Name: "ok", Files: []string{"../../.bashrc"}},
+		{Name: "ok", Files: []string{"README.md"}},
+	}
+	for _, tmpl := range tests {
+		if _, err := src.Fetch(&tmpl); err == nil {
+			t.Errorf("Fetch(%+v) expected an error", tmpl)
+		}
+	}
+}
+
+func TestValidPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"datagen.toml", true},
+		{".claude/agents/bot.md", true},
+		{"samples/a.json", true},
+		{"", false},
+		{"/etc/passwd", false},
+		{"../x", false},
+		{"a/../../x", false},
+		{"a/./b", false},
+		{`a\b`, false},
+		{"..", false},
+	}
+	for _, tt := range tests {
+		if got := ValidPath(tt.path); got != tt.want {
+			t.Errorf("ValidPath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWrite_Conflicts(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "datagen.toml"), []byte("mine"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"datagen.toml":   []byte("theirs"),
+		"samples/a.json": []byte("{}"),
+	}
+
+	if _, err := Write(dir, files, false); err == nil || !strings.Contains(err.Error(), "datagen.toml") {
+		t.Fatalf("Write() error = %v, want a conflict on datagen.toml", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "samples", "a.json")); !os.IsNotExist(err) {
+		t.Errorf("expected nothing written on conflict")
+	}
+
+	written, err := Write(dir, files, true)
+	if err != nil {
+		t.Fatalf("Write(force) error = %v", err)
+	}
+	if want := []string{"datagen.toml", "samples/a.json"}; !reflect.DeepEqual(written, want) {
+		t.Errorf("Write() = %v, want %v", written, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "datagen.toml")); string(data) != "theirs" {
+		t.Errorf("datagen.toml = %q, want overwritten", data)
+	}
+}
@@ -0,0 +1,207 @@
+// Package transcript records the output of external commands (railway, npm,
+// docker) so a failed invocation leaves a full, redacted log behind instead
+// of whatever scrolled past in the terminal.
+package transcript
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dir is where transcripts are written, relative to the project directory
+const Dir = ".datagen/logs"
+
+// redacted replaces every secret value in a saved transcript
+const redacted = "[REDACTED]"
+
+// minSecretLen keeps short values like "1" or "true" from being scrubbed
+// everywhere they happen to appear
+const minSecretLen = 6
+
+var (
+	secretNamePattern = regexp.MustCompile(`(?i)(KEY|TOKEN|SECRET|PASSWORD|PASSWD|CREDENTIAL)`)
+	bearerPattern     = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]{8,}`)
+	assignmentPattern = regexp.MustCompile(`(?i)\b([A-Z0-9_]*(?:KEY|TOKEN|SECRET|PASSWORD)[A-Z0-9_]*)(\s*[=:]\s*"?)[^\s"]+`)
+	anthropicPattern  = regexp.MustCompile(`sk-ant-[A-Za-z0-9_-]+`)
+)
+
+// Recorder tees a command's output into memory while it still streams to
+// wherever the caller pointed it
+type Recorder struct {
+	cmd     *exec.Cmd
+	out     lockedBuffer
+	secrets []string
+	now     func() time.Time
+}
+
+// Attach wraps cmd's Stdout and Stderr so their output is also recorded.
+// Streams that are nil are left alone, so callers can still use StdoutPipe.
+// Call Attach after setting cmd.Stdout and cmd.Stderr.
+func Attach(cmd *exec.Cmd) *Recorder {
+	r := &Recorder{cmd: cmd, now: time.Now}
+	if cmd.Stdout != nil {
+		cmd.Stdout = io.MultiWriter(cmd.Stdout, &r.out)
+	}
+	if cmd.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(cmd.Stderr, &r.out)
+	}
+	return r
+}
+
+// Redact registers extra values, such as variables being set, that must not
+// appear in a saved transcript
+func (r *Recorder) Redact(values ...string) {
+	r.secrets = append(r.secrets, values...)
+}
+
+// Failure is a command error whose transcript was saved to Path
+type Failure struct {
+	Err  error
+	Path string
+}
+
+func (f *Failure) Error() string {
+	return fmt.Sprintf("%v\nFull output saved to %s", f.Err, f.Path)
+}
+
+func (f *Failure) Unwrap() error { return f.Err }
+
+// Fail saves the transcript under projectDir when err is non-nil and returns
+// err as a *Failure carrying the transcript path. A nil err passes through.
+func (r *Recorder) Fail(projectDir string, err error) error {
+	if err == nil {
+		return nil
+	}
+	path, saveErr := r.Save(projectDir, err)
+	if saveErr != nil {
+		return fmt.Errorf("%w (could not save command log: %v)", err, saveErr)
+	}
+	return &Failure{Err: err, Path: path}
+}
+
+// Latest returns the most recent transcript under projectDir
+func Latest(projectDir string) (string, error) {
+	logDir := filepath.Join(projectDir, Dir)
+	entries, err := os.ReadDir(logDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("no command logs in %s", logDir)
+		}
+		return "", err
+	}
+	latest := ""
+	for _, e := range entries {
+		// Names start with a sortable timestamp
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".log") && e.Name() > latest {
+			latest = e.Name()
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no command logs in %s", logDir)
+	}
+	return filepath.Join(logDir, latest), nil
+}
+
+// Save writes the redacted transcript to projectDir/.datagen/logs and returns
+// its path
+func (r *Recorder) Save(projectDir string, runErr error) (string, error) {
+	if projectDir == "" {
+		projectDir = "."
+	}
+	logDir := filepath.Join(projectDir, Dir)
+	if err := os.MkdirAll(logDir, 0o700); err != nil {
+		return "", err
+	}
+
+	now := r.now()
+	path := filepath.Join(logDir, fmt.Sprintf("%s-%s.log", now.Format("20060102-150405"), r.name()))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "command: %s\n", strings.Join(r.cmd.Args, " "))
+	if r.cmd.Dir != "" {
+		fmt.Fprintf(&b, "dir: %s\n", r.cmd.Dir)
+	}
+	fmt.Fprintf(&b, "time: %s\n", now.Format(time.RFC3339))
+	if runErr != nil {
+		fmt.Fprintf(&b, "error: %v\n", runErr)
+	}
+	b.WriteString("\n")
+	b.Write(r.out.Bytes())
+
+	if err := os.WriteFile(path, []byte(r.redact(b.String())), 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// name returns a filename-safe label such as "railway-up"
+func (r *Recorder) name() string {
+	parts := []string{filepath.Base(r.cmd.Path)}
+	if len(r.cmd.Args) > 1 && !strings.HasPrefix(r.cmd.Args[1], "-") {
+		parts = append(parts, r.cmd.Args[1])
+	}
+	name := strings.Join(parts, "-")
+	return strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+			return c
+		}
+		return '_'
+	}, name)
+}
+
+func (r *Recorder) redact(text string) string {
+	values := append([]string{}, r.secrets...)
+	for _, kv := range os.Environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if ok && secretNamePattern.MatchString(name) {
+			values = append(values, value)
+		}
+	}
+	return Redact(text, values...)
+}
+
+// Redact scrubs the given secret values and anything shaped like a credential
+// (bearer tokens, Anthropic keys, NAME_KEY=value assignments) from text
+func Redact(text string, secrets ...string) string {
+	// Replace longer values first so a secret containing another is fully hidden
+	secrets = append([]string(nil), secrets...)
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	for _, secret := range secrets {
+		if len(secret) < minSecretLen {
+			continue
+		}
+		text = strings.ReplaceAll(text, secret, redacted)
+	}
+	text = anthropicPattern.ReplaceAllString(text, redacted)
+	text = bearerPattern.ReplaceAllString(text, "${1}"+redacted)
+	text = assignmentPattern.ReplaceAllString(text, "${1}${2}"+redacted)
+	return text
+}
+
+// lockedBuffer serialises writes from the stdout and stderr copiers
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...)
+}
@@ -0,0 +1,107 @@
+package transcript
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"explicit value", "set GREETING=supersecretvalue", "set GREETING=[REDACTED]"},
+		{"short values kept", "retries=3", "retries=3"},
+		{"bearer token", "Authorization: Bearer abcdefgh12345678", "Authorization: Bearer [REDACTED]"},
+		{"anthropic key", "using sk-ant-api03-abc_DEF-123", "using [REDACTED]"},
+		{"key assignment", `DATAGEN_API_KEY="dg_live_123"`, `DATAGEN_API_KEY="[REDACTED]"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Redact(tt.in, "supersecretvalue", "3"); got != tt.want {
+				t.Fatalf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecorderFailSavesTranscript(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	dir := t.TempDir()
+	t.Setenv("TEST_DEPLOY_TOKEN", "tok_0123456789")
+
+	cmd := exec.Command("sh", "-c", "echo deploying with tok_0123456789; echo boom >&2; exit 3")
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	rec := Attach(cmd)
+	rec.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	err := rec.Fail(dir, cmd.Run())
+	if err == nil {
+		t.Fatal("Fail() error = nil, want command failure")
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Fail() error = %v, want wrapped *exec.ExitError", err)
+	}
+	if !strings.Contains(stdout.String(), "deploying") || !strings.Contains(stderr.String(), "boom") {
+		t.Fatalf("output not streamed: stdout=%q stderr=%q", stdout.String(), stderr.String())
+	}
+
+	path := filepath.Join(dir, Dir, "20260102-030405-sh.log")
+	if !strings.Contains(err.Error(), path) {
+		t.Fatalf("Fail() error = %q, want it to mention %s", err, path)
+	}
+	data, readErr := os.ReadFile(path)
+	if readErr != nil {
+		t.Fatalf("ReadFile error = %v", readErr)
+	}
+	got := string(data)
+	for _, want := range []string{"command: sh -c", "boom", "deploying with [REDACTED]"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("transcript missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "tok_0123456789") {
+		t.Fatalf("transcript leaked secret:\n%s", got)
+	}
+}
+
+func TestRecorderFailPassesNil(t *testing.T) {
+	rec := Attach(exec.Command("true"))
+	if err := rec.Fail(t.TempDir(), nil); err != nil {
+		t.Fatalf("Fail(nil) = %v, want nil", err)
+	}
+}
+
+func TestLatest(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Latest(dir); err == nil {
+		t.Fatal("Latest() on empty project error = nil, want error")
+	}
+	logDir := filepath.Join(dir, Dir)
+	if err := os.MkdirAll(logDir, 0o700); err != nil {
+		t.Fatalf("MkdirAll error = %v", err)
+	}
+	for _, name := range []string{"20260101-120000-railway-up.log", "20260102-090000-railway-init.log", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(logDir, name), nil, 0o600); err != nil {
+			t.Fatalf("WriteFile error = %v", err)
+		}
+	}
+	got, err := Latest(dir)
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+	if want := filepath.Join(logDir, "20260102-090000-railway-init.log"); got != want {
+		t.Fatalf("Latest() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,262 @@
+// Package undo snapshots the files a command is about to rewrite in a
+// generated project (and its datagen.toml and prompts) into
+// .datagen/undo/<timestamp>/, so `datagen undo` can put them back the way
+// they were before the command ran.
+package undo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dir holds the snapshots, relative to the project directory
+const Dir = ".datagen/undo"
+
+// Keep is how many snapshots are kept per project
+const Keep = 10
+
+// ErrNothingToUndo is returned by Restore for a project without snapshots
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// indexFile lists a snapshot's files; their saved contents sit next to it,
+// named by their position in the list
+const indexFile = "snapshot.json"
+
+const timeLayout = "20060102T150405.000000000Z"
+
+var (
+	mu     sync.Mutex
+	active *Snapshot
+)
+
+// Snapshot is the state of the files one command changed, from before it ran
+type Snapshot struct {
+	Command string    `json:"command"`
+	Time    time.Time `json:"time"`
+	Files   []File    `json:"files"`
+
+	project string // absolute project directory
+	dir     string // where the snapshot is stored
+	saved   map[string]bool
+}
+
+// File is one file in a snapshot
+type File struct {
+	// Path is relative to the project directory, or absolute for files
+	// outside it (a datagen.toml above an --output directory)
+	Path string `json:"path"`
+	// Absent is set when the file did not exist; undoing removes it
+	Absent bool        `json:"absent,omitempty"`
+	Mode   fs.FileMode `json:"mode,omitempty"`
+}
+
+// Begin starts a snapshot of the project in dir for command. Until End, Save
+// records each file before it is first written. The snapshot is only stored
+// once a file has been saved into it.
+func Begin(dir, command string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	mu.Lock()
+	defer mu.Unlock()
+	active = &Snapshot{
+		Command: command,
+		Time:    now,
+		project: abs,
+		dir:     filepath.Join(abs, Dir, now.Format(timeLayout)),
+		saved:   map[string]bool{},
+	}
+	return nil
+}
+
+// Save copies path into the current snapshot before it is rewritten or
+// created, unless it was already saved. Without a snapshot (commands that
+// don't take one, tests) it does nothing. Call it before writing.
+func Save(path string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if active == nil {
+		return nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if active.saved[abs] {
+		return nil
+	}
+	if err := active.save(abs); err != nil {
+		return fmt.Errorf("failed to snapshot %s for undo: %w", path, err)
+	}
+	active.saved[abs] = true
+	return nil
+}
+
+func (s *Snapshot) save(abs string) error {
+	file := File{Path: abs}
+	if rel, err := filepath.Rel(s.project, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		file.Path = filepath.ToSlash(rel)
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	info, err := os.Stat(abs)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		file.Absent = true
+	case err != nil:
+		return err
+	default:
+		data, err := os.ReadFile(abs)
+		if err != nil {
+			return err
+		}
+		file.Mode = info.Mode().Perm()
+		if err := os.WriteFile(filepath.Join(s.dir, strconv.Itoa(len(s.Files))), data, 0644); err != nil {
+			return err
+		}
+	}
+	s.Files = append(s.Files, file)
+	return s.writeIndex()
+}
+
+// writeIndex is rewritten after every file, so a command that dies midway
+// still leaves an undoable snapshot of what it touched
+func (s *Snapshot) writeIndex() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.dir, indexFile), append(data, '\n'), 0644)
+}
+
+// End finishes the current snapshot and prunes old ones beyond Keep. It
+// returns the snapshot, or nil when the command saved no files.
+func End() (*Snapshot, error) {
+	mu.Lock()
+	s := active
+	active = nil
+	mu.Unlock()
+	if s == nil || len(s.Files) == 0 {
+		return nil, nil
+	}
+	snapshots, err := List(s.project)
+	if err != nil {
+		return s, err
+	}
+	for _, old := range snapshots[min(Keep, len(snapshots)):] {
+		if err := os.RemoveAll(old.dir); err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
+
+// List returns the project's snapshots, newest first
+func List(dir string) ([]*Snapshot, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(abs, Dir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []*Snapshot
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		s, err := read(abs, filepath.Join(abs, Dir, e.Name()))
+		if err != nil {
+			// Begun but nothing saved yet, or not a snapshot at all
+			continue
+		}
+		snapshots = append(snapshots, s)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Time.After(snapshots[j].Time) })
+	return snapshots, nil
+}
+
+func read(project, dir string) (*Snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{project: project, dir: dir}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(dir, indexFile), err)
+	}
+	return s, nil
+}
+
+// Restore puts back the files of the project's latest snapshot, removing
+// the ones the command created, and deletes the snapshot so the next
+// Restore goes one command further back.
+func Restore(dir string) (*Snapshot, error) {
+	snapshots, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, ErrNothingToUndo
+	}
+	s := snapshots[0]
+	for i, f := range s.Files {
+		path := s.abs(f.Path)
+		if f.Absent {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+			s.removeEmptyDirs(filepath.Dir(path))
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, strconv.Itoa(i)))
+		if err != nil {
+			return nil, fmt.Errorf("snapshot of %s is damaged: %w", f.Path, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		mode := f.Mode
+		if mode == 0 {
+			mode = 0644
+		}
+		if err := os.WriteFile(path, data, mode); err != nil {
+			return nil, err
+		}
+	}
+	return s, os.RemoveAll(s.dir)
+}
+
+func (s *Snapshot) abs(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(s.project, filepath.FromSlash(path))
+}
+
+// removeEmptyDirs removes dir and its parents up to the project directory
+// while they are empty, so undoing a first build leaves no empty app/
+func (s *Snapshot) removeEmptyDirs(dir string) {
+	for dir != s.project && strings.HasPrefix(dir, s.project+string(filepath.Separator)) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
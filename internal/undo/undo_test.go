@@ -0,0 +1,122 @@
+package undo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func write(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := Save(path); err != nil {
+		t.Fatalf("Save(%s) error = %v", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "app", "main.py")
+	config := filepath.Join(t.TempDir(), "datagen.toml")
+	if err := os.MkdirAll(filepath.Dir(main), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(main, []byte("v1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(config, []byte("before\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Begin(dir, "add"); err != nil {
+		t.Fatal(err)
+	}
+	write(t, main, "v2\n")
+	write(t, main, "v3\n") // only the first write is snapshotted
+	write(t, config, "after\n")
+	created := filepath.Join(dir, "scripts", "stream_client.py")
+	write(t, created, "new\n")
+	s, err := End()
+	if err != nil {
+		t.Fatalf("End() error = %v", err)
+	}
+	if s == nil || len(s.Files) != 3 {
+		t.Fatalf("End() = %+v, want a snapshot of 3 files", s)
+	}
+	if s.Files[0].Path != "app/main.py" || s.Files[1].Path != config || !s.Files[2].Absent {
+		t.Errorf("Files = %+v, want app/main.py relative, datagen.toml absolute, stream_client.py absent", s.Files)
+	}
+
+	restored, err := Restore(dir)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if restored.Command != "add" {
+		t.Errorf("Command = %q, want add", restored.Command)
+	}
+	for path, want := range map[string]string{main: "v1\n", config: "before\n"} {
+		if data, _ := os.ReadFile(path); string(data) != want {
+			t.Errorf("%s = %q, want %q", path, data, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Dir(created)); !os.IsNotExist(err) {
+		t.Errorf("scripts/ should be removed with the file the command created, stat error = %v", err)
+	}
+
+	if _, err := Restore(dir); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("second Restore() error = %v, want ErrNothingToUndo", err)
+	}
+}
+
+func TestEndWithoutChanges(t *testing.T) {
+	dir := t.TempDir()
+	if err := Begin(dir, "build"); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := End(); s != nil || err != nil {
+		t.Fatalf("End() = %v, %v; want no snapshot", s, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, Dir)); !os.IsNotExist(err) {
+		t.Errorf("%s should not be created for a command that wrote nothing", Dir)
+	}
+	if err := Save(filepath.Join(dir, "app", "main.py")); err != nil {
+		t.Fatalf("Save() outside a snapshot error = %v", err)
+	}
+}
+
+func TestEndPrunes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.py")
+	for i := range Keep + 2 {
+		if err := Begin(dir, "build"); err != nil {
+			t.Fatal(err)
+		}
+		write(t, path, string(rune('a'+i)))
+		if _, err := End(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshots, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != Keep {
+		t.Fatalf("List() returned %d snapshots, want %d", len(snapshots), Keep)
+	}
+
+	// Newest first: undoing twice goes back two builds
+	for range 2 {
+		if _, err := Restore(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if data, _ := os.ReadFile(path); string(data) != string(rune('a'+Keep-1)) {
+		t.Errorf("after two undos app.py = %q, want %q", data, string(rune('a'+Keep-1)))
+	}
+}
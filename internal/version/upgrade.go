@@ -0,0 +1,134 @@
+package version
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	releaseDownloadURL = "https://github.com/datagendev/datagen-cli/releases/download"
+	checksumsAsset     = "checksums.txt"
+	downloadTimeout    = 5 * time.Minute
+)
+
+// AssetName returns the release asset built for goos/goarch, matching the
+// names install.sh and install.ps1 download
+func AssetName(goos, goarch string) string {
+	name := fmt.Sprintf("datagen-%s-%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// DownloadRelease fetches the binary for the running OS/arch from release tag
+// and verifies it against the release's checksums.txt
+func DownloadRelease(tag string) ([]byte, error) {
+	asset := AssetName(runtime.GOOS, runtime.GOARCH)
+	client := &http.Client{Timeout: downloadTimeout}
+
+	sums, err := download(client, fmt.Sprintf("%s/%s/%s", releaseDownloadURL, tag, checksumsAsset))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", checksumsAsset, err)
+	}
+	want, err := ParseChecksum(sums, asset)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := download(client, fmt.Sprintf("%s/%s/%s", releaseDownloadURL, tag, asset))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset, err)
+	}
+	if err := VerifyChecksum(data, want); err != nil {
+		return nil, fmt.Errorf("%s: %w", asset, err)
+	}
+	return data, nil
+}
+
+func download(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ParseChecksum returns the SHA-256 listed for asset in sha256sum-style
+// checksums.txt contents
+func ParseChecksum(sums []byte, asset string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// sha256sum marks binary mode with a leading '*' on the file name
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == asset {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum for %s in %s", asset, checksumsAsset)
+}
+
+// VerifyChecksum checks data against a hex-encoded SHA-256
+func VerifyChecksum(data []byte, want string) error {
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch (got %s, want %s)", got, want)
+	}
+	return nil
+}
+
+// ReplaceExecutable swaps the binary at path for data. The new file is written
+// next to it and renamed into place so a failed upgrade leaves the old binary
+// working. Windows cannot overwrite a running executable, so the old one is
+// moved aside to path.old first.
+func ReplaceExecutable(path string, data []byte) error {
+	mode := os.FileMode(0o755)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".datagen-upgrade-*")
+	if err != nil {
+		return fmt.Errorf("failed to write next to %s: %w", path, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		old := path + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(path, old); err != nil {
+			return err
+		}
+		if err := os.Rename(tmpPath, path); err != nil {
+			_ = os.Rename(old, path)
+			return err
+		}
+		return nil
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -29,8 +29,12 @@ type githubRelease struct {
 	TagName string `json:"tag_name"`
 }
 
+// NoUpdateCheckEnv turns off the passive update notice when set to any value
+const NoUpdateCheckEnv = "DATAGEN_NO_UPDATE_CHECK"
+
 // CheckForUpdate starts a background goroutine that checks for a newer CLI
-// version. Returns a channel that receives a user-facing message (empty if
+// version. The latest release is fetched at most once a day and cached in
+// between. Returns a channel that receives a user-facing message (empty if
 // no update or on any error).
 func CheckForUpdate() <-chan string {
 	ch := make(chan string, 1)
@@ -42,25 +46,25 @@ func CheckForUpdate() <-chan string {
 			}
 		}()
 
-		if Version == "dev" || isCI() {
-			return
-		}
-
-		if !shouldCheck() {
+		if Version == "dev" || isCI() || os.Getenv(NoUpdateCheckEnv) != "" {
 			return
 		}
 
-		latest, err := FetchLatestVersion()
-		if err != nil {
-			return
+		var latest string
+		if c, err := readCache(); err == nil && time.Since(c.LastChecked) <= cooldownDuration {
+			latest = c.LatestVersion
+		} else {
+			latest, err = FetchLatestVersion()
+			if err != nil {
+				return
+			}
+			_ = writeCache(latest)
 		}
 
-		_ = writeCache(latest)
-
 		if IsNewer(Version, latest) {
 			ch <- fmt.Sprintf(
-				"\nA newer version of datagen is available: %s (current: %s)\nUpdate with: curl -fsSL https://raw.githubusercontent.com/datagendev/datagen-cli/main/install.sh | sh",
-				latest, Version,
+				"\nA newer version of datagen is available: %s (current: %s)\nUpdate with: datagen upgrade (set %s=1 to silence this notice)",
+				latest, Version, NoUpdateCheckEnv,
 			)
 		}
 	}()
@@ -151,14 +155,6 @@ func cachePath() (string, error) {
 	return filepath.Join(dir, "datagen", "update-check.json"), nil
 }
 
-func shouldCheck() bool {
-	c, err := readCache()
-	if err != nil {
-		return true
-	}
-	return time.Since(c.LastChecked) > cooldownDuration
-}
-
 func readCache() (*updateCache, error) {
 	p, err := cachePath()
 	if err != nil {
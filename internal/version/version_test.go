@@ -122,3 +122,57 @@ func TestCacheReadWrite(t *testing.T) {
 		t.Error("expected LastChecked to be older than 24h")
 	}
 }
+
+func TestAssetName(t *testing.T) {
+	if got := AssetName("darwin", "arm64"); got != "datagen-darwin-arm64" {
+		t.Errorf("AssetName(darwin, arm64) = %q", got)
+	}
+	if got := AssetName("windows", "amd64"); got != "datagen-windows-amd64.exe" {
+		t.Errorf("AssetName(windows, amd64) = %q", got)
+	}
+}
+
+func TestParseChecksum(t *testing.T) {
+	sums := []byte("bfb015f7  datagen-darwin-amd64\n27815AE2 *datagen-darwin-arm64\n")
+	if got, err := ParseChecksum(sums, "datagen-darwin-arm64"); err != nil || got != "27815ae2" {
+		t.Errorf("ParseChecksum() = %q, %v; want 27815ae2", got, err)
+	}
+	if _, err := ParseChecksum(sums, "datagen-linux-amd64"); err == nil {
+		t.Error("expected an error for a missing asset")
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	// sha256 of "datagen"
+	const want = "8aa4cbb874ea84f3b84e8fb3fbde5ced2565b49fc8782212dfdf00d73894bb83"
+	if err := VerifyChecksum([]byte("datagen"), want); err != nil {
+		t.Errorf("VerifyChecksum() error = %v", err)
+	}
+	if err := VerifyChecksum([]byte("tampered"), want); err == nil {
+		t.Error("expected a mismatch error")
+	}
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "datagen")
+	if err := os.WriteFile(path, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReplaceExecutable(path, []byte("new")); err != nil {
+		t.Fatalf("ReplaceExecutable() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Errorf("contents = %q, want new", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o755 {
+		t.Errorf("mode = %v, want 0755", info.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected only the binary left behind, got %d entries", len(entries))
+	}
+}